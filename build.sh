@@ -2,7 +2,7 @@
 
 set -e # Exit the script if any command fails
 
-SERVICES=("user")
+SERVICES=("user" "quiz" "game")
 
 for SERVICE in "${SERVICES[@]}"; do
     IMAGE_NAME="quizchief-$SERVICE-service"
@@ -11,8 +11,10 @@ for SERVICE in "${SERVICES[@]}"; do
     DOCKER_REPO="codymmoore97"
     SERVICE_PATH="./server/internal/$SERVICE"
 
-    echo "Generating sqlc files for $SERVICE..."
-    sqlc generate --file $SERVICE_PATH/sqlc.yaml
+    if [ -f $SERVICE_PATH/sqlc.yaml ]; then
+        echo "Generating sqlc files for $SERVICE..."
+        sqlc generate --file $SERVICE_PATH/sqlc.yaml
+    fi
 
     echo "Building docker image for $SERVICE..."
     docker build --no-cache --target=release -f $SERVICE_PATH/Dockerfile -t $FULL_IMAGE_NAME server
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"common"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"quiz"
+)
+
+const usage = `Usage:
+  quiz [-migrate-on-start]             Start the quiz service
+  quiz migrate up|down|status|version  Run database migrations and exit
+`
+
+// main Initializes the quiz service
+func main() {
+	flags := flag.NewFlagSet("quiz", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	migrateOnStart := flags.Bool(
+		"migrate-on-start",
+		os.Getenv("MIGRATE_ON_START") == "true",
+		"apply pending migrations before starting the server",
+	)
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() > 0 {
+		if flags.Arg(0) != "migrate" || flags.NArg() != 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		if err := quiz.Migrate(context.Background(), flags.Arg(1), os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *migrateOnStart {
+		if err := quiz.Migrate(context.Background(), common.MigrateUp, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	quiz.RunServer()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"common"
+	"context"
+	"fmt"
+	"os"
+	"user"
+	"user/userctl"
+)
+
+// main Runs the userctl admin CLI against the user database
+func main() {
+	ctx := context.Background()
+
+	databaseConfig, err := common.LoadDatabaseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading database configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	service, closeDatabase, err := user.NewService(ctx, databaseConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error establishing database connection: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeDatabase()
+
+	if err := userctl.Run(ctx, os.Args[1:], service, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		_ = closeDatabase()
+		os.Exit(1)
+	}
+}
@@ -4,6 +4,10 @@ go 1.24
 
 replace user => ./internal/user
 
+replace quiz => ./internal/quiz
+
+replace game => ./internal/game
+
 require github.com/go-chi/chi/v5 v5.2.1
 
 require (
@@ -0,0 +1,159 @@
+package common
+
+import (
+	"container/list"
+	"context"
+	"expvar"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache Key/value store with per-entry expiry
+type Cache interface {
+	// Get Retrieve the value for the key, reporting false if it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set Store the value for the key until the TTL elapses
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete Remove the keys, ignoring keys that are not present
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// CacheMetrics Hit, miss and backend error counters for a cache
+type CacheMetrics struct {
+	Hits   atomic.Int64
+	Misses atomic.Int64
+	Errors atomic.Int64
+}
+
+// Publish Expose the counters through expvar under the specified name. They are served by NewMetricsHandler
+func (metrics *CacheMetrics) Publish(name string) {
+	expvar.Publish(
+		name, expvar.Func(
+			func() any {
+				return map[string]int64{
+					"hits":   metrics.Hits.Load(),
+					"misses": metrics.Misses.Load(),
+					"errors": metrics.Errors.Load(),
+				}
+			},
+		),
+	)
+}
+
+// NewMetricsHandler Create a handler serving the published expvar metrics at /debug/vars. The metrics include memory
+// statistics and the process command line, so the handler belongs on an internal listener rather than the public router
+func NewMetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	return mux
+}
+
+// LRUCache In-process Cache evicting the least recently used entry once capacity is reached
+type LRUCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+
+	// Now Clock used to expire entries
+	Now func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+var _ Cache = (*LRUCache)(nil)
+
+// NewLRUCache Create an empty LRU cache holding at most capacity entries
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		Now:      time.Now,
+	}
+}
+
+// Get Get() implementation from Cache interface
+func (cache *LRUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !cache.Now().Before(entry.expiresAt) {
+		cache.remove(element)
+		return nil, false, nil
+	}
+
+	cache.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set Set() implementation from Cache interface
+func (cache *LRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.capacity <= 0 {
+		return nil
+	}
+
+	expiresAt := cache.Now().Add(ttl)
+	if element, ok := cache.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cache.order.MoveToFront(element)
+		return nil
+	}
+
+	for cache.order.Len() >= cache.capacity {
+		cache.remove(cache.order.Back())
+	}
+
+	cache.entries[key] = cache.order.PushFront(
+		&lruEntry{
+			key:       key,
+			value:     value,
+			expiresAt: expiresAt,
+		},
+	)
+	return nil
+}
+
+// Delete Delete() implementation from Cache interface
+func (cache *LRUCache) Delete(ctx context.Context, keys ...string) error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, ok := cache.entries[key]; ok {
+			cache.remove(element)
+		}
+	}
+	return nil
+}
+
+// Len Get the number of entries currently held, including expired entries not yet evicted
+func (cache *LRUCache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.order.Len()
+}
+
+// remove Remove the element from the cache. The mutex must be held
+func (cache *LRUCache) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*lruEntry).key)
+}
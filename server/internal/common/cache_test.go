@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRUCache_GetSet(t *testing.T) {
+	cache := NewLRUCache(2)
+	ctx := context.Background()
+
+	if _, ok, _ := cache.Get(ctx, "missing"); ok {
+		t.Error(`cache.Get(ctx, "missing") ok = "true", expected "false"`)
+	}
+
+	_ = cache.Set(ctx, "key", []byte("value"), time.Minute)
+	value, ok, err := cache.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Errorf(`cache.Get(ctx, "key") = "%s, %v, %v", expected "value, true, <nil>"`, value, ok, err)
+	}
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "a", []byte("a"), time.Minute)
+	_ = cache.Set(ctx, "b", []byte("b"), time.Minute)
+	_, _, _ = cache.Get(ctx, "a")
+	_ = cache.Set(ctx, "c", []byte("c"), time.Minute)
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error(`cache.Get(ctx, "b") ok = "true", expected "false"`)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := cache.Get(ctx, key); !ok {
+			t.Errorf(`cache.Get(ctx, "%s") ok = "false", expected "true"`, key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf(`cache.Len() = "%d", expected "2"`, cache.Len())
+	}
+}
+
+func TestLRUCache_Expiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewLRUCache(2)
+	cache.Now = func() time.Time {
+		return now
+	}
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "key", []byte("value"), time.Minute)
+	now = now.Add(time.Minute)
+
+	if _, ok, _ := cache.Get(ctx, "key"); ok {
+		t.Error(`cache.Get(ctx, "key") ok = "true", expected "false"`)
+	}
+	if cache.Len() != 0 {
+		t.Errorf(`cache.Len() = "%d", expected "0"`, cache.Len())
+	}
+}
+
+func TestLRUCache_Delete(t *testing.T) {
+	cache := NewLRUCache(2)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "a", []byte("a"), time.Minute)
+	_ = cache.Set(ctx, "b", []byte("b"), time.Minute)
+	_ = cache.Delete(ctx, "a", "missing")
+
+	if _, ok, _ := cache.Get(ctx, "a"); ok {
+		t.Error(`cache.Get(ctx, "a") ok = "true", expected "false"`)
+	}
+	if _, ok, _ := cache.Get(ctx, "b"); !ok {
+		t.Error(`cache.Get(ctx, "b") ok = "false", expected "true"`)
+	}
+}
+
+func TestLoadCacheConfig(t *testing.T) {
+	config, err := LoadCacheConfig()
+	if err != nil {
+		t.Fatalf(`LoadCacheConfig() error = "%v", expected "<nil>"`, err)
+	}
+	if config.Size != DefaultCacheSize || config.TTL != DefaultCacheTTL {
+		t.Errorf(`config = "%+v", expected defaults`, config)
+	}
+
+	t.Setenv("CACHE_SIZE", "0")
+	t.Setenv("CACHE_TTL", "5s")
+	config, err = LoadCacheConfig()
+	if err != nil {
+		t.Fatalf(`LoadCacheConfig() error = "%v", expected "<nil>"`, err)
+	}
+	if config.Size != 0 || config.TTL != 5*time.Second {
+		t.Errorf(`config = "%+v", expected size "0" and TTL "5s"`, config)
+	}
+}
+
+func TestNewMetricsHandler(t *testing.T) {
+	metrics := &CacheMetrics{}
+	metrics.Hits.Add(1)
+	metrics.Publish("test_cache")
+
+	request := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+	recorder := httptest.NewRecorder()
+	NewMetricsHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, `"test_cache": {"errors":0,"hits":1,"misses":0}`) {
+		t.Errorf(`recorder.Body = "%v", expected it to contain the test_cache counters`, body)
+	}
+}
@@ -0,0 +1,516 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	// DriverMemory DATABASE_DRIVER value selecting the in-memory store instead of a SQL database
+	DriverMemory = "memory"
+)
+
+const (
+	DefaultMaxOpenConns    = 25
+	DefaultMaxIdleConns    = 25
+	DefaultConnMaxLifetime = 5 * time.Minute
+	DefaultConnectTimeout  = time.Minute
+	DefaultQueryTimeout    = 10 * time.Second
+	DefaultInitialBackoff  = 500 * time.Millisecond
+	DefaultMaxBackoff      = 10 * time.Second
+)
+
+const (
+	DefaultCacheSize = 10000
+	DefaultCacheTTL  = time.Minute
+)
+
+const (
+	DefaultOutboxPollInterval = time.Second
+	DefaultOutboxBatchSize    = 100
+	DefaultOutboxRetention    = 7 * 24 * time.Hour
+)
+
+const (
+	DefaultDeletionGracePeriod   = 30 * 24 * time.Hour
+	DefaultDeletionPurgeInterval = time.Hour
+	DefaultDeletionBatchSize     = 100
+	DefaultArchiveRetention      = 365 * 24 * time.Hour
+)
+
+const (
+	DefaultDataExportPollInterval = 5 * time.Second
+	DefaultDataExportLease        = 10 * time.Minute
+	DefaultDataExportTTL          = 7 * 24 * time.Hour
+)
+
+// DefaultRecentQuestionWindow How long a player who saw a question bank question is not shown it again
+const DefaultRecentQuestionWindow = 7 * 24 * time.Hour
+
+const (
+	DefaultLobbyMaxPlayers    = 50
+	DefaultLobbyIdleTimeout   = 30 * time.Minute
+	DefaultLobbySweepInterval = time.Minute
+	DefaultQuestionTimeLimit  = 20 * time.Second
+	DefaultAnswerGrace        = 500 * time.Millisecond
+)
+
+const (
+	DefaultMatchPlayers       = 4
+	DefaultMatchMinPlayers    = 2
+	DefaultMatchInitialWindow = 100
+	DefaultMatchWindowGrowth  = 10
+	DefaultMatchMaxWindow     = 500
+	DefaultMatchMaxWait       = 30 * time.Second
+	DefaultMatchInterval      = time.Second
+	DefaultMatchQuestionCount = 10
+)
+
+// DatabaseConfig Connection and pool settings for a service database
+type DatabaseConfig struct {
+	Driver          string
+	Url             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnectTimeout  time.Duration
+	QueryTimeout    time.Duration
+	InitialBackoff  time.Duration
+	MaxBackoff      time.Duration
+}
+
+// AuthConfig Settings for authenticating requests. Without a user service address, tokens are trusted without
+// checking that their user still exists
+type AuthConfig struct {
+	// UserServiceAddr gRPC address of the user service (e.g. "user:9090")
+	UserServiceAddr string
+}
+
+// CacheConfig Settings for a service's read-through cache. A size of zero disables caching
+type CacheConfig struct {
+	Size int
+	TTL  time.Duration
+}
+
+// OutboxConfig Settings for relaying outbox events. Without a webhook url there is no sink and the relay is not run
+type OutboxConfig struct {
+	WebhookUrl    string
+	WebhookSecret string
+	PollInterval  time.Duration
+	BatchSize     int
+	Retention     time.Duration
+}
+
+// DeletionConfig Settings for self-service account deletion and the job purging deleted accounts
+type DeletionConfig struct {
+	// GracePeriod How long an account can still be recovered after deletion is requested
+	GracePeriod   time.Duration
+	PurgeInterval time.Duration
+	BatchSize     int
+	// ArchiveRetention How long archived accounts are kept before being anonymized. Zero keeps them forever
+	ArchiveRetention time.Duration
+}
+
+// DataExportConfig Settings for the job building users' personal data exports. Without a game service url, exports
+// leave out the user's game data
+type DataExportConfig struct {
+	PollInterval time.Duration
+	// Lease How long a worker has to build an export before another worker may retry it
+	Lease time.Duration
+	// TTL How long a finished export can be downloaded
+	TTL time.Duration
+	// GameServiceUrl Base url of the game service (e.g. "http://game:8082"), asked for the user's game data
+	GameServiceUrl string
+	// GameServiceSecret Secret signing requests to the game service, matching its USER_DATA_SECRET
+	GameServiceSecret string
+}
+
+// UserDataConfig Settings for the routes the user service calls to export and delete a user's game data. Without a
+// secret the routes are not served
+type UserDataConfig struct {
+	// Secret Secret the user service signs its requests and events with
+	Secret string
+}
+
+// QuestionBankConfig Settings for generating quizzes from the question bank
+type QuestionBankConfig struct {
+	// RecentWindow How long a question is left out of generated quizzes for players who saw it
+	RecentWindow time.Duration
+}
+
+// LobbyConfig Settings for game lobbies
+type LobbyConfig struct {
+	// MaxPlayers Most players a lobby can hold, used when the host does not set a smaller limit
+	MaxPlayers int
+	// IdleTimeout How long a lobby without any activity is kept before it expires
+	IdleTimeout   time.Duration
+	SweepInterval time.Duration
+	// QuestionTimeLimit How long players have to answer each question
+	QuestionTimeLimit time.Duration
+	// AnswerGrace How long after the time limit answers are still accepted, allowing for network latency
+	AnswerGrace time.Duration
+}
+
+// LeaderboardConfig Settings for game leaderboards. Without a user service address, leaderboard entries are not
+// resolved to usernames
+type LeaderboardConfig struct {
+	// UserServiceAddr gRPC address of the user service (e.g. "user:9090")
+	UserServiceAddr string
+}
+
+// MatchmakingConfig Settings for the ranked matchmaking queue. Rating windows are in rating points either side of a
+// player's rating
+type MatchmakingConfig struct {
+	// Players How many players a match is made for
+	Players int
+	// MinPlayers Fewest players a match is made for once its longest waiting player has waited MaxWait
+	MinPlayers    int
+	InitialWindow int
+	// WindowGrowth How much a player's window widens for every second they wait
+	WindowGrowth int
+	MaxWindow    int
+	MaxWait      time.Duration
+	// Interval How often waiting players are matched
+	Interval time.Duration
+	// QuestionCount How many questions the quiz of a match has, drawn from the question bank
+	QuestionCount int
+}
+
+// MailConfig Settings for sending email. Without an SMTP server, emails are written to the log instead
+type MailConfig struct {
+	SMTPAddr string
+	From     string
+	Username string
+	Password string
+	// AppUrl Base url of the web app used for links in emails, falling back to BASE_URL
+	AppUrl string
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// LoadDatabaseConfig Build the database configuration from environment variables
+func LoadDatabaseConfig() (*DatabaseConfig, error) {
+	config := DatabaseConfig{
+		Driver: os.Getenv("DATABASE_DRIVER"),
+		Url:    os.Getenv("DATABASE_URL"),
+	}
+
+	if config.Driver == "" {
+		return nil, errors.New("DATABASE_DRIVER environment variable not set")
+	}
+
+	if config.Url == "" && config.Driver != DriverMemory {
+		return nil, errors.New("DATABASE_URL environment variable not set")
+	}
+
+	var err error
+	if config.MaxOpenConns, err = getEnvInt("DATABASE_MAX_OPEN_CONNS", DefaultMaxOpenConns); err != nil {
+		return nil, err
+	}
+
+	if config.MaxIdleConns, err = getEnvInt("DATABASE_MAX_IDLE_CONNS", DefaultMaxIdleConns); err != nil {
+		return nil, err
+	}
+
+	if config.ConnMaxLifetime, err = getEnvDuration(
+		"DATABASE_CONN_MAX_LIFETIME",
+		DefaultConnMaxLifetime,
+	); err != nil {
+		return nil, err
+	}
+
+	if config.ConnectTimeout, err = getEnvDuration("DATABASE_CONNECT_TIMEOUT", DefaultConnectTimeout); err != nil {
+		return nil, err
+	}
+
+	if config.QueryTimeout, err = getEnvDuration("DATABASE_QUERY_TIMEOUT", DefaultQueryTimeout); err != nil {
+		return nil, err
+	}
+
+	config.InitialBackoff = DefaultInitialBackoff
+	config.MaxBackoff = DefaultMaxBackoff
+
+	return &config, nil
+}
+
+// LoadAuthConfig Build the authentication configuration from environment variables
+func LoadAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		UserServiceAddr: os.Getenv("USER_SERVICE_ADDR"),
+	}
+}
+
+// LoadCacheConfig Build the cache configuration from environment variables
+func LoadCacheConfig() (*CacheConfig, error) {
+	var config CacheConfig
+
+	var err error
+	if config.Size, err = getEnvInt("CACHE_SIZE", DefaultCacheSize); err != nil {
+		return nil, err
+	}
+
+	if config.TTL, err = getEnvDuration("CACHE_TTL", DefaultCacheTTL); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadOutboxConfig Build the outbox relay configuration from environment variables
+func LoadOutboxConfig() (*OutboxConfig, error) {
+	config := OutboxConfig{
+		WebhookUrl:    os.Getenv("OUTBOX_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+	}
+
+	if config.WebhookUrl != "" && config.WebhookSecret == "" {
+		return nil, errors.New("OUTBOX_WEBHOOK_SECRET environment variable not set")
+	}
+
+	var err error
+	if config.PollInterval, err = getEnvDuration("OUTBOX_POLL_INTERVAL", DefaultOutboxPollInterval); err != nil {
+		return nil, err
+	}
+
+	if config.BatchSize, err = getEnvInt("OUTBOX_BATCH_SIZE", DefaultOutboxBatchSize); err != nil {
+		return nil, err
+	}
+
+	if config.Retention, err = getEnvDuration("OUTBOX_RETENTION", DefaultOutboxRetention); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadDeletionConfig Build the account deletion configuration from environment variables
+func LoadDeletionConfig() (*DeletionConfig, error) {
+	var config DeletionConfig
+	var err error
+	if config.GracePeriod, err = getEnvDuration("DELETION_GRACE_PERIOD", DefaultDeletionGracePeriod); err != nil {
+		return nil, err
+	}
+
+	if config.PurgeInterval, err = getEnvDuration("DELETION_PURGE_INTERVAL", DefaultDeletionPurgeInterval); err != nil {
+		return nil, err
+	}
+
+	if config.BatchSize, err = getEnvInt("DELETION_BATCH_SIZE", DefaultDeletionBatchSize); err != nil {
+		return nil, err
+	}
+
+	if config.ArchiveRetention, err = getEnvDuration("ARCHIVE_RETENTION", DefaultArchiveRetention); err != nil {
+		return nil, err
+	}
+
+	if config.GracePeriod <= 0 || config.PurgeInterval <= 0 || config.BatchSize <= 0 || config.ArchiveRetention < 0 {
+		return nil, errors.New("account deletion settings must be positive")
+	}
+
+	return &config, nil
+}
+
+// LoadDataExportConfig Build the data export configuration from environment variables
+func LoadDataExportConfig() (*DataExportConfig, error) {
+	var config DataExportConfig
+	var err error
+	if config.PollInterval, err = getEnvDuration("DATA_EXPORT_POLL_INTERVAL", DefaultDataExportPollInterval); err != nil {
+		return nil, err
+	}
+
+	if config.Lease, err = getEnvDuration("DATA_EXPORT_LEASE", DefaultDataExportLease); err != nil {
+		return nil, err
+	}
+
+	if config.TTL, err = getEnvDuration("DATA_EXPORT_TTL", DefaultDataExportTTL); err != nil {
+		return nil, err
+	}
+
+	if config.PollInterval <= 0 || config.Lease <= 0 || config.TTL <= 0 {
+		return nil, errors.New("data export settings must be positive")
+	}
+
+	config.GameServiceUrl = os.Getenv("GAME_SERVICE_URL")
+	config.GameServiceSecret = os.Getenv("GAME_SERVICE_SECRET")
+	if config.GameServiceUrl != "" && config.GameServiceSecret == "" {
+		return nil, errors.New("GAME_SERVICE_SECRET environment variable not set")
+	}
+
+	return &config, nil
+}
+
+// LoadUserDataConfig Build the user data configuration from environment variables
+func LoadUserDataConfig() *UserDataConfig {
+	return &UserDataConfig{
+		Secret: os.Getenv("USER_DATA_SECRET"),
+	}
+}
+
+// LoadQuestionBankConfig Build the question bank configuration from environment variables
+func LoadQuestionBankConfig() (*QuestionBankConfig, error) {
+	var config QuestionBankConfig
+	var err error
+	if config.RecentWindow, err = getEnvDuration("RECENT_QUESTION_WINDOW", DefaultRecentQuestionWindow); err != nil {
+		return nil, err
+	}
+
+	if config.RecentWindow <= 0 {
+		return nil, errors.New("RECENT_QUESTION_WINDOW must be positive")
+	}
+
+	return &config, nil
+}
+
+// LoadLobbyConfig Build the game lobby configuration from environment variables
+func LoadLobbyConfig() (*LobbyConfig, error) {
+	var config LobbyConfig
+	var err error
+	if config.MaxPlayers, err = getEnvInt("LOBBY_MAX_PLAYERS", DefaultLobbyMaxPlayers); err != nil {
+		return nil, err
+	}
+
+	if config.IdleTimeout, err = getEnvDuration("LOBBY_IDLE_TIMEOUT", DefaultLobbyIdleTimeout); err != nil {
+		return nil, err
+	}
+
+	if config.SweepInterval, err = getEnvDuration("LOBBY_SWEEP_INTERVAL", DefaultLobbySweepInterval); err != nil {
+		return nil, err
+	}
+
+	if config.QuestionTimeLimit, err = getEnvDuration("QUESTION_TIME_LIMIT", DefaultQuestionTimeLimit); err != nil {
+		return nil, err
+	}
+
+	if config.AnswerGrace, err = getEnvDuration("ANSWER_GRACE", DefaultAnswerGrace); err != nil {
+		return nil, err
+	}
+
+	if config.MaxPlayers < 2 {
+		return nil, errors.New("LOBBY_MAX_PLAYERS must be at least 2")
+	}
+
+	if config.IdleTimeout <= 0 || config.SweepInterval <= 0 || config.QuestionTimeLimit <= 0 {
+		return nil, errors.New("lobby settings must be positive")
+	}
+
+	if config.AnswerGrace < 0 {
+		return nil, errors.New("ANSWER_GRACE must not be negative")
+	}
+
+	return &config, nil
+}
+
+// LoadLeaderboardConfig Build the leaderboard configuration from environment variables
+func LoadLeaderboardConfig() *LeaderboardConfig {
+	return &LeaderboardConfig{
+		UserServiceAddr: os.Getenv("USER_SERVICE_ADDR"),
+	}
+}
+
+// LoadMatchmakingConfig Build the matchmaking configuration from environment variables
+func LoadMatchmakingConfig() (*MatchmakingConfig, error) {
+	var config MatchmakingConfig
+	var err error
+	if config.Players, err = getEnvInt("MATCH_PLAYERS", DefaultMatchPlayers); err != nil {
+		return nil, err
+	}
+
+	if config.MinPlayers, err = getEnvInt("MATCH_MIN_PLAYERS", DefaultMatchMinPlayers); err != nil {
+		return nil, err
+	}
+
+	if config.InitialWindow, err = getEnvInt("MATCH_INITIAL_WINDOW", DefaultMatchInitialWindow); err != nil {
+		return nil, err
+	}
+
+	if config.WindowGrowth, err = getEnvInt("MATCH_WINDOW_GROWTH", DefaultMatchWindowGrowth); err != nil {
+		return nil, err
+	}
+
+	if config.MaxWindow, err = getEnvInt("MATCH_MAX_WINDOW", DefaultMatchMaxWindow); err != nil {
+		return nil, err
+	}
+
+	if config.MaxWait, err = getEnvDuration("MATCH_MAX_WAIT", DefaultMatchMaxWait); err != nil {
+		return nil, err
+	}
+
+	if config.Interval, err = getEnvDuration("MATCH_INTERVAL", DefaultMatchInterval); err != nil {
+		return nil, err
+	}
+
+	if config.QuestionCount, err = getEnvInt("MATCH_QUESTION_COUNT", DefaultMatchQuestionCount); err != nil {
+		return nil, err
+	}
+
+	if config.MinPlayers < 2 || config.Players < config.MinPlayers {
+		return nil, errors.New("MATCH_MIN_PLAYERS must be at least 2 and at most MATCH_PLAYERS")
+	}
+
+	if config.MaxWindow < config.InitialWindow {
+		return nil, errors.New("MATCH_MAX_WINDOW must be at least MATCH_INITIAL_WINDOW")
+	}
+
+	if config.Interval <= 0 || config.QuestionCount <= 0 {
+		return nil, errors.New("matchmaking settings must be positive")
+	}
+
+	return &config, nil
+}
+
+// LoadMailConfig Build the mail configuration from environment variables
+func LoadMailConfig() (*MailConfig, error) {
+	config := MailConfig{
+		SMTPAddr: os.Getenv("SMTP_ADDR"),
+		From:     os.Getenv("SMTP_FROM"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		AppUrl:   os.Getenv("APP_URL"),
+	}
+
+	if config.SMTPAddr != "" && config.From == "" {
+		return nil, errors.New("SMTP_FROM environment variable not set")
+	}
+
+	if config.AppUrl == "" {
+		config.AppUrl = os.Getenv("BASE_URL")
+	}
+
+	return &config, nil
+}
+
+// getEnvInt Read an integer environment variable, returning the fallback if it is not set
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return parsed, nil
+}
+
+// getEnvDuration Read a duration environment variable (e.g. "30s"), returning the fallback if it is not set
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration", key)
+	}
+	return parsed, nil
+}
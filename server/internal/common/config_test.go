@@ -0,0 +1,278 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadDatabaseConfig_Defaults(t *testing.T) {
+	t.Setenv("DATABASE_DRIVER", "postgres")
+	t.Setenv("DATABASE_URL", "postgres://localhost")
+
+	config, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf(`LoadDatabaseConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.MaxOpenConns != DefaultMaxOpenConns {
+		t.Errorf(`config.MaxOpenConns = "%d", expected "%d"`, config.MaxOpenConns, DefaultMaxOpenConns)
+	}
+	if config.ConnMaxLifetime != DefaultConnMaxLifetime {
+		t.Errorf(`config.ConnMaxLifetime = "%v", expected "%v"`, config.ConnMaxLifetime, DefaultConnMaxLifetime)
+	}
+	if config.QueryTimeout != DefaultQueryTimeout {
+		t.Errorf(`config.QueryTimeout = "%v", expected "%v"`, config.QueryTimeout, DefaultQueryTimeout)
+	}
+}
+
+func TestLoadDatabaseConfig_Overrides(t *testing.T) {
+	t.Setenv("DATABASE_DRIVER", "postgres")
+	t.Setenv("DATABASE_URL", "postgres://localhost")
+	t.Setenv("DATABASE_MAX_OPEN_CONNS", "5")
+	t.Setenv("DATABASE_MAX_IDLE_CONNS", "2")
+	t.Setenv("DATABASE_CONN_MAX_LIFETIME", "1m")
+	t.Setenv("DATABASE_CONNECT_TIMEOUT", "90s")
+	t.Setenv("DATABASE_QUERY_TIMEOUT", "3s")
+
+	config, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf(`LoadDatabaseConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.MaxOpenConns != 5 {
+		t.Errorf(`config.MaxOpenConns = "%d", expected "5"`, config.MaxOpenConns)
+	}
+	if config.MaxIdleConns != 2 {
+		t.Errorf(`config.MaxIdleConns = "%d", expected "2"`, config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime != time.Minute {
+		t.Errorf(`config.ConnMaxLifetime = "%v", expected "1m0s"`, config.ConnMaxLifetime)
+	}
+	if config.ConnectTimeout != 90*time.Second {
+		t.Errorf(`config.ConnectTimeout = "%v", expected "1m30s"`, config.ConnectTimeout)
+	}
+	if config.QueryTimeout != 3*time.Second {
+		t.Errorf(`config.QueryTimeout = "%v", expected "3s"`, config.QueryTimeout)
+	}
+}
+
+func TestLoadDatabaseConfig_MissingDriver(t *testing.T) {
+	t.Setenv("DATABASE_DRIVER", "")
+	t.Setenv("DATABASE_URL", "postgres://localhost")
+
+	if _, err := LoadDatabaseConfig(); err == nil {
+		t.Error(`LoadDatabaseConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadDatabaseConfig_InvalidDuration(t *testing.T) {
+	t.Setenv("DATABASE_DRIVER", "postgres")
+	t.Setenv("DATABASE_URL", "postgres://localhost")
+	t.Setenv("DATABASE_QUERY_TIMEOUT", "soon")
+
+	if _, err := LoadDatabaseConfig(); err == nil {
+		t.Error(`LoadDatabaseConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadOutboxConfig_Defaults(t *testing.T) {
+	t.Setenv("OUTBOX_WEBHOOK_URL", "")
+
+	config, err := LoadOutboxConfig()
+	if err != nil {
+		t.Fatalf(`LoadOutboxConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.PollInterval != DefaultOutboxPollInterval {
+		t.Errorf(`config.PollInterval = "%v", expected "%v"`, config.PollInterval, DefaultOutboxPollInterval)
+	}
+	if config.BatchSize != DefaultOutboxBatchSize {
+		t.Errorf(`config.BatchSize = "%d", expected "%d"`, config.BatchSize, DefaultOutboxBatchSize)
+	}
+}
+
+func TestLoadOutboxConfig_MissingSecret(t *testing.T) {
+	t.Setenv("OUTBOX_WEBHOOK_URL", "http://localhost/hook")
+	t.Setenv("OUTBOX_WEBHOOK_SECRET", "")
+
+	if _, err := LoadOutboxConfig(); err == nil {
+		t.Error(`LoadOutboxConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadDeletionConfig_Defaults(t *testing.T) {
+	config, err := LoadDeletionConfig()
+	if err != nil {
+		t.Fatalf(`LoadDeletionConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.GracePeriod != DefaultDeletionGracePeriod {
+		t.Errorf(`config.GracePeriod = "%v", expected "%v"`, config.GracePeriod, DefaultDeletionGracePeriod)
+	}
+	if config.ArchiveRetention != DefaultArchiveRetention {
+		t.Errorf(`config.ArchiveRetention = "%v", expected "%v"`, config.ArchiveRetention, DefaultArchiveRetention)
+	}
+}
+
+func TestLoadDeletionConfig_InvalidBatchSize(t *testing.T) {
+	t.Setenv("DELETION_BATCH_SIZE", "0")
+
+	if _, err := LoadDeletionConfig(); err == nil {
+		t.Error(`LoadDeletionConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadDataExportConfig_Defaults(t *testing.T) {
+	config, err := LoadDataExportConfig()
+	if err != nil {
+		t.Fatalf(`LoadDataExportConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.TTL != DefaultDataExportTTL {
+		t.Errorf(`config.TTL = "%v", expected "%v"`, config.TTL, DefaultDataExportTTL)
+	}
+}
+
+func TestLoadDataExportConfig_InvalidTTL(t *testing.T) {
+	t.Setenv("DATA_EXPORT_TTL", "-1h")
+
+	if _, err := LoadDataExportConfig(); err == nil {
+		t.Error(`LoadDataExportConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadDataExportConfig_MissingGameServiceSecret(t *testing.T) {
+	t.Setenv("GAME_SERVICE_URL", "http://localhost:8082")
+	t.Setenv("GAME_SERVICE_SECRET", "")
+
+	if _, err := LoadDataExportConfig(); err == nil {
+		t.Error(`LoadDataExportConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadQuestionBankConfig_Defaults(t *testing.T) {
+	config, err := LoadQuestionBankConfig()
+	if err != nil {
+		t.Fatalf(`LoadQuestionBankConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.RecentWindow != DefaultRecentQuestionWindow {
+		t.Errorf(`config.RecentWindow = "%v", expected "%v"`, config.RecentWindow, DefaultRecentQuestionWindow)
+	}
+}
+
+func TestLoadQuestionBankConfig_ZeroWindow(t *testing.T) {
+	t.Setenv("RECENT_QUESTION_WINDOW", "0s")
+
+	if _, err := LoadQuestionBankConfig(); err == nil {
+		t.Error(`LoadQuestionBankConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadLobbyConfig_Defaults(t *testing.T) {
+	config, err := LoadLobbyConfig()
+	if err != nil {
+		t.Fatalf(`LoadLobbyConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.MaxPlayers != DefaultLobbyMaxPlayers || config.IdleTimeout != DefaultLobbyIdleTimeout {
+		t.Errorf(
+			`config = "%+v", expected "%d" players and "%v" idle timeout`,
+			config,
+			DefaultLobbyMaxPlayers,
+			DefaultLobbyIdleTimeout,
+		)
+	}
+}
+
+func TestLoadLobbyConfig_QuestionTimeLimit(t *testing.T) {
+	t.Setenv("QUESTION_TIME_LIMIT", "30s")
+	t.Setenv("ANSWER_GRACE", "0s")
+
+	config, err := LoadLobbyConfig()
+	if err != nil {
+		t.Fatalf(`LoadLobbyConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.QuestionTimeLimit != 30*time.Second || config.AnswerGrace != 0 {
+		t.Errorf(`config = "%+v", expected a "30s" time limit without grace`, config)
+	}
+
+	t.Setenv("QUESTION_TIME_LIMIT", "0s")
+	if _, err := LoadLobbyConfig(); err == nil {
+		t.Error(`LoadLobbyConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadLobbyConfig_TooFewPlayers(t *testing.T) {
+	t.Setenv("LOBBY_MAX_PLAYERS", "1")
+
+	if _, err := LoadLobbyConfig(); err == nil {
+		t.Error(`LoadLobbyConfig() error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestLoadMatchmakingConfig_Defaults(t *testing.T) {
+	config, err := LoadMatchmakingConfig()
+	if err != nil {
+		t.Fatalf(`LoadMatchmakingConfig() error = "%v", expected "<nil>"`, err)
+	}
+
+	if config.Players != DefaultMatchPlayers || config.MaxWindow != DefaultMatchMaxWindow {
+		t.Errorf(
+			`config = "%+v", expected "%d" players and a "%d" max window`,
+			config,
+			DefaultMatchPlayers,
+			DefaultMatchMaxWindow,
+		)
+	}
+}
+
+func TestLoadMatchmakingConfig_Invalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"one player":             {"MATCH_PLAYERS": "1", "MATCH_MIN_PLAYERS": "1"},
+		"min above players":      {"MATCH_PLAYERS": "2", "MATCH_MIN_PLAYERS": "3"},
+		"max below initial":      {"MATCH_INITIAL_WINDOW": "200", "MATCH_MAX_WINDOW": "100"},
+		"no questions":           {"MATCH_QUESTION_COUNT": "0"},
+		"invalid max wait":       {"MATCH_MAX_WAIT": "soon"},
+		"zero interval":          {"MATCH_INTERVAL": "0s"},
+		"negative window growth": {"MATCH_WINDOW_GROWTH": "-1"},
+	}
+
+	for name, env := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				for key, value := range env {
+					t.Setenv(key, value)
+				}
+				if _, err := LoadMatchmakingConfig(); err == nil {
+					t.Error(`LoadMatchmakingConfig() error = "<nil>", expected non-nil`)
+				}
+			},
+		)
+	}
+}
+
+func TestLoadMailConfig_AppUrlFallback(t *testing.T) {
+	t.Setenv("SMTP_ADDR", "")
+	t.Setenv("APP_URL", "")
+	t.Setenv("BASE_URL", "http://localhost:8080")
+
+	config, err := LoadMailConfig()
+	if err != nil {
+		t.Fatalf(`LoadMailConfig() error = "%v", expected "<nil>"`, err)
+	}
+	if config.AppUrl != "http://localhost:8080" {
+		t.Errorf(`config.AppUrl = "%s", expected "http://localhost:8080"`, config.AppUrl)
+	}
+}
+
+func TestLoadMailConfig_MissingFrom(t *testing.T) {
+	t.Setenv("SMTP_ADDR", "localhost:25")
+	t.Setenv("SMTP_FROM", "")
+	t.Setenv("APP_URL", "http://localhost:8080")
+
+	if _, err := LoadMailConfig(); err == nil {
+		t.Error(`LoadMailConfig() error = "<nil>", expected non-nil`)
+	}
+}
@@ -0,0 +1,258 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookEventHeader     = "X-Quizchief-Event"
+	WebhookEventIdHeader   = "X-Quizchief-Event-Id"
+	WebhookTimestampHeader = "X-Quizchief-Timestamp"
+	WebhookSignatureHeader = "X-Quizchief-Signature"
+)
+
+// DefaultWebhookTimeout Time allowed for a webhook receiver to respond before the delivery counts as failed
+const DefaultWebhookTimeout = 10 * time.Second
+
+// DefaultWebhookTolerance How far a received webhook's timestamp may be from the receiver's clock
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// maxWebhookSize Largest webhook body WebhookAuthenticator reads
+const maxWebhookSize = 1 << 20
+
+// Event Envelope delivered to event sinks. Id is unique per event, so consumers can use it to drop the duplicates
+// that at-least-once delivery allows
+type Event struct {
+	Id         int64           `json:"id"`
+	Type       string          `json:"type"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// EventSink Destination for relayed events. An error means the event was not delivered and will be retried
+type EventSink interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// FanoutSink Publishes each event to every sink, failing if any of them fails. Sinks that already accepted
+// an event receive it again when it is retried
+type FanoutSink []EventSink
+
+// Publish Publish() implementation from EventSink interface
+func (sinks FanoutSink) Publish(ctx context.Context, event *Event) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookSink Delivers events as JSON POST requests signed with HMAC-SHA256 (see SignWebhook). Any response
+// other than 2xx counts as a failed delivery
+type WebhookSink struct {
+	Url    string
+	Secret []byte
+	Client *http.Client
+	// Now Clock used for the signature timestamp
+	Now func() time.Time
+}
+
+// NewWebhookSink Create a webhook sink posting to the specified url
+func NewWebhookSink(url string, secret string) *WebhookSink {
+	return &WebhookSink{
+		Url:    url,
+		Secret: []byte(secret),
+		Client: &http.Client{Timeout: DefaultWebhookTimeout},
+		Now:    time.Now,
+	}
+}
+
+// Publish Publish() implementation from EventSink interface
+func (sink *WebhookSink) Publish(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.Url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, event.Type)
+	request.Header.Set(WebhookEventIdHeader, strconv.FormatInt(event.Id, 10))
+	SignRequest(request, sink.Secret, body, sink.Now())
+
+	response, err := sink.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhook Compute the signature header value for a webhook body: "sha256=" followed by the hex HMAC-SHA256
+// of "<timestamp>.<body>". Including the timestamp lets receivers reject replayed requests
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest Set the timestamp and signature headers of a request to another service, so the receiver can check it
+// with VerifyWebhook, and forward the trace id from the request's context
+func SignRequest(request *http.Request, secret []byte, body []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, body))
+	if traceId := GetTraceId(request.Context()); traceId != "" {
+		request.Header.Set("X-Trace-Id", traceId)
+	}
+}
+
+// VerifyWebhook Check a received webhook's signature, and that its timestamp is within tolerance of now
+func VerifyWebhook(secret []byte, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp := header.Get(WebhookTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook timestamp")
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return errors.New("webhook timestamp outside of tolerance")
+	}
+
+	expected := SignWebhook(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(WebhookSignatureHeader))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
+
+// WebhookAuthenticator Rejects requests whose body is not signed with the secret (see SignRequest) or whose
+// timestamp is outside of DefaultWebhookTolerance, for routes only other services may call
+func WebhookAuthenticator(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+				if err != nil {
+					http.Error(w, "unable to read request body", http.StatusBadRequest)
+					return
+				}
+
+				if err := VerifyWebhook(secret, r.Header, body, time.Now(), DefaultWebhookTolerance); err != nil {
+					http.Error(w, err.Error(), http.StatusUnauthorized)
+					return
+				}
+
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// Publisher Message broker producer in the style of NATS or Kafka clients, publishing raw data to a subject
+// (or topic). Adapters for a concrete broker only need to implement this interface
+type Publisher interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+// PublisherSink Publishes each event as JSON to a broker subject named after the event type
+// (e.g. "user.created"), prefixed with SubjectPrefix if set
+type PublisherSink struct {
+	Publisher     Publisher
+	SubjectPrefix string
+}
+
+// Publish Publish() implementation from EventSink interface
+func (sink *PublisherSink) Publish(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := sink.Publisher.Publish(ctx, sink.SubjectPrefix+event.Type, data); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// Message Message recorded by a MemoryPublisher
+type Message struct {
+	Subject string
+	Data    []byte
+}
+
+// MemoryPublisher In-memory Publisher standing in for a message broker in tests and local runs. Subscribers are
+// called synchronously for every message published to their subject
+type MemoryPublisher struct {
+	mutex       sync.Mutex
+	messages    []Message
+	subscribers map[string][]func(data []byte)
+}
+
+// NewMemoryPublisher Create an in-memory publisher with no subscribers
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{
+		subscribers: map[string][]func(data []byte){},
+	}
+}
+
+// Publish Publish() implementation from Publisher interface
+func (publisher *MemoryPublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	publisher.mutex.Lock()
+	data = append([]byte(nil), data...)
+	publisher.messages = append(publisher.messages, Message{Subject: subject, Data: data})
+	subscribers := append([](func(data []byte)){}, publisher.subscribers[subject]...)
+	publisher.mutex.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(data)
+	}
+	return nil
+}
+
+// Subscribe Register a handler called with the data of each message published to the subject
+func (publisher *MemoryPublisher) Subscribe(subject string, handler func(data []byte)) {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.subscribers[subject] = append(publisher.subscribers[subject], handler)
+}
+
+// Messages Get a copy of every message published so far, in order
+func (publisher *MemoryPublisher) Messages() []Message {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	return append([]Message(nil), publisher.messages...)
+}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookSink_Publish(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var received Event
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if err := VerifyWebhook(secret, r.Header, body, now, time.Minute); err != nil {
+					t.Errorf(`VerifyWebhook(...) error = "%v", expected "<nil>"`, err)
+				}
+				if eventType := r.Header.Get(WebhookEventHeader); eventType != "user.created" {
+					t.Errorf(`event header = "%s", expected "user.created"`, eventType)
+				}
+				_ = json.Unmarshal(body, &received)
+				w.WriteHeader(http.StatusNoContent)
+			},
+		),
+	)
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, string(secret))
+	sink.Now = func() time.Time {
+		return now
+	}
+
+	event := Event{Id: 7, Type: "user.created", Source: "user", OccurredAt: now, Data: json.RawMessage(`{"userId":1}`)}
+	if err := sink.Publish(context.Background(), &event); err != nil {
+		t.Fatalf(`sink.Publish(...) error = "%v", expected "<nil>"`, err)
+	}
+	if received.Id != event.Id || string(received.Data) != string(event.Data) {
+		t.Errorf(`received = "%+v", expected "%+v"`, received, event)
+	}
+}
+
+func TestWebhookSink_Publish_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		),
+	)
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, "secret")
+	if err := sink.Publish(context.Background(), &Event{Type: "user.created"}); err == nil {
+		t.Error(`sink.Publish(...) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestVerifyWebhook_Failures(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+
+	valid := http.Header{}
+	valid.Set(WebhookTimestampHeader, "1700000000")
+	valid.Set(WebhookSignatureHeader, SignWebhook(secret, "1700000000", body))
+	if err := VerifyWebhook(secret, valid, body, now, time.Minute); err != nil {
+		t.Fatalf(`VerifyWebhook(valid) error = "%v", expected "<nil>"`, err)
+	}
+
+	tests := []struct {
+		name   string
+		secret []byte
+		body   []byte
+		now    time.Time
+	}{
+		{"wrong secret", []byte("other"), body, now},
+		{"tampered body", secret, []byte(`{"id":2}`), now},
+		{"stale timestamp", secret, body, now.Add(time.Hour)},
+	}
+
+	for _, test := range tests {
+		if err := VerifyWebhook(test.secret, valid, test.body, test.now, time.Minute); err == nil {
+			t.Errorf(`VerifyWebhook(%s) error = "<nil>", expected non-nil`, test.name)
+		}
+	}
+}
+
+func TestPublisherSink_Publish(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	var delivered []byte
+	publisher.Subscribe(
+		"events.user.deleted", func(data []byte) {
+			delivered = data
+		},
+	)
+
+	sink := &PublisherSink{Publisher: publisher, SubjectPrefix: "events."}
+	if err := sink.Publish(context.Background(), &Event{Id: 1, Type: "user.deleted"}); err != nil {
+		t.Fatalf(`sink.Publish(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	messages := publisher.Messages()
+	if len(messages) != 1 || messages[0].Subject != "events.user.deleted" {
+		t.Errorf(`messages = "%+v", expected one message on "events.user.deleted"`, messages)
+	}
+	if delivered == nil {
+		t.Error(`subscriber was not called`)
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Publish(ctx context.Context, event *Event) error {
+	return errors.New("unavailable")
+}
+
+func TestFanoutSink_Publish(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	sink := FanoutSink{&PublisherSink{Publisher: publisher}, failingSink{}}
+
+	if err := sink.Publish(context.Background(), &Event{Type: "user.updated"}); err == nil {
+		t.Error(`sink.Publish(...) error = "<nil>", expected non-nil`)
+	}
+	if messages := publisher.Messages(); len(messages) != 1 {
+		t.Errorf(`len(messages) = "%d", expected "1"`, len(messages))
+	}
+}
+
+func TestWebhookAuthenticator(t *testing.T) {
+	secret := []byte("secret")
+	handler := WebhookAuthenticator(secret)(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				_, _ = w.Write(body)
+			},
+		),
+	)
+
+	tests := []struct {
+		name           string
+		secret         []byte
+		now            time.Time
+		expectedStatus int
+	}{
+		{name: "valid", secret: secret, now: time.Now(), expectedStatus: http.StatusOK},
+		{name: "wrong secret", secret: []byte("other"), now: time.Now(), expectedStatus: http.StatusUnauthorized},
+		{name: "stale", secret: secret, now: time.Now().Add(-time.Hour), expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		body := `{"userId":1}`
+		request := httptest.NewRequest(http.MethodPost, "/internal", strings.NewReader(body))
+		SignRequest(request, test.secret, []byte(body), test.now)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expectedStatus {
+			t.Errorf(`recorder.Code = "%v" for %s, expected "%v"`, recorder.Code, test.name, test.expectedStatus)
+		}
+		if test.expectedStatus == http.StatusOK && recorder.Body.String() != body {
+			t.Errorf(`recorder.Body = "%s", expected "%s"`, recorder.Body.String(), body)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"common/userpb"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// traceIdMetadataKey gRPC metadata key carrying the trace id between services
+const traceIdMetadataKey = "x-trace-id"
+
+// DialUserService Create a gRPC client for the user service at the specified address (e.g. "user:9090"),
+// along with the connection that must be closed once the client is no longer needed
+func DialUserService(target string, options ...grpc.DialOption) (userpb.UserServiceClient, *grpc.ClientConn, error) {
+	options = append(
+		[]grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(TraceIdClientInterceptor),
+		},
+		options...,
+	)
+
+	connection, err := grpc.NewClient(target, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return userpb.NewUserServiceClient(connection), connection, nil
+}
+
+// TraceIdClientInterceptor Forwards the trace id from the context to the called service
+func TraceIdClientInterceptor(
+	ctx context.Context,
+	method string,
+	request any,
+	reply any,
+	connection *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	options ...grpc.CallOption,
+) error {
+	if traceId := GetTraceId(ctx); traceId != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, traceIdMetadataKey, traceId)
+	}
+	return invoker(ctx, method, request, reply, connection, options...)
+}
+
+// UnaryServerLogger Logs each gRPC call with the specified logger once it has been served, picking up the
+// caller's trace id. It is the gRPC counterpart of RequestLogger
+func UnaryServerLogger(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		request any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(traceIdMetadataKey); len(values) > 0 && values[0] != "" {
+				ctx = WithTraceId(ctx, values[0])
+			}
+		}
+
+		start := time.Now()
+		response, err := handler(ctx, request)
+		code := status.Code(err)
+
+		level := slog.LevelWarn
+		switch code {
+		case codes.OK:
+			level = slog.LevelInfo
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			level = slog.LevelError
+		}
+
+		logger.LogAttrs(
+			ctx,
+			level,
+			"call served",
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return response, err
+	}
+}
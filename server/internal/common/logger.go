@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+type traceIdKey struct{}
+
+// requestUserKey Key of the holder RequestLogger puts in the context, which WithUserClaims records the request's user
+// in. Claims stored further down the middleware chain are otherwise out of reach of the access log line
+type requestUserKey struct{}
+
+// NewLogger Create a logger configured by the LOG_LEVEL and LOG_FORMAT environment variables
+func NewLogger() *slog.Logger {
+	return NewLoggerWithWriter(os.Stdout, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+}
+
+// NewLoggerWithWriter Create a logger writing to the specified writer with the specified level and format
+func NewLoggerWithWriter(writer io.Writer, level string, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: ParseLogLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(writer, options)
+	} else {
+		handler = slog.NewTextHandler(writer, options)
+	}
+
+	return slog.New(&ContextHandler{Handler: handler})
+}
+
+// ParseLogLevel Convert a level name (debug, info, warn, error) to a slog.Level, defaulting to info
+func ParseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ContextHandler slog.Handler that attaches request-scoped values from the context to each record
+type ContextHandler struct {
+	slog.Handler
+}
+
+// Handle Handle() implementation from slog.Handler interface
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		record.AddAttrs(contextAttrs(ctx)...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs WithAttrs() implementation from slog.Handler interface
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup WithGroup() implementation from slog.Handler interface
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// contextAttrs Collect the request id, route pattern, user id and trace id stored in the context
+func contextAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+
+	if requestId := middleware.GetReqID(ctx); requestId != "" {
+		attrs = append(attrs, slog.String("request_id", requestId))
+	}
+
+	if routeContext := chi.RouteContext(ctx); routeContext != nil {
+		if routePattern := routeContext.RoutePattern(); routePattern != "" {
+			attrs = append(attrs, slog.String("route", routePattern))
+		}
+	}
+
+	if claims, ok := GetUserClaims(ctx); ok {
+		attrs = append(attrs, slog.Int("user_id", claims.ID))
+	} else if holder, ok := ctx.Value(requestUserKey{}).(*atomic.Pointer[UserClaims]); ok && holder.Load() != nil {
+		attrs = append(attrs, slog.Int("user_id", holder.Load().ID))
+	}
+
+	if traceId := GetTraceId(ctx); traceId != "" {
+		attrs = append(attrs, slog.String("trace_id", traceId))
+	}
+
+	return attrs
+}
+
+// WithUserClaims Return a copy of the context carrying the user claims, recording them for the request's access log
+func WithUserClaims(ctx context.Context, claims *UserClaims) context.Context {
+	if holder, ok := ctx.Value(requestUserKey{}).(*atomic.Pointer[UserClaims]); ok {
+		holder.Store(claims)
+	}
+	return context.WithValue(ctx, UsersClaimKey, claims)
+}
+
+// GetUserClaims Get the user claims stored in the context by AuthMiddleware
+func GetUserClaims(ctx context.Context) (*UserClaims, bool) {
+	switch claims := ctx.Value(UsersClaimKey).(type) {
+	case *UserClaims:
+		return claims, claims != nil
+	case UserClaims:
+		return &claims, true
+	default:
+		return nil, false
+	}
+}
+
+// WithTraceId Return a copy of the context carrying the specified trace id
+func WithTraceId(ctx context.Context, traceId string) context.Context {
+	return context.WithValue(ctx, traceIdKey{}, traceId)
+}
+
+// GetTraceId Get the trace id stored in the context
+func GetTraceId(ctx context.Context) string {
+	traceId, _ := ctx.Value(traceIdKey{}).(string)
+	return traceId
+}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"bogus":   slog.LevelInfo,
+	}
+
+	for input, expected := range tests {
+		if actual := ParseLogLevel(input); actual != expected {
+			t.Errorf(`ParseLogLevel("%s") = "%v", expected "%v"`, input, actual, expected)
+		}
+	}
+}
+
+func TestNewLoggerWithWriter_LevelFiltering(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter(&buffer, "warn", "json")
+
+	logger.Info("should be dropped")
+	if buffer.Len() != 0 {
+		t.Errorf(`buffer.Len() = "%d", expected "0"`, buffer.Len())
+	}
+
+	logger.Warn("should be written")
+	if buffer.Len() == 0 {
+		t.Error(`buffer.Len() = "0", expected non-zero`)
+	}
+}
+
+func TestContextHandler_AttachesRequestAttributes(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter(&buffer, "debug", "json")
+
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "request-1")
+	ctx = context.WithValue(ctx, UsersClaimKey, &UserClaims{ID: 7})
+	ctx = WithTraceId(ctx, "trace-1")
+	routeContext := chi.NewRouteContext()
+	routeContext.RoutePatterns = []string{"/user/{id}"}
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+
+	logger.InfoContext(ctx, "message")
+
+	var record map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf(`json.Unmarshal(buffer.Bytes(), &record) = "%v", expected "<nil>"`, err)
+	}
+
+	expected := map[string]any{
+		"request_id": "request-1",
+		"route":      "/user/{id}",
+		"user_id":    float64(7),
+		"trace_id":   "trace-1",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf(`record["%s"] = "%v", expected "%v"`, key, record[key], value)
+		}
+	}
+}
+
+func TestGetUserClaims_Value(t *testing.T) {
+	ctx := context.WithValue(context.Background(), UsersClaimKey, UserClaims{ID: 3})
+
+	claims, ok := GetUserClaims(ctx)
+	if !ok {
+		t.Fatal(`GetUserClaims(ctx) ok = "false", expected "true"`)
+	}
+	if claims.ID != 3 {
+		t.Errorf(`claims.ID = "%d", expected "3"`, claims.ID)
+	}
+}
+
+func TestGetUserClaims_Missing(t *testing.T) {
+	if _, ok := GetUserClaims(context.Background()); ok {
+		t.Error(`GetUserClaims(context.Background()) ok = "true", expected "false"`)
+	}
+}
+
+func TestRequestLogger_LogsRequest(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter(&buffer, "info", "json")
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(RequestLogger(logger))
+	router.Get(
+		"/user/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		},
+	)
+
+	request := httptest.NewRequest(http.MethodGet, "/user/1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var record map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf(`json.Unmarshal(buffer.Bytes(), &record) = "%v", expected "<nil>"`, err)
+	}
+
+	if record["level"] != "WARN" {
+		t.Errorf(`record["level"] = "%v", expected "WARN"`, record["level"])
+	}
+	if record["status"] != float64(http.StatusTeapot) {
+		t.Errorf(`record["status"] = "%v", expected "%d"`, record["status"], http.StatusTeapot)
+	}
+	if record["route"] != "/user/{id}" {
+		t.Errorf(`record["route"] = "%v", expected "/user/{id}"`, record["route"])
+	}
+	if record["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf(`record["trace_id"] = "%v", expected "4bf92f3577b34da6a3ce929d0e0e4736"`, record["trace_id"])
+	}
+	if record["request_id"] == nil {
+		t.Error(`record["request_id"] = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRequestLogger_LogsAuthenticatedUser(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLoggerWithWriter(&buffer, "info", "json")
+
+	router := chi.NewRouter()
+	router.Use(RequestLogger(logger))
+	router.Group(
+		func(router chi.Router) {
+			router.Use(
+				func(next http.Handler) http.Handler {
+					return http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							next.ServeHTTP(w, r.WithContext(WithUserClaims(r.Context(), &UserClaims{ID: 7})))
+						},
+					)
+				},
+			)
+			router.Get(
+				"/user/me", func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				},
+			)
+		},
+	)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user/me", nil))
+
+	var record map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf(`json.Unmarshal(buffer.Bytes(), &record) = "%v", expected "<nil>"`, err)
+	}
+
+	if record["user_id"] != float64(7) {
+		t.Errorf(`record["user_id"] = "%v", expected "7"`, record["user_id"])
+	}
+}
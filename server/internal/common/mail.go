@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MailMessage Plain text email
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer Sends emails to users
+type Mailer interface {
+	Send(ctx context.Context, message *MailMessage) error
+}
+
+// NewMailer Create the mailer for the mail configuration: SMTP when a server is configured, otherwise a LogMailer
+func NewMailer(config *MailConfig, logger *slog.Logger) Mailer {
+	if config.SMTPAddr == "" {
+		return &LogMailer{Logger: logger}
+	}
+
+	mailer := &SMTPMailer{
+		Addr: config.SMTPAddr,
+		From: config.From,
+	}
+	if config.Username != "" {
+		host, _, _ := net.SplitHostPort(config.SMTPAddr)
+		mailer.Auth = smtp.PlainAuth("", config.Username, config.Password, host)
+	}
+	return mailer
+}
+
+// SMTPMailer Mailer sending through an SMTP server, using STARTTLS when the server offers it
+type SMTPMailer struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// Send Send() implementation from Mailer interface
+func (mailer *SMTPMailer) Send(ctx context.Context, message *MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(mailer.Addr, mailer.Auth, mailer.From, []string{message.To}, formatMail(mailer.From, message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// formatMail Render a message as an RFC 5322 email
+func formatMail(from string, message *MailMessage) []byte {
+	var builder strings.Builder
+	builder.WriteString("From: " + from + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + message.Subject + "\r\n")
+	builder.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(builder.String())
+}
+
+// LogMailer Mailer writing each message to the log instead of sending it, for local runs without an SMTP server.
+// Messages may contain secrets such as confirmation links, so it must not be used in production
+type LogMailer struct {
+	Logger *slog.Logger
+}
+
+// Send Send() implementation from Mailer interface
+func (mailer *LogMailer) Send(ctx context.Context, message *MailMessage) error {
+	mailer.Logger.InfoContext(
+		ctx,
+		"mail not sent, no SMTP server configured",
+		slog.String("to", message.To),
+		slog.String("subject", message.Subject),
+		slog.String("body", message.Body),
+	)
+	return nil
+}
+
+// MemoryMailer Mailer recording messages in memory for tests
+type MemoryMailer struct {
+	mutex    sync.Mutex
+	messages []MailMessage
+}
+
+// Send Send() implementation from Mailer interface
+func (mailer *MemoryMailer) Send(ctx context.Context, message *MailMessage) error {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	mailer.messages = append(mailer.messages, *message)
+	return nil
+}
+
+// Messages Get a copy of every message sent so far, in order
+func (mailer *MemoryMailer) Messages() []MailMessage {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	return append([]MailMessage(nil), mailer.messages...)
+}
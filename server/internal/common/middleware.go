@@ -1,22 +1,40 @@
 package common
 
 import (
+	"common/userpb"
 	"context"
-	"encoding/json"
 	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/jwtauth/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
 	"net/http"
-	"user/db/generated"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
-// AuthMiddleware Validates JWT and extracts claims
-func AuthMiddleware(queries db.Queries) func(http.Handler) http.Handler {
+// UserGetter Retrieves users from the user service, which userpb.UserServiceClient implements
+type UserGetter interface {
+	GetUser(
+		ctx context.Context,
+		in *userpb.GetUserRequest,
+		opts ...grpc.CallOption,
+	) (*userpb.GetUserResponse, error)
+}
+
+// AuthMiddleware Validates JWT and extracts claims. It must follow jwtauth.Verifier and jwtauth.Authenticator, and
+// rejects tokens of users who no longer exist or whose username or email has changed. When users is nil, the token
+// is trusted on its own
+func AuthMiddleware(users UserGetter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
 				ctx := r.Context()
-				_, claimsMap, err := jwtauth.FromContext(ctx)
-				if err != nil {
+				token, _, err := jwtauth.FromContext(ctx)
+				if err != nil || token == nil {
 					http.Error(
 						w,
 						fmt.Sprintf("unable to get claims map from context: %v", err),
@@ -25,58 +43,111 @@ func AuthMiddleware(queries db.Queries) func(http.Handler) http.Handler {
 					return
 				}
 
-				claims := UserClaims{
-					ID:       claimsMap["user_id"].(int),
-					Username: claimsMap["username"].(string),
-					Email:    claimsMap["email"].(string),
-				}
-
-				statusCode, user, err := getUser(ctx, claims.ID)
+				claims, err := getTokenClaims(token)
 				if err != nil {
-					http.Error(w, fmt.Sprintf("unable to get user: %v", err), http.StatusInternalServerError)
-					return
-				} else if statusCode != http.StatusOK {
-					http.Error(w, "invalid user", http.StatusUnauthorized)
+					http.Error(w, err.Error(), http.StatusUnauthorized)
 					return
 				}
 
-				if user.Username != claims.Username {
-					http.Error(w, "invalid username", http.StatusUnauthorized)
-					return
-				} else if user.Email != claims.Email {
-					http.Error(w, "invalid email", http.StatusUnauthorized)
-					return
+				if users != nil {
+					response, err := users.GetUser(
+						ctx, &userpb.GetUserRequest{Lookup: &userpb.GetUserRequest_Id{Id: int64(claims.ID)}},
+					)
+					if status.Code(err) == codes.NotFound {
+						http.Error(w, "invalid user", http.StatusUnauthorized)
+						return
+					} else if err != nil {
+						http.Error(w, fmt.Sprintf("unable to get user: %v", err), http.StatusInternalServerError)
+						return
+					}
+
+					if response.GetUser().GetUsername() != claims.Username {
+						http.Error(w, "invalid username", http.StatusUnauthorized)
+						return
+					} else if response.GetUser().GetEmail() != claims.Email {
+						http.Error(w, "invalid email", http.StatusUnauthorized)
+						return
+					}
 				}
 
-				ctx = context.WithValue(ctx, UsersClaimKey, claims)
+				ctx = WithUserClaims(ctx, claims)
 				next.ServeHTTP(w, r.WithContext(ctx))
 			},
 		)
 	}
 }
 
-// getUser Retrieve the user with the specified ID
-func getUser(ctx context.Context, userId int) (int, *getUserResponse, error) {
-	getUserUrl, err := GetBaseUrl()
-	if err != nil {
-		return -1, nil, fmt.Errorf("unable to get base url: %w", err)
-	}
+// AdminOnly Rejects requests of users whose token does not grant the admin role. It must follow AuthMiddleware
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetUserClaims(r.Context())
+			if !ok {
+				http.Error(w, "user claims not found", http.StatusUnauthorized)
+				return
+			}
 
-	getUserResp, err := http.Get(fmt.Sprintf("%s/user?id=%d", getUserUrl, userId))
-	if err != nil {
-		return -1, nil, fmt.Errorf("unable to get get user response: %w", err)
-	}
-	defer getUserResp.Body.Close()
+			if !claims.Admin {
+				http.Error(w, "admin role required", http.StatusForbidden)
+				return
+			}
 
-	statusCode := getUserResp.StatusCode
-	if statusCode != http.StatusOK {
-		return statusCode, nil, nil
-	}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
 
-	var getUserResponse getUserResponse
-	if err := json.NewDecoder(getUserResp.Body).Decode(&getUserResponse); err != nil {
-		return statusCode, nil, fmt.Errorf("unable to parse get user response: %w", err)
+// RequestLogger Logs each request with the specified logger once it has been served, including the user that
+// authentication further down the chain identified
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ctx := context.WithValue(r.Context(), requestUserKey{}, &atomic.Pointer[UserClaims]{})
+				if traceId := getTraceIdFromHeaders(r.Header); traceId != "" {
+					ctx = WithTraceId(ctx, traceId)
+				}
+				r = r.WithContext(ctx)
+
+				wrappedWriter := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+				start := time.Now()
+				next.ServeHTTP(wrappedWriter, r)
+
+				status := wrappedWriter.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				level := slog.LevelInfo
+				if status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				} else if status >= http.StatusBadRequest {
+					level = slog.LevelWarn
+				}
+
+				logger.LogAttrs(
+					ctx,
+					level,
+					"request served",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.Int("status", status),
+					slog.Int("bytes", wrappedWriter.BytesWritten()),
+					slog.Duration("duration", time.Since(start)),
+				)
+			},
+		)
 	}
+}
 
-	return statusCode, &getUserResponse, nil
+// getTraceIdFromHeaders Extract the trace id from a W3C traceparent header, falling back to X-Trace-Id
+func getTraceIdFromHeaders(header http.Header) string {
+	if traceParent := header.Get("traceparent"); traceParent != "" {
+		parts := strings.Split(traceParent, "-")
+		if len(parts) == 4 && len(parts[1]) == 32 {
+			return parts[1]
+		}
+	}
+	return header.Get("X-Trace-Id")
 }
@@ -0,0 +1,145 @@
+package common
+
+import (
+	"common/userpb"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockUserGetter struct {
+	users map[int64]*userpb.User
+	err   error
+}
+
+// GetUser GetUser() implementation from UserGetter interface
+func (getter *mockUserGetter) GetUser(
+	ctx context.Context,
+	in *userpb.GetUserRequest,
+	opts ...grpc.CallOption,
+) (*userpb.GetUserResponse, error) {
+	if getter.err != nil {
+		return nil, getter.err
+	}
+	user, ok := getter.users[in.GetId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &userpb.GetUserResponse{User: user}, nil
+}
+
+// serveAuthenticated Serve a request with the token through the auth middleware, returning the status and the claims
+// the handler saw
+func serveAuthenticated(users UserGetter, token string) (int, *UserClaims) {
+	var claims *UserClaims
+	router := chi.NewRouter()
+	router.Use(jwtauth.Verifier(TokenAuth))
+	router.Use(jwtauth.Authenticator(TokenAuth))
+	router.Use(AuthMiddleware(users))
+	router.Get(
+		"/quiz", func(w http.ResponseWriter, r *http.Request) {
+			claims, _ = GetUserClaims(r.Context())
+			w.WriteHeader(http.StatusOK)
+		},
+	)
+
+	request := httptest.NewRequest(http.MethodGet, "/quiz", nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code, claims
+}
+
+func TestAuthMiddleware_Success(t *testing.T) {
+	TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	_, token, _ := TokenAuth.Encode(map[string]any{"user_id": 7, "username": "player", "email": "player@example.com"})
+	users := &mockUserGetter{users: map[int64]*userpb.User{7: {Id: 7, Username: "player", Email: "player@example.com"}}}
+
+	for _, getter := range []UserGetter{users, nil} {
+		code, claims := serveAuthenticated(getter, token)
+		if code != http.StatusOK {
+			t.Fatalf(`code = "%v", expected "%v"`, code, http.StatusOK)
+		}
+
+		expected := UserClaims{ID: 7, Username: "player", Email: "player@example.com"}
+		if claims == nil || *claims != expected {
+			t.Errorf(`claims = "%+v", expected "%+v"`, claims, expected)
+		}
+	}
+}
+
+func TestAuthMiddleware_Failures(t *testing.T) {
+	TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	_, token, _ := TokenAuth.Encode(map[string]any{"user_id": 7, "username": "player", "email": "player@example.com"})
+	_, otherKey, _ := jwtauth.New("HS256", []byte("other"), nil).Encode(map[string]any{"user_id": 7})
+	_, noUser, _ := TokenAuth.Encode(map[string]any{"email": "player@example.com"})
+
+	tests := []struct {
+		name     string
+		users    UserGetter
+		token    string
+		expected int
+	}{
+		{name: "no token", token: "", expected: http.StatusUnauthorized},
+		{name: "wrong key", token: otherKey, expected: http.StatusUnauthorized},
+		{name: "no user", token: noUser, expected: http.StatusUnauthorized},
+		{name: "deleted user", users: &mockUserGetter{}, token: token, expected: http.StatusUnauthorized},
+		{
+			name: "changed email",
+			users: &mockUserGetter{
+				users: map[int64]*userpb.User{7: {Id: 7, Username: "player", Email: "other@example.com"}},
+			},
+			token:    token,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "user service error",
+			users:    &mockUserGetter{err: errors.New("unavailable")},
+			token:    token,
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		if code, _ := serveAuthenticated(test.users, test.token); code != test.expected {
+			t.Errorf(`code = "%v" for %s, expected "%v"`, code, test.name, test.expected)
+		}
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	_, admin, _ := TokenAuth.Encode(map[string]any{"user_id": 7, "username": "admin", "admin": true})
+	_, player, _ := TokenAuth.Encode(map[string]any{"user_id": 8, "username": "player"})
+
+	router := chi.NewRouter()
+	router.Use(jwtauth.Verifier(TokenAuth))
+	router.Use(jwtauth.Authenticator(TokenAuth))
+	router.Use(AuthMiddleware(nil))
+	router.Use(AdminOnly)
+	router.Get(
+		"/user/export", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		},
+	)
+
+	for token, expected := range map[string]int{admin: http.StatusOK, player: http.StatusForbidden} {
+		request := httptest.NewRequest(http.MethodGet, "/user/export", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != expected {
+			t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, expected)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"io"
+	"io/fs"
+)
+
+const (
+	MigrateUp      = "up"
+	MigrateDown    = "down"
+	MigrateStatus  = "status"
+	MigrateVersion = "version"
+)
+
+// DefaultMigrationTable Table goose records applied migrations in, used by the user service
+const DefaultMigrationTable = "goose_db_version"
+
+// RunMigrations Run a migrate command (up, down, status, version) against the database using the specified
+// migrations. On Postgres, commands that change the schema hold an advisory lock so concurrent replicas do not race
+func RunMigrations(
+	ctx context.Context,
+	database *sql.DB,
+	driver string,
+	migrations fs.FS,
+	command string,
+	output io.Writer,
+) error {
+	return RunServiceMigrations(ctx, database, driver, migrations, DefaultMigrationTable, command, output)
+}
+
+// RunServiceMigrations Run a migrate command like RunMigrations, recording applied migrations in the specified table
+// so services sharing a database keep separate migration versions
+func RunServiceMigrations(
+	ctx context.Context,
+	database *sql.DB,
+	driver string,
+	migrations fs.FS,
+	table string,
+	command string,
+	output io.Writer,
+) error {
+	options := []goose.ProviderOption{goose.WithTableName(table)}
+	var dialect goose.Dialect
+	switch driver {
+	case DriverPostgres:
+		sessionLocker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return fmt.Errorf("failed to create migration lock: %w", err)
+		}
+		dialect = goose.DialectPostgres
+		options = append(options, goose.WithSessionLocker(sessionLocker))
+	case DriverSQLite:
+		dialect = goose.DialectSQLite3
+	default:
+		return fmt.Errorf("migrations are not supported for database driver %q", driver)
+	}
+
+	provider, err := goose.NewProvider(dialect, database, migrations, options...)
+	if err != nil {
+		return fmt.Errorf("failed to create migration provider: %w", err)
+	}
+
+	switch command {
+	case MigrateUp:
+		results, err := provider.Up(ctx)
+		printMigrationResults(output, results)
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	case MigrateDown:
+		result, err := provider.Down(ctx)
+		if result != nil {
+			printMigrationResults(output, []*goose.MigrationResult{result})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration: %w", err)
+		}
+	case MigrateStatus:
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "Pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			_, _ = fmt.Fprintf(output, "%-20s %s\n", appliedAt, status.Source.Path)
+		}
+	case MigrateVersion:
+		version, err := provider.GetDBVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get database version: %w", err)
+		}
+		_, _ = fmt.Fprintf(output, "version %d\n", version)
+	default:
+		return ValidateMigrateCommand(command)
+	}
+
+	return nil
+}
+
+// ValidateMigrateCommand Check that the command is one of the supported migrate commands
+func ValidateMigrateCommand(command string) error {
+	switch command {
+	case MigrateUp, MigrateDown, MigrateStatus, MigrateVersion:
+		return nil
+	default:
+		return fmt.Errorf(
+			"unknown migrate command %q, expected one of %s, %s, %s, %s",
+			command,
+			MigrateUp,
+			MigrateDown,
+			MigrateStatus,
+			MigrateVersion,
+		)
+	}
+}
+
+// printMigrationResults Write one line per applied or rolled back migration
+func printMigrationResults(output io.Writer, results []*goose.MigrationResult) {
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(output, "no migrations to run")
+		return
+	}
+	for _, result := range results {
+		_, _ = fmt.Fprintln(output, result.String())
+	}
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+func TestValidateMigrateCommand_Success(t *testing.T) {
+	for _, command := range []string{MigrateUp, MigrateDown, MigrateStatus, MigrateVersion} {
+		if err := ValidateMigrateCommand(command); err != nil {
+			t.Errorf(`ValidateMigrateCommand("%s") = "%v", expected "<nil>"`, command, err)
+		}
+	}
+}
+
+func TestValidateMigrateCommand_UnknownCommand(t *testing.T) {
+	if err := ValidateMigrateCommand("redo"); err == nil {
+		t.Error(`ValidateMigrateCommand("redo") = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRunServiceMigrations_SeparateTables(t *testing.T) {
+	database := newTestDatabase(t)
+	migrations := map[string]fstest.MapFS{
+		"users_migrations": {
+			"00001_create_users.sql": {
+				Data: []byte("-- +goose Up\nCREATE TABLE users (id INTEGER);\n\n-- +goose Down\nDROP TABLE users;\n"),
+			},
+		},
+		"quiz_migrations": {
+			"00001_create_quizzes.sql": {
+				Data: []byte("-- +goose Up\nCREATE TABLE quizzes (id INTEGER);\n\n-- +goose Down\nDROP TABLE quizzes;\n"),
+			},
+		},
+	}
+
+	for table, files := range migrations {
+		err := RunServiceMigrations(context.Background(), database, DriverSQLite, files, table, MigrateUp, io.Discard)
+		if err != nil {
+			t.Fatalf(`RunServiceMigrations(..., "%s", ...) error = "%v", expected "<nil>"`, table, err)
+		}
+	}
+
+	for _, table := range []string{"users", "quizzes"} {
+		if _, err := database.Exec("SELECT COUNT(*) FROM " + table); err != nil {
+			t.Errorf(`table "%s" error = "%v", expected it to be created`, table, err)
+		}
+	}
+}
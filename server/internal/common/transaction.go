@@ -0,0 +1,89 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"log/slog"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"time"
+)
+
+const (
+	DefaultTxMaxRetries = 3
+	txRetryBackoff      = 10 * time.Millisecond
+)
+
+// RunInTx Run fn inside a database transaction, committing if it succeeds and rolling back if it fails.
+// Serialization failures and deadlocks are retried up to maxRetries times with backoff
+func RunInTx(
+	ctx context.Context,
+	database *sql.DB,
+	options *sql.TxOptions,
+	maxRetries int,
+	fn func(tx *sql.Tx) error,
+) error {
+	backoff := txRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := runInTxOnce(ctx, database, options, fn)
+		if err == nil || !IsSerializationFailure(err) || attempt >= maxRetries {
+			return err
+		}
+
+		slog.WarnContext(
+			ctx,
+			"retrying transaction after serialization failure",
+			slog.Int("attempt", attempt+1),
+			slog.Any("error", err),
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// runInTxOnce Run fn inside a single transaction attempt
+func runInTxOnce(ctx context.Context, database *sql.DB, options *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := database.BeginTx(ctx, options)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// IsSerializationFailure Check whether the error is a transient conflict that is safe to retry: a Postgres
+// serialization failure or deadlock, or a busy/locked SQLite database
+func IsSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code() & 0xff
+		return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"testing"
+	"time"
+)
+
+func newTestDatabase(t *testing.T) *sql.DB {
+	config := &DatabaseConfig{
+		Driver:         DriverSQLite,
+		Url:            ":memory:",
+		ConnectTimeout: time.Second,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+
+	database, err := OpenDatabase(context.Background(), config)
+	if err != nil {
+		t.Fatalf(`OpenDatabase(...) error = "%v", expected "<nil>"`, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = database.Close()
+		},
+	)
+
+	if _, err := database.Exec("CREATE TABLE counter (value INTEGER NOT NULL)"); err != nil {
+		t.Fatalf(`database.Exec(...) error = "%v", expected "<nil>"`, err)
+	}
+	return database
+}
+
+func countRows(t *testing.T, database *sql.DB) int {
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM counter").Scan(&count); err != nil {
+		t.Fatalf(`database.QueryRow(...).Scan(&count) error = "%v", expected "<nil>"`, err)
+	}
+	return count
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&pq.Error{Code: "40001"}, true},
+		{&pq.Error{Code: "40P01"}, true},
+		{fmt.Errorf("wrapped: %w", &pq.Error{Code: "40001"}), true},
+		{&pq.Error{Code: "23505"}, false},
+		{errors.New("serialization failure"), false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		if actual := IsSerializationFailure(test.err); actual != test.expected {
+			t.Errorf(`IsSerializationFailure(%v) = "%v", expected "%v"`, test.err, actual, test.expected)
+		}
+	}
+}
+
+func TestRunInTx_Commit(t *testing.T) {
+	database := newTestDatabase(t)
+
+	err := RunInTx(
+		context.Background(), database, nil, DefaultTxMaxRetries, func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO counter (value) VALUES (1)")
+			return err
+		},
+	)
+	if err != nil {
+		t.Fatalf(`RunInTx(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if count := countRows(t, database); count != 1 {
+		t.Errorf(`count = "%d", expected "1"`, count)
+	}
+}
+
+func TestRunInTx_Rollback(t *testing.T) {
+	database := newTestDatabase(t)
+	expected := errors.New("rollback")
+
+	err := RunInTx(
+		context.Background(), database, nil, DefaultTxMaxRetries, func(tx *sql.Tx) error {
+			if _, err := tx.Exec("INSERT INTO counter (value) VALUES (1)"); err != nil {
+				return err
+			}
+			return expected
+		},
+	)
+	if !errors.Is(err, expected) {
+		t.Errorf(`RunInTx(...) error = "%v", expected "%v"`, err, expected)
+	}
+
+	if count := countRows(t, database); count != 0 {
+		t.Errorf(`count = "%d", expected "0"`, count)
+	}
+}
+
+func TestRunInTx_RetriesSerializationFailure(t *testing.T) {
+	database := newTestDatabase(t)
+
+	attempts := 0
+	err := RunInTx(
+		context.Background(), database, nil, DefaultTxMaxRetries, func(tx *sql.Tx) error {
+			attempts++
+			if _, err := tx.Exec("INSERT INTO counter (value) VALUES (?)", attempts); err != nil {
+				return err
+			}
+			if attempts < 3 {
+				return &pq.Error{Code: "40001"}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf(`RunInTx(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if attempts != 3 {
+		t.Errorf(`attempts = "%d", expected "3"`, attempts)
+	}
+	if count := countRows(t, database); count != 1 {
+		t.Errorf(`count = "%d", expected "1"`, count)
+	}
+}
+
+func TestRunInTx_RetriesExhausted(t *testing.T) {
+	database := newTestDatabase(t)
+
+	attempts := 0
+	err := RunInTx(
+		context.Background(), database, nil, 2, func(tx *sql.Tx) error {
+			attempts++
+			return &pq.Error{Code: "40001"}
+		},
+	)
+	if !IsSerializationFailure(err) {
+		t.Errorf(`RunInTx(...) error = "%v", expected a serialization failure`, err)
+	}
+	if attempts != 3 {
+		t.Errorf(`attempts = "%d", expected "3"`, attempts)
+	}
+}
+
+func TestRunInTx_DoesNotRetryOtherErrors(t *testing.T) {
+	database := newTestDatabase(t)
+
+	attempts := 0
+	_ = RunInTx(
+		context.Background(), database, nil, DefaultTxMaxRetries, func(tx *sql.Tx) error {
+			attempts++
+			return errors.New("constraint violation")
+		},
+	)
+	if attempts != 1 {
+		t.Errorf(`attempts = "%d", expected "1"`, attempts)
+	}
+}
@@ -0,0 +1,4 @@
+// Package userpb contains the generated protobuf messages and gRPC client/server for the user service
+package userpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative user.proto
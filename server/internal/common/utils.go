@@ -4,10 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	_ "github.com/lib/pq" // registers "postgres" driver
+	"log/slog"
+	_ "modernc.org/sqlite" // registers "sqlite" driver
 	"os"
+	"time"
 )
 
 // InitJWT Initialize the global JWTAuth instance using the JWT_SECRET environment variable
@@ -20,30 +25,108 @@ func InitJWT() error {
 	return nil
 }
 
-// GetDatabaseConnection Establishes a database connection and returns the database object
-func GetDatabaseConnection() (*sql.DB, error) {
-	databaseDriver := os.Getenv("DATABASE_DRIVER")
-	if databaseDriver == "" {
-		return nil, errors.New("DATABASE_DRIVER environment variable not set")
+// getTokenClaims Get the user claims carried by a verified token
+func getTokenClaims(token jwt.Token) (*UserClaims, error) {
+	claimsMap, err := token.AsMap(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token claims: %w", err)
 	}
 
-	databaseHost := os.Getenv("DATABASE_URL")
-	if databaseHost == "" {
-		return nil, errors.New("DATABASE_URL environment variable not set")
+	// Numbers in a parsed token are float64, while tokens encoded in this process still hold an int
+	var userId int
+	switch id := claimsMap["user_id"].(type) {
+	case float64:
+		userId = int(id)
+	case int:
+		userId = id
+	}
+	username, _ := claimsMap["username"].(string)
+	email, _ := claimsMap["email"].(string)
+	admin, _ := claimsMap["admin"].(bool)
+	if userId <= 0 || username == "" {
+		return nil, errors.New("token does not identify a user")
 	}
 
-	database, err := sql.Open(databaseDriver, databaseHost)
+	return &UserClaims{ID: userId, Username: username, Email: email, Admin: admin}, nil
+}
+
+// GetDatabaseConnection Establishes a database connection using the environment configuration and returns the database object
+func GetDatabaseConnection() (*sql.DB, error) {
+	config, err := LoadDatabaseConfig()
 	if err != nil {
 		return nil, err
 	}
+	return OpenDatabase(context.Background(), config)
+}
 
-	if err = database.Ping(); err != nil {
+// OpenDatabase Opens a database connection pool, retrying the initial connection until the connect timeout elapses
+func OpenDatabase(ctx context.Context, config *DatabaseConfig) (*sql.DB, error) {
+	database, err := sql.Open(config.Driver, config.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	database.SetMaxOpenConns(config.MaxOpenConns)
+	database.SetMaxIdleConns(config.MaxIdleConns)
+	database.SetConnMaxLifetime(config.ConnMaxLifetime)
+	if config.Driver == DriverSQLite {
+		// SQLite allows a single writer, and each connection to ":memory:" is a separate database
+		database.SetMaxOpenConns(1)
+		database.SetMaxIdleConns(1)
+		database.SetConnMaxLifetime(0)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.ConnectTimeout)
+	defer cancel()
+
+	if err = pingWithRetry(ctx, database, config.InitialBackoff, config.MaxBackoff); err != nil {
+		_ = database.Close()
 		return nil, err
 	}
 
 	return database, nil
 }
 
+// WithQueryTimeout Derive a context for a single query, bounded by both the parent context and the query timeout
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// pingWithRetry Ping the database with exponential backoff until it responds or the context is done
+func pingWithRetry(ctx context.Context, database pinger, initialBackoff time.Duration, maxBackoff time.Duration) error {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := database.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		slog.WarnContext(
+			ctx,
+			"database connection attempt failed",
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", backoff),
+			slog.Any("error", err),
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("unable to connect to database after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
 // GetBaseUrl Get the base URL for the current service
 func GetBaseUrl() (string, error) {
 	baseUrl := os.Getenv("BASE_URL")
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockPinger struct {
+	failures int
+	attempts int
+}
+
+func (p *mockPinger) PingContext(ctx context.Context) error {
+	p.attempts++
+	if p.attempts <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestPingWithRetry_EventualSuccess(t *testing.T) {
+	database := &mockPinger{failures: 3}
+
+	err := pingWithRetry(context.Background(), database, time.Millisecond, 4*time.Millisecond)
+	if err != nil {
+		t.Errorf(`pingWithRetry(...) = "%v", expected "<nil>"`, err)
+	}
+
+	if database.attempts != 4 {
+		t.Errorf(`database.attempts = "%d", expected "4"`, database.attempts)
+	}
+}
+
+func TestPingWithRetry_Deadline(t *testing.T) {
+	database := &mockPinger{failures: 1000}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := pingWithRetry(ctx, database, time.Millisecond, 2*time.Millisecond)
+	if err == nil {
+		t.Error(`pingWithRetry(...) = "<nil>", expected non-nil`)
+	}
+
+	if database.attempts < 2 {
+		t.Errorf(`database.attempts = "%d", expected at least "2"`, database.attempts)
+	}
+}
+
+func TestWithQueryTimeout_AppliesTimeout(t *testing.T) {
+	ctx, cancel := WithQueryTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal(`ctx.Deadline() ok = "false", expected "true"`)
+	}
+	if time.Until(deadline) > time.Second {
+		t.Errorf(`time.Until(deadline) = "%v", expected at most "1s"`, time.Until(deadline))
+	}
+}
+
+func TestWithQueryTimeout_KeepsEarlierParentDeadline(t *testing.T) {
+	parent, parentCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer parentCancel()
+
+	ctx, cancel := WithQueryTimeout(parent, time.Hour)
+	defer cancel()
+
+	parentDeadline, _ := parent.Deadline()
+	deadline, _ := ctx.Deadline()
+	if !deadline.Equal(parentDeadline) {
+		t.Errorf(`ctx.Deadline() = "%v", expected "%v"`, deadline, parentDeadline)
+	}
+}
+
+func TestWithQueryTimeout_NoTimeout(t *testing.T) {
+	ctx, cancel := WithQueryTimeout(context.Background(), 0)
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error(`ctx.Deadline() ok = "true", expected "false"`)
+	}
+}
@@ -0,0 +1,123 @@
+// Package client contains a client for the game service's WebSocket protocol, for tests and bots
+package client
+
+import (
+	"context"
+	"fmt"
+	"game/protocol"
+	"golang.org/x/net/websocket"
+	"net/url"
+	quizdto "quiz/dto"
+	"strings"
+)
+
+// Client A player's connection to a lobby or to matchmaking. Messages must be received from a single goroutine, while
+// sending is safe from several
+type Client struct {
+	conn *websocket.Conn
+}
+
+// Dial Connect to the lobby with the join code as the user the token was issued to. baseUrl is the url of the game
+// service, such as "http://localhost:8082"
+func Dial(ctx context.Context, baseUrl string, code string, token string) (*Client, error) {
+	return dial(ctx, baseUrl, "/lobby/"+url.PathEscape(code)+"/ws", token)
+}
+
+// DialMatchmaking Connect to matchmaking as the user the token was issued to, to be told when a match is found. The
+// connection must be open before queueing
+func DialMatchmaking(ctx context.Context, baseUrl string, token string) (*Client, error) {
+	return dial(ctx, baseUrl, "/matchmaking/ws", token)
+}
+
+// dial Connect to the WebSocket endpoint at the path of the game service
+func dial(ctx context.Context, baseUrl string, path string, token string) (*Client, error) {
+	origin, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base url: %w", err)
+	}
+
+	location := *origin
+	location.Scheme = strings.Replace(origin.Scheme, "http", "ws", 1)
+	location.Path = strings.TrimSuffix(origin.Path, "/") + path
+
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure connection: %w", err)
+	}
+	config.Header.Set("Authorization", "Bearer "+token)
+
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", path, err)
+	}
+
+	return &Client{conn: conn}, nil
+}
+
+// Receive Wait for the next message from the server
+func (client *Client) Receive() (*protocol.Message, error) {
+	var message protocol.Message
+	if err := websocket.JSON.Receive(client.conn, &message); err != nil {
+		return nil, fmt.Errorf("failed to receive message: %w", err)
+	}
+	return &message, nil
+}
+
+// Await Skip messages until one of the message type arrives and decode its payload into data, which may be nil. An
+// error message from the server is returned as a *protocol.Error, unless that is the type awaited
+func (client *Client) Await(messageType string, data any) error {
+	for {
+		message, err := client.Receive()
+		if err != nil {
+			return err
+		}
+
+		if message.Type == messageType {
+			if data == nil {
+				return nil
+			}
+			return message.Decode(data)
+		}
+
+		if message.Type == protocol.TypeError {
+			var serverErr protocol.Error
+			if err := message.Decode(&serverErr); err != nil {
+				return err
+			}
+			return &serverErr
+		}
+	}
+}
+
+// Send Send a message of the current protocol version with the payload, which may be nil
+func (client *Client) Send(messageType string, data any) error {
+	message, err := protocol.NewMessage(messageType, data)
+	if err != nil {
+		return err
+	}
+
+	if err := websocket.JSON.Send(client.conn, message); err != nil {
+		return fmt.Errorf("failed to send %s message: %w", messageType, err)
+	}
+	return nil
+}
+
+// StartGame Ask to start the game. Only the host can start it
+func (client *Client) StartGame() error {
+	return client.Send(protocol.TypeStartGame, nil)
+}
+
+// SubmitAnswer Answer the open question
+func (client *Client) SubmitAnswer(questionId int64, answer quizdto.Answer) error {
+	return client.Send(protocol.TypeSubmitAnswer, &protocol.SubmitAnswer{QuestionId: questionId, Answer: answer})
+}
+
+// NextQuestion Ask to close the open question. Only the host can move on
+func (client *Client) NextQuestion() error {
+	return client.Send(protocol.TypeNextQuestion, nil)
+}
+
+// Close Close the connection
+func (client *Client) Close() error {
+	return client.conn.Close()
+}
@@ -0,0 +1,454 @@
+// Package memory contains a thread-safe in-memory implementation of db.Querier for tests and local runs
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"game/db/generated"
+	"sort"
+	"sync"
+)
+
+// ErrUniqueViolation Returned when a write would violate a unique constraint, mirroring Postgres
+var ErrUniqueViolation = errors.New("duplicate key value violates unique constraint")
+
+// Querier In-memory db.Querier mirroring the games, game_results, leaderboard_scores and ratings tables
+type Querier struct {
+	*tables
+	// inTx Whether this is the handle RunInTx passes to its function, whose calls run inside the transaction
+	inTx bool
+}
+
+// tables State shared by a querier and the handles of its transactions
+type tables struct {
+	// txMutex Held by a running transaction, and shared by every call made outside of it, so that no other caller
+	// sees or writes the store until the transaction commits or rolls back
+	txMutex sync.RWMutex
+	mutex   sync.RWMutex
+	games   []db.Game
+	gameId  int64
+	results []db.GameResult
+	scores  []db.LeaderboardScore
+	ratings []db.Rating
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New Create an empty in-memory querier
+func New() *Querier {
+	return &Querier{tables: &tables{}}
+}
+
+// CreateGame CreateGame() implementation from db.Querier interface
+func (q *Querier) CreateGame(ctx context.Context, arg db.CreateGameParams) (db.Game, error) {
+	defer q.lock()()
+
+	q.gameId++
+	game := db.Game{
+		ID:         q.gameId,
+		Code:       arg.Code,
+		QuizID:     arg.QuizID,
+		FinishedAt: arg.FinishedAt,
+	}
+	q.games = append(q.games, game)
+
+	return game, nil
+}
+
+// GetGame GetGame() implementation from db.Querier interface
+func (q *Querier) GetGame(ctx context.Context, id int64) (db.Game, error) {
+	defer q.rlock()()
+
+	for _, game := range q.games {
+		if game.ID == id {
+			return game, nil
+		}
+	}
+	return db.Game{}, sql.ErrNoRows
+}
+
+// CreateGameResult CreateGameResult() implementation from db.Querier interface
+func (q *Querier) CreateGameResult(ctx context.Context, arg db.CreateGameResultParams) error {
+	defer q.lock()()
+
+	for _, result := range q.results {
+		if result.GameID == arg.GameID && result.UserID == arg.UserID {
+			return ErrUniqueViolation
+		}
+	}
+
+	q.results = append(
+		q.results, db.GameResult{
+			GameID: arg.GameID,
+			UserID: arg.UserID,
+			Score:  arg.Score,
+			Rank:   arg.Rank,
+		},
+	)
+	return nil
+}
+
+// GetGameResults GetGameResults() implementation from db.Querier interface
+func (q *Querier) GetGameResults(ctx context.Context, gameID int64) ([]db.GameResult, error) {
+	defer q.rlock()()
+
+	var results []db.GameResult
+	for _, result := range q.results {
+		if result.GameID == gameID {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(
+		results, func(i, j int) bool {
+			if results[i].Rank != results[j].Rank {
+				return results[i].Rank < results[j].Rank
+			}
+			return results[i].UserID < results[j].UserID
+		},
+	)
+	return results, nil
+}
+
+// AddLeaderboardScore AddLeaderboardScore() implementation from db.Querier interface. A player already on the board
+// has the score added to theirs, keeping the board's expiry
+func (q *Querier) AddLeaderboardScore(ctx context.Context, arg db.AddLeaderboardScoreParams) error {
+	defer q.lock()()
+
+	for i, score := range q.scores {
+		if score.Board == arg.Board && score.UserID == arg.UserID {
+			q.scores[i].Score += arg.Score
+			q.scores[i].GamesPlayed++
+			q.scores[i].UpdatedAt = arg.UpdatedAt
+			return nil
+		}
+	}
+
+	q.scores = append(
+		q.scores, db.LeaderboardScore{
+			Board:       arg.Board,
+			UserID:      arg.UserID,
+			Score:       arg.Score,
+			GamesPlayed: 1,
+			UpdatedAt:   arg.UpdatedAt,
+			ExpiresAt:   arg.ExpiresAt,
+		},
+	)
+	return nil
+}
+
+// GetLeaderboardScore GetLeaderboardScore() implementation from db.Querier interface
+func (q *Querier) GetLeaderboardScore(
+	ctx context.Context,
+	arg db.GetLeaderboardScoreParams,
+) (db.LeaderboardScore, error) {
+	defer q.rlock()()
+
+	for _, score := range q.scores {
+		if score.Board == arg.Board && score.UserID == arg.UserID {
+			return score, nil
+		}
+	}
+	return db.LeaderboardScore{}, sql.ErrNoRows
+}
+
+// GetLeaderboardPage GetLeaderboardPage() implementation from db.Querier interface. Players with the same score share
+// the best of their ranks, and are listed by user id
+func (q *Querier) GetLeaderboardPage(
+	ctx context.Context,
+	arg db.GetLeaderboardPageParams,
+) ([]db.GetLeaderboardPageRow, error) {
+	defer q.rlock()()
+
+	if arg.Limit < 0 || arg.Offset < 0 {
+		return nil, errors.New("LIMIT and OFFSET must not be negative")
+	}
+
+	board := q.board(arg.Board)
+	rows := make([]db.GetLeaderboardPageRow, len(board))
+	for i, score := range board {
+		rank := int64(i + 1)
+		if i > 0 && score.Score == board[i-1].Score {
+			rank = rows[i-1].Rank
+		}
+		rows[i] = db.GetLeaderboardPageRow{
+			UserID:      score.UserID,
+			Score:       score.Score,
+			GamesPlayed: score.GamesPlayed,
+			Rank:        rank,
+		}
+	}
+
+	start := min(int(arg.Offset), len(rows))
+	end := min(start+int(arg.Limit), len(rows))
+
+	return rows[start:end], nil
+}
+
+// CountLeaderboardScoresAhead CountLeaderboardScoresAhead() implementation from db.Querier interface
+func (q *Querier) CountLeaderboardScoresAhead(
+	ctx context.Context,
+	arg db.CountLeaderboardScoresAheadParams,
+) (int64, error) {
+	defer q.rlock()()
+
+	var count int64
+	for _, score := range q.scores {
+		if score.Board == arg.Board &&
+			(score.Score > arg.Score || (score.Score == arg.Score && score.UserID < arg.UserID)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteExpiredLeaderboardScores DeleteExpiredLeaderboardScores() implementation from db.Querier interface
+func (q *Querier) DeleteExpiredLeaderboardScores(ctx context.Context, expiresAt sql.NullTime) (int64, error) {
+	defer q.lock()()
+
+	if !expiresAt.Valid {
+		return 0, nil
+	}
+
+	var kept []db.LeaderboardScore
+	for _, score := range q.scores {
+		if !score.ExpiresAt.Valid || score.ExpiresAt.Time.After(expiresAt.Time) {
+			kept = append(kept, score)
+		}
+	}
+	deleted := int64(len(q.scores) - len(kept))
+	q.scores = kept
+
+	return deleted, nil
+}
+
+// GetRating GetRating() implementation from db.Querier interface
+func (q *Querier) GetRating(ctx context.Context, arg db.GetRatingParams) (db.Rating, error) {
+	defer q.rlock()()
+
+	for _, rating := range q.ratings {
+		if rating.UserID == arg.UserID && rating.Category == arg.Category {
+			return rating, nil
+		}
+	}
+	return db.Rating{}, sql.ErrNoRows
+}
+
+// UpsertRating UpsertRating() implementation from db.Querier interface
+func (q *Querier) UpsertRating(ctx context.Context, arg db.UpsertRatingParams) error {
+	defer q.lock()()
+
+	rating := db.Rating{
+		UserID:      arg.UserID,
+		Category:    arg.Category,
+		Rating:      arg.Rating,
+		Deviation:   arg.Deviation,
+		Volatility:  arg.Volatility,
+		GamesPlayed: 1,
+		UpdatedAt:   arg.UpdatedAt,
+	}
+	for i, existing := range q.ratings {
+		if existing.UserID == arg.UserID && existing.Category == arg.Category {
+			rating.GamesPlayed = existing.GamesPlayed + 1
+			q.ratings[i] = rating
+			return nil
+		}
+	}
+
+	q.ratings = append(q.ratings, rating)
+	return nil
+}
+
+// GetUserGameResults GetUserGameResults() implementation from db.Querier interface
+func (q *Querier) GetUserGameResults(ctx context.Context, userID int32) ([]db.GetUserGameResultsRow, error) {
+	defer q.rlock()()
+
+	var rows []db.GetUserGameResultsRow
+	for _, result := range q.results {
+		if result.UserID != userID {
+			continue
+		}
+		for _, game := range q.games {
+			if game.ID == result.GameID {
+				rows = append(
+					rows, db.GetUserGameResultsRow{
+						GameID:     game.ID,
+						QuizID:     game.QuizID,
+						FinishedAt: game.FinishedAt,
+						Score:      result.Score,
+						Rank:       result.Rank,
+					},
+				)
+			}
+		}
+	}
+	sort.Slice(
+		rows, func(i, j int) bool {
+			if !rows[i].FinishedAt.Equal(rows[j].FinishedAt) {
+				return rows[i].FinishedAt.Before(rows[j].FinishedAt)
+			}
+			return rows[i].GameID < rows[j].GameID
+		},
+	)
+	return rows, nil
+}
+
+// DeleteUserGameResults DeleteUserGameResults() implementation from db.Querier interface
+func (q *Querier) DeleteUserGameResults(ctx context.Context, userID int32) (int64, error) {
+	defer q.lock()()
+
+	var kept []db.GameResult
+	for _, result := range q.results {
+		if result.UserID != userID {
+			kept = append(kept, result)
+		}
+	}
+	deleted := int64(len(q.results) - len(kept))
+	q.results = kept
+
+	return deleted, nil
+}
+
+// GetUserLeaderboardScores GetUserLeaderboardScores() implementation from db.Querier interface
+func (q *Querier) GetUserLeaderboardScores(ctx context.Context, userID int32) ([]db.LeaderboardScore, error) {
+	defer q.rlock()()
+
+	var scores []db.LeaderboardScore
+	for _, score := range q.scores {
+		if score.UserID == userID {
+			scores = append(scores, score)
+		}
+	}
+	sort.Slice(
+		scores, func(i, j int) bool {
+			return scores[i].Board < scores[j].Board
+		},
+	)
+	return scores, nil
+}
+
+// DeleteUserLeaderboardScores DeleteUserLeaderboardScores() implementation from db.Querier interface
+func (q *Querier) DeleteUserLeaderboardScores(ctx context.Context, userID int32) (int64, error) {
+	defer q.lock()()
+
+	var kept []db.LeaderboardScore
+	for _, score := range q.scores {
+		if score.UserID != userID {
+			kept = append(kept, score)
+		}
+	}
+	deleted := int64(len(q.scores) - len(kept))
+	q.scores = kept
+
+	return deleted, nil
+}
+
+// GetUserRatings GetUserRatings() implementation from db.Querier interface
+func (q *Querier) GetUserRatings(ctx context.Context, userID int32) ([]db.Rating, error) {
+	defer q.rlock()()
+
+	var ratings []db.Rating
+	for _, rating := range q.ratings {
+		if rating.UserID == userID {
+			ratings = append(ratings, rating)
+		}
+	}
+	sort.Slice(
+		ratings, func(i, j int) bool {
+			return ratings[i].Category < ratings[j].Category
+		},
+	)
+	return ratings, nil
+}
+
+// DeleteUserRatings DeleteUserRatings() implementation from db.Querier interface
+func (q *Querier) DeleteUserRatings(ctx context.Context, userID int32) (int64, error) {
+	defer q.lock()()
+
+	var kept []db.Rating
+	for _, rating := range q.ratings {
+		if rating.UserID != userID {
+			kept = append(kept, rating)
+		}
+	}
+	deleted := int64(len(q.ratings) - len(kept))
+	q.ratings = kept
+
+	return deleted, nil
+}
+
+// RunInTx Run fn as a transaction: every call made outside of it waits until it ends, and every write made by fn is
+// rolled back if it returns an error. Transactions started inside fn join it
+func (q *Querier) RunInTx(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error {
+	if q.inTx {
+		return fn(q)
+	}
+
+	q.txMutex.Lock()
+	defer q.txMutex.Unlock()
+
+	// No other caller can reach the store until the transaction ends, so restoring this snapshot on rollback undoes
+	// only the transaction's own writes
+	games := append([]db.Game(nil), q.games...)
+	results := append([]db.GameResult(nil), q.results...)
+	scores := append([]db.LeaderboardScore(nil), q.scores...)
+	ratings := append([]db.Rating(nil), q.ratings...)
+	gameId := q.gameId
+
+	if err := fn(&Querier{tables: q.tables, inTx: true}); err != nil {
+		q.mutex.Lock()
+		q.games, q.results, q.scores, q.ratings, q.gameId = games, results, scores, ratings, gameId
+		q.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// lock Take the write lock, first waiting for any transaction this call is not part of to end, and get the function
+// releasing it
+func (q *Querier) lock() (unlock func()) {
+	if !q.inTx {
+		q.txMutex.RLock()
+	}
+	q.mutex.Lock()
+	return func() {
+		q.mutex.Unlock()
+		if !q.inTx {
+			q.txMutex.RUnlock()
+		}
+	}
+}
+
+// rlock Take the read lock, first waiting for any transaction this call is not part of to end, and get the function
+// releasing it
+func (q *Querier) rlock() (unlock func()) {
+	if !q.inTx {
+		q.txMutex.RLock()
+	}
+	q.mutex.RLock()
+	return func() {
+		q.mutex.RUnlock()
+		if !q.inTx {
+			q.txMutex.RUnlock()
+		}
+	}
+}
+
+// board Get the scores on the board, best first and by user id among equal scores. The mutex must be held
+func (q *Querier) board(board string) []db.LeaderboardScore {
+	var scores []db.LeaderboardScore
+	for _, score := range q.scores {
+		if score.Board == board {
+			scores = append(scores, score)
+		}
+	}
+	sort.Slice(
+		scores, func(i, j int) bool {
+			if scores[i].Score != scores[j].Score {
+				return scores[i].Score > scores[j].Score
+			}
+			return scores[i].UserID < scores[j].UserID
+		},
+	)
+	return scores
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"game/db/generated"
+	"testing"
+	"time"
+)
+
+func TestQuerier_GetLeaderboardPage_Ties(t *testing.T) {
+	querier := New()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, arg := range []db.AddLeaderboardScoreParams{
+		{Board: "all-time", UserID: 3, Score: 5},
+		{Board: "all-time", UserID: 1, Score: 10},
+		{Board: "all-time", UserID: 2, Score: 5},
+		{Board: "all-time", UserID: 2, Score: 5},
+		{Board: "all-time", UserID: 4, Score: 5},
+		{Board: "other", UserID: 5, Score: 100},
+	} {
+		arg.UpdatedAt = now
+		if err := querier.AddLeaderboardScore(context.Background(), arg); err != nil {
+			t.Fatalf(`querier.AddLeaderboardScore(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	rows, err := querier.GetLeaderboardPage(
+		context.Background(),
+		db.GetLeaderboardPageParams{Board: "all-time", Limit: 3, Offset: 1},
+	)
+	if err != nil {
+		t.Fatalf(`querier.GetLeaderboardPage(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	// Users 1 and 2 tie on 10 after user 2's second game, and users 3 and 4 on 5
+	expected := []db.GetLeaderboardPageRow{
+		{UserID: 2, Score: 10, GamesPlayed: 2, Rank: 1},
+		{UserID: 3, Score: 5, GamesPlayed: 1, Rank: 3},
+		{UserID: 4, Score: 5, GamesPlayed: 1, Rank: 3},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf(`rows = "%+v", expected "%+v"`, rows, expected)
+	}
+	for i := range rows {
+		if rows[i] != expected[i] {
+			t.Errorf(`rows[%d] = "%+v", expected "%+v"`, i, rows[i], expected[i])
+		}
+	}
+
+	ahead, err := querier.CountLeaderboardScoresAhead(
+		context.Background(),
+		db.CountLeaderboardScoresAheadParams{Board: "all-time", Score: 5, UserID: 4},
+	)
+	if err != nil || ahead != 3 {
+		t.Errorf(`querier.CountLeaderboardScoresAhead(...) = "%d", "%v", expected "3", "<nil>"`, ahead, err)
+	}
+}
+
+func TestQuerier_DeleteExpiredLeaderboardScores(t *testing.T) {
+	querier := New()
+	now := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	nextWeek := now.AddDate(0, 0, 7)
+	for _, arg := range []db.AddLeaderboardScoreParams{
+		{Board: "all-time", UserID: 1, Score: 1},
+		{Board: "weekly:2024-12-30", UserID: 1, Score: 1, ExpiresAt: sql.NullTime{Time: now, Valid: true}},
+		{Board: "weekly:2025-01-06", UserID: 1, Score: 1, ExpiresAt: sql.NullTime{Time: nextWeek, Valid: true}},
+	} {
+		_ = querier.AddLeaderboardScore(context.Background(), arg)
+	}
+
+	deleted, err := querier.DeleteExpiredLeaderboardScores(context.Background(), sql.NullTime{Time: now, Valid: true})
+	if err != nil || deleted != 1 {
+		t.Errorf(`querier.DeleteExpiredLeaderboardScores(...) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+
+	for board, expected := range map[string]error{
+		"all-time":          nil,
+		"weekly:2024-12-30": sql.ErrNoRows,
+		"weekly:2025-01-06": nil,
+	} {
+		_, err := querier.GetLeaderboardScore(
+			context.Background(),
+			db.GetLeaderboardScoreParams{Board: board, UserID: 1},
+		)
+		if !errors.Is(err, expected) {
+			t.Errorf(`querier.GetLeaderboardScore(ctx, "%s") error = "%v", expected "%v"`, board, err, expected)
+		}
+	}
+}
+
+func TestQuerier_UpsertRating(t *testing.T) {
+	querier := New()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, arg := range []db.UpsertRatingParams{
+		{UserID: 1, Rating: 1500, Deviation: 350, Volatility: 0.06, UpdatedAt: now},
+		{UserID: 1, Category: "history", Rating: 1400, Deviation: 300, Volatility: 0.06, UpdatedAt: now},
+		{UserID: 1, Rating: 1600, Deviation: 290, Volatility: 0.059, UpdatedAt: now.Add(time.Hour)},
+	} {
+		if err := querier.UpsertRating(context.Background(), arg); err != nil {
+			t.Fatalf(`querier.UpsertRating(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	// The second update of the overall rating replaces it, counting both games, and leaves the category's alone
+	expected := db.Rating{
+		UserID:      1,
+		Rating:      1600,
+		Deviation:   290,
+		Volatility:  0.059,
+		GamesPlayed: 2,
+		UpdatedAt:   now.Add(time.Hour),
+	}
+	rating, err := querier.GetRating(context.Background(), db.GetRatingParams{UserID: 1})
+	if err != nil || rating != expected {
+		t.Errorf(`querier.GetRating(ctx, 1, "") = "%+v", "%v", expected "%+v", "<nil>"`, rating, err, expected)
+	}
+
+	history, err := querier.GetRating(context.Background(), db.GetRatingParams{UserID: 1, Category: "history"})
+	if err != nil || history.Rating != 1400 || history.GamesPlayed != 1 {
+		t.Errorf(`querier.GetRating(ctx, 1, "history") = "%+v", "%v", expected "1400" after "1" game`, history, err)
+	}
+
+	_, err = querier.GetRating(context.Background(), db.GetRatingParams{UserID: 2})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.GetRating(ctx, 2, "") error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestQuerier_RunInTx_RollsBack(t *testing.T) {
+	querier := New()
+
+	err := querier.RunInTx(
+		context.Background(), nil, func(queries db.Querier) error {
+			game, err := queries.CreateGame(context.Background(), db.CreateGameParams{Code: "ABC234", QuizID: 1})
+			if err != nil {
+				return err
+			}
+			_ = queries.CreateGameResult(context.Background(), db.CreateGameResultParams{GameID: game.ID, UserID: 1})
+			return queries.CreateGameResult(context.Background(), db.CreateGameResultParams{GameID: game.ID, UserID: 1})
+		},
+	)
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf(`querier.RunInTx(...) error = "%v", expected "%v"`, err, ErrUniqueViolation)
+	}
+
+	if _, err := querier.GetGame(context.Background(), 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.GetGame(ctx, 1) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestQuerier_RunInTx_RollbackKeepsOtherWrites(t *testing.T) {
+	querier := New()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- querier.RunInTx(
+			context.Background(), nil, func(queries db.Querier) error {
+				if _, err := queries.CreateGame(context.Background(), db.CreateGameParams{Code: "ABC234"}); err != nil {
+					return err
+				}
+				close(started)
+				<-release
+				return errors.New("rollback")
+			},
+		)
+	}()
+	<-started
+
+	created := make(chan error)
+	go func() {
+		_, err := querier.CreateGame(context.Background(), db.CreateGameParams{Code: "XYZ789"})
+		created <- err
+	}()
+
+	// Writes from outside the transaction wait for it to end, so rolling it back cannot undo them
+	select {
+	case err := <-created:
+		t.Fatalf(`querier.CreateGame(...) = "%v" while the transaction was running, expected it to wait`, err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-done; err == nil {
+		t.Fatal(`querier.RunInTx(...) error = "<nil>", expected non-nil`)
+	}
+	if err := <-created; err != nil {
+		t.Fatalf(`querier.CreateGame(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if game, err := querier.GetGame(context.Background(), 1); err != nil || game.Code != "XYZ789" {
+		t.Errorf(`querier.GetGame(ctx, 1) = "%+v", "%v", expected the game created outside the transaction`, game, err)
+	}
+}
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE games (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(6) NOT NULL,
+    quiz_id BIGINT NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE game_results (
+    game_id BIGINT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    rank INTEGER NOT NULL,
+    PRIMARY KEY (game_id, user_id)
+);
+
+CREATE TABLE leaderboard_scores (
+    board VARCHAR(40) NOT NULL,
+    user_id INTEGER NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    games_played INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (board, user_id)
+);
+
+CREATE INDEX leaderboard_scores_rank_idx ON leaderboard_scores (board, score DESC, user_id);
+CREATE INDEX leaderboard_scores_expires_at_idx ON leaderboard_scores (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE leaderboard_scores;
+DROP TABLE game_results;
+DROP TABLE games;
+-- +goose StatementEnd
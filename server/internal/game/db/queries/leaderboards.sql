@@ -0,0 +1,76 @@
+-- name: CreateGame :one
+INSERT INTO games (code, quiz_id, finished_at)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: GetGame :one
+SELECT *
+FROM games
+WHERE id = $1;
+
+-- name: CreateGameResult :exec
+INSERT INTO game_results (game_id, user_id, score, rank)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetGameResults :many
+SELECT *
+FROM game_results
+WHERE game_id = $1
+ORDER BY rank, user_id;
+
+-- name: AddLeaderboardScore :exec
+INSERT INTO leaderboard_scores (board, user_id, score, games_played, updated_at, expires_at)
+VALUES ($1, $2, $3, 1, $4, $5)
+ON CONFLICT (board, user_id) DO UPDATE
+SET score = leaderboard_scores.score + excluded.score,
+    games_played = leaderboard_scores.games_played + 1,
+    updated_at = excluded.updated_at;
+
+-- name: GetLeaderboardScore :one
+SELECT *
+FROM leaderboard_scores
+WHERE board = $1
+    AND user_id = $2;
+
+-- name: GetLeaderboardPage :many
+-- Players with the same score share the best of their ranks, and are listed by user id
+SELECT user_id, score, games_played, RANK() OVER (ORDER BY score DESC) AS rank
+FROM leaderboard_scores
+WHERE board = $1
+ORDER BY score DESC, user_id
+LIMIT $2
+OFFSET $3;
+
+-- name: CountLeaderboardScoresAhead :one
+-- Number of players listed before the player with the score and user id, which is their position on the board
+SELECT
+    COUNT(*)
+FROM
+    leaderboard_scores
+WHERE board = $1
+    AND (score > $2 OR (score = $2 AND user_id < $3));
+
+-- name: DeleteExpiredLeaderboardScores :execrows
+DELETE FROM leaderboard_scores
+WHERE expires_at <= $1;
+
+-- name: GetUserGameResults :many
+SELECT game_results.game_id, games.quiz_id, games.finished_at, game_results.score, game_results.rank
+FROM game_results
+    JOIN games ON games.id = game_results.game_id
+WHERE game_results.user_id = $1
+ORDER BY games.finished_at, game_results.game_id;
+
+-- name: DeleteUserGameResults :execrows
+DELETE FROM game_results
+WHERE user_id = $1;
+
+-- name: GetUserLeaderboardScores :many
+SELECT *
+FROM leaderboard_scores
+WHERE user_id = $1
+ORDER BY board;
+
+-- name: DeleteUserLeaderboardScores :execrows
+DELETE FROM leaderboard_scores
+WHERE user_id = $1;
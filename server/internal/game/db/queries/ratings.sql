@@ -0,0 +1,25 @@
+-- name: GetRating :one
+SELECT *
+FROM ratings
+WHERE user_id = $1
+    AND category = $2;
+
+-- name: UpsertRating :exec
+INSERT INTO ratings (user_id, category, rating, deviation, volatility, games_played, updated_at)
+VALUES ($1, $2, $3, $4, $5, 1, $6)
+ON CONFLICT (user_id, category) DO UPDATE
+SET rating = excluded.rating,
+    deviation = excluded.deviation,
+    volatility = excluded.volatility,
+    games_played = ratings.games_played + 1,
+    updated_at = excluded.updated_at;
+
+-- name: GetUserRatings :many
+SELECT *
+FROM ratings
+WHERE user_id = $1
+ORDER BY category;
+
+-- name: DeleteUserRatings :execrows
+DELETE FROM ratings
+WHERE user_id = $1;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE games (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(6) NOT NULL,
+    quiz_id INTEGER NOT NULL,
+    finished_at DATETIME NOT NULL
+);
+
+CREATE TABLE game_results (
+    game_id INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    score REAL NOT NULL,
+    rank INTEGER NOT NULL,
+    PRIMARY KEY (game_id, user_id)
+);
+
+CREATE TABLE leaderboard_scores (
+    board VARCHAR(40) NOT NULL,
+    user_id INTEGER NOT NULL,
+    score REAL NOT NULL,
+    games_played INTEGER NOT NULL,
+    updated_at DATETIME NOT NULL,
+    expires_at DATETIME,
+    PRIMARY KEY (board, user_id)
+);
+
+CREATE INDEX leaderboard_scores_rank_idx ON leaderboard_scores (board, score DESC, user_id);
+CREATE INDEX leaderboard_scores_expires_at_idx ON leaderboard_scores (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE leaderboard_scores;
+DROP TABLE game_results;
+DROP TABLE games;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE ratings (
+    user_id INTEGER NOT NULL,
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    rating REAL NOT NULL,
+    deviation REAL NOT NULL,
+    volatility REAL NOT NULL,
+    games_played INTEGER NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, category)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE ratings;
+-- +goose StatementEnd
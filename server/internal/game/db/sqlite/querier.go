@@ -0,0 +1,274 @@
+// Package sqlite adapts the sqlc-generated SQLite queries to the db.Querier interface used by the game service
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"game/db/generated"
+	"game/db/sqlite/generated"
+)
+
+// Querier db.Querier implementation backed by the SQLite queries
+type Querier struct {
+	Queries sqlitedb.Querier
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New Create a db.Querier using the specified SQLite connection
+func New(database sqlitedb.DBTX) *Querier {
+	return &Querier{
+		Queries: sqlitedb.New(database),
+	}
+}
+
+// CreateGame CreateGame() implementation from db.Querier interface
+func (q *Querier) CreateGame(ctx context.Context, arg db.CreateGameParams) (db.Game, error) {
+	game, err := q.Queries.CreateGame(
+		ctx, sqlitedb.CreateGameParams{
+			Code:       arg.Code,
+			QuizID:     arg.QuizID,
+			FinishedAt: arg.FinishedAt,
+		},
+	)
+	return toGame(game), err
+}
+
+// GetGame GetGame() implementation from db.Querier interface
+func (q *Querier) GetGame(ctx context.Context, id int64) (db.Game, error) {
+	game, err := q.Queries.GetGame(ctx, id)
+	return toGame(game), err
+}
+
+// CreateGameResult CreateGameResult() implementation from db.Querier interface
+func (q *Querier) CreateGameResult(ctx context.Context, arg db.CreateGameResultParams) error {
+	return q.Queries.CreateGameResult(
+		ctx, sqlitedb.CreateGameResultParams{
+			GameID: arg.GameID,
+			UserID: int64(arg.UserID),
+			Score:  arg.Score,
+			Rank:   int64(arg.Rank),
+		},
+	)
+}
+
+// GetGameResults GetGameResults() implementation from db.Querier interface
+func (q *Querier) GetGameResults(ctx context.Context, gameID int64) ([]db.GameResult, error) {
+	results, err := q.Queries.GetGameResults(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]db.GameResult, len(results))
+	for i, result := range results {
+		converted[i] = db.GameResult{
+			GameID: result.GameID,
+			UserID: int32(result.UserID),
+			Score:  result.Score,
+			Rank:   int32(result.Rank),
+		}
+	}
+	return converted, nil
+}
+
+// AddLeaderboardScore AddLeaderboardScore() implementation from db.Querier interface
+func (q *Querier) AddLeaderboardScore(ctx context.Context, arg db.AddLeaderboardScoreParams) error {
+	return q.Queries.AddLeaderboardScore(
+		ctx, sqlitedb.AddLeaderboardScoreParams{
+			Board:     arg.Board,
+			UserID:    int64(arg.UserID),
+			Score:     arg.Score,
+			UpdatedAt: arg.UpdatedAt,
+			ExpiresAt: arg.ExpiresAt,
+		},
+	)
+}
+
+// GetLeaderboardScore GetLeaderboardScore() implementation from db.Querier interface
+func (q *Querier) GetLeaderboardScore(
+	ctx context.Context,
+	arg db.GetLeaderboardScoreParams,
+) (db.LeaderboardScore, error) {
+	score, err := q.Queries.GetLeaderboardScore(
+		ctx, sqlitedb.GetLeaderboardScoreParams{
+			Board:  arg.Board,
+			UserID: int64(arg.UserID),
+		},
+	)
+	return db.LeaderboardScore{
+		Board:       score.Board,
+		UserID:      int32(score.UserID),
+		Score:       score.Score,
+		GamesPlayed: int32(score.GamesPlayed),
+		UpdatedAt:   score.UpdatedAt,
+		ExpiresAt:   score.ExpiresAt,
+	}, err
+}
+
+// GetLeaderboardPage GetLeaderboardPage() implementation from db.Querier interface
+func (q *Querier) GetLeaderboardPage(
+	ctx context.Context,
+	arg db.GetLeaderboardPageParams,
+) ([]db.GetLeaderboardPageRow, error) {
+	rows, err := q.Queries.GetLeaderboardPage(
+		ctx, sqlitedb.GetLeaderboardPageParams{
+			Board:  arg.Board,
+			Limit:  int64(arg.Limit),
+			Offset: int64(arg.Offset),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]db.GetLeaderboardPageRow, len(rows))
+	for i, row := range rows {
+		converted[i] = db.GetLeaderboardPageRow{
+			UserID:      int32(row.UserID),
+			Score:       row.Score,
+			GamesPlayed: int32(row.GamesPlayed),
+			Rank:        row.Rank,
+		}
+	}
+	return converted, nil
+}
+
+// CountLeaderboardScoresAhead CountLeaderboardScoresAhead() implementation from db.Querier interface
+func (q *Querier) CountLeaderboardScoresAhead(
+	ctx context.Context,
+	arg db.CountLeaderboardScoresAheadParams,
+) (int64, error) {
+	return q.Queries.CountLeaderboardScoresAhead(
+		ctx, sqlitedb.CountLeaderboardScoresAheadParams{
+			Board:  arg.Board,
+			Score:  arg.Score,
+			UserID: int64(arg.UserID),
+		},
+	)
+}
+
+// DeleteExpiredLeaderboardScores DeleteExpiredLeaderboardScores() implementation from db.Querier interface
+func (q *Querier) DeleteExpiredLeaderboardScores(ctx context.Context, expiresAt sql.NullTime) (int64, error) {
+	return q.Queries.DeleteExpiredLeaderboardScores(ctx, expiresAt)
+}
+
+// GetRating GetRating() implementation from db.Querier interface
+func (q *Querier) GetRating(ctx context.Context, arg db.GetRatingParams) (db.Rating, error) {
+	rating, err := q.Queries.GetRating(
+		ctx, sqlitedb.GetRatingParams{
+			UserID:   int64(arg.UserID),
+			Category: arg.Category,
+		},
+	)
+	return db.Rating{
+		UserID:      int32(rating.UserID),
+		Category:    rating.Category,
+		Rating:      rating.Rating,
+		Deviation:   rating.Deviation,
+		Volatility:  rating.Volatility,
+		GamesPlayed: int32(rating.GamesPlayed),
+		UpdatedAt:   rating.UpdatedAt,
+	}, err
+}
+
+// UpsertRating UpsertRating() implementation from db.Querier interface
+func (q *Querier) UpsertRating(ctx context.Context, arg db.UpsertRatingParams) error {
+	return q.Queries.UpsertRating(
+		ctx, sqlitedb.UpsertRatingParams{
+			UserID:     int64(arg.UserID),
+			Category:   arg.Category,
+			Rating:     arg.Rating,
+			Deviation:  arg.Deviation,
+			Volatility: arg.Volatility,
+			UpdatedAt:  arg.UpdatedAt,
+		},
+	)
+}
+
+// GetUserGameResults GetUserGameResults() implementation from db.Querier interface
+func (q *Querier) GetUserGameResults(ctx context.Context, userID int32) ([]db.GetUserGameResultsRow, error) {
+	rows, err := q.Queries.GetUserGameResults(ctx, int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]db.GetUserGameResultsRow, len(rows))
+	for i, row := range rows {
+		converted[i] = db.GetUserGameResultsRow{
+			GameID:     row.GameID,
+			QuizID:     row.QuizID,
+			FinishedAt: row.FinishedAt,
+			Score:      row.Score,
+			Rank:       int32(row.Rank),
+		}
+	}
+	return converted, nil
+}
+
+// DeleteUserGameResults DeleteUserGameResults() implementation from db.Querier interface
+func (q *Querier) DeleteUserGameResults(ctx context.Context, userID int32) (int64, error) {
+	return q.Queries.DeleteUserGameResults(ctx, int64(userID))
+}
+
+// GetUserLeaderboardScores GetUserLeaderboardScores() implementation from db.Querier interface
+func (q *Querier) GetUserLeaderboardScores(ctx context.Context, userID int32) ([]db.LeaderboardScore, error) {
+	scores, err := q.Queries.GetUserLeaderboardScores(ctx, int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]db.LeaderboardScore, len(scores))
+	for i, score := range scores {
+		converted[i] = db.LeaderboardScore{
+			Board:       score.Board,
+			UserID:      int32(score.UserID),
+			Score:       score.Score,
+			GamesPlayed: int32(score.GamesPlayed),
+			UpdatedAt:   score.UpdatedAt,
+			ExpiresAt:   score.ExpiresAt,
+		}
+	}
+	return converted, nil
+}
+
+// DeleteUserLeaderboardScores DeleteUserLeaderboardScores() implementation from db.Querier interface
+func (q *Querier) DeleteUserLeaderboardScores(ctx context.Context, userID int32) (int64, error) {
+	return q.Queries.DeleteUserLeaderboardScores(ctx, int64(userID))
+}
+
+// GetUserRatings GetUserRatings() implementation from db.Querier interface
+func (q *Querier) GetUserRatings(ctx context.Context, userID int32) ([]db.Rating, error) {
+	ratings, err := q.Queries.GetUserRatings(ctx, int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]db.Rating, len(ratings))
+	for i, rating := range ratings {
+		converted[i] = db.Rating{
+			UserID:      int32(rating.UserID),
+			Category:    rating.Category,
+			Rating:      rating.Rating,
+			Deviation:   rating.Deviation,
+			Volatility:  rating.Volatility,
+			GamesPlayed: int32(rating.GamesPlayed),
+			UpdatedAt:   rating.UpdatedAt,
+		}
+	}
+	return converted, nil
+}
+
+// DeleteUserRatings DeleteUserRatings() implementation from db.Querier interface
+func (q *Querier) DeleteUserRatings(ctx context.Context, userID int32) (int64, error) {
+	return q.Queries.DeleteUserRatings(ctx, int64(userID))
+}
+
+// toGame Convert a SQLite game row to the shared db.Game model
+func toGame(game sqlitedb.Game) db.Game {
+	return db.Game{
+		ID:         game.ID,
+		Code:       game.Code,
+		QuizID:     game.QuizID,
+		FinishedAt: game.FinishedAt,
+	}
+}
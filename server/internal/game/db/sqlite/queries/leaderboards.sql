@@ -0,0 +1,76 @@
+-- name: CreateGame :one
+INSERT INTO games (code, quiz_id, finished_at)
+VALUES (sqlc.arg(code), sqlc.arg(quiz_id), sqlc.arg(finished_at))
+    RETURNING *;
+
+-- name: GetGame :one
+SELECT *
+FROM games
+WHERE id = sqlc.arg(id);
+
+-- name: CreateGameResult :exec
+INSERT INTO game_results (game_id, user_id, score, rank)
+VALUES (sqlc.arg(game_id), sqlc.arg(user_id), sqlc.arg(score), sqlc.arg(rank));
+
+-- name: GetGameResults :many
+SELECT *
+FROM game_results
+WHERE game_id = sqlc.arg(game_id)
+ORDER BY rank, user_id;
+
+-- name: AddLeaderboardScore :exec
+INSERT INTO leaderboard_scores (board, user_id, score, games_played, updated_at, expires_at)
+VALUES (sqlc.arg(board), sqlc.arg(user_id), sqlc.arg(score), 1, sqlc.arg(updated_at), sqlc.arg(expires_at))
+ON CONFLICT (board, user_id) DO UPDATE
+SET score = leaderboard_scores.score + excluded.score,
+    games_played = leaderboard_scores.games_played + 1,
+    updated_at = excluded.updated_at;
+
+-- name: GetLeaderboardScore :one
+SELECT *
+FROM leaderboard_scores
+WHERE board = sqlc.arg(board)
+    AND user_id = sqlc.arg(user_id);
+
+-- name: GetLeaderboardPage :many
+-- Players with the same score share the best of their ranks, and are listed by user id
+SELECT user_id, score, games_played, CAST(RANK() OVER (ORDER BY score DESC) AS INTEGER) AS rank
+FROM leaderboard_scores
+WHERE board = sqlc.arg(board)
+ORDER BY score DESC, user_id
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
+
+-- name: CountLeaderboardScoresAhead :one
+-- Number of players listed before the player with the score and user id, which is their position on the board
+SELECT
+    COUNT(*)
+FROM
+    leaderboard_scores
+WHERE board = sqlc.arg(board)
+    AND (score > sqlc.arg(score) OR (score = sqlc.arg(score) AND user_id < sqlc.arg(user_id)));
+
+-- name: DeleteExpiredLeaderboardScores :execrows
+DELETE FROM leaderboard_scores
+WHERE expires_at <= sqlc.arg(expires_at);
+
+-- name: GetUserGameResults :many
+SELECT game_results.game_id, games.quiz_id, games.finished_at, game_results.score, game_results.rank
+FROM game_results
+    JOIN games ON games.id = game_results.game_id
+WHERE game_results.user_id = sqlc.arg(user_id)
+ORDER BY games.finished_at, game_results.game_id;
+
+-- name: DeleteUserGameResults :execrows
+DELETE FROM game_results
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: GetUserLeaderboardScores :many
+SELECT *
+FROM leaderboard_scores
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY board;
+
+-- name: DeleteUserLeaderboardScores :execrows
+DELETE FROM leaderboard_scores
+WHERE user_id = sqlc.arg(user_id);
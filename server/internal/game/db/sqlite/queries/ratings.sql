@@ -0,0 +1,33 @@
+-- name: GetRating :one
+SELECT *
+FROM ratings
+WHERE user_id = sqlc.arg(user_id)
+    AND category = sqlc.arg(category);
+
+-- name: UpsertRating :exec
+INSERT INTO ratings (user_id, category, rating, deviation, volatility, games_played, updated_at)
+VALUES (
+    sqlc.arg(user_id),
+    sqlc.arg(category),
+    sqlc.arg(rating),
+    sqlc.arg(deviation),
+    sqlc.arg(volatility),
+    1,
+    sqlc.arg(updated_at)
+)
+ON CONFLICT (user_id, category) DO UPDATE
+SET rating = excluded.rating,
+    deviation = excluded.deviation,
+    volatility = excluded.volatility,
+    games_played = ratings.games_played + 1,
+    updated_at = excluded.updated_at;
+
+-- name: GetUserRatings :many
+SELECT *
+FROM ratings
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY category;
+
+-- name: DeleteUserRatings :execrows
+DELETE FROM ratings
+WHERE user_id = sqlc.arg(user_id);
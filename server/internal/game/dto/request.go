@@ -0,0 +1,130 @@
+package dto
+
+import (
+	quizdto "quiz/dto"
+	"time"
+)
+
+type CreateLobbyRequest struct {
+	HostId     int           `json:"hostId"`
+	HostName   string        `json:"hostName"`
+	QuizId     int64         `json:"quizId"`
+	MaxPlayers int           `json:"maxPlayers"`
+	Scoring    *ScoringRules `json:"scoring,omitempty"`
+}
+
+// ScoringRules How the game of a lobby turns graded answers into points. Rules left at zero are not applied
+type ScoringRules struct {
+	// Points Points for a correct answer
+	Points float64 `json:"points"`
+	// PartialCredit Whether partly correct answers, such as some of the options of a multiple select question, earn
+	// their share of the points rather than nothing
+	PartialCredit bool `json:"partialCredit"`
+	// TimeDecay Share of the points lost by answering at the time limit rather than straight away, from 0 to 1. The
+	// loss grows linearly with the response time
+	TimeDecay float64 `json:"timeDecay"`
+	// StreakBonus Multiplier added to the points for each correct answer in a row right before this one
+	StreakBonus float64 `json:"streakBonus"`
+	// MaxStreakMultiplier Largest multiplier a streak can reach, or 0 for no limit
+	MaxStreakMultiplier float64 `json:"maxStreakMultiplier"`
+	// WrongPenalty Points taken away for an answer that earns nothing. Questions left unanswered cost nothing
+	WrongPenalty float64 `json:"wrongPenalty"`
+}
+
+type GetLobbyRequest struct {
+	Code string `json:"code"`
+}
+
+type JoinLobbyRequest struct {
+	UserId   int    `json:"userId"`
+	Username string `json:"username"`
+	Code     string `json:"code"`
+}
+
+type LeaveLobbyRequest struct {
+	UserId int    `json:"userId"`
+	Code   string `json:"code"`
+}
+
+type SetReadyRequest struct {
+	UserId int    `json:"userId"`
+	Code   string `json:"code"`
+	Ready  bool   `json:"ready"`
+}
+
+type ConnectRequest struct {
+	UserId int    `json:"userId"`
+	Code   string `json:"code"`
+}
+
+type StartGameRequest struct {
+	UserId int    `json:"userId"`
+	Code   string `json:"code"`
+}
+
+type SubmitAnswerRequest struct {
+	UserId     int            `json:"userId"`
+	Code       string         `json:"code"`
+	QuestionId int64          `json:"questionId"`
+	Answer     quizdto.Answer `json:"answer"`
+}
+
+type NextQuestionRequest struct {
+	UserId int    `json:"userId"`
+	Code   string `json:"code"`
+}
+
+// RecordGameRequest Results of a finished game, ingested into the leaderboards
+type RecordGameRequest struct {
+	Code       string       `json:"code"`
+	QuizId     int64        `json:"quizId"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Results    []GameResult `json:"results"`
+	// Ranked Whether the game updates the players' ratings, overall and in Category if it is set
+	Ranked   bool   `json:"ranked"`
+	Category string `json:"category"`
+}
+
+type GetGameResultsRequest struct {
+	GameId int64 `json:"gameId"`
+}
+
+type GetLeaderboardRequest struct {
+	UserId int    `json:"userId"`
+	Board  string `json:"board"`
+	// QuizId Quiz of a per-quiz board
+	QuizId int64 `json:"quizId"`
+	Limit  *int  `json:"limit"`
+	Offset *int  `json:"offset"`
+	// AroundMe Whether the page is centered on the requesting user rather than starting at Offset
+	AroundMe bool `json:"aroundMe"`
+}
+
+type GetRatingRequest struct {
+	UserId int `json:"userId"`
+	// Category Question bank category of the rating, or empty for the overall rating
+	Category string `json:"category"`
+}
+
+type EnqueueRequest struct {
+	UserId   int    `json:"userId"`
+	Username string `json:"username"`
+	// Category Question bank category to be matched for, or empty for questions of any category
+	Category string `json:"category"`
+}
+
+type CancelQueueRequest struct {
+	UserId int `json:"userId"`
+}
+
+type ConnectMatchmakingRequest struct {
+	UserId int `json:"userId"`
+}
+
+type GetUserDataRequest struct {
+	UserId int `json:"userId"`
+}
+
+type DeleteUserDataRequest struct {
+	UserId int `json:"userId"`
+}
@@ -0,0 +1,151 @@
+package dto
+
+import (
+	"time"
+)
+
+type Player struct {
+	UserId   int       `json:"userId"`
+	Username string    `json:"username"`
+	IsHost   bool      `json:"isHost"`
+	Ready    bool      `json:"ready"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// Lobby A game room players join with its code before the game starts. Players are listed in the order they joined
+type Lobby struct {
+	Code       string       `json:"code"`
+	QuizId     int64        `json:"quizId"`
+	QuizTitle  string       `json:"quizTitle"`
+	HostId     int          `json:"hostId"`
+	State      string       `json:"state"`
+	MaxPlayers int          `json:"maxPlayers"`
+	Players    []Player     `json:"players"`
+	Scoring    ScoringRules `json:"scoring"`
+	// Ranked Whether the lobby was made by matchmaking, so its game updates the players' ratings
+	Ranked bool `json:"ranked"`
+	// Category Question bank category a ranked lobby's quiz was drawn from, if any
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type CreateLobbyResponse = Lobby
+
+type GetLobbyResponse = Lobby
+
+type JoinLobbyResponse = Lobby
+
+type LeaveLobbyResponse struct{}
+
+type SetReadyResponse = Lobby
+
+type StartGameResponse struct{}
+
+type SubmitAnswerResponse struct{}
+
+type NextQuestionResponse struct{}
+
+// GameResult A player's final score and rank in a finished game
+type GameResult struct {
+	UserId   int     `json:"userId"`
+	Username string  `json:"username"`
+	Score    float64 `json:"score"`
+	Rank     int     `json:"rank"`
+}
+
+// Game A finished game with its results, ordered by rank
+type Game struct {
+	GameId     int64        `json:"gameId"`
+	Code       string       `json:"code"`
+	QuizId     int64        `json:"quizId"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Results    []GameResult `json:"results"`
+}
+
+type RecordGameResponse = Game
+
+type GetGameResultsResponse = Game
+
+// LeaderboardEntry A player's standing on a leaderboard. Players with the same score share a rank
+type LeaderboardEntry struct {
+	Rank        int     `json:"rank"`
+	UserId      int     `json:"userId"`
+	Username    string  `json:"username"`
+	Score       float64 `json:"score"`
+	GamesPlayed int     `json:"gamesPlayed"`
+}
+
+// Leaderboard A page of a leaderboard, ordered by rank and by user id among equal scores
+type Leaderboard struct {
+	Board  string `json:"board"`
+	QuizId int64  `json:"quizId,omitempty"`
+	// StartsAt Start of the week a weekly board covers
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	// EndsAt End of the week a weekly board covers, when it resets
+	EndsAt  *time.Time         `json:"endsAt,omitempty"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+type GetLeaderboardResponse = Leaderboard
+
+// PlayerRating A player's Glicko-2 skill rating. Players who have not played a ranked game have the default rating
+type PlayerRating struct {
+	UserId   int     `json:"userId"`
+	Category string  `json:"category,omitempty"`
+	Rating   float64 `json:"rating"`
+	// Deviation Uncertainty of the rating: the player's strength is within 2 deviations of it with 95% confidence
+	Deviation   float64 `json:"deviation"`
+	Volatility  float64 `json:"volatility"`
+	GamesPlayed int     `json:"gamesPlayed"`
+}
+
+type GetRatingResponse = PlayerRating
+
+// Ticket A player's place in the matchmaking queue
+type Ticket struct {
+	UserId   int    `json:"userId"`
+	Category string `json:"category,omitempty"`
+	// Rating Rating the player is matched by, in the category if there is one
+	Rating     float64   `json:"rating"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+}
+
+type EnqueueResponse = Ticket
+
+type CancelQueueResponse struct{}
+
+// UserGame A finished game the user played, with their score and rank in it
+type UserGame struct {
+	GameId     int64     `json:"gameId"`
+	QuizId     int64     `json:"quizId"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Score      float64   `json:"score"`
+	Rank       int       `json:"rank"`
+}
+
+// UserLeaderboardScore The user's score on a leaderboard
+type UserLeaderboardScore struct {
+	Board       string     `json:"board"`
+	Score       float64    `json:"score"`
+	GamesPlayed int        `json:"gamesPlayed"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// UserData Everything the game service stores about a user, for their personal data export
+type UserData struct {
+	UserId            int                    `json:"userId"`
+	Games             []UserGame             `json:"games"`
+	LeaderboardScores []UserLeaderboardScore `json:"leaderboardScores"`
+	Ratings           []PlayerRating         `json:"ratings"`
+}
+
+type GetUserDataResponse = UserData
+
+// DeleteUserDataResponse How many of the user's game results, leaderboard scores and ratings were deleted
+type DeleteUserDataResponse struct {
+	Games             int64 `json:"games"`
+	LeaderboardScores int64 `json:"leaderboardScores"`
+	Ratings           int64 `json:"ratings"`
+}
@@ -0,0 +1,439 @@
+package game
+
+import (
+	"common"
+	"encoding/json"
+	"errors"
+	"game/dto"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// CreateLobbyHandler Handler function for create lobby endpoint
+func CreateLobbyHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateCreateLobbyRequest(r, userClaims)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateCreateLobbyRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.CreateLobby(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/lobby/"+response.Code)
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetLobbyHandler Handler function for get lobby endpoint
+func GetLobbyHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := common.GetUserClaims(r.Context()); !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.GetLobbyRequest{Code: parseJoinCode(r)}
+
+		if err := ValidateGetLobbyRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetLobby(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// JoinLobbyHandler Handler function for join lobby endpoint
+func JoinLobbyHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.JoinLobbyRequest{
+			UserId:   userClaims.ID,
+			Username: userClaims.Username,
+			Code:     parseJoinCode(r),
+		}
+
+		if err := ValidateJoinLobbyRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.JoinLobby(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// LeaveLobbyHandler Handler function for leave lobby endpoint
+func LeaveLobbyHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.LeaveLobbyRequest{
+			UserId: userClaims.ID,
+			Code:   parseJoinCode(r),
+		}
+
+		if err := ValidateLeaveLobbyRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if _, err := service.LeaveLobby(r.Context(), &request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// SetReadyHandler Handler function for set ready endpoint
+func SetReadyHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateSetReadyRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateSetReadyRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.SetReady(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetGameResultsHandler Handler function for get game results endpoint
+func GetGameResultsHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := common.GetUserClaims(r.Context()); !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		gameId, err := strconv.ParseInt(chi.URLParam(r, "gameId"), 10, 64)
+		if err != nil {
+			handleError(&common.HTTPError{StatusCode: http.StatusBadRequest, Message: "invalid game id"}, w, r)
+			return
+		}
+		request := dto.GetGameResultsRequest{GameId: gameId}
+
+		if err := ValidateGetGameResultsRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetGameResults(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetLeaderboardHandler Handler function for the get leaderboard endpoints, serving the specified board
+func GetLeaderboardHandler(service Service, board string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateGetLeaderboardRequest(r, userClaims.ID, board)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateGetLeaderboardRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetLeaderboard(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetRatingHandler Handler function for get rating endpoint, serving the signed-in user's rating
+func GetRatingHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.GetRatingRequest{
+			UserId:   userClaims.ID,
+			Category: r.URL.Query().Get("category"),
+		}
+
+		if err := ValidateGetRatingRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetRating(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// EnqueueHandler Handler function for enqueue endpoint
+func EnqueueHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateEnqueueRequest(r, userClaims)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateEnqueueRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.Enqueue(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// CancelQueueHandler Handler function for cancel queue endpoint
+func CancelQueueHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.CancelQueueRequest{UserId: userClaims.ID}
+
+		if _, err := service.CancelQueue(r.Context(), &request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// generateCreateLobbyRequest Populate and return CreateLobbyRequest hosted by the signed-in user
+func generateCreateLobbyRequest(r *http.Request, userClaims *common.UserClaims) (*dto.CreateLobbyRequest, error) {
+	var request dto.CreateLobbyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+	request.HostId = userClaims.ID
+	request.HostName = userClaims.Username
+
+	return &request, nil
+}
+
+// generateSetReadyRequest Populate and return SetReadyRequest for the specified user
+func generateSetReadyRequest(r *http.Request, userId int) (*dto.SetReadyRequest, error) {
+	var request dto.SetReadyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+	request.UserId = userId
+	request.Code = parseJoinCode(r)
+
+	return &request, nil
+}
+
+// generateEnqueueRequest Populate and return EnqueueRequest for the signed-in user
+func generateEnqueueRequest(r *http.Request, userClaims *common.UserClaims) (*dto.EnqueueRequest, error) {
+	var request dto.EnqueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+	request.UserId = userClaims.ID
+	request.Username = userClaims.Username
+
+	return &request, nil
+}
+
+// generateGetLeaderboardRequest Populate and return GetLeaderboardRequest for the specified user and board
+func generateGetLeaderboardRequest(r *http.Request, userId int, board string) (*dto.GetLeaderboardRequest, error) {
+	query := r.URL.Query()
+	request := dto.GetLeaderboardRequest{UserId: userId, Board: board}
+
+	if board == BoardQuiz {
+		quizId, err := strconv.ParseInt(chi.URLParam(r, "quizId"), 10, 64)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid quiz id",
+			}
+		}
+		request.QuizId = quizId
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid limit",
+			}
+		}
+		request.Limit = &limit
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid offset",
+			}
+		}
+		request.Offset = &offset
+	}
+
+	if around := query.Get("around"); around != "" {
+		if around != "me" {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    `around must be "me"`,
+			}
+		}
+		request.AroundMe = true
+	}
+
+	return &request, nil
+}
+
+// parseJoinCode Get the join code path parameter in normalized form
+func parseJoinCode(r *http.Request) string {
+	return NormalizeJoinCode(chi.URLParam(r, "code"))
+}
+
+// handleError Write the appropriate response given an error, logging errors that result in a 500
+func handleError(err error, w http.ResponseWriter, r *http.Request) {
+	var httpErr *common.HTTPError
+	if errors.As(err, &httpErr) {
+		http.Error(w, httpErr.Error(), httpErr.StatusCode)
+	} else {
+		slog.ErrorContext(r.Context(), "unhandled service error", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
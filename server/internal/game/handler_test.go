@@ -0,0 +1,415 @@
+package game
+
+import (
+	"common"
+	"context"
+	"encoding/json"
+	"game/dto"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newAuthenticatedRequest(method string, target string, body string) *http.Request {
+	userClaims := &common.UserClaims{
+		ID:       HostId,
+		Username: HostName,
+		Email:    "test@email.com",
+	}
+	ctx := context.WithValue(context.Background(), common.UsersClaimKey, userClaims)
+	return httptest.NewRequestWithContext(ctx, method, target, strings.NewReader(body))
+}
+
+func TestCreateLobbyHandler_Success(t *testing.T) {
+	var received *dto.CreateLobbyRequest
+	service := &mockService{
+		createLobbyFunc: func(context context.Context, request *dto.CreateLobbyRequest) (*dto.CreateLobbyResponse, error) {
+			received = request
+			return &dto.Lobby{Code: "ABC234", QuizId: request.QuizId, HostId: request.HostId}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/lobby", `{"quizId": 3, "hostId": 9}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/lobby", CreateLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	if location := recorder.Header().Get("Location"); location != "/lobby/ABC234" {
+		t.Errorf(`Location = "%s", expected "/lobby/ABC234"`, location)
+	}
+	if received == nil || received.HostId != HostId || received.HostName != HostName || received.QuizId != QuizId {
+		t.Errorf(`received = "%+v", expected the host from the user claims "%d"`, received, HostId)
+	}
+
+	var response dto.CreateLobbyResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if response.Code != "ABC234" {
+		t.Errorf(`response.Code = "%s", expected "ABC234"`, response.Code)
+	}
+}
+
+func TestCreateLobbyHandler_MissingUserClaims(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodPost, "/lobby", strings.NewReader(`{"quizId": 3}`))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/lobby", CreateLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestNewRouter_Authentication(t *testing.T) {
+	hostToken := newTestToken(t, HostId, HostName)
+	guestToken := newTestToken(t, GuestId, "guest")
+	service, _ := newTestService()
+	router := NewRouter(service, nil, slog.Default())
+
+	serve := func(method string, target string, token string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := serve(http.MethodPost, "/lobby", "", `{"quizId": 3}`); recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v" without a token, expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+
+	recorder := serve(http.MethodPost, "/lobby", hostToken, `{"quizId": 3}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	var lobby dto.CreateLobbyResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&lobby); err != nil {
+		t.Fatalf(`json.NewDecoder(recorder.Body).Decode(&lobby) = "%v", expected "<nil>"`, err)
+	}
+	if lobby.HostId != HostId {
+		t.Errorf(`lobby.HostId = "%d", expected the user of the token "%d"`, lobby.HostId, HostId)
+	}
+
+	recorder = serve(http.MethodPost, "/lobby/"+lobby.Code+"/join", guestToken, "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v" joining, expected "%v"`, recorder.Code, http.StatusOK)
+	}
+	if recorder := serve(http.MethodGet, "/rating", guestToken, ""); recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v" for the rating, expected "%v"`, recorder.Code, http.StatusOK)
+	}
+}
+
+func TestCreateLobbyHandler_ValidationError(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/lobby", `{"quizId": 3, "maxPlayers": 1}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/lobby", CreateLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetLobbyHandler_NotFound(t *testing.T) {
+	service := &mockService{
+		getLobbyFunc: func(context context.Context, request *dto.GetLobbyRequest) (*dto.GetLobbyResponse, error) {
+			return nil, lobbyNotFoundError
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/lobby/ABC234", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/lobby/{code}", GetLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestJoinLobbyHandler_Success(t *testing.T) {
+	var received *dto.JoinLobbyRequest
+	service := &mockService{
+		joinLobbyFunc: func(context context.Context, request *dto.JoinLobbyRequest) (*dto.JoinLobbyResponse, error) {
+			received = request
+			return &dto.Lobby{Code: request.Code}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/lobby/abc-234/join", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/lobby/{code}/join", JoinLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+	if received == nil || received.Code != "ABC234" || received.UserId != HostId || received.Username != HostName {
+		t.Errorf(`received = "%+v", expected code "ABC234" for the user from the user claims`, received)
+	}
+}
+
+func TestJoinLobbyHandler_InvalidCode(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/lobby/ABC0OI/join", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/lobby/{code}/join", JoinLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLeaveLobbyHandler_Success(t *testing.T) {
+	service := &mockService{
+		leaveLobbyFunc: func(context context.Context, request *dto.LeaveLobbyRequest) (*dto.LeaveLobbyResponse, error) {
+			if request.UserId != HostId || request.Code != "ABC234" {
+				t.Errorf(`request = "%+v", expected user "%d" and code "ABC234"`, request, HostId)
+			}
+			return &dto.LeaveLobbyResponse{}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/lobby/ABC234/leave", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/lobby/{code}/leave", LeaveLobbyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestSetReadyHandler_Success(t *testing.T) {
+	var received *dto.SetReadyRequest
+	service := &mockService{
+		setReadyFunc: func(context context.Context, request *dto.SetReadyRequest) (*dto.SetReadyResponse, error) {
+			received = request
+			return &dto.Lobby{Code: request.Code}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPut, "/lobby/ABC234/ready", `{"ready": true}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Put("/lobby/{code}/ready", SetReadyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+	if received == nil || !received.Ready || received.UserId != HostId || received.Code != "ABC234" {
+		t.Errorf(`received = "%+v", expected user "%d" ready in "ABC234"`, received, HostId)
+	}
+}
+
+func TestSetReadyHandler_InvalidBody(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPut, "/lobby/ABC234/ready", "{")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Put("/lobby/{code}/ready", SetReadyHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetLeaderboardHandler_Success(t *testing.T) {
+	var received *dto.GetLeaderboardRequest
+	service := &mockService{
+		getLeaderboardFunc: func(
+			context context.Context,
+			request *dto.GetLeaderboardRequest,
+		) (*dto.GetLeaderboardResponse, error) {
+			received = request
+			return &dto.Leaderboard{Board: request.Board, QuizId: request.QuizId}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/leaderboard/quiz/3?limit=5&around=me", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/leaderboard/quiz/{quizId}", GetLeaderboardHandler(service, BoardQuiz))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+	if received == nil || received.UserId != HostId || received.Board != BoardQuiz || received.QuizId != QuizId ||
+		received.Limit == nil || *received.Limit != 5 || !received.AroundMe {
+		t.Errorf(`received = "%+v", expected 5 entries of quiz "%d" around user "%d"`, received, QuizId, HostId)
+	}
+}
+
+func TestGetLeaderboardHandler_InvalidQuery(t *testing.T) {
+	service := &mockService{}
+
+	for _, target := range []string{
+		"/leaderboard/all-time?limit=ten",
+		"/leaderboard/all-time?limit=1000",
+		"/leaderboard/all-time?around=you",
+		"/leaderboard/all-time?offset=5&around=me",
+	} {
+		request := newAuthenticatedRequest(http.MethodGet, target, "")
+		recorder := httptest.NewRecorder()
+
+		r := chi.NewRouter()
+		r.Get("/leaderboard/all-time", GetLeaderboardHandler(service, BoardAllTime))
+		r.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf(`%s: recorder.Code = "%v", expected "%v"`, target, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetGameResultsHandler_NotFound(t *testing.T) {
+	service := &mockService{
+		getGameResultsFunc: func(
+			context context.Context,
+			request *dto.GetGameResultsRequest,
+		) (*dto.GetGameResultsResponse, error) {
+			return nil, gameNotFoundError
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/game/7/results", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/game/{gameId}/results", GetGameResultsHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestEnqueueHandler_Success(t *testing.T) {
+	var received *dto.EnqueueRequest
+	service := &mockService{
+		enqueueFunc: func(context context.Context, request *dto.EnqueueRequest) (*dto.EnqueueResponse, error) {
+			received = request
+			return &dto.Ticket{UserId: request.UserId, Category: request.Category, Rating: 1500}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/matchmaking", `{"category": "history", "userId": 9}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/matchmaking", EnqueueHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	if received == nil || received.UserId != HostId || received.Username != HostName || received.Category != "history" {
+		t.Errorf(`received = "%+v", expected user "%d" from the user claims queueing for "history"`, received, HostId)
+	}
+}
+
+func TestEnqueueHandler_ValidationError(t *testing.T) {
+	service := &mockService{}
+
+	body := `{"category": "` + strings.Repeat("a", 51) + `"}`
+	for _, body := range []string{"", "{", body} {
+		request := newAuthenticatedRequest(http.MethodPost, "/matchmaking", body)
+		recorder := httptest.NewRecorder()
+
+		r := chi.NewRouter()
+		r.Post("/matchmaking", EnqueueHandler(service))
+		r.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf(`%s: recorder.Code = "%v", expected "%v"`, body, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCancelQueueHandler_NotQueued(t *testing.T) {
+	service := &mockService{
+		cancelQueueFunc: func(
+			context context.Context,
+			request *dto.CancelQueueRequest,
+		) (*dto.CancelQueueResponse, error) {
+			if request.UserId != HostId {
+				t.Errorf(`request.UserId = "%d", expected "%d"`, request.UserId, HostId)
+			}
+			return nil, notQueuedError
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodDelete, "/matchmaking", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Delete("/matchmaking", CancelQueueHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetRatingHandler_Success(t *testing.T) {
+	service := &mockService{
+		getRatingFunc: func(context context.Context, request *dto.GetRatingRequest) (*dto.GetRatingResponse, error) {
+			return &dto.PlayerRating{UserId: request.UserId, Category: request.Category, Rating: 1620}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/rating?category=history", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/rating", GetRatingHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	var response dto.GetRatingResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if response.UserId != HostId || response.Category != "history" || response.Rating != 1620 {
+		t.Errorf(`response = "%+v", expected the "history" rating of user "%d"`, response, HostId)
+	}
+}
@@ -0,0 +1,402 @@
+package game
+
+import (
+	"common"
+	"common/userpb"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"game/db/generated"
+	"game/dto"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	BoardAllTime = "all-time"
+	BoardWeekly  = "weekly"
+	BoardQuiz    = "quiz"
+)
+
+const (
+	DefaultLeaderboardLimit = 10
+	MaxLeaderboardLimit     = 100
+)
+
+// UserDirectory Resolves user ids to users. userpb.UserServiceClient satisfies it
+type UserDirectory interface {
+	BatchGetUsers(
+		ctx context.Context,
+		in *userpb.BatchGetUsersRequest,
+		opts ...grpc.CallOption,
+	) (*userpb.BatchGetUsersResponse, error)
+}
+
+// gameNotFoundError Returned for games that were never recorded
+var gameNotFoundError = &common.HTTPError{
+	StatusCode: http.StatusNotFound,
+	Message:    "game not found",
+}
+
+// RecordGame Store the results of a finished game and add each player's score to the all-time board, the board of the
+// week the game finished in and the board of its quiz. The ratings of the players of a ranked game are updated with
+// them
+func (service *ServiceImpl) RecordGame(
+	context context.Context,
+	request *dto.RecordGameRequest,
+) (*dto.RecordGameResponse, error) {
+	weekStart := WeekStart(request.FinishedAt)
+	weekEnd := sql.NullTime{Time: weekStart.AddDate(0, 0, 7), Valid: true}
+	boards := []struct {
+		key       string
+		expiresAt sql.NullTime
+	}{
+		{key: BoardAllTime},
+		{key: weeklyBoardKey(weekStart), expiresAt: weekEnd},
+		{key: quizBoardKey(request.QuizId)},
+	}
+
+	var response *dto.Game
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			game, err := queries.CreateGame(
+				context, db.CreateGameParams{
+					Code:       request.Code,
+					QuizID:     request.QuizId,
+					FinishedAt: request.FinishedAt,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, result := range request.Results {
+				err := queries.CreateGameResult(
+					context, db.CreateGameResultParams{
+						GameID: game.ID,
+						UserID: int32(result.UserId),
+						Score:  result.Score,
+						Rank:   int32(result.Rank),
+					},
+				)
+				if err != nil {
+					return err
+				}
+
+				for _, board := range boards {
+					err := queries.AddLeaderboardScore(
+						context, db.AddLeaderboardScoreParams{
+							Board:     board.key,
+							UserID:    int32(result.UserId),
+							Score:     result.Score,
+							UpdatedAt: request.FinishedAt,
+							ExpiresAt: board.expiresAt,
+						},
+					)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			if request.Ranked {
+				err := updateRatings(context, queries, request.Results, request.Category, request.FinishedAt)
+				if err != nil {
+					return err
+				}
+			}
+
+			response = &dto.Game{
+				GameId:     game.ID,
+				Code:       game.Code,
+				QuizId:     game.QuizID,
+				FinishedAt: game.FinishedAt,
+				Results:    request.Results,
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record game: %w", err)
+	}
+
+	return response, nil
+}
+
+// GetGameResults Retrieve the results of a finished game
+func (service *ServiceImpl) GetGameResults(
+	context context.Context,
+	request *dto.GetGameResultsRequest,
+) (*dto.GetGameResultsResponse, error) {
+	var response *dto.Game
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			game, err := queries.GetGame(context, request.GameId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return gameNotFoundError
+			} else if err != nil {
+				return err
+			}
+
+			results, err := queries.GetGameResults(context, game.ID)
+			if err != nil {
+				return err
+			}
+
+			response = &dto.Game{
+				GameId:     game.ID,
+				Code:       game.Code,
+				QuizId:     game.QuizID,
+				FinishedAt: game.FinishedAt,
+				Results:    make([]dto.GameResult, len(results)),
+			}
+			for i, result := range results {
+				response.Results[i] = dto.GameResult{
+					UserId: int(result.UserID),
+					Score:  result.Score,
+					Rank:   int(result.Rank),
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := make([]int, len(response.Results))
+	for i, result := range response.Results {
+		userIds[i] = result.UserId
+	}
+	usernames := service.resolveUsernames(context, userIds)
+	for i := range response.Results {
+		response.Results[i].Username = usernames[response.Results[i].UserId]
+	}
+
+	return response, nil
+}
+
+// GetLeaderboard Retrieve a page of a leaderboard, either from an offset or centered on the requesting user. The
+// weekly board is the one of the current week
+func (service *ServiceImpl) GetLeaderboard(
+	context context.Context,
+	request *dto.GetLeaderboardRequest,
+) (*dto.GetLeaderboardResponse, error) {
+	response := &dto.Leaderboard{Board: request.Board}
+	var key string
+	switch request.Board {
+	case BoardAllTime:
+		key = BoardAllTime
+	case BoardWeekly:
+		startsAt := WeekStart(service.Now())
+		endsAt := startsAt.AddDate(0, 0, 7)
+		response.StartsAt, response.EndsAt = &startsAt, &endsAt
+		key = weeklyBoardKey(startsAt)
+	case BoardQuiz:
+		response.QuizId = request.QuizId
+		key = quizBoardKey(request.QuizId)
+	default:
+		return nil, fmt.Errorf("unknown leaderboard %q", request.Board)
+	}
+
+	limit := DefaultLeaderboardLimit
+	if request.Limit != nil {
+		limit = *request.Limit
+	}
+	offset := 0
+	if request.Offset != nil {
+		offset = *request.Offset
+	}
+
+	var rows []db.GetLeaderboardPageRow
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			if request.AroundMe {
+				score, err := queries.GetLeaderboardScore(
+					context, db.GetLeaderboardScoreParams{Board: key, UserID: int32(request.UserId)},
+				)
+				if errors.Is(err, sql.ErrNoRows) {
+					return &common.HTTPError{
+						StatusCode: http.StatusNotFound,
+						Message:    "not ranked on this leaderboard",
+					}
+				} else if err != nil {
+					return err
+				}
+
+				ahead, err := queries.CountLeaderboardScoresAhead(
+					context, db.CountLeaderboardScoresAheadParams{
+						Board:  key,
+						Score:  score.Score,
+						UserID: score.UserID,
+					},
+				)
+				if err != nil {
+					return err
+				}
+				offset = max(int(ahead)-limit/2, 0)
+			}
+
+			var err error
+			rows, err = queries.GetLeaderboardPage(
+				context, db.GetLeaderboardPageParams{Board: key, Limit: int32(limit), Offset: int32(offset)},
+			)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := make([]int, len(rows))
+	for i, row := range rows {
+		userIds[i] = int(row.UserID)
+	}
+	usernames := service.resolveUsernames(context, userIds)
+
+	response.Entries = make([]dto.LeaderboardEntry, len(rows))
+	for i, row := range rows {
+		response.Entries[i] = dto.LeaderboardEntry{
+			Rank:        int(row.Rank),
+			UserId:      int(row.UserID),
+			Username:    usernames[int(row.UserID)],
+			Score:       row.Score,
+			GamesPlayed: int(row.GamesPlayed),
+		}
+	}
+
+	return response, nil
+}
+
+// ResetWeeklyLeaderboards Remove the scores of weekly boards whose week has ended, returning how many were removed
+func (service *ServiceImpl) ResetWeeklyLeaderboards(context context.Context) (int64, error) {
+	deleted, err := service.Queries.DeleteExpiredLeaderboardScores(
+		context, sql.NullTime{Time: service.Now(), Valid: true},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset weekly leaderboards: %w", err)
+	}
+	return deleted, nil
+}
+
+// recordFinishedGame Record the final standings of a lobby's game in the background, logging any failure. The mutex
+// must be held
+func (service *ServiceImpl) recordFinishedGame(lobby *lobby) {
+	if service.Queries == nil {
+		return
+	}
+
+	standings := lobby.standings()
+	request := &dto.RecordGameRequest{
+		Code:       lobby.code,
+		QuizId:     lobby.quizId,
+		FinishedAt: service.Now(),
+		Results:    make([]dto.GameResult, len(standings)),
+		Ranked:     lobby.ranked,
+		Category:   lobby.category,
+	}
+	for i, entry := range standings {
+		request.Results[i] = dto.GameResult{
+			UserId:   entry.UserId,
+			Username: entry.Username,
+			Score:    entry.Score,
+			Rank:     entry.Rank,
+		}
+	}
+
+	service.recording.Add(1)
+	go func() {
+		defer service.recording.Done()
+		if _, err := service.RecordGame(context.Background(), request); err != nil {
+			slog.Error("failed to record finished game", slog.String("code", request.Code), slog.Any("error", err))
+		}
+	}()
+}
+
+// resolveUsernames Get the usernames of the users by id from the user service. Users it does not know, and every user
+// when it cannot be reached, are left out so leaderboards are still served without names
+func (service *ServiceImpl) resolveUsernames(context context.Context, userIds []int) map[int]string {
+	usernames := map[int]string{}
+	if service.Users == nil || len(userIds) == 0 {
+		return usernames
+	}
+
+	ids := make([]int64, len(userIds))
+	for i, userId := range userIds {
+		ids[i] = int64(userId)
+	}
+
+	response, err := service.Users.BatchGetUsers(context, &userpb.BatchGetUsersRequest{Ids: ids})
+	if err != nil {
+		slog.WarnContext(context, "failed to resolve usernames", slog.Any("error", err))
+		return usernames
+	}
+
+	for _, user := range response.GetUsers() {
+		usernames[int(user.GetId())] = user.GetUsername()
+	}
+	return usernames
+}
+
+// WeekStart Get the start of the week the time falls in: midnight UTC on its Monday
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// weeklyBoardKey Get the key in the leaderboard_scores table of the board of the week starting at weekStart
+func weeklyBoardKey(weekStart time.Time) string {
+	return BoardWeekly + ":" + weekStart.Format(time.DateOnly)
+}
+
+// quizBoardKey Get the key of the board of a quiz
+func quizBoardKey(quizId int64) string {
+	return BoardQuiz + ":" + strconv.FormatInt(quizId, 10)
+}
+
+// LeaderboardResetter Background job resetting the weekly leaderboards when each week ends
+type LeaderboardResetter struct {
+	Service *ServiceImpl
+	Logger  *slog.Logger
+	// RetryInterval How long to wait before trying again after a reset fails
+	RetryInterval time.Duration
+}
+
+// NewLeaderboardResetter Create a resetter for the service's leaderboards
+func NewLeaderboardResetter(service *ServiceImpl, logger *slog.Logger) *LeaderboardResetter {
+	return &LeaderboardResetter{
+		Service:       service,
+		Logger:        logger,
+		RetryInterval: time.Minute,
+	}
+}
+
+// Run Reset the weekly leaderboards straight away, catching up on resets missed while the service was down, then at
+// the start of every week until the context is cancelled
+func (resetter *LeaderboardResetter) Run(ctx context.Context) {
+	for {
+		now := resetter.Service.Now()
+		wait := WeekStart(now).AddDate(0, 0, 7).Sub(now)
+
+		deleted, err := resetter.Service.ResetWeeklyLeaderboards(ctx)
+		if err != nil {
+			resetter.Logger.ErrorContext(ctx, "failed to reset weekly leaderboards", slog.Any("error", err))
+			wait = min(wait, resetter.RetryInterval)
+		} else if deleted > 0 {
+			resetter.Logger.InfoContext(ctx, "reset weekly leaderboards", slog.Int64("count", deleted))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
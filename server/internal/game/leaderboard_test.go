@@ -0,0 +1,207 @@
+package game
+
+import (
+	"context"
+	"game/dto"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// recordTestGame Record a game of the quiz finished at the time, with the scores by user id ranked as given
+func recordTestGame(t *testing.T, service *ServiceImpl, quizId int64, finishedAt time.Time, results ...dto.GameResult) {
+	_, err := service.RecordGame(
+		context.Background(),
+		&dto.RecordGameRequest{Code: "ABC234", QuizId: quizId, FinishedAt: finishedAt, Results: results},
+	)
+	if err != nil {
+		t.Fatalf(`service.RecordGame(...) error = "%v", expected "<nil>"`, err)
+	}
+}
+
+// getTestLeaderboard Get a leaderboard as the host, failing on error
+func getTestLeaderboard(t *testing.T, service *ServiceImpl, request dto.GetLeaderboardRequest) *dto.Leaderboard {
+	request.UserId = HostId
+	leaderboard, err := service.GetLeaderboard(context.Background(), &request)
+	if err != nil {
+		t.Fatalf(`service.GetLeaderboard(ctx, "%s") error = "%v", expected "<nil>"`, request.Board, err)
+	}
+	return leaderboard
+}
+
+// assertEntries Check the user ids, ranks and scores of a leaderboard's entries
+func assertEntries(t *testing.T, leaderboard *dto.Leaderboard, expected ...dto.LeaderboardEntry) {
+	t.Helper()
+	if len(leaderboard.Entries) != len(expected) {
+		t.Fatalf(`%s entries = "%+v", expected "%+v"`, leaderboard.Board, leaderboard.Entries, expected)
+	}
+	for i, entry := range leaderboard.Entries {
+		if entry.UserId != expected[i].UserId || entry.Rank != expected[i].Rank || entry.Score != expected[i].Score {
+			t.Errorf(`%s entries[%d] = "%+v", expected "%+v"`, leaderboard.Board, i, entry, expected[i])
+		}
+	}
+}
+
+func TestService_RecordGame_Leaderboards(t *testing.T) {
+	service, _ := newTestService()
+	now := service.Now()
+	lastWeek := now.AddDate(0, 0, -7)
+
+	recordTestGame(t, service, QuizId, lastWeek, dto.GameResult{UserId: 5, Score: 50, Rank: 1})
+	recordTestGame(
+		t, service, QuizId, now,
+		dto.GameResult{UserId: GuestId, Score: 30, Rank: 1},
+		dto.GameResult{UserId: HostId, Score: 20, Rank: 2},
+	)
+	recordTestGame(
+		t, service, QuizId+1, now,
+		dto.GameResult{UserId: HostId, Score: 10, Rank: 1},
+		dto.GameResult{UserId: 5, Score: 0, Rank: 2},
+	)
+
+	// The host and the guest tie on the all-time board, sharing a rank and listed by user id
+	allTime := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardAllTime})
+	assertEntries(
+		t, allTime,
+		dto.LeaderboardEntry{Rank: 1, UserId: 5, Score: 50},
+		dto.LeaderboardEntry{Rank: 2, UserId: HostId, Score: 30},
+		dto.LeaderboardEntry{Rank: 2, UserId: GuestId, Score: 30},
+	)
+	if allTime.Entries[1].Username != HostName || allTime.Entries[1].GamesPlayed != 2 {
+		t.Errorf(`allTime.Entries[1] = "%+v", expected "%s" with "2" games played`, allTime.Entries[1], HostName)
+	}
+	if allTime.Entries[0].Username != "" {
+		t.Errorf(`allTime.Entries[0].Username = "%s", expected "" for a user the user service does not know`,
+			allTime.Entries[0].Username)
+	}
+
+	// Last week's game is left out of the weekly board
+	weekly := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardWeekly})
+	assertEntries(
+		t, weekly,
+		dto.LeaderboardEntry{Rank: 1, UserId: HostId, Score: 30},
+		dto.LeaderboardEntry{Rank: 1, UserId: GuestId, Score: 30},
+		dto.LeaderboardEntry{Rank: 3, UserId: 5, Score: 0},
+	)
+	weekStart := time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)
+	if weekly.StartsAt == nil || !weekly.StartsAt.Equal(weekStart) || !weekly.EndsAt.Equal(weekStart.AddDate(0, 0, 7)) {
+		t.Errorf(`weekly = "%v" to "%v", expected the week starting "%v"`, weekly.StartsAt, weekly.EndsAt, weekStart)
+	}
+
+	quiz := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardQuiz, QuizId: QuizId})
+	assertEntries(
+		t, quiz,
+		dto.LeaderboardEntry{Rank: 1, UserId: 5, Score: 50},
+		dto.LeaderboardEntry{Rank: 2, UserId: GuestId, Score: 30},
+		dto.LeaderboardEntry{Rank: 3, UserId: HostId, Score: 20},
+	)
+}
+
+func TestService_GetLeaderboard_AroundMe(t *testing.T) {
+	service, _ := newTestService()
+	var results []dto.GameResult
+	for userId := 10; userId < 20; userId++ {
+		results = append(results, dto.GameResult{UserId: userId, Score: float64(100 - userId)})
+	}
+	results = append(results, dto.GameResult{UserId: HostId, Score: 85})
+	recordTestGame(t, service, QuizId, service.Now(), results...)
+
+	// The host ties with user 15 for 6th place, and is listed first by user id
+	limit := 3
+	leaderboard := getTestLeaderboard(
+		t, service, dto.GetLeaderboardRequest{Board: BoardAllTime, Limit: &limit, AroundMe: true},
+	)
+	assertEntries(
+		t, leaderboard,
+		dto.LeaderboardEntry{Rank: 5, UserId: 14, Score: 86},
+		dto.LeaderboardEntry{Rank: 6, UserId: HostId, Score: 85},
+		dto.LeaderboardEntry{Rank: 6, UserId: 15, Score: 85},
+	)
+
+	// Near the top, the page starts at the first place rather than before it
+	top := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardAllTime, AroundMe: true})
+	if len(top.Entries) != DefaultLeaderboardLimit || top.Entries[0].UserId != 10 {
+		t.Errorf(`top.Entries = "%+v", expected the first "%d" entries`, top.Entries, DefaultLeaderboardLimit)
+	}
+
+	_, err := service.GetLeaderboard(
+		context.Background(),
+		&dto.GetLeaderboardRequest{UserId: GuestId, Board: BoardAllTime, AroundMe: true},
+	)
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_GetGameResults(t *testing.T) {
+	service, _ := newTestService()
+	recordTestGame(
+		t, service, QuizId, service.Now(),
+		dto.GameResult{UserId: HostId, Score: 20, Rank: 1},
+		dto.GameResult{UserId: GuestId, Score: 10, Rank: 2},
+	)
+
+	game, err := service.GetGameResults(context.Background(), &dto.GetGameResultsRequest{GameId: 1})
+	if err != nil {
+		t.Fatalf(`service.GetGameResults(...) error = "%v", expected "<nil>"`, err)
+	}
+	if game.QuizId != QuizId || len(game.Results) != 2 || game.Results[1].Username != "guest" {
+		t.Errorf(`game = "%+v", expected the results of quiz "%d" with usernames`, game, QuizId)
+	}
+
+	_, err = service.GetGameResults(context.Background(), &dto.GetGameResultsRequest{GameId: 2})
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_ResetWeeklyLeaderboards(t *testing.T) {
+	service, clock := newTestService()
+	recordTestGame(t, service, QuizId, service.Now(), dto.GameResult{UserId: HostId, Score: 10, Rank: 1})
+
+	if deleted, err := service.ResetWeeklyLeaderboards(context.Background()); err != nil || deleted != 0 {
+		t.Errorf(`service.ResetWeeklyLeaderboards(ctx) = "%d", "%v", expected "0", "<nil>" mid-week`, deleted, err)
+	}
+
+	// The test clock starts on a Wednesday, so the week ends 4 and a half days later
+	clock.Advance(4*24*time.Hour + 12*time.Hour)
+	if deleted, err := service.ResetWeeklyLeaderboards(context.Background()); err != nil || deleted != 1 {
+		t.Errorf(`service.ResetWeeklyLeaderboards(ctx) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+
+	allTime := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardAllTime})
+	assertEntries(t, allTime, dto.LeaderboardEntry{Rank: 1, UserId: HostId, Score: 10})
+}
+
+func TestService_PlayGame_RecordsResults(t *testing.T) {
+	service, _ := newTestService()
+	code, _, _ := startTestGame(t, service)
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	submitTestAnswer(t, service, code, GuestId, 1, 12)
+	submitTestAnswer(t, service, code, HostId, 2, 21)
+	submitTestAnswer(t, service, code, GuestId, 2, 21)
+	service.recording.Wait()
+
+	game, err := service.GetGameResults(context.Background(), &dto.GetGameResultsRequest{GameId: 1})
+	if err != nil {
+		t.Fatalf(`service.GetGameResults(...) error = "%v", expected "<nil>"`, err)
+	}
+	expected := []dto.GameResult{
+		{UserId: HostId, Username: HostName, Score: 2, Rank: 1},
+		{UserId: GuestId, Username: "guest", Score: 1, Rank: 2},
+	}
+	if game.Code != code || len(game.Results) != 2 || game.Results[0] != expected[0] || game.Results[1] != expected[1] {
+		t.Errorf(`game = "%+v", expected lobby "%s" with results "%+v"`, game, code, expected)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"monday":       monday,
+		"sunday night": monday.Add(7*24*time.Hour - time.Nanosecond),
+		"other zone":   time.Date(2025, 1, 13, 0, 30, 0, 0, time.FixedZone("CET", 3600)),
+	}
+
+	for name, at := range tests {
+		if start := WeekStart(at); !start.Equal(monday) {
+			t.Errorf(`%s: WeekStart("%v") = "%v", expected "%v"`, name, at, start, monday)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"common"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"game/dto"
+	"game/scoring"
+	"log/slog"
+	"math/big"
+	quizdto "quiz/dto"
+	"strings"
+	"time"
+)
+
+const (
+	// JoinCodeAlphabet Characters join codes are made of: upper case letters and digits, leaving out 0, 1, I, L and O,
+	// which are easily confused when read aloud or copied from a screen
+	JoinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	JoinCodeLength   = 6
+	// maxJoinCodeAttempts How many codes are drawn before giving up on finding one not in use
+	maxJoinCodeAttempts = 10
+)
+
+const (
+	LobbyStateWaiting  = "waiting"
+	LobbyStatePlaying  = "playing"
+	LobbyStateFinished = "finished"
+)
+
+// lobby A game room, guarded by the mutex of the ServiceImpl holding it
+type lobby struct {
+	code       string
+	quizId     int64
+	quizTitle  string
+	hostId     int
+	state      string
+	maxPlayers int
+	players    []player
+	createdAt  time.Time
+	// activeAt When a player last joined, left, changed their ready state or played
+	activeAt time.Time
+	// ranked Whether the lobby was made by matchmaking, so its game updates the players' ratings. Ranked lobbies are
+	// only joined through matchmaking
+	ranked bool
+	// category Question bank category the match was made for, if any
+	category string
+	// rules Scoring rules of the game, and scoring the engine applying them
+	rules   dto.ScoringRules
+	scoring *scoring.Engine
+	// questions Questions of the quiz as it was when the lobby was created
+	questions []quizdto.PlayableQuestion
+	// answerKey The same questions with their answers by question id, so answers are graded against the quiz as it was
+	// when the lobby was created even if it is edited or deleted meanwhile
+	answerKey map[int64]quizdto.Question
+	// question Index of the open question while playing
+	question int
+	// openedAt When the open question was sent to the players
+	openedAt time.Time
+	// stopTimer Stops the timer closing the open question once time is up
+	stopTimer func() bool
+	// answers Answers to the open question by user id
+	answers map[int]*answer
+	// subscriptions Connections of players by user id
+	subscriptions map[int]*Subscription
+}
+
+type player struct {
+	userId   int
+	username string
+	ready    bool
+	joinedAt time.Time
+	score    float64
+	// answers The player's answers to the questions closed so far, in order, which their score is recomputed from
+	answers []scoring.Answer
+}
+
+// answer A graded answer, with the time the server received it
+type answer struct {
+	grade      *quizdto.GradeAnswerResponse
+	receivedAt time.Time
+}
+
+// stopQuestionTimer Stop the timer of the open question, if there is one
+func (lobby *lobby) stopQuestionTimer() {
+	if lobby.stopTimer != nil {
+		lobby.stopTimer()
+		lobby.stopTimer = nil
+	}
+}
+
+// findPlayer Get the index of the user in the lobby's players, or -1 if they have not joined
+func (lobby *lobby) findPlayer(userId int) int {
+	for i, player := range lobby.players {
+		if player.userId == userId {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewJoinCode Generate a random join code of JoinCodeLength characters from JoinCodeAlphabet
+func NewJoinCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(JoinCodeAlphabet)))
+
+	var code strings.Builder
+	for range JoinCodeLength {
+		index, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate join code: %w", err)
+		}
+		code.WriteByte(JoinCodeAlphabet[index.Int64()])
+	}
+	return code.String(), nil
+}
+
+// NormalizeJoinCode Put a join code as typed by a player into the form it was issued in, upper case without spaces or
+// dashes
+func NormalizeJoinCode(code string) string {
+	return strings.Map(
+		func(r rune) rune {
+			if r == ' ' || r == '-' {
+				return -1
+			}
+			return r
+		},
+		strings.ToUpper(code),
+	)
+}
+
+// LobbySweeper Background job removing lobbies that have been idle for longer than the idle timeout
+type LobbySweeper struct {
+	Service  *ServiceImpl
+	Logger   *slog.Logger
+	Interval time.Duration
+}
+
+// NewLobbySweeper Create a sweeper from the lobby configuration
+func NewLobbySweeper(service *ServiceImpl, config *common.LobbyConfig, logger *slog.Logger) *LobbySweeper {
+	return &LobbySweeper{
+		Service:  service,
+		Logger:   logger,
+		Interval: config.SweepInterval,
+	}
+}
+
+// Run Remove expired lobbies every interval until the context is cancelled
+func (sweeper *LobbySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweeper.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if expired := sweeper.Service.ExpireLobbies(); expired > 0 {
+			sweeper.Logger.InfoContext(ctx, "expired idle lobbies", slog.Int("count", expired))
+		}
+	}
+}
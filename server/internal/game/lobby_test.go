@@ -0,0 +1,46 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewJoinCode(t *testing.T) {
+	codes := map[string]bool{}
+	for range 100 {
+		code, err := NewJoinCode()
+		if err != nil {
+			t.Fatalf(`NewJoinCode() error = "%v", expected "<nil>"`, err)
+		}
+
+		if err := validateJoinCode(code); err != nil {
+			t.Errorf(`validateJoinCode("%s") = "%v", expected "<nil>"`, code, err)
+		}
+		codes[code] = true
+	}
+
+	if len(codes) < 99 {
+		t.Errorf(`len(codes) = "%d", expected about "100" distinct codes`, len(codes))
+	}
+}
+
+func TestJoinCodeAlphabet_Unambiguous(t *testing.T) {
+	if strings.ContainsAny(JoinCodeAlphabet, "01ILO") {
+		t.Errorf(`JoinCodeAlphabet = "%s", expected none of "01ILO"`, JoinCodeAlphabet)
+	}
+}
+
+func TestNormalizeJoinCode(t *testing.T) {
+	tests := map[string]string{
+		"ABC234":   "ABC234",
+		"abc234":   "ABC234",
+		"abc-234":  "ABC234",
+		" ab c234": "ABC234",
+	}
+
+	for code, expected := range tests {
+		if actual := NormalizeJoinCode(code); actual != expected {
+			t.Errorf(`NormalizeJoinCode("%s") = "%s", expected "%s"`, code, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package game
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"game/db/generated"
+	"game/dto"
+	"game/protocol"
+	"game/rating"
+	"game/scoring"
+	"log/slog"
+	"math"
+	"net/http"
+	quizdto "quiz/dto"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ticket A player waiting in the matchmaking queue, guarded by the mutex of the ServiceImpl holding it
+type ticket struct {
+	userId   int
+	username string
+	category string
+	// rating Rating the player is matched by, in the category if there is one
+	rating     rating.Rating
+	enqueuedAt time.Time
+}
+
+// notQueuedError Returned when a player cancels without being in the queue
+var notQueuedError = &common.HTTPError{
+	StatusCode: http.StatusNotFound,
+	Message:    "not in the matchmaking queue",
+}
+
+// GetRating Retrieve the user's rating, overall or in a category
+func (service *ServiceImpl) GetRating(
+	context context.Context,
+	request *dto.GetRatingRequest,
+) (*dto.GetRatingResponse, error) {
+	category := normalizeCategory(request.Category)
+	response := &dto.PlayerRating{UserId: request.UserId, Category: category}
+
+	playerRating := rating.Default()
+	if service.Queries != nil {
+		stored, err := service.Queries.GetRating(
+			context, db.GetRatingParams{UserID: int32(request.UserId), Category: category},
+		)
+		if err == nil {
+			playerRating = toRating(stored)
+			response.GamesPlayed = int(stored.GamesPlayed)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to retrieve rating: %w", err)
+		}
+	}
+
+	response.Rating = playerRating.Rating
+	response.Deviation = playerRating.Deviation
+	response.Volatility = playerRating.Volatility
+	return response, nil
+}
+
+// Enqueue Add the user to the matchmaking queue for a category, or for questions of any category without one. The
+// user must be connected to matchmaking to be told when they are matched
+func (service *ServiceImpl) Enqueue(
+	context context.Context,
+	request *dto.EnqueueRequest,
+) (*dto.EnqueueResponse, error) {
+	category := normalizeCategory(request.Category)
+	playerRating := rating.Default()
+	if service.Queries != nil {
+		var err error
+		playerRating, err = loadRating(context, service.Queries, request.UserId, category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve rating: %w", err)
+		}
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if _, ok := service.matchmakingSubscriptions[request.UserId]; !ok {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "connect to matchmaking before queueing",
+		}
+	}
+
+	if service.findTicket(request.UserId) >= 0 {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "already in the matchmaking queue",
+		}
+	}
+
+	queued := &ticket{
+		userId:     request.UserId,
+		username:   request.Username,
+		category:   category,
+		rating:     playerRating,
+		enqueuedAt: service.Now(),
+	}
+	service.queue = append(service.queue, queued)
+
+	return &dto.Ticket{
+		UserId:     queued.userId,
+		Category:   queued.category,
+		Rating:     queued.rating.Rating,
+		EnqueuedAt: queued.enqueuedAt,
+	}, nil
+}
+
+// CancelQueue Remove the user from the matchmaking queue
+func (service *ServiceImpl) CancelQueue(
+	context context.Context,
+	request *dto.CancelQueueRequest,
+) (*dto.CancelQueueResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	index := service.findTicket(request.UserId)
+	if index < 0 {
+		return nil, notQueuedError
+	}
+
+	service.queue = slices.Delete(service.queue, index, index+1)
+	return &dto.CancelQueueResponse{}, nil
+}
+
+// ConnectMatchmaking Subscribe the user to matchmaking messages, ending any connection they already had. A queued
+// player connecting again keeps their place
+func (service *ServiceImpl) ConnectMatchmaking(
+	context context.Context,
+	request *dto.ConnectMatchmakingRequest,
+) (*Subscription, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if previous, ok := service.matchmakingSubscriptions[request.UserId]; ok {
+		delete(service.matchmakingSubscriptions, previous.UserId)
+		close(previous.messages)
+	}
+
+	subscription := newSubscription("", request.UserId)
+	service.matchmakingSubscriptions[request.UserId] = subscription
+	return subscription, nil
+}
+
+// DisconnectMatchmaking End a matchmaking subscription, taking the player out of the queue since they could no longer
+// be told about a match. Subscriptions that already ended are ignored
+func (service *ServiceImpl) DisconnectMatchmaking(subscription *Subscription) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if service.matchmakingSubscriptions[subscription.UserId] != subscription {
+		return nil
+	}
+
+	service.unsubscribeMatchmaking(subscription)
+	return nil
+}
+
+// MatchPlayers Match waiting players into ranked lobbies, returning how many lobbies were created. Each player's rating
+// window starts at the initial window and widens with every second they wait, up to the max window. In the order they
+// queued, each player is grouped with the players of the same category closest in rating whose rating is within both
+// their windows. A group is matched once it is full, or once it has the minimum number of players and its first player
+// has waited the max wait. Players of a match whose lobby cannot be created are sent the error and leave the queue
+func (service *ServiceImpl) MatchPlayers(context context.Context) int {
+	service.mutex.Lock()
+	matches := service.formMatches()
+	service.mutex.Unlock()
+
+	created := 0
+	for _, match := range matches {
+		if err := service.startMatch(context, match); err != nil {
+			message := toErrorMessage(context, fmt.Errorf("failed to create match lobby: %w", err))
+			service.mutex.Lock()
+			for _, matched := range match {
+				service.deliverMatchmaking(matched.userId, message)
+			}
+			service.mutex.Unlock()
+			continue
+		}
+		created++
+	}
+	return created
+}
+
+// formMatches Take the groups of players that can be matched out of the queue. The mutex must be held
+func (service *ServiceImpl) formMatches() [][]*ticket {
+	config := &service.Matchmaking
+	now := service.Now()
+	matched := map[*ticket]bool{}
+	var matches [][]*ticket
+
+	for _, first := range service.queue {
+		if matched[first] {
+			continue
+		}
+
+		firstWindow := service.matchWindow(first, now)
+		var candidates []*ticket
+		for _, other := range service.queue {
+			if other == first || matched[other] || other.category != first.category {
+				continue
+			}
+			distance := math.Abs(other.rating.Rating - first.rating.Rating)
+			if distance <= min(firstWindow, service.matchWindow(other, now)) {
+				candidates = append(candidates, other)
+			}
+		}
+
+		// The queue is in the order players queued, which a stable sort keeps among equal distances
+		sort.SliceStable(
+			candidates, func(i, j int) bool {
+				return math.Abs(candidates[i].rating.Rating-first.rating.Rating) <
+					math.Abs(candidates[j].rating.Rating-first.rating.Rating)
+			},
+		)
+		match := append([]*ticket{first}, candidates[:min(len(candidates), config.Players-1)]...)
+
+		waitedLongEnough := now.Sub(first.enqueuedAt) >= config.MaxWait
+		if len(match) < config.Players && (len(match) < config.MinPlayers || !waitedLongEnough) {
+			continue
+		}
+		for _, player := range match {
+			matched[player] = true
+		}
+		matches = append(matches, match)
+	}
+
+	service.queue = slices.DeleteFunc(
+		service.queue, func(waiting *ticket) bool {
+			return matched[waiting]
+		},
+	)
+	return matches
+}
+
+// startMatch Create the ranked lobby of a match, for a quiz generated from the question bank for its category, and
+// send it to the players. Every player is ready, and the player the match was formed around hosts
+func (service *ServiceImpl) startMatch(context context.Context, match []*ticket) error {
+	first := match[0]
+	playerIds := make([]int, len(match))
+	players := make([]player, len(match))
+	for i, matched := range match {
+		playerIds[i] = matched.userId
+		players[i] = player{userId: matched.userId, username: matched.username, ready: true}
+	}
+
+	generated, err := service.Quizzes.GenerateQuiz(
+		context, &quizdto.GenerateQuizRequest{
+			OwnerId:   first.userId,
+			Count:     service.Matchmaking.QuestionCount,
+			Category:  first.category,
+			PlayerIds: playerIds,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate quiz: %w", err)
+	}
+
+	quiz, err := service.Quizzes.GetGameQuiz(context, &quizdto.GetGameQuizRequest{QuizId: generated.QuizId})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve quiz: %w", err)
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	created, err := service.addLobby(quiz, len(players), scoring.DefaultRules, players)
+	if err != nil {
+		return err
+	}
+	created.ranked = true
+	created.category = first.category
+
+	message, err := protocol.NewMessage(protocol.TypeMatchFound, service.toLobbyDTO(created))
+	if err != nil {
+		return err
+	}
+	for _, matched := range match {
+		service.deliverMatchmaking(matched.userId, message)
+	}
+	return nil
+}
+
+// matchWindow Get how far from a player's rating others can be to be matched with them at the time
+func (service *ServiceImpl) matchWindow(waiting *ticket, now time.Time) float64 {
+	config := &service.Matchmaking
+	window := float64(config.InitialWindow) + float64(config.WindowGrowth)*now.Sub(waiting.enqueuedAt).Seconds()
+	return min(window, float64(config.MaxWindow))
+}
+
+// findTicket Get the index of the user's ticket in the queue, or -1 if they are not queued. The mutex must be held
+func (service *ServiceImpl) findTicket(userId int) int {
+	return slices.IndexFunc(
+		service.queue, func(waiting *ticket) bool {
+			return waiting.userId == userId
+		},
+	)
+}
+
+// deliverMatchmaking Queue a message for the player's matchmaking connection if they have one, ending the
+// subscription if its buffer is full. The mutex must be held
+func (service *ServiceImpl) deliverMatchmaking(userId int, message *protocol.Message) {
+	subscription, ok := service.matchmakingSubscriptions[userId]
+	if ok && !subscription.offer(message) {
+		service.unsubscribeMatchmaking(subscription)
+	}
+}
+
+// unsubscribeMatchmaking End a matchmaking subscription and take the player out of the queue. The mutex must be held
+func (service *ServiceImpl) unsubscribeMatchmaking(subscription *Subscription) {
+	delete(service.matchmakingSubscriptions, subscription.UserId)
+	close(subscription.messages)
+
+	if index := service.findTicket(subscription.UserId); index >= 0 {
+		service.queue = slices.Delete(service.queue, index, index+1)
+	}
+}
+
+// updateRatings Update the ratings of the players of a ranked game from their results, overall and in the category
+// if there is one. Games left with a single player are not rated
+func updateRatings(
+	context context.Context,
+	queries db.Querier,
+	results []dto.GameResult,
+	category string,
+	updatedAt time.Time,
+) error {
+	if len(results) < 2 {
+		return nil
+	}
+
+	categories := []string{""}
+	if category != "" {
+		categories = append(categories, category)
+	}
+
+	for _, category := range categories {
+		players := make([]rating.Rating, len(results))
+		ranks := make([]int, len(results))
+		for i, result := range results {
+			var err error
+			if players[i], err = loadRating(context, queries, result.UserId, category); err != nil {
+				return err
+			}
+			ranks[i] = result.Rank
+		}
+
+		for i, updated := range rating.UpdateAll(players, ranks, rating.DefaultTau) {
+			err := queries.UpsertRating(
+				context, db.UpsertRatingParams{
+					UserID:     int32(results[i].UserId),
+					Category:   category,
+					Rating:     updated.Rating,
+					Deviation:  updated.Deviation,
+					Volatility: updated.Volatility,
+					UpdatedAt:  updatedAt,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadRating Get the user's rating in the category, or the default rating if they have not played a ranked game in it
+func loadRating(context context.Context, queries db.Querier, userId int, category string) (rating.Rating, error) {
+	stored, err := queries.GetRating(context, db.GetRatingParams{UserID: int32(userId), Category: category})
+	if errors.Is(err, sql.ErrNoRows) {
+		return rating.Default(), nil
+	} else if err != nil {
+		return rating.Rating{}, err
+	}
+	return toRating(stored), nil
+}
+
+// toRating Convert a stored rating to the rating system's
+func toRating(stored db.Rating) rating.Rating {
+	return rating.Rating{
+		Rating:     stored.Rating,
+		Deviation:  stored.Deviation,
+		Volatility: stored.Volatility,
+	}
+}
+
+// normalizeCategory Put a category into the form the question bank stores it in, so players typing it differently
+// are matched together
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// Matchmaker Background job matching the players waiting in the queue
+type Matchmaker struct {
+	Service  *ServiceImpl
+	Logger   *slog.Logger
+	Interval time.Duration
+}
+
+// NewMatchmaker Create a matchmaker from the matchmaking configuration
+func NewMatchmaker(service *ServiceImpl, config *common.MatchmakingConfig, logger *slog.Logger) *Matchmaker {
+	return &Matchmaker{
+		Service:  service,
+		Logger:   logger,
+		Interval: config.Interval,
+	}
+}
+
+// Run Match waiting players every interval until the context is cancelled
+func (matchmaker *Matchmaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(matchmaker.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if created := matchmaker.Service.MatchPlayers(ctx); created > 0 {
+			matchmaker.Logger.InfoContext(ctx, "matched players", slog.Int("lobbies", created))
+		}
+	}
+}
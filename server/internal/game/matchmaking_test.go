@@ -0,0 +1,283 @@
+package game
+
+import (
+	"context"
+	"game/db/generated"
+	"game/dto"
+	"game/protocol"
+	"game/rating"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// queueTestPlayer Connect the user to matchmaking and queue them for the category, failing on error
+func queueTestPlayer(t *testing.T, service *ServiceImpl, userId int, category string) *Subscription {
+	subscription, err := service.ConnectMatchmaking(
+		context.Background(), &dto.ConnectMatchmakingRequest{UserId: userId},
+	)
+	if err != nil {
+		t.Fatalf(`service.ConnectMatchmaking(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+	}
+
+	_, err = service.Enqueue(
+		context.Background(), &dto.EnqueueRequest{UserId: userId, Username: "player", Category: category},
+	)
+	if err != nil {
+		t.Fatalf(`service.Enqueue(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+	}
+	return subscription
+}
+
+// setTestRating Store the user's rating in the category, with the default deviation and volatility
+func setTestRating(t *testing.T, service *ServiceImpl, userId int, category string, value float64) {
+	err := service.Queries.UpsertRating(
+		context.Background(), db.UpsertRatingParams{
+			UserID:     int32(userId),
+			Category:   category,
+			Rating:     value,
+			Deviation:  rating.DefaultDeviation,
+			Volatility: rating.DefaultVolatility,
+			UpdatedAt:  service.Now(),
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.Queries.UpsertRating(...) error = "%v", expected "<nil>"`, err)
+	}
+}
+
+// getTestRating Get the user's rating in the category, failing on error
+func getTestRating(t *testing.T, service *ServiceImpl, userId int, category string) *dto.PlayerRating {
+	playerRating, err := service.GetRating(
+		context.Background(), &dto.GetRatingRequest{UserId: userId, Category: category},
+	)
+	if err != nil {
+		t.Fatalf(`service.GetRating(ctx, %d, "%s") error = "%v", expected "<nil>"`, userId, category, err)
+	}
+	return playerRating
+}
+
+// assertMatchPlayers Match the waiting players, checking how many lobbies were created
+func assertMatchPlayers(t *testing.T, service *ServiceImpl, expected int) {
+	t.Helper()
+	if created := service.MatchPlayers(context.Background()); created != expected {
+		t.Fatalf(`service.MatchPlayers(ctx) = "%d", expected "%d"`, created, expected)
+	}
+}
+
+func TestService_Enqueue(t *testing.T) {
+	service, clock := newTestService()
+	setTestRating(t, service, HostId, "history", 1700)
+
+	_, err := service.Enqueue(context.Background(), &dto.EnqueueRequest{UserId: HostId})
+	assertHTTPError(t, err, http.StatusConflict)
+
+	_, err = service.ConnectMatchmaking(context.Background(), &dto.ConnectMatchmakingRequest{UserId: HostId})
+	if err != nil {
+		t.Fatalf(`service.ConnectMatchmaking(...) error = "%v", expected "<nil>"`, err)
+	}
+	ticket, err := service.Enqueue(
+		context.Background(), &dto.EnqueueRequest{UserId: HostId, Username: HostName, Category: " History "},
+	)
+	if err != nil {
+		t.Fatalf(`service.Enqueue(...) error = "%v", expected "<nil>"`, err)
+	}
+	expected := dto.Ticket{UserId: HostId, Category: "history", Rating: 1700, EnqueuedAt: clock.Now()}
+	if *ticket != expected {
+		t.Errorf(`ticket = "%+v", expected "%+v"`, ticket, expected)
+	}
+
+	_, err = service.Enqueue(context.Background(), &dto.EnqueueRequest{UserId: HostId})
+	assertHTTPError(t, err, http.StatusConflict)
+
+	if _, err := service.CancelQueue(context.Background(), &dto.CancelQueueRequest{UserId: HostId}); err != nil {
+		t.Errorf(`service.CancelQueue(...) error = "%v", expected "<nil>"`, err)
+	}
+	_, err = service.CancelQueue(context.Background(), &dto.CancelQueueRequest{UserId: HostId})
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_MatchPlayers_FullMatch(t *testing.T) {
+	service, _ := newTestService()
+	ratings := map[int]float64{HostId: 1500, GuestId: 1520, 5: 2000, 6: 1580}
+	subscriptions := map[int]*Subscription{}
+	for _, userId := range []int{HostId, GuestId, 5, 6} {
+		setTestRating(t, service, userId, "", ratings[userId])
+		subscriptions[userId] = queueTestPlayer(t, service, userId, "")
+	}
+
+	assertMatchPlayers(t, service, 1)
+
+	var lobby protocol.MatchFound
+	for _, userId := range []int{HostId, GuestId, 6} {
+		expectMessage(t, subscriptions[userId], protocol.TypeMatchFound, &lobby)
+	}
+	if !lobby.Ranked || lobby.HostId != HostId || lobby.MaxPlayers != 3 || lobby.QuizId != QuizId {
+		t.Errorf(`lobby = "%+v", expected a ranked lobby of 3 players hosted by "%d"`, lobby, HostId)
+	}
+	for _, player := range lobby.Players {
+		if !player.Ready {
+			t.Errorf(`lobby.Players = "%+v", expected every player to be ready`, lobby.Players)
+			break
+		}
+	}
+
+	// The player far above the others is left waiting, and cannot join the ranked lobby from outside
+	drainMessages(subscriptions[5])
+	if _, err := service.CancelQueue(context.Background(), &dto.CancelQueueRequest{UserId: 5}); err != nil {
+		t.Errorf(`service.CancelQueue(ctx, 5) error = "%v", expected "<nil>"`, err)
+	}
+	_, err := service.JoinLobby(context.Background(), &dto.JoinLobbyRequest{UserId: 5, Code: lobby.Code})
+	assertHTTPError(t, err, http.StatusForbidden)
+}
+
+func TestService_MatchPlayers_WideningWindow(t *testing.T) {
+	service, clock := newTestService()
+	for userId, value := range map[int]float64{HostId: 1500, GuestId: 1560, 5: 1770} {
+		setTestRating(t, service, userId, "", value)
+		queueTestPlayer(t, service, userId, "")
+	}
+
+	// After 10 seconds the windows are 200 points wide, still too narrow for the third player
+	clock.Advance(10 * time.Second)
+	assertMatchPlayers(t, service, 0)
+
+	// The windows stop widening at 300 points, which is wide enough
+	clock.Advance(10 * time.Second)
+	assertMatchPlayers(t, service, 1)
+}
+
+func TestService_MatchPlayers_MaxWait(t *testing.T) {
+	service, clock := newTestService()
+	host := queueTestPlayer(t, service, HostId, "history")
+	queueTestPlayer(t, service, GuestId, "History")
+	queueTestPlayer(t, service, 5, "")
+
+	assertMatchPlayers(t, service, 0)
+
+	// Once the first player has waited long enough, the two players of the category are matched without a third
+	clock.Advance(30 * time.Second)
+	assertMatchPlayers(t, service, 1)
+
+	var lobby protocol.MatchFound
+	expectMessage(t, host, protocol.TypeMatchFound, &lobby)
+	if len(lobby.Players) != 2 || lobby.Category != "history" {
+		t.Errorf(`lobby = "%+v", expected a "history" lobby of 2 players`, lobby)
+	}
+	if _, err := service.CancelQueue(context.Background(), &dto.CancelQueueRequest{UserId: 5}); err != nil {
+		t.Errorf(`service.CancelQueue(ctx, 5) error = "%v", expected "<nil>" for the player of another category`, err)
+	}
+}
+
+func TestService_MatchPlayers_QuizUnavailable(t *testing.T) {
+	service, _ := newTestService()
+	delete(service.Quizzes.(stubQuizSource), QuizId)
+	var subscriptions []*Subscription
+	for _, userId := range []int{HostId, GuestId, 5} {
+		subscriptions = append(subscriptions, queueTestPlayer(t, service, userId, ""))
+	}
+
+	assertMatchPlayers(t, service, 0)
+
+	for _, subscription := range subscriptions {
+		var serverErr protocol.Error
+		expectMessage(t, subscription, protocol.TypeError, &serverErr)
+		if serverErr.StatusCode != http.StatusConflict {
+			t.Errorf(`serverErr = "%+v", expected status "%d"`, serverErr, http.StatusConflict)
+		}
+	}
+	_, err := service.CancelQueue(context.Background(), &dto.CancelQueueRequest{UserId: HostId})
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_DisconnectMatchmaking(t *testing.T) {
+	service, _ := newTestService()
+	first := queueTestPlayer(t, service, HostId, "")
+
+	// Connecting again ends the first connection but keeps the player's place
+	second, err := service.ConnectMatchmaking(context.Background(), &dto.ConnectMatchmakingRequest{UserId: HostId})
+	if err != nil {
+		t.Fatalf(`service.ConnectMatchmaking(...) error = "%v", expected "<nil>"`, err)
+	}
+	assertEnded(t, first)
+	if err := service.DisconnectMatchmaking(first); err != nil {
+		t.Errorf(`service.DisconnectMatchmaking(first) = "%v", expected "<nil>"`, err)
+	}
+	_, err = service.Enqueue(context.Background(), &dto.EnqueueRequest{UserId: HostId})
+	assertHTTPError(t, err, http.StatusConflict)
+
+	if err := service.DisconnectMatchmaking(second); err != nil {
+		t.Errorf(`service.DisconnectMatchmaking(second) = "%v", expected "<nil>"`, err)
+	}
+	assertEnded(t, second)
+	_, err = service.CancelQueue(context.Background(), &dto.CancelQueueRequest{UserId: HostId})
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_RecordGame_UpdatesRatings(t *testing.T) {
+	service, _ := newTestService()
+	results := []dto.GameResult{{UserId: HostId, Score: 2, Rank: 1}, {UserId: GuestId, Score: 1, Rank: 2}}
+
+	recordTestGame(t, service, QuizId, service.Now(), results...)
+	if played := getTestRating(t, service, HostId, "").GamesPlayed; played != 0 {
+		t.Errorf(`GamesPlayed = "%d", expected "0" after an unranked game`, played)
+	}
+
+	_, err := service.RecordGame(
+		context.Background(), &dto.RecordGameRequest{
+			Code:       "ABC234",
+			QuizId:     QuizId,
+			FinishedAt: service.Now(),
+			Results:    results,
+			Ranked:     true,
+			Category:   "history",
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.RecordGame(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	for _, category := range []string{"", "history"} {
+		host := getTestRating(t, service, HostId, category)
+		guest := getTestRating(t, service, GuestId, category)
+		if host.Rating <= rating.DefaultRating || guest.Rating >= rating.DefaultRating || host.GamesPlayed != 1 {
+			t.Errorf(`"%s" ratings = "%+v", "%+v", expected the winner to gain and the loser to lose`,
+				category, host, guest)
+		}
+		if host.Deviation >= rating.DefaultDeviation {
+			t.Errorf(`host.Deviation = "%v", expected less than "%v"`, host.Deviation, rating.DefaultDeviation)
+		}
+	}
+	if other := getTestRating(t, service, HostId, "science"); other.Rating != rating.DefaultRating {
+		t.Errorf(`other = "%+v", expected the default rating in another category`, other)
+	}
+}
+
+func TestService_PlayRankedGame(t *testing.T) {
+	service, clock := newTestService()
+	host := queueTestPlayer(t, service, HostId, "")
+	queueTestPlayer(t, service, GuestId, "")
+	clock.Advance(30 * time.Second)
+	assertMatchPlayers(t, service, 1)
+
+	var lobby protocol.MatchFound
+	expectMessage(t, host, protocol.TypeMatchFound, &lobby)
+	connectTestPlayer(t, service, lobby.Code, HostId)
+	connectTestPlayer(t, service, lobby.Code, GuestId)
+	_, err := service.StartGame(context.Background(), &dto.StartGameRequest{UserId: HostId, Code: lobby.Code})
+	if err != nil {
+		t.Fatalf(`service.StartGame(...) error = "%v", expected "<nil>"`, err)
+	}
+	submitTestAnswer(t, service, lobby.Code, HostId, 1, 11)
+	submitTestAnswer(t, service, lobby.Code, GuestId, 1, 12)
+	submitTestAnswer(t, service, lobby.Code, HostId, 2, 21)
+	submitTestAnswer(t, service, lobby.Code, GuestId, 2, 22)
+	service.recording.Wait()
+
+	if winner := getTestRating(t, service, HostId, ""); winner.Rating <= rating.DefaultRating {
+		t.Errorf(`winner = "%+v", expected more than "%d"`, winner, rating.DefaultRating)
+	}
+	if loser := getTestRating(t, service, GuestId, ""); loser.Rating >= rating.DefaultRating {
+		t.Errorf(`loser = "%+v", expected less than "%d"`, loser, rating.DefaultRating)
+	}
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"common"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// MigrationTable Table recording the applied game migrations, kept apart from the other services' in a shared database
+const MigrationTable = "game_goose_db_version"
+
+//go:embed db/migrations/*.sql db/sqlite/migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migrations Get the game service SQL migrations embedded in the binary for the specified database driver
+func Migrations(driver string) fs.FS {
+	directory := "db/migrations"
+	if driver == common.DriverSQLite {
+		directory = "db/sqlite/migrations"
+	}
+
+	migrations, err := fs.Sub(embeddedMigrations, directory)
+	if err != nil {
+		panic(err)
+	}
+	return migrations
+}
+
+// Migrate Run a migrate command (up, down, status, version) against the game database
+func Migrate(ctx context.Context, command string, output io.Writer) error {
+	if err := common.ValidateMigrateCommand(command); err != nil {
+		return err
+	}
+
+	databaseConfig, err := common.LoadDatabaseConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load database configuration: %w", err)
+	}
+
+	if databaseConfig.Driver == common.DriverMemory {
+		_, _ = fmt.Fprintln(output, "in-memory database does not use migrations")
+		return nil
+	}
+
+	database, err := common.OpenDatabase(ctx, databaseConfig)
+	if err != nil {
+		return fmt.Errorf("failed to establish database connection: %w", err)
+	}
+	defer database.Close()
+
+	return common.RunServiceMigrations(
+		ctx,
+		database,
+		databaseConfig.Driver,
+		Migrations(databaseConfig.Driver),
+		MigrationTable,
+		command,
+		output,
+	)
+}
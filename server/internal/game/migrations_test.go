@@ -0,0 +1,58 @@
+package game
+
+import (
+	"common"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+var migrationDirectories = map[string]string{
+	common.DriverPostgres: "db/migrations",
+	common.DriverSQLite:   "db/sqlite/migrations",
+}
+
+func TestMigrations_EmbedsAllFiles(t *testing.T) {
+	for driver, directory := range migrationDirectories {
+		embedded, err := fs.Glob(Migrations(driver), "*.sql")
+		if err != nil {
+			t.Fatalf(`fs.Glob(Migrations("%s"), "*.sql") error = "%v", expected "<nil>"`, driver, err)
+		}
+
+		onDisk, err := fs.Glob(os.DirFS(directory), "*.sql")
+		if err != nil {
+			t.Fatalf(`fs.Glob(os.DirFS("%s"), "*.sql") error = "%v", expected "<nil>"`, directory, err)
+		}
+
+		if strings.Join(embedded, ",") != strings.Join(onDisk, ",") {
+			t.Errorf(`embedded %s migrations = "%v", expected "%v"`, driver, embedded, onDisk)
+		}
+	}
+}
+
+func TestMigrations_HaveGooseAnnotations(t *testing.T) {
+	for driver := range migrationDirectories {
+		migrations := Migrations(driver)
+		names, _ := fs.Glob(migrations, "*.sql")
+		for _, name := range names {
+			contents, err := fs.ReadFile(migrations, name)
+			if err != nil {
+				t.Fatalf(`fs.ReadFile(migrations, "%s") error = "%v", expected "<nil>"`, name, err)
+			}
+
+			if !strings.Contains(string(contents), "-- +goose Up") || !strings.Contains(string(contents), "-- +goose Down") {
+				t.Errorf(`%s migration "%s" is missing "-- +goose Up" or "-- +goose Down"`, driver, name)
+			}
+		}
+	}
+}
+
+func TestMigrations_SQLiteMirrorsPostgres(t *testing.T) {
+	postgres, _ := fs.Glob(Migrations(common.DriverPostgres), "*.sql")
+	sqlite, _ := fs.Glob(Migrations(common.DriverSQLite), "*.sql")
+
+	if strings.Join(postgres, ",") != strings.Join(sqlite, ",") {
+		t.Errorf(`sqlite migrations = "%v", expected "%v"`, sqlite, postgres)
+	}
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"encoding/json"
+	"game/dto"
+	"game/protocol"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	quizdto "quiz/dto"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// undocumentedRoutes Routes that serve the documentation and are intentionally left out of the spec
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+func TestOpenAPIHandler_Success(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/openapi.json", OpenAPIHandler())
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf(`Content-Type = "%s", expected "application/json"`, contentType)
+	}
+
+	var document openAPIDocument
+	if err := json.NewDecoder(recorder.Body).Decode(&document); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&document) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestDocsHandler_Success(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/docs", DocsHandler())
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	if !strings.Contains(recorder.Body.String(), "/openapi.json") {
+		t.Error(`recorder.Body does not reference "/openapi.json"`)
+	}
+}
+
+func TestOpenAPISpec_MatchesRoutes(t *testing.T) {
+	document := loadOpenAPIDocument(t)
+
+	documented := map[string]bool{}
+	for path, operations := range document.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	registered := map[string]bool{}
+	router := NewRouter(&mockService{}, nil, slog.Default())
+	err := chi.Walk(
+		router,
+		func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			key := method + " " + route
+			if !undocumentedRoutes[key] {
+				registered[key] = true
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf(`chi.Walk(router, ...) = "%v", expected "<nil>"`, err)
+	}
+
+	for route := range registered {
+		if !documented[route] {
+			t.Errorf(`route "%s" is registered but missing from openapi.json`, route)
+		}
+	}
+
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf(`route "%s" is documented in openapi.json but not registered`, route)
+		}
+	}
+}
+
+func TestOpenAPISpec_MatchesDTOs(t *testing.T) {
+	document := loadOpenAPIDocument(t)
+
+	tests := []struct {
+		schema        string
+		value         any
+		ignoredFields []string
+	}{
+		{schema: "CreateLobbyRequest", value: dto.CreateLobbyRequest{}, ignoredFields: []string{"hostId", "hostName"}},
+		{schema: "SetReadyRequest", value: dto.SetReadyRequest{}, ignoredFields: []string{"userId", "code"}},
+		{schema: "Player", value: dto.Player{}},
+		{schema: "ScoringRules", value: dto.ScoringRules{}},
+		{schema: "Lobby", value: dto.Lobby{}},
+		{schema: "Message", value: protocol.Message{}},
+		{schema: "PlayableAnswerOption", value: quizdto.PlayableAnswerOption{}},
+		{schema: "PlayableQuestion", value: quizdto.PlayableQuestion{}},
+		{schema: "QuestionStart", value: protocol.QuestionStart{}},
+		{schema: "Answer", value: quizdto.Answer{}},
+		{schema: "SubmitAnswer", value: protocol.SubmitAnswer{}},
+		{schema: "AnswerAck", value: protocol.AnswerAck{}},
+		{schema: "PlayerResult", value: protocol.PlayerResult{}},
+		{schema: "QuestionResults", value: protocol.QuestionResults{}},
+		{schema: "ScoreboardEntry", value: protocol.ScoreboardEntry{}},
+		{schema: "Scoreboard", value: protocol.Scoreboard{}},
+		{schema: "GameOver", value: protocol.GameOver{}},
+		{schema: "ProtocolError", value: protocol.Error{}},
+		{schema: "GameResult", value: dto.GameResult{}},
+		{schema: "Game", value: dto.Game{}},
+		{schema: "LeaderboardEntry", value: dto.LeaderboardEntry{}},
+		{schema: "Leaderboard", value: dto.Leaderboard{}},
+		{schema: "PlayerRating", value: dto.PlayerRating{}},
+		{schema: "EnqueueRequest", value: dto.EnqueueRequest{}, ignoredFields: []string{"userId", "username"}},
+		{schema: "Ticket", value: dto.Ticket{}},
+	}
+
+	for _, test := range tests {
+		schema, ok := document.Components.Schemas[test.schema]
+		if !ok {
+			t.Errorf(`schema "%s" is missing from openapi.json`, test.schema)
+			continue
+		}
+
+		expected := jsonFieldNames(test.value, test.ignoredFields)
+		actual := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			actual = append(actual, property)
+		}
+		sort.Strings(actual)
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf(`schema "%s" properties = "%v", expected "%v"`, test.schema, actual, expected)
+		}
+	}
+}
+
+func loadOpenAPIDocument(t *testing.T) *openAPIDocument {
+	var document openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &document); err != nil {
+		t.Fatalf(`json.Unmarshal(openAPISpec, &document) = "%v", expected "<nil>"`, err)
+	}
+	return &document
+}
+
+func jsonFieldNames(value any, ignoredFields []string) []string {
+	ignored := map[string]bool{}
+	for _, field := range ignoredFields {
+		ignored[field] = true
+	}
+
+	valueType := reflect.TypeOf(value)
+	names := make([]string, 0, valueType.NumField())
+	for i := 0; i < valueType.NumField(); i++ {
+		name := strings.Split(valueType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || ignored[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
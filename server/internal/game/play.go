@@ -0,0 +1,384 @@
+package game
+
+import (
+	"common"
+	"context"
+	"fmt"
+	"game/dto"
+	"game/protocol"
+	"game/scoring"
+	"log/slog"
+	"net/http"
+	"quiz"
+	"slices"
+	"time"
+)
+
+// gameNotInProgressError Returned for game actions on a lobby whose game has not started or is over
+var gameNotInProgressError = &common.HTTPError{
+	StatusCode: http.StatusConflict,
+	Message:    "game is not in progress",
+}
+
+// Connect Subscribe a player to the messages of their lobby, ending any earlier connection of theirs. The player is
+// sent the lobby, and the open question if the game is in progress
+func (service *ServiceImpl) Connect(context context.Context, request *dto.ConnectRequest) (*Subscription, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getLobby(request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if found.findPlayer(request.UserId) < 0 {
+		return nil, notInLobbyError
+	}
+
+	subscription := found.subscribe(request.UserId)
+	if err := found.send(request.UserId, protocol.TypeLobbyUpdate, service.toLobbyDTO(found)); err != nil {
+		return nil, err
+	}
+	if found.state == LobbyStatePlaying {
+		if err := found.send(request.UserId, protocol.TypeQuestionStart, service.newQuestionStart(found)); err != nil {
+			return nil, err
+		}
+	}
+
+	return subscription, nil
+}
+
+// Disconnect End a subscription, closing the open question if the player was the last one it was waiting for.
+// Subscriptions that already ended are ignored
+func (service *ServiceImpl) Disconnect(subscription *Subscription) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, ok := service.lobbies[subscription.Code]
+	if !ok || found.subscriptions[subscription.UserId] != subscription {
+		return nil
+	}
+
+	found.unsubscribe(subscription)
+	return service.closeQuestionIfAnswered(found)
+}
+
+// StartGame Start the game of a lobby once every player other than the host is ready, opening the first question.
+// Only the host can start the game
+func (service *ServiceImpl) StartGame(
+	context context.Context,
+	request *dto.StartGameRequest,
+) (*dto.StartGameResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getHostedLobby(request.Code, request.UserId, "only the host can start the game")
+	if err != nil {
+		return nil, err
+	}
+
+	if found.state != LobbyStateWaiting {
+		return nil, gameStartedError
+	}
+
+	if len(found.players) < MinPlayers {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    fmt.Sprintf("at least %d players are needed to start", MinPlayers),
+		}
+	}
+
+	for _, player := range found.players {
+		if !player.ready && player.userId != found.hostId {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusConflict,
+				Message:    "not every player is ready",
+			}
+		}
+	}
+
+	found.state = LobbyStatePlaying
+	found.question = 0
+	found.activeAt = service.Now()
+
+	if err := service.broadcastLobby(found); err != nil {
+		return nil, err
+	}
+	if err := service.openQuestion(found); err != nil {
+		return nil, err
+	}
+
+	return &dto.StartGameResponse{}, nil
+}
+
+// SubmitAnswer Grade and record a player's answer to the open question, acknowledging it to the player. The answer is
+// graded against the quiz as it was when the lobby was created, timed by when the server received it, and rejected
+// once time is up. The question closes once every connected player has answered
+func (service *ServiceImpl) SubmitAnswer(
+	context context.Context,
+	request *dto.SubmitAnswerRequest,
+) (*dto.SubmitAnswerResponse, error) {
+	receivedAt := service.Now()
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getLobby(request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.checkAnswerable(found, request, receivedAt); err != nil {
+		return nil, err
+	}
+
+	grade, err := quiz.GradeQuestion(found.answerKey[request.QuestionId], &request.Answer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grade answer: %w", err)
+	}
+
+	found.answers[request.UserId] = &answer{grade: grade, receivedAt: receivedAt}
+	found.activeAt = service.Now()
+
+	ack := &protocol.AnswerAck{QuestionId: request.QuestionId}
+	if err := found.send(request.UserId, protocol.TypeAnswerAck, ack); err != nil {
+		return nil, err
+	}
+	if err := service.closeQuestionIfAnswered(found); err != nil {
+		return nil, err
+	}
+
+	return &dto.SubmitAnswerResponse{}, nil
+}
+
+// NextQuestion Close the open question without waiting for the remaining answers. Only the host can move on
+func (service *ServiceImpl) NextQuestion(
+	context context.Context,
+	request *dto.NextQuestionRequest,
+) (*dto.NextQuestionResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getHostedLobby(request.Code, request.UserId, "only the host can move to the next question")
+	if err != nil {
+		return nil, err
+	}
+
+	if found.state != LobbyStatePlaying {
+		return nil, gameNotInProgressError
+	}
+
+	found.activeAt = service.Now()
+	if err := service.closeQuestion(found); err != nil {
+		return nil, err
+	}
+
+	return &dto.NextQuestionResponse{}, nil
+}
+
+// getHostedLobby Get the lobby with the join code if the user hosts it, otherwise a 403 with the message. The mutex
+// must be held
+func (service *ServiceImpl) getHostedLobby(code string, userId int, message string) (*lobby, error) {
+	found, err := service.getLobby(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if found.findPlayer(userId) < 0 {
+		return nil, notInLobbyError
+	}
+
+	if found.hostId != userId {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusForbidden,
+			Message:    message,
+		}
+	}
+
+	return found, nil
+}
+
+// checkAnswerable Check that the player can answer the question with an answer received at the time. The mutex must
+// be held
+func (service *ServiceImpl) checkAnswerable(
+	lobby *lobby,
+	request *dto.SubmitAnswerRequest,
+	receivedAt time.Time,
+) error {
+	if lobby.findPlayer(request.UserId) < 0 {
+		return notInLobbyError
+	}
+
+	if lobby.state != LobbyStatePlaying {
+		return gameNotInProgressError
+	}
+
+	if lobby.questions[lobby.question].QuestionId != request.QuestionId {
+		return &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "question is not open",
+		}
+	}
+
+	if _, ok := lobby.answers[request.UserId]; ok {
+		return &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "question already answered",
+		}
+	}
+
+	if receivedAt.After(service.deadline(lobby).Add(service.AnswerGrace)) {
+		return &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "time is up for this question",
+		}
+	}
+
+	return nil
+}
+
+// closeQuestionIfAnswered Close the open question if every connected player has answered it. The mutex must be held
+func (service *ServiceImpl) closeQuestionIfAnswered(lobby *lobby) error {
+	if lobby.state != LobbyStatePlaying || len(lobby.subscriptions) == 0 {
+		return nil
+	}
+
+	for userId := range lobby.subscriptions {
+		if _, ok := lobby.answers[userId]; !ok {
+			return nil
+		}
+	}
+
+	return service.closeQuestion(lobby)
+}
+
+// openQuestion Open the question at the lobby's question index, sending it to every player and starting its timer. The
+// mutex must be held
+func (service *ServiceImpl) openQuestion(lobby *lobby) error {
+	lobby.openedAt = service.Now()
+	lobby.answers = map[int]*answer{}
+
+	question := lobby.question
+	lobby.stopTimer = service.AfterFunc(
+		service.QuestionTimeLimit+service.AnswerGrace, func() {
+			service.expireQuestion(lobby, question)
+		},
+	)
+
+	return lobby.broadcast(protocol.TypeQuestionStart, service.newQuestionStart(lobby))
+}
+
+// expireQuestion Close the question at the index once time is up, unless it has already closed or the lobby is gone
+func (service *ServiceImpl) expireQuestion(lobby *lobby, question int) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if service.lobbies[lobby.code] != lobby || lobby.state != LobbyStatePlaying || lobby.question != question {
+		return
+	}
+
+	if err := service.closeQuestion(lobby); err != nil {
+		slog.Error("failed to close question", slog.String("code", lobby.code), slog.Any("error", err))
+	}
+}
+
+// closeQuestion Score the answers to the open question by the game's scoring rules and send its results and the
+// scoreboard, then open the next question or end the game after the last one, recording its results. The mutex must
+// be held
+func (service *ServiceImpl) closeQuestion(lobby *lobby) error {
+	lobby.stopQuestionTimer()
+
+	results := &protocol.QuestionResults{
+		QuestionId: lobby.questions[lobby.question].QuestionId,
+		Results:    make([]protocol.PlayerResult, len(lobby.players)),
+	}
+	for i := range lobby.players {
+		player := &lobby.players[i]
+		result := protocol.PlayerResult{UserId: player.userId}
+		var scored scoring.Answer
+		if submitted, ok := lobby.answers[player.userId]; ok {
+			responseTime := min(submitted.receivedAt.Sub(lobby.openedAt), service.QuestionTimeLimit)
+			result.Answered = true
+			result.Correct = submitted.grade.Correct
+			result.ResponseTimeMs = responseTime.Milliseconds()
+			scored = scoring.Answer{Answered: true, Credit: submitted.grade.Score, ResponseTime: responseTime}
+		}
+
+		player.answers = append(player.answers, scored)
+		points := lobby.scoring.Score(player.answers)
+		result.Score = points[len(points)-1]
+		player.score += result.Score
+		results.Results[i] = result
+	}
+
+	if err := lobby.broadcast(protocol.TypeQuestionResults, results); err != nil {
+		return err
+	}
+	standings := lobby.standings()
+	if err := lobby.broadcast(protocol.TypeScoreboard, &protocol.Scoreboard{Entries: standings}); err != nil {
+		return err
+	}
+
+	lobby.question++
+	if lobby.question < len(lobby.questions) {
+		return service.openQuestion(lobby)
+	}
+
+	lobby.state = LobbyStateFinished
+	service.recordFinishedGame(lobby)
+	if err := service.broadcastLobby(lobby); err != nil {
+		return err
+	}
+	return lobby.broadcast(protocol.TypeGameOver, &protocol.GameOver{Standings: standings})
+}
+
+// broadcastLobby Send the lobby to every connected player. The mutex must be held
+func (service *ServiceImpl) broadcastLobby(lobby *lobby) error {
+	return lobby.broadcast(protocol.TypeLobbyUpdate, service.toLobbyDTO(lobby))
+}
+
+// standings Get the players ordered by score, players who joined earlier first among equal scores. The mutex must be
+// held
+func (lobby *lobby) standings() []protocol.ScoreboardEntry {
+	players := slices.Clone(lobby.players)
+	slices.SortStableFunc(
+		players, func(a player, b player) int {
+			if a.score > b.score {
+				return -1
+			} else if a.score < b.score {
+				return 1
+			}
+			return 0
+		},
+	)
+
+	entries := make([]protocol.ScoreboardEntry, len(players))
+	for i, player := range players {
+		rank := i + 1
+		if i > 0 && player.score == players[i-1].score {
+			rank = entries[i-1].Rank
+		}
+		entries[i] = protocol.ScoreboardEntry{
+			Rank:     rank,
+			UserId:   player.userId,
+			Username: player.username,
+			Score:    player.score,
+		}
+	}
+	return entries
+}
+
+// deadline Get the time the open question's time limit runs out. The mutex must be held
+func (service *ServiceImpl) deadline(lobby *lobby) time.Time {
+	return lobby.openedAt.Add(service.QuestionTimeLimit)
+}
+
+// newQuestionStart Get the question start message for the open question. The mutex must be held
+func (service *ServiceImpl) newQuestionStart(lobby *lobby) *protocol.QuestionStart {
+	return &protocol.QuestionStart{
+		Number:    lobby.question + 1,
+		Count:     len(lobby.questions),
+		Question:  lobby.questions[lobby.question],
+		StartedAt: lobby.openedAt,
+		Deadline:  service.deadline(lobby),
+	}
+}
@@ -0,0 +1,621 @@
+package game
+
+import (
+	"context"
+	"game/dto"
+	"game/protocol"
+	"net/http"
+	quizdto "quiz/dto"
+	"testing"
+	"time"
+)
+
+// connectTestPlayer Connect the player to the lobby
+func connectTestPlayer(t *testing.T, service *ServiceImpl, code string, userId int) *Subscription {
+	subscription, err := service.Connect(context.Background(), &dto.ConnectRequest{UserId: userId, Code: code})
+	if err != nil {
+		t.Fatalf(`service.Connect(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+	}
+	return subscription
+}
+
+// startTestGame Start a game between the host and a ready guest, both connected, with the messages sent before the
+// game started already received
+func startTestGame(t *testing.T, service *ServiceImpl) (string, *Subscription, *Subscription) {
+	code := createTestLobby(t, service).Code
+	joinTestLobby(t, service, code, GuestId)
+	_, err := service.SetReady(context.Background(), &dto.SetReadyRequest{UserId: GuestId, Code: code, Ready: true})
+	if err != nil {
+		t.Fatalf(`service.SetReady(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	host := connectTestPlayer(t, service, code, HostId)
+	guest := connectTestPlayer(t, service, code, GuestId)
+	drainMessages(host)
+	drainMessages(guest)
+
+	_, err = service.StartGame(context.Background(), &dto.StartGameRequest{UserId: HostId, Code: code})
+	if err != nil {
+		t.Fatalf(`service.StartGame(...) error = "%v", expected "<nil>"`, err)
+	}
+	return code, host, guest
+}
+
+// submitTestAnswer Answer the question with the single answer option
+func submitTestAnswer(t *testing.T, service *ServiceImpl, code string, userId int, questionId int64, optionId int64) {
+	_, err := service.SubmitAnswer(
+		context.Background(), &dto.SubmitAnswerRequest{
+			UserId:     userId,
+			Code:       code,
+			QuestionId: questionId,
+			Answer:     quizdto.Answer{AnswerOptionIds: []int64{optionId}},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.SubmitAnswer(ctx, %d, %d) error = "%v", expected "<nil>"`, userId, questionId, err)
+	}
+}
+
+// nextMessage Get the next message queued for the subscription, failing if there is none
+func nextMessage(t *testing.T, subscription *Subscription) *protocol.Message {
+	select {
+	case message, ok := <-subscription.Messages:
+		if !ok {
+			t.Fatalf(`subscription of "%d" ended, expected a message`, subscription.UserId)
+		}
+		return message
+	default:
+		t.Fatalf(`no message queued for "%d", expected one`, subscription.UserId)
+		return nil
+	}
+}
+
+// expectMessage Check that the next message queued for the subscription is of the type, decoding it into data
+func expectMessage(t *testing.T, subscription *Subscription, messageType string, data any) {
+	message := nextMessage(t, subscription)
+	if message.Type != messageType || message.Version != protocol.Version {
+		t.Fatalf(`message = "%+v", expected a version %d "%s" message`, message, protocol.Version, messageType)
+	}
+	if data != nil {
+		if err := message.Decode(data); err != nil {
+			t.Fatalf(`message.Decode(data) = "%v", expected "<nil>"`, err)
+		}
+	}
+}
+
+// drainMessages Discard the messages queued for the subscription
+func drainMessages(subscription *Subscription) {
+	for {
+		select {
+		case _, ok := <-subscription.Messages:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// assertEnded Check that the subscription has ended
+func assertEnded(t *testing.T, subscription *Subscription) {
+	drainMessages(subscription)
+	select {
+	case _, ok := <-subscription.Messages:
+		if ok {
+			t.Errorf(`subscription of "%d" received a message, expected it to have ended`, subscription.UserId)
+		}
+	default:
+		t.Errorf(`subscription of "%d" is open, expected it to have ended`, subscription.UserId)
+	}
+}
+
+func TestService_Connect_SendsLobby(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+
+	host := connectTestPlayer(t, service, code, HostId)
+
+	var lobby protocol.LobbyUpdate
+	expectMessage(t, host, protocol.TypeLobbyUpdate, &lobby)
+	if lobby.Code != code || len(lobby.Players) != 1 {
+		t.Errorf(`lobby = "%+v", expected lobby "%s" with only the host`, lobby, code)
+	}
+
+	joinTestLobby(t, service, code, GuestId)
+	expectMessage(t, host, protocol.TypeLobbyUpdate, &lobby)
+	if len(lobby.Players) != 2 {
+		t.Errorf(`lobby.Players = "%+v", expected the host and the guest`, lobby.Players)
+	}
+}
+
+func TestService_Connect_NotAPlayer(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+
+	_, err := service.Connect(context.Background(), &dto.ConnectRequest{UserId: GuestId, Code: code})
+	assertHTTPError(t, err, http.StatusForbidden)
+}
+
+func TestService_Connect_ReplacesConnection(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+
+	first := connectTestPlayer(t, service, code, HostId)
+	second := connectTestPlayer(t, service, code, HostId)
+
+	assertEnded(t, first)
+	expectMessage(t, second, protocol.TypeLobbyUpdate, nil)
+
+	if err := service.Disconnect(first); err != nil {
+		t.Fatalf(`service.Disconnect(first) = "%v", expected "<nil>"`, err)
+	}
+	joinTestLobby(t, service, code, GuestId)
+	expectMessage(t, second, protocol.TypeLobbyUpdate, nil)
+}
+
+func TestService_Connect_DuringGame(t *testing.T) {
+	service, _ := newTestService()
+	code, _, _ := startTestGame(t, service)
+
+	guest := connectTestPlayer(t, service, code, GuestId)
+
+	var lobby protocol.LobbyUpdate
+	expectMessage(t, guest, protocol.TypeLobbyUpdate, &lobby)
+	var question protocol.QuestionStart
+	expectMessage(t, guest, protocol.TypeQuestionStart, &question)
+	if lobby.State != LobbyStatePlaying || question.Number != 1 {
+		t.Errorf(`lobby.State, question.Number = "%s", "%d", expected "playing", "1"`, lobby.State, question.Number)
+	}
+}
+
+func TestService_StartGame_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		userId     int
+		withGuest  bool
+		guestReady bool
+		started    bool
+		statusCode int
+	}{
+		"not the host":    {userId: GuestId, withGuest: true, guestReady: true, statusCode: http.StatusForbidden},
+		"not a player":    {userId: 3, withGuest: true, guestReady: true, statusCode: http.StatusForbidden},
+		"too few players": {userId: HostId, statusCode: http.StatusConflict},
+		"guest not ready": {userId: HostId, withGuest: true, statusCode: http.StatusConflict},
+		"already started": {
+			userId:     HostId,
+			withGuest:  true,
+			guestReady: true,
+			started:    true,
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				service, _ := newTestService()
+				code := createTestLobby(t, service).Code
+				if test.withGuest {
+					joinTestLobby(t, service, code, GuestId)
+					_, _ = service.SetReady(
+						context.Background(),
+						&dto.SetReadyRequest{UserId: GuestId, Code: code, Ready: test.guestReady},
+					)
+				}
+				if test.started {
+					_, _ = service.StartGame(context.Background(), &dto.StartGameRequest{UserId: HostId, Code: code})
+				}
+
+				_, err := service.StartGame(
+					context.Background(),
+					&dto.StartGameRequest{UserId: test.userId, Code: code},
+				)
+				assertHTTPError(t, err, test.statusCode)
+			},
+		)
+	}
+}
+
+func TestService_PlayGame(t *testing.T) {
+	service, _ := newTestService()
+	code, host, guest := startTestGame(t, service)
+
+	for _, subscription := range []*Subscription{host, guest} {
+		var lobby protocol.LobbyUpdate
+		expectMessage(t, subscription, protocol.TypeLobbyUpdate, &lobby)
+		var question protocol.QuestionStart
+		expectMessage(t, subscription, protocol.TypeQuestionStart, &question)
+		if lobby.State != LobbyStatePlaying || question.Number != 1 || question.Count != 2 {
+			t.Errorf(`lobby, question = "%+v", "%+v", expected question 1 of 2 of a game in progress`, lobby, question)
+		}
+		if question.Question.QuestionId != 1 || len(question.Question.AnswerOptions) != 2 {
+			t.Errorf(`question.Question = "%+v", expected question "1" with its answer options`, question.Question)
+		}
+	}
+
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	var ack protocol.AnswerAck
+	expectMessage(t, host, protocol.TypeAnswerAck, &ack)
+	if ack.QuestionId != 1 {
+		t.Errorf(`ack.QuestionId = "%d", expected "1"`, ack.QuestionId)
+	}
+	if len(guest.Messages) != 0 {
+		t.Errorf(`len(guest.Messages) = "%d", expected "0" before every player answered`, len(guest.Messages))
+	}
+
+	submitTestAnswer(t, service, code, GuestId, 1, 12)
+	expectMessage(t, guest, protocol.TypeAnswerAck, nil)
+	for _, subscription := range []*Subscription{host, guest} {
+		var results protocol.QuestionResults
+		expectMessage(t, subscription, protocol.TypeQuestionResults, &results)
+		expected := []protocol.PlayerResult{
+			{UserId: HostId, Answered: true, Correct: true, Score: 1},
+			{UserId: GuestId, Answered: true},
+		}
+		if results.QuestionId != 1 || len(results.Results) != 2 || results.Results[0] != expected[0] ||
+			results.Results[1] != expected[1] {
+			t.Errorf(`results = "%+v", expected "%+v" for question "1"`, results, expected)
+		}
+
+		var scoreboard protocol.Scoreboard
+		expectMessage(t, subscription, protocol.TypeScoreboard, &scoreboard)
+		if len(scoreboard.Entries) != 2 || scoreboard.Entries[0].UserId != HostId || scoreboard.Entries[0].Rank != 1 ||
+			scoreboard.Entries[1].Rank != 2 {
+			t.Errorf(`scoreboard = "%+v", expected the host ranked first`, scoreboard)
+		}
+
+		var question protocol.QuestionStart
+		expectMessage(t, subscription, protocol.TypeQuestionStart, &question)
+		if question.Number != 2 || question.Question.QuestionId != 2 {
+			t.Errorf(`question = "%+v", expected question "2"`, question)
+		}
+	}
+
+	submitTestAnswer(t, service, code, GuestId, 2, 21)
+	_, err := service.NextQuestion(context.Background(), &dto.NextQuestionRequest{UserId: HostId, Code: code})
+	if err != nil {
+		t.Fatalf(`service.NextQuestion(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	drainMessages(guest)
+	var results protocol.QuestionResults
+	expectMessage(t, host, protocol.TypeQuestionResults, &results)
+	if results.Results[0].Answered || !results.Results[1].Correct {
+		t.Errorf(`results.Results = "%+v", expected only the guest to have answered, correctly`, results.Results)
+	}
+	expectMessage(t, host, protocol.TypeScoreboard, nil)
+
+	var lobby protocol.LobbyUpdate
+	expectMessage(t, host, protocol.TypeLobbyUpdate, &lobby)
+	var gameOver protocol.GameOver
+	expectMessage(t, host, protocol.TypeGameOver, &gameOver)
+	if lobby.State != LobbyStateFinished {
+		t.Errorf(`lobby.State = "%s", expected "%s"`, lobby.State, LobbyStateFinished)
+	}
+	if len(gameOver.Standings) != 2 || gameOver.Standings[0].Rank != 1 || gameOver.Standings[1].Rank != 1 ||
+		gameOver.Standings[0].UserId != HostId || gameOver.Standings[1].Score != 1 {
+		t.Errorf(`gameOver.Standings = "%+v", expected the host and guest tied on "1"`, gameOver.Standings)
+	}
+
+	_, err = service.NextQuestion(context.Background(), &dto.NextQuestionRequest{UserId: HostId, Code: code})
+	assertHTTPError(t, err, http.StatusConflict)
+}
+
+func TestService_SubmitAnswer_Invalid(t *testing.T) {
+	service, _ := newTestService()
+	code, _, _ := startTestGame(t, service)
+	submitTestAnswer(t, service, code, HostId, 1, 12)
+
+	tests := map[string]struct {
+		userId     int
+		questionId int64
+		statusCode int
+	}{
+		"already answered":  {userId: HostId, questionId: 1, statusCode: http.StatusConflict},
+		"question not open": {userId: GuestId, questionId: 2, statusCode: http.StatusConflict},
+		"not a player":      {userId: 3, questionId: 1, statusCode: http.StatusForbidden},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				_, err := service.SubmitAnswer(
+					context.Background(), &dto.SubmitAnswerRequest{
+						UserId:     test.userId,
+						Code:       code,
+						QuestionId: test.questionId,
+						Answer:     quizdto.Answer{AnswerOptionIds: []int64{11}},
+					},
+				)
+				assertHTTPError(t, err, test.statusCode)
+			},
+		)
+	}
+}
+
+func TestService_SubmitAnswer_NotStarted(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+
+	_, err := service.SubmitAnswer(
+		context.Background(), &dto.SubmitAnswerRequest{
+			UserId:     HostId,
+			Code:       code,
+			QuestionId: 1,
+			Answer:     quizdto.Answer{AnswerOptionIds: []int64{11}},
+		},
+	)
+	assertHTTPError(t, err, http.StatusConflict)
+}
+
+func TestService_SubmitAnswer_QuizDeleted(t *testing.T) {
+	service, _ := newTestService()
+	code, host, _ := startTestGame(t, service)
+	drainMessages(host)
+
+	// Answers are graded against the quiz as it was when the lobby was created
+	delete(service.Quizzes.(stubQuizSource), QuizId)
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	submitTestAnswer(t, service, code, GuestId, 1, 12)
+
+	expectMessage(t, host, protocol.TypeAnswerAck, nil)
+	var results protocol.QuestionResults
+	expectMessage(t, host, protocol.TypeQuestionResults, &results)
+	if len(results.Results) != 2 || !results.Results[0].Correct || results.Results[1].Correct {
+		t.Errorf(`results.Results = "%+v", expected only the host to be correct`, results.Results)
+	}
+}
+
+func TestService_NextQuestion_NotHost(t *testing.T) {
+	service, _ := newTestService()
+	code, _, _ := startTestGame(t, service)
+
+	_, err := service.NextQuestion(context.Background(), &dto.NextQuestionRequest{UserId: GuestId, Code: code})
+	assertHTTPError(t, err, http.StatusForbidden)
+}
+
+func TestService_Disconnect_ClosesQuestion(t *testing.T) {
+	service, _ := newTestService()
+	code, host, guest := startTestGame(t, service)
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	drainMessages(host)
+
+	if err := service.Disconnect(guest); err != nil {
+		t.Fatalf(`service.Disconnect(guest) = "%v", expected "<nil>"`, err)
+	}
+
+	assertEnded(t, guest)
+	expectMessage(t, host, protocol.TypeQuestionResults, nil)
+}
+
+func TestService_QuestionTimer_ClosesQuestion(t *testing.T) {
+	service, clock := newTestService()
+	startedAt := clock.Now()
+	code, host, guest := startTestGame(t, service)
+	drainMessages(guest)
+
+	expectMessage(t, host, protocol.TypeLobbyUpdate, nil)
+	var question protocol.QuestionStart
+	expectMessage(t, host, protocol.TypeQuestionStart, &question)
+	if !question.StartedAt.Equal(startedAt) || !question.Deadline.Equal(startedAt.Add(20*time.Second)) {
+		t.Errorf(`question = "%+v", expected it to start now with a 20 second deadline`, question)
+	}
+
+	clock.Advance(5 * time.Second)
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	expectMessage(t, host, protocol.TypeAnswerAck, nil)
+
+	// The question stays open for the grace period after the deadline
+	clock.Advance(15*time.Second + 499*time.Millisecond)
+	if len(host.Messages) != 0 {
+		t.Errorf(`len(host.Messages) = "%d", expected "0" before time is up`, len(host.Messages))
+	}
+
+	clock.Advance(time.Millisecond)
+	var results protocol.QuestionResults
+	expectMessage(t, host, protocol.TypeQuestionResults, &results)
+	expected := []protocol.PlayerResult{
+		{UserId: HostId, Answered: true, Correct: true, Score: 1, ResponseTimeMs: 5000},
+		{UserId: GuestId},
+	}
+	if len(results.Results) != 2 || results.Results[0] != expected[0] || results.Results[1] != expected[1] {
+		t.Errorf(`results.Results = "%+v", expected "%+v"`, results.Results, expected)
+	}
+
+	expectMessage(t, host, protocol.TypeScoreboard, nil)
+	expectMessage(t, host, protocol.TypeQuestionStart, &question)
+	if question.Number != 2 || !question.Deadline.Equal(clock.Now().Add(20*time.Second)) {
+		t.Errorf(`question = "%+v", expected question 2 to start with a fresh deadline`, question)
+	}
+
+	clock.Advance(20*time.Second + 500*time.Millisecond)
+	drainMessages(host)
+	found, _ := service.getLobby(code)
+	if found.state != LobbyStateFinished {
+		t.Errorf(`found.state = "%s", expected "%s" after the last question`, found.state, LobbyStateFinished)
+	}
+}
+
+func TestService_PlayGame_ScoringRules(t *testing.T) {
+	service, clock := newTestService()
+	rules := &dto.ScoringRules{Points: 100, TimeDecay: 0.5, StreakBonus: 0.5, WrongPenalty: 10}
+	created, err := service.CreateLobby(
+		context.Background(),
+		&dto.CreateLobbyRequest{HostId: HostId, HostName: HostName, QuizId: QuizId, Scoring: rules},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+	if created.Scoring != *rules {
+		t.Errorf(`created.Scoring = "%+v", expected "%+v"`, created.Scoring, *rules)
+	}
+
+	code := created.Code
+	joinTestLobby(t, service, code, GuestId)
+	_, err = service.SetReady(context.Background(), &dto.SetReadyRequest{UserId: GuestId, Code: code, Ready: true})
+	if err != nil {
+		t.Fatalf(`service.SetReady(...) error = "%v", expected "<nil>"`, err)
+	}
+	host := connectTestPlayer(t, service, code, HostId)
+	_, err = service.StartGame(context.Background(), &dto.StartGameRequest{UserId: HostId, Code: code})
+	if err != nil {
+		t.Fatalf(`service.StartGame(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	// A quarter of the time limit costs an eighth of the points, while the guest's wrong answer costs the penalty. Only
+	// the host is connected, so each question closes once they answer
+	clock.Advance(5 * time.Second)
+	submitTestAnswer(t, service, code, GuestId, 1, 12)
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	drainMessages(host)
+
+	// Half the time limit costs a quarter, and the second correct answer in a row earns half as much again. The guest
+	// leaves the question unanswered, which costs nothing
+	clock.Advance(10 * time.Second)
+	submitTestAnswer(t, service, code, HostId, 2, 21)
+
+	expectMessage(t, host, protocol.TypeAnswerAck, nil)
+	var results protocol.QuestionResults
+	expectMessage(t, host, protocol.TypeQuestionResults, &results)
+	if len(results.Results) != 2 || results.Results[0].Score != 112.5 || results.Results[1].Score != 0 {
+		t.Errorf(`results.Results = "%+v", expected scores "112.5" and "0"`, results.Results)
+	}
+
+	expectMessage(t, host, protocol.TypeScoreboard, nil)
+	expectMessage(t, host, protocol.TypeLobbyUpdate, nil)
+	var gameOver protocol.GameOver
+	expectMessage(t, host, protocol.TypeGameOver, &gameOver)
+	if len(gameOver.Standings) != 2 || gameOver.Standings[0].Score != 200 || gameOver.Standings[1].Score != -10 {
+		t.Errorf(`gameOver.Standings = "%+v", expected the host on "200" and the guest on "-10"`, gameOver.Standings)
+	}
+}
+
+func TestService_QuestionTimer_Stopped(t *testing.T) {
+	service, clock := newTestService()
+	code, _, _ := startTestGame(t, service)
+	if active := clock.activeTimers(); active != 1 {
+		t.Fatalf(`clock.activeTimers() = "%d", expected "1" while a question is open`, active)
+	}
+
+	// Closing a question early replaces its timer with the next question's
+	submitTestAnswer(t, service, code, HostId, 1, 11)
+	submitTestAnswer(t, service, code, GuestId, 1, 11)
+	if active := clock.activeTimers(); active != 1 {
+		t.Errorf(`clock.activeTimers() = "%d", expected "1" after the first question closed`, active)
+	}
+
+	for _, userId := range []int{GuestId, HostId} {
+		_, err := service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: userId, Code: code})
+		if err != nil {
+			t.Fatalf(`service.LeaveLobby(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+	if active := clock.activeTimers(); active != 0 {
+		t.Errorf(`clock.activeTimers() = "%d", expected "0" once the lobby closed`, active)
+	}
+}
+
+func TestService_SubmitAnswer_Deadline(t *testing.T) {
+	tests := map[string]struct {
+		elapsed  time.Duration
+		accepted bool
+	}{
+		"before the deadline": {elapsed: 19 * time.Second, accepted: true},
+		"within the grace":    {elapsed: 20*time.Second + 500*time.Millisecond, accepted: true},
+		"after the grace":     {elapsed: 20*time.Second + 501*time.Millisecond},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				service, clock := newTestService()
+				// The timer is left to never fire, as if it ran late, so that the deadline check decides alone
+				service.AfterFunc = func(duration time.Duration, f func()) func() bool {
+					return func() bool {
+						return false
+					}
+				}
+				code, _, _ := startTestGame(t, service)
+
+				clock.Advance(test.elapsed)
+				_, err := service.SubmitAnswer(
+					context.Background(), &dto.SubmitAnswerRequest{
+						UserId:     HostId,
+						Code:       code,
+						QuestionId: 1,
+						Answer:     quizdto.Answer{AnswerOptionIds: []int64{11}},
+					},
+				)
+				if test.accepted && err != nil {
+					t.Errorf(`service.SubmitAnswer(...) error = "%v", expected "<nil>"`, err)
+				} else if !test.accepted {
+					assertHTTPError(t, err, http.StatusConflict)
+				}
+			},
+		)
+	}
+}
+
+func TestService_LeaveLobby_EndsSubscription(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+	joinTestLobby(t, service, code, GuestId)
+	host := connectTestPlayer(t, service, code, HostId)
+	guest := connectTestPlayer(t, service, code, GuestId)
+	drainMessages(host)
+
+	_, err := service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: GuestId, Code: code})
+	if err != nil {
+		t.Fatalf(`service.LeaveLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	assertEnded(t, guest)
+	var lobby protocol.LobbyUpdate
+	expectMessage(t, host, protocol.TypeLobbyUpdate, &lobby)
+	if len(lobby.Players) != 1 {
+		t.Errorf(`lobby.Players = "%+v", expected only the host`, lobby.Players)
+	}
+
+	_, err = service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: HostId, Code: code})
+	if err != nil {
+		t.Fatalf(`service.LeaveLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+	assertEnded(t, host)
+}
+
+func TestLobby_Deliver_EndsSlowSubscription(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+	host := connectTestPlayer(t, service, code, HostId)
+
+	for range subscriptionBuffer {
+		joinTestLobby(t, service, code, GuestId)
+		_, _ = service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: GuestId, Code: code})
+	}
+
+	assertEnded(t, host)
+}
+
+func TestLobby_Standings(t *testing.T) {
+	found := &lobby{
+		players: []player{
+			{userId: 1, username: "first", score: 1},
+			{userId: 2, username: "second", score: 2.5},
+			{userId: 3, username: "third", score: 1},
+			{userId: 4, username: "fourth"},
+		},
+	}
+
+	expected := []protocol.ScoreboardEntry{
+		{Rank: 1, UserId: 2, Username: "second", Score: 2.5},
+		{Rank: 2, UserId: 1, Username: "first", Score: 1},
+		{Rank: 2, UserId: 3, Username: "third", Score: 1},
+		{Rank: 4, UserId: 4, Username: "fourth"},
+	}
+	standings := found.standings()
+	for i := range expected {
+		if standings[i] != expected[i] {
+			t.Errorf(`standings[%d] = "%+v", expected "%+v"`, i, standings[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package protocol contains the messages exchanged over the game service's WebSocket connection
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"game/dto"
+	quizdto "quiz/dto"
+	"time"
+)
+
+// Version Version of the protocol. It changes whenever a message changes in a way older clients cannot read, and
+// messages of any other version are rejected
+const Version = 1
+
+// Message types sent by the server
+const (
+	TypeLobbyUpdate     = "lobby_update"
+	TypeQuestionStart   = "question_start"
+	TypeAnswerAck       = "answer_ack"
+	TypeQuestionResults = "question_results"
+	TypeScoreboard      = "scoreboard"
+	TypeGameOver        = "game_over"
+	TypeMatchFound      = "match_found"
+	TypeError           = "error"
+)
+
+// Message types sent by players
+const (
+	TypeStartGame    = "start_game"
+	TypeSubmitAnswer = "submit_answer"
+	TypeNextQuestion = "next_question"
+)
+
+// Message Envelope of every message. Data holds the payload of the message type and is left out for types without
+// one
+type Message struct {
+	Version int             `json:"version"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// LobbyUpdate Sent to every player whenever a player joins, leaves or changes their ready state, or the game state
+// changes, and to a player when they connect
+type LobbyUpdate = dto.Lobby
+
+// QuestionStart Sent to every player when a question opens, and to a player connecting while it is open. The question
+// does not give away the answer. The times are the server's: answers received after the deadline, plus a small grace
+// for latency, are rejected
+type QuestionStart struct {
+	Number    int                      `json:"number"`
+	Count     int                      `json:"count"`
+	Question  quizdto.PlayableQuestion `json:"question"`
+	StartedAt time.Time                `json:"startedAt"`
+	Deadline  time.Time                `json:"deadline"`
+}
+
+// SubmitAnswer Sent by a player to answer the open question. Each question can be answered once
+type SubmitAnswer struct {
+	QuestionId int64          `json:"questionId"`
+	Answer     quizdto.Answer `json:"answer"`
+}
+
+// AnswerAck Sent to a player once their answer has been recorded. Whether it was correct is only revealed by the
+// question results
+type AnswerAck struct {
+	QuestionId int64 `json:"questionId"`
+}
+
+// PlayerResult How a player did on a question
+type PlayerResult struct {
+	UserId   int     `json:"userId"`
+	Answered bool    `json:"answered"`
+	Correct  bool    `json:"correct"`
+	Score    float64 `json:"score"`
+	// ResponseTimeMs Milliseconds from the question opening to the server receiving the answer, at most the time limit
+	ResponseTimeMs int64 `json:"responseTimeMs"`
+}
+
+// QuestionResults Sent to every player when a question closes, once every connected player has answered, time is up
+// or the host moves on
+type QuestionResults struct {
+	QuestionId int64          `json:"questionId"`
+	Results    []PlayerResult `json:"results"`
+}
+
+// ScoreboardEntry A player's total score. Players with the same score share a rank
+type ScoreboardEntry struct {
+	Rank     int     `json:"rank"`
+	UserId   int     `json:"userId"`
+	Username string  `json:"username"`
+	Score    float64 `json:"score"`
+}
+
+// Scoreboard Sent to every player after the results of each question, ordered by rank
+type Scoreboard struct {
+	Entries []ScoreboardEntry `json:"entries"`
+}
+
+// GameOver Sent to every player after the results of the last question, with the final standings ordered by rank
+type GameOver struct {
+	Standings []ScoreboardEntry `json:"standings"`
+}
+
+// MatchFound Sent over the matchmaking connection to each player of a match once its ranked lobby is created. Every
+// player is already in the lobby and ready, so they connect to it and the host starts the game
+type MatchFound = dto.Lobby
+
+// Error Sent to a player whose message could not be handled, or over the matchmaking connection to the players of a
+// match whose lobby could not be created. StatusCode is the HTTP status code the same error
+// would have on the REST API
+type Error struct {
+	StatusCode int    `json:"statusCode"`
+	Message    string `json:"message"`
+}
+
+// Error Error() implementation from error interface
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// NewMessage Create a message of the current version with the payload
+func NewMessage(messageType string, data any) (*Message, error) {
+	message := &Message{Version: Version, Type: messageType}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s message: %w", messageType, err)
+		}
+		message.Data = encoded
+	}
+	return message, nil
+}
+
+// Decode Decode the payload of the message into data
+func (message *Message) Decode(data any) error {
+	if err := json.Unmarshal(message.Data, data); err != nil {
+		return fmt.Errorf("failed to decode %s message: %w", message.Type, err)
+	}
+	return nil
+}
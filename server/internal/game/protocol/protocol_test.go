@@ -0,0 +1,41 @@
+package protocol
+
+import (
+	"testing"
+)
+
+func TestNewMessage(t *testing.T) {
+	message, err := NewMessage(TypeAnswerAck, &AnswerAck{QuestionId: 7})
+	if err != nil {
+		t.Fatalf(`NewMessage(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if message.Version != Version || message.Type != TypeAnswerAck || string(message.Data) != `{"questionId":7}` {
+		t.Errorf(`message = "%+v", expected a version %d answer ack for question "7"`, message, Version)
+	}
+
+	var ack AnswerAck
+	if err := message.Decode(&ack); err != nil || ack.QuestionId != 7 {
+		t.Errorf(`message.Decode(&ack) = "%v", ack = "%+v", expected "<nil>", question "7"`, err, ack)
+	}
+}
+
+func TestNewMessage_WithoutData(t *testing.T) {
+	message, err := NewMessage(TypeStartGame, nil)
+	if err != nil {
+		t.Fatalf(`NewMessage(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if message.Data != nil {
+		t.Errorf(`message.Data = "%s", expected none`, message.Data)
+	}
+}
+
+func TestMessage_Decode_Invalid(t *testing.T) {
+	message := &Message{Version: Version, Type: TypeSubmitAnswer, Data: []byte(`"answer"`)}
+
+	var data SubmitAnswer
+	if err := message.Decode(&data); err == nil {
+		t.Error(`message.Decode(&data) = "<nil>", expected an error`)
+	}
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"game/db/generated"
+	"game/db/memory"
+	"game/db/sqlite"
+	"time"
+)
+
+// NewQueries Create the leaderboard and rating queries for the configured database driver, with the Transactor running them
+// atomically and a function releasing their resources
+func NewQueries(ctx context.Context, config *common.DatabaseConfig) (db.Querier, Transactor, func() error, error) {
+	if config.Driver == common.DriverMemory {
+		queries := memory.New()
+		return queries, queries, func() error { return nil }, nil
+	}
+
+	database, err := common.OpenDatabase(ctx, config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	newQuerier := func(tx db.DBTX) db.Querier {
+		return NewTimeoutQuerier(db.New(tx), config.QueryTimeout)
+	}
+	if config.Driver == common.DriverSQLite {
+		newQuerier = func(tx db.DBTX) db.Querier {
+			return NewTimeoutQuerier(sqlite.New(tx), config.QueryTimeout)
+		}
+	}
+
+	transactor := &SQLTransactor{
+		Database:   database,
+		NewQuerier: newQuerier,
+		MaxRetries: common.DefaultTxMaxRetries,
+	}
+	return newQuerier(database), transactor, database.Close, nil
+}
+
+// TimeoutQuerier db.Querier decorator bounding every query by a timeout derived from the request context
+type TimeoutQuerier struct {
+	Queries db.Querier
+	Timeout time.Duration
+}
+
+// NewTimeoutQuerier Wrap the specified querier so each query is cancelled after the timeout
+func NewTimeoutQuerier(queries db.Querier, timeout time.Duration) *TimeoutQuerier {
+	return &TimeoutQuerier{
+		Queries: queries,
+		Timeout: timeout,
+	}
+}
+
+var _ db.Querier = (*TimeoutQuerier)(nil)
+
+// AddLeaderboardScore AddLeaderboardScore() implementation from db.Querier interface
+func (q *TimeoutQuerier) AddLeaderboardScore(ctx context.Context, arg db.AddLeaderboardScoreParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.AddLeaderboardScore(ctx, arg)
+}
+
+// CountLeaderboardScoresAhead CountLeaderboardScoresAhead() implementation from db.Querier interface
+func (q *TimeoutQuerier) CountLeaderboardScoresAhead(
+	ctx context.Context,
+	arg db.CountLeaderboardScoresAheadParams,
+) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CountLeaderboardScoresAhead(ctx, arg)
+}
+
+// CreateGame CreateGame() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateGame(ctx context.Context, arg db.CreateGameParams) (db.Game, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateGame(ctx, arg)
+}
+
+// CreateGameResult CreateGameResult() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateGameResult(ctx context.Context, arg db.CreateGameResultParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateGameResult(ctx, arg)
+}
+
+// DeleteExpiredLeaderboardScores DeleteExpiredLeaderboardScores() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteExpiredLeaderboardScores(ctx context.Context, expiresAt sql.NullTime) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteExpiredLeaderboardScores(ctx, expiresAt)
+}
+
+// GetGame GetGame() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetGame(ctx context.Context, id int64) (db.Game, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetGame(ctx, id)
+}
+
+// GetGameResults GetGameResults() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetGameResults(ctx context.Context, gameID int64) ([]db.GameResult, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetGameResults(ctx, gameID)
+}
+
+// GetLeaderboardPage GetLeaderboardPage() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetLeaderboardPage(
+	ctx context.Context,
+	arg db.GetLeaderboardPageParams,
+) ([]db.GetLeaderboardPageRow, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetLeaderboardPage(ctx, arg)
+}
+
+// GetLeaderboardScore GetLeaderboardScore() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetLeaderboardScore(
+	ctx context.Context,
+	arg db.GetLeaderboardScoreParams,
+) (db.LeaderboardScore, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetLeaderboardScore(ctx, arg)
+}
+
+// GetRating GetRating() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetRating(ctx context.Context, arg db.GetRatingParams) (db.Rating, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetRating(ctx, arg)
+}
+
+// UpsertRating UpsertRating() implementation from db.Querier interface
+func (q *TimeoutQuerier) UpsertRating(ctx context.Context, arg db.UpsertRatingParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.UpsertRating(ctx, arg)
+}
+
+// GetUserGameResults GetUserGameResults() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUserGameResults(ctx context.Context, userID int32) ([]db.GetUserGameResultsRow, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUserGameResults(ctx, userID)
+}
+
+// DeleteUserGameResults DeleteUserGameResults() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteUserGameResults(ctx context.Context, userID int32) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteUserGameResults(ctx, userID)
+}
+
+// GetUserLeaderboardScores GetUserLeaderboardScores() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUserLeaderboardScores(ctx context.Context, userID int32) ([]db.LeaderboardScore, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUserLeaderboardScores(ctx, userID)
+}
+
+// DeleteUserLeaderboardScores DeleteUserLeaderboardScores() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteUserLeaderboardScores(ctx context.Context, userID int32) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteUserLeaderboardScores(ctx, userID)
+}
+
+// GetUserRatings GetUserRatings() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUserRatings(ctx context.Context, userID int32) ([]db.Rating, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUserRatings(ctx, userID)
+}
+
+// DeleteUserRatings DeleteUserRatings() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteUserRatings(ctx context.Context, userID int32) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteUserRatings(ctx, userID)
+}
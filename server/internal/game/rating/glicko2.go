@@ -0,0 +1,148 @@
+// Package rating contains the Glicko-2 skill rating system. Each ranked game is one rating period, and a game of more
+// than two players is rated as a series of head-to-head results between every pair of players in it
+package rating
+
+import "math"
+
+const (
+	DefaultRating     = 1500
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+	// DefaultTau System constant constraining how quickly volatility changes, from the 0.3 to 1.2 Glickman suggests
+	DefaultTau = 0.5
+)
+
+const (
+	// scale Factor converting between the Glicko and Glicko-2 scales
+	scale = 173.7178
+	// convergence Tolerance of the volatility iteration
+	convergence = 0.000001
+)
+
+// Rating A player's skill estimate on the Glicko scale
+type Rating struct {
+	Rating float64
+	// Deviation Uncertainty of the rating: the player's strength is within 2 deviations of it with 95% confidence
+	Deviation float64
+	// Volatility Expected fluctuation of the player's strength
+	Volatility float64
+}
+
+// Default Get the rating of a player who has never played a ranked game
+func Default() Rating {
+	return Rating{
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// Outcome Result of a game against one opponent
+type Outcome struct {
+	Opponent Rating
+	// Score 1 for a win, 0.5 for a draw and 0 for a loss
+	Score float64
+}
+
+// Update Get the player's rating after a rating period with the outcomes. A player without any outcomes keeps their
+// rating, but grows more uncertain
+func Update(player Rating, outcomes []Outcome, tau float64) Rating {
+	mu := (player.Rating - DefaultRating) / scale
+	phi := player.Deviation / scale
+	sigma := player.Volatility
+
+	if len(outcomes) == 0 {
+		return Rating{
+			Rating:     player.Rating,
+			Deviation:  math.Sqrt(phi*phi+sigma*sigma) * scale,
+			Volatility: sigma,
+		}
+	}
+
+	var inverseVariance, improvement float64
+	for _, outcome := range outcomes {
+		opponentMu := (outcome.Opponent.Rating - DefaultRating) / scale
+		g := weight(outcome.Opponent.Deviation / scale)
+		expected := 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+		inverseVariance += g * g * expected * (1 - expected)
+		improvement += g * (outcome.Score - expected)
+	}
+	variance := 1 / inverseVariance
+	delta := variance * improvement
+
+	sigma = volatility(phi, sigma, variance, delta, tau)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	mu += phi * phi * improvement
+
+	return Rating{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  phi * scale,
+		Volatility: sigma,
+	}
+}
+
+// UpdateAll Get the ratings of the players of a game after it, from their ranks in it where a lower rank is better.
+// Each player is rated against every other, winning against those ranked below them and drawing with those sharing
+// their rank
+func UpdateAll(players []Rating, ranks []int, tau float64) []Rating {
+	updated := make([]Rating, len(players))
+	for i, player := range players {
+		outcomes := make([]Outcome, 0, len(players)-1)
+		for j, opponent := range players {
+			if i == j {
+				continue
+			}
+			outcome := Outcome{Opponent: opponent, Score: 0.5}
+			if ranks[i] < ranks[j] {
+				outcome.Score = 1
+			} else if ranks[i] > ranks[j] {
+				outcome.Score = 0
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		updated[i] = Update(player, outcomes, tau)
+	}
+	return updated
+}
+
+// weight Reduce the impact of a game against an opponent by the uncertainty of their rating
+func weight(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// volatility Compute the new volatility with the Illinois algorithm, as in step 5 of Glickman's description
+func volatility(phi float64, sigma float64, variance float64, delta float64, tau float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-phi*phi-variance-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	lower := a
+	var upper float64
+	if delta*delta > phi*phi+variance {
+		upper = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		upper = a - k*tau
+	}
+
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > convergence {
+		next := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fNext := f(next)
+		if fNext*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+		upper, fUpper = next, fNext
+	}
+
+	return math.Exp(lower / 2)
+}
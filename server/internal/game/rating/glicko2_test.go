@@ -0,0 +1,66 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+// assertRating Check a rating against the expected one, within the tolerance
+func assertRating(t *testing.T, name string, actual Rating, expected Rating, tolerance float64) {
+	t.Helper()
+	if math.Abs(actual.Rating-expected.Rating) > tolerance ||
+		math.Abs(actual.Deviation-expected.Deviation) > tolerance ||
+		math.Abs(actual.Volatility-expected.Volatility) > tolerance/1000 {
+		t.Errorf(`%s = "%+v", expected "%+v"`, name, actual, expected)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	// The worked example of Glickman's "Example of the Glicko-2 system"
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	outcomes := []Outcome{
+		{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: 0},
+	}
+
+	updated := Update(player, outcomes, 0.5)
+	assertRating(t, "Update(...)", updated, Rating{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999}, 0.01)
+}
+
+func TestUpdate_NoOutcomes(t *testing.T) {
+	player := Rating{Rating: 1700, Deviation: 50, Volatility: 0.06}
+
+	updated := Update(player, nil, DefaultTau)
+	if updated.Rating != player.Rating || updated.Volatility != player.Volatility {
+		t.Errorf(`Update(...) = "%+v", expected the rating and volatility of "%+v"`, updated, player)
+	}
+	if updated.Deviation <= player.Deviation {
+		t.Errorf(`updated.Deviation = "%v", expected more than "%v"`, updated.Deviation, player.Deviation)
+	}
+}
+
+func TestUpdateAll(t *testing.T) {
+	players := []Rating{Default(), Default(), Default(), Default()}
+
+	updated := UpdateAll(players, []int{1, 2, 2, 4}, DefaultTau)
+
+	// Equal players move symmetrically around the default: the winner gains what the last player loses, and the two
+	// players tied in the middle won as often as they lost
+	if updated[0].Rating <= DefaultRating || updated[3].Rating >= DefaultRating {
+		t.Errorf(`updated = "%+v", expected the first player to gain and the last to lose`, updated)
+	}
+	if math.Abs(updated[0].Rating-DefaultRating-(DefaultRating-updated[3].Rating)) > 0.000001 {
+		t.Errorf(`updated = "%+v", expected the first and last players to move by as much`, updated)
+	}
+	for _, i := range []int{1, 2} {
+		if math.Abs(updated[i].Rating-DefaultRating) > 0.000001 {
+			t.Errorf(`updated[%d].Rating = "%v", expected "%v"`, i, updated[i].Rating, DefaultRating)
+		}
+	}
+	for i, player := range updated {
+		if player.Deviation >= DefaultDeviation {
+			t.Errorf(`updated[%d].Deviation = "%v", expected less than "%v"`, i, player.Deviation, DefaultDeviation)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package scoring contains the rules turning graded answers into points. Scoring is deterministic: the same rules and
+// answers always score the same, so a game's scores can be recomputed from its recorded answers
+package scoring
+
+import (
+	"game/dto"
+	"time"
+)
+
+// DefaultRules Rules for games created without any: 1 point for a correct answer, with partial credit
+var DefaultRules = dto.ScoringRules{
+	Points:        1,
+	PartialCredit: true,
+}
+
+// Answer A player's graded answer to a question, or the lack of one
+type Answer struct {
+	Answered bool
+	// Credit Share of the answer that was correct, from 0 to 1, as graded by the quiz service
+	Credit float64
+	// ResponseTime Time from the question opening to the server receiving the answer
+	ResponseTime time.Duration
+}
+
+// Turn An answer being scored, with what rules know about the game around it
+type Turn struct {
+	Answer Answer
+	// Streak Number of correct answers in a row right before this one
+	Streak int
+	// TimeLimit Time players had to answer the question
+	TimeLimit time.Duration
+}
+
+// Rule One step of scoring an answer. Each rule adjusts the points the rules before it came to
+type Rule interface {
+	Apply(points float64, turn *Turn) float64
+}
+
+// Engine Scores a player's answers by its rules, applied in order
+type Engine struct {
+	Rules     []Rule
+	TimeLimit time.Duration
+}
+
+// NewEngine Create an engine for the scoring rules of a game whose questions have the time limit. The rules must have
+// been validated
+func NewEngine(rules *dto.ScoringRules, timeLimit time.Duration) *Engine {
+	engine := &Engine{TimeLimit: timeLimit}
+	if rules.PartialCredit {
+		engine.Rules = append(engine.Rules, PartialCredit{Points: rules.Points})
+	} else {
+		engine.Rules = append(engine.Rules, FlatPoints{Points: rules.Points})
+	}
+	if rules.WrongPenalty > 0 {
+		engine.Rules = append(engine.Rules, WrongAnswerPenalty{Points: rules.WrongPenalty})
+	}
+	if rules.TimeDecay > 0 {
+		engine.Rules = append(engine.Rules, TimeDecay{Share: rules.TimeDecay})
+	}
+	if rules.StreakBonus > 0 {
+		engine.Rules = append(
+			engine.Rules,
+			StreakMultiplier{Bonus: rules.StreakBonus, MaxMultiplier: rules.MaxStreakMultiplier},
+		)
+	}
+	return engine
+}
+
+// Score Get the points for each of a player's answers, given in the order the questions were asked
+func (engine *Engine) Score(answers []Answer) []float64 {
+	points := make([]float64, len(answers))
+	streak := 0
+	for i, answer := range answers {
+		turn := &Turn{Answer: answer, Streak: streak, TimeLimit: engine.TimeLimit}
+		for _, rule := range engine.Rules {
+			points[i] = rule.Apply(points[i], turn)
+		}
+
+		if answer.Answered && answer.Credit == 1 {
+			streak++
+		} else {
+			streak = 0
+		}
+	}
+	return points
+}
+
+// Total Get the sum of the points for a player's answers
+func (engine *Engine) Total(answers []Answer) float64 {
+	total := 0.0
+	for _, points := range engine.Score(answers) {
+		total += points
+	}
+	return total
+}
+
+// FlatPoints Awards the points for a fully correct answer and nothing otherwise
+type FlatPoints struct {
+	Points float64
+}
+
+// Apply Apply() implementation from Rule interface
+func (rule FlatPoints) Apply(points float64, turn *Turn) float64 {
+	if turn.Answer.Answered && turn.Answer.Credit == 1 {
+		return rule.Points
+	}
+	return 0
+}
+
+// PartialCredit Awards the share of the points the answer earned
+type PartialCredit struct {
+	Points float64
+}
+
+// Apply Apply() implementation from Rule interface
+func (rule PartialCredit) Apply(points float64, turn *Turn) float64 {
+	if !turn.Answer.Answered {
+		return 0
+	}
+	return rule.Points * turn.Answer.Credit
+}
+
+// WrongAnswerPenalty Takes the points away for an answer that earned nothing so far. It goes before rules that scale
+// points, so answers those rules reduce to nothing are not mistaken for wrong ones
+type WrongAnswerPenalty struct {
+	Points float64
+}
+
+// Apply Apply() implementation from Rule interface
+func (rule WrongAnswerPenalty) Apply(points float64, turn *Turn) float64 {
+	if turn.Answer.Answered && points == 0 {
+		return -rule.Points
+	}
+	return points
+}
+
+// TimeDecay Reduces the points earned by the share of the time limit taken to answer, times Share. Penalties are not
+// reduced
+type TimeDecay struct {
+	Share float64
+}
+
+// Apply Apply() implementation from Rule interface
+func (rule TimeDecay) Apply(points float64, turn *Turn) float64 {
+	if points <= 0 || turn.TimeLimit <= 0 {
+		return points
+	}
+	elapsed := min(float64(turn.Answer.ResponseTime)/float64(turn.TimeLimit), 1)
+	return points * (1 - rule.Share*elapsed)
+}
+
+// StreakMultiplier Multiplies the points earned by 1 plus Bonus for each correct answer in a row before this one, up
+// to MaxMultiplier unless that is 0. Penalties are not multiplied
+type StreakMultiplier struct {
+	Bonus         float64
+	MaxMultiplier float64
+}
+
+// Apply Apply() implementation from Rule interface
+func (rule StreakMultiplier) Apply(points float64, turn *Turn) float64 {
+	if points <= 0 {
+		return points
+	}
+	multiplier := 1 + rule.Bonus*float64(turn.Streak)
+	if rule.MaxMultiplier > 0 {
+		multiplier = min(multiplier, rule.MaxMultiplier)
+	}
+	return points * multiplier
+}
@@ -0,0 +1,115 @@
+package scoring
+
+import (
+	"game/dto"
+	"slices"
+	"testing"
+	"time"
+)
+
+const TimeLimit = 20 * time.Second
+
+// correct Get a fully correct answer received after the response time
+func correct(responseTime time.Duration) Answer {
+	return Answer{Answered: true, Credit: 1, ResponseTime: responseTime}
+}
+
+func TestEngine_Score(t *testing.T) {
+	wrong := Answer{Answered: true}
+	half := Answer{Answered: true, Credit: 0.5}
+	unanswered := Answer{}
+
+	tests := map[string]struct {
+		rules    dto.ScoringRules
+		answers  []Answer
+		expected []float64
+	}{
+		"default": {
+			rules:    DefaultRules,
+			answers:  []Answer{correct(0), half, wrong, unanswered},
+			expected: []float64{1, 0.5, 0, 0},
+		},
+		"flat points": {
+			rules:    dto.ScoringRules{Points: 100},
+			answers:  []Answer{correct(time.Second), half, wrong},
+			expected: []float64{100, 0, 0},
+		},
+		"time decay": {
+			rules:    dto.ScoringRules{Points: 100, TimeDecay: 0.5},
+			answers:  []Answer{correct(0), correct(10 * time.Second), correct(30 * time.Second)},
+			expected: []float64{100, 75, 50},
+		},
+		"streak": {
+			rules: dto.ScoringRules{Points: 100, StreakBonus: 0.5, MaxStreakMultiplier: 2},
+			answers: []Answer{
+				correct(0), correct(0), correct(0), correct(0), wrong, correct(0),
+			},
+			expected: []float64{100, 150, 200, 200, 0, 100},
+		},
+		"partial answers break streaks": {
+			rules:    dto.ScoringRules{Points: 100, PartialCredit: true, StreakBonus: 1},
+			answers:  []Answer{correct(0), half, correct(0), unanswered, correct(0)},
+			expected: []float64{100, 100, 100, 0, 100},
+		},
+		"wrong answer penalty": {
+			rules:    dto.ScoringRules{Points: 100, PartialCredit: true, WrongPenalty: 25, StreakBonus: 1},
+			answers:  []Answer{correct(0), wrong, unanswered, half},
+			expected: []float64{100, -25, 0, 50},
+		},
+		"decayed answers are not penalized": {
+			rules:    dto.ScoringRules{Points: 100, TimeDecay: 1, WrongPenalty: 25},
+			answers:  []Answer{correct(TimeLimit)},
+			expected: []float64{0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				engine := NewEngine(&test.rules, TimeLimit)
+				if points := engine.Score(test.answers); !slices.Equal(points, test.expected) {
+					t.Errorf(`engine.Score(answers) = "%v", expected "%v"`, points, test.expected)
+				}
+			},
+		)
+	}
+}
+
+func TestEngine_Total(t *testing.T) {
+	engine := NewEngine(&dto.ScoringRules{Points: 10, StreakBonus: 0.5, WrongPenalty: 5}, TimeLimit)
+	answers := []Answer{correct(0), correct(0), {Answered: true}}
+
+	if total := engine.Total(answers); total != 20 {
+		t.Errorf(`engine.Total(answers) = "%v", expected "20"`, total)
+	}
+}
+
+func TestEngine_Score_Custom(t *testing.T) {
+	// Rules can be combined freely, here adding a point to every answer following a correct one
+	engine := &Engine{
+		Rules: []Rule{
+			FlatPoints{Points: 2},
+			ruleFunc(
+				func(points float64, turn *Turn) float64 {
+					if turn.Streak > 0 {
+						return points + 1
+					}
+					return points
+				},
+			),
+		},
+		TimeLimit: TimeLimit,
+	}
+
+	points := engine.Score([]Answer{correct(0), correct(0)})
+	if !slices.Equal(points, []float64{2, 3}) {
+		t.Errorf(`engine.Score(answers) = "%v", expected "[2 3]"`, points)
+	}
+}
+
+// ruleFunc Rule implemented by a function
+type ruleFunc func(points float64, turn *Turn) float64
+
+func (f ruleFunc) Apply(points float64, turn *Turn) float64 {
+	return f(points, turn)
+}
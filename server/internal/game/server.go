@@ -0,0 +1,172 @@
+package game
+
+import (
+	"common"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	_ "github.com/lib/pq" // registers "postgres" driver
+	"log/slog"
+	"net/http"
+	"os"
+	"quiz"
+	"time"
+)
+
+// RunServer Start the game service and listen for requests
+func RunServer() {
+	logger := common.NewLogger()
+	slog.SetDefault(logger)
+
+	if err := common.InitJWT(); err != nil {
+		logger.Error("Error initializing JWT", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	databaseConfig, err := common.LoadDatabaseConfig()
+	if err != nil {
+		logger.Error("Error loading database configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// Quizzes are read from the quiz service's database, whose migrations the quiz service runs
+	quizService, closeDatabase, err := quiz.NewService(context.Background(), databaseConfig)
+	if err != nil {
+		logger.Error("Error establishing database connection", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer closeDatabase()
+
+	lobbyConfig, err := common.LoadLobbyConfig()
+	if err != nil {
+		logger.Error("Error loading lobby configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+	service := NewService(quizService, lobbyConfig)
+
+	queries, transactor, closeQueries, err := NewQueries(context.Background(), databaseConfig)
+	if err != nil {
+		logger.Error("Error establishing database connection", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer closeQueries()
+	service.Queries = queries
+	service.Transactor = transactor
+
+	// Leaderboards and authentication share the user service client
+	var users common.UserGetter
+	leaderboardConfig := common.LoadLeaderboardConfig()
+	if leaderboardConfig.UserServiceAddr != "" {
+		client, connection, err := common.DialUserService(leaderboardConfig.UserServiceAddr)
+		if err != nil {
+			logger.Error("Error creating user service client", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer connection.Close()
+		service.Users = client
+		users = client
+	} else {
+		logger.Warn(
+			"USER_SERVICE_ADDR not set, leaderboards are served without usernames and tokens are trusted without " +
+				"checking their user",
+		)
+	}
+
+	matchmakingConfig, err := common.LoadMatchmakingConfig()
+	if err != nil {
+		logger.Error("Error loading matchmaking configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+	service.Matchmaking = *matchmakingConfig
+
+	go NewLobbySweeper(service, lobbyConfig, logger).Run(context.Background())
+	go NewLeaderboardResetter(service, logger).Run(context.Background())
+	go NewMatchmaker(service, matchmakingConfig, logger).Run(context.Background())
+
+	router := NewRouter(service, users, logger)
+
+	userDataConfig := common.LoadUserDataConfig()
+	if userDataConfig.Secret != "" {
+		router.Mount("/internal", NewInternalRouter(service, []byte(userDataConfig.Secret)))
+	} else {
+		logger.Warn("USER_DATA_SECRET not set, user data is neither exported nor deleted when the user is deleted")
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		logger.Error("PORT environment variable not set")
+		os.Exit(1)
+	}
+
+	if metricsPort := os.Getenv("METRICS_PORT"); metricsPort != "" {
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, common.NewMetricsHandler()); err != nil {
+				logger.Error("Metrics server error", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+
+		logger.Info("Serving metrics on port " + metricsPort)
+	}
+
+	logger.Info("Listening on port " + port)
+
+	err = http.ListenAndServe(":"+port, router)
+	if err != nil {
+		logger.Error("Server error", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+// NewRouter Create the router for the game service with all routes registered. Requests must carry a token of one of
+// the users, checked against the user service when users is not nil
+func NewRouter(service Service, users common.UserGetter, logger *slog.Logger) *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(middleware.RealIP)
+	router.Use(common.RequestLogger(logger))
+	router.Use(middleware.Recoverer)
+
+	router.Get("/openapi.json", OpenAPIHandler())
+	router.Get("/docs", DocsHandler())
+
+	// WebSocket connections last the whole game, so they are left out of the request timeout. Browsers cannot set
+	// headers on WebSocket requests, so their token can also be passed in the jwt query parameter
+	router.Group(
+		func(router chi.Router) {
+			router.Use(jwtauth.Verify(common.TokenAuth, jwtauth.TokenFromHeader, jwtauth.TokenFromQuery))
+			router.Use(jwtauth.Authenticator(common.TokenAuth))
+			router.Use(common.AuthMiddleware(users))
+
+			router.Get("/lobby/{code}/ws", GameSocketHandler(service))
+			router.Get("/matchmaking/ws", MatchmakingSocketHandler(service))
+		},
+	)
+
+	router.Group(
+		func(router chi.Router) {
+			router.Use(middleware.Timeout(time.Minute))
+			router.Use(jwtauth.Verifier(common.TokenAuth))
+			router.Use(jwtauth.Authenticator(common.TokenAuth))
+			router.Use(common.AuthMiddleware(users))
+
+			router.Post("/lobby", CreateLobbyHandler(service))
+			router.Get("/lobby/{code}", GetLobbyHandler(service))
+			router.Post("/lobby/{code}/join", JoinLobbyHandler(service))
+			router.Post("/lobby/{code}/leave", LeaveLobbyHandler(service))
+			router.Put("/lobby/{code}/ready", SetReadyHandler(service))
+
+			router.Get("/game/{gameId}/results", GetGameResultsHandler(service))
+			router.Get("/leaderboard/all-time", GetLeaderboardHandler(service, BoardAllTime))
+			router.Get("/leaderboard/weekly", GetLeaderboardHandler(service, BoardWeekly))
+			router.Get("/leaderboard/quiz/{quizId}", GetLeaderboardHandler(service, BoardQuiz))
+
+			router.Get("/rating", GetRatingHandler(service))
+			router.Post("/matchmaking", EnqueueHandler(service))
+			router.Delete("/matchmaking", CancelQueueHandler(service))
+		},
+	)
+
+	return router
+}
@@ -0,0 +1,441 @@
+// Package game contains the implementation for a game lobby, leaderboard and matchmaking RESTful service
+package game
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"game/db/generated"
+	"game/dto"
+	"game/scoring"
+	"net/http"
+	quizdto "quiz/dto"
+	"sync"
+	"time"
+)
+
+// Service Interface for performing game lobby operations
+type Service interface {
+	CreateLobby(context context.Context, request *dto.CreateLobbyRequest) (*dto.CreateLobbyResponse, error)
+	GetLobby(context context.Context, request *dto.GetLobbyRequest) (*dto.GetLobbyResponse, error)
+	JoinLobby(context context.Context, request *dto.JoinLobbyRequest) (*dto.JoinLobbyResponse, error)
+	LeaveLobby(context context.Context, request *dto.LeaveLobbyRequest) (*dto.LeaveLobbyResponse, error)
+	SetReady(context context.Context, request *dto.SetReadyRequest) (*dto.SetReadyResponse, error)
+	Connect(context context.Context, request *dto.ConnectRequest) (*Subscription, error)
+	Disconnect(subscription *Subscription) error
+	StartGame(context context.Context, request *dto.StartGameRequest) (*dto.StartGameResponse, error)
+	SubmitAnswer(context context.Context, request *dto.SubmitAnswerRequest) (*dto.SubmitAnswerResponse, error)
+	NextQuestion(context context.Context, request *dto.NextQuestionRequest) (*dto.NextQuestionResponse, error)
+	GetGameResults(context context.Context, request *dto.GetGameResultsRequest) (*dto.GetGameResultsResponse, error)
+	GetLeaderboard(context context.Context, request *dto.GetLeaderboardRequest) (*dto.GetLeaderboardResponse, error)
+	GetRating(context context.Context, request *dto.GetRatingRequest) (*dto.GetRatingResponse, error)
+	Enqueue(context context.Context, request *dto.EnqueueRequest) (*dto.EnqueueResponse, error)
+	CancelQueue(context context.Context, request *dto.CancelQueueRequest) (*dto.CancelQueueResponse, error)
+	ConnectMatchmaking(context context.Context, request *dto.ConnectMatchmakingRequest) (*Subscription, error)
+	DisconnectMatchmaking(subscription *Subscription) error
+	GetUserData(context context.Context, request *dto.GetUserDataRequest) (*dto.GetUserDataResponse, error)
+	DeleteUserData(context context.Context, request *dto.DeleteUserDataRequest) (*dto.DeleteUserDataResponse, error)
+}
+
+// QuizSource Provides the quizzes lobbies are created for, with the questions to grade answers against, and generates
+// the quizzes of matches from the question bank. quiz.Service satisfies it, so the game service reads quizzes from the
+// quiz database directly
+type QuizSource interface {
+	GetGameQuiz(context context.Context, request *quizdto.GetGameQuizRequest) (*quizdto.GetGameQuizResponse, error)
+	GenerateQuiz(context context.Context, request *quizdto.GenerateQuizRequest) (*quizdto.GenerateQuizResponse, error)
+}
+
+// ServiceImpl Implementation for the Service. Lobbies are held in memory, so every player of a lobby must reach the
+// same instance
+type ServiceImpl struct {
+	Quizzes QuizSource
+	// MaxPlayers Most players a lobby can hold
+	MaxPlayers int
+	// IdleTimeout How long a lobby without activity is kept
+	IdleTimeout time.Duration
+	// QuestionTimeLimit How long players have to answer each question
+	QuestionTimeLimit time.Duration
+	// AnswerGrace How long after the time limit answers are still accepted
+	AnswerGrace time.Duration
+	// Now Clock used for join times, lobby expiry and answer deadlines
+	Now func() time.Time
+	// AfterFunc Call f in its own goroutine once the duration has passed on the same clock as Now, returning a function
+	// that stops the timer
+	AfterFunc func(duration time.Duration, f func()) (stop func() bool)
+	// Queries Store of finished games and leaderboards. When nil, finished games are not recorded
+	Queries db.Querier
+	// Transactor Runs multi-step operations atomically. When nil, queries run directly against Queries
+	Transactor Transactor
+	// Users Resolves leaderboard entries to usernames. When nil, entries are served without them
+	Users UserDirectory
+	// Matchmaking How waiting players are matched into ranked lobbies
+	Matchmaking common.MatchmakingConfig
+
+	mutex   sync.Mutex
+	lobbies map[string]*lobby
+	// queue Players waiting to be matched, in the order they queued
+	queue []*ticket
+	// matchmakingSubscriptions Connections of players to matchmaking by user id
+	matchmakingSubscriptions map[int]*Subscription
+	// recording Finished games being recorded in the background
+	recording sync.WaitGroup
+}
+
+// lobbyNotFoundError Returned for join codes that were never issued or whose lobby expired or closed
+var lobbyNotFoundError = &common.HTTPError{
+	StatusCode: http.StatusNotFound,
+	Message:    "lobby not found",
+}
+
+// notInLobbyError Returned when a user acts on a lobby they have not joined
+var notInLobbyError = &common.HTTPError{
+	StatusCode: http.StatusForbidden,
+	Message:    "not a player in this lobby",
+}
+
+// gameStartedError Returned when a lobby is joined or its game started after the game has started
+var gameStartedError = &common.HTTPError{
+	StatusCode: http.StatusConflict,
+	Message:    "game has already started",
+}
+
+// NewService Create a service for the lobby configuration, creating lobbies for quizzes from the specified source
+func NewService(quizzes QuizSource, config *common.LobbyConfig) *ServiceImpl {
+	return &ServiceImpl{
+		Quizzes:           quizzes,
+		MaxPlayers:        config.MaxPlayers,
+		IdleTimeout:       config.IdleTimeout,
+		QuestionTimeLimit: config.QuestionTimeLimit,
+		AnswerGrace:       config.AnswerGrace,
+		Now:               time.Now,
+		AfterFunc: func(duration time.Duration, f func()) func() bool {
+			return time.AfterFunc(duration, f).Stop
+		},
+		lobbies:                  map[string]*lobby{},
+		matchmakingSubscriptions: map[int]*Subscription{},
+	}
+}
+
+// CreateLobby Create a lobby for a quiz of the host's with a new join code. The host is its first player
+func (service *ServiceImpl) CreateLobby(
+	context context.Context,
+	request *dto.CreateLobbyRequest,
+) (*dto.CreateLobbyResponse, error) {
+	maxPlayers := request.MaxPlayers
+	if maxPlayers == 0 {
+		maxPlayers = service.MaxPlayers
+	} else if maxPlayers > service.MaxPlayers {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("maxPlayers must be at most %d", service.MaxPlayers),
+		}
+	}
+
+	// Only the quiz's owner can host it, as quizzes are private to their owners
+	quiz, err := service.Quizzes.GetGameQuiz(
+		context, &quizdto.GetGameQuizRequest{QuizId: request.QuizId, OwnerId: request.HostId},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve quiz: %w", err)
+	}
+	if len(quiz.Quiz.Questions) == 0 {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "quiz has no questions",
+		}
+	}
+
+	rules := scoring.DefaultRules
+	if request.Scoring != nil {
+		rules = *request.Scoring
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	created, err := service.addLobby(
+		quiz, maxPlayers, rules, []player{{userId: request.HostId, username: request.HostName}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.toLobbyDTO(created), nil
+}
+
+// GetLobby Retrieve a lobby by its join code
+func (service *ServiceImpl) GetLobby(
+	context context.Context,
+	request *dto.GetLobbyRequest,
+) (*dto.GetLobbyResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getLobby(request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.toLobbyDTO(found), nil
+}
+
+// JoinLobby Add the user to a lobby that has not started and has room. Joining a lobby again changes nothing
+func (service *ServiceImpl) JoinLobby(
+	context context.Context,
+	request *dto.JoinLobbyRequest,
+) (*dto.JoinLobbyResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getLobby(request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if found.findPlayer(request.UserId) >= 0 {
+		return service.toLobbyDTO(found), nil
+	}
+
+	if found.ranked {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusForbidden,
+			Message:    "ranked lobbies are only joined through matchmaking",
+		}
+	}
+
+	if found.state != LobbyStateWaiting {
+		return nil, gameStartedError
+	}
+
+	if len(found.players) >= found.maxPlayers {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "lobby is full",
+		}
+	}
+
+	now := service.Now()
+	found.players = append(found.players, player{userId: request.UserId, username: request.Username, joinedAt: now})
+	found.activeAt = now
+
+	if err := service.broadcastLobby(found); err != nil {
+		return nil, err
+	}
+
+	return service.toLobbyDTO(found), nil
+}
+
+// LeaveLobby Remove the user from a lobby, ending their connection. When the host leaves, the player who joined
+// earliest becomes host, and the lobby closes once no players are left
+func (service *ServiceImpl) LeaveLobby(
+	context context.Context,
+	request *dto.LeaveLobbyRequest,
+) (*dto.LeaveLobbyResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getLobby(request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	index := found.findPlayer(request.UserId)
+	if index < 0 {
+		return nil, notInLobbyError
+	}
+
+	found.players = append(found.players[:index], found.players[index+1:]...)
+	if subscription, ok := found.subscriptions[request.UserId]; ok {
+		found.unsubscribe(subscription)
+	}
+	if len(found.players) == 0 {
+		service.closeLobby(found)
+		return &dto.LeaveLobbyResponse{}, nil
+	}
+
+	if found.hostId == request.UserId {
+		found.hostId = found.players[0].userId
+	}
+	found.activeAt = service.Now()
+
+	if err := service.broadcastLobby(found); err != nil {
+		return nil, err
+	}
+	if err := service.closeQuestionIfAnswered(found); err != nil {
+		return nil, err
+	}
+
+	return &dto.LeaveLobbyResponse{}, nil
+}
+
+// SetReady Mark the user ready or not ready in a lobby they have joined
+func (service *ServiceImpl) SetReady(
+	context context.Context,
+	request *dto.SetReadyRequest,
+) (*dto.SetReadyResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	found, err := service.getLobby(request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	index := found.findPlayer(request.UserId)
+	if index < 0 {
+		return nil, notInLobbyError
+	}
+
+	found.players[index].ready = request.Ready
+	found.activeAt = service.Now()
+
+	if err := service.broadcastLobby(found); err != nil {
+		return nil, err
+	}
+
+	return service.toLobbyDTO(found), nil
+}
+
+// ExpireLobbies Remove every lobby that has been idle for longer than the idle timeout, returning how many were removed
+func (service *ServiceImpl) ExpireLobbies() int {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	now := service.Now()
+	expired := 0
+	for _, lobby := range service.lobbies {
+		if !now.Before(service.expiresAt(lobby)) {
+			service.closeLobby(lobby)
+			expired++
+		}
+	}
+	return expired
+}
+
+// runInTx Run fn inside a transaction if a Transactor is configured, otherwise directly against Queries
+func (service *ServiceImpl) runInTx(
+	context context.Context,
+	options *sql.TxOptions,
+	fn func(queries db.Querier) error,
+) error {
+	if service.Transactor == nil {
+		return fn(service.Queries)
+	}
+	return service.Transactor.RunInTx(context, options, fn)
+}
+
+// addLobby Add a waiting lobby for the quiz with a new join code, holding the players with the first as host. The
+// mutex must be held
+func (service *ServiceImpl) addLobby(
+	quiz *quizdto.GameQuiz,
+	maxPlayers int,
+	rules dto.ScoringRules,
+	players []player,
+) (*lobby, error) {
+	code, err := service.newUnusedJoinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := service.Now()
+	for i := range players {
+		players[i].joinedAt = now
+	}
+
+	created := &lobby{
+		code:       code,
+		quizId:     quiz.Quiz.QuizId,
+		quizTitle:  quiz.Quiz.Title,
+		hostId:     players[0].userId,
+		state:      LobbyStateWaiting,
+		maxPlayers: maxPlayers,
+		players:    players,
+		createdAt:  now,
+		activeAt:   now,
+
+		rules:         rules,
+		scoring:       scoring.NewEngine(&rules, service.QuestionTimeLimit),
+		questions:     quiz.Quiz.Questions,
+		answerKey:     map[int64]quizdto.Question{},
+		subscriptions: map[int]*Subscription{},
+	}
+	for _, question := range quiz.Questions {
+		created.answerKey[question.QuestionId] = question
+	}
+	service.lobbies[code] = created
+	return created, nil
+}
+
+// getLobby Get the lobby with the join code, or lobbyNotFoundError if there is none or it expired. The mutex must be
+// held
+func (service *ServiceImpl) getLobby(code string) (*lobby, error) {
+	code = NormalizeJoinCode(code)
+	found, ok := service.lobbies[code]
+	if !ok {
+		return nil, lobbyNotFoundError
+	}
+
+	if !service.Now().Before(service.expiresAt(found)) {
+		service.closeLobby(found)
+		return nil, lobbyNotFoundError
+	}
+
+	return found, nil
+}
+
+// closeLobby Remove the lobby, ending every connection to it. The mutex must be held
+func (service *ServiceImpl) closeLobby(lobby *lobby) {
+	lobby.stopQuestionTimer()
+	lobby.unsubscribeAll()
+	delete(service.lobbies, lobby.code)
+}
+
+// newUnusedJoinCode Draw join codes until one is not used by another lobby. The mutex must be held
+func (service *ServiceImpl) newUnusedJoinCode() (string, error) {
+	for range maxJoinCodeAttempts {
+		code, err := NewJoinCode()
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := service.getLobby(code); errors.Is(err, lobbyNotFoundError) {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to find an unused join code")
+}
+
+// expiresAt Get the time the lobby expires unless there is more activity
+func (service *ServiceImpl) expiresAt(lobby *lobby) time.Time {
+	return lobby.activeAt.Add(service.IdleTimeout)
+}
+
+// toLobbyDTO Convert a lobby to the response DTO. The mutex must be held
+func (service *ServiceImpl) toLobbyDTO(lobby *lobby) *dto.Lobby {
+	result := &dto.Lobby{
+		Code:       lobby.code,
+		QuizId:     lobby.quizId,
+		QuizTitle:  lobby.quizTitle,
+		HostId:     lobby.hostId,
+		State:      lobby.state,
+		MaxPlayers: lobby.maxPlayers,
+		Players:    make([]dto.Player, len(lobby.players)),
+		Scoring:    lobby.rules,
+		Ranked:     lobby.ranked,
+		Category:   lobby.category,
+		CreatedAt:  lobby.createdAt,
+		ExpiresAt:  service.expiresAt(lobby),
+	}
+	for i, player := range lobby.players {
+		result.Players[i] = dto.Player{
+			UserId:   player.userId,
+			Username: player.username,
+			IsHost:   player.userId == lobby.hostId,
+			Ready:    player.ready,
+			JoinedAt: player.joinedAt,
+		}
+	}
+	return result
+}
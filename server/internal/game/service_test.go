@@ -0,0 +1,223 @@
+package game
+
+import (
+	"context"
+	"game/dto"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func createTestLobby(t *testing.T, service *ServiceImpl) *dto.Lobby {
+	lobby, err := service.CreateLobby(
+		context.Background(),
+		&dto.CreateLobbyRequest{HostId: HostId, HostName: HostName, QuizId: QuizId},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+	return lobby
+}
+
+func joinTestLobby(t *testing.T, service *ServiceImpl, code string, userIds ...int) *dto.Lobby {
+	var lobby *dto.Lobby
+	for _, userId := range userIds {
+		var err error
+		lobby, err = service.JoinLobby(
+			context.Background(),
+			&dto.JoinLobbyRequest{UserId: userId, Username: "player", Code: code},
+		)
+		if err != nil {
+			t.Fatalf(`service.JoinLobby(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+		}
+	}
+	return lobby
+}
+
+func TestService_CreateLobby_Success(t *testing.T) {
+	service, clock := newTestService()
+
+	lobby := createTestLobby(t, service)
+	if err := validateJoinCode(lobby.Code); err != nil {
+		t.Errorf(`validateJoinCode("%s") = "%v", expected "<nil>"`, lobby.Code, err)
+	}
+	if lobby.QuizTitle != "Capital Cities" || lobby.HostId != HostId || lobby.State != LobbyStateWaiting {
+		t.Errorf(`lobby = "%+v", expected a waiting lobby for "Capital Cities" hosted by "%d"`, lobby, HostId)
+	}
+	if lobby.MaxPlayers != 4 || !lobby.ExpiresAt.Equal(clock.Now().Add(30*time.Minute)) {
+		t.Errorf(`lobby = "%+v", expected the configured limit of "4" players and 30 minute timeout`, lobby)
+	}
+	if len(lobby.Players) != 1 || !lobby.Players[0].IsHost || lobby.Players[0].Username != HostName {
+		t.Errorf(`lobby.Players = "%+v", expected only the host`, lobby.Players)
+	}
+}
+
+func TestService_CreateLobby_Invalid(t *testing.T) {
+	service, _ := newTestService()
+
+	tests := map[string]struct {
+		request    dto.CreateLobbyRequest
+		statusCode int
+	}{
+		"unknown quiz": {
+			request:    dto.CreateLobbyRequest{HostId: HostId, QuizId: 99},
+			statusCode: http.StatusNotFound,
+		},
+		"another user's quiz": {
+			request:    dto.CreateLobbyRequest{HostId: GuestId, QuizId: QuizId},
+			statusCode: http.StatusNotFound,
+		},
+		"too many players": {
+			request:    dto.CreateLobbyRequest{HostId: HostId, QuizId: QuizId, MaxPlayers: 5},
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		_, err := service.CreateLobby(context.Background(), &test.request)
+		if err == nil {
+			t.Errorf(`%s: service.CreateLobby(...) error = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, test.statusCode)
+	}
+}
+
+func TestService_JoinLobby_Capacity(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestLobby(t, service)
+
+	lobby := joinTestLobby(t, service, created.Code, 2, 3, 4)
+	if len(lobby.Players) != 4 {
+		t.Fatalf(`len(lobby.Players) = "%d", expected "4"`, len(lobby.Players))
+	}
+
+	// Joining again is allowed even though the lobby is full
+	joinTestLobby(t, service, created.Code, 2)
+
+	_, err := service.JoinLobby(context.Background(), &dto.JoinLobbyRequest{UserId: 5, Code: created.Code})
+	assertHTTPError(t, err, http.StatusConflict)
+}
+
+func TestService_JoinLobby_NormalizesCode(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestLobby(t, service)
+
+	code := created.Code[:3] + "-" + created.Code[3:]
+	lobby := joinTestLobby(t, service, code, 2)
+	if len(lobby.Players) != 2 {
+		t.Errorf(`lobby.Players = "%+v", expected the host and player "2"`, lobby.Players)
+	}
+}
+
+func TestService_LeaveLobby_TransfersHost(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestLobby(t, service)
+	joinTestLobby(t, service, created.Code, 2, 3)
+
+	_, err := service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: HostId, Code: created.Code})
+	if err != nil {
+		t.Fatalf(`service.LeaveLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	lobby, err := service.GetLobby(context.Background(), &dto.GetLobbyRequest{Code: created.Code})
+	if err != nil {
+		t.Fatalf(`service.GetLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+	if lobby.HostId != 2 || len(lobby.Players) != 2 || !lobby.Players[0].IsHost || lobby.Players[1].IsHost {
+		t.Errorf(`lobby = "%+v", expected player "2", who joined first, to be host`, lobby)
+	}
+}
+
+func TestService_LeaveLobby_ClosesEmptyLobby(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestLobby(t, service)
+
+	_, err := service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: HostId, Code: created.Code})
+	if err != nil {
+		t.Fatalf(`service.LeaveLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err = service.GetLobby(context.Background(), &dto.GetLobbyRequest{Code: created.Code})
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_LeaveLobby_NotAPlayer(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestLobby(t, service)
+
+	_, err := service.LeaveLobby(context.Background(), &dto.LeaveLobbyRequest{UserId: 2, Code: created.Code})
+	assertHTTPError(t, err, http.StatusForbidden)
+}
+
+func TestService_SetReady_Success(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestLobby(t, service)
+	joinTestLobby(t, service, created.Code, 2)
+
+	lobby, err := service.SetReady(context.Background(), &dto.SetReadyRequest{UserId: 2, Code: created.Code, Ready: true})
+	if err != nil {
+		t.Fatalf(`service.SetReady(...) error = "%v", expected "<nil>"`, err)
+	}
+	if lobby.Players[0].Ready || !lobby.Players[1].Ready {
+		t.Errorf(`lobby.Players = "%+v", expected only player "2" to be ready`, lobby.Players)
+	}
+
+	_, err = service.SetReady(context.Background(), &dto.SetReadyRequest{UserId: 3, Code: created.Code, Ready: true})
+	assertHTTPError(t, err, http.StatusForbidden)
+}
+
+func TestService_ExpireLobbies(t *testing.T) {
+	service, clock := newTestService()
+	idle := createTestLobby(t, service)
+	clock.Advance(20 * time.Minute)
+	active := createTestLobby(t, service)
+
+	clock.Advance(15 * time.Minute)
+	if expired := service.ExpireLobbies(); expired != 1 {
+		t.Errorf(`service.ExpireLobbies() = "%d", expected "1"`, expired)
+	}
+
+	_, err := service.GetLobby(context.Background(), &dto.GetLobbyRequest{Code: idle.Code})
+	assertHTTPError(t, err, http.StatusNotFound)
+
+	// Activity pushes the expiry back
+	joinTestLobby(t, service, active.Code, 2)
+	clock.Advance(20 * time.Minute)
+	if _, err := service.GetLobby(context.Background(), &dto.GetLobbyRequest{Code: active.Code}); err != nil {
+		t.Errorf(`service.GetLobby(...) error = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestLobbySweeper_Run(t *testing.T) {
+	service, clock := newTestService()
+	created := createTestLobby(t, service)
+	clock.Advance(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		(&LobbySweeper{Service: service, Logger: slog.Default(), Interval: time.Millisecond}).Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		service.mutex.Lock()
+		_, ok := service.lobbies[created.Code]
+		service.mutex.Unlock()
+		if !ok {
+			break
+		}
+
+		select {
+		case <-deadline:
+			t.Fatal(`service.lobbies still holds the expired lobby, expected the sweeper to remove it`)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	<-done
+}
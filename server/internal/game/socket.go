@@ -0,0 +1,233 @@
+package game
+
+import (
+	"common"
+	"context"
+	"errors"
+	"fmt"
+	"game/dto"
+	"game/protocol"
+	"golang.org/x/net/websocket"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// MaxMessageBytes Largest message a player can send
+	MaxMessageBytes = 16 << 10
+	// socketWriteTimeout Time allowed for writing a message before the connection counts as broken
+	socketWriteTimeout = 10 * time.Second
+)
+
+// GameSocketHandler Handler function for the game WebSocket endpoint. It must follow the auth middleware, which reads
+// the JWT from the Authorization header, or from the jwt query parameter for browsers, which cannot set headers on
+// WebSocket requests. Origins are not checked, since the connection is authorized by the token rather than by cookies
+func GameSocketHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.ConnectRequest{
+			UserId: userClaims.ID,
+			Code:   parseJoinCode(r),
+		}
+
+		if err := ValidateConnectRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		subscription, err := service.Connect(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		defer func() {
+			if err := service.Disconnect(subscription); err != nil {
+				slog.ErrorContext(r.Context(), "failed to disconnect player", slog.Any("error", err))
+			}
+		}()
+
+		server := websocket.Server{
+			Handler: func(conn *websocket.Conn) {
+				serveSocket(
+					r.Context(), conn, subscription, func(message *protocol.Message) error {
+						return handleMessage(r.Context(), service, subscription, message)
+					},
+				)
+			},
+		}
+		server.ServeHTTP(w, r)
+	}
+}
+
+// MatchmakingSocketHandler Handler function for the matchmaking WebSocket endpoint, over which players are told when
+// they are matched. The connection is authorized like the game's, and closing it leaves the queue
+func MatchmakingSocketHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.ConnectMatchmakingRequest{UserId: userClaims.ID}
+
+		subscription, err := service.ConnectMatchmaking(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		defer func() {
+			if err := service.DisconnectMatchmaking(subscription); err != nil {
+				slog.ErrorContext(r.Context(), "failed to disconnect player from matchmaking", slog.Any("error", err))
+			}
+		}()
+
+		server := websocket.Server{
+			Handler: func(conn *websocket.Conn) {
+				serveSocket(r.Context(), conn, subscription, handleMatchmakingMessage)
+			},
+		}
+		server.ServeHTTP(w, r)
+	}
+}
+
+// serveSocket Write the subscription's messages to the connection while handling the messages the player sends, until
+// either the connection or the subscription ends
+func serveSocket(
+	ctx context.Context,
+	conn *websocket.Conn,
+	subscription *Subscription,
+	handle func(message *protocol.Message) error,
+) {
+	conn.MaxPayloadBytes = MaxMessageBytes
+
+	go func() {
+		defer conn.Close()
+		for message := range subscription.Messages {
+			if err := writeMessage(conn, message); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var message protocol.Message
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			return
+		}
+
+		if err := handle(&message); err != nil {
+			if err := writeMessage(conn, toErrorMessage(ctx, err)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleMessage Handle a message sent by the player of the subscription
+func handleMessage(ctx context.Context, service Service, subscription *Subscription, message *protocol.Message) error {
+	if err := checkVersion(message); err != nil {
+		return err
+	}
+
+	switch message.Type {
+	case protocol.TypeStartGame:
+		request := dto.StartGameRequest{UserId: subscription.UserId, Code: subscription.Code}
+		if err := ValidateStartGameRequest(&request); err != nil {
+			return err
+		}
+		_, err := service.StartGame(ctx, &request)
+		return err
+	case protocol.TypeSubmitAnswer:
+		var data protocol.SubmitAnswer
+		if err := message.Decode(&data); err != nil {
+			return &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid message data: " + err.Error(),
+			}
+		}
+		request := dto.SubmitAnswerRequest{
+			UserId:     subscription.UserId,
+			Code:       subscription.Code,
+			QuestionId: data.QuestionId,
+			Answer:     data.Answer,
+		}
+		if err := ValidateSubmitAnswerRequest(&request); err != nil {
+			return err
+		}
+		_, err := service.SubmitAnswer(ctx, &request)
+		return err
+	case protocol.TypeNextQuestion:
+		request := dto.NextQuestionRequest{UserId: subscription.UserId, Code: subscription.Code}
+		if err := ValidateNextQuestionRequest(&request); err != nil {
+			return err
+		}
+		_, err := service.NextQuestion(ctx, &request)
+		return err
+	default:
+		return unknownMessageTypeError(message)
+	}
+}
+
+// handleMatchmakingMessage Handle a message sent over a matchmaking connection. Players are only sent messages there,
+// and queue and cancel through the REST API
+func handleMatchmakingMessage(message *protocol.Message) error {
+	if err := checkVersion(message); err != nil {
+		return err
+	}
+	return unknownMessageTypeError(message)
+}
+
+// checkVersion Check that a message is of the current protocol version
+func checkVersion(message *protocol.Message) error {
+	if message.Version != protocol.Version {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("unsupported protocol version %d, expected %d", message.Version, protocol.Version),
+		}
+	}
+	return nil
+}
+
+// unknownMessageTypeError Get the error for a message of a type that cannot be handled
+func unknownMessageTypeError(message *protocol.Message) error {
+	return &common.HTTPError{
+		StatusCode: http.StatusBadRequest,
+		Message:    fmt.Sprintf("unknown message type %q", message.Type),
+	}
+}
+
+// writeMessage Write a message to the connection. Writes are safe to make from several goroutines
+func writeMessage(conn *websocket.Conn, message *protocol.Message) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(conn, message)
+}
+
+// toErrorMessage Convert an error to the error message sent to the player. Errors that would result in a 500 on the
+// REST API are only logged, and the player is sent a generic message
+func toErrorMessage(ctx context.Context, err error) *protocol.Message {
+	data := protocol.Error{
+		StatusCode: http.StatusInternalServerError,
+		Message:    "internal server error",
+	}
+
+	var httpErr *common.HTTPError
+	if errors.As(err, &httpErr) {
+		data.StatusCode = httpErr.StatusCode
+		data.Message = httpErr.Message
+	} else {
+		slog.ErrorContext(ctx, "unhandled service error", slog.Any("error", err))
+	}
+
+	// An error message always encodes
+	message, _ := protocol.NewMessage(protocol.TypeError, &data)
+	return message
+}
@@ -0,0 +1,328 @@
+package game
+
+import (
+	"common"
+	"common/userpb"
+	"context"
+	"errors"
+	"game/client"
+	"game/dto"
+	"game/protocol"
+	"github.com/go-chi/jwtauth/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	quizdto "quiz/dto"
+	"testing"
+	"time"
+)
+
+// newTestToken Issue a token for the user, signed with a test key
+func newTestToken(t *testing.T, userId int, username string) string {
+	if common.TokenAuth == nil {
+		common.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	}
+
+	_, token, err := common.TokenAuth.Encode(map[string]any{"user_id": userId, "username": username})
+	if err != nil {
+		t.Fatalf(`common.TokenAuth.Encode(...) error = "%v", expected "<nil>"`, err)
+	}
+	return token
+}
+
+// stubUserGetter User service client that finds no users
+type stubUserGetter struct{}
+
+// GetUser GetUser() implementation from common.UserGetter interface
+func (stubUserGetter) GetUser(
+	ctx context.Context,
+	in *userpb.GetUserRequest,
+	opts ...grpc.CallOption,
+) (*userpb.GetUserResponse, error) {
+	return nil, status.Error(codes.NotFound, "user not found")
+}
+
+// dialTestLobby Connect the user to the lobby on the server
+func dialTestLobby(t *testing.T, server *httptest.Server, code string, userId int) *client.Client {
+	player, err := client.Dial(context.Background(), server.URL, code, newTestToken(t, userId, "player"))
+	if err != nil {
+		t.Fatalf(`client.Dial(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = player.Close()
+		},
+	)
+	return player
+}
+
+// await Wait for a message of the type on the client
+func await(t *testing.T, player *client.Client, messageType string, data any) {
+	if err := player.Await(messageType, data); err != nil {
+		t.Fatalf(`player.Await("%s", data) = "%v", expected "<nil>"`, messageType, err)
+	}
+}
+
+func TestGameSocketHandler_PlayGame(t *testing.T) {
+	service, _ := newTestService()
+	server := httptest.NewServer(NewRouter(service, nil, slog.Default()))
+	defer server.Close()
+
+	code := createTestLobby(t, service).Code
+	joinTestLobby(t, service, code, GuestId)
+	host := dialTestLobby(t, server, code, HostId)
+	guest := dialTestLobby(t, server, code, GuestId)
+	await(t, host, protocol.TypeLobbyUpdate, nil)
+	await(t, guest, protocol.TypeLobbyUpdate, nil)
+
+	if err := host.StartGame(); err != nil {
+		t.Fatalf(`host.StartGame() = "%v", expected "<nil>"`, err)
+	}
+	var serverErr *protocol.Error
+	if err := host.Await(protocol.TypeQuestionStart, nil); !errors.As(err, &serverErr) ||
+		serverErr.StatusCode != http.StatusConflict {
+		t.Fatalf(`host.Await(...) = "%v", expected a 409 error while the guest is not ready`, err)
+	}
+
+	_, err := service.SetReady(context.Background(), &dto.SetReadyRequest{UserId: GuestId, Code: code, Ready: true})
+	if err != nil {
+		t.Fatalf(`service.SetReady(...) error = "%v", expected "<nil>"`, err)
+	}
+	if err := host.StartGame(); err != nil {
+		t.Fatalf(`host.StartGame() = "%v", expected "<nil>"`, err)
+	}
+
+	// Only the first answer option is correct, so the host answers every question correctly and the guest none
+	players := []struct {
+		client *client.Client
+		option int
+	}{
+		{client: host, option: 0},
+		{client: guest, option: 1},
+	}
+	for number := int64(1); number <= 2; number++ {
+		for _, player := range players {
+			var question protocol.QuestionStart
+			await(t, player.client, protocol.TypeQuestionStart, &question)
+			if question.Question.QuestionId != number {
+				t.Fatalf(`question.Question.QuestionId = "%d", expected "%d"`, question.Question.QuestionId, number)
+			}
+
+			option := question.Question.AnswerOptions[player.option]
+			answer := quizdto.Answer{AnswerOptionIds: []int64{option.AnswerOptionId}}
+			if err := player.client.SubmitAnswer(number, answer); err != nil {
+				t.Fatalf(`player.client.SubmitAnswer(...) = "%v", expected "<nil>"`, err)
+			}
+
+			var ack protocol.AnswerAck
+			await(t, player.client, protocol.TypeAnswerAck, &ack)
+			if ack.QuestionId != number {
+				t.Errorf(`ack.QuestionId = "%d", expected "%d"`, ack.QuestionId, number)
+			}
+		}
+	}
+
+	var gameOver protocol.GameOver
+	await(t, guest, protocol.TypeGameOver, &gameOver)
+	if len(gameOver.Standings) != 2 || gameOver.Standings[0].UserId != HostId || gameOver.Standings[0].Score != 2 {
+		t.Errorf(`gameOver.Standings = "%+v", expected the host first on "2"`, gameOver.Standings)
+	}
+}
+
+func TestGameSocketHandler_InvalidToken(t *testing.T) {
+	service, _ := newTestService()
+	server := httptest.NewServer(NewRouter(service, nil, slog.Default()))
+	defer server.Close()
+	code := createTestLobby(t, service).Code
+
+	if _, err := client.Dial(context.Background(), server.URL, code, "not a token"); err == nil {
+		t.Error(`client.Dial(ctx, "not a token") error = "<nil>", expected an error`)
+	}
+}
+
+func TestGameSocketHandler_UnknownUser(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+
+	// The token is validly signed, but its user no longer exists
+	request := httptest.NewRequest(http.MethodGet, "/lobby/"+code+"/ws?jwt="+newTestToken(t, HostId, HostName), nil)
+	recorder := httptest.NewRecorder()
+
+	NewRouter(service, stubUserGetter{}, slog.Default()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestGameSocketHandler_NotAPlayer(t *testing.T) {
+	service, _ := newTestService()
+	code := createTestLobby(t, service).Code
+
+	request := httptest.NewRequest(http.MethodGet, "/lobby/"+code+"/ws", nil)
+	request.Header.Set("Authorization", "Bearer "+newTestToken(t, GuestId, "guest"))
+	recorder := httptest.NewRecorder()
+
+	NewRouter(service, nil, slog.Default()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusForbidden)
+	}
+}
+
+func TestGameSocketHandler_TokenInQuery(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/lobby/ABCDEF/ws?jwt="+newTestToken(t, HostId, HostName), nil)
+	recorder := httptest.NewRecorder()
+
+	mock := &mockService{
+		connectFunc: func(context context.Context, request *dto.ConnectRequest) (*Subscription, error) {
+			if request.UserId != HostId || request.Code != "ABCDEF" {
+				t.Errorf(`request = "%+v", expected user "%d" connecting to "ABCDEF"`, request, HostId)
+			}
+			return nil, lobbyNotFoundError
+		},
+	}
+	NewRouter(mock, nil, slog.Default()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestMatchmakingSocketHandler_MatchFound(t *testing.T) {
+	service, clock := newTestService()
+	server := httptest.NewServer(NewRouter(service, nil, slog.Default()))
+	defer server.Close()
+
+	var players []*client.Client
+	for _, userId := range []int{HostId, GuestId} {
+		player, err := client.DialMatchmaking(context.Background(), server.URL, newTestToken(t, userId, "player"))
+		if err != nil {
+			t.Fatalf(`client.DialMatchmaking(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+		}
+		defer player.Close()
+		players = append(players, player)
+
+		_, err = service.Enqueue(context.Background(), &dto.EnqueueRequest{UserId: userId, Username: "player"})
+		if err != nil {
+			t.Fatalf(`service.Enqueue(ctx, %d) error = "%v", expected "<nil>"`, userId, err)
+		}
+	}
+
+	// Players only receive over the matchmaking connection
+	if err := players[0].StartGame(); err != nil {
+		t.Fatalf(`players[0].StartGame() = "%v", expected "<nil>"`, err)
+	}
+	var serverErr *protocol.Error
+	if err := players[0].Await(protocol.TypeMatchFound, nil); !errors.As(err, &serverErr) ||
+		serverErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf(`players[0].Await(...) = "%v", expected a 400 error`, err)
+	}
+
+	clock.Advance(30 * time.Second)
+	if created := service.MatchPlayers(context.Background()); created != 1 {
+		t.Fatalf(`service.MatchPlayers(ctx) = "%d", expected "1"`, created)
+	}
+	for _, player := range players {
+		var lobby protocol.MatchFound
+		await(t, player, protocol.TypeMatchFound, &lobby)
+		if !lobby.Ranked || len(lobby.Players) != 2 {
+			t.Errorf(`lobby = "%+v", expected a ranked lobby of 2 players`, lobby)
+		}
+	}
+}
+
+func TestMatchmakingSocketHandler_InvalidToken(t *testing.T) {
+	service, _ := newTestService()
+	server := httptest.NewServer(NewRouter(service, nil, slog.Default()))
+	defer server.Close()
+
+	if _, err := client.DialMatchmaking(context.Background(), server.URL, "not a token"); err == nil {
+		t.Error(`client.DialMatchmaking(ctx, "not a token") error = "<nil>", expected an error`)
+	}
+}
+
+func TestHandleMessage_Invalid(t *testing.T) {
+	subscription := &Subscription{Code: "ABCDEF", UserId: HostId}
+
+	tests := map[string]*protocol.Message{
+		"unsupported version": {Version: protocol.Version + 1, Type: protocol.TypeStartGame},
+		"unknown type":        {Version: protocol.Version, Type: "chat"},
+		"invalid data":        {Version: protocol.Version, Type: protocol.TypeSubmitAnswer, Data: []byte(`[]`)},
+		"missing answer": {
+			Version: protocol.Version,
+			Type:    protocol.TypeSubmitAnswer,
+			Data:    []byte(`{"questionId":1}`),
+		},
+	}
+
+	for name, message := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				err := handleMessage(context.Background(), &mockService{}, subscription, message)
+				assertHTTPError(t, err, http.StatusBadRequest)
+			},
+		)
+	}
+}
+
+func TestHandleMessage_SubmitAnswer(t *testing.T) {
+	subscription := &Subscription{Code: "ABCDEF", UserId: HostId}
+	message, _ := protocol.NewMessage(
+		protocol.TypeSubmitAnswer,
+		&protocol.SubmitAnswer{QuestionId: 1, Answer: quizdto.Answer{AnswerOptionIds: []int64{11}}},
+	)
+
+	called := false
+	mock := &mockService{
+		submitAnswerFunc: func(context context.Context, request *dto.SubmitAnswerRequest) (
+			*dto.SubmitAnswerResponse,
+			error,
+		) {
+			called = true
+			if request.UserId != HostId || request.Code != "ABCDEF" || request.QuestionId != 1 {
+				t.Errorf(`request = "%+v", expected the host answering question "1" in "ABCDEF"`, request)
+			}
+			return &dto.SubmitAnswerResponse{}, nil
+		},
+	}
+
+	if err := handleMessage(context.Background(), mock, subscription, message); err != nil || !called {
+		t.Errorf(`handleMessage(...) = "%v", called = "%v", expected "<nil>", "true"`, err, called)
+	}
+}
+
+func TestToErrorMessage(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected protocol.Error
+	}{
+		"http error": {
+			err:      notInLobbyError,
+			expected: protocol.Error{StatusCode: http.StatusForbidden, Message: "not a player in this lobby"},
+		},
+		"other": {
+			err:      errors.New("boom"),
+			expected: protocol.Error{StatusCode: http.StatusInternalServerError, Message: "internal server error"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				message := toErrorMessage(context.Background(), test.err)
+
+				var data protocol.Error
+				if err := message.Decode(&data); err != nil || message.Type != protocol.TypeError {
+					t.Fatalf(`message = "%+v", expected an error message`, message)
+				}
+				if data != test.expected {
+					t.Errorf(`data = "%+v", expected "%+v"`, data, test.expected)
+				}
+			},
+		)
+	}
+}
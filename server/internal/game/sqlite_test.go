@@ -0,0 +1,221 @@
+package game
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"game/db/generated"
+	"game/db/sqlite"
+	"game/dto"
+	"game/rating"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newSQLiteService Create a test service recording finished games in a migrated in-memory SQLite database
+func newSQLiteService(t *testing.T) (*ServiceImpl, *testClock) {
+	config := &common.DatabaseConfig{
+		Driver:         common.DriverSQLite,
+		Url:            ":memory:",
+		ConnectTimeout: time.Second,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+
+	database, err := common.OpenDatabase(context.Background(), config)
+	if err != nil {
+		t.Fatalf(`common.OpenDatabase(...) error = "%v", expected "<nil>"`, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = database.Close()
+		},
+	)
+
+	err = common.RunServiceMigrations(
+		context.Background(),
+		database,
+		common.DriverSQLite,
+		Migrations(common.DriverSQLite),
+		MigrationTable,
+		common.MigrateUp,
+		io.Discard,
+	)
+	if err != nil {
+		t.Fatalf(`common.RunServiceMigrations(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	service, clock := newTestService()
+	service.Queries = sqlite.New(database)
+	service.Transactor = &SQLTransactor{
+		Database: database,
+		NewQuerier: func(tx db.DBTX) db.Querier {
+			return sqlite.New(tx)
+		},
+		MaxRetries: common.DefaultTxMaxRetries,
+	}
+	return service, clock
+}
+
+func TestSQLite_Leaderboards(t *testing.T) {
+	service, clock := newSQLiteService(t)
+	now := service.Now()
+
+	recordTestGame(
+		t, service, QuizId, now.AddDate(0, 0, -7),
+		dto.GameResult{UserId: 5, Score: 50, Rank: 1},
+	)
+	recordTestGame(
+		t, service, QuizId, now,
+		dto.GameResult{UserId: GuestId, Score: 30, Rank: 1},
+		dto.GameResult{UserId: HostId, Score: 20, Rank: 2},
+		dto.GameResult{UserId: 5, Score: 20, Rank: 2},
+	)
+	recordTestGame(t, service, QuizId+1, now, dto.GameResult{UserId: HostId, Score: 10, Rank: 1})
+
+	allTime := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardAllTime})
+	assertEntries(
+		t, allTime,
+		dto.LeaderboardEntry{Rank: 1, UserId: 5, Score: 70},
+		dto.LeaderboardEntry{Rank: 2, UserId: HostId, Score: 30},
+		dto.LeaderboardEntry{Rank: 2, UserId: GuestId, Score: 30},
+	)
+	if allTime.Entries[0].GamesPlayed != 2 || allTime.Entries[1].Username != HostName {
+		t.Errorf(`allTime.Entries = "%+v", expected user 5 with "2" games and "%s"`, allTime.Entries, HostName)
+	}
+
+	limit := 1
+	aroundMe := getTestLeaderboard(
+		t, service, dto.GetLeaderboardRequest{Board: BoardWeekly, Limit: &limit, AroundMe: true},
+	)
+	assertEntries(t, aroundMe, dto.LeaderboardEntry{Rank: 1, UserId: HostId, Score: 30})
+
+	quiz := getTestLeaderboard(t, service, dto.GetLeaderboardRequest{Board: BoardQuiz, QuizId: QuizId + 1})
+	assertEntries(t, quiz, dto.LeaderboardEntry{Rank: 1, UserId: HostId, Score: 10})
+
+	game, err := service.GetGameResults(context.Background(), &dto.GetGameResultsRequest{GameId: 2})
+	if err != nil {
+		t.Fatalf(`service.GetGameResults(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(game.Results) != 3 || !game.FinishedAt.Equal(now) || game.Results[2].UserId != 5 {
+		t.Errorf(`game = "%+v", expected 3 results ranked as recorded`, game)
+	}
+
+	// Last week's board and then this week's are reset once their week ends
+	if deleted, err := service.ResetWeeklyLeaderboards(context.Background()); err != nil || deleted != 1 {
+		t.Errorf(`service.ResetWeeklyLeaderboards(ctx) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+	clock.Advance(7 * 24 * time.Hour)
+	if deleted, err := service.ResetWeeklyLeaderboards(context.Background()); err != nil || deleted != 3 {
+		t.Errorf(`service.ResetWeeklyLeaderboards(ctx) = "%d", "%v", expected "3", "<nil>"`, deleted, err)
+	}
+
+	_, err = service.GetLeaderboard(
+		context.Background(),
+		&dto.GetLeaderboardRequest{UserId: HostId, Board: BoardWeekly, AroundMe: true},
+	)
+	assertHTTPError(t, err, http.StatusNotFound)
+
+	_, err = service.Queries.GetLeaderboardScore(
+		context.Background(),
+		db.GetLeaderboardScoreParams{Board: BoardAllTime, UserID: HostId},
+	)
+	if err != nil {
+		t.Errorf(`service.Queries.GetLeaderboardScore(ctx, "%s") error = "%v", expected "<nil>"`, BoardAllTime, err)
+	}
+}
+
+func TestSQLite_RecordGame_RollsBack(t *testing.T) {
+	service, _ := newSQLiteService(t)
+
+	_, err := service.RecordGame(
+		context.Background(), &dto.RecordGameRequest{
+			Code:       "ABC234",
+			QuizId:     QuizId,
+			FinishedAt: service.Now(),
+			Results:    []dto.GameResult{{UserId: HostId, Score: 10, Rank: 1}, {UserId: HostId, Score: 10, Rank: 1}},
+		},
+	)
+	if err == nil {
+		t.Fatal(`service.RecordGame(...) error = "<nil>", expected non-nil for a player recorded twice`)
+	}
+
+	_, err = service.Queries.GetLeaderboardScore(
+		context.Background(),
+		db.GetLeaderboardScoreParams{Board: BoardAllTime, UserID: HostId},
+	)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`service.Queries.GetLeaderboardScore(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestSQLite_Ratings(t *testing.T) {
+	service, _ := newSQLiteService(t)
+
+	for range 2 {
+		_, err := service.RecordGame(
+			context.Background(), &dto.RecordGameRequest{
+				Code:       "ABC234",
+				QuizId:     QuizId,
+				FinishedAt: service.Now(),
+				Results:    []dto.GameResult{{UserId: HostId, Score: 2, Rank: 1}, {UserId: GuestId, Score: 1, Rank: 2}},
+				Ranked:     true,
+				Category:   "history",
+			},
+		)
+		if err != nil {
+			t.Fatalf(`service.RecordGame(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	host := getTestRating(t, service, HostId, "history")
+	if host.GamesPlayed != 2 || host.Rating <= rating.DefaultRating {
+		t.Errorf(`host = "%+v", expected a rating above the default after "2" wins`, host)
+	}
+	if guest := getTestRating(t, service, GuestId, ""); guest.GamesPlayed != 2 || guest.Rating >= rating.DefaultRating {
+		t.Errorf(`guest = "%+v", expected a rating below the default after "2" losses`, guest)
+	}
+}
+
+func TestSQLite_UserData(t *testing.T) {
+	service, _ := newSQLiteService(t)
+	_, err := service.RecordGame(
+		context.Background(), &dto.RecordGameRequest{
+			Code:       "ABC234",
+			QuizId:     QuizId,
+			FinishedAt: service.Now(),
+			Results: []dto.GameResult{
+				{UserId: HostId, Score: 20, Rank: 1},
+				{UserId: GuestId, Score: 10, Rank: 2},
+			},
+			Ranked: true,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.RecordGame(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	data, err := service.GetUserData(context.Background(), &dto.GetUserDataRequest{UserId: HostId})
+	if err != nil {
+		t.Fatalf(`service.GetUserData(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(data.Games) != 1 || !data.Games[0].FinishedAt.Equal(service.Now()) || len(data.Ratings) != 1 {
+		t.Errorf(`data = "%+v", expected the recorded game and rating`, data)
+	}
+
+	deleted, err := service.DeleteUserData(context.Background(), &dto.DeleteUserDataRequest{UserId: HostId})
+	if err != nil {
+		t.Fatalf(`service.DeleteUserData(...) error = "%v", expected "<nil>"`, err)
+	}
+	if deleted.Games != 1 || deleted.LeaderboardScores != 3 || deleted.Ratings != 1 {
+		t.Errorf(`deleted = "%+v", expected "1" game, "3" leaderboard scores and "1" rating`, deleted)
+	}
+
+	guest, err := service.GetUserData(context.Background(), &dto.GetUserDataRequest{UserId: GuestId})
+	if err != nil || len(guest.Games) != 1 {
+		t.Errorf(`service.GetUserData(guest) = "%+v", "%v", expected the guest's game to be kept`, guest, err)
+	}
+}
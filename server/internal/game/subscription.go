@@ -0,0 +1,102 @@
+package game
+
+import (
+	"game/protocol"
+)
+
+// subscriptionBuffer How many messages can wait to be written to a connection before it counts as too slow and its
+// subscription ends
+const subscriptionBuffer = 64
+
+// Subscription A player's connection to a lobby, or to matchmaking when Code is empty. Messages receives what is sent
+// to the player, and is closed once the subscription ends because the player disconnected, left, connected again
+// elsewhere or fell behind, or the lobby closed
+type Subscription struct {
+	Code     string
+	UserId   int
+	Messages <-chan *protocol.Message
+
+	messages chan *protocol.Message
+}
+
+// newSubscription Create a subscription of the player to the lobby with the join code, or to matchmaking without one
+func newSubscription(code string, userId int) *Subscription {
+	messages := make(chan *protocol.Message, subscriptionBuffer)
+	return &Subscription{
+		Code:     code,
+		UserId:   userId,
+		Messages: messages,
+		messages: messages,
+	}
+}
+
+// offer Queue a message for the subscription without waiting, returning false if its buffer is full
+func (subscription *Subscription) offer(message *protocol.Message) bool {
+	select {
+	case subscription.messages <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// subscribe Subscribe the player to the lobby's messages, ending any subscription they already had. The mutex must be
+// held
+func (lobby *lobby) subscribe(userId int) *Subscription {
+	if previous, ok := lobby.subscriptions[userId]; ok {
+		lobby.unsubscribe(previous)
+	}
+
+	subscription := newSubscription(lobby.code, userId)
+	lobby.subscriptions[userId] = subscription
+	return subscription
+}
+
+// unsubscribe End the subscription. The mutex must be held
+func (lobby *lobby) unsubscribe(subscription *Subscription) {
+	delete(lobby.subscriptions, subscription.UserId)
+	close(subscription.messages)
+}
+
+// unsubscribeAll End every subscription to the lobby. The mutex must be held
+func (lobby *lobby) unsubscribeAll() {
+	for _, subscription := range lobby.subscriptions {
+		lobby.unsubscribe(subscription)
+	}
+}
+
+// send Send a message to the player if they are connected. The mutex must be held
+func (lobby *lobby) send(userId int, messageType string, data any) error {
+	subscription, ok := lobby.subscriptions[userId]
+	if !ok {
+		return nil
+	}
+
+	message, err := protocol.NewMessage(messageType, data)
+	if err != nil {
+		return err
+	}
+	lobby.deliver(subscription, message)
+	return nil
+}
+
+// broadcast Send a message to every connected player. The mutex must be held
+func (lobby *lobby) broadcast(messageType string, data any) error {
+	message, err := protocol.NewMessage(messageType, data)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range lobby.subscriptions {
+		lobby.deliver(subscription, message)
+	}
+	return nil
+}
+
+// deliver Queue a message for the subscription without waiting, ending the subscription if its buffer is full so a
+// slow connection cannot hold up the lobby. The mutex must be held
+func (lobby *lobby) deliver(subscription *Subscription, message *protocol.Message) {
+	if !subscription.offer(message) {
+		lobby.unsubscribe(subscription)
+	}
+}
@@ -0,0 +1,399 @@
+package game
+
+import (
+	"common"
+	"common/userpb"
+	"context"
+	"errors"
+	"game/db/memory"
+	"game/dto"
+	"google.golang.org/grpc"
+	"net/http"
+	quizdto "quiz/dto"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	HostId   = 1
+	HostName = "host"
+	GuestId  = 2
+	QuizId   = 3
+)
+
+type mockService struct {
+	createLobbyFunc  func(context context.Context, request *dto.CreateLobbyRequest) (*dto.CreateLobbyResponse, error)
+	getLobbyFunc     func(context context.Context, request *dto.GetLobbyRequest) (*dto.GetLobbyResponse, error)
+	joinLobbyFunc    func(context context.Context, request *dto.JoinLobbyRequest) (*dto.JoinLobbyResponse, error)
+	leaveLobbyFunc   func(context context.Context, request *dto.LeaveLobbyRequest) (*dto.LeaveLobbyResponse, error)
+	setReadyFunc     func(context context.Context, request *dto.SetReadyRequest) (*dto.SetReadyResponse, error)
+	connectFunc      func(context context.Context, request *dto.ConnectRequest) (*Subscription, error)
+	disconnectFunc   func(subscription *Subscription) error
+	startGameFunc    func(context context.Context, request *dto.StartGameRequest) (*dto.StartGameResponse, error)
+	submitAnswerFunc func(
+		context context.Context,
+		request *dto.SubmitAnswerRequest,
+	) (*dto.SubmitAnswerResponse, error)
+	nextQuestionFunc func(
+		context context.Context,
+		request *dto.NextQuestionRequest,
+	) (*dto.NextQuestionResponse, error)
+	getGameResultsFunc func(
+		context context.Context,
+		request *dto.GetGameResultsRequest,
+	) (*dto.GetGameResultsResponse, error)
+	getLeaderboardFunc func(
+		context context.Context,
+		request *dto.GetLeaderboardRequest,
+	) (*dto.GetLeaderboardResponse, error)
+	getRatingFunc   func(context context.Context, request *dto.GetRatingRequest) (*dto.GetRatingResponse, error)
+	enqueueFunc     func(context context.Context, request *dto.EnqueueRequest) (*dto.EnqueueResponse, error)
+	cancelQueueFunc func(
+		context context.Context,
+		request *dto.CancelQueueRequest,
+	) (*dto.CancelQueueResponse, error)
+	connectMatchmakingFunc func(
+		context context.Context,
+		request *dto.ConnectMatchmakingRequest,
+	) (*Subscription, error)
+	disconnectMatchmakingFunc func(subscription *Subscription) error
+	getUserDataFunc           func(
+		context context.Context,
+		request *dto.GetUserDataRequest,
+	) (*dto.GetUserDataResponse, error)
+	deleteUserDataFunc func(
+		context context.Context,
+		request *dto.DeleteUserDataRequest,
+	) (*dto.DeleteUserDataResponse, error)
+}
+
+func (m *mockService) CreateLobby(context context.Context, request *dto.CreateLobbyRequest) (
+	*dto.CreateLobbyResponse,
+	error,
+) {
+	return m.createLobbyFunc(context, request)
+}
+
+func (m *mockService) GetLobby(context context.Context, request *dto.GetLobbyRequest) (*dto.GetLobbyResponse, error) {
+	return m.getLobbyFunc(context, request)
+}
+
+func (m *mockService) JoinLobby(context context.Context, request *dto.JoinLobbyRequest) (
+	*dto.JoinLobbyResponse,
+	error,
+) {
+	return m.joinLobbyFunc(context, request)
+}
+
+func (m *mockService) LeaveLobby(context context.Context, request *dto.LeaveLobbyRequest) (
+	*dto.LeaveLobbyResponse,
+	error,
+) {
+	return m.leaveLobbyFunc(context, request)
+}
+
+func (m *mockService) SetReady(context context.Context, request *dto.SetReadyRequest) (*dto.SetReadyResponse, error) {
+	return m.setReadyFunc(context, request)
+}
+
+func (m *mockService) Connect(context context.Context, request *dto.ConnectRequest) (*Subscription, error) {
+	return m.connectFunc(context, request)
+}
+
+func (m *mockService) Disconnect(subscription *Subscription) error {
+	return m.disconnectFunc(subscription)
+}
+
+func (m *mockService) StartGame(context context.Context, request *dto.StartGameRequest) (
+	*dto.StartGameResponse,
+	error,
+) {
+	return m.startGameFunc(context, request)
+}
+
+func (m *mockService) SubmitAnswer(context context.Context, request *dto.SubmitAnswerRequest) (
+	*dto.SubmitAnswerResponse,
+	error,
+) {
+	return m.submitAnswerFunc(context, request)
+}
+
+func (m *mockService) NextQuestion(context context.Context, request *dto.NextQuestionRequest) (
+	*dto.NextQuestionResponse,
+	error,
+) {
+	return m.nextQuestionFunc(context, request)
+}
+
+func (m *mockService) GetGameResults(context context.Context, request *dto.GetGameResultsRequest) (
+	*dto.GetGameResultsResponse,
+	error,
+) {
+	return m.getGameResultsFunc(context, request)
+}
+
+func (m *mockService) GetLeaderboard(context context.Context, request *dto.GetLeaderboardRequest) (
+	*dto.GetLeaderboardResponse,
+	error,
+) {
+	return m.getLeaderboardFunc(context, request)
+}
+
+func (m *mockService) GetRating(context context.Context, request *dto.GetRatingRequest) (
+	*dto.GetRatingResponse,
+	error,
+) {
+	return m.getRatingFunc(context, request)
+}
+
+func (m *mockService) Enqueue(context context.Context, request *dto.EnqueueRequest) (*dto.EnqueueResponse, error) {
+	return m.enqueueFunc(context, request)
+}
+
+func (m *mockService) CancelQueue(context context.Context, request *dto.CancelQueueRequest) (
+	*dto.CancelQueueResponse,
+	error,
+) {
+	return m.cancelQueueFunc(context, request)
+}
+
+func (m *mockService) ConnectMatchmaking(context context.Context, request *dto.ConnectMatchmakingRequest) (
+	*Subscription,
+	error,
+) {
+	return m.connectMatchmakingFunc(context, request)
+}
+
+func (m *mockService) DisconnectMatchmaking(subscription *Subscription) error {
+	return m.disconnectMatchmakingFunc(subscription)
+}
+
+func (m *mockService) GetUserData(context context.Context, request *dto.GetUserDataRequest) (
+	*dto.GetUserDataResponse,
+	error,
+) {
+	return m.getUserDataFunc(context, request)
+}
+
+func (m *mockService) DeleteUserData(context context.Context, request *dto.DeleteUserDataRequest) (
+	*dto.DeleteUserDataResponse,
+	error,
+) {
+	return m.deleteUserDataFunc(context, request)
+}
+
+// stubUserDirectory UserDirectory holding usernames by user id
+type stubUserDirectory map[int64]string
+
+func (s stubUserDirectory) BatchGetUsers(
+	ctx context.Context,
+	in *userpb.BatchGetUsersRequest,
+	opts ...grpc.CallOption,
+) (*userpb.BatchGetUsersResponse, error) {
+	response := &userpb.BatchGetUsersResponse{}
+	for _, id := range in.Ids {
+		if username, ok := s[id]; ok {
+			response.Users = append(response.Users, &userpb.User{Id: id, Username: username})
+		} else {
+			response.MissingIds = append(response.MissingIds, id)
+		}
+	}
+	return response, nil
+}
+
+// stubQuizSource QuizSource holding playable quizzes by id, all owned by HostId. The first answer option of every
+// question is the correct one
+type stubQuizSource map[int64]*quizdto.PlayableQuiz
+
+// GetGameQuiz Serve the quiz with the first answer option of each question as its correct answer
+func (s stubQuizSource) GetGameQuiz(
+	context context.Context,
+	request *quizdto.GetGameQuizRequest,
+) (*quizdto.GetGameQuizResponse, error) {
+	quiz, ok := s[request.QuizId]
+	if !ok || (request.OwnerId != 0 && request.OwnerId != HostId) {
+		return nil, &common.HTTPError{StatusCode: http.StatusNotFound, Message: "quiz not found"}
+	}
+
+	response := &quizdto.GameQuiz{Quiz: *quiz, Questions: make([]quizdto.Question, len(quiz.Questions))}
+	for i, question := range quiz.Questions {
+		response.Questions[i] = quizdto.Question{
+			QuestionId:    question.QuestionId,
+			Position:      question.Position,
+			Type:          question.Type,
+			Prompt:        question.Prompt,
+			AnswerOptions: make([]quizdto.AnswerOption, len(question.AnswerOptions)),
+		}
+		for j, option := range question.AnswerOptions {
+			response.Questions[i].AnswerOptions[j] = quizdto.AnswerOption{
+				AnswerOptionId: option.AnswerOptionId,
+				Position:       j + 1,
+				Text:           option.Text,
+				IsCorrect:      j == 0,
+			}
+		}
+	}
+	return response, nil
+}
+
+// GenerateQuiz Serve the quiz with id QuizId as the generated quiz, or fail as the question bank does when it has too
+// few questions if there is none
+func (s stubQuizSource) GenerateQuiz(
+	context context.Context,
+	request *quizdto.GenerateQuizRequest,
+) (*quizdto.GenerateQuizResponse, error) {
+	quiz, ok := s[QuizId]
+	if !ok {
+		return nil, &common.HTTPError{StatusCode: http.StatusConflict, Message: "not enough questions"}
+	}
+	return &quizdto.GenerateQuizResponse{QuizId: quiz.QuizId, OwnerId: request.OwnerId, Title: quiz.Title}, nil
+}
+
+// testClock Clock that only moves when the test advances it, running the timers that come due as it does
+type testClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*testTimer
+}
+
+type testTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *testClock) AfterFunc(duration time.Duration, f func()) func() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	timer := &testTimer{at: c.now.Add(duration), f: f}
+	c.timers = append(c.timers, timer)
+	return func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		stopped := !timer.stopped
+		timer.stopped = true
+		return stopped
+	}
+}
+
+// Advance Move the clock forward, running the timers that come due in order on the calling goroutine
+func (c *testClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(duration)
+	for {
+		var next *testTimer
+		for _, timer := range c.timers {
+			if !timer.stopped && !timer.at.After(end) && (next == nil || timer.at.Before(next.at)) {
+				next = timer
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		next.stopped = true
+		c.now = next.at
+		// Timers may use the clock themselves
+		c.mutex.Unlock()
+		next.f()
+		c.mutex.Lock()
+	}
+	c.now = end
+	c.mutex.Unlock()
+}
+
+// activeTimers Count the timers that are neither stopped nor run
+func (c *testClock) activeTimers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	active := 0
+	for _, timer := range c.timers {
+		if !timer.stopped {
+			active++
+		}
+	}
+	return active
+}
+
+// newTestService Create a service holding lobbies of at most 4 players for a quiz with id QuizId and two questions,
+// which have 20 seconds to answer with half a second of grace. Finished games are recorded in memory, resolving the
+// host's and guest's usernames. Matches are made for 3 players, or 2 after 30 seconds, with windows of 100 rating
+// points widening by 10 a second up to 300. The service runs on a clock that only moves when the test advances it
+func newTestService() (*ServiceImpl, *testClock) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	quizzes := stubQuizSource{
+		QuizId: {
+			QuizId: QuizId,
+			Title:  "Capital Cities",
+			Questions: []quizdto.PlayableQuestion{
+				{
+					QuestionId: 1,
+					Position:   1,
+					Type:       "single_choice",
+					Prompt:     "What is the capital of France?",
+					AnswerOptions: []quizdto.PlayableAnswerOption{
+						{AnswerOptionId: 11, Text: "Paris"},
+						{AnswerOptionId: 12, Text: "Lyon"},
+					},
+				},
+				{
+					QuestionId: 2,
+					Position:   2,
+					Type:       "single_choice",
+					Prompt:     "What is the capital of Italy?",
+					AnswerOptions: []quizdto.PlayableAnswerOption{
+						{AnswerOptionId: 21, Text: "Rome"},
+						{AnswerOptionId: 22, Text: "Milan"},
+					},
+				},
+			},
+		},
+	}
+
+	service := NewService(
+		quizzes, &common.LobbyConfig{
+			MaxPlayers:        4,
+			IdleTimeout:       30 * time.Minute,
+			QuestionTimeLimit: 20 * time.Second,
+			AnswerGrace:       500 * time.Millisecond,
+		},
+	)
+	service.Now = clock.Now
+	service.AfterFunc = clock.AfterFunc
+	queries := memory.New()
+	service.Queries = queries
+	service.Transactor = queries
+	service.Users = stubUserDirectory{HostId: HostName, GuestId: "guest"}
+	service.Matchmaking = common.MatchmakingConfig{
+		Players:       3,
+		MinPlayers:    2,
+		InitialWindow: 100,
+		WindowGrowth:  10,
+		MaxWindow:     300,
+		MaxWait:       30 * time.Second,
+		Interval:      time.Second,
+		QuestionCount: 2,
+	}
+	return service, clock
+}
+
+func assertHTTPError(t *testing.T, err error, statusCode int) {
+	var httpErr *common.HTTPError
+	if ok := errors.As(err, &httpErr); !ok {
+		t.Fatalf(`errors.As(err, &httpErr) = "%v", expected "true"`, ok)
+	}
+
+	if httpErr.StatusCode != statusCode {
+		t.Errorf(`httpErr.StatusCode = "%d", expected "%d"`, httpErr.StatusCode, statusCode)
+	}
+}
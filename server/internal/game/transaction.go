@@ -0,0 +1,36 @@
+package game
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"game/db/generated"
+)
+
+// Transactor Runs a group of queries atomically against a db.Querier scoped to a single transaction
+type Transactor interface {
+	RunInTx(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error
+}
+
+// SQLTransactor Transactor backed by a SQL database, retrying transactions that fail to serialize
+type SQLTransactor struct {
+	Database *sql.DB
+	// NewQuerier Create a querier bound to the transaction
+	NewQuerier func(tx db.DBTX) db.Querier
+	MaxRetries int
+}
+
+var _ Transactor = (*SQLTransactor)(nil)
+
+// RunInTx RunInTx() implementation from Transactor interface
+func (transactor *SQLTransactor) RunInTx(
+	ctx context.Context,
+	options *sql.TxOptions,
+	fn func(queries db.Querier) error,
+) error {
+	return common.RunInTx(
+		ctx, transactor.Database, options, transactor.MaxRetries, func(tx *sql.Tx) error {
+			return fn(transactor.NewQuerier(tx))
+		},
+	)
+}
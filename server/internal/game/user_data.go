@@ -0,0 +1,207 @@
+package game
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"game/db/generated"
+	"game/dto"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// EventUserDeleted Type of the user service event sent once a user is deleted, after which their game data is deleted
+const EventUserDeleted = "user.deleted"
+
+// GetUserData Retrieve the games, leaderboard scores and ratings stored for the user, for their data export
+func (service *ServiceImpl) GetUserData(
+	context context.Context,
+	request *dto.GetUserDataRequest,
+) (*dto.GetUserDataResponse, error) {
+	response := &dto.UserData{
+		UserId:            request.UserId,
+		Games:             []dto.UserGame{},
+		LeaderboardScores: []dto.UserLeaderboardScore{},
+		Ratings:           []dto.PlayerRating{},
+	}
+	if service.Queries == nil {
+		return response, nil
+	}
+
+	userId := int32(request.UserId)
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			games, err := queries.GetUserGameResults(context, userId)
+			if err != nil {
+				return err
+			}
+			for _, game := range games {
+				response.Games = append(
+					response.Games, dto.UserGame{
+						GameId:     game.GameID,
+						QuizId:     game.QuizID,
+						FinishedAt: game.FinishedAt,
+						Score:      game.Score,
+						Rank:       int(game.Rank),
+					},
+				)
+			}
+
+			scores, err := queries.GetUserLeaderboardScores(context, userId)
+			if err != nil {
+				return err
+			}
+			for _, score := range scores {
+				response.LeaderboardScores = append(
+					response.LeaderboardScores, dto.UserLeaderboardScore{
+						Board:       score.Board,
+						Score:       score.Score,
+						GamesPlayed: int(score.GamesPlayed),
+						UpdatedAt:   score.UpdatedAt,
+						ExpiresAt:   nullableTime(score.ExpiresAt),
+					},
+				)
+			}
+
+			ratings, err := queries.GetUserRatings(context, userId)
+			if err != nil {
+				return err
+			}
+			for _, stored := range ratings {
+				response.Ratings = append(
+					response.Ratings, dto.PlayerRating{
+						UserId:      request.UserId,
+						Category:    stored.Category,
+						Rating:      stored.Rating,
+						Deviation:   stored.Deviation,
+						Volatility:  stored.Volatility,
+						GamesPlayed: int(stored.GamesPlayed),
+					},
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user data: %w", err)
+	}
+	return response, nil
+}
+
+// DeleteUserData Delete the user's game results, leaderboard scores and ratings. The games themselves are kept for the
+// other players
+func (service *ServiceImpl) DeleteUserData(
+	context context.Context,
+	request *dto.DeleteUserDataRequest,
+) (*dto.DeleteUserDataResponse, error) {
+	response := &dto.DeleteUserDataResponse{}
+	if service.Queries == nil {
+		return response, nil
+	}
+
+	userId := int32(request.UserId)
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			if response.Games, err = queries.DeleteUserGameResults(context, userId); err != nil {
+				return err
+			}
+			if response.LeaderboardScores, err = queries.DeleteUserLeaderboardScores(context, userId); err != nil {
+				return err
+			}
+			response.Ratings, err = queries.DeleteUserRatings(context, userId)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete user data: %w", err)
+	}
+	return response, nil
+}
+
+// NewInternalRouter Create the router for the routes only the user service calls, which must sign its requests with
+// the secret. It is mounted at /internal
+func NewInternalRouter(service Service, secret []byte) *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middleware.Timeout(time.Minute))
+	router.Use(common.WebhookAuthenticator(secret))
+
+	router.Post("/user-data", GetUserDataHandler(service))
+	router.Post("/user-events", UserEventsHandler(service))
+
+	return router
+}
+
+// GetUserDataHandler Handler function for get user data endpoint
+func GetUserDataHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request dto.GetUserDataRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.UserId <= 0 {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		response, err := service.GetUserData(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// UserEventsHandler Handler function for the user service's event webhook. Deleted users have their game data
+// deleted, and every other event is acknowledged without doing anything
+func UserEventsHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var event common.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if event.Type != EventUserDeleted {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var request dto.DeleteUserDataRequest
+		if err := json.Unmarshal(event.Data, &request); err != nil || request.UserId <= 0 {
+			http.Error(w, "invalid event data", http.StatusBadRequest)
+			return
+		}
+
+		response, err := service.DeleteUserData(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		slog.InfoContext(
+			r.Context(), "deleted user data",
+			slog.Int("user_id", request.UserId),
+			slog.Int64("games", response.Games),
+			slog.Int64("leaderboard_scores", response.LeaderboardScores),
+			slog.Int64("ratings", response.Ratings),
+		)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// nullableTime Get the time, or nil if it is null
+func nullableTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
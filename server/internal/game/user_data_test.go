@@ -0,0 +1,96 @@
+package game
+
+import (
+	"common"
+	"context"
+	"encoding/json"
+	"game/dto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postInternal Send a request signed with the secret to the internal router
+func postInternal(router http.Handler, secret string, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	common.SignRequest(request, []byte(secret), []byte(body), time.Now())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestService_GetUserData(t *testing.T) {
+	service, _ := newTestService()
+	_, err := service.RecordGame(
+		context.Background(), &dto.RecordGameRequest{
+			Code:       "ABC234",
+			QuizId:     QuizId,
+			FinishedAt: service.Now(),
+			Results: []dto.GameResult{
+				{UserId: HostId, Score: 20, Rank: 1},
+				{UserId: GuestId, Score: 10, Rank: 2},
+			},
+			Ranked: true,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.RecordGame(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	data, err := service.GetUserData(context.Background(), &dto.GetUserDataRequest{UserId: HostId})
+	if err != nil {
+		t.Fatalf(`service.GetUserData(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(data.Games) != 1 || data.Games[0].QuizId != QuizId || data.Games[0].Score != 20 || data.Games[0].Rank != 1 {
+		t.Errorf(`data.Games = "%+v", expected the recorded game`, data.Games)
+	}
+	if len(data.LeaderboardScores) != 3 {
+		t.Errorf(`data.LeaderboardScores = "%+v", expected the all-time, weekly and quiz boards`,
+			data.LeaderboardScores)
+	}
+	if len(data.Ratings) != 1 || data.Ratings[0].GamesPlayed != 1 {
+		t.Errorf(`data.Ratings = "%+v", expected the overall rating`, data.Ratings)
+	}
+}
+
+func TestUserEventsHandler_DeletesUserData(t *testing.T) {
+	service, _ := newTestService()
+	recordTestGame(
+		t, service, QuizId, service.Now(),
+		dto.GameResult{UserId: HostId, Score: 20, Rank: 1},
+		dto.GameResult{UserId: GuestId, Score: 10, Rank: 2},
+	)
+	router := NewInternalRouter(service, []byte("secret"))
+
+	recorder := postInternal(router, "secret", "/user-events", `{"id":1,"type":"user.updated","data":{"userId":1}}`)
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf(`recorder.Code = "%v" for user.updated, expected "%v"`, recorder.Code, http.StatusNoContent)
+	}
+
+	event := `{"id":2,"type":"user.deleted","data":{"userId":1,"username":"host"}}`
+	if recorder := postInternal(router, "other", "/user-events", event); recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v" with the wrong secret, expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+	if recorder := postInternal(router, "secret", "/user-events", event); recorder.Code != http.StatusNoContent {
+		t.Fatalf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNoContent)
+	}
+
+	recorder = postInternal(router, "secret", "/user-data", `{"userId":1}`)
+	var data dto.UserData
+	if err := json.NewDecoder(recorder.Body).Decode(&data); err != nil {
+		t.Fatalf(`json.NewDecoder(recorder.Body).Decode(&data) = "%v", expected "<nil>"`, err)
+	}
+	if len(data.Games) != 0 || len(data.LeaderboardScores) != 0 || len(data.Ratings) != 0 {
+		t.Errorf(`data = "%+v", expected nothing left for the deleted user`, data)
+	}
+
+	guest, err := service.GetUserData(context.Background(), &dto.GetUserDataRequest{UserId: GuestId})
+	if err != nil {
+		t.Fatalf(`service.GetUserData(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(guest.Games) != 1 || len(guest.LeaderboardScores) != 3 {
+		t.Errorf(`guest = "%+v", expected the guest's data to be kept`, guest)
+	}
+}
@@ -0,0 +1,230 @@
+package game
+
+import (
+	"common"
+	"fmt"
+	"game/dto"
+	"net/http"
+	"quiz"
+	"strings"
+	"unicode/utf8"
+)
+
+const MinPlayers = 2
+
+// ValidateCreateLobbyRequest Validate request for creating a lobby. The upper limit on maxPlayers is configured, so the
+// service checks it
+func ValidateCreateLobbyRequest(request *dto.CreateLobbyRequest) error {
+	if request.QuizId <= 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid quiz id",
+		}
+	}
+
+	if request.MaxPlayers != 0 && request.MaxPlayers < MinPlayers {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("maxPlayers must be at least %d", MinPlayers),
+		}
+	}
+
+	if request.Scoring != nil {
+		return validateScoringRules(request.Scoring)
+	}
+
+	return nil
+}
+
+// validateScoringRules Validate the scoring rules of a game
+func validateScoringRules(rules *dto.ScoringRules) error {
+	if rules.Points <= 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "scoring.points must be positive",
+		}
+	}
+
+	if rules.TimeDecay < 0 || rules.TimeDecay > 1 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "scoring.timeDecay must be between 0 and 1",
+		}
+	}
+
+	if rules.StreakBonus < 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "scoring.streakBonus must not be negative",
+		}
+	}
+
+	if rules.MaxStreakMultiplier != 0 && rules.MaxStreakMultiplier < 1 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "scoring.maxStreakMultiplier must be 0 or at least 1",
+		}
+	}
+
+	if rules.WrongPenalty < 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "scoring.wrongPenalty must not be negative",
+		}
+	}
+
+	return nil
+}
+
+// ValidateGetLobbyRequest Validate request for retrieving a lobby
+func ValidateGetLobbyRequest(request *dto.GetLobbyRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateJoinLobbyRequest Validate request for joining a lobby
+func ValidateJoinLobbyRequest(request *dto.JoinLobbyRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateLeaveLobbyRequest Validate request for leaving a lobby
+func ValidateLeaveLobbyRequest(request *dto.LeaveLobbyRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateSetReadyRequest Validate request for changing a player's ready state
+func ValidateSetReadyRequest(request *dto.SetReadyRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateConnectRequest Validate request for connecting to a lobby
+func ValidateConnectRequest(request *dto.ConnectRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateStartGameRequest Validate request for starting the game of a lobby
+func ValidateStartGameRequest(request *dto.StartGameRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateSubmitAnswerRequest Validate request for answering the open question. Whether the answer suits the question
+// type is left to grading
+func ValidateSubmitAnswerRequest(request *dto.SubmitAnswerRequest) error {
+	if err := validateJoinCode(request.Code); err != nil {
+		return err
+	}
+
+	if request.QuestionId <= 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid question id",
+		}
+	}
+
+	answer := request.Answer
+	if len(answer.AnswerOptionIds) == 0 && answer.Number == nil && answer.Text == nil {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "answerOptionIds, number, or text is required",
+		}
+	}
+
+	if len(answer.AnswerOptionIds) > quiz.MaxAnswerOptions {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("at most %d answer options can be selected", quiz.MaxAnswerOptions),
+		}
+	}
+
+	if answer.Text != nil && utf8.RuneCountInString(*answer.Text) > quiz.MaxAnswerOptionLength {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("text must be at most %d characters", quiz.MaxAnswerOptionLength),
+		}
+	}
+
+	return nil
+}
+
+// ValidateNextQuestionRequest Validate request for closing the open question
+func ValidateNextQuestionRequest(request *dto.NextQuestionRequest) error {
+	return validateJoinCode(request.Code)
+}
+
+// ValidateGetGameResultsRequest Validate request for retrieving the results of a finished game
+func ValidateGetGameResultsRequest(request *dto.GetGameResultsRequest) error {
+	if request.GameId <= 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid game id",
+		}
+	}
+
+	return nil
+}
+
+// ValidateGetLeaderboardRequest Validate request for retrieving a page of a leaderboard
+func ValidateGetLeaderboardRequest(request *dto.GetLeaderboardRequest) error {
+	if request.Board == BoardQuiz && request.QuizId <= 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid quiz id",
+		}
+	}
+
+	if request.Limit != nil && (*request.Limit <= 0 || *request.Limit > MaxLeaderboardLimit) {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("limit must be between 1 and %d", MaxLeaderboardLimit),
+		}
+	}
+
+	if request.Offset != nil && *request.Offset < 0 {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "offset must not be negative",
+		}
+	}
+
+	if request.Offset != nil && request.AroundMe {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "offset cannot be combined with around",
+		}
+	}
+
+	return nil
+}
+
+// ValidateGetRatingRequest Validate request for retrieving a rating
+func ValidateGetRatingRequest(request *dto.GetRatingRequest) error {
+	return validateCategory(request.Category)
+}
+
+// ValidateEnqueueRequest Validate request for joining the matchmaking queue
+func ValidateEnqueueRequest(request *dto.EnqueueRequest) error {
+	return validateCategory(request.Category)
+}
+
+// validateCategory Validate a question bank category, which may be empty
+func validateCategory(category string) error {
+	if utf8.RuneCountInString(strings.TrimSpace(category)) > quiz.MaxCategoryLength {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("category must be at most %d characters", quiz.MaxCategoryLength),
+		}
+	}
+
+	return nil
+}
+
+// validateJoinCode Validate that a normalized join code could have been issued
+func validateJoinCode(code string) error {
+	if len(code) != JoinCodeLength || strings.Trim(code, JoinCodeAlphabet) != "" {
+		return &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid join code",
+		}
+	}
+
+	return nil
+}
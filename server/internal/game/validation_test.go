@@ -0,0 +1,149 @@
+package game
+
+import (
+	"game/dto"
+	"net/http"
+	"quiz"
+	quizdto "quiz/dto"
+	"strings"
+	"testing"
+)
+
+func TestValidateCreateLobbyRequest_Success(t *testing.T) {
+	requests := []dto.CreateLobbyRequest{
+		{HostId: HostId, QuizId: QuizId},
+		{HostId: HostId, QuizId: QuizId, MaxPlayers: MinPlayers},
+		{
+			HostId: HostId,
+			QuizId: QuizId,
+			Scoring: &dto.ScoringRules{
+				Points:              1000,
+				TimeDecay:           1,
+				StreakBonus:         0.1,
+				MaxStreakMultiplier: 1.5,
+				WrongPenalty:        100,
+			},
+		},
+	}
+
+	for _, request := range requests {
+		if err := ValidateCreateLobbyRequest(&request); err != nil {
+			t.Errorf(`ValidateCreateLobbyRequest(%+v) = "%v", expected "<nil>"`, request, err)
+		}
+	}
+}
+
+func TestValidateCreateLobbyRequest_Invalid(t *testing.T) {
+	tests := map[string]dto.CreateLobbyRequest{
+		"missing quiz id":  {HostId: HostId},
+		"too few players":  {HostId: HostId, QuizId: QuizId, MaxPlayers: 1},
+		"negative players": {HostId: HostId, QuizId: QuizId, MaxPlayers: -1},
+		"no points":        {HostId: HostId, QuizId: QuizId, Scoring: &dto.ScoringRules{}},
+		"time decay over 1": {
+			HostId:  HostId,
+			QuizId:  QuizId,
+			Scoring: &dto.ScoringRules{Points: 1, TimeDecay: 1.5},
+		},
+		"negative streak bonus": {
+			HostId:  HostId,
+			QuizId:  QuizId,
+			Scoring: &dto.ScoringRules{Points: 1, StreakBonus: -1},
+		},
+		"streak multiplier under 1": {
+			HostId:  HostId,
+			QuizId:  QuizId,
+			Scoring: &dto.ScoringRules{Points: 1, StreakBonus: 1, MaxStreakMultiplier: 0.5},
+		},
+		"negative penalty": {
+			HostId:  HostId,
+			QuizId:  QuizId,
+			Scoring: &dto.ScoringRules{Points: 1, WrongPenalty: -1},
+		},
+	}
+
+	for name, request := range tests {
+		err := ValidateCreateLobbyRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateCreateLobbyRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateJoinLobbyRequest_InvalidCode(t *testing.T) {
+	for _, code := range []string{"", "ABC23", "ABC2345", "ABC0OI", "abc234"} {
+		err := ValidateJoinLobbyRequest(&dto.JoinLobbyRequest{UserId: HostId, Code: code})
+		if err == nil {
+			t.Errorf(`ValidateJoinLobbyRequest("%s") = "<nil>", expected non-nil`, code)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateSubmitAnswerRequest_Invalid(t *testing.T) {
+	tooLong := strings.Repeat("é", quiz.MaxAnswerOptionLength+1)
+	tests := map[string]dto.SubmitAnswerRequest{
+		"invalid code": {
+			UserId:     HostId,
+			Code:       "ABC",
+			QuestionId: 1,
+			Answer:     quizdto.Answer{AnswerOptionIds: []int64{11}},
+		},
+		"missing question id": {UserId: HostId, Code: "ABCDEF", Answer: quizdto.Answer{AnswerOptionIds: []int64{11}}},
+		"missing answer":      {UserId: HostId, Code: "ABCDEF", QuestionId: 1},
+		"too many options": {
+			UserId:     HostId,
+			Code:       "ABCDEF",
+			QuestionId: 1,
+			Answer:     quizdto.Answer{AnswerOptionIds: make([]int64, quiz.MaxAnswerOptions+1)},
+		},
+		"text too long": {UserId: HostId, Code: "ABCDEF", QuestionId: 1, Answer: quizdto.Answer{Text: &tooLong}},
+	}
+
+	for name, request := range tests {
+		err := ValidateSubmitAnswerRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateSubmitAnswerRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateGetLeaderboardRequest_Invalid(t *testing.T) {
+	zero, negative, tooMany := 0, -1, MaxLeaderboardLimit+1
+	tests := map[string]dto.GetLeaderboardRequest{
+		"missing quiz id":      {Board: BoardQuiz},
+		"zero limit":           {Board: BoardAllTime, Limit: &zero},
+		"limit too large":      {Board: BoardAllTime, Limit: &tooMany},
+		"negative offset":      {Board: BoardWeekly, Offset: &negative},
+		"offset and around me": {Board: BoardWeekly, Offset: &zero, AroundMe: true},
+	}
+
+	for name, request := range tests {
+		err := ValidateGetLeaderboardRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateGetLeaderboardRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateEnqueueRequest(t *testing.T) {
+	// Categories are limited in characters rather than bytes
+	for _, category := range []string{"", strings.Repeat("é", quiz.MaxCategoryLength)} {
+		if err := ValidateEnqueueRequest(&dto.EnqueueRequest{UserId: HostId, Category: category}); err != nil {
+			t.Errorf(`ValidateEnqueueRequest("%s") = "%v", expected "<nil>"`, category, err)
+		}
+	}
+
+	category := strings.Repeat("é", quiz.MaxCategoryLength+1)
+	err := ValidateEnqueueRequest(&dto.EnqueueRequest{UserId: HostId, Category: category})
+	if err == nil {
+		t.Fatalf(`ValidateEnqueueRequest("%s") = "<nil>", expected non-nil`, category)
+	}
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
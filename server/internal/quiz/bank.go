@@ -0,0 +1,447 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"quiz/db/generated"
+	"quiz/dto"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// CreateBankQuestion Add a question to the question bank. The category, language and tags are stored lowercase so
+// filters match regardless of case
+func (service *ServiceImpl) CreateBankQuestion(
+	context context.Context,
+	request *dto.CreateBankQuestionRequest,
+) (*dto.CreateBankQuestionResponse, error) {
+	questionRequest := bankQuestionRequest(request)
+	typeName := questionRequest.Type
+	if typeName == "" {
+		typeName = SingleChoice
+	}
+	questionType, ok := GetQuestionType(typeName)
+	if !ok {
+		return nil, fmt.Errorf("unknown question type %q", typeName)
+	}
+	tags := normalizeTags(request.Tags)
+
+	var response *dto.BankQuestion
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			question, err := queries.CreateBankQuestion(
+				context, db.CreateBankQuestionParams{
+					OwnerID:          int32(request.OwnerId),
+					Category:         normalizeLabel(request.Category),
+					Difficulty:       request.Difficulty,
+					Language:         normalizeLabel(request.Language),
+					QuestionType:     typeName,
+					Prompt:           request.Prompt,
+					NumericAnswer:    nullFloat64(request.NumericAnswer),
+					NumericTolerance: nullFloat64(request.NumericTolerance),
+					Shared:           request.Shared,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			optionRequests := questionType.AnswerOptions(&questionRequest)
+			options := make([]db.BankAnswerOption, len(optionRequests))
+			for i, optionRequest := range optionRequests {
+				options[i], err = queries.CreateBankAnswerOption(
+					context, db.CreateBankAnswerOptionParams{
+						BankQuestionID: question.ID,
+						Position:       int32(i + 1),
+						Text:           optionRequest.Text,
+						IsCorrect:      optionRequest.IsCorrect,
+					},
+				)
+				if err != nil {
+					return fmt.Errorf("failed to create answer option: %w", err)
+				}
+			}
+
+			for _, tag := range tags {
+				err := queries.CreateBankQuestionTag(
+					context, db.CreateBankQuestionTagParams{
+						BankQuestionID: question.ID,
+						Tag:            tag,
+					},
+				)
+				if err != nil {
+					return fmt.Errorf("failed to create tag: %w", err)
+				}
+			}
+
+			response = toBankQuestionDTO(question, options, tags)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bank question: %w", err)
+	}
+
+	return response, nil
+}
+
+// GetBankQuestion Retrieve a question bank question added by the requesting user
+func (service *ServiceImpl) GetBankQuestion(
+	context context.Context,
+	request *dto.GetBankQuestionRequest,
+) (*dto.GetBankQuestionResponse, error) {
+	var response *dto.BankQuestion
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			question, err := getOwnedBankQuestion(context, queries, request.QuestionId, request.OwnerId)
+			if err != nil {
+				return err
+			}
+
+			options, err := queries.GetBankAnswerOptionsByQuestion(context, question.ID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve answer options: %w", err)
+			}
+
+			tags, err := queries.GetBankQuestionTags(context, question.ID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve tags: %w", err)
+			}
+
+			response = toBankQuestionDTO(question, options, tags)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// DeleteBankQuestion Delete a question bank question added by the requesting user. Quizzes already generated from it
+// keep their copy
+func (service *ServiceImpl) DeleteBankQuestion(
+	context context.Context,
+	request *dto.DeleteBankQuestionRequest,
+) (*dto.DeleteBankQuestionResponse, error) {
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			question, err := getOwnedBankQuestion(context, queries, request.QuestionId, request.OwnerId)
+			if err != nil {
+				return err
+			}
+
+			// Dependent rows go first since SQLite does not enforce the cascading foreign keys
+			if err := queries.DeleteBankQuestionViewsByQuestion(context, question.ID); err != nil {
+				return fmt.Errorf("failed to delete views: %w", err)
+			}
+			if err := queries.DeleteBankQuestionTagsByQuestion(context, question.ID); err != nil {
+				return fmt.Errorf("failed to delete tags: %w", err)
+			}
+			if err := queries.DeleteBankAnswerOptionsByQuestion(context, question.ID); err != nil {
+				return fmt.Errorf("failed to delete answer options: %w", err)
+			}
+			return queries.DeleteBankQuestion(context, question.ID)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete bank question: %w", err)
+	}
+
+	return &dto.DeleteBankQuestionResponse{}, nil
+}
+
+// GenerateQuiz Create a quiz owned by the requesting user from questions drawn at random from their own and the
+// shared questions of the question bank. Questions any of the players saw within the recent question window are left
+// out, and the drawn questions are recorded as seen by every player. Without player ids, the requesting user is the
+// only player
+func (service *ServiceImpl) GenerateQuiz(
+	context context.Context,
+	request *dto.GenerateQuizRequest,
+) (*dto.GenerateQuizResponse, error) {
+	playerIds := request.PlayerIds
+	if len(playerIds) == 0 {
+		playerIds = []int{request.OwnerId}
+	}
+
+	title := request.Title
+	if title == "" {
+		title = generatedQuizTitle(request)
+	}
+
+	var response *dto.Quiz
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			candidateIds, err := findBankQuestionIds(context, queries, request, playerIds, service.recentQuestionWindow())
+			if err != nil {
+				return err
+			}
+
+			if len(candidateIds) < request.Count {
+				return &common.HTTPError{
+					StatusCode: http.StatusConflict,
+					Message: fmt.Sprintf(
+						"only %d questions match the filters and were not seen recently, %d requested",
+						len(candidateIds),
+						request.Count,
+					),
+				}
+			}
+
+			rand.Shuffle(
+				len(candidateIds), func(i, j int) {
+					candidateIds[i], candidateIds[j] = candidateIds[j], candidateIds[i]
+				},
+			)
+			drawnIds := candidateIds[:request.Count]
+
+			questionRequests := make([]dto.QuestionRequest, len(drawnIds))
+			for i, id := range drawnIds {
+				questionRequests[i], err = getBankQuestionRequest(context, queries, id)
+				if err != nil {
+					return err
+				}
+			}
+
+			quiz, err := queries.CreateQuiz(
+				context, db.CreateQuizParams{
+					OwnerID:     int32(request.OwnerId),
+					Title:       title,
+					Description: request.Description,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			questions, err := createQuestions(context, queries, quiz.ID, questionRequests)
+			if err != nil {
+				return err
+			}
+
+			for _, playerId := range playerIds {
+				for _, id := range drawnIds {
+					err := queries.RecordBankQuestionView(
+						context, db.RecordBankQuestionViewParams{
+							UserID:         int32(playerId),
+							BankQuestionID: id,
+						},
+					)
+					if err != nil {
+						return fmt.Errorf("failed to record question view: %w", err)
+					}
+				}
+			}
+
+			response = toQuizDTO(quiz, questions)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate quiz: %w", err)
+	}
+
+	return response, nil
+}
+
+// recentQuestionWindow Get the configured recent question window, or the default if none is set
+func (service *ServiceImpl) recentQuestionWindow() time.Duration {
+	if service.RecentQuestionWindow <= 0 {
+		return common.DefaultRecentQuestionWindow
+	}
+	return service.RecentQuestionWindow
+}
+
+// findBankQuestionIds Get the ids of the owner's and the shared question bank questions matching the filters and
+// every tag, leaving out those any of the players saw within the window
+func findBankQuestionIds(
+	context context.Context,
+	queries db.Querier,
+	request *dto.GenerateQuizRequest,
+	playerIds []int,
+	window time.Duration,
+) ([]int64, error) {
+	ids, err := queries.GetBankQuestionIds(
+		context, db.GetBankQuestionIdsParams{
+			Category:   nullString(normalizeLabel(request.Category)),
+			Difficulty: nullString(request.Difficulty),
+			Language:   nullString(normalizeLabel(request.Language)),
+			OwnerID:    int32(request.OwnerId),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve bank questions: %w", err)
+	}
+
+	for _, tag := range normalizeTags(request.Tags) {
+		tagged, err := queries.GetBankQuestionIdsByTag(context, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve tagged bank questions: %w", err)
+		}
+		ids = slices.DeleteFunc(
+			ids, func(id int64) bool {
+				return !slices.Contains(tagged, id)
+			},
+		)
+	}
+
+	for _, playerId := range playerIds {
+		if len(ids) == 0 {
+			break
+		}
+
+		seen, err := queries.GetRecentBankQuestionIds(
+			context, db.GetRecentBankQuestionIdsParams{
+				UserID:        int32(playerId),
+				WindowSeconds: int64(window / time.Second),
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve recently seen questions: %w", err)
+		}
+		ids = slices.DeleteFunc(
+			ids, func(id int64) bool {
+				return slices.Contains(seen, id)
+			},
+		)
+	}
+
+	return ids, nil
+}
+
+// getOwnedBankQuestion Get the question bank question if it belongs to the owner, otherwise questionNotFoundError
+func getOwnedBankQuestion(
+	context context.Context,
+	queries db.Querier,
+	questionId int64,
+	ownerId int,
+) (db.BankQuestion, error) {
+	question, err := queries.GetBankQuestion(context, questionId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && question.OwnerID != int32(ownerId)) {
+		return db.BankQuestion{}, questionNotFoundError
+	} else if err != nil {
+		return db.BankQuestion{}, fmt.Errorf("failed to retrieve bank question: %w", err)
+	}
+	return question, nil
+}
+
+// getBankQuestionRequest Get a question bank question as a request for adding it to a quiz
+func getBankQuestionRequest(context context.Context, queries db.Querier, id int64) (dto.QuestionRequest, error) {
+	question, err := queries.GetBankQuestion(context, id)
+	if err != nil {
+		return dto.QuestionRequest{}, fmt.Errorf("failed to retrieve bank question: %w", err)
+	}
+
+	options, err := queries.GetBankAnswerOptionsByQuestion(context, id)
+	if err != nil {
+		return dto.QuestionRequest{}, fmt.Errorf("failed to retrieve answer options: %w", err)
+	}
+
+	request := dto.QuestionRequest{
+		Type:             question.QuestionType,
+		Prompt:           question.Prompt,
+		AnswerOptions:    make([]dto.AnswerOptionRequest, len(options)),
+		NumericAnswer:    nullableFloat64(question.NumericAnswer),
+		NumericTolerance: nullableFloat64(question.NumericTolerance),
+	}
+	for i, option := range options {
+		request.AnswerOptions[i] = dto.AnswerOptionRequest{Text: option.Text, IsCorrect: option.IsCorrect}
+	}
+	return request, nil
+}
+
+// bankQuestionRequest Get the question of a question bank request as it would be authored in a quiz
+func bankQuestionRequest(request *dto.CreateBankQuestionRequest) dto.QuestionRequest {
+	return dto.QuestionRequest{
+		Type:             request.Type,
+		Prompt:           request.Prompt,
+		AnswerOptions:    request.AnswerOptions,
+		NumericAnswer:    request.NumericAnswer,
+		NumericTolerance: request.NumericTolerance,
+	}
+}
+
+// generatedQuizTitle Describe the filters of a generated quiz, e.g. "10 medium science questions"
+func generatedQuizTitle(request *dto.GenerateQuizRequest) string {
+	words := []string{fmt.Sprint(request.Count)}
+	if request.Difficulty != "" {
+		words = append(words, request.Difficulty)
+	}
+	if category := normalizeLabel(request.Category); category != "" {
+		words = append(words, category)
+	}
+	words = append(words, "questions")
+
+	title := strings.Join(words, " ")
+	if len([]rune(title)) > MaxTitleLength {
+		return string([]rune(title)[:MaxTitleLength])
+	}
+	return title
+}
+
+// normalizeLabel Trim and lowercase a category, language or tag
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// normalizeTags Normalize the tags, dropping duplicates, in alphabetical order
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		normalized = append(normalized, normalizeLabel(tag))
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// toBankQuestionDTO Convert a question bank row, its answer options and tags to the response DTO
+func toBankQuestionDTO(question db.BankQuestion, options []db.BankAnswerOption, tags []string) *dto.BankQuestion {
+	result := &dto.BankQuestion{
+		QuestionId:       question.ID,
+		OwnerId:          int(question.OwnerID),
+		Category:         question.Category,
+		Difficulty:       question.Difficulty,
+		Language:         question.Language,
+		Tags:             tags,
+		Shared:           question.Shared,
+		Type:             question.QuestionType,
+		Prompt:           question.Prompt,
+		AnswerOptions:    make([]dto.AnswerOption, len(options)),
+		NumericAnswer:    nullableFloat64(question.NumericAnswer),
+		NumericTolerance: nullableFloat64(question.NumericTolerance),
+		CreatedAt:        question.CreatedAt,
+	}
+	if result.Tags == nil {
+		result.Tags = []string{}
+	}
+	for i, option := range options {
+		result.AnswerOptions[i] = dto.AnswerOption{
+			AnswerOptionId: option.ID,
+			Position:       int(option.Position),
+			Text:           option.Text,
+			IsCorrect:      option.IsCorrect,
+		}
+	}
+	return result
+}
+
+// nullString Convert an optional string to a nullable column value, treating the empty string as null
+func nullString(value string) sql.NullString {
+	if value == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: value, Valid: true}
+}
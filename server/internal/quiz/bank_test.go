@@ -0,0 +1,280 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"quiz/dto"
+	"slices"
+	"testing"
+	"time"
+)
+
+// bankQuestionCount Number of questions added by createTestBankQuestion, keeping their prompts unique
+var bankQuestionCount int
+
+// createTestBankQuestion Add a single choice question with the labels to the question bank
+func createTestBankQuestion(
+	t *testing.T,
+	service *ServiceImpl,
+	category string,
+	difficulty string,
+	tags ...string,
+) *dto.BankQuestion {
+	bankQuestionCount++
+	question, err := service.CreateBankQuestion(
+		context.Background(), &dto.CreateBankQuestionRequest{
+			OwnerId:    OwnerId,
+			Category:   category,
+			Difficulty: difficulty,
+			Language:   "en",
+			Tags:       tags,
+			Prompt:     fmt.Sprintf("%s question %d", category, bankQuestionCount),
+			AnswerOptions: []dto.AnswerOptionRequest{
+				{Text: "Right", IsCorrect: true},
+				{Text: "Wrong"},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateBankQuestion(...) error = "%v", expected "<nil>"`, err)
+	}
+	return question
+}
+
+// quizPrompts Get the prompts of the quiz questions
+func quizPrompts(quiz *dto.Quiz) []string {
+	prompts := make([]string, len(quiz.Questions))
+	for i, question := range quiz.Questions {
+		prompts[i] = question.Prompt
+	}
+	return prompts
+}
+
+func TestService_CreateBankQuestion_NormalizesLabels(t *testing.T) {
+	service, _ := newTestService()
+
+	created := createTestBankQuestion(t, service, " Science ", DifficultyMedium, "Physics", "space", "physics")
+	if created.Category != "science" || created.Type != SingleChoice {
+		t.Errorf(`created = "%+v", expected a single choice question in category "science"`, created)
+	}
+	if !slices.Equal(created.Tags, []string{"physics", "space"}) {
+		t.Errorf(`created.Tags = "%v", expected "[physics space]"`, created.Tags)
+	}
+
+	stored, err := service.GetBankQuestion(
+		context.Background(),
+		&dto.GetBankQuestionRequest{OwnerId: OwnerId, QuestionId: created.QuestionId},
+	)
+	if err != nil {
+		t.Fatalf(`service.GetBankQuestion(...) error = "%v", expected "<nil>"`, err)
+	}
+	if !slices.Equal(stored.Tags, created.Tags) || len(stored.AnswerOptions) != 2 || !stored.AnswerOptions[0].IsCorrect {
+		t.Errorf(`stored = "%+v", expected "%+v"`, stored, created)
+	}
+}
+
+func TestService_GetBankQuestion_OtherOwner(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestBankQuestion(t, service, "science", DifficultyEasy)
+
+	_, err := service.GetBankQuestion(
+		context.Background(),
+		&dto.GetBankQuestionRequest{OwnerId: OwnerId + 1, QuestionId: created.QuestionId},
+	)
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_DeleteBankQuestion_Success(t *testing.T) {
+	service, queries := newTestService()
+	created := createTestBankQuestion(t, service, "science", DifficultyEasy, "physics")
+	if _, err := service.GenerateQuiz(context.Background(), &dto.GenerateQuizRequest{OwnerId: OwnerId, Count: 1}); err != nil {
+		t.Fatalf(`service.GenerateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err := service.DeleteBankQuestion(
+		context.Background(),
+		&dto.DeleteBankQuestionRequest{OwnerId: OwnerId, QuestionId: created.QuestionId},
+	)
+	if err != nil {
+		t.Fatalf(`service.DeleteBankQuestion(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err = service.GetBankQuestion(
+		context.Background(),
+		&dto.GetBankQuestionRequest{OwnerId: OwnerId, QuestionId: created.QuestionId},
+	)
+	assertHTTPError(t, err, http.StatusNotFound)
+
+	tagged, _ := queries.GetBankQuestionIdsByTag(context.Background(), "physics")
+	options, _ := queries.GetBankAnswerOptionsByQuestion(context.Background(), created.QuestionId)
+	if len(tagged) != 0 || len(options) != 0 {
+		t.Errorf(`tagged, options = "%v", "%v", expected none`, tagged, options)
+	}
+}
+
+func TestService_DeleteBankQuestion_OtherOwner(t *testing.T) {
+	service, _ := newTestService()
+	created := createTestBankQuestion(t, service, "science", DifficultyEasy)
+
+	_, err := service.DeleteBankQuestion(
+		context.Background(),
+		&dto.DeleteBankQuestionRequest{OwnerId: OwnerId + 1, QuestionId: created.QuestionId},
+	)
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_GenerateQuiz_Filters(t *testing.T) {
+	service, _ := newTestService()
+	wanted := []string{
+		createTestBankQuestion(t, service, "Science", DifficultyMedium, "physics", "space").Prompt,
+		createTestBankQuestion(t, service, "science", DifficultyMedium, "space", "physics", "history").Prompt,
+	}
+	createTestBankQuestion(t, service, "science", DifficultyMedium, "physics")
+	createTestBankQuestion(t, service, "science", DifficultyHard, "physics", "space")
+	createTestBankQuestion(t, service, "history", DifficultyMedium, "physics", "space")
+
+	quiz, err := service.GenerateQuiz(
+		context.Background(), &dto.GenerateQuizRequest{
+			OwnerId:    OwnerId,
+			Count:      2,
+			Category:   "SCIENCE",
+			Difficulty: DifficultyMedium,
+			Language:   "EN",
+			Tags:       []string{"Space", "physics"},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.GenerateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if quiz.OwnerId != OwnerId || quiz.Title != "2 medium science questions" {
+		t.Errorf(`quiz = "%+v", expected a quiz titled "2 medium science questions" owned by "%d"`, quiz, OwnerId)
+	}
+	prompts := quizPrompts(quiz)
+	slices.Sort(prompts)
+	slices.Sort(wanted)
+	if !slices.Equal(prompts, wanted) {
+		t.Errorf(`prompts = "%v", expected "%v"`, prompts, wanted)
+	}
+	for i, question := range quiz.Questions {
+		if question.Position != i+1 || len(question.AnswerOptions) != 2 || !question.AnswerOptions[0].IsCorrect {
+			t.Errorf(`quiz.Questions[%d] = "%+v", expected a copy of the bank question at position "%d"`, i, question, i+1)
+		}
+	}
+}
+
+func TestService_GenerateQuiz_NoRecentRepeats(t *testing.T) {
+	service, queries := newTestService()
+	now := time.Now()
+	queries.Now = func() time.Time {
+		return now
+	}
+	service.RecentQuestionWindow = time.Hour
+	for range 4 {
+		createTestBankQuestion(t, service, "science", DifficultyEasy)
+	}
+
+	first, err := service.GenerateQuiz(
+		context.Background(),
+		&dto.GenerateQuizRequest{OwnerId: OwnerId, Count: 2, PlayerIds: []int{2, 3}},
+	)
+	if err != nil {
+		t.Fatalf(`service.GenerateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	second, err := service.GenerateQuiz(
+		context.Background(),
+		&dto.GenerateQuizRequest{OwnerId: OwnerId, Count: 2, PlayerIds: []int{3, 4}},
+	)
+	if err != nil {
+		t.Fatalf(`service.GenerateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	for _, prompt := range quizPrompts(second) {
+		if slices.Contains(quizPrompts(first), prompt) {
+			t.Errorf(`second quiz repeats "%s", expected only questions player "3" has not seen`, prompt)
+		}
+	}
+
+	_, err = service.GenerateQuiz(
+		context.Background(),
+		&dto.GenerateQuizRequest{OwnerId: OwnerId, Count: 1, PlayerIds: []int{3}},
+	)
+	assertHTTPError(t, err, http.StatusConflict)
+
+	now = now.Add(time.Hour + time.Second)
+	if _, err := service.GenerateQuiz(
+		context.Background(),
+		&dto.GenerateQuizRequest{OwnerId: OwnerId, Count: 4, PlayerIds: []int{3}},
+	); err != nil {
+		t.Errorf(`service.GenerateQuiz(...) after the window error = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestService_GenerateQuiz_TooFewQuestions(t *testing.T) {
+	service, queries := newTestService()
+	createTestBankQuestion(t, service, "science", DifficultyEasy)
+
+	_, err := service.GenerateQuiz(
+		context.Background(),
+		&dto.GenerateQuizRequest{OwnerId: OwnerId, Count: 2, Category: "science"},
+	)
+	assertHTTPError(t, err, http.StatusConflict)
+
+	if count, _ := queries.CountQuizzesByOwner(context.Background(), OwnerId); count != 0 {
+		t.Errorf(`queries.CountQuizzesByOwner(...) = "%d", expected "0"`, count)
+	}
+}
+
+func TestService_GenerateQuiz_OtherOwnersPrivateQuestions(t *testing.T) {
+	service, _ := newTestService()
+	createTestBankQuestion(t, service, "science", DifficultyEasy)
+	shared, err := service.CreateBankQuestion(
+		context.Background(), &dto.CreateBankQuestionRequest{
+			OwnerId:    OwnerId,
+			Category:   "science",
+			Difficulty: DifficultyEasy,
+			Language:   "en",
+			Shared:     true,
+			Prompt:     "Shared science question",
+			AnswerOptions: []dto.AnswerOptionRequest{
+				{Text: "Right", IsCorrect: true},
+				{Text: "Wrong"},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateBankQuestion(...) error = "%v", expected "<nil>"`, err)
+	}
+	if !shared.Shared {
+		t.Errorf(`shared.Shared = "%v", expected "true"`, shared.Shared)
+	}
+
+	_, err = service.GenerateQuiz(context.Background(), &dto.GenerateQuizRequest{OwnerId: OwnerId + 1, Count: 2})
+	assertHTTPError(t, err, http.StatusConflict)
+
+	quiz, err := service.GenerateQuiz(context.Background(), &dto.GenerateQuizRequest{OwnerId: OwnerId + 1, Count: 1})
+	if err != nil {
+		t.Fatalf(`service.GenerateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	if prompts := quizPrompts(quiz); !slices.Equal(prompts, []string{shared.Prompt}) {
+		t.Errorf(`quizPrompts(quiz) = "%v", expected only the shared question`, prompts)
+	}
+}
+
+func TestGeneratedQuizTitle(t *testing.T) {
+	tests := []struct {
+		request  dto.GenerateQuizRequest
+		expected string
+	}{
+		{request: dto.GenerateQuizRequest{Count: 10}, expected: "10 questions"},
+		{request: dto.GenerateQuizRequest{Count: 5, Difficulty: DifficultyHard}, expected: "5 hard questions"},
+		{request: dto.GenerateQuizRequest{Count: 1, Category: " Film "}, expected: "1 film questions"},
+	}
+
+	for _, test := range tests {
+		if actual := generatedQuizTitle(&test.request); actual != test.expected {
+			t.Errorf(`generatedQuizTitle(%+v) = "%s", expected "%s"`, test.request, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,629 @@
+// Package memory contains a thread-safe in-memory implementation of db.Querier for tests and local runs
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"quiz/db/generated"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUniqueViolation Returned when a write would violate a unique constraint, mirroring Postgres
+var ErrUniqueViolation = errors.New("duplicate key value violates unique constraint")
+
+// Querier In-memory db.Querier mirroring the quizzes, questions and answer_options tables, and the question bank
+// tables
+type Querier struct {
+	*tables
+	// inTx Whether this is the handle RunInTx passes to its function, whose calls run inside the transaction
+	inTx bool
+}
+
+// tables State shared by a querier and the handles of its transactions
+type tables struct {
+	// txMutex Held by a running transaction, and shared by every call made outside of it, so that no other caller
+	// sees or writes the store until the transaction commits or rolls back
+	txMutex        sync.RWMutex
+	mutex          sync.RWMutex
+	quizzes        []db.Quiz
+	quizId         int64
+	questions      []db.Question
+	questionId     int64
+	options        []db.AnswerOption
+	optionId       int64
+	bankQuestions  []db.BankQuestion
+	bankQuestionId int64
+	bankOptions    []db.BankAnswerOption
+	bankOptionId   int64
+	bankTags       []db.BankQuestionTag
+	bankViews      []db.BankQuestionView
+
+	// Now Clock used for created_at, updated_at and seen_at
+	Now func() time.Time
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New Create an empty in-memory querier
+func New() *Querier {
+	return &Querier{
+		tables: &tables{
+			Now: time.Now,
+		},
+	}
+}
+
+// CountQuizzesByOwner CountQuizzesByOwner() implementation from db.Querier interface
+func (q *Querier) CountQuizzesByOwner(ctx context.Context, ownerID int32) (int64, error) {
+	defer q.rlock()()
+
+	var count int64
+	for _, quiz := range q.quizzes {
+		if quiz.OwnerID == ownerID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CreateQuiz CreateQuiz() implementation from db.Querier interface
+func (q *Querier) CreateQuiz(ctx context.Context, arg db.CreateQuizParams) (db.Quiz, error) {
+	defer q.lock()()
+
+	q.quizId++
+	now := q.Now()
+	quiz := db.Quiz{
+		ID:          q.quizId,
+		OwnerID:     arg.OwnerID,
+		Title:       arg.Title,
+		Description: arg.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	q.quizzes = append(q.quizzes, quiz)
+
+	return quiz, nil
+}
+
+// GetQuiz GetQuiz() implementation from db.Querier interface
+func (q *Querier) GetQuiz(ctx context.Context, id int64) (db.Quiz, error) {
+	defer q.rlock()()
+
+	if i := q.findQuiz(id); i >= 0 {
+		return q.quizzes[i], nil
+	}
+	return db.Quiz{}, sql.ErrNoRows
+}
+
+// GetQuizzesByOwner GetQuizzesByOwner() implementation from db.Querier interface
+func (q *Querier) GetQuizzesByOwner(ctx context.Context, arg db.GetQuizzesByOwnerParams) ([]db.Quiz, error) {
+	defer q.rlock()()
+
+	if arg.Limit < 0 || arg.Offset < 0 {
+		return nil, errors.New("LIMIT and OFFSET must not be negative")
+	}
+
+	var quizzes []db.Quiz
+	for _, quiz := range q.quizzes {
+		if quiz.OwnerID == arg.OwnerID {
+			quizzes = append(quizzes, quiz)
+		}
+	}
+
+	start := min(int(arg.Offset), len(quizzes))
+	end := min(start+int(arg.Limit), len(quizzes))
+
+	return quizzes[start:end], nil
+}
+
+// UpdateQuiz UpdateQuiz() implementation from db.Querier interface
+func (q *Querier) UpdateQuiz(ctx context.Context, arg db.UpdateQuizParams) (db.Quiz, error) {
+	defer q.lock()()
+
+	i := q.findQuiz(arg.ID)
+	if i < 0 {
+		return db.Quiz{}, sql.ErrNoRows
+	}
+
+	q.quizzes[i].Title = arg.Title
+	q.quizzes[i].Description = arg.Description
+	q.quizzes[i].UpdatedAt = q.Now()
+
+	return q.quizzes[i], nil
+}
+
+// DeleteQuiz DeleteQuiz() implementation from db.Querier interface
+func (q *Querier) DeleteQuiz(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	if i := q.findQuiz(id); i >= 0 {
+		q.quizzes = append(q.quizzes[:i:i], q.quizzes[i+1:]...)
+	}
+	return nil
+}
+
+// CreateQuestion CreateQuestion() implementation from db.Querier interface
+func (q *Querier) CreateQuestion(ctx context.Context, arg db.CreateQuestionParams) (db.Question, error) {
+	defer q.lock()()
+
+	for _, question := range q.questions {
+		if question.QuizID == arg.QuizID && question.Position == arg.Position {
+			return db.Question{}, ErrUniqueViolation
+		}
+	}
+
+	q.questionId++
+	question := db.Question{
+		ID:               q.questionId,
+		QuizID:           arg.QuizID,
+		Position:         arg.Position,
+		Prompt:           arg.Prompt,
+		QuestionType:     arg.QuestionType,
+		NumericAnswer:    arg.NumericAnswer,
+		NumericTolerance: arg.NumericTolerance,
+	}
+	q.questions = append(q.questions, question)
+
+	return question, nil
+}
+
+// GetQuestionsByQuiz GetQuestionsByQuiz() implementation from db.Querier interface
+func (q *Querier) GetQuestionsByQuiz(ctx context.Context, quizID int64) ([]db.Question, error) {
+	defer q.rlock()()
+
+	var questions []db.Question
+	for _, question := range q.questions {
+		if question.QuizID == quizID {
+			questions = append(questions, question)
+		}
+	}
+	sort.Slice(
+		questions, func(i, j int) bool {
+			return questions[i].Position < questions[j].Position
+		},
+	)
+	return questions, nil
+}
+
+// GetQuizQuestion GetQuizQuestion() implementation from db.Querier interface
+func (q *Querier) GetQuizQuestion(ctx context.Context, arg db.GetQuizQuestionParams) (db.Question, error) {
+	defer q.rlock()()
+
+	for _, question := range q.questions {
+		if question.ID == arg.ID && question.QuizID == arg.QuizID {
+			return question, nil
+		}
+	}
+	return db.Question{}, sql.ErrNoRows
+}
+
+// DeleteQuestionsByQuiz DeleteQuestionsByQuiz() implementation from db.Querier interface
+func (q *Querier) DeleteQuestionsByQuiz(ctx context.Context, quizID int64) error {
+	defer q.lock()()
+
+	questions := q.questions[:0:0]
+	for _, question := range q.questions {
+		if question.QuizID != quizID {
+			questions = append(questions, question)
+		}
+	}
+	q.questions = questions
+	return nil
+}
+
+// CreateAnswerOption CreateAnswerOption() implementation from db.Querier interface
+func (q *Querier) CreateAnswerOption(ctx context.Context, arg db.CreateAnswerOptionParams) (db.AnswerOption, error) {
+	defer q.lock()()
+
+	for _, option := range q.options {
+		if option.QuestionID == arg.QuestionID && option.Position == arg.Position {
+			return db.AnswerOption{}, ErrUniqueViolation
+		}
+	}
+
+	q.optionId++
+	option := db.AnswerOption{
+		ID:         q.optionId,
+		QuestionID: arg.QuestionID,
+		Position:   arg.Position,
+		Text:       arg.Text,
+		IsCorrect:  arg.IsCorrect,
+	}
+	q.options = append(q.options, option)
+
+	return option, nil
+}
+
+// GetAnswerOptionsByQuiz GetAnswerOptionsByQuiz() implementation from db.Querier interface. Options are ordered by
+// question position, then option position
+func (q *Querier) GetAnswerOptionsByQuiz(ctx context.Context, quizID int64) ([]db.AnswerOption, error) {
+	defer q.rlock()()
+
+	positions := q.questionPositions(quizID)
+	var options []db.AnswerOption
+	for _, option := range q.options {
+		if _, ok := positions[option.QuestionID]; ok {
+			options = append(options, option)
+		}
+	}
+	sort.Slice(
+		options, func(i, j int) bool {
+			if positions[options[i].QuestionID] != positions[options[j].QuestionID] {
+				return positions[options[i].QuestionID] < positions[options[j].QuestionID]
+			}
+			return options[i].Position < options[j].Position
+		},
+	)
+	return options, nil
+}
+
+// GetAnswerOptionsByQuestion GetAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *Querier) GetAnswerOptionsByQuestion(ctx context.Context, questionID int64) ([]db.AnswerOption, error) {
+	defer q.rlock()()
+
+	var options []db.AnswerOption
+	for _, option := range q.options {
+		if option.QuestionID == questionID {
+			options = append(options, option)
+		}
+	}
+	sort.Slice(
+		options, func(i, j int) bool {
+			return options[i].Position < options[j].Position
+		},
+	)
+	return options, nil
+}
+
+// DeleteAnswerOptionsByQuiz DeleteAnswerOptionsByQuiz() implementation from db.Querier interface
+func (q *Querier) DeleteAnswerOptionsByQuiz(ctx context.Context, quizID int64) error {
+	defer q.lock()()
+
+	positions := q.questionPositions(quizID)
+	options := q.options[:0:0]
+	for _, option := range q.options {
+		if _, ok := positions[option.QuestionID]; !ok {
+			options = append(options, option)
+		}
+	}
+	q.options = options
+	return nil
+}
+
+// CreateBankQuestion CreateBankQuestion() implementation from db.Querier interface
+func (q *Querier) CreateBankQuestion(ctx context.Context, arg db.CreateBankQuestionParams) (db.BankQuestion, error) {
+	defer q.lock()()
+
+	q.bankQuestionId++
+	question := db.BankQuestion{
+		ID:               q.bankQuestionId,
+		OwnerID:          arg.OwnerID,
+		Category:         arg.Category,
+		Difficulty:       arg.Difficulty,
+		Language:         arg.Language,
+		QuestionType:     arg.QuestionType,
+		Prompt:           arg.Prompt,
+		NumericAnswer:    arg.NumericAnswer,
+		NumericTolerance: arg.NumericTolerance,
+		CreatedAt:        q.Now(),
+		Shared:           arg.Shared,
+	}
+	q.bankQuestions = append(q.bankQuestions, question)
+
+	return question, nil
+}
+
+// GetBankQuestion GetBankQuestion() implementation from db.Querier interface
+func (q *Querier) GetBankQuestion(ctx context.Context, id int64) (db.BankQuestion, error) {
+	defer q.rlock()()
+
+	for _, question := range q.bankQuestions {
+		if question.ID == id {
+			return question, nil
+		}
+	}
+	return db.BankQuestion{}, sql.ErrNoRows
+}
+
+// GetBankQuestionIds GetBankQuestionIds() implementation from db.Querier interface. Null filters match every question,
+// and only the owner's and shared questions match
+func (q *Querier) GetBankQuestionIds(ctx context.Context, arg db.GetBankQuestionIdsParams) ([]int64, error) {
+	defer q.rlock()()
+
+	matches := func(filter sql.NullString, value string) bool {
+		return !filter.Valid || filter.String == value
+	}
+
+	var ids []int64
+	for _, question := range q.bankQuestions {
+		if matches(arg.Category, question.Category) &&
+			matches(arg.Difficulty, question.Difficulty) &&
+			matches(arg.Language, question.Language) &&
+			(question.OwnerID == arg.OwnerID || question.Shared) {
+			ids = append(ids, question.ID)
+		}
+	}
+	return ids, nil
+}
+
+// DeleteBankQuestion DeleteBankQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankQuestion(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	questions := q.bankQuestions[:0:0]
+	for _, question := range q.bankQuestions {
+		if question.ID != id {
+			questions = append(questions, question)
+		}
+	}
+	q.bankQuestions = questions
+	return nil
+}
+
+// CreateBankAnswerOption CreateBankAnswerOption() implementation from db.Querier interface
+func (q *Querier) CreateBankAnswerOption(
+	ctx context.Context,
+	arg db.CreateBankAnswerOptionParams,
+) (db.BankAnswerOption, error) {
+	defer q.lock()()
+
+	for _, option := range q.bankOptions {
+		if option.BankQuestionID == arg.BankQuestionID && option.Position == arg.Position {
+			return db.BankAnswerOption{}, ErrUniqueViolation
+		}
+	}
+
+	q.bankOptionId++
+	option := db.BankAnswerOption{
+		ID:             q.bankOptionId,
+		BankQuestionID: arg.BankQuestionID,
+		Position:       arg.Position,
+		Text:           arg.Text,
+		IsCorrect:      arg.IsCorrect,
+	}
+	q.bankOptions = append(q.bankOptions, option)
+
+	return option, nil
+}
+
+// GetBankAnswerOptionsByQuestion GetBankAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *Querier) GetBankAnswerOptionsByQuestion(
+	ctx context.Context,
+	bankQuestionID int64,
+) ([]db.BankAnswerOption, error) {
+	defer q.rlock()()
+
+	var options []db.BankAnswerOption
+	for _, option := range q.bankOptions {
+		if option.BankQuestionID == bankQuestionID {
+			options = append(options, option)
+		}
+	}
+	sort.Slice(
+		options, func(i, j int) bool {
+			return options[i].Position < options[j].Position
+		},
+	)
+	return options, nil
+}
+
+// DeleteBankAnswerOptionsByQuestion DeleteBankAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankAnswerOptionsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	defer q.lock()()
+
+	options := q.bankOptions[:0:0]
+	for _, option := range q.bankOptions {
+		if option.BankQuestionID != bankQuestionID {
+			options = append(options, option)
+		}
+	}
+	q.bankOptions = options
+	return nil
+}
+
+// CreateBankQuestionTag CreateBankQuestionTag() implementation from db.Querier interface
+func (q *Querier) CreateBankQuestionTag(ctx context.Context, arg db.CreateBankQuestionTagParams) error {
+	defer q.lock()()
+
+	for _, tag := range q.bankTags {
+		if tag.BankQuestionID == arg.BankQuestionID && tag.Tag == arg.Tag {
+			return ErrUniqueViolation
+		}
+	}
+
+	q.bankTags = append(q.bankTags, db.BankQuestionTag{BankQuestionID: arg.BankQuestionID, Tag: arg.Tag})
+	return nil
+}
+
+// GetBankQuestionTags GetBankQuestionTags() implementation from db.Querier interface
+func (q *Querier) GetBankQuestionTags(ctx context.Context, bankQuestionID int64) ([]string, error) {
+	defer q.rlock()()
+
+	var tags []string
+	for _, tag := range q.bankTags {
+		if tag.BankQuestionID == bankQuestionID {
+			tags = append(tags, tag.Tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// GetBankQuestionIdsByTag GetBankQuestionIdsByTag() implementation from db.Querier interface
+func (q *Querier) GetBankQuestionIdsByTag(ctx context.Context, tag string) ([]int64, error) {
+	defer q.rlock()()
+
+	var ids []int64
+	for _, questionTag := range q.bankTags {
+		if questionTag.Tag == tag {
+			ids = append(ids, questionTag.BankQuestionID)
+		}
+	}
+	sort.Slice(
+		ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		},
+	)
+	return ids, nil
+}
+
+// DeleteBankQuestionTagsByQuestion DeleteBankQuestionTagsByQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankQuestionTagsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	defer q.lock()()
+
+	tags := q.bankTags[:0:0]
+	for _, tag := range q.bankTags {
+		if tag.BankQuestionID != bankQuestionID {
+			tags = append(tags, tag)
+		}
+	}
+	q.bankTags = tags
+	return nil
+}
+
+// RecordBankQuestionView RecordBankQuestionView() implementation from db.Querier interface. Seeing a question again
+// moves seen_at forward
+func (q *Querier) RecordBankQuestionView(ctx context.Context, arg db.RecordBankQuestionViewParams) error {
+	defer q.lock()()
+
+	now := q.Now()
+	for i, view := range q.bankViews {
+		if view.UserID == arg.UserID && view.BankQuestionID == arg.BankQuestionID {
+			q.bankViews[i].SeenAt = now
+			return nil
+		}
+	}
+
+	q.bankViews = append(
+		q.bankViews, db.BankQuestionView{
+			UserID:         arg.UserID,
+			BankQuestionID: arg.BankQuestionID,
+			SeenAt:         now,
+		},
+	)
+	return nil
+}
+
+// GetRecentBankQuestionIds GetRecentBankQuestionIds() implementation from db.Querier interface
+func (q *Querier) GetRecentBankQuestionIds(
+	ctx context.Context,
+	arg db.GetRecentBankQuestionIdsParams,
+) ([]int64, error) {
+	defer q.rlock()()
+
+	seenAfter := q.Now().Add(-time.Duration(arg.WindowSeconds) * time.Second)
+	var ids []int64
+	for _, view := range q.bankViews {
+		if view.UserID == arg.UserID && view.SeenAt.After(seenAfter) {
+			ids = append(ids, view.BankQuestionID)
+		}
+	}
+	sort.Slice(
+		ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		},
+	)
+	return ids, nil
+}
+
+// DeleteBankQuestionViewsByQuestion DeleteBankQuestionViewsByQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankQuestionViewsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	defer q.lock()()
+
+	views := q.bankViews[:0:0]
+	for _, view := range q.bankViews {
+		if view.BankQuestionID != bankQuestionID {
+			views = append(views, view)
+		}
+	}
+	q.bankViews = views
+	return nil
+}
+
+// RunInTx Run fn as a transaction: every call made outside of it waits until it ends, and every write made by fn is
+// rolled back if it returns an error. Transactions started inside fn join it
+func (q *Querier) RunInTx(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error {
+	if q.inTx {
+		return fn(q)
+	}
+
+	q.txMutex.Lock()
+	defer q.txMutex.Unlock()
+
+	// No other caller can reach the store until the transaction ends, so restoring this snapshot on rollback undoes
+	// only the transaction's own writes
+	quizzes := append([]db.Quiz(nil), q.quizzes...)
+	questions := append([]db.Question(nil), q.questions...)
+	answerOptions := append([]db.AnswerOption(nil), q.options...)
+	quizId, questionId, optionId := q.quizId, q.questionId, q.optionId
+	bankQuestions := append([]db.BankQuestion(nil), q.bankQuestions...)
+	bankOptions := append([]db.BankAnswerOption(nil), q.bankOptions...)
+	bankTags := append([]db.BankQuestionTag(nil), q.bankTags...)
+	bankViews := append([]db.BankQuestionView(nil), q.bankViews...)
+	bankQuestionId, bankOptionId := q.bankQuestionId, q.bankOptionId
+
+	if err := fn(&Querier{tables: q.tables, inTx: true}); err != nil {
+		q.mutex.Lock()
+		q.quizzes, q.questions, q.options = quizzes, questions, answerOptions
+		q.quizId, q.questionId, q.optionId = quizId, questionId, optionId
+		q.bankQuestions, q.bankOptions, q.bankTags, q.bankViews = bankQuestions, bankOptions, bankTags, bankViews
+		q.bankQuestionId, q.bankOptionId = bankQuestionId, bankOptionId
+		q.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// lock Take the write lock, first waiting for any transaction this call is not part of to end, and get the function
+// releasing it
+func (q *Querier) lock() (unlock func()) {
+	if !q.inTx {
+		q.txMutex.RLock()
+	}
+	q.mutex.Lock()
+	return func() {
+		q.mutex.Unlock()
+		if !q.inTx {
+			q.txMutex.RUnlock()
+		}
+	}
+}
+
+// rlock Take the read lock, first waiting for any transaction this call is not part of to end, and get the function
+// releasing it
+func (q *Querier) rlock() (unlock func()) {
+	if !q.inTx {
+		q.txMutex.RLock()
+	}
+	q.mutex.RLock()
+	return func() {
+		q.mutex.RUnlock()
+		if !q.inTx {
+			q.txMutex.RUnlock()
+		}
+	}
+}
+
+// findQuiz Get the index of the quiz with the specified id, or -1
+func (q *Querier) findQuiz(id int64) int {
+	for i, quiz := range q.quizzes {
+		if quiz.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// questionPositions Map the ids of the quiz's questions to their positions
+func (q *Querier) questionPositions(quizID int64) map[int64]int32 {
+	positions := map[int64]int32{}
+	for _, question := range q.questions {
+		if question.QuizID == quizID {
+			positions[question.ID] = question.Position
+		}
+	}
+	return positions
+}
@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"quiz/db/generated"
+	"testing"
+	"time"
+)
+
+func TestQuerier_UpdateQuiz_UpdatedAt(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	querier := New()
+	querier.Now = func() time.Time {
+		return created
+	}
+
+	quiz, err := querier.CreateQuiz(context.Background(), db.CreateQuizParams{OwnerID: 1, Title: "title"})
+	if err != nil {
+		t.Fatalf(`querier.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	updated := created.Add(time.Hour)
+	querier.Now = func() time.Time {
+		return updated
+	}
+	quiz, err = querier.UpdateQuiz(context.Background(), db.UpdateQuizParams{ID: quiz.ID, Title: "new title"})
+	if err != nil {
+		t.Fatalf(`querier.UpdateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if quiz.Title != "new title" || !quiz.CreatedAt.Equal(created) || !quiz.UpdatedAt.Equal(updated) {
+		t.Errorf(`quiz = "%+v", expected "new title" created "%v" updated "%v"`, quiz, created, updated)
+	}
+
+	if _, err := querier.UpdateQuiz(context.Background(), db.UpdateQuizParams{ID: 99}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.UpdateQuiz(ctx, 99) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestQuerier_CreateQuestion_UniquePosition(t *testing.T) {
+	querier := New()
+	params := db.CreateQuestionParams{QuizID: 1, Position: 1, Prompt: "prompt"}
+
+	if _, err := querier.CreateQuestion(context.Background(), params); err != nil {
+		t.Fatalf(`querier.CreateQuestion(...) error = "%v", expected "<nil>"`, err)
+	}
+	if _, err := querier.CreateQuestion(context.Background(), params); !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf(`querier.CreateQuestion(...) error = "%v", expected "%v"`, err, ErrUniqueViolation)
+	}
+}
+
+func TestQuerier_GetAnswerOptionsByQuiz_Order(t *testing.T) {
+	querier := New()
+	second, _ := querier.CreateQuestion(context.Background(), db.CreateQuestionParams{QuizID: 1, Position: 2})
+	first, _ := querier.CreateQuestion(context.Background(), db.CreateQuestionParams{QuizID: 1, Position: 1})
+	other, _ := querier.CreateQuestion(context.Background(), db.CreateQuestionParams{QuizID: 2, Position: 1})
+
+	for _, arg := range []db.CreateAnswerOptionParams{
+		{QuestionID: second.ID, Position: 1, Text: "c"},
+		{QuestionID: first.ID, Position: 2, Text: "b"},
+		{QuestionID: other.ID, Position: 1, Text: "x"},
+		{QuestionID: first.ID, Position: 1, Text: "a"},
+	} {
+		if _, err := querier.CreateAnswerOption(context.Background(), arg); err != nil {
+			t.Fatalf(`querier.CreateAnswerOption(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	options, _ := querier.GetAnswerOptionsByQuiz(context.Background(), 1)
+	var texts string
+	for _, option := range options {
+		texts += option.Text
+	}
+	if texts != "abc" {
+		t.Errorf(`answer options = "%s", expected "abc"`, texts)
+	}
+
+	if err := querier.DeleteAnswerOptionsByQuiz(context.Background(), 1); err != nil {
+		t.Fatalf(`querier.DeleteAnswerOptionsByQuiz(ctx, 1) error = "%v", expected "<nil>"`, err)
+	}
+	if options, _ := querier.GetAnswerOptionsByQuiz(context.Background(), 2); len(options) != 1 {
+		t.Errorf(`len(answer options of quiz 2) = "%d", expected "1"`, len(options))
+	}
+}
+
+func TestQuerier_RunInTx_RollsBack(t *testing.T) {
+	querier := New()
+
+	err := querier.RunInTx(
+		context.Background(), nil, func(queries db.Querier) error {
+			if _, err := queries.CreateQuiz(context.Background(), db.CreateQuizParams{OwnerID: 1}); err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		},
+	)
+	if err == nil {
+		t.Fatal(`querier.RunInTx(...) error = "<nil>", expected non-nil`)
+	}
+
+	if count, _ := querier.CountQuizzesByOwner(context.Background(), 1); count != 0 {
+		t.Errorf(`querier.CountQuizzesByOwner(ctx, 1) = "%d", expected "0"`, count)
+	}
+	quiz, _ := querier.CreateQuiz(context.Background(), db.CreateQuizParams{OwnerID: 1})
+	if quiz.ID != 1 {
+		t.Errorf(`quiz.ID = "%d", expected "1"`, quiz.ID)
+	}
+}
+
+func TestQuerier_RunInTx_RollbackKeepsOtherWrites(t *testing.T) {
+	querier := New()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- querier.RunInTx(
+			context.Background(), nil, func(queries db.Querier) error {
+				if _, err := queries.CreateQuiz(context.Background(), db.CreateQuizParams{OwnerID: 1}); err != nil {
+					return err
+				}
+				close(started)
+				<-release
+				return errors.New("rollback")
+			},
+		)
+	}()
+	<-started
+
+	created := make(chan error)
+	go func() {
+		_, err := querier.CreateQuiz(context.Background(), db.CreateQuizParams{OwnerID: 2})
+		created <- err
+	}()
+
+	// Writes from outside the transaction wait for it to end, so rolling it back cannot undo them
+	select {
+	case err := <-created:
+		t.Fatalf(`querier.CreateQuiz(...) = "%v" while the transaction was running, expected it to wait`, err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-done; err == nil {
+		t.Fatal(`querier.RunInTx(...) error = "<nil>", expected non-nil`)
+	}
+	if err := <-created; err != nil {
+		t.Fatalf(`querier.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if count, _ := querier.CountQuizzesByOwner(context.Background(), 2); count != 1 {
+		t.Errorf(`querier.CountQuizzesByOwner(ctx, 2) = "%d", expected "1"`, count)
+	}
+}
+
+func TestQuerier_GetBankQuestionIds_Filters(t *testing.T) {
+	querier := New()
+	for _, arg := range []db.CreateBankQuestionParams{
+		{Category: "science", Difficulty: "easy", Language: "en"},
+		{Category: "science", Difficulty: "hard", Language: "en"},
+		{Category: "history", Difficulty: "easy", Language: "de"},
+	} {
+		if _, err := querier.CreateBankQuestion(context.Background(), arg); err != nil {
+			t.Fatalf(`querier.CreateBankQuestion(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	tests := []struct {
+		arg      db.GetBankQuestionIdsParams
+		expected int
+	}{
+		{arg: db.GetBankQuestionIdsParams{}, expected: 3},
+		{arg: db.GetBankQuestionIdsParams{Category: sql.NullString{String: "science", Valid: true}}, expected: 2},
+		{arg: db.GetBankQuestionIdsParams{Difficulty: sql.NullString{String: "easy", Valid: true}}, expected: 2},
+		{
+			arg: db.GetBankQuestionIdsParams{
+				Difficulty: sql.NullString{String: "easy", Valid: true},
+				Language:   sql.NullString{String: "de", Valid: true},
+			},
+			expected: 1,
+		},
+	}
+
+	for _, test := range tests {
+		ids, err := querier.GetBankQuestionIds(context.Background(), test.arg)
+		if err != nil {
+			t.Fatalf(`querier.GetBankQuestionIds(...) error = "%v", expected "<nil>"`, err)
+		}
+		if len(ids) != test.expected {
+			t.Errorf(`querier.GetBankQuestionIds(%+v) = "%v", expected %d ids`, test.arg, ids, test.expected)
+		}
+	}
+}
+
+func TestQuerier_GetRecentBankQuestionIds_Window(t *testing.T) {
+	seen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	querier := New()
+	querier.Now = func() time.Time {
+		return seen
+	}
+
+	arg := db.RecordBankQuestionViewParams{UserID: 1, BankQuestionID: 4}
+	if err := querier.RecordBankQuestionView(context.Background(), arg); err != nil {
+		t.Fatalf(`querier.RecordBankQuestionView(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	querier.Now = func() time.Time {
+		return seen.Add(30 * time.Minute)
+	}
+	recent := db.GetRecentBankQuestionIdsParams{UserID: 1, WindowSeconds: 3600}
+	if ids, _ := querier.GetRecentBankQuestionIds(context.Background(), recent); len(ids) != 1 || ids[0] != 4 {
+		t.Errorf(`querier.GetRecentBankQuestionIds(...) = "%v", expected "[4]"`, ids)
+	}
+
+	querier.Now = func() time.Time {
+		return seen.Add(2 * time.Hour)
+	}
+	if ids, _ := querier.GetRecentBankQuestionIds(context.Background(), recent); len(ids) != 0 {
+		t.Errorf(`querier.GetRecentBankQuestionIds(...) after the window = "%v", expected none`, ids)
+	}
+
+	// Seeing the question again restarts the window
+	if err := querier.RecordBankQuestionView(context.Background(), arg); err != nil {
+		t.Fatalf(`querier.RecordBankQuestionView(...) error = "%v", expected "<nil>"`, err)
+	}
+	if ids, _ := querier.GetRecentBankQuestionIds(context.Background(), recent); len(ids) != 1 {
+		t.Errorf(`querier.GetRecentBankQuestionIds(...) after seeing again = "%v", expected "[4]"`, ids)
+	}
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE quizzes (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT DEFAULT '' NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX quizzes_owner_id_idx ON quizzes (owner_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE quizzes;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION update_quizzes_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+       NEW.updated_at = CURRENT_TIMESTAMP;
+       RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_quizzes_updated_at
+BEFORE UPDATE ON quizzes
+FOR EACH ROW
+EXECUTE FUNCTION update_quizzes_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_quizzes_updated_at ON quizzes;
+DROP FUNCTION IF EXISTS update_quizzes_updated_at;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions
+    ADD COLUMN question_type VARCHAR(20) DEFAULT 'single_choice' NOT NULL,
+    ADD COLUMN numeric_answer DOUBLE PRECISION,
+    ADD COLUMN numeric_tolerance DOUBLE PRECISION;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions
+    DROP COLUMN numeric_tolerance,
+    DROP COLUMN numeric_answer,
+    DROP COLUMN question_type;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE bank_questions ADD COLUMN shared BOOLEAN DEFAULT false NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bank_questions DROP COLUMN shared;
+-- +goose StatementEnd
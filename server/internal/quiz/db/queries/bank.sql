@@ -0,0 +1,74 @@
+-- name: CreateBankQuestion :one
+INSERT INTO bank_questions (
+    owner_id, category, difficulty, language, question_type, prompt, numeric_answer, numeric_tolerance, shared
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING *;
+
+-- name: GetBankQuestion :one
+SELECT *
+FROM bank_questions
+WHERE id = $1;
+
+-- name: GetBankQuestionIds :many
+SELECT id
+FROM bank_questions
+WHERE (sqlc.narg('category')::VARCHAR IS NULL OR category = sqlc.narg('category'))
+    AND (sqlc.narg('difficulty')::VARCHAR IS NULL OR difficulty = sqlc.narg('difficulty'))
+    AND (sqlc.narg('language')::VARCHAR IS NULL OR language = sqlc.narg('language'))
+    AND (owner_id = sqlc.arg('owner_id') OR shared)
+ORDER BY id;
+
+-- name: DeleteBankQuestion :exec
+DELETE FROM bank_questions
+WHERE id = $1;
+
+-- name: CreateBankAnswerOption :one
+INSERT INTO bank_answer_options (bank_question_id, position, text, is_correct)
+VALUES ($1, $2, $3, $4)
+    RETURNING *;
+
+-- name: GetBankAnswerOptionsByQuestion :many
+SELECT *
+FROM bank_answer_options
+WHERE bank_question_id = $1
+ORDER BY position;
+
+-- name: DeleteBankAnswerOptionsByQuestion :exec
+DELETE FROM bank_answer_options
+WHERE bank_question_id = $1;
+
+-- name: CreateBankQuestionTag :exec
+INSERT INTO bank_question_tags (bank_question_id, tag)
+VALUES ($1, $2);
+
+-- name: GetBankQuestionTags :many
+SELECT tag
+FROM bank_question_tags
+WHERE bank_question_id = $1
+ORDER BY tag;
+
+-- name: GetBankQuestionIdsByTag :many
+SELECT bank_question_id
+FROM bank_question_tags
+WHERE tag = $1
+ORDER BY bank_question_id;
+
+-- name: DeleteBankQuestionTagsByQuestion :exec
+DELETE FROM bank_question_tags
+WHERE bank_question_id = $1;
+
+-- name: RecordBankQuestionView :exec
+INSERT INTO bank_question_views (user_id, bank_question_id, seen_at)
+VALUES ($1, $2, CURRENT_TIMESTAMP)
+ON CONFLICT (user_id, bank_question_id) DO UPDATE SET seen_at = EXCLUDED.seen_at;
+
+-- name: GetRecentBankQuestionIds :many
+SELECT bank_question_id
+FROM bank_question_views
+WHERE user_id = $1 AND seen_at > CURRENT_TIMESTAMP - (sqlc.arg('window_seconds')::BIGINT * INTERVAL '1 second')
+ORDER BY bank_question_id;
+
+-- name: DeleteBankQuestionViewsByQuestion :exec
+DELETE FROM bank_question_views
+WHERE bank_question_id = $1;
@@ -0,0 +1,41 @@
+-- name: CreateQuestion :one
+INSERT INTO questions (quiz_id, position, prompt, question_type, numeric_answer, numeric_tolerance)
+VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING *;
+
+-- name: GetQuestionsByQuiz :many
+SELECT *
+FROM questions
+WHERE quiz_id = $1
+ORDER BY position;
+
+-- name: GetQuizQuestion :one
+SELECT *
+FROM questions
+WHERE id = $1 AND quiz_id = $2;
+
+-- name: DeleteQuestionsByQuiz :exec
+DELETE FROM questions
+WHERE quiz_id = $1;
+
+-- name: CreateAnswerOption :one
+INSERT INTO answer_options (question_id, position, text, is_correct)
+VALUES ($1, $2, $3, $4)
+    RETURNING *;
+
+-- name: GetAnswerOptionsByQuiz :many
+SELECT answer_options.*
+FROM answer_options
+    JOIN questions ON questions.id = answer_options.question_id
+WHERE questions.quiz_id = $1
+ORDER BY questions.position, answer_options.position;
+
+-- name: GetAnswerOptionsByQuestion :many
+SELECT *
+FROM answer_options
+WHERE question_id = $1
+ORDER BY position;
+
+-- name: DeleteAnswerOptionsByQuiz :exec
+DELETE FROM answer_options
+WHERE question_id IN (SELECT id FROM questions WHERE quiz_id = $1);
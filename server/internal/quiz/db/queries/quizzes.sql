@@ -0,0 +1,35 @@
+-- name: CreateQuiz :one
+INSERT INTO quizzes (owner_id, title, description)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: GetQuiz :one
+SELECT *
+FROM quizzes
+WHERE id = $1;
+
+-- name: GetQuizzesByOwner :many
+SELECT *
+FROM quizzes
+WHERE owner_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: CountQuizzesByOwner :one
+SELECT
+    COUNT(*)
+FROM
+    quizzes
+WHERE owner_id = $1;
+
+-- name: UpdateQuiz :one
+UPDATE quizzes
+SET title = $2,
+    description = $3
+WHERE id = $1
+    RETURNING *;
+
+-- name: DeleteQuiz :exec
+DELETE FROM quizzes
+WHERE id = $1;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions ADD COLUMN question_type VARCHAR(20) DEFAULT 'single_choice' NOT NULL;
+ALTER TABLE questions ADD COLUMN numeric_answer REAL;
+ALTER TABLE questions ADD COLUMN numeric_tolerance REAL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions DROP COLUMN numeric_tolerance;
+ALTER TABLE questions DROP COLUMN numeric_answer;
+ALTER TABLE questions DROP COLUMN question_type;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE bank_questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    difficulty VARCHAR(10) NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    question_type VARCHAR(20) NOT NULL,
+    prompt TEXT NOT NULL,
+    numeric_answer REAL,
+    numeric_tolerance REAL,
+    created_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')) NOT NULL
+);
+
+CREATE INDEX bank_questions_filter_idx ON bank_questions (category, difficulty, language);
+
+CREATE TABLE bank_answer_options (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bank_question_id INTEGER NOT NULL REFERENCES bank_questions (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    is_correct BOOLEAN DEFAULT false NOT NULL,
+    UNIQUE (bank_question_id, position)
+);
+
+CREATE TABLE bank_question_tags (
+    bank_question_id INTEGER NOT NULL REFERENCES bank_questions (id) ON DELETE CASCADE,
+    tag VARCHAR(30) NOT NULL,
+    PRIMARY KEY (bank_question_id, tag)
+);
+
+CREATE INDEX bank_question_tags_tag_idx ON bank_question_tags (tag, bank_question_id);
+
+CREATE TABLE bank_question_views (
+    user_id INTEGER NOT NULL,
+    bank_question_id INTEGER NOT NULL REFERENCES bank_questions (id) ON DELETE CASCADE,
+    seen_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')) NOT NULL,
+    PRIMARY KEY (user_id, bank_question_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE bank_question_views;
+DROP TABLE bank_question_tags;
+DROP TABLE bank_answer_options;
+DROP TABLE bank_questions;
+-- +goose StatementEnd
@@ -0,0 +1,369 @@
+// Package sqlite adapts the sqlc-generated SQLite queries to the db.Querier interface used by the quiz service
+package sqlite
+
+import (
+	"context"
+	"quiz/db/generated"
+	"quiz/db/sqlite/generated"
+)
+
+// Querier db.Querier implementation backed by the SQLite queries
+type Querier struct {
+	Queries sqlitedb.Querier
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New Create a db.Querier using the specified SQLite connection
+func New(database sqlitedb.DBTX) *Querier {
+	return &Querier{
+		Queries: sqlitedb.New(database),
+	}
+}
+
+// CountQuizzesByOwner CountQuizzesByOwner() implementation from db.Querier interface
+func (q *Querier) CountQuizzesByOwner(ctx context.Context, ownerID int32) (int64, error) {
+	return q.Queries.CountQuizzesByOwner(ctx, int64(ownerID))
+}
+
+// CreateQuiz CreateQuiz() implementation from db.Querier interface
+func (q *Querier) CreateQuiz(ctx context.Context, arg db.CreateQuizParams) (db.Quiz, error) {
+	quiz, err := q.Queries.CreateQuiz(
+		ctx, sqlitedb.CreateQuizParams{
+			OwnerID:     int64(arg.OwnerID),
+			Title:       arg.Title,
+			Description: arg.Description,
+		},
+	)
+	return toQuiz(quiz), err
+}
+
+// GetQuiz GetQuiz() implementation from db.Querier interface
+func (q *Querier) GetQuiz(ctx context.Context, id int64) (db.Quiz, error) {
+	quiz, err := q.Queries.GetQuiz(ctx, id)
+	return toQuiz(quiz), err
+}
+
+// GetQuizzesByOwner GetQuizzesByOwner() implementation from db.Querier interface
+func (q *Querier) GetQuizzesByOwner(ctx context.Context, arg db.GetQuizzesByOwnerParams) ([]db.Quiz, error) {
+	quizzes, err := q.Queries.GetQuizzesByOwner(
+		ctx, sqlitedb.GetQuizzesByOwnerParams{
+			OwnerID: int64(arg.OwnerID),
+			Limit:   int64(arg.Limit),
+			Offset:  int64(arg.Offset),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.Quiz, len(quizzes))
+	for i, quiz := range quizzes {
+		result[i] = toQuiz(quiz)
+	}
+	return result, nil
+}
+
+// UpdateQuiz UpdateQuiz() implementation from db.Querier interface
+func (q *Querier) UpdateQuiz(ctx context.Context, arg db.UpdateQuizParams) (db.Quiz, error) {
+	quiz, err := q.Queries.UpdateQuiz(
+		ctx, sqlitedb.UpdateQuizParams{
+			ID:          arg.ID,
+			Title:       arg.Title,
+			Description: arg.Description,
+		},
+	)
+	return toQuiz(quiz), err
+}
+
+// DeleteQuiz DeleteQuiz() implementation from db.Querier interface
+func (q *Querier) DeleteQuiz(ctx context.Context, id int64) error {
+	return q.Queries.DeleteQuiz(ctx, id)
+}
+
+// CreateQuestion CreateQuestion() implementation from db.Querier interface
+func (q *Querier) CreateQuestion(ctx context.Context, arg db.CreateQuestionParams) (db.Question, error) {
+	question, err := q.Queries.CreateQuestion(
+		ctx, sqlitedb.CreateQuestionParams{
+			QuizID:           arg.QuizID,
+			Position:         int64(arg.Position),
+			Prompt:           arg.Prompt,
+			QuestionType:     arg.QuestionType,
+			NumericAnswer:    arg.NumericAnswer,
+			NumericTolerance: arg.NumericTolerance,
+		},
+	)
+	return toQuestion(question), err
+}
+
+// GetQuestionsByQuiz GetQuestionsByQuiz() implementation from db.Querier interface
+func (q *Querier) GetQuestionsByQuiz(ctx context.Context, quizID int64) ([]db.Question, error) {
+	questions, err := q.Queries.GetQuestionsByQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.Question, len(questions))
+	for i, question := range questions {
+		result[i] = toQuestion(question)
+	}
+	return result, nil
+}
+
+// GetQuizQuestion GetQuizQuestion() implementation from db.Querier interface
+func (q *Querier) GetQuizQuestion(ctx context.Context, arg db.GetQuizQuestionParams) (db.Question, error) {
+	question, err := q.Queries.GetQuizQuestion(
+		ctx, sqlitedb.GetQuizQuestionParams{
+			ID:     arg.ID,
+			QuizID: arg.QuizID,
+		},
+	)
+	return toQuestion(question), err
+}
+
+// DeleteQuestionsByQuiz DeleteQuestionsByQuiz() implementation from db.Querier interface
+func (q *Querier) DeleteQuestionsByQuiz(ctx context.Context, quizID int64) error {
+	return q.Queries.DeleteQuestionsByQuiz(ctx, quizID)
+}
+
+// CreateAnswerOption CreateAnswerOption() implementation from db.Querier interface
+func (q *Querier) CreateAnswerOption(ctx context.Context, arg db.CreateAnswerOptionParams) (db.AnswerOption, error) {
+	option, err := q.Queries.CreateAnswerOption(
+		ctx, sqlitedb.CreateAnswerOptionParams{
+			QuestionID: arg.QuestionID,
+			Position:   int64(arg.Position),
+			Text:       arg.Text,
+			IsCorrect:  arg.IsCorrect,
+		},
+	)
+	return toAnswerOption(option), err
+}
+
+// GetAnswerOptionsByQuiz GetAnswerOptionsByQuiz() implementation from db.Querier interface
+func (q *Querier) GetAnswerOptionsByQuiz(ctx context.Context, quizID int64) ([]db.AnswerOption, error) {
+	options, err := q.Queries.GetAnswerOptionsByQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.AnswerOption, len(options))
+	for i, option := range options {
+		result[i] = toAnswerOption(option)
+	}
+	return result, nil
+}
+
+// GetAnswerOptionsByQuestion GetAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *Querier) GetAnswerOptionsByQuestion(ctx context.Context, questionID int64) ([]db.AnswerOption, error) {
+	options, err := q.Queries.GetAnswerOptionsByQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.AnswerOption, len(options))
+	for i, option := range options {
+		result[i] = toAnswerOption(option)
+	}
+	return result, nil
+}
+
+// DeleteAnswerOptionsByQuiz DeleteAnswerOptionsByQuiz() implementation from db.Querier interface
+func (q *Querier) DeleteAnswerOptionsByQuiz(ctx context.Context, quizID int64) error {
+	return q.Queries.DeleteAnswerOptionsByQuiz(ctx, quizID)
+}
+
+// CreateBankQuestion CreateBankQuestion() implementation from db.Querier interface
+func (q *Querier) CreateBankQuestion(ctx context.Context, arg db.CreateBankQuestionParams) (db.BankQuestion, error) {
+	question, err := q.Queries.CreateBankQuestion(
+		ctx, sqlitedb.CreateBankQuestionParams{
+			OwnerID:          int64(arg.OwnerID),
+			Category:         arg.Category,
+			Difficulty:       arg.Difficulty,
+			Language:         arg.Language,
+			QuestionType:     arg.QuestionType,
+			Prompt:           arg.Prompt,
+			NumericAnswer:    arg.NumericAnswer,
+			NumericTolerance: arg.NumericTolerance,
+			Shared:           arg.Shared,
+		},
+	)
+	return toBankQuestion(question), err
+}
+
+// GetBankQuestion GetBankQuestion() implementation from db.Querier interface
+func (q *Querier) GetBankQuestion(ctx context.Context, id int64) (db.BankQuestion, error) {
+	question, err := q.Queries.GetBankQuestion(ctx, id)
+	return toBankQuestion(question), err
+}
+
+// GetBankQuestionIds GetBankQuestionIds() implementation from db.Querier interface
+func (q *Querier) GetBankQuestionIds(ctx context.Context, arg db.GetBankQuestionIdsParams) ([]int64, error) {
+	return q.Queries.GetBankQuestionIds(
+		ctx, sqlitedb.GetBankQuestionIdsParams{
+			Category:   arg.Category,
+			Difficulty: arg.Difficulty,
+			Language:   arg.Language,
+			OwnerID:    int64(arg.OwnerID),
+		},
+	)
+}
+
+// DeleteBankQuestion DeleteBankQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankQuestion(ctx context.Context, id int64) error {
+	return q.Queries.DeleteBankQuestion(ctx, id)
+}
+
+// CreateBankAnswerOption CreateBankAnswerOption() implementation from db.Querier interface
+func (q *Querier) CreateBankAnswerOption(
+	ctx context.Context,
+	arg db.CreateBankAnswerOptionParams,
+) (db.BankAnswerOption, error) {
+	option, err := q.Queries.CreateBankAnswerOption(
+		ctx, sqlitedb.CreateBankAnswerOptionParams{
+			BankQuestionID: arg.BankQuestionID,
+			Position:       int64(arg.Position),
+			Text:           arg.Text,
+			IsCorrect:      arg.IsCorrect,
+		},
+	)
+	return toBankAnswerOption(option), err
+}
+
+// GetBankAnswerOptionsByQuestion GetBankAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *Querier) GetBankAnswerOptionsByQuestion(
+	ctx context.Context,
+	bankQuestionID int64,
+) ([]db.BankAnswerOption, error) {
+	options, err := q.Queries.GetBankAnswerOptionsByQuestion(ctx, bankQuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.BankAnswerOption, len(options))
+	for i, option := range options {
+		result[i] = toBankAnswerOption(option)
+	}
+	return result, nil
+}
+
+// DeleteBankAnswerOptionsByQuestion DeleteBankAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankAnswerOptionsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	return q.Queries.DeleteBankAnswerOptionsByQuestion(ctx, bankQuestionID)
+}
+
+// CreateBankQuestionTag CreateBankQuestionTag() implementation from db.Querier interface
+func (q *Querier) CreateBankQuestionTag(ctx context.Context, arg db.CreateBankQuestionTagParams) error {
+	return q.Queries.CreateBankQuestionTag(
+		ctx, sqlitedb.CreateBankQuestionTagParams{
+			BankQuestionID: arg.BankQuestionID,
+			Tag:            arg.Tag,
+		},
+	)
+}
+
+// GetBankQuestionTags GetBankQuestionTags() implementation from db.Querier interface
+func (q *Querier) GetBankQuestionTags(ctx context.Context, bankQuestionID int64) ([]string, error) {
+	return q.Queries.GetBankQuestionTags(ctx, bankQuestionID)
+}
+
+// GetBankQuestionIdsByTag GetBankQuestionIdsByTag() implementation from db.Querier interface
+func (q *Querier) GetBankQuestionIdsByTag(ctx context.Context, tag string) ([]int64, error) {
+	return q.Queries.GetBankQuestionIdsByTag(ctx, tag)
+}
+
+// DeleteBankQuestionTagsByQuestion DeleteBankQuestionTagsByQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankQuestionTagsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	return q.Queries.DeleteBankQuestionTagsByQuestion(ctx, bankQuestionID)
+}
+
+// RecordBankQuestionView RecordBankQuestionView() implementation from db.Querier interface
+func (q *Querier) RecordBankQuestionView(ctx context.Context, arg db.RecordBankQuestionViewParams) error {
+	return q.Queries.RecordBankQuestionView(
+		ctx, sqlitedb.RecordBankQuestionViewParams{
+			UserID:         int64(arg.UserID),
+			BankQuestionID: arg.BankQuestionID,
+		},
+	)
+}
+
+// GetRecentBankQuestionIds GetRecentBankQuestionIds() implementation from db.Querier interface
+func (q *Querier) GetRecentBankQuestionIds(
+	ctx context.Context,
+	arg db.GetRecentBankQuestionIdsParams,
+) ([]int64, error) {
+	return q.Queries.GetRecentBankQuestionIds(
+		ctx, sqlitedb.GetRecentBankQuestionIdsParams{
+			UserID:        int64(arg.UserID),
+			WindowSeconds: arg.WindowSeconds,
+		},
+	)
+}
+
+// DeleteBankQuestionViewsByQuestion DeleteBankQuestionViewsByQuestion() implementation from db.Querier interface
+func (q *Querier) DeleteBankQuestionViewsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	return q.Queries.DeleteBankQuestionViewsByQuestion(ctx, bankQuestionID)
+}
+
+// toQuiz Convert a SQLite quiz row to the shared db.Quiz model
+func toQuiz(quiz sqlitedb.Quiz) db.Quiz {
+	return db.Quiz{
+		ID:          quiz.ID,
+		OwnerID:     int32(quiz.OwnerID),
+		Title:       quiz.Title,
+		Description: quiz.Description,
+		CreatedAt:   quiz.CreatedAt,
+		UpdatedAt:   quiz.UpdatedAt,
+	}
+}
+
+// toQuestion Convert a SQLite question row to the shared db.Question model
+func toQuestion(question sqlitedb.Question) db.Question {
+	return db.Question{
+		ID:               question.ID,
+		QuizID:           question.QuizID,
+		Position:         int32(question.Position),
+		Prompt:           question.Prompt,
+		QuestionType:     question.QuestionType,
+		NumericAnswer:    question.NumericAnswer,
+		NumericTolerance: question.NumericTolerance,
+	}
+}
+
+// toAnswerOption Convert a SQLite answer option row to the shared db.AnswerOption model
+func toAnswerOption(option sqlitedb.AnswerOption) db.AnswerOption {
+	return db.AnswerOption{
+		ID:         option.ID,
+		QuestionID: option.QuestionID,
+		Position:   int32(option.Position),
+		Text:       option.Text,
+		IsCorrect:  option.IsCorrect,
+	}
+}
+
+// toBankQuestion Convert a SQLite bank question row to the shared db.BankQuestion model
+func toBankQuestion(question sqlitedb.BankQuestion) db.BankQuestion {
+	return db.BankQuestion{
+		ID:               question.ID,
+		OwnerID:          int32(question.OwnerID),
+		Category:         question.Category,
+		Difficulty:       question.Difficulty,
+		Language:         question.Language,
+		QuestionType:     question.QuestionType,
+		Prompt:           question.Prompt,
+		NumericAnswer:    question.NumericAnswer,
+		NumericTolerance: question.NumericTolerance,
+		CreatedAt:        question.CreatedAt,
+		Shared:           question.Shared,
+	}
+}
+
+// toBankAnswerOption Convert a SQLite bank answer option row to the shared db.BankAnswerOption model
+func toBankAnswerOption(option sqlitedb.BankAnswerOption) db.BankAnswerOption {
+	return db.BankAnswerOption{
+		ID:             option.ID,
+		BankQuestionID: option.BankQuestionID,
+		Position:       int32(option.Position),
+		Text:           option.Text,
+		IsCorrect:      option.IsCorrect,
+	}
+}
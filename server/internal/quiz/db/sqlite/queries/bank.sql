@@ -0,0 +1,85 @@
+-- name: CreateBankQuestion :one
+INSERT INTO bank_questions (
+    owner_id, category, difficulty, language, question_type, prompt, numeric_answer, numeric_tolerance, shared
+)
+VALUES (
+    sqlc.arg(owner_id),
+    sqlc.arg(category),
+    sqlc.arg(difficulty),
+    sqlc.arg(language),
+    sqlc.arg(question_type),
+    sqlc.arg(prompt),
+    sqlc.narg(numeric_answer),
+    sqlc.narg(numeric_tolerance),
+    sqlc.arg(shared)
+)
+    RETURNING *;
+
+-- name: GetBankQuestion :one
+SELECT *
+FROM bank_questions
+WHERE id = sqlc.arg(id);
+
+-- name: GetBankQuestionIds :many
+SELECT id
+FROM bank_questions
+WHERE (sqlc.narg(category) IS NULL OR category = sqlc.narg(category))
+    AND (sqlc.narg(difficulty) IS NULL OR difficulty = sqlc.narg(difficulty))
+    AND (sqlc.narg(language) IS NULL OR language = sqlc.narg(language))
+    AND (owner_id = sqlc.arg(owner_id) OR shared)
+ORDER BY id;
+
+-- name: DeleteBankQuestion :exec
+DELETE FROM bank_questions
+WHERE id = sqlc.arg(id);
+
+-- name: CreateBankAnswerOption :one
+INSERT INTO bank_answer_options (bank_question_id, position, text, is_correct)
+VALUES (sqlc.arg(bank_question_id), sqlc.arg(position), sqlc.arg(text), sqlc.arg(is_correct))
+    RETURNING *;
+
+-- name: GetBankAnswerOptionsByQuestion :many
+SELECT *
+FROM bank_answer_options
+WHERE bank_question_id = sqlc.arg(bank_question_id)
+ORDER BY position;
+
+-- name: DeleteBankAnswerOptionsByQuestion :exec
+DELETE FROM bank_answer_options
+WHERE bank_question_id = sqlc.arg(bank_question_id);
+
+-- name: CreateBankQuestionTag :exec
+INSERT INTO bank_question_tags (bank_question_id, tag)
+VALUES (sqlc.arg(bank_question_id), sqlc.arg(tag));
+
+-- name: GetBankQuestionTags :many
+SELECT tag
+FROM bank_question_tags
+WHERE bank_question_id = sqlc.arg(bank_question_id)
+ORDER BY tag;
+
+-- name: GetBankQuestionIdsByTag :many
+SELECT bank_question_id
+FROM bank_question_tags
+WHERE tag = sqlc.arg(tag)
+ORDER BY bank_question_id;
+
+-- name: DeleteBankQuestionTagsByQuestion :exec
+DELETE FROM bank_question_tags
+WHERE bank_question_id = sqlc.arg(bank_question_id);
+
+-- name: RecordBankQuestionView :exec
+INSERT INTO bank_question_views (user_id, bank_question_id, seen_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(bank_question_id), strftime('%Y-%m-%d %H:%M:%f', 'now'))
+ON CONFLICT (user_id, bank_question_id) DO UPDATE SET seen_at = excluded.seen_at;
+
+-- name: GetRecentBankQuestionIds :many
+SELECT bank_question_id
+FROM bank_question_views
+WHERE user_id = sqlc.arg(user_id)
+    AND seen_at > strftime('%Y-%m-%d %H:%M:%f', 'now', '-' || sqlc.arg(window_seconds) || ' seconds')
+ORDER BY bank_question_id;
+
+-- name: DeleteBankQuestionViewsByQuestion :exec
+DELETE FROM bank_question_views
+WHERE bank_question_id = sqlc.arg(bank_question_id);
@@ -0,0 +1,48 @@
+-- name: CreateQuestion :one
+INSERT INTO questions (quiz_id, position, prompt, question_type, numeric_answer, numeric_tolerance)
+VALUES (
+    sqlc.arg(quiz_id),
+    sqlc.arg(position),
+    sqlc.arg(prompt),
+    sqlc.arg(question_type),
+    sqlc.narg(numeric_answer),
+    sqlc.narg(numeric_tolerance)
+)
+    RETURNING *;
+
+-- name: GetQuestionsByQuiz :many
+SELECT *
+FROM questions
+WHERE quiz_id = sqlc.arg(quiz_id)
+ORDER BY position;
+
+-- name: GetQuizQuestion :one
+SELECT *
+FROM questions
+WHERE id = sqlc.arg(id) AND quiz_id = sqlc.arg(quiz_id);
+
+-- name: DeleteQuestionsByQuiz :exec
+DELETE FROM questions
+WHERE quiz_id = sqlc.arg(quiz_id);
+
+-- name: CreateAnswerOption :one
+INSERT INTO answer_options (question_id, position, text, is_correct)
+VALUES (sqlc.arg(question_id), sqlc.arg(position), sqlc.arg(text), sqlc.arg(is_correct))
+    RETURNING *;
+
+-- name: GetAnswerOptionsByQuiz :many
+SELECT answer_options.*
+FROM answer_options
+    JOIN questions ON questions.id = answer_options.question_id
+WHERE questions.quiz_id = sqlc.arg(quiz_id)
+ORDER BY questions.position, answer_options.position;
+
+-- name: GetAnswerOptionsByQuestion :many
+SELECT *
+FROM answer_options
+WHERE question_id = sqlc.arg(question_id)
+ORDER BY position;
+
+-- name: DeleteAnswerOptionsByQuiz :exec
+DELETE FROM answer_options
+WHERE question_id IN (SELECT id FROM questions WHERE quiz_id = sqlc.arg(quiz_id));
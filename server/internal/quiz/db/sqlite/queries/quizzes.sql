@@ -0,0 +1,37 @@
+-- name: CreateQuiz :one
+INSERT INTO quizzes (owner_id, title, description)
+VALUES (sqlc.arg(owner_id), sqlc.arg(title), sqlc.arg(description))
+    RETURNING *;
+
+-- name: GetQuiz :one
+SELECT *
+FROM quizzes
+WHERE id = sqlc.arg(id);
+
+-- name: GetQuizzesByOwner :many
+SELECT *
+FROM quizzes
+WHERE owner_id = sqlc.arg(owner_id)
+ORDER BY id
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
+
+-- name: CountQuizzesByOwner :one
+SELECT
+    COUNT(*)
+FROM
+    quizzes
+WHERE owner_id = sqlc.arg(owner_id);
+
+-- name: UpdateQuiz :one
+-- updated_at is set explicitly because RETURNING does not reflect changes made by AFTER UPDATE triggers
+UPDATE quizzes
+SET title = sqlc.arg(title),
+    description = sqlc.arg(description),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: DeleteQuiz :exec
+DELETE FROM quizzes
+WHERE id = sqlc.arg(id);
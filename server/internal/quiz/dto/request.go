@@ -0,0 +1,120 @@
+package dto
+
+type AnswerOptionRequest struct {
+	Text      string `json:"text"`
+	IsCorrect bool   `json:"isCorrect"`
+}
+
+type QuestionRequest struct {
+	Type             string                `json:"type"`
+	Prompt           string                `json:"prompt"`
+	AnswerOptions    []AnswerOptionRequest `json:"answerOptions"`
+	NumericAnswer    *float64              `json:"numericAnswer"`
+	NumericTolerance *float64              `json:"numericTolerance"`
+}
+
+type CreateQuizRequest struct {
+	OwnerId     int               `json:"ownerId"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Questions   []QuestionRequest `json:"questions"`
+}
+
+type GetQuizRequest struct {
+	OwnerId int   `json:"ownerId"`
+	QuizId  int64 `json:"quizId"`
+}
+
+type GetQuizzesRequest struct {
+	OwnerId int  `json:"ownerId"`
+	Limit   *int `json:"limit"`
+	Offset  *int `json:"offset"`
+}
+
+type UpdateQuizRequest struct {
+	OwnerId     int               `json:"ownerId"`
+	QuizId      int64             `json:"quizId"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Questions   []QuestionRequest `json:"questions"`
+}
+
+type DeleteQuizRequest struct {
+	OwnerId int   `json:"ownerId"`
+	QuizId  int64 `json:"quizId"`
+}
+
+// GetPlayableQuizRequest A quiz to play. With OwnerId set, only a quiz owned by that user is found
+type GetPlayableQuizRequest struct {
+	QuizId  int64 `json:"quizId"`
+	OwnerId int   `json:"ownerId"`
+}
+
+// GetGameQuizRequest A quiz to run a game of. With OwnerId set, only a quiz owned by that user is found
+type GetGameQuizRequest struct {
+	QuizId  int64 `json:"quizId"`
+	OwnerId int   `json:"ownerId"`
+}
+
+// Answer A player's answer to a question. Which fields are used depends on the question type: answer option ids for
+// choice and ordering questions (in the chosen order for ordering), the number for numeric questions and the text
+// for free text questions
+type Answer struct {
+	AnswerOptionIds []int64  `json:"answerOptionIds"`
+	Number          *float64 `json:"number"`
+	Text            *string  `json:"text"`
+}
+
+type GradeAnswerRequest struct {
+	QuizId     int64  `json:"quizId"`
+	QuestionId int64  `json:"questionId"`
+	Answer     Answer `json:"answer"`
+}
+
+type ImportQuizRequest struct {
+	OwnerId     int    `json:"ownerId"`
+	Format      string `json:"format"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	DryRun      bool   `json:"dryRun"`
+}
+
+// CreateBankQuestionRequest A question for the question bank: a question as authored in a quiz, labelled with the
+// category, difficulty, language and tags used to find it. Only shared questions are drawn into other users' quizzes
+type CreateBankQuestionRequest struct {
+	OwnerId          int                   `json:"ownerId"`
+	Category         string                `json:"category"`
+	Difficulty       string                `json:"difficulty"`
+	Language         string                `json:"language"`
+	Tags             []string              `json:"tags"`
+	Shared           bool                  `json:"shared"`
+	Type             string                `json:"type"`
+	Prompt           string                `json:"prompt"`
+	AnswerOptions    []AnswerOptionRequest `json:"answerOptions"`
+	NumericAnswer    *float64              `json:"numericAnswer"`
+	NumericTolerance *float64              `json:"numericTolerance"`
+}
+
+type GetBankQuestionRequest struct {
+	OwnerId    int   `json:"ownerId"`
+	QuestionId int64 `json:"questionId"`
+}
+
+type DeleteBankQuestionRequest struct {
+	OwnerId    int   `json:"ownerId"`
+	QuestionId int64 `json:"questionId"`
+}
+
+// GenerateQuizRequest Criteria for a quiz drawn from the owner's and the shared questions of the question bank. Empty
+// filters match every question, and a question must have every tag listed
+type GenerateQuizRequest struct {
+	OwnerId     int      `json:"ownerId"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Count       int      `json:"count"`
+	Category    string   `json:"category"`
+	Difficulty  string   `json:"difficulty"`
+	Language    string   `json:"language"`
+	Tags        []string `json:"tags"`
+	PlayerIds   []int    `json:"playerIds"`
+}
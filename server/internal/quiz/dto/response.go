@@ -0,0 +1,136 @@
+package dto
+
+import (
+	"time"
+)
+
+type AnswerOption struct {
+	AnswerOptionId int64  `json:"answerOptionId"`
+	Position       int    `json:"position"`
+	Text           string `json:"text"`
+	IsCorrect      bool   `json:"isCorrect"`
+}
+
+type Question struct {
+	QuestionId       int64          `json:"questionId"`
+	Position         int            `json:"position"`
+	Type             string         `json:"type"`
+	Prompt           string         `json:"prompt"`
+	AnswerOptions    []AnswerOption `json:"answerOptions"`
+	NumericAnswer    *float64       `json:"numericAnswer,omitempty"`
+	NumericTolerance *float64       `json:"numericTolerance,omitempty"`
+}
+
+type Quiz struct {
+	QuizId      int64      `json:"quizId"`
+	OwnerId     int        `json:"ownerId"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Questions   []Question `json:"questions"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type QuizSummary struct {
+	QuizId      int64     `json:"quizId"`
+	OwnerId     int       `json:"ownerId"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type CreateQuizResponse = Quiz
+
+type GetQuizResponse = Quiz
+
+type GetQuizzesResponse struct {
+	Quizzes  []QuizSummary `json:"quizzes"`
+	PrevLink *string       `json:"prevLink,omitempty"`
+	NextLink *string       `json:"nextLink,omitempty"`
+}
+
+type UpdateQuizResponse = Quiz
+
+type DeleteQuizResponse struct {
+}
+
+// PlayableAnswerOption An answer option as shown to players, without whether it is correct
+type PlayableAnswerOption struct {
+	AnswerOptionId int64  `json:"answerOptionId"`
+	Text           string `json:"text"`
+}
+
+// PlayableQuestion A question as shown to players, without anything that gives away the answer
+type PlayableQuestion struct {
+	QuestionId    int64                  `json:"questionId"`
+	Position      int                    `json:"position"`
+	Type          string                 `json:"type"`
+	Prompt        string                 `json:"prompt"`
+	AnswerOptions []PlayableAnswerOption `json:"answerOptions"`
+}
+
+// PlayableQuiz A quiz as shown to players
+type PlayableQuiz struct {
+	QuizId      int64              `json:"quizId"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Questions   []PlayableQuestion `json:"questions"`
+}
+
+type GetPlayableQuizResponse = PlayableQuiz
+
+// GameQuiz A quiz as shown to players together with its questions as authored, so a game can grade answers against
+// the quiz as it was when the game was set up
+type GameQuiz struct {
+	Quiz      PlayableQuiz `json:"quiz"`
+	Questions []Question   `json:"questions"`
+}
+
+type GetGameQuizResponse = GameQuiz
+
+type GradeAnswerResponse struct {
+	Correct bool    `json:"correct"`
+	Score   float64 `json:"score"`
+}
+
+type ImportQuestionError struct {
+	Row     int    `json:"row"`
+	Prompt  string `json:"prompt"`
+	Message string `json:"message"`
+}
+
+type ImportQuizResponse struct {
+	DryRun   bool                  `json:"dryRun"`
+	Total    int                   `json:"total"`
+	Imported int                   `json:"imported"`
+	Failed   int                   `json:"failed"`
+	Errors   []ImportQuestionError `json:"errors"`
+	Preview  []QuestionRequest     `json:"preview"`
+	Quiz     *Quiz                 `json:"quiz,omitempty"`
+}
+
+type BankQuestion struct {
+	QuestionId       int64          `json:"questionId"`
+	OwnerId          int            `json:"ownerId"`
+	Category         string         `json:"category"`
+	Difficulty       string         `json:"difficulty"`
+	Language         string         `json:"language"`
+	Tags             []string       `json:"tags"`
+	Shared           bool           `json:"shared"`
+	Type             string         `json:"type"`
+	Prompt           string         `json:"prompt"`
+	AnswerOptions    []AnswerOption `json:"answerOptions"`
+	NumericAnswer    *float64       `json:"numericAnswer,omitempty"`
+	NumericTolerance *float64       `json:"numericTolerance,omitempty"`
+	CreatedAt        time.Time      `json:"createdAt"`
+}
+
+type CreateBankQuestionResponse = BankQuestion
+
+type GetBankQuestionResponse = BankQuestion
+
+type DeleteBankQuestionResponse struct {
+}
+
+type GenerateQuizResponse = Quiz
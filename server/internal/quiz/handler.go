@@ -0,0 +1,609 @@
+package quiz
+
+import (
+	"common"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"quiz/dto"
+	"strconv"
+)
+
+// CreateQuizHandler Handler function for create quiz endpoint
+func CreateQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateCreateQuizRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateCreateQuizRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.CreateQuiz(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/quiz/"+strconv.FormatInt(response.QuizId, 10))
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetQuizHandler Handler function for get quiz endpoint
+func GetQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		quizId, err := parseQuizId(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		request := dto.GetQuizRequest{
+			OwnerId: userClaims.ID,
+			QuizId:  quizId,
+		}
+
+		if err := ValidateGetQuizRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetQuiz(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetQuizzesHandler Handler function for get quizzes endpoint
+func GetQuizzesHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateGetQuizzesRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateGetQuizzesRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetQuizzes(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// UpdateQuizHandler Handler function for update quiz endpoint
+func UpdateQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateUpdateQuizRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateUpdateQuizRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.UpdateQuiz(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// DeleteQuizHandler Handler function for delete quiz endpoint
+func DeleteQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		quizId, err := parseQuizId(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		request := dto.DeleteQuizRequest{
+			OwnerId: userClaims.ID,
+			QuizId:  quizId,
+		}
+
+		if err := ValidateDeleteQuizRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if _, err := service.DeleteQuiz(r.Context(), &request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetPlayableQuizHandler Handler function for get playable quiz endpoint
+func GetPlayableQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := common.GetUserClaims(r.Context()); !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		quizId, err := parseQuizId(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		request := dto.GetPlayableQuizRequest{QuizId: quizId}
+
+		if err := ValidateGetPlayableQuizRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetPlayableQuiz(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GradeAnswerHandler Handler function for grade answer endpoint
+func GradeAnswerHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := common.GetUserClaims(r.Context()); !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateGradeAnswerRequest(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateGradeAnswerRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GradeAnswer(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// ImportQuizHandler Handler function for import quiz endpoint
+func ImportQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateImportQuizRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateImportQuizRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := ImportQuiz(r.Context(), service, request, http.MaxBytesReader(w, r.Body, MaxImportSize))
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if response.Quiz != nil {
+			w.Header().Set("Location", "/quiz/"+strconv.FormatInt(response.Quiz.QuizId, 10))
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// CreateBankQuestionHandler Handler function for create bank question endpoint
+func CreateBankQuestionHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateCreateBankQuestionRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateCreateBankQuestionRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.CreateBankQuestion(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/bank/question/"+strconv.FormatInt(response.QuestionId, 10))
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetBankQuestionHandler Handler function for get bank question endpoint
+func GetBankQuestionHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		questionId, err := parseQuestionId(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		request := dto.GetBankQuestionRequest{
+			OwnerId:    userClaims.ID,
+			QuestionId: questionId,
+		}
+
+		if err := ValidateGetBankQuestionRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetBankQuestion(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// DeleteBankQuestionHandler Handler function for delete bank question endpoint
+func DeleteBankQuestionHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		questionId, err := parseQuestionId(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+		request := dto.DeleteBankQuestionRequest{
+			OwnerId:    userClaims.ID,
+			QuestionId: questionId,
+		}
+
+		if err := ValidateDeleteBankQuestionRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if _, err := service.DeleteBankQuestion(r.Context(), &request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GenerateQuizHandler Handler function for generate quiz endpoint
+func GenerateQuizHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateGenerateQuizRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateGenerateQuizRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GenerateQuiz(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/quiz/"+strconv.FormatInt(response.QuizId, 10))
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// generateCreateQuizRequest Populate and return CreateQuizRequest for the specified owner
+func generateCreateQuizRequest(r *http.Request, ownerId int) (*dto.CreateQuizRequest, error) {
+	var request dto.CreateQuizRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+	request.OwnerId = ownerId
+
+	return &request, nil
+}
+
+// generateCreateBankQuestionRequest Populate and return CreateBankQuestionRequest for the specified owner
+func generateCreateBankQuestionRequest(r *http.Request, ownerId int) (*dto.CreateBankQuestionRequest, error) {
+	var request dto.CreateBankQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+	request.OwnerId = ownerId
+
+	return &request, nil
+}
+
+// generateGenerateQuizRequest Populate and return GenerateQuizRequest for the specified owner
+func generateGenerateQuizRequest(r *http.Request, ownerId int) (*dto.GenerateQuizRequest, error) {
+	var request dto.GenerateQuizRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+	request.OwnerId = ownerId
+
+	return &request, nil
+}
+
+// generateGetQuizzesRequest Populate and return GetQuizzesRequest for the specified owner
+func generateGetQuizzesRequest(r *http.Request, ownerId int) (*dto.GetQuizzesRequest, error) {
+	query := r.URL.Query()
+	request := dto.GetQuizzesRequest{OwnerId: ownerId}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid limit",
+			}
+		}
+		request.Limit = &limit
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid offset",
+			}
+		}
+		request.Offset = &offset
+	}
+
+	return &request, nil
+}
+
+// generateImportQuizRequest Populate and return ImportQuizRequest for the specified owner
+func generateImportQuizRequest(r *http.Request, ownerId int) (*dto.ImportQuizRequest, error) {
+	query := r.URL.Query()
+	request := dto.ImportQuizRequest{
+		OwnerId:     ownerId,
+		Format:      query.Get("format"),
+		Title:       query.Get("title"),
+		Description: query.Get("description"),
+	}
+
+	if dryRunStr := query.Get("dryRun"); dryRunStr != "" {
+		dryRun, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid dryRun",
+			}
+		}
+		request.DryRun = dryRun
+	}
+
+	return &request, nil
+}
+
+// generateUpdateQuizRequest Populate and return UpdateQuizRequest for the specified owner
+func generateUpdateQuizRequest(r *http.Request, ownerId int) (*dto.UpdateQuizRequest, error) {
+	var request dto.UpdateQuizRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+
+	quizId, err := parseQuizId(r)
+	if err != nil {
+		return nil, err
+	}
+	request.OwnerId = ownerId
+	request.QuizId = quizId
+
+	return &request, nil
+}
+
+// generateGradeAnswerRequest Populate and return GradeAnswerRequest, whose body is the answer
+func generateGradeAnswerRequest(r *http.Request) (*dto.GradeAnswerRequest, error) {
+	var request dto.GradeAnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request.Answer); err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid request body: " + err.Error(),
+		}
+	}
+
+	quizId, err := parseQuizId(r)
+	if err != nil {
+		return nil, err
+	}
+
+	questionId, err := parseQuestionId(r)
+	if err != nil {
+		return nil, err
+	}
+	request.QuizId = quizId
+	request.QuestionId = questionId
+
+	return &request, nil
+}
+
+// parseQuizId Parse the quiz id path parameter
+func parseQuizId(r *http.Request) (int64, error) {
+	quizId, err := strconv.ParseInt(chi.URLParam(r, "quizId"), 10, 64)
+	if err != nil {
+		return 0, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid quiz id",
+		}
+	}
+	return quizId, nil
+}
+
+// handleError Write the appropriate response given an error, logging errors that result in a 500
+func handleError(err error, w http.ResponseWriter, r *http.Request) {
+	var httpErr *common.HTTPError
+	if errors.As(err, &httpErr) {
+		http.Error(w, httpErr.Error(), httpErr.StatusCode)
+	} else {
+		slog.ErrorContext(r.Context(), "unhandled service error", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// parseQuestionId Parse the question id path parameter
+func parseQuestionId(r *http.Request) (int64, error) {
+	questionId, err := strconv.ParseInt(chi.URLParam(r, "questionId"), 10, 64)
+	if err != nil {
+		return 0, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid question id",
+		}
+	}
+	return questionId, nil
+}
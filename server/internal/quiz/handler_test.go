@@ -0,0 +1,622 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"quiz/dto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newAuthenticatedRequest(method string, target string, body string) *http.Request {
+	userClaims := &common.UserClaims{
+		ID:       OwnerId,
+		Username: "test-username",
+		Email:    "test@email.com",
+	}
+	ctx := context.WithValue(context.Background(), common.UsersClaimKey, userClaims)
+	return httptest.NewRequestWithContext(ctx, method, target, strings.NewReader(body))
+}
+
+func newTestQuiz(quizId int64) *dto.Quiz {
+	return &dto.Quiz{
+		QuizId:    quizId,
+		OwnerId:   OwnerId,
+		Title:     ValidTitle,
+		Questions: []dto.Question{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func TestCreateQuizHandler_Success(t *testing.T) {
+	var received *dto.CreateQuizRequest
+	service := &mockService{
+		createQuizFunc: func(context context.Context, request *dto.CreateQuizRequest) (*dto.CreateQuizResponse, error) {
+			received = request
+			return newTestQuiz(7), nil
+		},
+	}
+
+	body, _ := json.Marshal(
+		dto.CreateQuizRequest{
+			OwnerId:   OwnerId + 1,
+			Title:     ValidTitle,
+			Questions: validQuestions(),
+		},
+	)
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz", string(body))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz", CreateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	if location := recorder.Header().Get("Location"); location != "/quiz/7" {
+		t.Errorf(`Location = "%s", expected "/quiz/7"`, location)
+	}
+	if received == nil || received.OwnerId != OwnerId {
+		t.Errorf(`received = "%+v", expected the owner from the user claims "%d"`, received, OwnerId)
+	}
+
+	var response dto.CreateQuizResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if response.QuizId != 7 {
+		t.Errorf(`response.QuizId = "%d", expected "7"`, response.QuizId)
+	}
+}
+
+func TestCreateQuizHandler_MissingUserClaims(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodPost, "/quiz", strings.NewReader("{}"))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz", CreateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCreateQuizHandler_InvalidBody(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz", "{")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz", CreateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCreateQuizHandler_ValidationError(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz", `{"title": ""}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz", CreateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetQuizHandler_Success(t *testing.T) {
+	service := &mockService{
+		getQuizFunc: func(context context.Context, request *dto.GetQuizRequest) (*dto.GetQuizResponse, error) {
+			if request.OwnerId != OwnerId || request.QuizId != 7 {
+				t.Errorf(`request = "%+v", expected owner "%d" and quiz "7"`, request, OwnerId)
+			}
+			return newTestQuiz(7), nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/quiz/7", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/quiz/{quizId}", GetQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+}
+
+func TestGetQuizHandler_InvalidQuizId(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/quiz/abc", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/quiz/{quizId}", GetQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetQuizHandler_NotFound(t *testing.T) {
+	service := &mockService{
+		getQuizFunc: func(context context.Context, request *dto.GetQuizRequest) (*dto.GetQuizResponse, error) {
+			return nil, quizNotFoundError
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/quiz/7", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/quiz/{quizId}", GetQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetQuizzesHandler_Success(t *testing.T) {
+	service := &mockService{
+		getQuizzesFunc: func(context context.Context, request *dto.GetQuizzesRequest) (*dto.GetQuizzesResponse, error) {
+			if request.OwnerId != OwnerId || request.Limit == nil || *request.Limit != 5 {
+				t.Errorf(`request = "%+v", expected owner "%d" and limit "5"`, request, OwnerId)
+			}
+			return &dto.GetQuizzesResponse{Quizzes: []dto.QuizSummary{{QuizId: 1, OwnerId: OwnerId}}}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/quiz?limit=5", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/quiz", GetQuizzesHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	var response dto.GetQuizzesResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if len(response.Quizzes) != 1 {
+		t.Errorf(`len(response.Quizzes) = "%d", expected "1"`, len(response.Quizzes))
+	}
+}
+
+func TestGetQuizzesHandler_InvalidLimit(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/quiz?limit=abc", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/quiz", GetQuizzesHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestNewRouter_Authentication(t *testing.T) {
+	common.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	_, token, _ := common.TokenAuth.Encode(map[string]any{"user_id": OwnerId, "username": "owner"})
+	service := &mockService{
+		getQuizzesFunc: func(context context.Context, request *dto.GetQuizzesRequest) (*dto.GetQuizzesResponse, error) {
+			if request.OwnerId != OwnerId {
+				t.Errorf(`request.OwnerId = "%d", expected "%d"`, request.OwnerId, OwnerId)
+			}
+			return &dto.GetQuizzesResponse{Quizzes: []dto.QuizSummary{}}, nil
+		},
+	}
+	router := NewRouter(service, nil, slog.Default())
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/quiz", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v" without a token, expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/quiz", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+}
+
+func TestUpdateQuizHandler_Success(t *testing.T) {
+	service := &mockService{
+		updateQuizFunc: func(context context.Context, request *dto.UpdateQuizRequest) (*dto.UpdateQuizResponse, error) {
+			if request.OwnerId != OwnerId || request.QuizId != 7 {
+				t.Errorf(`request = "%+v", expected owner "%d" and quiz "7"`, request, OwnerId)
+			}
+			return newTestQuiz(7), nil
+		},
+	}
+
+	body, _ := json.Marshal(
+		dto.UpdateQuizRequest{
+			OwnerId:   OwnerId + 1,
+			QuizId:    8,
+			Title:     ValidTitle,
+			Questions: validQuestions(),
+		},
+	)
+	request := newAuthenticatedRequest(http.MethodPut, "/quiz/7", string(body))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Put("/quiz/{quizId}", UpdateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+}
+
+func TestUpdateQuizHandler_ServiceError(t *testing.T) {
+	service := &mockService{
+		updateQuizFunc: func(context context.Context, request *dto.UpdateQuizRequest) (*dto.UpdateQuizResponse, error) {
+			return nil, errors.New("mock error")
+		},
+	}
+
+	body, _ := json.Marshal(dto.UpdateQuizRequest{Title: ValidTitle, Questions: validQuestions()})
+	request := newAuthenticatedRequest(http.MethodPut, "/quiz/7", string(body))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Put("/quiz/{quizId}", UpdateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestDeleteQuizHandler_Success(t *testing.T) {
+	service := &mockService{
+		deleteQuizFunc: func(context context.Context, request *dto.DeleteQuizRequest) (*dto.DeleteQuizResponse, error) {
+			if request.OwnerId != OwnerId || request.QuizId != 7 {
+				t.Errorf(`request = "%+v", expected owner "%d" and quiz "7"`, request, OwnerId)
+			}
+			return &dto.DeleteQuizResponse{}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodDelete, "/quiz/7", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Delete("/quiz/{quizId}", DeleteQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNoContent)
+	}
+	if recorder.Body.Len() != 0 {
+		t.Errorf(`recorder.Body = "%s", expected empty`, recorder.Body.String())
+	}
+}
+
+func TestGetPlayableQuizHandler_Success(t *testing.T) {
+	service := &mockService{
+		getPlayableQuizFunc: func(
+			context context.Context,
+			request *dto.GetPlayableQuizRequest,
+		) (*dto.GetPlayableQuizResponse, error) {
+			if request.QuizId != 7 {
+				t.Errorf(`request.QuizId = "%d", expected "7"`, request.QuizId)
+			}
+			return &dto.PlayableQuiz{QuizId: 7, Title: ValidTitle, Questions: []dto.PlayableQuestion{}}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/quiz/7/play", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/quiz/{quizId}/play", GetPlayableQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+}
+
+func TestGradeAnswerHandler_Success(t *testing.T) {
+	service := &mockService{
+		gradeAnswerFunc: func(context context.Context, request *dto.GradeAnswerRequest) (*dto.GradeAnswerResponse, error) {
+			if request.QuizId != 7 || request.QuestionId != 3 || request.Answer.Text == nil || *request.Answer.Text != "Paris" {
+				t.Errorf(`request = "%+v", expected quiz "7", question "3" and text "Paris"`, request)
+			}
+			return &dto.GradeAnswerResponse{Correct: true, Score: 1}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz/7/question/3/grade", `{"text": "Paris"}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz/{quizId}/question/{questionId}/grade", GradeAnswerHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	var response dto.GradeAnswerResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if !response.Correct || response.Score != 1 {
+		t.Errorf(`response = "%+v", expected a correct answer`, response)
+	}
+}
+
+func TestGradeAnswerHandler_EmptyAnswer(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz/7/question/3/grade", `{}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz/{quizId}/question/{questionId}/grade", GradeAnswerHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestImportQuizHandler_Success(t *testing.T) {
+	service := &mockService{
+		createQuizFunc: func(context context.Context, request *dto.CreateQuizRequest) (*dto.CreateQuizResponse, error) {
+			if request.OwnerId != OwnerId || request.Title != ValidTitle || len(request.Questions) != 1 {
+				t.Errorf(`request = "%+v", expected 1 question titled "%s" owned by "%d"`, request, ValidTitle, OwnerId)
+			}
+			return newTestQuiz(5), nil
+		},
+	}
+
+	request := newAuthenticatedRequest(
+		http.MethodPost,
+		"/quiz/import?format=csv&title=Capital+Cities",
+		"question,answer 1,answer 2,correct\nWhat is the capital of France?,Paris,London,1\n",
+	)
+	recorder := httptest.NewRecorder()
+
+	ImportQuizHandler(service).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	if location := recorder.Header().Get("Location"); location != "/quiz/5" {
+		t.Errorf(`Location = "%s", expected "/quiz/5"`, location)
+	}
+}
+
+func TestImportQuizHandler_DryRun(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(
+		http.MethodPost,
+		"/quiz/import?format=csv&title=Capital+Cities&dryRun=true",
+		"question,answer 1,answer 2,correct\nWhat is the capital of France?,Paris,London,3\n",
+	)
+	recorder := httptest.NewRecorder()
+
+	ImportQuizHandler(service).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	var response dto.ImportQuizResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if !response.DryRun || response.Failed != 1 || len(response.Errors) != 1 || response.Errors[0].Row != 2 {
+		t.Errorf(`response = "%+v", expected a dry run with an error on row 2`, response)
+	}
+}
+
+func TestImportQuizHandler_InvalidDryRun(t *testing.T) {
+	service := &mockService{}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz/import?format=csv&title=Quiz&dryRun=maybe", "")
+	recorder := httptest.NewRecorder()
+
+	ImportQuizHandler(service).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCreateBankQuestionHandler_Success(t *testing.T) {
+	var received *dto.CreateBankQuestionRequest
+	service := &mockService{
+		createBankQuestionFunc: func(
+			context context.Context,
+			request *dto.CreateBankQuestionRequest,
+		) (*dto.CreateBankQuestionResponse, error) {
+			received = request
+			return &dto.BankQuestion{QuestionId: 5, OwnerId: request.OwnerId}, nil
+		},
+	}
+
+	body, _ := json.Marshal(
+		dto.CreateBankQuestionRequest{
+			OwnerId:       OwnerId + 1,
+			Category:      "geography",
+			Difficulty:    DifficultyEasy,
+			Language:      "en",
+			Prompt:        ValidPrompt,
+			AnswerOptions: validQuestions()[0].AnswerOptions,
+		},
+	)
+	request := newAuthenticatedRequest(http.MethodPost, "/bank/question", string(body))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/bank/question", CreateBankQuestionHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	if location := recorder.Header().Get("Location"); location != "/bank/question/5" {
+		t.Errorf(`Location = "%s", expected "/bank/question/5"`, location)
+	}
+	if received == nil || received.OwnerId != OwnerId {
+		t.Errorf(`received = "%+v", expected the owner from the user claims "%d"`, received, OwnerId)
+	}
+}
+
+func TestCreateBankQuestionHandler_ValidationError(t *testing.T) {
+	request := newAuthenticatedRequest(
+		http.MethodPost,
+		"/bank/question",
+		`{"category": "geography", "difficulty": "impossible", "language": "en", "prompt": "Capital?"}`,
+	)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/bank/question", CreateBankQuestionHandler(&mockService{}))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetBankQuestionHandler_NotFound(t *testing.T) {
+	service := &mockService{
+		getBankQuestionFunc: func(
+			context context.Context,
+			request *dto.GetBankQuestionRequest,
+		) (*dto.GetBankQuestionResponse, error) {
+			return nil, questionNotFoundError
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodGet, "/bank/question/5", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/bank/question/{questionId}", GetBankQuestionHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestDeleteBankQuestionHandler_Success(t *testing.T) {
+	service := &mockService{
+		deleteBankQuestionFunc: func(
+			context context.Context,
+			request *dto.DeleteBankQuestionRequest,
+		) (*dto.DeleteBankQuestionResponse, error) {
+			if request.OwnerId != OwnerId || request.QuestionId != 5 {
+				t.Errorf(`request = "%+v", expected owner "%d" and question "5"`, request, OwnerId)
+			}
+			return &dto.DeleteBankQuestionResponse{}, nil
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodDelete, "/bank/question/5", "")
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Delete("/bank/question/{questionId}", DeleteBankQuestionHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestGenerateQuizHandler_Success(t *testing.T) {
+	var received *dto.GenerateQuizRequest
+	service := &mockService{
+		generateQuizFunc: func(context context.Context, request *dto.GenerateQuizRequest) (*dto.GenerateQuizResponse, error) {
+			received = request
+			return newTestQuiz(9), nil
+		},
+	}
+
+	request := newAuthenticatedRequest(
+		http.MethodPost,
+		"/quiz/generate",
+		`{"count": 10, "category": "science", "difficulty": "medium"}`,
+	)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz/generate", GenerateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusCreated)
+	}
+	if location := recorder.Header().Get("Location"); location != "/quiz/9" {
+		t.Errorf(`Location = "%s", expected "/quiz/9"`, location)
+	}
+	if received == nil || received.OwnerId != OwnerId || received.Count != 10 || received.Difficulty != DifficultyMedium {
+		t.Errorf(`received = "%+v", expected 10 medium questions for owner "%d"`, received, OwnerId)
+	}
+}
+
+func TestGenerateQuizHandler_NotEnoughQuestions(t *testing.T) {
+	service := &mockService{
+		generateQuizFunc: func(context context.Context, request *dto.GenerateQuizRequest) (*dto.GenerateQuizResponse, error) {
+			return nil, fmt.Errorf(
+				"failed to generate quiz: %w",
+				&common.HTTPError{StatusCode: http.StatusConflict, Message: "only 3 questions match"},
+			)
+		},
+	}
+
+	request := newAuthenticatedRequest(http.MethodPost, "/quiz/generate", `{"count": 10}`)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/quiz/generate", GenerateQuizHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusConflict {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusConflict)
+	}
+}
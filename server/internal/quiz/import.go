@@ -0,0 +1,410 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"net/http"
+	"quiz/dto"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatOpenTriviaDB = "opentdb"
+	FormatCSV          = "csv"
+	FormatXLSX         = "xlsx"
+)
+
+// MaxImportSize Largest file accepted by the import endpoint
+const MaxImportSize = 8 << 20
+
+// maxImportRows Question rows read from an import before it is rejected: MaxQuestions plus some failed rows to
+// report, so a file with many rows is not read to the end
+const maxImportRows = MaxQuestions + 100
+
+// answerColumnRegex Matches the answer column headers of CSV and XLSX imports, "answer 1" to "answer 10"
+var answerColumnRegex = regexp.MustCompile(`^answer ([1-9]|10)$`)
+
+// correctAnswersSeparatorRegex Separates the answer numbers listed in the correct column
+var correctAnswersSeparatorRegex = regexp.MustCompile(`[\s,;]+`)
+
+// openTriviaDBResponse Body of an Open Trivia DB API response, https://opentdb.com/api_config.php
+type openTriviaDBResponse struct {
+	ResponseCode int                    `json:"response_code"`
+	Results      []openTriviaDBQuestion `json:"results"`
+}
+
+type openTriviaDBQuestion struct {
+	Type             string   `json:"type"`
+	Question         string   `json:"question"`
+	CorrectAnswer    string   `json:"correct_answer"`
+	IncorrectAnswers []string `json:"incorrect_answers"`
+}
+
+// importColumns Positions of the columns of a CSV or XLSX import, -1 for columns that are absent
+type importColumns struct {
+	prompt       int
+	answers      [MaxAnswerOptions]int
+	correct      int
+	questionType int
+	tolerance    int
+}
+
+// ImportQuiz Create a quiz from questions in an Open Trivia DB JSON response, or a CSV or XLSX file laid out like
+// a Kahoot spreadsheet. Every question goes through the same validation as POST /quiz and failures are reported
+// per row without stopping the import; the quiz is created from the questions that passed. With DryRun set, the
+// questions are only validated and previewed
+func ImportQuiz(
+	context context.Context,
+	service Service,
+	request *dto.ImportQuizRequest,
+	reader io.Reader,
+) (*dto.ImportQuizResponse, error) {
+	response := dto.ImportQuizResponse{
+		DryRun:  request.DryRun,
+		Errors:  []dto.ImportQuestionError{},
+		Preview: []dto.QuestionRequest{},
+	}
+
+	importRow := func(row int, question *dto.QuestionRequest, rowErr error) error {
+		if err := context.Err(); err != nil {
+			return fmt.Errorf("import interrupted after %d rows: %w", response.Total, err)
+		}
+		if response.Total >= maxImportRows {
+			return invalidImportError(fmt.Sprintf("an import can have at most %d rows", maxImportRows))
+		}
+		response.Total++
+
+		err := rowErr
+		if err == nil && len(response.Preview) >= MaxQuestions {
+			err = fmt.Errorf("a quiz can have at most %d questions", MaxQuestions)
+		} else if err == nil {
+			err = validateQuestion(*question)
+		}
+
+		if err != nil {
+			response.Failed++
+			response.Errors = append(
+				response.Errors, dto.ImportQuestionError{
+					Row:     row,
+					Prompt:  question.Prompt,
+					Message: err.Error(),
+				},
+			)
+			return nil
+		}
+
+		response.Preview = append(response.Preview, *question)
+		return nil
+	}
+
+	var err error
+	switch request.Format {
+	case FormatOpenTriviaDB:
+		err = readImportOpenTriviaDB(reader, importRow)
+	case FormatCSV:
+		err = readImportCSV(reader, importRow)
+	case FormatXLSX:
+		err = readImportXLSX(reader, importRow)
+	default:
+		err = validateImportFormat(request.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.DryRun {
+		response.Imported = len(response.Preview)
+		return &response, nil
+	}
+
+	if len(response.Preview) == 0 {
+		return &response, nil
+	}
+
+	quiz, err := service.CreateQuiz(
+		context, &dto.CreateQuizRequest{
+			OwnerId:     request.OwnerId,
+			Title:       request.Title,
+			Description: request.Description,
+			Questions:   response.Preview,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Imported = len(response.Preview)
+	response.Quiz = quiz
+	return &response, nil
+}
+
+// readImportOpenTriviaDB Call importRow for each question of an Open Trivia DB response, numbering rows from 1.
+// Questions and answers are HTML-decoded, as the API returns them HTML-encoded by default
+func readImportOpenTriviaDB(
+	reader io.Reader,
+	importRow func(row int, question *dto.QuestionRequest, err error) error,
+) error {
+	var response openTriviaDBResponse
+	if err := json.NewDecoder(reader).Decode(&response); err != nil {
+		return invalidImportError(fmt.Sprintf("invalid Open Trivia DB JSON: %v", err))
+	}
+
+	if response.ResponseCode != 0 {
+		return invalidImportError(fmt.Sprintf("Open Trivia DB response code %d", response.ResponseCode))
+	}
+
+	for i, result := range response.Results {
+		question := dto.QuestionRequest{Prompt: html.UnescapeString(result.Question)}
+		correctAnswer := html.UnescapeString(result.CorrectAnswer)
+
+		var rowErr error
+		switch result.Type {
+		case "multiple":
+			question.Type = SingleChoice
+			for _, answer := range result.IncorrectAnswers {
+				question.AnswerOptions = append(
+					question.AnswerOptions,
+					dto.AnswerOptionRequest{Text: html.UnescapeString(answer)},
+				)
+			}
+
+			// The correct answer comes separately; put it at a position derived from the prompt, so a preview and
+			// the import that follows it agree
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(question.Prompt))
+			position := int(hash.Sum32() % uint32(len(question.AnswerOptions)+1))
+			question.AnswerOptions = append(question.AnswerOptions, dto.AnswerOptionRequest{})
+			copy(question.AnswerOptions[position+1:], question.AnswerOptions[position:])
+			question.AnswerOptions[position] = dto.AnswerOptionRequest{Text: correctAnswer, IsCorrect: true}
+		case "boolean":
+			question.Type = TrueFalse
+			question.AnswerOptions = []dto.AnswerOptionRequest{
+				{Text: "True", IsCorrect: correctAnswer == "True"},
+				{Text: "False", IsCorrect: correctAnswer == "False"},
+			}
+		default:
+			rowErr = fmt.Errorf("unsupported Open Trivia DB question type %q", result.Type)
+		}
+
+		if err := importRow(i+1, &question, rowErr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readImportCSV Call importRow for each row of a CSV import after its header row, numbering rows by line
+func readImportCSV(reader io.Reader, importRow func(row int, question *dto.QuestionRequest, err error) error) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	table := importTable{importRow: importRow}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return invalidImportError(fmt.Sprintf("invalid CSV: %v", err))
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		row := tableRow{Line: line}
+		for column, text := range record {
+			if text != "" {
+				row.Cells = append(row.Cells, tableCell{Column: column, Text: text})
+			}
+		}
+		if err := table.readRow(row); err != nil {
+			return err
+		}
+	}
+
+	return table.finish()
+}
+
+// readImportXLSX Call importRow for each row of the first worksheet of an XLSX import after its header row,
+// numbering rows as the spreadsheet does
+func readImportXLSX(reader io.Reader, importRow func(row int, question *dto.QuestionRequest, err error) error) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return invalidImportError(fmt.Sprintf("failed to read XLSX file: %v", err))
+	}
+
+	table := importTable{importRow: importRow}
+	var rowErr error
+	err = readXLSXRows(
+		data, func(row tableRow) error {
+			rowErr = table.readRow(row)
+			return rowErr
+		},
+	)
+	if rowErr != nil {
+		return rowErr
+	} else if err != nil {
+		return invalidImportError(fmt.Sprintf("invalid XLSX: %v", err))
+	}
+
+	return table.finish()
+}
+
+// importTable Reads the rows of a CSV or XLSX import in order, finding the header row, skipping any title rows above
+// it, and calling importRow for each non-blank row below it
+type importTable struct {
+	columns   *importColumns
+	importRow func(row int, question *dto.QuestionRequest, err error) error
+}
+
+// readRow Read the next row of the import
+func (table *importTable) readRow(row tableRow) error {
+	if table.columns == nil {
+		table.columns = parseImportHeader(row.Cells)
+		return nil
+	}
+
+	if isBlankRow(row.Cells) {
+		return nil
+	}
+
+	question, err := table.columns.question(row)
+	return table.importRow(row.Line, question, err)
+}
+
+// finish Check that the import had a header row once every row has been read
+func (table *importTable) finish() error {
+	if table.columns == nil {
+		return invalidImportError("no header row with a question column was found")
+	}
+	return nil
+}
+
+// parseImportHeader Get the column positions from a header row, or nil if the row has no question column. Headers
+// are matched ignoring case and anything after " - ", so Kahoot's "Question - max 120 characters" is a question
+// column
+func parseImportHeader(cells []tableCell) *importColumns {
+	columns := importColumns{prompt: -1, correct: -1, questionType: -1, tolerance: -1}
+	for i := range columns.answers {
+		columns.answers[i] = -1
+	}
+
+	for _, cell := range cells {
+		header, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(cell.Text)), " - ")
+		header = strings.TrimSpace(header)
+
+		switch header {
+		case "question", "prompt":
+			columns.prompt = cell.Column
+		case "correct", "correct answer", "correct answers", "correct answer(s)":
+			columns.correct = cell.Column
+		case "type":
+			columns.questionType = cell.Column
+		case "tolerance":
+			columns.tolerance = cell.Column
+		default:
+			if match := answerColumnRegex.FindStringSubmatch(header); match != nil {
+				number, _ := strconv.Atoi(match[1])
+				columns.answers[number-1] = cell.Column
+			}
+		}
+	}
+
+	if columns.prompt < 0 {
+		return nil
+	}
+	return &columns
+}
+
+// question Map a row onto a question. For choice questions the correct column lists the numbers of the correct
+// answer columns, and the type defaults to single_choice or, with several correct answers, multiple_select. For
+// numeric questions it holds the answer
+func (columns *importColumns) question(row tableRow) (*dto.QuestionRequest, error) {
+	cell := func(i int) string {
+		return strings.TrimSpace(row.cell(i))
+	}
+
+	question := dto.QuestionRequest{
+		Type:   strings.ToLower(cell(columns.questionType)),
+		Prompt: cell(columns.prompt),
+	}
+
+	optionIndexes := map[int]int{}
+	for number, column := range columns.answers {
+		if text := cell(column); text != "" {
+			optionIndexes[number+1] = len(question.AnswerOptions)
+			question.AnswerOptions = append(question.AnswerOptions, dto.AnswerOptionRequest{Text: text})
+		}
+	}
+
+	if tolerance := cell(columns.tolerance); tolerance != "" {
+		value, err := strconv.ParseFloat(tolerance, 64)
+		if err != nil {
+			return &question, fmt.Errorf("invalid tolerance %q", tolerance)
+		}
+		question.NumericTolerance = &value
+	}
+
+	correct := cell(columns.correct)
+	switch question.Type {
+	case Numeric:
+		value, err := strconv.ParseFloat(correct, 64)
+		if err != nil {
+			return &question, fmt.Errorf("invalid numeric answer %q", correct)
+		}
+		question.NumericAnswer = &value
+	case Ordering, FreeText:
+	default:
+		correctCount := 0
+		for _, field := range correctAnswersSeparatorRegex.Split(correct, -1) {
+			if field == "" {
+				continue
+			}
+
+			number, err := strconv.Atoi(field)
+			if err != nil {
+				return &question, fmt.Errorf("invalid correct answer %q", field)
+			}
+
+			index, ok := optionIndexes[number]
+			if !ok {
+				return &question, fmt.Errorf("correct answer %d is empty", number)
+			}
+			question.AnswerOptions[index].IsCorrect = true
+			correctCount++
+		}
+
+		if question.Type == "" && correctCount > 1 {
+			question.Type = MultipleSelect
+		} else if question.Type == "" {
+			question.Type = SingleChoice
+		}
+	}
+
+	return &question, nil
+}
+
+// isBlankRow Check whether every cell of a row is empty
+func isBlankRow(cells []tableCell) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidImportError Create the error returned when an import file cannot be parsed at all
+func invalidImportError(message string) error {
+	return &common.HTTPError{
+		StatusCode: http.StatusBadRequest,
+		Message:    message,
+	}
+}
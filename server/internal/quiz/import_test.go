@@ -0,0 +1,311 @@
+package quiz
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"quiz/dto"
+	"strings"
+	"testing"
+)
+
+const openTriviaDBResponseJSON = `{
+	"response_code": 0,
+	"results": [
+		{
+			"type": "multiple",
+			"difficulty": "easy",
+			"category": "Geography",
+			"question": "What is the capital of France&#039;s neighbour, Belgium?",
+			"correct_answer": "Brussels",
+			"incorrect_answers": ["Antwerp", "Li&egrave;ge", "Ghent"]
+		},
+		{
+			"type": "boolean",
+			"difficulty": "easy",
+			"category": "Science",
+			"question": "&quot;H2O&quot; is the chemical formula for water.",
+			"correct_answer": "True",
+			"incorrect_answers": ["False"]
+		},
+		{
+			"type": "essay",
+			"question": "Explain plate tectonics.",
+			"correct_answer": "",
+			"incorrect_answers": []
+		}
+	]
+}`
+
+// newTestXLSX Build a single-sheet XLSX file whose first row uses shared strings and whose other rows use inline
+// strings and numbers
+func newTestXLSX(t *testing.T) []byte {
+	return newTestXLSXSheet(
+		t,
+		`<row r="2"><c r="A2" t="inlineStr"><is><t>Is the Earth round?</t></is></c>`+
+			`<c r="B2" t="inlineStr"><is><t>Yes</t></is></c><c r="C2" t="inlineStr"><is><t>No</t></is></c>`+
+			`<c r="D2"><v>1</v></c></row>`+
+			`<row r="4"><c r="A4" t="inlineStr"><is><t>How many legs does a spider have?</t></is></c>`+
+			`<c r="D4"><v>8</v></c><c r="E4" t="inlineStr"><is><t>numeric</t></is></c></row>`,
+	)
+}
+
+// newTestXLSXSheet Build a single-sheet XLSX file with a header row of shared strings followed by the rows
+func newTestXLSXSheet(t *testing.T, rows string) []byte {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Quiz" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>Question - max 120 characters</t></si><si><t>Answer 1</t></si><si><t>Answer 2</t></si>` +
+			`<si><r><t>Correct </t></r><r><t>answer(s)</t></r></si><si><t>Type</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c>` +
+			`<c r="D1" t="s"><v>3</v></c><c r="E1" t="s"><v>4</v></c></row>` + rows + `</sheetData></worksheet>`,
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for name, contents := range parts {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf(`archive.Create("%s") error = "%v", expected "<nil>"`, name, err)
+		}
+		if _, err := writer.Write([]byte(contents)); err != nil {
+			t.Fatalf(`writer.Write(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf(`archive.Close() error = "%v", expected "<nil>"`, err)
+	}
+	return buffer.Bytes()
+}
+
+func importTestQuiz(t *testing.T, service Service, format string, contents []byte, dryRun bool) *dto.ImportQuizResponse {
+	response, err := ImportQuiz(
+		context.Background(),
+		service,
+		&dto.ImportQuizRequest{OwnerId: OwnerId, Format: format, Title: ValidTitle, DryRun: dryRun},
+		bytes.NewReader(contents),
+	)
+	if err != nil {
+		t.Fatalf(`ImportQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	return response
+}
+
+func TestImportQuiz_OpenTriviaDB(t *testing.T) {
+	service, _ := newTestService()
+
+	response := importTestQuiz(t, service, FormatOpenTriviaDB, []byte(openTriviaDBResponseJSON), false)
+	if response.Total != 3 || response.Imported != 2 || response.Failed != 1 {
+		t.Fatalf(`response = "%+v", expected 3 questions with 2 imported and 1 failed`, response)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Row != 3 {
+		t.Errorf(`response.Errors = "%+v", expected an error on row 3`, response.Errors)
+	}
+	if response.Quiz == nil || len(response.Quiz.Questions) != 2 {
+		t.Fatalf(`response.Quiz = "%+v", expected a quiz with 2 questions`, response.Quiz)
+	}
+
+	multiple := response.Quiz.Questions[0]
+	if multiple.Type != SingleChoice || multiple.Prompt != "What is the capital of France's neighbour, Belgium?" {
+		t.Errorf(`multiple = "%+v", expected a decoded single_choice question`, multiple)
+	}
+	texts := map[string]bool{}
+	for _, option := range multiple.AnswerOptions {
+		texts[option.Text] = option.IsCorrect
+	}
+	if len(texts) != 4 || !texts["Brussels"] || texts["Liège"] {
+		t.Errorf(`multiple.AnswerOptions = "%+v", expected 4 options with "Brussels" correct`, multiple.AnswerOptions)
+	}
+
+	boolean := response.Quiz.Questions[1]
+	if boolean.Type != TrueFalse || boolean.Prompt != `"H2O" is the chemical formula for water.` ||
+		!boolean.AnswerOptions[0].IsCorrect || boolean.AnswerOptions[1].IsCorrect {
+		t.Errorf(`boolean = "%+v", expected a decoded true_false question with "True" correct`, boolean)
+	}
+}
+
+func TestImportQuiz_OpenTriviaDBPreviewMatchesImport(t *testing.T) {
+	service, _ := newTestService()
+
+	preview := importTestQuiz(t, service, FormatOpenTriviaDB, []byte(openTriviaDBResponseJSON), true)
+	imported := importTestQuiz(t, service, FormatOpenTriviaDB, []byte(openTriviaDBResponseJSON), false)
+	for i, option := range preview.Preview[0].AnswerOptions {
+		if imported.Preview[0].AnswerOptions[i] != option {
+			t.Errorf(`imported.Preview[0].AnswerOptions = "%+v", expected "%+v"`, imported.Preview[0].AnswerOptions, preview.Preview[0].AnswerOptions)
+			break
+		}
+	}
+}
+
+func TestImportQuiz_OpenTriviaDBResponseCode(t *testing.T) {
+	service, _ := newTestService()
+
+	_, err := ImportQuiz(
+		context.Background(),
+		service,
+		&dto.ImportQuizRequest{OwnerId: OwnerId, Format: FormatOpenTriviaDB, Title: ValidTitle},
+		strings.NewReader(`{"response_code": 1, "results": []}`),
+	)
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestImportQuiz_CSV(t *testing.T) {
+	service := &mockService{
+		createQuizFunc: func(context context.Context, request *dto.CreateQuizRequest) (*dto.CreateQuizResponse, error) {
+			t.Error(`service.CreateQuiz(...) called, expected no quiz to be created by a dry run`)
+			return nil, nil
+		},
+	}
+
+	contents := "Quiz title,,,,,\n" +
+		"\n" +
+		"Question - max 120 characters,Answer 1,Answer 2,Answer 3,Answer 4,Correct answer(s)\n" +
+		"What is the capital of France?,Paris,London,Berlin,,1\n" +
+		"Which are primes?,2,4,5,9,\"1,3\"\n" +
+		"\n" +
+		"Which is largest?,Mars,Jupiter,,,5\n" +
+		",Orphan,,,,\n"
+
+	response := importTestQuiz(t, service, FormatCSV, []byte(contents), true)
+	if !response.DryRun || response.Total != 4 || response.Imported != 2 || response.Failed != 2 || response.Quiz != nil {
+		t.Fatalf(`response = "%+v", expected a dry run of 4 rows with 2 valid and 2 failed`, response)
+	}
+	if response.Preview[0].Type != SingleChoice || !response.Preview[0].AnswerOptions[0].IsCorrect {
+		t.Errorf(`response.Preview[0] = "%+v", expected single_choice with "Paris" correct`, response.Preview[0])
+	}
+	if response.Preview[1].Type != MultipleSelect || len(response.Preview[1].AnswerOptions) != 4 {
+		t.Errorf(`response.Preview[1] = "%+v", expected multiple_select with 4 options`, response.Preview[1])
+	}
+	if len(response.Errors) != 2 || response.Errors[0].Row != 7 || response.Errors[1].Row != 8 {
+		t.Errorf(`response.Errors = "%+v", expected errors on rows 7 and 8`, response.Errors)
+	}
+}
+
+func TestImportQuiz_CSVTypes(t *testing.T) {
+	service, _ := newTestService()
+
+	contents := "question,answer 1,answer 2,answer 3,correct,type,tolerance\n" +
+		"Value of pi?,,,,3.14,numeric,0.01\n" +
+		"Order the planets from the sun,Mercury,Venus,Earth,,ordering,\n" +
+		"Who painted the Mona Lisa?,Leonardo da Vinci,Da Vinci,,,free_text,\n" +
+		"Value of e?,,,,about 2.7,numeric,\n"
+
+	response := importTestQuiz(t, service, FormatCSV, []byte(contents), false)
+	if response.Imported != 3 || response.Failed != 1 || response.Quiz == nil {
+		t.Fatalf(`response = "%+v", expected 3 imported and 1 failed`, response)
+	}
+
+	numeric := response.Quiz.Questions[0]
+	if numeric.Type != Numeric || numeric.NumericAnswer == nil || *numeric.NumericAnswer != 3.14 ||
+		numeric.NumericTolerance == nil || *numeric.NumericTolerance != 0.01 {
+		t.Errorf(`numeric = "%+v", expected 3.14 within 0.01`, numeric)
+	}
+	if response.Quiz.Questions[1].Type != Ordering || response.Quiz.Questions[2].Type != FreeText {
+		t.Errorf(`response.Quiz.Questions = "%+v", expected ordering and free_text questions`, response.Quiz.Questions)
+	}
+}
+
+func TestImportQuiz_CSVMissingHeader(t *testing.T) {
+	service, _ := newTestService()
+
+	_, err := ImportQuiz(
+		context.Background(),
+		service,
+		&dto.ImportQuizRequest{OwnerId: OwnerId, Format: FormatCSV, Title: ValidTitle},
+		strings.NewReader("What is the capital of France?,Paris,London\n"),
+	)
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestImportQuiz_NothingValid(t *testing.T) {
+	service, _ := newTestService()
+
+	response := importTestQuiz(t, service, FormatCSV, []byte("question,answer 1,correct\nLonely?,Yes,1\n"), false)
+	if response.Imported != 0 || response.Failed != 1 || response.Quiz != nil {
+		t.Errorf(`response = "%+v", expected no quiz and 1 failed`, response)
+	}
+}
+
+func TestImportQuiz_XLSX(t *testing.T) {
+	service, _ := newTestService()
+
+	response := importTestQuiz(t, service, FormatXLSX, newTestXLSX(t), false)
+	if response.Total != 2 || response.Imported != 2 || response.Quiz == nil {
+		t.Fatalf(`response = "%+v", expected 2 imported questions`, response)
+	}
+
+	choice := response.Quiz.Questions[0]
+	if choice.Prompt != "Is the Earth round?" || len(choice.AnswerOptions) != 2 || !choice.AnswerOptions[0].IsCorrect {
+		t.Errorf(`choice = "%+v", expected "Yes" correct`, choice)
+	}
+
+	numeric := response.Quiz.Questions[1]
+	if numeric.Type != Numeric || numeric.NumericAnswer == nil || *numeric.NumericAnswer != 8 {
+		t.Errorf(`numeric = "%+v", expected 8`, numeric)
+	}
+}
+
+func TestImportQuiz_XLSXWideRows(t *testing.T) {
+	service, _ := newTestService()
+
+	// Blank rows referencing the last column are skipped without padding them to 16,384 cells
+	wideRows := strings.Repeat(`<row><c r="XFD"/></row>`, 20000)
+	question := `<row><c t="inlineStr"><is><t>Is the Earth round?</t></is></c>` +
+		`<c t="inlineStr"><is><t>Yes</t></is></c><c t="inlineStr"><is><t>No</t></is></c><c><v>1</v></c>` +
+		`<c r="XFD"><v>ignored</v></c></row>`
+
+	response := importTestQuiz(t, service, FormatXLSX, newTestXLSXSheet(t, wideRows+question), true)
+	if response.Total != 1 || response.Imported != 1 || response.Preview[0].AnswerOptions[1].Text != "No" {
+		t.Errorf(`response = "%+v", expected 1 imported question`, response)
+	}
+}
+
+func TestImportQuiz_TooManyRows(t *testing.T) {
+	service, _ := newTestService()
+
+	rows := strings.Repeat(`<row><c r="XFD"><v>1</v></c></row>`, 20000)
+	_, err := ImportQuiz(
+		context.Background(),
+		service,
+		&dto.ImportQuizRequest{OwnerId: OwnerId, Format: FormatXLSX, Title: ValidTitle, DryRun: true},
+		bytes.NewReader(newTestXLSXSheet(t, rows)),
+	)
+	assertHTTPError(t, err, http.StatusBadRequest)
+
+	csvRows := "question,answer 1,answer 2,correct\n" + strings.Repeat("Is the Earth round?,Yes,No,1\n", 300)
+	_, err = ImportQuiz(
+		context.Background(),
+		service,
+		&dto.ImportQuizRequest{OwnerId: OwnerId, Format: FormatCSV, Title: ValidTitle, DryRun: true},
+		strings.NewReader(csvRows),
+	)
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestImportQuiz_InvalidXLSX(t *testing.T) {
+	service, _ := newTestService()
+
+	_, err := ImportQuiz(
+		context.Background(),
+		service,
+		&dto.ImportQuizRequest{OwnerId: OwnerId, Format: FormatXLSX, Title: ValidTitle},
+		strings.NewReader("question,answer 1\n"),
+	)
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestXLSXColumnIndex(t *testing.T) {
+	tests := map[string]int{"A1": 0, "C7": 2, "Z3": 25, "AA10": 26, "XFD1": 16383, "12": -1}
+
+	for reference, expected := range tests {
+		if actual := xlsxColumnIndex(reference); actual != expected {
+			t.Errorf(`xlsxColumnIndex("%s") = "%d", expected "%d"`, reference, actual, expected)
+		}
+	}
+}
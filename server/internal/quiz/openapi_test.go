@@ -0,0 +1,187 @@
+package quiz
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"quiz/dto"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// undocumentedRoutes Routes that serve the documentation and are intentionally left out of the spec
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+func TestOpenAPIHandler_Success(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/openapi.json", OpenAPIHandler())
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf(`Content-Type = "%s", expected "application/json"`, contentType)
+	}
+
+	var document openAPIDocument
+	if err := json.NewDecoder(recorder.Body).Decode(&document); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&document) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestDocsHandler_Success(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/docs", DocsHandler())
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	if !strings.Contains(recorder.Body.String(), "/openapi.json") {
+		t.Error(`recorder.Body does not reference "/openapi.json"`)
+	}
+}
+
+func TestOpenAPISpec_MatchesRoutes(t *testing.T) {
+	document := loadOpenAPIDocument(t)
+
+	documented := map[string]bool{}
+	for path, operations := range document.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	registered := map[string]bool{}
+	router := NewRouter(&mockService{}, nil, slog.Default())
+	err := chi.Walk(
+		router,
+		func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			key := method + " " + route
+			if !undocumentedRoutes[key] {
+				registered[key] = true
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf(`chi.Walk(router, ...) = "%v", expected "<nil>"`, err)
+	}
+
+	for route := range registered {
+		if !documented[route] {
+			t.Errorf(`route "%s" is registered but missing from openapi.json`, route)
+		}
+	}
+
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf(`route "%s" is documented in openapi.json but not registered`, route)
+		}
+	}
+}
+
+func TestOpenAPISpec_MatchesDTOs(t *testing.T) {
+	document := loadOpenAPIDocument(t)
+
+	tests := []struct {
+		schema        string
+		value         any
+		ignoredFields []string
+	}{
+		{schema: "AnswerOptionRequest", value: dto.AnswerOptionRequest{}},
+		{schema: "QuestionRequest", value: dto.QuestionRequest{}},
+		{schema: "CreateQuizRequest", value: dto.CreateQuizRequest{}, ignoredFields: []string{"ownerId"}},
+		{schema: "UpdateQuizRequest", value: dto.UpdateQuizRequest{}, ignoredFields: []string{"ownerId", "quizId"}},
+		{schema: "AnswerOption", value: dto.AnswerOption{}},
+		{schema: "Question", value: dto.Question{}},
+		{schema: "Quiz", value: dto.Quiz{}},
+		{schema: "QuizSummary", value: dto.QuizSummary{}},
+		{schema: "GetQuizzesResponse", value: dto.GetQuizzesResponse{}},
+		{schema: "PlayableAnswerOption", value: dto.PlayableAnswerOption{}},
+		{schema: "PlayableQuestion", value: dto.PlayableQuestion{}},
+		{schema: "PlayableQuiz", value: dto.PlayableQuiz{}},
+		{schema: "Answer", value: dto.Answer{}},
+		{schema: "GradeAnswerResponse", value: dto.GradeAnswerResponse{}},
+		{schema: "ImportQuestionError", value: dto.ImportQuestionError{}},
+		{schema: "ImportQuizResponse", value: dto.ImportQuizResponse{}},
+		{schema: "CreateBankQuestionRequest", value: dto.CreateBankQuestionRequest{}, ignoredFields: []string{"ownerId"}},
+		{schema: "BankQuestion", value: dto.BankQuestion{}},
+		{schema: "GenerateQuizRequest", value: dto.GenerateQuizRequest{}, ignoredFields: []string{"ownerId"}},
+	}
+
+	for _, test := range tests {
+		schema, ok := document.Components.Schemas[test.schema]
+		if !ok {
+			t.Errorf(`schema "%s" is missing from openapi.json`, test.schema)
+			continue
+		}
+
+		expected := jsonFieldNames(test.value, test.ignoredFields)
+		actual := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			actual = append(actual, property)
+		}
+		sort.Strings(actual)
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf(`schema "%s" properties = "%v", expected "%v"`, test.schema, actual, expected)
+		}
+	}
+}
+
+func loadOpenAPIDocument(t *testing.T) *openAPIDocument {
+	var document openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &document); err != nil {
+		t.Fatalf(`json.Unmarshal(openAPISpec, &document) = "%v", expected "<nil>"`, err)
+	}
+	return &document
+}
+
+func jsonFieldNames(value any, ignoredFields []string) []string {
+	ignored := map[string]bool{}
+	for _, field := range ignoredFields {
+		ignored[field] = true
+	}
+
+	valueType := reflect.TypeOf(value)
+	names := make([]string, 0, valueType.NumField())
+	for i := 0; i < valueType.NumField(); i++ {
+		name := strings.Split(valueType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || ignored[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
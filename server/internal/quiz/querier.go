@@ -0,0 +1,268 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"quiz/db/generated"
+	"quiz/db/memory"
+	"quiz/db/sqlite"
+	"time"
+)
+
+// NewService Create the service for the configured database driver, along with a function releasing its resources
+func NewService(ctx context.Context, config *common.DatabaseConfig) (*ServiceImpl, func() error, error) {
+	if config.Driver == common.DriverMemory {
+		queries := memory.New()
+		service := &ServiceImpl{
+			Queries:    queries,
+			Transactor: queries,
+		}
+		return service, func() error { return nil }, nil
+	}
+
+	database, err := common.OpenDatabase(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newQuerier := func(tx db.DBTX) db.Querier {
+		return NewTimeoutQuerier(db.New(tx), config.QueryTimeout)
+	}
+	if config.Driver == common.DriverSQLite {
+		newQuerier = func(tx db.DBTX) db.Querier {
+			return NewTimeoutQuerier(sqlite.New(tx), config.QueryTimeout)
+		}
+	}
+
+	service := &ServiceImpl{
+		Queries: newQuerier(database),
+		Transactor: &SQLTransactor{
+			Database:   database,
+			NewQuerier: newQuerier,
+			MaxRetries: common.DefaultTxMaxRetries,
+		},
+	}
+	return service, database.Close, nil
+}
+
+// TimeoutQuerier db.Querier decorator bounding every query by a timeout derived from the request context
+type TimeoutQuerier struct {
+	Queries db.Querier
+	Timeout time.Duration
+}
+
+// NewTimeoutQuerier Wrap the specified querier so each query is cancelled after the timeout
+func NewTimeoutQuerier(queries db.Querier, timeout time.Duration) *TimeoutQuerier {
+	return &TimeoutQuerier{
+		Queries: queries,
+		Timeout: timeout,
+	}
+}
+
+// CountQuizzesByOwner CountQuizzesByOwner() implementation from db.Querier interface
+func (q *TimeoutQuerier) CountQuizzesByOwner(ctx context.Context, ownerID int32) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CountQuizzesByOwner(ctx, ownerID)
+}
+
+// CreateAnswerOption CreateAnswerOption() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateAnswerOption(ctx context.Context, arg db.CreateAnswerOptionParams) (db.AnswerOption, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateAnswerOption(ctx, arg)
+}
+
+// CreateBankAnswerOption CreateBankAnswerOption() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateBankAnswerOption(
+	ctx context.Context,
+	arg db.CreateBankAnswerOptionParams,
+) (db.BankAnswerOption, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateBankAnswerOption(ctx, arg)
+}
+
+// CreateBankQuestion CreateBankQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateBankQuestion(
+	ctx context.Context,
+	arg db.CreateBankQuestionParams,
+) (db.BankQuestion, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateBankQuestion(ctx, arg)
+}
+
+// CreateBankQuestionTag CreateBankQuestionTag() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateBankQuestionTag(ctx context.Context, arg db.CreateBankQuestionTagParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateBankQuestionTag(ctx, arg)
+}
+
+// CreateQuestion CreateQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateQuestion(ctx context.Context, arg db.CreateQuestionParams) (db.Question, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateQuestion(ctx, arg)
+}
+
+// CreateQuiz CreateQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateQuiz(ctx context.Context, arg db.CreateQuizParams) (db.Quiz, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateQuiz(ctx, arg)
+}
+
+// DeleteAnswerOptionsByQuiz DeleteAnswerOptionsByQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteAnswerOptionsByQuiz(ctx context.Context, quizID int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteAnswerOptionsByQuiz(ctx, quizID)
+}
+
+// DeleteBankAnswerOptionsByQuestion DeleteBankAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteBankAnswerOptionsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteBankAnswerOptionsByQuestion(ctx, bankQuestionID)
+}
+
+// DeleteBankQuestion DeleteBankQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteBankQuestion(ctx context.Context, id int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteBankQuestion(ctx, id)
+}
+
+// DeleteBankQuestionTagsByQuestion DeleteBankQuestionTagsByQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteBankQuestionTagsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteBankQuestionTagsByQuestion(ctx, bankQuestionID)
+}
+
+// DeleteBankQuestionViewsByQuestion DeleteBankQuestionViewsByQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteBankQuestionViewsByQuestion(ctx context.Context, bankQuestionID int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteBankQuestionViewsByQuestion(ctx, bankQuestionID)
+}
+
+// DeleteQuestionsByQuiz DeleteQuestionsByQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteQuestionsByQuiz(ctx context.Context, quizID int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteQuestionsByQuiz(ctx, quizID)
+}
+
+// DeleteQuiz DeleteQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteQuiz(ctx context.Context, id int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteQuiz(ctx, id)
+}
+
+// GetAnswerOptionsByQuestion GetAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetAnswerOptionsByQuestion(ctx context.Context, questionID int64) ([]db.AnswerOption, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetAnswerOptionsByQuestion(ctx, questionID)
+}
+
+// GetAnswerOptionsByQuiz GetAnswerOptionsByQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetAnswerOptionsByQuiz(ctx context.Context, quizID int64) ([]db.AnswerOption, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetAnswerOptionsByQuiz(ctx, quizID)
+}
+
+// GetBankAnswerOptionsByQuestion GetBankAnswerOptionsByQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetBankAnswerOptionsByQuestion(
+	ctx context.Context,
+	bankQuestionID int64,
+) ([]db.BankAnswerOption, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetBankAnswerOptionsByQuestion(ctx, bankQuestionID)
+}
+
+// GetBankQuestion GetBankQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetBankQuestion(ctx context.Context, id int64) (db.BankQuestion, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetBankQuestion(ctx, id)
+}
+
+// GetBankQuestionIds GetBankQuestionIds() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetBankQuestionIds(ctx context.Context, arg db.GetBankQuestionIdsParams) ([]int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetBankQuestionIds(ctx, arg)
+}
+
+// GetBankQuestionIdsByTag GetBankQuestionIdsByTag() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetBankQuestionIdsByTag(ctx context.Context, tag string) ([]int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetBankQuestionIdsByTag(ctx, tag)
+}
+
+// GetBankQuestionTags GetBankQuestionTags() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetBankQuestionTags(ctx context.Context, bankQuestionID int64) ([]string, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetBankQuestionTags(ctx, bankQuestionID)
+}
+
+// GetQuestionsByQuiz GetQuestionsByQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetQuestionsByQuiz(ctx context.Context, quizID int64) ([]db.Question, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetQuestionsByQuiz(ctx, quizID)
+}
+
+// GetQuiz GetQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetQuiz(ctx context.Context, id int64) (db.Quiz, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetQuiz(ctx, id)
+}
+
+// GetQuizQuestion GetQuizQuestion() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetQuizQuestion(ctx context.Context, arg db.GetQuizQuestionParams) (db.Question, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetQuizQuestion(ctx, arg)
+}
+
+// GetQuizzesByOwner GetQuizzesByOwner() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetQuizzesByOwner(ctx context.Context, arg db.GetQuizzesByOwnerParams) ([]db.Quiz, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetQuizzesByOwner(ctx, arg)
+}
+
+// GetRecentBankQuestionIds GetRecentBankQuestionIds() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetRecentBankQuestionIds(
+	ctx context.Context,
+	arg db.GetRecentBankQuestionIdsParams,
+) ([]int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetRecentBankQuestionIds(ctx, arg)
+}
+
+// RecordBankQuestionView RecordBankQuestionView() implementation from db.Querier interface
+func (q *TimeoutQuerier) RecordBankQuestionView(ctx context.Context, arg db.RecordBankQuestionViewParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.RecordBankQuestionView(ctx, arg)
+}
+
+// UpdateQuiz UpdateQuiz() implementation from db.Querier interface
+func (q *TimeoutQuerier) UpdateQuiz(ctx context.Context, arg db.UpdateQuizParams) (db.Quiz, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.UpdateQuiz(ctx, arg)
+}
@@ -0,0 +1,391 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"quiz/db/generated"
+	"quiz/dto"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	SingleChoice   = "single_choice"
+	MultipleSelect = "multiple_select"
+	TrueFalse      = "true_false"
+	Numeric        = "numeric"
+	Ordering       = "ordering"
+	FreeText       = "free_text"
+)
+
+// FreeTextMinSimilarity How similar a free text answer must be to an accepted answer to count, where similarity is
+// 1 - (Levenshtein distance / length of the longer text) after ignoring case and extra whitespace. At 0.8, one typo
+// is forgiven in every five characters
+const FreeTextMinSimilarity = 0.8
+
+// QuestionType Validation, storage and grading rules for one type of question. Correct answers never leave the
+// service: players only see PlayableOptions, and Grade only reports a score
+type QuestionType interface {
+	// Validate Check the type-specific parts of an authored question
+	Validate(question *dto.QuestionRequest) error
+	// AnswerOptions Get the answer options to store for an authored question
+	AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest
+	// PlayableOptions Get the answer options to show players, in the order they are shown
+	PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption
+	// Grade Score an answer from 0 (wrong) to 1 (correct). Malformed answers score 0
+	Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64
+}
+
+var questionTypes = map[string]QuestionType{
+	SingleChoice:   singleChoiceType{},
+	MultipleSelect: multipleSelectType{},
+	TrueFalse:      trueFalseType{},
+	Numeric:        numericType{},
+	Ordering:       orderingType{},
+	FreeText:       freeTextType{},
+}
+
+// GetQuestionType Get the rules for the named question type. Questions without a type are single choice
+func GetQuestionType(name string) (QuestionType, bool) {
+	if name == "" {
+		name = SingleChoice
+	}
+	questionType, ok := questionTypes[name]
+	return questionType, ok
+}
+
+// singleChoiceType One correct answer option out of several
+type singleChoiceType struct{}
+
+// Validate Validate() implementation from QuestionType interface
+func (singleChoiceType) Validate(question *dto.QuestionRequest) error {
+	if err := validateAnswerOptions(question.AnswerOptions, MinAnswerOptions, MaxAnswerOptions); err != nil {
+		return err
+	}
+	if countCorrect(question.AnswerOptions) != 1 {
+		return errors.New("exactly one answer option must be correct")
+	}
+	return nil
+}
+
+// AnswerOptions AnswerOptions() implementation from QuestionType interface
+func (singleChoiceType) AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest {
+	return question.AnswerOptions
+}
+
+// PlayableOptions PlayableOptions() implementation from QuestionType interface
+func (singleChoiceType) PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	return toPlayableOptions(options)
+}
+
+// Grade Grade() implementation from QuestionType interface
+func (singleChoiceType) Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64 {
+	return gradeSingleOption(options, answer)
+}
+
+// multipleSelectType Any number of correct answer options, with partial credit
+type multipleSelectType struct{}
+
+// Validate Validate() implementation from QuestionType interface
+func (multipleSelectType) Validate(question *dto.QuestionRequest) error {
+	if err := validateAnswerOptions(question.AnswerOptions, MinAnswerOptions, MaxAnswerOptions); err != nil {
+		return err
+	}
+	if countCorrect(question.AnswerOptions) == 0 {
+		return errors.New("at least one answer option must be correct")
+	}
+	return nil
+}
+
+// AnswerOptions AnswerOptions() implementation from QuestionType interface
+func (multipleSelectType) AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest {
+	return question.AnswerOptions
+}
+
+// PlayableOptions PlayableOptions() implementation from QuestionType interface
+func (multipleSelectType) PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	return toPlayableOptions(options)
+}
+
+// Grade Grade() implementation from QuestionType interface. Each correct option selected earns an equal share of
+// the credit and each incorrect one selected takes a share away, so selecting everything does not pay off
+func (multipleSelectType) Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64 {
+	correct := map[int64]bool{}
+	correctCount := 0
+	for _, option := range options {
+		correct[option.ID] = option.IsCorrect
+		if option.IsCorrect {
+			correctCount++
+		}
+	}
+	if correctCount == 0 {
+		return 0
+	}
+
+	hits, misses := 0, 0
+	selected := map[int64]bool{}
+	for _, id := range answer.AnswerOptionIds {
+		if selected[id] {
+			continue
+		}
+		selected[id] = true
+
+		if correct[id] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	return max(0, float64(hits-misses)/float64(correctCount))
+}
+
+// trueFalseType A statement that is either true or false, stored as two answer options
+type trueFalseType struct{}
+
+// Validate Validate() implementation from QuestionType interface
+func (trueFalseType) Validate(question *dto.QuestionRequest) error {
+	if err := validateAnswerOptions(question.AnswerOptions, 2, 2); err != nil {
+		return err
+	}
+	if countCorrect(question.AnswerOptions) != 1 {
+		return errors.New("exactly one answer option must be correct")
+	}
+	return nil
+}
+
+// AnswerOptions AnswerOptions() implementation from QuestionType interface
+func (trueFalseType) AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest {
+	return question.AnswerOptions
+}
+
+// PlayableOptions PlayableOptions() implementation from QuestionType interface
+func (trueFalseType) PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	return toPlayableOptions(options)
+}
+
+// Grade Grade() implementation from QuestionType interface
+func (trueFalseType) Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64 {
+	return gradeSingleOption(options, answer)
+}
+
+// numericType A number, correct within an absolute tolerance
+type numericType struct{}
+
+// Validate Validate() implementation from QuestionType interface
+func (numericType) Validate(question *dto.QuestionRequest) error {
+	if len(question.AnswerOptions) > 0 {
+		return errors.New("numeric questions cannot have answer options")
+	}
+	if question.NumericAnswer == nil {
+		return errors.New("numericAnswer is required")
+	}
+	if question.NumericTolerance != nil && *question.NumericTolerance < 0 {
+		return errors.New("numericTolerance must not be negative")
+	}
+	return nil
+}
+
+// AnswerOptions AnswerOptions() implementation from QuestionType interface
+func (numericType) AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest {
+	return nil
+}
+
+// PlayableOptions PlayableOptions() implementation from QuestionType interface
+func (numericType) PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	return []dto.PlayableAnswerOption{}
+}
+
+// Grade Grade() implementation from QuestionType interface
+func (numericType) Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64 {
+	if answer.Number == nil || !question.NumericAnswer.Valid {
+		return 0
+	}
+
+	tolerance := 0.0
+	if question.NumericTolerance.Valid {
+		tolerance = question.NumericTolerance.Float64
+	}
+
+	if math.Abs(*answer.Number-question.NumericAnswer.Float64) <= tolerance {
+		return 1
+	}
+	return 0
+}
+
+// orderingType Answer options to put in order. They are authored in the correct order and shown shuffled
+type orderingType struct{}
+
+// Validate Validate() implementation from QuestionType interface
+func (orderingType) Validate(question *dto.QuestionRequest) error {
+	return validateAnswerOptions(question.AnswerOptions, MinAnswerOptions, MaxAnswerOptions)
+}
+
+// AnswerOptions AnswerOptions() implementation from QuestionType interface. The order is the answer, so no option
+// is marked correct
+func (orderingType) AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest {
+	options := make([]dto.AnswerOptionRequest, len(question.AnswerOptions))
+	for i, option := range question.AnswerOptions {
+		options[i] = dto.AnswerOptionRequest{Text: option.Text}
+	}
+	return options
+}
+
+// PlayableOptions PlayableOptions() implementation from QuestionType interface
+func (orderingType) PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	playable := toPlayableOptions(options)
+	rand.Shuffle(
+		len(playable), func(i, j int) {
+			playable[i], playable[j] = playable[j], playable[i]
+		},
+	)
+	return playable
+}
+
+// Grade Grade() implementation from QuestionType interface. Only the complete order earns credit
+func (orderingType) Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64 {
+	if len(answer.AnswerOptionIds) != len(options) {
+		return 0
+	}
+	for i, option := range options {
+		if answer.AnswerOptionIds[i] != option.ID {
+			return 0
+		}
+	}
+	return 1
+}
+
+// freeTextType A typed answer, matched against accepted variants while forgiving case, spacing and small typos
+type freeTextType struct{}
+
+// Validate Validate() implementation from QuestionType interface
+func (freeTextType) Validate(question *dto.QuestionRequest) error {
+	return validateAnswerOptions(question.AnswerOptions, 1, MaxAnswerOptions)
+}
+
+// AnswerOptions AnswerOptions() implementation from QuestionType interface. Every answer option is an accepted
+// variant, so all of them are marked correct
+func (freeTextType) AnswerOptions(question *dto.QuestionRequest) []dto.AnswerOptionRequest {
+	options := make([]dto.AnswerOptionRequest, len(question.AnswerOptions))
+	for i, option := range question.AnswerOptions {
+		options[i] = dto.AnswerOptionRequest{Text: option.Text, IsCorrect: true}
+	}
+	return options
+}
+
+// PlayableOptions PlayableOptions() implementation from QuestionType interface. The answer options are the accepted
+// answers, so none are shown
+func (freeTextType) PlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	return []dto.PlayableAnswerOption{}
+}
+
+// Grade Grade() implementation from QuestionType interface
+func (freeTextType) Grade(question db.Question, options []db.AnswerOption, answer *dto.Answer) float64 {
+	if answer.Text == nil {
+		return 0
+	}
+
+	text := normalizeFreeText(*answer.Text)
+	if text == "" {
+		return 0
+	}
+
+	for _, option := range options {
+		variant := normalizeFreeText(option.Text)
+		longest := max(utf8.RuneCountInString(text), utf8.RuneCountInString(variant))
+		similarity := 1 - float64(levenshtein(text, variant))/float64(longest)
+		if similarity >= FreeTextMinSimilarity {
+			return 1
+		}
+	}
+	return 0
+}
+
+// validateAnswerOptions Validate the number of answer options and their text
+func validateAnswerOptions(options []dto.AnswerOptionRequest, minOptions int, maxOptions int) error {
+	if len(options) < minOptions || len(options) > maxOptions {
+		if minOptions == maxOptions {
+			return fmt.Errorf("must have exactly %d answer options", minOptions)
+		}
+		return fmt.Errorf("must have between %d and %d answer options", minOptions, maxOptions)
+	}
+
+	for i, option := range options {
+		if strings.TrimSpace(option.Text) == "" {
+			return fmt.Errorf("answer option %d text is required", i+1)
+		}
+
+		if utf8.RuneCountInString(option.Text) > MaxAnswerOptionLength {
+			return fmt.Errorf("answer option %d must be at most %d characters", i+1, MaxAnswerOptionLength)
+		}
+	}
+
+	return nil
+}
+
+// countCorrect Count the answer options marked correct
+func countCorrect(options []dto.AnswerOptionRequest) int {
+	count := 0
+	for _, option := range options {
+		if option.IsCorrect {
+			count++
+		}
+	}
+	return count
+}
+
+// gradeSingleOption Grade an answer that must select exactly one correct option
+func gradeSingleOption(options []db.AnswerOption, answer *dto.Answer) float64 {
+	if len(answer.AnswerOptionIds) != 1 {
+		return 0
+	}
+	for _, option := range options {
+		if option.ID == answer.AnswerOptionIds[0] && option.IsCorrect {
+			return 1
+		}
+	}
+	return 0
+}
+
+// toPlayableOptions Convert answer options to what players see, dropping whether they are correct
+func toPlayableOptions(options []db.AnswerOption) []dto.PlayableAnswerOption {
+	playable := make([]dto.PlayableAnswerOption, len(options))
+	for i, option := range options {
+		playable[i] = dto.PlayableAnswerOption{
+			AnswerOptionId: option.ID,
+			Text:           option.Text,
+		}
+	}
+	return playable
+}
+
+// normalizeFreeText Lower-case text and collapse whitespace, so free text answers match regardless of either
+func normalizeFreeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// levenshtein Get the minimum number of single-character insertions, deletions and substitutions turning a into b
+func levenshtein(a string, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
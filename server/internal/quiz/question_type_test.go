@@ -0,0 +1,171 @@
+package quiz
+
+import (
+	"database/sql"
+	"quiz/db/generated"
+	"quiz/dto"
+	"testing"
+)
+
+func float64Pointer(value float64) *float64 {
+	return &value
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+// testOptions Stored answer options with ids 1..n, correct where specified
+func testOptions(correct ...bool) []db.AnswerOption {
+	options := make([]db.AnswerOption, len(correct))
+	for i, isCorrect := range correct {
+		options[i] = db.AnswerOption{ID: int64(i + 1), Position: int32(i + 1), Text: "Option", IsCorrect: isCorrect}
+	}
+	return options
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"paris", "", 5},
+		{"paris", "paris", 0},
+		{"paris", "pariss", 1},
+		{"kitten", "sitting", 3},
+		{"zürich", "zurich", 1},
+	}
+
+	for _, test := range tests {
+		if actual := levenshtein(test.a, test.b); actual != test.expected {
+			t.Errorf(`levenshtein("%s", "%s") = "%d", expected "%d"`, test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func TestQuestionTypes_Validate(t *testing.T) {
+	choices := func(correct ...bool) []dto.AnswerOptionRequest {
+		options := make([]dto.AnswerOptionRequest, len(correct))
+		for i, isCorrect := range correct {
+			options[i] = dto.AnswerOptionRequest{Text: "Option", IsCorrect: isCorrect}
+		}
+		return options
+	}
+
+	tests := []struct {
+		name     string
+		question dto.QuestionRequest
+		valid    bool
+	}{
+		{"single choice", dto.QuestionRequest{Type: SingleChoice, AnswerOptions: choices(true, false)}, true},
+		{"untyped", dto.QuestionRequest{AnswerOptions: choices(false, true)}, true},
+		{"single choice two correct", dto.QuestionRequest{Type: SingleChoice, AnswerOptions: choices(true, true)}, false},
+		{"multiple select", dto.QuestionRequest{Type: MultipleSelect, AnswerOptions: choices(true, true, false)}, true},
+		{"multiple select none correct", dto.QuestionRequest{Type: MultipleSelect, AnswerOptions: choices(false, false)}, false},
+		{"true false", dto.QuestionRequest{Type: TrueFalse, AnswerOptions: choices(false, true)}, true},
+		{"true false three options", dto.QuestionRequest{Type: TrueFalse, AnswerOptions: choices(false, true, false)}, false},
+		{"numeric", dto.QuestionRequest{Type: Numeric, NumericAnswer: float64Pointer(42)}, true},
+		{"numeric missing answer", dto.QuestionRequest{Type: Numeric}, false},
+		{"numeric with options", dto.QuestionRequest{Type: Numeric, NumericAnswer: float64Pointer(42), AnswerOptions: choices(true)}, false},
+		{
+			"numeric negative tolerance",
+			dto.QuestionRequest{Type: Numeric, NumericAnswer: float64Pointer(42), NumericTolerance: float64Pointer(-1)},
+			false,
+		},
+		{"ordering", dto.QuestionRequest{Type: Ordering, AnswerOptions: choices(false, false, false)}, true},
+		{"ordering one option", dto.QuestionRequest{Type: Ordering, AnswerOptions: choices(false)}, false},
+		{"free text", dto.QuestionRequest{Type: FreeText, AnswerOptions: choices(false)}, true},
+		{"free text no variants", dto.QuestionRequest{Type: FreeText}, false},
+	}
+
+	for _, test := range tests {
+		questionType, ok := GetQuestionType(test.question.Type)
+		if !ok {
+			t.Fatalf(`%s: GetQuestionType("%s") ok = "false", expected "true"`, test.name, test.question.Type)
+		}
+
+		err := questionType.Validate(&test.question)
+		if test.valid && err != nil {
+			t.Errorf(`%s: Validate(&question) = "%v", expected "<nil>"`, test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf(`%s: Validate(&question) = "<nil>", expected non-nil`, test.name)
+		}
+	}
+}
+
+func TestQuestionTypes_Grade(t *testing.T) {
+	numeric := db.Question{
+		QuestionType:     Numeric,
+		NumericAnswer:    sql.NullFloat64{Float64: 3.14, Valid: true},
+		NumericTolerance: sql.NullFloat64{Float64: 0.01, Valid: true},
+	}
+	freeTextOptions := []db.AnswerOption{
+		{ID: 1, Text: "Leonardo da Vinci", IsCorrect: true},
+		{ID: 2, Text: "Da Vinci", IsCorrect: true},
+	}
+
+	tests := []struct {
+		name     string
+		question db.Question
+		options  []db.AnswerOption
+		answer   dto.Answer
+		expected float64
+	}{
+		{"single choice correct", db.Question{QuestionType: SingleChoice}, testOptions(false, true), dto.Answer{AnswerOptionIds: []int64{2}}, 1},
+		{"single choice wrong", db.Question{QuestionType: SingleChoice}, testOptions(false, true), dto.Answer{AnswerOptionIds: []int64{1}}, 0},
+		{"single choice two picks", db.Question{QuestionType: SingleChoice}, testOptions(false, true), dto.Answer{AnswerOptionIds: []int64{1, 2}}, 0},
+		{"true false correct", db.Question{QuestionType: TrueFalse}, testOptions(true, false), dto.Answer{AnswerOptionIds: []int64{1}}, 1},
+		{"multiple select all", db.Question{QuestionType: MultipleSelect}, testOptions(true, true, false, false), dto.Answer{AnswerOptionIds: []int64{1, 2}}, 1},
+		{"multiple select partial", db.Question{QuestionType: MultipleSelect}, testOptions(true, true, false, false), dto.Answer{AnswerOptionIds: []int64{2}}, 0.5},
+		{"multiple select hit and miss", db.Question{QuestionType: MultipleSelect}, testOptions(true, true, false, false), dto.Answer{AnswerOptionIds: []int64{1, 3}}, 0},
+		{"multiple select everything", db.Question{QuestionType: MultipleSelect}, testOptions(true, false, false, false), dto.Answer{AnswerOptionIds: []int64{1, 2, 3, 4}}, 0},
+		{"multiple select duplicates", db.Question{QuestionType: MultipleSelect}, testOptions(true, true, false), dto.Answer{AnswerOptionIds: []int64{1, 1}}, 0.5},
+		{"numeric exact", numeric, nil, dto.Answer{Number: float64Pointer(3.14)}, 1},
+		{"numeric within tolerance", numeric, nil, dto.Answer{Number: float64Pointer(3.1455)}, 1},
+		{"numeric outside tolerance", numeric, nil, dto.Answer{Number: float64Pointer(3.2)}, 0},
+		{"numeric missing", numeric, nil, dto.Answer{Text: stringPointer("3.14")}, 0},
+		{"ordering correct", db.Question{QuestionType: Ordering}, testOptions(false, false, false), dto.Answer{AnswerOptionIds: []int64{1, 2, 3}}, 1},
+		{"ordering swapped", db.Question{QuestionType: Ordering}, testOptions(false, false, false), dto.Answer{AnswerOptionIds: []int64{1, 3, 2}}, 0},
+		{"ordering incomplete", db.Question{QuestionType: Ordering}, testOptions(false, false, false), dto.Answer{AnswerOptionIds: []int64{1, 2}}, 0},
+		{"free text exact", db.Question{QuestionType: FreeText}, freeTextOptions, dto.Answer{Text: stringPointer("Da Vinci")}, 1},
+		{"free text case and spacing", db.Question{QuestionType: FreeText}, freeTextOptions, dto.Answer{Text: stringPointer("  leonardo   DA vinci ")}, 1},
+		{"free text typo", db.Question{QuestionType: FreeText}, freeTextOptions, dto.Answer{Text: stringPointer("Leonardo da Vinchi")}, 1},
+		{"free text too different", db.Question{QuestionType: FreeText}, freeTextOptions, dto.Answer{Text: stringPointer("Michelangelo")}, 0},
+		{"free text empty", db.Question{QuestionType: FreeText}, freeTextOptions, dto.Answer{Text: stringPointer(" ")}, 0},
+	}
+
+	for _, test := range tests {
+		questionType, _ := GetQuestionType(test.question.QuestionType)
+		if actual := questionType.Grade(test.question, test.options, &test.answer); actual != test.expected {
+			t.Errorf(`%s: Grade(...) = "%v", expected "%v"`, test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestQuestionTypes_PlayableOptions(t *testing.T) {
+	options := testOptions(true, false, false, false, false)
+
+	ordering, _ := GetQuestionType(Ordering)
+	playable := ordering.PlayableOptions(options)
+	seen := map[int64]bool{}
+	for _, option := range playable {
+		seen[option.AnswerOptionId] = true
+	}
+	if len(playable) != len(options) || len(seen) != len(options) {
+		t.Errorf(`ordering.PlayableOptions(options) = "%+v", expected every option once`, playable)
+	}
+
+	for _, name := range []string{FreeText, Numeric} {
+		questionType, _ := GetQuestionType(name)
+		if playable := questionType.PlayableOptions(options); playable == nil || len(playable) != 0 {
+			t.Errorf(`%s: PlayableOptions(options) = "%+v", expected "[]"`, name, playable)
+		}
+	}
+}
+
+func TestGetQuestionType_Unknown(t *testing.T) {
+	if _, ok := GetQuestionType("essay"); ok {
+		t.Error(`GetQuestionType("essay") ok = "true", expected "false"`)
+	}
+}
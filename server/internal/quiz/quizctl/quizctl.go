@@ -0,0 +1,209 @@
+// Package quizctl contains the implementation of the quizctl admin CLI for managing quizzes
+package quizctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"quiz"
+	"quiz/dto"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+const usage = `Usage: quizctl [-o table|json] <command> [flags]
+
+Commands:
+  import  Create a quiz from an Open Trivia DB JSON, CSV or XLSX file (use -dry-run to only validate and preview)
+`
+
+// command A quizctl subcommand
+type command func(ctx context.Context, service quiz.Service, args []string) (any, error)
+
+var commands = map[string]command{
+	"import": importCommand,
+}
+
+// importFormatsByExtension Import format used for each file extension when -format is not set
+var importFormatsByExtension = map[string]string{
+	".json": quiz.FormatOpenTriviaDB,
+	".csv":  quiz.FormatCSV,
+	".xlsx": quiz.FormatXLSX,
+}
+
+// Run Parse the arguments, run the matching command against the service and write the result to stdout
+func Run(ctx context.Context, args []string, service quiz.Service, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("quizctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+	}
+	output := flags.String("o", OutputTable, "output format (table or json)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *output != OutputTable && *output != OutputJSON {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no command specified")
+	}
+
+	run, ok := commands[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+
+	result, err := run(ctx, service, flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	if *output == OutputJSON {
+		return writeJSON(stdout, result)
+	}
+	return writeTable(stdout, result)
+}
+
+// importCommand Create a quiz from a file and report the questions that failed
+func importCommand(ctx context.Context, service quiz.Service, args []string) (any, error) {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "path of the file to import")
+	format := flags.String("format", "", "file format (opentdb, csv or xlsx); defaults to the file extension")
+	ownerId := flags.Int("owner", -1, "id of the user who will own the quiz")
+	title := flags.String("title", "", "title of the quiz")
+	description := flags.String("description", "", "description of the quiz")
+	dryRun := flags.Bool("dry-run", false, "validate and preview the questions without creating the quiz")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *file == "" {
+		return nil, errors.New("-file is required")
+	}
+
+	if *ownerId <= 0 {
+		return nil, errors.New("-owner is required")
+	}
+
+	request := dto.ImportQuizRequest{
+		OwnerId:     *ownerId,
+		Format:      *format,
+		Title:       *title,
+		Description: *description,
+		DryRun:      *dryRun,
+	}
+	if request.Format == "" {
+		request.Format = importFormatsByExtension[strings.ToLower(filepath.Ext(*file))]
+	}
+	if err := quiz.ValidateImportQuizRequest(&request); err != nil {
+		return nil, err
+	}
+
+	reader, err := os.Open(*file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return quiz.ImportQuiz(ctx, service, &request, reader)
+}
+
+// writeJSON Write the result as indented JSON
+func writeJSON(writer io.Writer, result any) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// writeTable Write the result as aligned tables
+func writeTable(writer io.Writer, result any) error {
+	switch value := result.(type) {
+	case *dto.ImportQuizResponse:
+		return writeImportReport(writer, value)
+	default:
+		return fmt.Errorf("unsupported result type %T", result)
+	}
+}
+
+// writeImportReport Write an import summary followed by a preview of the questions and a table of the rows that
+// failed
+func writeImportReport(writer io.Writer, response *dto.ImportQuizResponse) error {
+	summary := "imported"
+	if response.DryRun {
+		summary = "valid (dry run)"
+	}
+	_, _ = fmt.Fprintf(writer, "%d rows, %d %s, %d failed\n", response.Total, response.Imported, summary, response.Failed)
+
+	if response.Quiz != nil {
+		_, _ = fmt.Fprintf(writer, "created quiz %d\n", response.Quiz.QuizId)
+	}
+
+	if len(response.Preview) > 0 {
+		_, _ = fmt.Fprintln(writer)
+		table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(table, "#\tTYPE\tPROMPT\tANSWER")
+		for i, question := range response.Preview {
+			_, _ = fmt.Fprintf(table, "%d\t%s\t%s\t%s\n", i+1, question.Type, question.Prompt, previewAnswer(question))
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(response.Errors) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(writer)
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "ROW\tPROMPT\tERROR")
+	for _, rowError := range response.Errors {
+		_, _ = fmt.Fprintf(table, "%d\t%s\t%s\n", rowError.Row, rowError.Prompt, rowError.Message)
+	}
+	return table.Flush()
+}
+
+// previewAnswer Summarize the correct answer of a question for the preview table
+func previewAnswer(question dto.QuestionRequest) string {
+	switch question.Type {
+	case quiz.Numeric:
+		answer := fmt.Sprint(*question.NumericAnswer)
+		if question.NumericTolerance != nil {
+			answer += fmt.Sprint(" ± ", *question.NumericTolerance)
+		}
+		return answer
+	case quiz.Ordering, quiz.FreeText:
+		texts := make([]string, len(question.AnswerOptions))
+		for i, option := range question.AnswerOptions {
+			texts[i] = option.Text
+		}
+		separator := " / "
+		if question.Type == quiz.Ordering {
+			separator = " > "
+		}
+		return strings.Join(texts, separator)
+	default:
+		var texts []string
+		for _, option := range question.AnswerOptions {
+			if option.IsCorrect {
+				texts = append(texts, option.Text)
+			}
+		}
+		return strings.Join(texts, ", ")
+	}
+}
@@ -0,0 +1,159 @@
+package quizctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"quiz/dto"
+	"strings"
+	"testing"
+)
+
+const csvContents = "question,answer 1,answer 2,correct\n" +
+	"What is the capital of France?,Paris,London,1\n" +
+	"What is the capital of Spain?,Madrid,,2\n"
+
+// stubService Service recording the quizzes it creates
+type stubService struct {
+	quizzes []dto.CreateQuizRequest
+}
+
+func (s *stubService) CreateQuiz(ctx context.Context, request *dto.CreateQuizRequest) (*dto.CreateQuizResponse, error) {
+	s.quizzes = append(s.quizzes, *request)
+	return &dto.Quiz{QuizId: int64(len(s.quizzes)), OwnerId: request.OwnerId, Title: request.Title}, nil
+}
+
+func (s *stubService) GetQuiz(ctx context.Context, request *dto.GetQuizRequest) (*dto.GetQuizResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GetQuizzes(ctx context.Context, request *dto.GetQuizzesRequest) (*dto.GetQuizzesResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) UpdateQuiz(ctx context.Context, request *dto.UpdateQuizRequest) (*dto.UpdateQuizResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) DeleteQuiz(ctx context.Context, request *dto.DeleteQuizRequest) (*dto.DeleteQuizResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GetPlayableQuiz(
+	ctx context.Context,
+	request *dto.GetPlayableQuizRequest,
+) (*dto.GetPlayableQuizResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GetGameQuiz(ctx context.Context, request *dto.GetGameQuizRequest) (*dto.GetGameQuizResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GradeAnswer(ctx context.Context, request *dto.GradeAnswerRequest) (*dto.GradeAnswerResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) CreateBankQuestion(
+	ctx context.Context,
+	request *dto.CreateBankQuestionRequest,
+) (*dto.CreateBankQuestionResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GetBankQuestion(
+	ctx context.Context,
+	request *dto.GetBankQuestionRequest,
+) (*dto.GetBankQuestionResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) DeleteBankQuestion(
+	ctx context.Context,
+	request *dto.DeleteBankQuestionRequest,
+) (*dto.DeleteBankQuestionResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GenerateQuiz(ctx context.Context, request *dto.GenerateQuizRequest) (*dto.GenerateQuizResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func run(t *testing.T, service *stubService, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), args, service, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func writeTestFile(t *testing.T, name string, contents string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+		t.Fatalf(`os.WriteFile(...) error = "%v", expected "<nil>"`, err)
+	}
+	return file
+}
+
+func TestRun_Import(t *testing.T) {
+	service := &stubService{}
+	file := writeTestFile(t, "capitals.csv", csvContents)
+
+	output, err := run(t, service, "import", "-file", file, "-owner", "3", "-title", "Capitals")
+	if err != nil {
+		t.Fatalf(`Run(import) error = "%v", expected "<nil>"`, err)
+	}
+
+	if len(service.quizzes) != 1 || service.quizzes[0].OwnerId != 3 || len(service.quizzes[0].Questions) != 1 {
+		t.Errorf(`service.quizzes = "%+v", expected 1 quiz with 1 question owned by "3"`, service.quizzes)
+	}
+	for _, expected := range []string{"2 rows, 1 imported, 1 failed", "created quiz 1", "What is the capital of France?", "correct answer 2 is empty"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf(`output = "%s", expected it to contain "%s"`, output, expected)
+		}
+	}
+}
+
+func TestRun_ImportDryRunJSON(t *testing.T) {
+	service := &stubService{}
+	file := writeTestFile(t, "capitals.csv", csvContents)
+
+	output, err := run(t, service, "-o", "json", "import", "-file", file, "-owner", "3", "-title", "Capitals", "-dry-run")
+	if err != nil {
+		t.Fatalf(`Run(import) error = "%v", expected "<nil>"`, err)
+	}
+
+	var report dto.ImportQuizResponse
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf(`json.Unmarshal(output, &report) error = "%v", expected "<nil>"`, err)
+	}
+	if !report.DryRun || report.Total != 2 || report.Imported != 1 || report.Failed != 1 || report.Quiz != nil {
+		t.Errorf(`report = "%+v", expected a dry run of 2 rows with 1 valid and 1 failed`, report)
+	}
+	if len(service.quizzes) != 0 {
+		t.Errorf(`len(service.quizzes) = "%d", expected "0"`, len(service.quizzes))
+	}
+}
+
+func TestRun_ImportUnknownExtension(t *testing.T) {
+	file := writeTestFile(t, "capitals.txt", csvContents)
+
+	if _, err := run(t, &stubService{}, "import", "-file", file, "-owner", "3", "-title", "Capitals"); err == nil {
+		t.Error(`Run(import) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_ImportMissingOwner(t *testing.T) {
+	file := writeTestFile(t, "capitals.csv", csvContents)
+
+	if _, err := run(t, &stubService{}, "import", "-file", file, "-title", "Capitals"); err == nil {
+		t.Error(`Run(import) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	if _, err := run(t, &stubService{}, "export"); err == nil {
+		t.Error(`Run(export) error = "<nil>", expected non-nil`)
+	}
+}
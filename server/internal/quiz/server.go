@@ -0,0 +1,123 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	_ "github.com/lib/pq" // registers "postgres" driver
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// RunServer Start the quiz service and listen for requests
+func RunServer() {
+	logger := common.NewLogger()
+	slog.SetDefault(logger)
+
+	if err := common.InitJWT(); err != nil {
+		logger.Error("Error initializing JWT", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	databaseConfig, err := common.LoadDatabaseConfig()
+	if err != nil {
+		logger.Error("Error loading database configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	service, closeDatabase, err := NewService(context.Background(), databaseConfig)
+	if err != nil {
+		logger.Error("Error establishing database connection", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer closeDatabase()
+
+	questionBankConfig, err := common.LoadQuestionBankConfig()
+	if err != nil {
+		logger.Error("Error loading question bank configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+	service.RecentQuestionWindow = questionBankConfig.RecentWindow
+
+	var users common.UserGetter
+	authConfig := common.LoadAuthConfig()
+	if authConfig.UserServiceAddr != "" {
+		client, connection, err := common.DialUserService(authConfig.UserServiceAddr)
+		if err != nil {
+			logger.Error("Error creating user service client", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer connection.Close()
+		users = client
+	} else {
+		logger.Warn("USER_SERVICE_ADDR not set, tokens are trusted without checking their user")
+	}
+
+	router := NewRouter(service, users, logger)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		logger.Error("PORT environment variable not set")
+		os.Exit(1)
+	}
+
+	if metricsPort := os.Getenv("METRICS_PORT"); metricsPort != "" {
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, common.NewMetricsHandler()); err != nil {
+				logger.Error("Metrics server error", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+
+		logger.Info("Serving metrics on port " + metricsPort)
+	}
+
+	logger.Info("Listening on port " + port)
+
+	err = http.ListenAndServe(":"+port, router)
+	if err != nil {
+		logger.Error("Server error", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+// NewRouter Create the router for the quiz service with all routes registered. Requests must carry a token of one of
+// the users, checked against the user service when users is not nil
+func NewRouter(service Service, users common.UserGetter, logger *slog.Logger) *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(middleware.RealIP)
+	router.Use(common.RequestLogger(logger))
+	router.Use(middleware.Recoverer)
+	router.Use(middleware.Timeout(time.Minute))
+
+	router.Get("/openapi.json", OpenAPIHandler())
+	router.Get("/docs", DocsHandler())
+
+	router.Group(
+		func(router chi.Router) {
+			router.Use(jwtauth.Verifier(common.TokenAuth))
+			router.Use(jwtauth.Authenticator(common.TokenAuth))
+			router.Use(common.AuthMiddleware(users))
+
+			router.Post("/quiz", CreateQuizHandler(service))
+			router.Get("/quiz", GetQuizzesHandler(service))
+			router.Post("/quiz/import", ImportQuizHandler(service))
+			router.Get("/quiz/{quizId}", GetQuizHandler(service))
+			router.Put("/quiz/{quizId}", UpdateQuizHandler(service))
+			router.Delete("/quiz/{quizId}", DeleteQuizHandler(service))
+			router.Get("/quiz/{quizId}/play", GetPlayableQuizHandler(service))
+			router.Post("/quiz/{quizId}/question/{questionId}/grade", GradeAnswerHandler(service))
+			router.Post("/quiz/generate", GenerateQuizHandler(service))
+			router.Post("/bank/question", CreateBankQuestionHandler(service))
+			router.Get("/bank/question/{questionId}", GetBankQuestionHandler(service))
+			router.Delete("/bank/question/{questionId}", DeleteBankQuestionHandler(service))
+		},
+	)
+
+	return router
+}
@@ -0,0 +1,593 @@
+// Package quiz contains the implementation for a quiz authoring RESTful service
+package quiz
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"quiz/db/generated"
+	"quiz/dto"
+	"time"
+)
+
+const DefaultQuizzesPageLimit = 20
+
+// Service Interface for performing quiz operations
+type Service interface {
+	CreateQuiz(context context.Context, request *dto.CreateQuizRequest) (*dto.CreateQuizResponse, error)
+	GetQuiz(context context.Context, request *dto.GetQuizRequest) (*dto.GetQuizResponse, error)
+	GetQuizzes(context context.Context, request *dto.GetQuizzesRequest) (*dto.GetQuizzesResponse, error)
+	UpdateQuiz(context context.Context, request *dto.UpdateQuizRequest) (*dto.UpdateQuizResponse, error)
+	DeleteQuiz(context context.Context, request *dto.DeleteQuizRequest) (*dto.DeleteQuizResponse, error)
+	GetPlayableQuiz(context context.Context, request *dto.GetPlayableQuizRequest) (*dto.GetPlayableQuizResponse, error)
+	GetGameQuiz(context context.Context, request *dto.GetGameQuizRequest) (*dto.GetGameQuizResponse, error)
+	GradeAnswer(context context.Context, request *dto.GradeAnswerRequest) (*dto.GradeAnswerResponse, error)
+	CreateBankQuestion(
+		context context.Context,
+		request *dto.CreateBankQuestionRequest,
+	) (*dto.CreateBankQuestionResponse, error)
+	GetBankQuestion(context context.Context, request *dto.GetBankQuestionRequest) (*dto.GetBankQuestionResponse, error)
+	DeleteBankQuestion(
+		context context.Context,
+		request *dto.DeleteBankQuestionRequest,
+	) (*dto.DeleteBankQuestionResponse, error)
+	GenerateQuiz(context context.Context, request *dto.GenerateQuizRequest) (*dto.GenerateQuizResponse, error)
+}
+
+// ServiceImpl Implementation for the Service
+type ServiceImpl struct {
+	Queries db.Querier
+	// Transactor Runs multi-step operations atomically. When nil, queries run directly against Queries
+	Transactor Transactor
+	// RecentQuestionWindow How long a question bank question stays out of generated quizzes for a player who saw it.
+	// When zero, common.DefaultRecentQuestionWindow is used
+	RecentQuestionWindow time.Duration
+}
+
+// quizNotFoundError Returned for quizzes that do not exist or belong to another user, so ids of other users' quizzes
+// are not revealed
+var quizNotFoundError = &common.HTTPError{
+	StatusCode: http.StatusNotFound,
+	Message:    "quiz not found",
+}
+
+// questionNotFoundError Returned for questions that do not exist or belong to another quiz
+var questionNotFoundError = &common.HTTPError{
+	StatusCode: http.StatusNotFound,
+	Message:    "question not found",
+}
+
+// runInTx Run fn inside a transaction if a Transactor is configured, otherwise directly against Queries
+func (service *ServiceImpl) runInTx(
+	context context.Context,
+	options *sql.TxOptions,
+	fn func(queries db.Querier) error,
+) error {
+	if service.Transactor == nil {
+		return fn(service.Queries)
+	}
+	return service.Transactor.RunInTx(context, options, fn)
+}
+
+// CreateQuiz Create a new quiz with its questions and answer options
+func (service *ServiceImpl) CreateQuiz(
+	context context.Context,
+	request *dto.CreateQuizRequest,
+) (*dto.CreateQuizResponse, error) {
+	var response *dto.Quiz
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			quiz, err := queries.CreateQuiz(
+				context, db.CreateQuizParams{
+					OwnerID:     int32(request.OwnerId),
+					Title:       request.Title,
+					Description: request.Description,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			questions, err := createQuestions(context, queries, quiz.ID, request.Questions)
+			if err != nil {
+				return err
+			}
+
+			response = toQuizDTO(quiz, questions)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quiz: %w", err)
+	}
+
+	return response, nil
+}
+
+// GetQuiz Retrieve a quiz owned by the requesting user, with its questions and answer options in order
+func (service *ServiceImpl) GetQuiz(
+	context context.Context,
+	request *dto.GetQuizRequest,
+) (*dto.GetQuizResponse, error) {
+	var response *dto.Quiz
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			quiz, err := getOwnedQuiz(context, queries, request.QuizId, request.OwnerId)
+			if err != nil {
+				return err
+			}
+
+			questions, err := getQuestions(context, queries, quiz.ID)
+			if err != nil {
+				return err
+			}
+
+			response = toQuizDTO(quiz, questions)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetQuizzes Retrieve the quizzes owned by the requesting user (paginated)
+func (service *ServiceImpl) GetQuizzes(
+	context context.Context,
+	request *dto.GetQuizzesRequest,
+) (*dto.GetQuizzesResponse, error) {
+	params := db.GetQuizzesByOwnerParams{
+		OwnerID: int32(request.OwnerId),
+		Limit:   DefaultQuizzesPageLimit,
+	}
+
+	if request.Limit != nil {
+		params.Limit = int32(*request.Limit)
+	}
+
+	if request.Offset != nil {
+		params.Offset = int32(*request.Offset)
+	}
+
+	// Count and page in one snapshot so the pagination links match the returned page
+	var quizCount int64
+	var quizzes []db.Quiz
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			var err error
+			quizCount, err = queries.CountQuizzesByOwner(context, params.OwnerID)
+			if err != nil {
+				return fmt.Errorf("failed to count quizzes: %w", err)
+			}
+
+			quizzes, err = queries.GetQuizzesByOwner(context, params)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve quizzes: %w", err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.GetQuizzesResponse{Quizzes: make([]dto.QuizSummary, len(quizzes))}
+	for i, quiz := range quizzes {
+		response.Quizzes[i] = dto.QuizSummary{
+			QuizId:      quiz.ID,
+			OwnerId:     int(quiz.OwnerID),
+			Title:       quiz.Title,
+			Description: quiz.Description,
+			CreatedAt:   quiz.CreatedAt,
+			UpdatedAt:   quiz.UpdatedAt,
+		}
+	}
+
+	routeUrl, err := common.GetRouteUrl(context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route url: %w", err)
+	}
+
+	if params.Offset > 0 {
+		prevLink := fmt.Sprintf("%s?limit=%d&offset=%d", routeUrl, params.Limit, max(params.Offset-params.Limit, 0))
+		response.PrevLink = &prevLink
+	}
+
+	if int64(params.Offset)+int64(params.Limit) < quizCount {
+		nextLink := fmt.Sprintf("%s?limit=%d&offset=%d", routeUrl, params.Limit, params.Offset+params.Limit)
+		response.NextLink = &nextLink
+	}
+
+	return &response, nil
+}
+
+// UpdateQuiz Replace the title, description and questions of a quiz owned by the requesting user
+func (service *ServiceImpl) UpdateQuiz(
+	context context.Context,
+	request *dto.UpdateQuizRequest,
+) (*dto.UpdateQuizResponse, error) {
+	var response *dto.Quiz
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			if _, err := getOwnedQuiz(context, queries, request.QuizId, request.OwnerId); err != nil {
+				return err
+			}
+
+			quiz, err := queries.UpdateQuiz(
+				context, db.UpdateQuizParams{
+					ID:          request.QuizId,
+					Title:       request.Title,
+					Description: request.Description,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := deleteQuestions(context, queries, quiz.ID); err != nil {
+				return err
+			}
+
+			questions, err := createQuestions(context, queries, quiz.ID, request.Questions)
+			if err != nil {
+				return err
+			}
+
+			response = toQuizDTO(quiz, questions)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update quiz: %w", err)
+	}
+
+	return response, nil
+}
+
+// DeleteQuiz Delete a quiz owned by the requesting user, along with its questions and answer options
+func (service *ServiceImpl) DeleteQuiz(
+	context context.Context,
+	request *dto.DeleteQuizRequest,
+) (*dto.DeleteQuizResponse, error) {
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			if _, err := getOwnedQuiz(context, queries, request.QuizId, request.OwnerId); err != nil {
+				return err
+			}
+
+			if err := deleteQuestions(context, queries, request.QuizId); err != nil {
+				return err
+			}
+			return queries.DeleteQuiz(context, request.QuizId)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete quiz: %w", err)
+	}
+
+	return &dto.DeleteQuizResponse{}, nil
+}
+
+// GetPlayableQuiz Retrieve a quiz as shown to players, without anything that gives away the answers. Any quiz is found
+// unless the request has an owner id
+func (service *ServiceImpl) GetPlayableQuiz(
+	context context.Context,
+	request *dto.GetPlayableQuizRequest,
+) (*dto.GetPlayableQuizResponse, error) {
+	response, err := service.GetGameQuiz(
+		context,
+		&dto.GetGameQuizRequest{QuizId: request.QuizId, OwnerId: request.OwnerId},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Quiz, nil
+}
+
+// GetGameQuiz Retrieve a quiz both as shown to players and with its questions as authored, read together so both
+// views match. Any quiz is found unless the request has an owner id
+func (service *ServiceImpl) GetGameQuiz(
+	context context.Context,
+	request *dto.GetGameQuizRequest,
+) (*dto.GetGameQuizResponse, error) {
+	var response *dto.GameQuiz
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			quiz, err := queries.GetQuiz(context, request.QuizId)
+			otherOwner := err == nil && request.OwnerId != 0 && quiz.OwnerID != int32(request.OwnerId)
+			if errors.Is(err, sql.ErrNoRows) || otherOwner {
+				return quizNotFoundError
+			} else if err != nil {
+				return fmt.Errorf("failed to retrieve quiz: %w", err)
+			}
+
+			questions, optionsByQuestion, err := getQuestionRows(context, queries, quiz.ID)
+			if err != nil {
+				return err
+			}
+
+			response = &dto.GameQuiz{
+				Quiz: dto.PlayableQuiz{
+					QuizId:      quiz.ID,
+					Title:       quiz.Title,
+					Description: quiz.Description,
+					Questions:   make([]dto.PlayableQuestion, len(questions)),
+				},
+				Questions: make([]dto.Question, len(questions)),
+			}
+			for i, question := range questions {
+				questionType, ok := GetQuestionType(question.QuestionType)
+				if !ok {
+					return fmt.Errorf("unknown question type %q", question.QuestionType)
+				}
+
+				response.Quiz.Questions[i] = dto.PlayableQuestion{
+					QuestionId:    question.ID,
+					Position:      int(question.Position),
+					Type:          question.QuestionType,
+					Prompt:        question.Prompt,
+					AnswerOptions: questionType.PlayableOptions(optionsByQuestion[question.ID]),
+				}
+				response.Questions[i] = toQuestionDTO(question, optionsByQuestion[question.ID])
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GradeAnswer Grade an answer to a question of any quiz. Only the score is returned, never the correct answer
+func (service *ServiceImpl) GradeAnswer(
+	context context.Context,
+	request *dto.GradeAnswerRequest,
+) (*dto.GradeAnswerResponse, error) {
+	var question db.Question
+	var options []db.AnswerOption
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			var err error
+			question, err = queries.GetQuizQuestion(
+				context, db.GetQuizQuestionParams{
+					ID:     request.QuestionId,
+					QuizID: request.QuizId,
+				},
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return questionNotFoundError
+			} else if err != nil {
+				return fmt.Errorf("failed to retrieve question: %w", err)
+			}
+
+			options, err = queries.GetAnswerOptionsByQuestion(context, question.ID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve answer options: %w", err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return gradeQuestion(question, options, &request.Answer)
+}
+
+// GradeQuestion Grade an answer to a question as returned by GetGameQuiz, without reading the quiz database
+func GradeQuestion(question dto.Question, answer *dto.Answer) (*dto.GradeAnswerResponse, error) {
+	options := make([]db.AnswerOption, len(question.AnswerOptions))
+	for i, option := range question.AnswerOptions {
+		options[i] = db.AnswerOption{
+			ID:         option.AnswerOptionId,
+			QuestionID: question.QuestionId,
+			Position:   int32(option.Position),
+			Text:       option.Text,
+			IsCorrect:  option.IsCorrect,
+		}
+	}
+
+	return gradeQuestion(
+		db.Question{
+			ID:               question.QuestionId,
+			Position:         int32(question.Position),
+			QuestionType:     question.Type,
+			Prompt:           question.Prompt,
+			NumericAnswer:    nullFloat64(question.NumericAnswer),
+			NumericTolerance: nullFloat64(question.NumericTolerance),
+		},
+		options,
+		answer,
+	)
+}
+
+// gradeQuestion Grade an answer to the question with the answer options
+func gradeQuestion(
+	question db.Question,
+	options []db.AnswerOption,
+	answer *dto.Answer,
+) (*dto.GradeAnswerResponse, error) {
+	questionType, ok := GetQuestionType(question.QuestionType)
+	if !ok {
+		return nil, fmt.Errorf("unknown question type %q", question.QuestionType)
+	}
+
+	score := questionType.Grade(question, options, answer)
+	return &dto.GradeAnswerResponse{
+		Correct: score == 1,
+		Score:   score,
+	}, nil
+}
+
+// getOwnedQuiz Get the quiz if it belongs to the owner, otherwise quizNotFoundError
+func getOwnedQuiz(context context.Context, queries db.Querier, quizId int64, ownerId int) (db.Quiz, error) {
+	quiz, err := queries.GetQuiz(context, quizId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && quiz.OwnerID != int32(ownerId)) {
+		return db.Quiz{}, quizNotFoundError
+	} else if err != nil {
+		return db.Quiz{}, fmt.Errorf("failed to retrieve quiz: %w", err)
+	}
+	return quiz, nil
+}
+
+// createQuestions Insert the questions and their answer options, numbering both from 1 in request order
+func createQuestions(
+	context context.Context,
+	queries db.Querier,
+	quizId int64,
+	requests []dto.QuestionRequest,
+) ([]dto.Question, error) {
+	questions := make([]dto.Question, len(requests))
+	for i, request := range requests {
+		typeName := request.Type
+		if typeName == "" {
+			typeName = SingleChoice
+		}
+		questionType, ok := GetQuestionType(typeName)
+		if !ok {
+			return nil, fmt.Errorf("unknown question type %q", typeName)
+		}
+
+		question, err := queries.CreateQuestion(
+			context, db.CreateQuestionParams{
+				QuizID:           quizId,
+				Position:         int32(i + 1),
+				Prompt:           request.Prompt,
+				QuestionType:     typeName,
+				NumericAnswer:    nullFloat64(request.NumericAnswer),
+				NumericTolerance: nullFloat64(request.NumericTolerance),
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create question: %w", err)
+		}
+
+		optionRequests := questionType.AnswerOptions(&request)
+		options := make([]db.AnswerOption, len(optionRequests))
+		for j, optionRequest := range optionRequests {
+			options[j], err = queries.CreateAnswerOption(
+				context, db.CreateAnswerOptionParams{
+					QuestionID: question.ID,
+					Position:   int32(j + 1),
+					Text:       optionRequest.Text,
+					IsCorrect:  optionRequest.IsCorrect,
+				},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create answer option: %w", err)
+			}
+		}
+
+		questions[i] = toQuestionDTO(question, options)
+	}
+	return questions, nil
+}
+
+// getQuestions Get the questions of a quiz with their answer options, both in position order
+func getQuestions(context context.Context, queries db.Querier, quizId int64) ([]dto.Question, error) {
+	questions, optionsByQuestion, err := getQuestionRows(context, queries, quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Question, len(questions))
+	for i, question := range questions {
+		result[i] = toQuestionDTO(question, optionsByQuestion[question.ID])
+	}
+	return result, nil
+}
+
+// getQuestionRows Get the questions of a quiz in position order, and their answer options in position order keyed
+// by question id
+func getQuestionRows(
+	context context.Context,
+	queries db.Querier,
+	quizId int64,
+) ([]db.Question, map[int64][]db.AnswerOption, error) {
+	questions, err := queries.GetQuestionsByQuiz(context, quizId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve questions: %w", err)
+	}
+
+	options, err := queries.GetAnswerOptionsByQuiz(context, quizId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve answer options: %w", err)
+	}
+
+	optionsByQuestion := map[int64][]db.AnswerOption{}
+	for _, option := range options {
+		optionsByQuestion[option.QuestionID] = append(optionsByQuestion[option.QuestionID], option)
+	}
+	return questions, optionsByQuestion, nil
+}
+
+// deleteQuestions Delete the questions of a quiz and their answer options. Answer options go first since SQLite does
+// not enforce the cascading foreign keys
+func deleteQuestions(context context.Context, queries db.Querier, quizId int64) error {
+	if err := queries.DeleteAnswerOptionsByQuiz(context, quizId); err != nil {
+		return fmt.Errorf("failed to delete answer options: %w", err)
+	}
+	if err := queries.DeleteQuestionsByQuiz(context, quizId); err != nil {
+		return fmt.Errorf("failed to delete questions: %w", err)
+	}
+	return nil
+}
+
+// toQuizDTO Convert a quiz row and its questions to the response DTO
+func toQuizDTO(quiz db.Quiz, questions []dto.Question) *dto.Quiz {
+	return &dto.Quiz{
+		QuizId:      quiz.ID,
+		OwnerId:     int(quiz.OwnerID),
+		Title:       quiz.Title,
+		Description: quiz.Description,
+		Questions:   questions,
+		CreatedAt:   quiz.CreatedAt,
+		UpdatedAt:   quiz.UpdatedAt,
+	}
+}
+
+// toQuestionDTO Convert a question row and its answer options to the response DTO
+func toQuestionDTO(question db.Question, options []db.AnswerOption) dto.Question {
+	result := dto.Question{
+		QuestionId:       question.ID,
+		Position:         int(question.Position),
+		Type:             question.QuestionType,
+		Prompt:           question.Prompt,
+		AnswerOptions:    make([]dto.AnswerOption, len(options)),
+		NumericAnswer:    nullableFloat64(question.NumericAnswer),
+		NumericTolerance: nullableFloat64(question.NumericTolerance),
+	}
+	for i, option := range options {
+		result.AnswerOptions[i] = dto.AnswerOption{
+			AnswerOptionId: option.ID,
+			Position:       int(option.Position),
+			Text:           option.Text,
+			IsCorrect:      option.IsCorrect,
+		}
+	}
+	return result
+}
+
+// nullFloat64 Convert an optional number to a nullable column value
+func nullFloat64(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
+// nullableFloat64 Get a pointer to the number, or nil if it is null
+func nullableFloat64(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
@@ -0,0 +1,525 @@
+package quiz
+
+import (
+    "common"
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "github.com/go-chi/chi/v5"
+    "net/http"
+    "os"
+    "quiz/db/generated"
+    "quiz/db/memory"
+    "quiz/dto"
+    "testing"
+)
+
+const (
+    BASE_URL_KEY = "BASE_URL"
+    MockUrl      = "https://mock-url"
+)
+
+func newTestService() (*ServiceImpl, *memory.Querier) {
+    queries := memory.New()
+    return &ServiceImpl{Queries: queries, Transactor: queries}, queries
+}
+
+func createTestQuiz(t *testing.T, service *ServiceImpl, ownerId int) *dto.Quiz {
+    quiz, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{
+            OwnerId:     ownerId,
+            Title:       ValidTitle,
+            Description: "Name the capital",
+            Questions:   validQuestions(),
+        },
+    )
+    if err != nil {
+        t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+    return quiz
+}
+
+func TestService_CreateQuiz_Success(t *testing.T) {
+    service, _ := newTestService()
+
+    quiz := createTestQuiz(t, service, OwnerId)
+    if quiz.QuizId == 0 || quiz.OwnerId != OwnerId || quiz.Title != ValidTitle {
+        t.Errorf(`quiz = "%+v", expected a quiz titled "%s" owned by "%d"`, quiz, ValidTitle, OwnerId)
+    }
+    if len(quiz.Questions) != 2 {
+        t.Fatalf(`len(quiz.Questions) = "%d", expected "2"`, len(quiz.Questions))
+    }
+
+    for i, question := range quiz.Questions {
+        if question.Position != i+1 {
+            t.Errorf(`quiz.Questions[%d].Position = "%d", expected "%d"`, i, question.Position, i+1)
+        }
+        for j, option := range question.AnswerOptions {
+            if option.Position != j+1 {
+                t.Errorf(`quiz.Questions[%d].AnswerOptions[%d].Position = "%d", expected "%d"`, i, j, option.Position, j+1)
+            }
+        }
+    }
+    if !quiz.Questions[1].AnswerOptions[1].IsCorrect || quiz.Questions[1].AnswerOptions[1].Text != "Tokyo" {
+        t.Errorf(`quiz.Questions[1].AnswerOptions[1] = "%+v", expected correct option "Tokyo"`, quiz.Questions[1].AnswerOptions[1])
+    }
+}
+
+func TestService_CreateQuiz_RollsBackOnError(t *testing.T) {
+    service, queries := newTestService()
+    service.Queries = nil
+    service.Transactor = &failingTransactor{queries: queries, failOn: 2}
+
+    _, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: validQuestions()},
+    )
+    if err == nil {
+        t.Fatal(`service.CreateQuiz(...) error = "<nil>", expected non-nil`)
+    }
+
+    if count, _ := queries.CountQuizzesByOwner(context.Background(), OwnerId); count != 0 {
+        t.Errorf(`queries.CountQuizzesByOwner(ctx, %d) = "%d", expected "0"`, OwnerId, count)
+    }
+}
+
+func TestService_GetQuiz_Success(t *testing.T) {
+    service, _ := newTestService()
+    created := createTestQuiz(t, service, OwnerId)
+
+    quiz, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+    if err != nil {
+        t.Fatalf(`service.GetQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    if fmt.Sprintf("%+v", quiz) != fmt.Sprintf("%+v", created) {
+        t.Errorf(`quiz = "%+v", expected "%+v"`, quiz, created)
+    }
+}
+
+func TestService_GetQuiz_OtherOwner(t *testing.T) {
+    service, _ := newTestService()
+    created := createTestQuiz(t, service, OwnerId)
+
+    _, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId + 1, QuizId: created.QuizId})
+    assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_GetQuiz_NotFound(t *testing.T) {
+    service, _ := newTestService()
+
+    _, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: 1})
+    assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_GetQuizzes_Success(t *testing.T) {
+    service, _ := newTestService()
+    for i := 0; i < 3; i++ {
+        createTestQuiz(t, service, OwnerId)
+    }
+    createTestQuiz(t, service, OwnerId+1)
+
+    os.Setenv(BASE_URL_KEY, MockUrl)
+
+    rctx := chi.NewRouteContext()
+    rctx.RoutePatterns = []string{"/quiz"}
+    ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+
+    limit := 1
+    offset := 1
+    response, err := service.GetQuizzes(ctx, &dto.GetQuizzesRequest{OwnerId: OwnerId, Limit: &limit, Offset: &offset})
+    if err != nil {
+        t.Fatalf(`service.GetQuizzes(ctx, request) error = "%v", expected "<nil>"`, err)
+    }
+
+    if len(response.Quizzes) != 1 || response.Quizzes[0].QuizId != 2 {
+        t.Errorf(`response.Quizzes = "%+v", expected the second quiz`, response.Quizzes)
+    }
+
+    routeUrl, _ := common.GetRouteUrl(ctx)
+    expectedPrevLink := fmt.Sprintf("%s?limit=%d&offset=%d", routeUrl, limit, offset-limit)
+    if response.PrevLink == nil || *response.PrevLink != expectedPrevLink {
+        t.Errorf(`response.PrevLink = "%v", expected "%v"`, response.PrevLink, expectedPrevLink)
+    }
+
+    expectedNextLink := fmt.Sprintf("%s?limit=%d&offset=%d", routeUrl, limit, offset+limit)
+    if response.NextLink == nil || *response.NextLink != expectedNextLink {
+        t.Errorf(`response.NextLink = "%v", expected "%v"`, response.NextLink, expectedNextLink)
+    }
+}
+
+func TestService_GetQuizzes_LastPage(t *testing.T) {
+    service, _ := newTestService()
+    createTestQuiz(t, service, OwnerId)
+
+    os.Setenv(BASE_URL_KEY, MockUrl)
+
+    rctx := chi.NewRouteContext()
+    rctx.RoutePatterns = []string{"/quiz"}
+    ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+
+    response, err := service.GetQuizzes(ctx, &dto.GetQuizzesRequest{OwnerId: OwnerId})
+    if err != nil {
+        t.Fatalf(`service.GetQuizzes(ctx, request) error = "%v", expected "<nil>"`, err)
+    }
+
+    if len(response.Quizzes) != 1 || response.PrevLink != nil || response.NextLink != nil {
+        t.Errorf(`response = "%+v", expected one quiz and no links`, response)
+    }
+}
+
+func TestService_UpdateQuiz_Success(t *testing.T) {
+    service, _ := newTestService()
+    created := createTestQuiz(t, service, OwnerId)
+
+    questions := validQuestions()[1:]
+    updated, err := service.UpdateQuiz(
+        context.Background(), &dto.UpdateQuizRequest{
+            OwnerId:   OwnerId,
+            QuizId:    created.QuizId,
+            Title:     "Asian Capitals",
+            Questions: questions,
+        },
+    )
+    if err != nil {
+        t.Fatalf(`service.UpdateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    if updated.Title != "Asian Capitals" || updated.Description != "" {
+        t.Errorf(`updated = "%+v", expected title "Asian Capitals" and no description`, updated)
+    }
+
+    quiz, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+    if err != nil {
+        t.Fatalf(`service.GetQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+    if len(quiz.Questions) != 1 || quiz.Questions[0].Prompt != questions[0].Prompt || quiz.Questions[0].Position != 1 {
+        t.Errorf(`quiz.Questions = "%+v", expected only "%s" at position 1`, quiz.Questions, questions[0].Prompt)
+    }
+    if len(quiz.Questions[0].AnswerOptions) != 2 {
+        t.Errorf(`len(quiz.Questions[0].AnswerOptions) = "%d", expected "2"`, len(quiz.Questions[0].AnswerOptions))
+    }
+}
+
+func TestService_UpdateQuiz_OtherOwner(t *testing.T) {
+    service, _ := newTestService()
+    created := createTestQuiz(t, service, OwnerId)
+
+    _, err := service.UpdateQuiz(
+        context.Background(), &dto.UpdateQuizRequest{
+            OwnerId:   OwnerId + 1,
+            QuizId:    created.QuizId,
+            Title:     "Stolen",
+            Questions: validQuestions(),
+        },
+    )
+    assertHTTPError(t, err, http.StatusNotFound)
+
+    quiz, _ := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+    if quiz.Title != ValidTitle {
+        t.Errorf(`quiz.Title = "%s", expected "%s"`, quiz.Title, ValidTitle)
+    }
+}
+
+func TestService_DeleteQuiz_Success(t *testing.T) {
+    service, queries := newTestService()
+    created := createTestQuiz(t, service, OwnerId)
+
+    if _, err := service.DeleteQuiz(
+        context.Background(),
+        &dto.DeleteQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId},
+    ); err != nil {
+        t.Fatalf(`service.DeleteQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    _, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+    assertHTTPError(t, err, http.StatusNotFound)
+
+    options, _ := queries.GetAnswerOptionsByQuiz(context.Background(), created.QuizId)
+    questions, _ := queries.GetQuestionsByQuiz(context.Background(), created.QuizId)
+    if len(options) != 0 || len(questions) != 0 {
+        t.Errorf(`questions, options = "%v", "%v", expected none`, questions, options)
+    }
+}
+
+func TestService_DeleteQuiz_OtherOwner(t *testing.T) {
+    service, _ := newTestService()
+    created := createTestQuiz(t, service, OwnerId)
+
+    _, err := service.DeleteQuiz(context.Background(), &dto.DeleteQuizRequest{OwnerId: OwnerId + 1, QuizId: created.QuizId})
+    assertHTTPError(t, err, http.StatusNotFound)
+}
+
+// typedQuestions Get one question of every type but single choice
+func typedQuestions() []dto.QuestionRequest {
+    return []dto.QuestionRequest{
+        {
+            Type:   MultipleSelect,
+            Prompt: "Which of these are primary colours?",
+            AnswerOptions: []dto.AnswerOptionRequest{
+                {Text: "Red", IsCorrect: true},
+                {Text: "Green"},
+                {Text: "Blue", IsCorrect: true},
+            },
+        },
+        {
+            Type:          TrueFalse,
+            Prompt:        "The Pacific is the largest ocean",
+            AnswerOptions: []dto.AnswerOptionRequest{{Text: "True", IsCorrect: true}, {Text: "False"}},
+        },
+        {
+            Type:             Numeric,
+            Prompt:           "How many metres high is the Eiffel Tower?",
+            NumericAnswer:    float64Pointer(330),
+            NumericTolerance: float64Pointer(5),
+        },
+        {
+            Type:          Ordering,
+            Prompt:        "Order these planets from the Sun",
+            AnswerOptions: []dto.AnswerOptionRequest{{Text: "Mercury", IsCorrect: true}, {Text: "Venus"}, {Text: "Earth"}},
+        },
+        {
+            Type:          FreeText,
+            Prompt:        "Who painted the Mona Lisa?",
+            AnswerOptions: []dto.AnswerOptionRequest{{Text: "Leonardo da Vinci"}, {Text: "Da Vinci"}},
+        },
+    }
+}
+
+func TestService_CreateQuiz_QuestionTypes(t *testing.T) {
+    service, _ := newTestService()
+
+    quiz, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: typedQuestions()},
+    )
+    if err != nil {
+        t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    numeric := quiz.Questions[2]
+    if numeric.Type != Numeric || numeric.NumericAnswer == nil || *numeric.NumericAnswer != 330 ||
+        len(numeric.AnswerOptions) != 0 {
+        t.Errorf(`quiz.Questions[2] = "%+v", expected a numeric question with answer "330"`, numeric)
+    }
+
+    for _, option := range quiz.Questions[3].AnswerOptions {
+        if option.IsCorrect {
+            t.Errorf(`ordering option "%s" IsCorrect = "true", expected "false"`, option.Text)
+        }
+    }
+
+    for _, option := range quiz.Questions[4].AnswerOptions {
+        if !option.IsCorrect {
+            t.Errorf(`free text option "%s" IsCorrect = "false", expected "true"`, option.Text)
+        }
+    }
+
+    untyped := createTestQuiz(t, service, OwnerId)
+    if untyped.Questions[0].Type != SingleChoice {
+        t.Errorf(`untyped.Questions[0].Type = "%s", expected "%s"`, untyped.Questions[0].Type, SingleChoice)
+    }
+}
+
+func TestService_GetPlayableQuiz_HidesAnswers(t *testing.T) {
+    service, _ := newTestService()
+    created, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: typedQuestions()},
+    )
+    if err != nil {
+        t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    // Any signed-in user can play a quiz, not just its owner
+    quiz, err := service.GetPlayableQuiz(context.Background(), &dto.GetPlayableQuizRequest{QuizId: created.QuizId})
+    if err != nil {
+        t.Fatalf(`service.GetPlayableQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    if len(quiz.Questions) != len(created.Questions) {
+        t.Fatalf(`len(quiz.Questions) = "%d", expected "%d"`, len(quiz.Questions), len(created.Questions))
+    }
+
+    if len(quiz.Questions[0].AnswerOptions) != 3 || quiz.Questions[0].AnswerOptions[2].Text != "Blue" {
+        t.Errorf(`quiz.Questions[0].AnswerOptions = "%+v", expected the options in order`, quiz.Questions[0].AnswerOptions)
+    }
+
+    if len(quiz.Questions[3].AnswerOptions) != 3 {
+        t.Errorf(`len(quiz.Questions[3].AnswerOptions) = "%d", expected "3"`, len(quiz.Questions[3].AnswerOptions))
+    }
+
+    for _, i := range []int{2, 4} {
+        if len(quiz.Questions[i].AnswerOptions) != 0 {
+            t.Errorf(`quiz.Questions[%d].AnswerOptions = "%+v", expected none`, i, quiz.Questions[i].AnswerOptions)
+        }
+    }
+}
+
+func TestService_GetPlayableQuiz_NotFound(t *testing.T) {
+    service, _ := newTestService()
+
+    _, err := service.GetPlayableQuiz(context.Background(), &dto.GetPlayableQuizRequest{QuizId: 42})
+    assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestService_GetPlayableQuiz_OtherOwner(t *testing.T) {
+    service, _ := newTestService()
+    quiz, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: typedQuestions()},
+    )
+    if err != nil {
+        t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    request := dto.GetPlayableQuizRequest{QuizId: quiz.QuizId, OwnerId: OwnerId + 1}
+    _, err = service.GetPlayableQuiz(context.Background(), &request)
+    assertHTTPError(t, err, http.StatusNotFound)
+
+    request.OwnerId = OwnerId
+    if _, err := service.GetPlayableQuiz(context.Background(), &request); err != nil {
+        t.Errorf(`service.GetPlayableQuiz(...) error = "%v", expected "<nil>" for the owner`, err)
+    }
+}
+
+func TestService_GradeAnswer_Success(t *testing.T) {
+    service, _ := newTestService()
+    quiz, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: typedQuestions()},
+    )
+    if err != nil {
+        t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    colours := quiz.Questions[0].AnswerOptions
+    planets := quiz.Questions[3].AnswerOptions
+    tests := []struct {
+        question int
+        answer   dto.Answer
+        expected dto.GradeAnswerResponse
+    }{
+        {0, dto.Answer{AnswerOptionIds: []int64{colours[0].AnswerOptionId, colours[2].AnswerOptionId}}, dto.GradeAnswerResponse{Correct: true, Score: 1}},
+        {0, dto.Answer{AnswerOptionIds: []int64{colours[2].AnswerOptionId}}, dto.GradeAnswerResponse{Score: 0.5}},
+        {2, dto.Answer{Number: float64Pointer(326)}, dto.GradeAnswerResponse{Correct: true, Score: 1}},
+        {
+            3,
+            dto.Answer{AnswerOptionIds: []int64{planets[0].AnswerOptionId, planets[1].AnswerOptionId, planets[2].AnswerOptionId}},
+            dto.GradeAnswerResponse{Correct: true, Score: 1},
+        },
+        {4, dto.Answer{Text: stringPointer("da vinci")}, dto.GradeAnswerResponse{Correct: true, Score: 1}},
+        {4, dto.Answer{Text: stringPointer("Raphael")}, dto.GradeAnswerResponse{}},
+    }
+
+    for _, test := range tests {
+        response, err := service.GradeAnswer(
+            context.Background(), &dto.GradeAnswerRequest{
+                QuizId:     quiz.QuizId,
+                QuestionId: quiz.Questions[test.question].QuestionId,
+                Answer:     test.answer,
+            },
+        )
+        if err != nil {
+            t.Fatalf(`service.GradeAnswer(...) error = "%v", expected "<nil>"`, err)
+        }
+
+        if *response != test.expected {
+            t.Errorf(`question %d: response = "%+v", expected "%+v"`, test.question, response, test.expected)
+        }
+    }
+}
+
+func TestGradeQuestion_AfterQuizDeleted(t *testing.T) {
+    service, _ := newTestService()
+    quiz, err := service.CreateQuiz(
+        context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: typedQuestions()},
+    )
+    if err != nil {
+        t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    game, err := service.GetGameQuiz(context.Background(), &dto.GetGameQuizRequest{QuizId: quiz.QuizId, OwnerId: OwnerId})
+    if err != nil {
+        t.Fatalf(`service.GetGameQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+    if len(game.Questions) != len(quiz.Questions) || len(game.Quiz.Questions) != len(quiz.Questions) {
+        t.Fatalf(`game = "%+v", expected both views of the "%d" questions`, game, len(quiz.Questions))
+    }
+
+    _, err = service.DeleteQuiz(context.Background(), &dto.DeleteQuizRequest{OwnerId: OwnerId, QuizId: quiz.QuizId})
+    if err != nil {
+        t.Fatalf(`service.DeleteQuiz(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    colours := game.Questions[0].AnswerOptions
+    tests := []struct {
+        question int
+        answer   dto.Answer
+        expected dto.GradeAnswerResponse
+    }{
+        {0, dto.Answer{AnswerOptionIds: []int64{colours[0].AnswerOptionId, colours[2].AnswerOptionId}}, dto.GradeAnswerResponse{Correct: true, Score: 1}},
+        {0, dto.Answer{AnswerOptionIds: []int64{colours[2].AnswerOptionId}}, dto.GradeAnswerResponse{Score: 0.5}},
+        {2, dto.Answer{Number: float64Pointer(326)}, dto.GradeAnswerResponse{Correct: true, Score: 1}},
+        {4, dto.Answer{Text: stringPointer("da vinci")}, dto.GradeAnswerResponse{Correct: true, Score: 1}},
+        {4, dto.Answer{Text: stringPointer("Raphael")}, dto.GradeAnswerResponse{}},
+    }
+
+    for _, test := range tests {
+        response, err := GradeQuestion(game.Questions[test.question], &test.answer)
+        if err != nil {
+            t.Fatalf(`GradeQuestion(...) error = "%v", expected "<nil>"`, err)
+        }
+
+        if *response != test.expected {
+            t.Errorf(`question %d: response = "%+v", expected "%+v"`, test.question, response, test.expected)
+        }
+    }
+}
+
+func TestService_GradeAnswer_QuestionOfOtherQuiz(t *testing.T) {
+    service, _ := newTestService()
+    first := createTestQuiz(t, service, OwnerId)
+    second := createTestQuiz(t, service, OwnerId)
+
+    _, err := service.GradeAnswer(
+        context.Background(), &dto.GradeAnswerRequest{
+            QuizId:     second.QuizId,
+            QuestionId: first.Questions[0].QuestionId,
+            Answer:     dto.Answer{AnswerOptionIds: []int64{first.Questions[0].AnswerOptions[0].AnswerOptionId}},
+        },
+    )
+    assertHTTPError(t, err, http.StatusNotFound)
+}
+
+// failingTransactor Transactor running against an in-memory querier that fails the nth CreateAnswerOption call
+type failingTransactor struct {
+    queries *memory.Querier
+    failOn  int
+}
+
+func (transactor *failingTransactor) RunInTx(
+    ctx context.Context,
+    options *sql.TxOptions,
+    fn func(queries db.Querier) error,
+) error {
+    return transactor.queries.RunInTx(
+        ctx, options, func(queries db.Querier) error {
+            return fn(&failingQuerier{Querier: queries, failOn: transactor.failOn})
+        },
+    )
+}
+
+// failingQuerier db.Querier failing the nth CreateAnswerOption call
+type failingQuerier struct {
+    db.Querier
+    failOn int
+    calls  int
+}
+
+func (q *failingQuerier) CreateAnswerOption(ctx context.Context, arg db.CreateAnswerOptionParams) (
+    db.AnswerOption,
+    error,
+) {
+    q.calls++
+    if q.calls == q.failOn {
+        return db.AnswerOption{}, errors.New("connection reset")
+    }
+    return q.Querier.CreateAnswerOption(ctx, arg)
+}
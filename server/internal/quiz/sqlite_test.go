@@ -0,0 +1,216 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"quiz/db/generated"
+	"quiz/db/sqlite"
+	"quiz/dto"
+	"testing"
+	"time"
+)
+
+func newSQLiteService(t *testing.T) *ServiceImpl {
+	config := &common.DatabaseConfig{
+		Driver:         common.DriverSQLite,
+		Url:            ":memory:",
+		ConnectTimeout: time.Second,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+
+	database, err := common.OpenDatabase(context.Background(), config)
+	if err != nil {
+		t.Fatalf(`common.OpenDatabase(...) error = "%v", expected "<nil>"`, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = database.Close()
+		},
+	)
+
+	err = common.RunServiceMigrations(
+		context.Background(),
+		database,
+		common.DriverSQLite,
+		Migrations(common.DriverSQLite),
+		MigrationTable,
+		common.MigrateUp,
+		io.Discard,
+	)
+	if err != nil {
+		t.Fatalf(`common.RunServiceMigrations(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	return &ServiceImpl{
+		Queries: sqlite.New(database),
+		Transactor: &SQLTransactor{
+			Database: database,
+			NewQuerier: func(tx db.DBTX) db.Querier {
+				return sqlite.New(tx)
+			},
+			MaxRetries: common.DefaultTxMaxRetries,
+		},
+	}
+}
+
+func TestSQLite_QuizLifecycle(t *testing.T) {
+	service := newSQLiteService(t)
+	created := createTestQuiz(t, service, OwnerId)
+
+	quiz, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+	if err != nil {
+		t.Fatalf(`service.GetQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(quiz.Questions) != 2 || quiz.Questions[0].Prompt != ValidPrompt {
+		t.Fatalf(`quiz.Questions = "%+v", expected "%s" first`, quiz.Questions, ValidPrompt)
+	}
+	if options := quiz.Questions[0].AnswerOptions; len(options) != 3 || options[0].Text != "Paris" || !options[0].IsCorrect {
+		t.Errorf(`quiz.Questions[0].AnswerOptions = "%+v", expected "Paris" first and correct`, options)
+	}
+
+	// Swapping the questions rewrites their positions, which must not collide with the rows being replaced
+	questions := validQuestions()
+	questions[0], questions[1] = questions[1], questions[0]
+	_, err = service.UpdateQuiz(
+		context.Background(), &dto.UpdateQuizRequest{
+			OwnerId:   OwnerId,
+			QuizId:    created.QuizId,
+			Title:     ValidTitle,
+			Questions: questions,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.UpdateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	quiz, err = service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+	if err != nil {
+		t.Fatalf(`service.GetQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(quiz.Questions) != 2 || quiz.Questions[1].Prompt != ValidPrompt {
+		t.Errorf(`quiz.Questions = "%+v", expected "%s" second`, quiz.Questions, ValidPrompt)
+	}
+	if quiz.UpdatedAt.Before(created.UpdatedAt) {
+		t.Errorf(`quiz.UpdatedAt = "%v", expected after "%v"`, quiz.UpdatedAt, created.UpdatedAt)
+	}
+
+	if _, err := service.DeleteQuiz(
+		context.Background(),
+		&dto.DeleteQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId},
+	); err != nil {
+		t.Fatalf(`service.DeleteQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err = service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: created.QuizId})
+	assertHTTPError(t, err, http.StatusNotFound)
+
+	options, err := service.Queries.GetAnswerOptionsByQuiz(context.Background(), created.QuizId)
+	if err != nil || len(options) != 0 {
+		t.Errorf(`queries.GetAnswerOptionsByQuiz(...) = "%v", "%v", expected none`, options, err)
+	}
+}
+
+func TestSQLite_GetQuizzesByOwner(t *testing.T) {
+	service := newSQLiteService(t)
+	for _, ownerId := range []int{OwnerId, OwnerId + 1, OwnerId} {
+		createTestQuiz(t, service, ownerId)
+	}
+
+	count, err := service.Queries.CountQuizzesByOwner(context.Background(), OwnerId)
+	if err != nil || count != 2 {
+		t.Errorf(`queries.CountQuizzesByOwner(ctx, %d) = "%d", "%v", expected "2", "<nil>"`, OwnerId, count, err)
+	}
+
+	quizzes, err := service.Queries.GetQuizzesByOwner(
+		context.Background(),
+		db.GetQuizzesByOwnerParams{OwnerID: OwnerId, Limit: 10},
+	)
+	if err != nil {
+		t.Fatalf(`queries.GetQuizzesByOwner(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(quizzes) != 2 || quizzes[0].ID != 1 || quizzes[1].ID != 3 {
+		t.Errorf(`quizzes = "%+v", expected quizzes "1" and "3"`, quizzes)
+	}
+
+	if _, err := service.Queries.GetQuiz(context.Background(), 99); err != sql.ErrNoRows {
+		t.Errorf(`queries.GetQuiz(ctx, 99) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestSQLite_GradeAnswer(t *testing.T) {
+	service := newSQLiteService(t)
+	quiz, err := service.CreateQuiz(
+		context.Background(), &dto.CreateQuizRequest{OwnerId: OwnerId, Title: ValidTitle, Questions: typedQuestions()},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	stored, err := service.GetQuiz(context.Background(), &dto.GetQuizRequest{OwnerId: OwnerId, QuizId: quiz.QuizId})
+	if err != nil {
+		t.Fatalf(`service.GetQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	numeric := stored.Questions[2]
+	if numeric.Type != Numeric || numeric.NumericTolerance == nil || *numeric.NumericTolerance != 5 {
+		t.Errorf(`stored.Questions[2] = "%+v", expected a numeric question with tolerance "5"`, numeric)
+	}
+
+	response, err := service.GradeAnswer(
+		context.Background(), &dto.GradeAnswerRequest{
+			QuizId:     quiz.QuizId,
+			QuestionId: numeric.QuestionId,
+			Answer:     dto.Answer{Number: float64Pointer(334)},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.GradeAnswer(...) error = "%v", expected "<nil>"`, err)
+	}
+	if !response.Correct {
+		t.Errorf(`response = "%+v", expected a correct answer`, response)
+	}
+}
+
+func TestSQLite_GenerateQuiz(t *testing.T) {
+	service := newSQLiteService(t)
+	for _, difficulty := range []string{DifficultyEasy, DifficultyEasy, DifficultyHard} {
+		_, err := service.CreateBankQuestion(
+			context.Background(), &dto.CreateBankQuestionRequest{
+				OwnerId:          OwnerId,
+				Category:         "Science",
+				Difficulty:       difficulty,
+				Language:         "en",
+				Tags:             []string{"physics"},
+				Type:             Numeric,
+				Prompt:           "How many metres per second is the speed of sound?",
+				NumericAnswer:    float64Pointer(343),
+				NumericTolerance: float64Pointer(5),
+			},
+		)
+		if err != nil {
+			t.Fatalf(`service.CreateBankQuestion(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	request := &dto.GenerateQuizRequest{
+		OwnerId:    OwnerId,
+		Count:      2,
+		Category:   "science",
+		Difficulty: DifficultyEasy,
+		Tags:       []string{"physics"},
+	}
+	quiz, err := service.GenerateQuiz(context.Background(), request)
+	if err != nil {
+		t.Fatalf(`service.GenerateQuiz(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(quiz.Questions) != 2 || quiz.Questions[0].NumericAnswer == nil || *quiz.Questions[0].NumericAnswer != 343 {
+		t.Errorf(`quiz.Questions = "%+v", expected 2 numeric questions answered "343"`, quiz.Questions)
+	}
+
+	// Both easy questions were just seen by the owner
+	_, err = service.GenerateQuiz(context.Background(), request)
+	assertHTTPError(t, err, http.StatusConflict)
+}
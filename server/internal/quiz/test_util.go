@@ -0,0 +1,158 @@
+package quiz
+
+import (
+	"common"
+	"context"
+	"errors"
+	"quiz/dto"
+	"testing"
+)
+
+const (
+	ValidTitle  = "Capital Cities"
+	ValidPrompt = "What is the capital of France?"
+	OwnerId     = 1
+)
+
+type mockService struct {
+	createQuizFunc      func(context context.Context, request *dto.CreateQuizRequest) (*dto.CreateQuizResponse, error)
+	getQuizFunc         func(context context.Context, request *dto.GetQuizRequest) (*dto.GetQuizResponse, error)
+	getQuizzesFunc      func(context context.Context, request *dto.GetQuizzesRequest) (*dto.GetQuizzesResponse, error)
+	updateQuizFunc      func(context context.Context, request *dto.UpdateQuizRequest) (*dto.UpdateQuizResponse, error)
+	deleteQuizFunc      func(context context.Context, request *dto.DeleteQuizRequest) (*dto.DeleteQuizResponse, error)
+	getPlayableQuizFunc func(
+		context context.Context,
+		request *dto.GetPlayableQuizRequest,
+	) (*dto.GetPlayableQuizResponse, error)
+	getGameQuizFunc func(
+		context context.Context,
+		request *dto.GetGameQuizRequest,
+	) (*dto.GetGameQuizResponse, error)
+	gradeAnswerFunc        func(context context.Context, request *dto.GradeAnswerRequest) (*dto.GradeAnswerResponse, error)
+	createBankQuestionFunc func(
+		context context.Context,
+		request *dto.CreateBankQuestionRequest,
+	) (*dto.CreateBankQuestionResponse, error)
+	getBankQuestionFunc func(
+		context context.Context,
+		request *dto.GetBankQuestionRequest,
+	) (*dto.GetBankQuestionResponse, error)
+	deleteBankQuestionFunc func(
+		context context.Context,
+		request *dto.DeleteBankQuestionRequest,
+	) (*dto.DeleteBankQuestionResponse, error)
+	generateQuizFunc func(context context.Context, request *dto.GenerateQuizRequest) (*dto.GenerateQuizResponse, error)
+}
+
+func (m *mockService) CreateQuiz(context context.Context, request *dto.CreateQuizRequest) (
+	*dto.CreateQuizResponse,
+	error,
+) {
+	return m.createQuizFunc(context, request)
+}
+
+func (m *mockService) GetQuiz(context context.Context, request *dto.GetQuizRequest) (*dto.GetQuizResponse, error) {
+	return m.getQuizFunc(context, request)
+}
+
+func (m *mockService) GetQuizzes(context context.Context, request *dto.GetQuizzesRequest) (
+	*dto.GetQuizzesResponse,
+	error,
+) {
+	return m.getQuizzesFunc(context, request)
+}
+
+func (m *mockService) UpdateQuiz(context context.Context, request *dto.UpdateQuizRequest) (
+	*dto.UpdateQuizResponse,
+	error,
+) {
+	return m.updateQuizFunc(context, request)
+}
+
+func (m *mockService) DeleteQuiz(context context.Context, request *dto.DeleteQuizRequest) (
+	*dto.DeleteQuizResponse,
+	error,
+) {
+	return m.deleteQuizFunc(context, request)
+}
+
+func (m *mockService) GetPlayableQuiz(context context.Context, request *dto.GetPlayableQuizRequest) (
+	*dto.GetPlayableQuizResponse,
+	error,
+) {
+	return m.getPlayableQuizFunc(context, request)
+}
+
+func (m *mockService) GetGameQuiz(context context.Context, request *dto.GetGameQuizRequest) (
+	*dto.GetGameQuizResponse,
+	error,
+) {
+	return m.getGameQuizFunc(context, request)
+}
+
+func (m *mockService) GradeAnswer(context context.Context, request *dto.GradeAnswerRequest) (
+	*dto.GradeAnswerResponse,
+	error,
+) {
+	return m.gradeAnswerFunc(context, request)
+}
+
+func (m *mockService) CreateBankQuestion(context context.Context, request *dto.CreateBankQuestionRequest) (
+	*dto.CreateBankQuestionResponse,
+	error,
+) {
+	return m.createBankQuestionFunc(context, request)
+}
+
+func (m *mockService) GetBankQuestion(context context.Context, request *dto.GetBankQuestionRequest) (
+	*dto.GetBankQuestionResponse,
+	error,
+) {
+	return m.getBankQuestionFunc(context, request)
+}
+
+func (m *mockService) DeleteBankQuestion(context context.Context, request *dto.DeleteBankQuestionRequest) (
+	*dto.DeleteBankQuestionResponse,
+	error,
+) {
+	return m.deleteBankQuestionFunc(context, request)
+}
+
+func (m *mockService) GenerateQuiz(context context.Context, request *dto.GenerateQuizRequest) (
+	*dto.GenerateQuizResponse,
+	error,
+) {
+	return m.generateQuizFunc(context, request)
+}
+
+// validQuestions Get a question list that passes validation
+func validQuestions() []dto.QuestionRequest {
+	return []dto.QuestionRequest{
+		{
+			Prompt: ValidPrompt,
+			AnswerOptions: []dto.AnswerOptionRequest{
+				{Text: "Paris", IsCorrect: true},
+				{Text: "Lyon"},
+				{Text: "Marseille"},
+			},
+		},
+		{
+			Prompt: "What is the capital of Japan?",
+			AnswerOptions: []dto.AnswerOptionRequest{
+				{Text: "Osaka"},
+				{Text: "Tokyo", IsCorrect: true},
+			},
+		},
+	}
+}
+
+func assertHTTPError(t *testing.T, err error, statusCode int) {
+	var httpErr *common.HTTPError
+	if ok := errors.As(err, &httpErr); !ok {
+		t.Fatalf(`errors.As(err, &httpErr) = "%v", expected "true"`, ok)
+	}
+
+	if httpErr.StatusCode != statusCode {
+		t.Errorf(`httpErr.StatusCode = "%d", expected "%d"`, httpErr.StatusCode, statusCode)
+	}
+}
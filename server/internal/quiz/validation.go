@@ -0,0 +1,369 @@
+package quiz
+
+import (
+    "common"
+    "errors"
+    "fmt"
+    "net/http"
+    "quiz/dto"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+)
+
+const (
+    MaxTitleLength        = 100
+    MaxDescriptionLength  = 1000
+    MaxQuestions          = 100
+    MaxPromptLength       = 500
+    MinAnswerOptions      = 2
+    MaxAnswerOptions      = 10
+    MaxAnswerOptionLength = 200
+    MaxCategoryLength     = 50
+    MaxTags               = 10
+    MaxTagLength          = 30
+    MaxPlayers            = 100
+)
+
+// languageRegex Matches language tags such as "en" or "pt-BR"
+var languageRegex = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+
+// ValidateCreateQuizRequest Validate request for creating a new quiz
+func ValidateCreateQuizRequest(request *dto.CreateQuizRequest) error {
+    return validateQuiz(request.Title, request.Description, request.Questions)
+}
+
+// ValidateGetQuizRequest Validate request for retrieving a quiz
+func ValidateGetQuizRequest(request *dto.GetQuizRequest) error {
+    return validateQuizId(request.QuizId)
+}
+
+// ValidateGetQuizzesRequest Validate request for retrieving paginated quizzes
+func ValidateGetQuizzesRequest(request *dto.GetQuizzesRequest) error {
+    if request.Limit != nil && *request.Limit <= 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "limit must be a positive number",
+        }
+    }
+
+    if request.Offset != nil && *request.Offset < 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "offset must be a positive number",
+        }
+    }
+
+    return nil
+}
+
+// ValidateUpdateQuizRequest Validate request for updating a quiz
+func ValidateUpdateQuizRequest(request *dto.UpdateQuizRequest) error {
+    if err := validateQuizId(request.QuizId); err != nil {
+        return err
+    }
+
+    return validateQuiz(request.Title, request.Description, request.Questions)
+}
+
+// ValidateDeleteQuizRequest Validate request for deleting a quiz
+func ValidateDeleteQuizRequest(request *dto.DeleteQuizRequest) error {
+    return validateQuizId(request.QuizId)
+}
+
+// ValidateGetPlayableQuizRequest Validate request for retrieving a quiz to play
+func ValidateGetPlayableQuizRequest(request *dto.GetPlayableQuizRequest) error {
+    return validateQuizId(request.QuizId)
+}
+
+// ValidateGradeAnswerRequest Validate request for grading an answer to a question
+func ValidateGradeAnswerRequest(request *dto.GradeAnswerRequest) error {
+    if err := validateQuizId(request.QuizId); err != nil {
+        return err
+    }
+
+    if request.QuestionId <= 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid question id",
+        }
+    }
+
+    answer := request.Answer
+    if len(answer.AnswerOptionIds) == 0 && answer.Number == nil && answer.Text == nil {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "answerOptionIds, number, or text is required",
+        }
+    }
+
+    if len(answer.AnswerOptionIds) > MaxAnswerOptions {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("at most %d answer options can be selected", MaxAnswerOptions),
+        }
+    }
+
+    if answer.Text != nil && utf8.RuneCountInString(*answer.Text) > MaxAnswerOptionLength {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("text must be at most %d characters", MaxAnswerOptionLength),
+        }
+    }
+
+    return nil
+}
+
+// ValidateImportQuizRequest Validate request for importing a quiz
+func ValidateImportQuizRequest(request *dto.ImportQuizRequest) error {
+    if err := validateImportFormat(request.Format); err != nil {
+        return err
+    }
+
+    return validateQuiz(request.Title, request.Description, nil)
+}
+
+// ValidateCreateBankQuestionRequest Validate request for adding a question to the question bank
+func ValidateCreateBankQuestionRequest(request *dto.CreateBankQuestionRequest) error {
+    if strings.TrimSpace(request.Category) == "" {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "category is required",
+        }
+    }
+
+    if request.Difficulty == "" {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "difficulty is required",
+        }
+    }
+
+    if request.Language == "" {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "language is required",
+        }
+    }
+
+    if err := validateBankFilters(request.Category, request.Difficulty, request.Language, request.Tags); err != nil {
+        return err
+    }
+
+    if err := validateQuestion(bankQuestionRequest(request)); err != nil {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    err.Error(),
+        }
+    }
+
+    return nil
+}
+
+// ValidateGetBankQuestionRequest Validate request for retrieving a question bank question
+func ValidateGetBankQuestionRequest(request *dto.GetBankQuestionRequest) error {
+    return validateBankQuestionId(request.QuestionId)
+}
+
+// ValidateDeleteBankQuestionRequest Validate request for deleting a question bank question
+func ValidateDeleteBankQuestionRequest(request *dto.DeleteBankQuestionRequest) error {
+    return validateBankQuestionId(request.QuestionId)
+}
+
+// ValidateGenerateQuizRequest Validate request for generating a quiz from the question bank. The title is optional
+func ValidateGenerateQuizRequest(request *dto.GenerateQuizRequest) error {
+    if request.Count <= 0 || request.Count > MaxQuestions {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("count must be between 1 and %d", MaxQuestions),
+        }
+    }
+
+    if request.Title != "" {
+        if err := validateQuiz(request.Title, request.Description, nil); err != nil {
+            return err
+        }
+    } else if utf8.RuneCountInString(request.Description) > MaxDescriptionLength {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("description must be at most %d characters", MaxDescriptionLength),
+        }
+    }
+
+    if err := validateBankFilters(request.Category, request.Difficulty, request.Language, request.Tags); err != nil {
+        return err
+    }
+
+    if len(request.PlayerIds) > MaxPlayers {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("at most %d players can be listed", MaxPlayers),
+        }
+    }
+
+    for _, playerId := range request.PlayerIds {
+        if playerId <= 0 {
+            return &common.HTTPError{
+                StatusCode: http.StatusBadRequest,
+                Message:    "invalid player id",
+            }
+        }
+    }
+
+    return nil
+}
+
+// validateBankFilters Validate the category, difficulty, language and tags of a question bank question or filter,
+// any of which may be empty
+func validateBankFilters(category string, difficulty string, language string, tags []string) error {
+    if utf8.RuneCountInString(strings.TrimSpace(category)) > MaxCategoryLength {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("category must be at most %d characters", MaxCategoryLength),
+        }
+    }
+
+    if difficulty != "" && difficulty != DifficultyEasy && difficulty != DifficultyMedium && difficulty != DifficultyHard {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message: fmt.Sprintf(
+                "unknown difficulty %q, expected %q, %q or %q",
+                difficulty,
+                DifficultyEasy,
+                DifficultyMedium,
+                DifficultyHard,
+            ),
+        }
+    }
+
+    if language != "" && !languageRegex.MatchString(language) {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("invalid language %q, expected a language code such as \"en\" or \"pt-BR\"", language),
+        }
+    }
+
+    if len(tags) > MaxTags {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("at most %d tags are allowed", MaxTags),
+        }
+    }
+
+    for _, tag := range tags {
+        if strings.TrimSpace(tag) == "" || utf8.RuneCountInString(strings.TrimSpace(tag)) > MaxTagLength {
+            return &common.HTTPError{
+                StatusCode: http.StatusBadRequest,
+                Message:    fmt.Sprintf("tags must be between 1 and %d characters", MaxTagLength),
+            }
+        }
+    }
+
+    return nil
+}
+
+// validateBankQuestionId Validate that a question bank question id is positive
+func validateBankQuestionId(questionId int64) error {
+    if questionId <= 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid question id",
+        }
+    }
+
+    return nil
+}
+
+// validateImportFormat Validate the file format of an import
+func validateImportFormat(format string) error {
+    if format != FormatOpenTriviaDB && format != FormatCSV && format != FormatXLSX {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message: fmt.Sprintf(
+                "unsupported format %q, expected %q, %q or %q",
+                format,
+                FormatOpenTriviaDB,
+                FormatCSV,
+                FormatXLSX,
+            ),
+        }
+    }
+
+    return nil
+}
+
+// validateQuizId Validate that a quiz id is positive
+func validateQuizId(quizId int64) error {
+    if quizId <= 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid quiz id",
+        }
+    }
+
+    return nil
+}
+
+// validateQuiz Validate the authored content of a quiz
+func validateQuiz(title string, description string, questions []dto.QuestionRequest) error {
+    if strings.TrimSpace(title) == "" {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "title is required",
+        }
+    }
+
+    if utf8.RuneCountInString(title) > MaxTitleLength {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("title must be at most %d characters", MaxTitleLength),
+        }
+    }
+
+    if utf8.RuneCountInString(description) > MaxDescriptionLength {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("description must be at most %d characters", MaxDescriptionLength),
+        }
+    }
+
+    if len(questions) > MaxQuestions {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("a quiz can have at most %d questions", MaxQuestions),
+        }
+    }
+
+    for i, question := range questions {
+        if err := validateQuestion(question); err != nil {
+            return &common.HTTPError{
+                StatusCode: http.StatusBadRequest,
+                Message:    fmt.Sprintf("question %d: %s", i+1, err.Error()),
+            }
+        }
+    }
+
+    return nil
+}
+
+// validateQuestion Validate a question, checking its answer options against the rules of its type
+func validateQuestion(question dto.QuestionRequest) error {
+    if strings.TrimSpace(question.Prompt) == "" {
+        return errors.New("prompt is required")
+    }
+
+    if utf8.RuneCountInString(question.Prompt) > MaxPromptLength {
+        return fmt.Errorf("prompt must be at most %d characters", MaxPromptLength)
+    }
+
+    questionType, ok := GetQuestionType(question.Type)
+    if !ok {
+        return fmt.Errorf("unknown question type %q", question.Type)
+    }
+
+    if question.Type != Numeric && (question.NumericAnswer != nil || question.NumericTolerance != nil) {
+        return errors.New("numericAnswer and numericTolerance are only allowed for numeric questions")
+    }
+
+    return questionType.Validate(&question)
+}
@@ -0,0 +1,244 @@
+package quiz
+
+import (
+	"net/http"
+	"quiz/dto"
+	"strings"
+	"testing"
+)
+
+func TestValidateCreateQuizRequest_Success(t *testing.T) {
+	request := dto.CreateQuizRequest{
+		OwnerId:   OwnerId,
+		Title:     ValidTitle,
+		Questions: validQuestions(),
+	}
+
+	if err := ValidateCreateQuizRequest(&request); err != nil {
+		t.Errorf(`ValidateCreateQuizRequest(&request) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestValidateCreateQuizRequest_NoQuestions(t *testing.T) {
+	request := dto.CreateQuizRequest{
+		OwnerId: OwnerId,
+		Title:   ValidTitle,
+	}
+
+	if err := ValidateCreateQuizRequest(&request); err != nil {
+		t.Errorf(`ValidateCreateQuizRequest(&request) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestValidateCreateQuizRequest_Invalid(t *testing.T) {
+	tests := map[string]func(request *dto.CreateQuizRequest){
+		"missing title": func(request *dto.CreateQuizRequest) {
+			request.Title = "  "
+		},
+		"long title": func(request *dto.CreateQuizRequest) {
+			request.Title = strings.Repeat("a", MaxTitleLength+1)
+		},
+		"long description": func(request *dto.CreateQuizRequest) {
+			request.Description = strings.Repeat("a", MaxDescriptionLength+1)
+		},
+		"too many questions": func(request *dto.CreateQuizRequest) {
+			for len(request.Questions) <= MaxQuestions {
+				request.Questions = append(request.Questions, validQuestions()[0])
+			}
+		},
+		"missing prompt": func(request *dto.CreateQuizRequest) {
+			request.Questions[0].Prompt = ""
+		},
+		"long prompt": func(request *dto.CreateQuizRequest) {
+			request.Questions[0].Prompt = strings.Repeat("a", MaxPromptLength+1)
+		},
+		"too few answer options": func(request *dto.CreateQuizRequest) {
+			request.Questions[0].AnswerOptions = request.Questions[0].AnswerOptions[:1]
+		},
+		"too many answer options": func(request *dto.CreateQuizRequest) {
+			for len(request.Questions[0].AnswerOptions) <= MaxAnswerOptions {
+				request.Questions[0].AnswerOptions = append(
+					request.Questions[0].AnswerOptions,
+					dto.AnswerOptionRequest{Text: "Nice"},
+				)
+			}
+		},
+		"missing answer option text": func(request *dto.CreateQuizRequest) {
+			request.Questions[1].AnswerOptions[0].Text = ""
+		},
+		"long answer option": func(request *dto.CreateQuizRequest) {
+			request.Questions[1].AnswerOptions[0].Text = strings.Repeat("a", MaxAnswerOptionLength+1)
+		},
+		"no correct answer option": func(request *dto.CreateQuizRequest) {
+			request.Questions[1].AnswerOptions[1].IsCorrect = false
+		},
+		"two correct answer options": func(request *dto.CreateQuizRequest) {
+			request.Questions[1].AnswerOptions[0].IsCorrect = true
+		},
+		"unknown question type": func(request *dto.CreateQuizRequest) {
+			request.Questions[0].Type = "essay"
+		},
+		"numeric answer on choice question": func(request *dto.CreateQuizRequest) {
+			request.Questions[0].NumericAnswer = float64Pointer(1)
+		},
+	}
+
+	for name, modify := range tests {
+		request := dto.CreateQuizRequest{
+			OwnerId:   OwnerId,
+			Title:     ValidTitle,
+			Questions: validQuestions(),
+		}
+		modify(&request)
+
+		err := ValidateCreateQuizRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateCreateQuizRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateGetQuizzesRequest_Invalid(t *testing.T) {
+	zero := 0
+	negative := -1
+	tests := []dto.GetQuizzesRequest{
+		{OwnerId: OwnerId, Limit: &zero},
+		{OwnerId: OwnerId, Offset: &negative},
+	}
+
+	for _, request := range tests {
+		assertHTTPError(t, ValidateGetQuizzesRequest(&request), http.StatusBadRequest)
+	}
+}
+
+func TestValidateUpdateQuizRequest_InvalidQuizId(t *testing.T) {
+	request := dto.UpdateQuizRequest{
+		OwnerId:   OwnerId,
+		Title:     ValidTitle,
+		Questions: validQuestions(),
+	}
+
+	assertHTTPError(t, ValidateUpdateQuizRequest(&request), http.StatusBadRequest)
+}
+
+func TestValidateDeleteQuizRequest_InvalidQuizId(t *testing.T) {
+	request := dto.DeleteQuizRequest{
+		OwnerId: OwnerId,
+		QuizId:  -1,
+	}
+
+	assertHTTPError(t, ValidateDeleteQuizRequest(&request), http.StatusBadRequest)
+}
+
+func TestValidateGradeAnswerRequest_Success(t *testing.T) {
+	request := dto.GradeAnswerRequest{
+		QuizId:     1,
+		QuestionId: 1,
+		Answer:     dto.Answer{Text: stringPointer("Paris")},
+	}
+
+	if err := ValidateGradeAnswerRequest(&request); err != nil {
+		t.Errorf(`ValidateGradeAnswerRequest(&request) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestValidateGradeAnswerRequest_Invalid(t *testing.T) {
+	tooManyIds := make([]int64, MaxAnswerOptions+1)
+	tests := map[string]dto.GradeAnswerRequest{
+		"invalid quiz id":     {QuizId: 0, QuestionId: 1, Answer: dto.Answer{Number: float64Pointer(1)}},
+		"invalid question id": {QuizId: 1, QuestionId: -1, Answer: dto.Answer{Number: float64Pointer(1)}},
+		"empty answer":        {QuizId: 1, QuestionId: 1},
+		"too many options":    {QuizId: 1, QuestionId: 1, Answer: dto.Answer{AnswerOptionIds: tooManyIds}},
+		"long text": {
+			QuizId:     1,
+			QuestionId: 1,
+			Answer:     dto.Answer{Text: stringPointer(strings.Repeat("a", MaxAnswerOptionLength+1))},
+		},
+	}
+
+	for name, request := range tests {
+		err := ValidateGradeAnswerRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateGradeAnswerRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateImportQuizRequest_Invalid(t *testing.T) {
+	tests := map[string]dto.ImportQuizRequest{
+		"unknown format": {OwnerId: OwnerId, Format: "docx", Title: ValidTitle},
+		"missing title":  {OwnerId: OwnerId, Format: FormatCSV},
+	}
+
+	for name, request := range tests {
+		err := ValidateImportQuizRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateImportQuizRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateCreateBankQuestionRequest_Invalid(t *testing.T) {
+	valid := dto.CreateBankQuestionRequest{
+		OwnerId:       OwnerId,
+		Category:      "geography",
+		Difficulty:    DifficultyEasy,
+		Language:      "pt-BR",
+		Tags:          []string{"capitals"},
+		Prompt:        ValidPrompt,
+		AnswerOptions: validQuestions()[0].AnswerOptions,
+	}
+	if err := ValidateCreateBankQuestionRequest(&valid); err != nil {
+		t.Fatalf(`ValidateCreateBankQuestionRequest(&valid) = "%v", expected "<nil>"`, err)
+	}
+
+	tests := map[string]func(request *dto.CreateBankQuestionRequest){
+		"missing category":   func(request *dto.CreateBankQuestionRequest) { request.Category = " " },
+		"missing difficulty": func(request *dto.CreateBankQuestionRequest) { request.Difficulty = "" },
+		"unknown difficulty": func(request *dto.CreateBankQuestionRequest) { request.Difficulty = "Easy" },
+		"invalid language":   func(request *dto.CreateBankQuestionRequest) { request.Language = "english" },
+		"empty tag":          func(request *dto.CreateBankQuestionRequest) { request.Tags = []string{""} },
+		"too many tags": func(request *dto.CreateBankQuestionRequest) {
+			request.Tags = strings.Split(strings.Repeat("tag,", MaxTags), ",")
+		},
+		"no correct option": func(request *dto.CreateBankQuestionRequest) {
+			request.AnswerOptions = []dto.AnswerOptionRequest{{Text: "Paris"}, {Text: "Lyon"}}
+		},
+	}
+
+	for name, modify := range tests {
+		request := valid
+		modify(&request)
+		err := ValidateCreateBankQuestionRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateCreateBankQuestionRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestValidateGenerateQuizRequest_Invalid(t *testing.T) {
+	tests := map[string]dto.GenerateQuizRequest{
+		"no questions":       {OwnerId: OwnerId},
+		"too many questions": {OwnerId: OwnerId, Count: MaxQuestions + 1},
+		"long title":         {OwnerId: OwnerId, Count: 1, Title: strings.Repeat("a", MaxTitleLength+1)},
+		"unknown difficulty": {OwnerId: OwnerId, Count: 1, Difficulty: "expert"},
+		"invalid player id":  {OwnerId: OwnerId, Count: 1, PlayerIds: []int{0}},
+	}
+
+	for name, request := range tests {
+		err := ValidateGenerateQuizRequest(&request)
+		if err == nil {
+			t.Errorf(`%s: ValidateGenerateQuizRequest(&request) = "<nil>", expected non-nil`, name)
+			continue
+		}
+		assertHTTPError(t, err, http.StatusBadRequest)
+	}
+}
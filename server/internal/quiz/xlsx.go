@@ -0,0 +1,238 @@
+package quiz
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxXLSXPartSize Largest uncompressed workbook part read from an XLSX file, so a small zip cannot expand without
+// bound
+const maxXLSXPartSize = 64 << 20
+
+// maxXLSXColumns Number of columns in an Excel worksheet
+const maxXLSXColumns = 16384
+
+// errXLSXPartMissing Returned by decodeXLSXPart for parts missing from the archive
+var errXLSXPartMissing = errors.New("is missing from the XLSX file")
+
+// tableRow A row of a CSV or XLSX import with the line or spreadsheet row number it came from. Only non-empty cells
+// are kept, as an XLSX row can reference any of its 16,384 columns
+type tableRow struct {
+	Line  int
+	Cells []tableCell
+}
+
+// tableCell A non-empty cell of a tableRow with its zero-based column
+type tableCell struct {
+	Column int
+	Text   string
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText Plain or rich text; rich text is split into runs that are joined back together
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+type xlsxRow struct {
+	Number int `xml:"r,attr"`
+	Cells  []struct {
+		Reference    string   `xml:"r,attr"`
+		Type         string   `xml:"t,attr"`
+		Value        string   `xml:"v"`
+		InlineString xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// String Get the text with its runs joined
+func (text xlsxText) String() string {
+	if len(text.Runs) == 0 {
+		return text.Text
+	}
+
+	var builder strings.Builder
+	for _, run := range text.Runs {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+// readXLSXRows Call readRow for each row of the first worksheet of an XLSX workbook, reading the worksheet one row at
+// a time so that returning an error stops it. Cells hold their stored values: numbers are not formatted and formulas
+// are not evaluated
+func readXLSXRows(data []byte, readRow func(row tableRow) error) error {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("not an XLSX file: %w", err)
+	}
+
+	sheetPath, err := firstWorksheetPath(archive)
+	if err != nil {
+		return err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	err = decodeXLSXPart(archive, "xl/sharedStrings.xml", &sharedStrings)
+	if err != nil && !errors.Is(err, errXLSXPartMissing) {
+		return err
+	}
+
+	file, err := archive.Open(sheetPath)
+	if err != nil {
+		return fmt.Errorf("%s %w", sheetPath, errXLSXPartMissing)
+	}
+	defer file.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(file, maxXLSXPartSize))
+	previousRow := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid %s: %w", sheetPath, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return fmt.Errorf("invalid %s: %w", sheetPath, err)
+		}
+
+		number := row.Number
+		if number == 0 {
+			number = previousRow + 1
+		}
+		previousRow = number
+
+		var cells []tableCell
+		column := -1
+		for _, cell := range row.Cells {
+			column++
+			if index := xlsxColumnIndex(cell.Reference); index >= 0 {
+				column = index
+			}
+			if column >= maxXLSXColumns {
+				return fmt.Errorf("invalid cell reference %s", cell.Reference)
+			}
+
+			var text string
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return fmt.Errorf("invalid shared string in cell %s", cell.Reference)
+				}
+				text = sharedStrings.Items[index].String()
+			case "inlineStr":
+				text = cell.InlineString.String()
+			case "b":
+				text = strings.ToUpper(strconv.FormatBool(cell.Value == "1"))
+			default:
+				text = cell.Value
+			}
+
+			if text != "" {
+				cells = append(cells, tableCell{Column: column, Text: text})
+			}
+		}
+
+		if err := readRow(tableRow{Line: number, Cells: cells}); err != nil {
+			return err
+		}
+	}
+}
+
+// cell Get the text of the cell in the column, or an empty string for a missing cell
+func (row tableRow) cell(column int) string {
+	text := ""
+	for _, cell := range row.Cells {
+		if cell.Column == column {
+			text = cell.Text
+		}
+	}
+	return text
+}
+
+// firstWorksheetPath Get the archive path of the first worksheet listed in the workbook
+func firstWorksheetPath(archive *zip.Reader) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodeXLSXPart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("the XLSX file has no worksheets")
+	}
+
+	var relationships xlsxRelationships
+	if err := decodeXLSXPart(archive, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return "", err
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.Id != workbook.Sheets[0].RelationshipId {
+			continue
+		}
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+	return "", errors.New("the first worksheet is missing from the XLSX file")
+}
+
+// decodeXLSXPart Decode the XML part of the archive with the specified name
+func decodeXLSXPart(archive *zip.Reader, name string, value any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("%s %w", name, errXLSXPartMissing)
+	}
+	defer file.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(file, maxXLSXPartSize)).Decode(value); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex Get the zero-based column of a cell reference such as "C7", or -1 without a column
+func xlsxColumnIndex(reference string) int {
+	column := 0
+	for _, character := range reference {
+		if character < 'A' || character > 'Z' {
+			break
+		}
+		column = column*26 + int(character-'A'+1)
+	}
+	return column - 1
+}
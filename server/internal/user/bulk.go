@@ -0,0 +1,268 @@
+package user
+
+import (
+	"bufio"
+	"common"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user/dto"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// exportPageLimit Number of users fetched per GetUsers call while exporting
+const exportPageLimit = 500
+
+// MaxImportSize Largest request body accepted by the import endpoint
+const MaxImportSize = 32 << 20
+
+// maxImportLineLength Longest JSONL line accepted by an import
+const maxImportLineLength = 1024 * 1024
+
+var exportCSVHeader = []string{"userId", "username", "email", "isVerified", "createdAt", "updatedAt"}
+
+var importCSVColumns = []string{"username", "email", "password"}
+
+// exportContentType Get the content type for an export format
+func exportContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// ExportUsers Write every user to the writer as CSV or JSONL, leaving out password hashes
+func ExportUsers(context context.Context, service Service, request *dto.ExportUsersRequest, writer io.Writer) error {
+	var write func(user *dto.ExportedUser) error
+	var flush func() error
+
+	switch request.Format {
+	case FormatCSV:
+		csvWriter := csv.NewWriter(writer)
+		if err := csvWriter.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		write = func(user *dto.ExportedUser) error {
+			return csvWriter.Write(
+				[]string{
+					strconv.Itoa(user.UserId),
+					user.Username,
+					user.Email,
+					strconv.FormatBool(user.IsVerified),
+					user.CreatedAt.Format(time.RFC3339Nano),
+					user.UpdatedAt.Format(time.RFC3339Nano),
+				},
+			)
+		}
+		flush = func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	case FormatJSONL:
+		encoder := json.NewEncoder(writer)
+		write = func(user *dto.ExportedUser) error {
+			return encoder.Encode(user)
+		}
+		flush = func() error {
+			return nil
+		}
+	default:
+		return validateBulkFormat(request.Format)
+	}
+
+	limit := exportPageLimit
+	for offset := 0; ; offset += limit {
+		page, err := service.GetUsers(context, &dto.GetUsersRequest{Limit: &limit, Offset: &offset})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve users: %w", err)
+		}
+
+		for _, user := range page.Users {
+			exported := dto.ExportedUser{
+				UserId:     user.UserId,
+				Username:   user.Username,
+				Email:      user.Email,
+				IsVerified: user.IsVerified,
+				CreatedAt:  user.CreatedAt,
+				UpdatedAt:  user.UpdatedAt,
+			}
+			if err := write(&exported); err != nil {
+				return fmt.Errorf("failed to write user: %w", err)
+			}
+		}
+
+		if len(page.Users) < limit {
+			break
+		}
+	}
+
+	return flush()
+}
+
+// ImportUsers Create users from CSV (with a username,email,password header row) or JSONL (one create user request
+// per line). Every row goes through ValidateCreateUserRequest and failures are reported per row without stopping
+// the import. With DryRun set, rows are only validated
+func ImportUsers(
+	context context.Context,
+	service Service,
+	request *dto.ImportUsersRequest,
+	reader io.Reader,
+) (*dto.ImportUsersResponse, error) {
+	response := dto.ImportUsersResponse{
+		DryRun: request.DryRun,
+		Errors: []dto.ImportUserError{},
+	}
+	usernames := map[string]bool{}
+	emails := map[string]bool{}
+
+	importRow := func(line int, row *dto.CreateUserRequest, rowErr error) error {
+		if err := context.Err(); err != nil {
+			return fmt.Errorf("import interrupted after %d rows: %w", response.Total, err)
+		}
+		response.Total++
+
+		err := rowErr
+		if err == nil && usernames[row.Username] {
+			err = errors.New("duplicate username in import")
+		} else if err == nil && emails[row.Email] {
+			err = errors.New("duplicate email in import")
+		} else if err == nil {
+			err = ValidateCreateUserRequest(row, service, context)
+		}
+
+		if err == nil && !request.DryRun {
+			if _, createErr := service.CreateUser(context, row); createErr != nil {
+				slog.ErrorContext(context, "failed to import user", slog.Int("line", line), slog.Any("error", createErr))
+				err = errors.New("failed to create user")
+			}
+		}
+
+		if err != nil {
+			response.Failed++
+			response.Errors = append(
+				response.Errors, dto.ImportUserError{
+					Line:     line,
+					Username: row.Username,
+					Message:  err.Error(),
+				},
+			)
+			return nil
+		}
+
+		usernames[row.Username] = true
+		emails[row.Email] = true
+		response.Imported++
+		return nil
+	}
+
+	var err error
+	switch request.Format {
+	case FormatCSV:
+		err = readImportCSV(reader, importRow)
+	case FormatJSONL:
+		err = readImportJSONL(reader, importRow)
+	default:
+		err = validateBulkFormat(request.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// readImportCSV Call importRow for each record of a CSV import, mapping columns by the header row
+func readImportCSV(reader io.Reader, importRow func(line int, row *dto.CreateUserRequest, err error) error) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return invalidImportError(fmt.Sprintf("invalid CSV header: %v", err))
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+	for _, column := range importCSVColumns {
+		if _, ok := columns[column]; !ok {
+			return invalidImportError(fmt.Sprintf("CSV header is missing the %q column", column))
+		}
+	}
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return invalidImportError(fmt.Sprintf("invalid CSV: %v", err))
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		row := dto.CreateUserRequest{}
+		var rowErr error
+		if len(record) == len(header) {
+			row.Username = record[columns["username"]]
+			row.Email = record[columns["email"]]
+			row.Password = record[columns["password"]]
+		} else {
+			rowErr = fmt.Errorf("expected %d fields, found %d", len(header), len(record))
+		}
+
+		if err := importRow(line, &row, rowErr); err != nil {
+			return err
+		}
+	}
+}
+
+// readImportJSONL Call importRow for each non-blank line of a JSONL import
+func readImportJSONL(reader io.Reader, importRow func(line int, row *dto.CreateUserRequest, err error) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineLength)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var row dto.CreateUserRequest
+		var rowErr error
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			rowErr = errors.New("invalid JSON")
+		}
+
+		if err := importRow(line, &row, rowErr); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return invalidImportError(fmt.Sprintf("invalid JSONL: %v", err))
+	}
+	return nil
+}
+
+// invalidImportError Create the error returned when an import file cannot be parsed at all
+func invalidImportError(message string) error {
+	return &common.HTTPError{
+		StatusCode: http.StatusBadRequest,
+		Message:    message,
+	}
+}
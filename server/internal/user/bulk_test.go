@@ -0,0 +1,150 @@
+package user
+
+import (
+	"bytes"
+	"common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"user/db/memory"
+	"user/dto"
+)
+
+func newBulkTestService(t *testing.T) *ServiceImpl {
+	t.Setenv(BASE_URL_KEY, MockUrl)
+
+	queries := memory.New()
+	return &ServiceImpl{
+		Queries:    queries,
+		Transactor: queries,
+	}
+}
+
+func TestImportUsers_JSONL(t *testing.T) {
+	service := newBulkTestService(t)
+
+	input := strings.Join(
+		[]string{
+			createUserPayload("first", "first@email.com"),
+			"",
+			createUserPayload("second", "second@email.com"),
+			createUserPayload("first", "other@email.com"),
+			`{"username": `,
+			`{"username": "weak", "email": "weak@email.com", "password": "short"}`,
+		}, "\n",
+	)
+
+	request := dto.ImportUsersRequest{Format: FormatJSONL}
+	report, err := ImportUsers(context.Background(), service, &request, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf(`ImportUsers(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if report.Total != 5 || report.Imported != 2 || report.Failed != 3 {
+		t.Errorf(`report = "%+v", expected 5 rows with 2 imported and 3 failed`, report)
+	}
+
+	expectedLines := []int{4, 5, 6}
+	for i, rowError := range report.Errors {
+		if i >= len(expectedLines) || rowError.Line != expectedLines[i] {
+			t.Errorf(`report.Errors[%d] = "%+v", expected line "%v"`, i, rowError, expectedLines)
+		}
+	}
+	if report.Errors[0].Message != "duplicate username in import" {
+		t.Errorf(`report.Errors[0].Message = "%s", expected "duplicate username in import"`, report.Errors[0].Message)
+	}
+
+	users, _ := service.Queries.CountUsers(context.Background())
+	if users != 2 {
+		t.Errorf(`users = "%d", expected "2"`, users)
+	}
+}
+
+func TestImportUsers_CSVDryRun(t *testing.T) {
+	service := newBulkTestService(t)
+
+	input := "email,username,password\n" +
+		"first@email.com,first," + ValidPassword + "\n" +
+		"second@email.com,second\n"
+
+	request := dto.ImportUsersRequest{Format: FormatCSV, DryRun: true}
+	report, err := ImportUsers(context.Background(), service, &request, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf(`ImportUsers(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if !report.DryRun || report.Total != 2 || report.Imported != 1 || report.Failed != 1 {
+		t.Errorf(`report = "%+v", expected 2 rows with 1 valid and 1 failed`, report)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Line != 3 {
+		t.Errorf(`report.Errors = "%+v", expected an error on line 3`, report.Errors)
+	}
+
+	if users, _ := service.Queries.CountUsers(context.Background()); users != 0 {
+		t.Errorf(`users = "%d", expected "0"`, users)
+	}
+}
+
+func TestImportUsers_InvalidFile(t *testing.T) {
+	service := newBulkTestService(t)
+
+	tests := []struct {
+		request dto.ImportUsersRequest
+		input   string
+	}{
+		{dto.ImportUsersRequest{Format: FormatCSV}, "username,email\nfirst,first@email.com\n"},
+		{dto.ImportUsersRequest{Format: FormatCSV}, "username,email,password\n\"unterminated\n"},
+		{dto.ImportUsersRequest{Format: "xml"}, ""},
+	}
+
+	for _, test := range tests {
+		_, err := ImportUsers(context.Background(), service, &test.request, strings.NewReader(test.input))
+
+		var httpError *common.HTTPError
+		if !errors.As(err, &httpError) || httpError.StatusCode != http.StatusBadRequest {
+			t.Errorf(`ImportUsers(%q) error = "%v", expected a bad request`, test.input, err)
+		}
+	}
+}
+
+func TestExportUsers_JSONL(t *testing.T) {
+	service := newBulkTestService(t)
+	for i := 0; i < 3; i++ {
+		_, err := service.CreateUser(
+			context.Background(), &dto.CreateUserRequest{
+				Username: fmt.Sprintf("user-%d", i),
+				Email:    fmt.Sprintf("user-%d@email.com", i),
+				Password: ValidPassword,
+			},
+		)
+		if err != nil {
+			t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	var output bytes.Buffer
+	request := dto.ExportUsersRequest{Format: FormatJSONL}
+	if err := ExportUsers(context.Background(), service, &request, &output); err != nil {
+		t.Fatalf(`ExportUsers(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if strings.Contains(output.String(), "passwordHash") {
+		t.Errorf(`output = "%s", expected no password hashes`, output.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf(`len(lines) = "%d", expected "3"`, len(lines))
+	}
+	var exported dto.ExportedUser
+	if err := json.Unmarshal([]byte(lines[2]), &exported); err != nil {
+		t.Fatalf(`json.Unmarshal(lines[2], &exported) error = "%v", expected "<nil>"`, err)
+	}
+	if exported.Username != "user-2" || exported.CreatedAt.IsZero() {
+		t.Errorf(`exported = "%+v", expected user "user-2" with a creation time`, exported)
+	}
+}
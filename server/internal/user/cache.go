@@ -0,0 +1,275 @@
+package user
+
+import (
+	"common"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+	"user/dto"
+)
+
+// CachingService Service decorator serving GetUser from a read-through cache. Users are stored under their id,
+// with username and email keys holding only the id, so invalidating the id key is enough when a user changes.
+// Every invalidation bumps a generation, and a miss only fills the cache if no invalidation happened since it began,
+// so a value read before a concurrent write is never stored after the write's invalidation. The generation is only
+// known to this process, so the cache must be the in-process LRU: with a cache shared between replicas, a replica
+// could store a value read before another replica's write after that write's invalidation
+type CachingService struct {
+	Service
+	Cache   *common.LRUCache
+	TTL     time.Duration
+	Metrics *common.CacheMetrics
+
+	generationMutex sync.Mutex
+	generation      uint64
+}
+
+// NewCachingService Wrap the specified service with a read-through cache held in this process
+func NewCachingService(service Service, cache *common.LRUCache, ttl time.Duration) *CachingService {
+	return &CachingService{
+		Service: service,
+		Cache:   cache,
+		TTL:     ttl,
+		Metrics: &common.CacheMetrics{},
+	}
+}
+
+// GetUser Retrieve a user, serving lookups by a single id, username, or email from the cache
+func (service *CachingService) GetUser(
+	context context.Context,
+	request *dto.GetUserRequest,
+) (*dto.GetUserResponse, error) {
+	key, ok := getUserCacheKey(request)
+	if !ok {
+		return service.Service.GetUser(context, request)
+	}
+
+	if user, ok := service.getCachedUser(context, key, request); ok {
+		service.Metrics.Hits.Add(1)
+		return user, nil
+	}
+	service.Metrics.Misses.Add(1)
+
+	generation := service.currentGeneration()
+	user, err := service.Service.GetUser(context, request)
+	if err != nil {
+		return nil, err
+	}
+
+	service.fill(context, user, generation)
+	return user, nil
+}
+
+// UpdateUser Update a user and invalidate its cache entry
+func (service *CachingService) UpdateUser(
+	context context.Context,
+	request *dto.UpdateUserRequest,
+) (*dto.UpdateUserResponse, error) {
+	defer service.Invalidate(context, request.UserId)
+	return service.Service.UpdateUser(context, request)
+}
+
+// DeleteUser Delete a user and invalidate its cache entry
+func (service *CachingService) DeleteUser(
+	context context.Context,
+	request *dto.DeleteUserRequest,
+) (*dto.DeleteUserResponse, error) {
+	defer service.Invalidate(context, request.UserId)
+	return service.Service.DeleteUser(context, request)
+}
+
+// VerifyUser Set a user's verification status and invalidate its cache entry
+func (service *CachingService) VerifyUser(
+	context context.Context,
+	request *dto.VerifyUserRequest,
+) (*dto.VerifyUserResponse, error) {
+	defer service.Invalidate(context, request.UserId)
+	return service.Service.VerifyUser(context, request)
+}
+
+// RestoreUser Restore a deleted user and invalidate its cache entry
+func (service *CachingService) RestoreUser(
+	context context.Context,
+	request *dto.RestoreUserRequest,
+) (*dto.RestoreUserResponse, error) {
+	defer service.Invalidate(context, request.UserId)
+	return service.Service.RestoreUser(context, request)
+}
+
+// ConfirmEmailChange Confirm an email change and invalidate the user's cache entry
+func (service *CachingService) ConfirmEmailChange(
+	context context.Context,
+	request *dto.ConfirmEmailChangeRequest,
+) (*dto.ConfirmEmailChangeResponse, error) {
+	response, err := service.Service.ConfirmEmailChange(context, request)
+	if err == nil {
+		service.Invalidate(context, response.UserId)
+	}
+	return response, err
+}
+
+// UndoEmailChange Undo an email change and invalidate the user's cache entry
+func (service *CachingService) UndoEmailChange(
+	context context.Context,
+	request *dto.UndoEmailChangeRequest,
+) (*dto.UndoEmailChangeResponse, error) {
+	response, err := service.Service.UndoEmailChange(context, request)
+	if err == nil {
+		service.Invalidate(context, response.UserId)
+	}
+	return response, err
+}
+
+// ScheduleUserDeletion Schedule a user's deletion and invalidate the user's cache entry
+func (service *CachingService) ScheduleUserDeletion(
+	context context.Context,
+	request *dto.ScheduleUserDeletionRequest,
+) (*dto.ScheduleUserDeletionResponse, error) {
+	response, err := service.Service.ScheduleUserDeletion(context, request)
+	if err == nil {
+		service.Invalidate(context, response.UserId)
+	}
+	return response, err
+}
+
+// CancelUserDeletion Cancel a user's deletion and invalidate the user's cache entry
+func (service *CachingService) CancelUserDeletion(
+	context context.Context,
+	request *dto.CancelUserDeletionRequest,
+) (*dto.CancelUserDeletionResponse, error) {
+	response, err := service.Service.CancelUserDeletion(context, request)
+	if err == nil {
+		service.Invalidate(context, response.UserId)
+	}
+	return response, err
+}
+
+// getCachedUser Look up a user by cache key, resolving username and email keys to the id entry. Index entries
+// pointing at a user whose username or email has since changed are treated as misses
+func (service *CachingService) getCachedUser(
+	context context.Context,
+	key string,
+	request *dto.GetUserRequest,
+) (*dto.GetUserResponse, bool) {
+	value, ok := service.get(context, key)
+	if !ok {
+		return nil, false
+	}
+
+	if request.UserId == nil {
+		value, ok = service.get(context, string(value))
+		if !ok {
+			return nil, false
+		}
+	}
+
+	var user dto.GetUserResponse
+	if err := json.Unmarshal(value, &user); err != nil {
+		service.Metrics.Errors.Add(1)
+		slog.WarnContext(context, "failed to decode cached user", slog.String("key", key), slog.Any("error", err))
+		return nil, false
+	}
+
+	if request.Username != nil && user.Username != *request.Username {
+		return nil, false
+	}
+	if request.Email != nil && user.Email != *request.Email {
+		return nil, false
+	}
+	return &user, true
+}
+
+// fill Store a user under its id along with username and email index entries, unless the cache was invalidated since
+// the specified generation was read
+func (service *CachingService) fill(context context.Context, user *dto.GetUserResponse, generation uint64) {
+	value, err := json.Marshal(user)
+	if err != nil {
+		service.Metrics.Errors.Add(1)
+		slog.WarnContext(context, "failed to encode user for cache", slog.Any("error", err))
+		return
+	}
+
+	service.generationMutex.Lock()
+	defer service.generationMutex.Unlock()
+
+	if service.generation != generation {
+		return
+	}
+
+	idKey := userIdCacheKey(user.UserId)
+	service.set(context, idKey, value)
+	service.set(context, usernameCacheKey(user.Username), []byte(idKey))
+	service.set(context, emailCacheKey(user.Email), []byte(idKey))
+}
+
+// currentGeneration Get the number of invalidations so far
+func (service *CachingService) currentGeneration() uint64 {
+	service.generationMutex.Lock()
+	defer service.generationMutex.Unlock()
+
+	return service.generation
+}
+
+// Invalidate Remove the cache entry for the user. Changes made without going through the caching service, such as
+// purging accounts, must call this once committed
+func (service *CachingService) Invalidate(context context.Context, userId int) {
+	service.generationMutex.Lock()
+	defer service.generationMutex.Unlock()
+
+	service.generation++
+	if err := service.Cache.Delete(context, userIdCacheKey(userId)); err != nil {
+		service.Metrics.Errors.Add(1)
+		slog.WarnContext(
+			context, "failed to invalidate cached user", slog.Int("user_id", userId), slog.Any("error", err),
+		)
+	}
+}
+
+// get Read a key from the cache, treating backend errors as misses
+func (service *CachingService) get(context context.Context, key string) ([]byte, bool) {
+	value, ok, err := service.Cache.Get(context, key)
+	if err != nil {
+		service.Metrics.Errors.Add(1)
+		slog.WarnContext(context, "failed to read from cache", slog.String("key", key), slog.Any("error", err))
+		return nil, false
+	}
+	return value, ok
+}
+
+// set Write a key to the cache, logging backend errors
+func (service *CachingService) set(context context.Context, key string, value []byte) {
+	if err := service.Cache.Set(context, key, value, service.TTL); err != nil {
+		service.Metrics.Errors.Add(1)
+		slog.WarnContext(context, "failed to write to cache", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// getUserCacheKey Get the cache key for a lookup by exactly one of id, username, or email
+func getUserCacheKey(request *dto.GetUserRequest) (string, bool) {
+	switch {
+	case request.UserId != nil && request.Username == nil && request.Email == nil:
+		return userIdCacheKey(*request.UserId), true
+	case request.UserId == nil && request.Username != nil && request.Email == nil:
+		return usernameCacheKey(*request.Username), true
+	case request.UserId == nil && request.Username == nil && request.Email != nil:
+		return emailCacheKey(*request.Email), true
+	default:
+		return "", false
+	}
+}
+
+func userIdCacheKey(userId int) string {
+	return "user:id:" + strconv.Itoa(userId)
+}
+
+func usernameCacheKey(username string) string {
+	return fmt.Sprintf("user:username:%s", username)
+}
+
+func emailCacheKey(email string) string {
+	return fmt.Sprintf("user:email:%s", email)
+}
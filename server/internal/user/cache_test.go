@@ -0,0 +1,184 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"user/dto"
+)
+
+func newCachingTestService(users map[int]*dto.GetUserResponse) (*CachingService, *int) {
+	calls := 0
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			calls++
+			for _, user := range users {
+				if request.UserId != nil && user.UserId != *request.UserId {
+					continue
+				}
+				if request.Username != nil && user.Username != *request.Username {
+					continue
+				}
+				if request.Email != nil && user.Email != *request.Email {
+					continue
+				}
+				copied := *user
+				return &copied, nil
+			}
+			return nil, sql.ErrNoRows
+		},
+		updateUserFunc: func(context context.Context, request *dto.UpdateUserRequest) (*dto.UpdateUserResponse, error) {
+			user := users[request.UserId]
+			if request.Username != nil {
+				user.Username = *request.Username
+			}
+			return user, nil
+		},
+		deleteUserFunc: func(context context.Context, request *dto.DeleteUserRequest) (*dto.DeleteUserResponse, error) {
+			delete(users, request.UserId)
+			return &dto.DeleteUserResponse{}, nil
+		},
+	}
+	return NewCachingService(service, common.NewLRUCache(100), time.Minute), &calls
+}
+
+func TestCachingService_GetUser_ReadThrough(t *testing.T) {
+	users := map[int]*dto.GetUserResponse{
+		1: {UserId: 1, Username: ValidUsername, Email: ValidEmail},
+	}
+	service, calls := newCachingTestService(users)
+
+	userId := 1
+	username := ValidUsername
+	email := ValidEmail
+	requests := []dto.GetUserRequest{
+		{UserId: &userId},
+		{UserId: &userId},
+		{Username: &username},
+		{Email: &email},
+	}
+	for _, request := range requests {
+		user, err := service.GetUser(context.Background(), &request)
+		if err != nil {
+			t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+		}
+		if user.UserId != userId {
+			t.Errorf(`user.UserId = "%d", expected "%d"`, user.UserId, userId)
+		}
+	}
+
+	if *calls != 1 {
+		t.Errorf(`calls = "%d", expected "1"`, *calls)
+	}
+	if hits, misses := service.Metrics.Hits.Load(), service.Metrics.Misses.Load(); hits != 3 || misses != 1 {
+		t.Errorf(`hits, misses = "%d, %d", expected "3, 1"`, hits, misses)
+	}
+}
+
+func TestCachingService_GetUser_MultipleFieldsBypassCache(t *testing.T) {
+	users := map[int]*dto.GetUserResponse{
+		1: {UserId: 1, Username: ValidUsername, Email: ValidEmail},
+	}
+	service, calls := newCachingTestService(users)
+
+	username := ValidUsername
+	email := ValidEmail
+	request := dto.GetUserRequest{Username: &username, Email: &email}
+	for i := 0; i < 2; i++ {
+		if _, err := service.GetUser(context.Background(), &request); err != nil {
+			t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	if *calls != 2 {
+		t.Errorf(`calls = "%d", expected "2"`, *calls)
+	}
+}
+
+func TestCachingService_UpdateUser_Invalidates(t *testing.T) {
+	users := map[int]*dto.GetUserResponse{
+		1: {UserId: 1, Username: ValidUsername, Email: ValidEmail},
+	}
+	service, _ := newCachingTestService(users)
+
+	userId := 1
+	oldUsername := ValidUsername
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &userId}); err != nil {
+		t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+
+	newUsername := "renamed"
+	request := dto.UpdateUserRequest{UserId: userId, Username: &newUsername}
+	if _, err := service.UpdateUser(context.Background(), &request); err != nil {
+		t.Fatalf(`service.UpdateUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+
+	user, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &userId})
+	if err != nil {
+		t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+	if user.Username != newUsername {
+		t.Errorf(`user.Username = "%s", expected "%s"`, user.Username, newUsername)
+	}
+
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{Username: &oldUsername}); err == nil {
+		t.Error(`service.GetUser(ctx, old username) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestCachingService_DeleteUser_Invalidates(t *testing.T) {
+	users := map[int]*dto.GetUserResponse{
+		1: {UserId: 1, Username: ValidUsername, Email: ValidEmail},
+	}
+	service, _ := newCachingTestService(users)
+
+	email := ValidEmail
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{Email: &email}); err != nil {
+		t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+
+	if _, err := service.DeleteUser(context.Background(), &dto.DeleteUserRequest{UserId: 1}); err != nil {
+		t.Fatalf(`service.DeleteUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{Email: &email}); err == nil {
+		t.Error(`service.GetUser(ctx, request) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestCachingService_GetUser_SkipsFillAfterInvalidation(t *testing.T) {
+	users := map[int]*dto.GetUserResponse{
+		1: {UserId: 1, Username: ValidUsername, Email: ValidEmail},
+	}
+	service, calls := newCachingTestService(users)
+
+	// Simulate a write committed and invalidated while the first lookup was reading the old value
+	base := service.Service.(*mockService)
+	getUser := base.getUserFunc
+	base.getUserFunc = func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+		user, err := getUser(context, request)
+		if *calls == 1 {
+			users[1].Username = "renamed"
+			service.Invalidate(context, 1)
+		}
+		return user, err
+	}
+
+	userId := 1
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &userId}); err != nil {
+		t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+
+	user, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &userId})
+	if err != nil {
+		t.Fatalf(`service.GetUser(ctx, request) error = "%v", expected "<nil>"`, err)
+	}
+	if user.Username != "renamed" {
+		t.Errorf(`user.Username = "%s", expected "renamed"`, user.Username)
+	}
+	if *calls != 2 {
+		t.Errorf(`calls = "%d", expected "2"`, *calls)
+	}
+}
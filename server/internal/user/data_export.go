@@ -0,0 +1,479 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"common"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"user/db/generated"
+	"user/dto"
+)
+
+const (
+	// DataExportPending Status of an export waiting for a worker
+	DataExportPending = "pending"
+	// DataExportRunning Status of an export a worker is building
+	DataExportRunning = "running"
+	// DataExportReady Status of an export that can be downloaded until it expires
+	DataExportReady = "ready"
+	// DataExportFailed Status of an export that could not be built. Requesting an export again starts a new one
+	DataExportFailed = "failed"
+	// DataExportExpired Status reported for a ready export once its download link has expired
+	DataExportExpired = "expired"
+)
+
+// RequestDataExport Start building an archive of the user's personal data, or return the export already in progress
+// or ready for download. The archive is built by the DataExporter, which emails the user a download link once it is
+// ready
+func (service *ServiceImpl) RequestDataExport(
+	context context.Context,
+	request *dto.RequestDataExportRequest,
+) (*dto.RequestDataExportResponse, error) {
+	var export db.DataExport
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			export, err = queries.GetLatestDataExport(context, int32(request.UserId))
+			if err == nil && dataExportStatus(export) != DataExportFailed && dataExportStatus(export) != DataExportExpired {
+				return nil
+			} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			export, err = queries.CreateDataExport(context, int32(request.UserId))
+			return err
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request data export: %w", err)
+	}
+
+	return toDataExportDTO(export), nil
+}
+
+// GetDataExport Get the status of one of the user's data exports
+func (service *ServiceImpl) GetDataExport(
+	context context.Context,
+	request *dto.GetDataExportRequest,
+) (*dto.GetDataExportResponse, error) {
+	export, err := service.Queries.GetDataExport(
+		context, db.GetDataExportParams{
+			ID:     request.ExportId,
+			UserID: int32(request.UserId),
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusNotFound,
+			Message:    "data export not found",
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+
+	return toDataExportDTO(export), nil
+}
+
+// DownloadDataExport Get a finished archive using the token from the download link. The token stops working once the
+// export expires
+func (service *ServiceImpl) DownloadDataExport(
+	context context.Context,
+	request *dto.DownloadDataExportRequest,
+) (*dto.DownloadDataExportResponse, error) {
+	export, err := service.Queries.GetDataExportByDownloadToken(
+		context,
+		sql.NullString{String: hashLinkToken(request.Token), Valid: true},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, invalidLinkTokenError()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+
+	if dataExportStatus(export) != DataExportReady {
+		return nil, invalidLinkTokenError()
+	}
+
+	archive, err := service.Queries.GetDataExportArchive(context, export.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, invalidLinkTokenError()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get data export archive: %w", err)
+	}
+
+	return &dto.DownloadDataExportResponse{
+		FileName: fmt.Sprintf("data-export-%d.zip", export.ID),
+		Archive:  archive,
+	}, nil
+}
+
+// dataExportStatus Get the status to report for an export, which is expired rather than ready once the link expires
+func dataExportStatus(export db.DataExport) string {
+	if export.Status == DataExportReady && export.ExpiresAt.Valid && !time.Now().Before(export.ExpiresAt.Time) {
+		return DataExportExpired
+	}
+	return export.Status
+}
+
+// toDataExportDTO Convert a data_exports row to the status returned to the user
+func toDataExportDTO(export db.DataExport) *dto.DataExport {
+	return &dto.DataExport{
+		ExportId:    export.ID,
+		Status:      dataExportStatus(export),
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: nullableTime(export.CompletedAt),
+		ExpiresAt:   nullableTime(export.ExpiresAt),
+	}
+}
+
+// nullableTime Get a pointer to the time, or nil if it is null
+func nullableTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// DataExportSource Adds one JSON file to a user's data export. Personal data kept by other services, such as game
+// history, is included by appending a source to DataExporter.Sources, as NewGameDataExportSource does
+type DataExportSource struct {
+	// Name File name within the archive
+	Name string
+	// Load Get the user's data to write to the file
+	Load func(ctx context.Context, queries db.Querier, userId int32) (any, error)
+}
+
+// DefaultDataExportSources The user service's own data: the account, its archived copies, its email changes and its
+// lifecycle events
+var DefaultDataExportSources = []DataExportSource{
+	{Name: "account.json", Load: loadExportedAccount},
+	{Name: "archive.json", Load: loadExportedArchive},
+	{Name: "email_changes.json", Load: loadExportedEmailChanges},
+	{Name: "events.json", Load: loadExportedEvents},
+}
+
+// DataExporter Builds requested data exports into zip archives of JSON files, one file per source plus a manifest,
+// and emails the user a download link. Exports are claimed with a lease, so an export abandoned by a crashed worker
+// is built again once the lease runs out. Archives are deleted once their download link expires
+type DataExporter struct {
+	Service *ServiceImpl
+	Logger  *slog.Logger
+	Sources []DataExportSource
+
+	PollInterval time.Duration
+	Lease        time.Duration
+	TTL          time.Duration
+}
+
+// NewDataExporter Create an exporter of the default sources from the data export configuration, along with the game
+// service's data when its url is configured
+func NewDataExporter(service *ServiceImpl, config *common.DataExportConfig, logger *slog.Logger) *DataExporter {
+	sources := DefaultDataExportSources
+	if config.GameServiceUrl != "" {
+		sources = append(
+			slices.Clip(sources),
+			NewGameDataExportSource(config.GameServiceUrl, config.GameServiceSecret),
+		)
+	}
+
+	return &DataExporter{
+		Service:      service,
+		Logger:       logger,
+		Sources:      sources,
+		PollInterval: config.PollInterval,
+		Lease:        config.Lease,
+		TTL:          config.TTL,
+	}
+}
+
+// NewGameDataExportSource Create a source adding the user's games, leaderboard scores and ratings, asked of the game
+// service at the url with a request signed with the secret
+func NewGameDataExportSource(url string, secret string) DataExportSource {
+	client := &http.Client{Timeout: common.DefaultWebhookTimeout}
+	url = strings.TrimSuffix(url, "/") + "/internal/user-data"
+
+	return DataExportSource{
+		Name: "games.json",
+		Load: func(ctx context.Context, queries db.Querier, userId int32) (any, error) {
+			body, err := json.Marshal(map[string]int32{"userId": userId})
+			if err != nil {
+				return nil, err
+			}
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create game service request: %w", err)
+			}
+			request.Header.Set("Content-Type", "application/json")
+			common.SignRequest(request, []byte(secret), body, time.Now())
+
+			response, err := client.Do(request)
+			if err != nil {
+				return nil, fmt.Errorf("failed to reach game service: %w", err)
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("game service responded with status %d", response.StatusCode)
+			}
+
+			var data json.RawMessage
+			if err := json.NewDecoder(response.Body).Decode(&data); err != nil {
+				return nil, fmt.Errorf("failed to decode game service response: %w", err)
+			}
+			return data, nil
+		},
+	}
+}
+
+// Run Build pending exports every poll interval until the context is cancelled, starting immediately
+func (exporter *DataExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(exporter.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := exporter.ProcessNext(ctx)
+			if err != nil && ctx.Err() == nil {
+				exporter.Logger.ErrorContext(ctx, "failed to build data export", slog.Any("error", err))
+			}
+			if !processed {
+				break
+			}
+		}
+
+		deleted, err := exporter.Service.Queries.DeleteExpiredDataExportArchives(ctx)
+		if err != nil && ctx.Err() == nil {
+			exporter.Logger.ErrorContext(ctx, "failed to delete expired data exports", slog.Any("error", err))
+		} else if deleted > 0 {
+			exporter.Logger.InfoContext(ctx, "deleted expired data exports", slog.Int64("count", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessNext Claim and build the next pending export, returning whether there was one. An export that cannot be
+// built is marked failed and its error returned
+func (exporter *DataExporter) ProcessNext(ctx context.Context) (bool, error) {
+	export, err := exporter.Service.Queries.ClaimDataExport(ctx, exporter.Lease.Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to claim data export: %w", err)
+	}
+
+	user, err := getUserById(ctx, exporter.Service.Queries, export.UserID)
+	if err == nil {
+		err = exporter.complete(ctx, export, user)
+	}
+	if err != nil {
+		markErr := exporter.Service.Queries.MarkDataExportFailed(
+			ctx, db.MarkDataExportFailedParams{
+				LastError: err.Error(),
+				ID:        export.ID,
+			},
+		)
+		return true, errors.Join(fmt.Errorf("failed to build data export %d: %w", export.ID, err), markErr)
+	}
+	return true, nil
+}
+
+// complete Build the archive for a claimed export, store it and email the user the download link
+func (exporter *DataExporter) complete(ctx context.Context, export db.DataExport, user db.User) error {
+	archive, err := exporter.BuildArchive(ctx, export)
+	if err != nil {
+		return err
+	}
+
+	token, tokenHash, err := newLinkToken()
+	if err != nil {
+		return err
+	}
+
+	err = exporter.Service.runInTx(
+		ctx, nil, func(queries db.Querier) error {
+			err := queries.InsertDataExportArchive(
+				ctx, db.InsertDataExportArchiveParams{
+					DataExportID: export.ID,
+					Archive:      archive,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			return queries.MarkDataExportReady(
+				ctx, db.MarkDataExportReadyParams{
+					DownloadTokenHash: tokenHash,
+					TtlSeconds:        exporter.TTL.Seconds(),
+					ID:                export.ID,
+				},
+			)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store data export archive: %w", err)
+	}
+
+	exporter.Service.sendMails(
+		ctx, []common.MailMessage{
+			{
+				To:      user.Email,
+				Subject: "Your data export is ready",
+				Body: fmt.Sprintf(
+					"Hi %s,\n\nThe copy of your data you asked for is ready. Download it within %s using the link "+
+						"below:\n\n%s\n\nIf you did not ask for your data, please change your password.\n",
+					user.Username,
+					formatLifetime(exporter.TTL),
+					exporter.Service.emailLink("/account/export/download", token),
+				),
+			},
+		},
+	)
+	return nil
+}
+
+// BuildArchive Write every source for the export's user to a zip archive, along with a manifest listing the files
+func (exporter *DataExporter) BuildArchive(ctx context.Context, export db.DataExport) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+
+	manifest := dto.DataExportManifest{
+		ExportId:    export.ID,
+		UserId:      int(export.UserID),
+		RequestedAt: export.CreatedAt,
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, source := range exporter.Sources {
+		data, err := source.Load(ctx, exporter.Service.Queries, export.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", source.Name, err)
+		}
+		if err := writeArchiveJSON(writer, source.Name, data); err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, source.Name)
+	}
+
+	if err := writeArchiveJSON(writer, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write data export archive: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// writeArchiveJSON Add a file containing the data as indented JSON to the archive
+func writeArchiveJSON(writer *zip.Writer, name string, data any) error {
+	file, err := writer.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// loadExportedAccount Get the user's account, without the password hash
+func loadExportedAccount(ctx context.Context, queries db.Querier, userId int32) (any, error) {
+	user, err := getUserById(ctx, queries, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.DataExportAccount{
+		ExportedUser: dto.ExportedUser{
+			UserId:     int(user.ID),
+			Username:   user.Username,
+			Email:      user.Email,
+			IsVerified: user.IsVerified,
+			CreatedAt:  user.CreatedAt,
+			UpdatedAt:  user.UpdatedAt,
+		},
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
+
+// loadExportedArchive Get the copies of the account archived when it was previously deleted
+func loadExportedArchive(ctx context.Context, queries db.Querier, userId int32) (any, error) {
+	rows, err := queries.GetUserArchive(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]dto.DataExportArchivedAccount, len(rows))
+	for i, row := range rows {
+		accounts[i] = dto.DataExportArchivedAccount{
+			ExportedUser: dto.ExportedUser{
+				UserId:     int(row.UsersID),
+				Username:   row.Username,
+				Email:      row.Email,
+				IsVerified: row.IsVerified,
+				CreatedAt:  row.CreatedAt,
+				UpdatedAt:  row.UpdatedAt,
+			},
+			ArchivedAt:   row.ArchivedAt,
+			AnonymizedAt: nullableTime(row.AnonymizedAt),
+		}
+	}
+	return accounts, nil
+}
+
+// loadExportedEmailChanges Get the history of the user's email changes, without their tokens
+func loadExportedEmailChanges(ctx context.Context, queries db.Querier, userId int32) (any, error) {
+	rows, err := queries.GetEmailChangesByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]dto.DataExportEmailChange, len(rows))
+	for i, row := range rows {
+		changes[i] = dto.DataExportEmailChange{
+			OldEmail:    row.OldEmail,
+			NewEmail:    row.NewEmail,
+			RequestedAt: row.CreatedAt,
+			ConfirmedAt: nullableTime(row.ConfirmedAt),
+			UndoneAt:    nullableTime(row.UndoneAt),
+			CancelledAt: nullableTime(row.CancelledAt),
+		}
+	}
+	return changes, nil
+}
+
+// loadExportedEvents Get the lifecycle events still kept in the outbox for the user
+func loadExportedEvents(ctx context.Context, queries db.Querier, userId int32) (any, error) {
+	rows, err := queries.GetOutboxEventsByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]dto.DataExportEvent, len(rows))
+	for i, row := range rows {
+		events[i] = dto.DataExportEvent{
+			EventType: row.EventType,
+			CreatedAt: row.CreatedAt,
+			Payload:   row.Payload,
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,288 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"common"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user/db/generated"
+	"user/dto"
+)
+
+func newTestDataExporter(service *ServiceImpl) *DataExporter {
+	return &DataExporter{
+		Service:      service,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Sources:      DefaultDataExportSources,
+		PollInterval: time.Millisecond,
+		Lease:        time.Minute,
+		TTL:          24 * time.Hour,
+	}
+}
+
+// buildTestDataExport Request an export for the user and build it, returning the export and the download token from
+// the email
+func buildTestDataExport(
+	t *testing.T,
+	service *ServiceImpl,
+	mailer *common.MemoryMailer,
+	exporter *DataExporter,
+	userId int,
+) (*dto.DataExport, string) {
+	export, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	if export.Status != DataExportPending {
+		t.Errorf(`export.Status = "%s", expected "%s"`, export.Status, DataExportPending)
+	}
+
+	if processed, err := exporter.ProcessNext(context.Background()); !processed || err != nil {
+		t.Fatalf(`exporter.ProcessNext(ctx) = "%v", "%v", expected "true", "<nil>"`, processed, err)
+	}
+
+	messages := mailer.Messages()
+	if len(messages) == 0 || messages[len(messages)-1].To != ValidEmail {
+		t.Fatalf(`messages = "%+v", expected a mail to "%s"`, messages, ValidEmail)
+	}
+	return export, linkToken(t, messages[len(messages)-1].Body, "/account/export/download")
+}
+
+// readArchiveFile Decode a JSON file from a zip archive
+func readArchiveFile(t *testing.T, archive []byte, name string, value any) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf(`zip.NewReader(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	file, err := reader.Open(name)
+	if err != nil {
+		t.Fatalf(`reader.Open("%s") error = "%v", expected "<nil>"`, name, err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(value); err != nil {
+		t.Fatalf(`json.NewDecoder(%s).Decode(...) error = "%v", expected "<nil>"`, name, err)
+	}
+}
+
+func TestService_DataExport(t *testing.T) {
+	service, _, mailer, userId := newDeletionTestService(t)
+	requestTestEmailChange(t, service, mailer, userId, newEmail)
+	exporter := newTestDataExporter(service)
+
+	export, token := buildTestDataExport(t, service, mailer, exporter, userId)
+
+	status, err := service.GetDataExport(
+		context.Background(),
+		&dto.GetDataExportRequest{UserId: userId, ExportId: export.ExportId},
+	)
+	if err != nil {
+		t.Fatalf(`service.GetDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	if status.Status != DataExportReady || status.ExpiresAt == nil {
+		t.Errorf(`status = "%+v", expected a ready export with an expiry`, status)
+	}
+
+	again, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	if again.ExportId != export.ExportId {
+		t.Errorf(`again.ExportId = "%d", expected the ready export "%d"`, again.ExportId, export.ExportId)
+	}
+
+	download, err := service.DownloadDataExport(context.Background(), &dto.DownloadDataExportRequest{Token: token})
+	if err != nil {
+		t.Fatalf(`service.DownloadDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	var manifest dto.DataExportManifest
+	readArchiveFile(t, download.Archive, "manifest.json", &manifest)
+	if manifest.UserId != userId || len(manifest.Files) != len(DefaultDataExportSources) {
+		t.Errorf(`manifest = "%+v", expected user "%d" with every source`, manifest, userId)
+	}
+
+	var account map[string]any
+	readArchiveFile(t, download.Archive, "account.json", &account)
+	if account["email"] != ValidEmail || account["pendingEmail"] != newEmail {
+		t.Errorf(`account = "%v", expected email "%s" pending "%s"`, account, ValidEmail, newEmail)
+	}
+	if _, ok := account["passwordHash"]; ok {
+		t.Errorf(`account = "%v", expected no password hash`, account)
+	}
+
+	var changes []dto.DataExportEmailChange
+	readArchiveFile(t, download.Archive, "email_changes.json", &changes)
+	if len(changes) != 1 || changes[0].NewEmail != newEmail {
+		t.Errorf(`changes = "%+v", expected the change to "%s"`, changes, newEmail)
+	}
+
+	var events []dto.DataExportEvent
+	readArchiveFile(t, download.Archive, "events.json", &events)
+	if len(events) == 0 || events[0].EventType != EventUserCreated {
+		t.Errorf(`events = "%+v", expected "%s" first`, events, EventUserCreated)
+	}
+}
+
+func TestService_RequestDataExport_InProgress(t *testing.T) {
+	service, _, _, userId := newDeletionTestService(t)
+
+	first, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	second, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if second.ExportId != first.ExportId {
+		t.Errorf(`second.ExportId = "%d", expected the pending export "%d"`, second.ExportId, first.ExportId)
+	}
+}
+
+func TestService_DownloadDataExport_Expired(t *testing.T) {
+	service, queries, mailer, userId := newDeletionTestService(t)
+	exporter := newTestDataExporter(service)
+
+	queries.Now = func() time.Time {
+		return time.Now().Add(-2 * exporter.TTL)
+	}
+	export, token := buildTestDataExport(t, service, mailer, exporter, userId)
+	queries.Now = time.Now
+
+	_, err := service.DownloadDataExport(context.Background(), &dto.DownloadDataExportRequest{Token: token})
+	assertHTTPError(t, err, http.StatusBadRequest)
+
+	status, err := service.GetDataExport(
+		context.Background(),
+		&dto.GetDataExportRequest{UserId: userId, ExportId: export.ExportId},
+	)
+	if err != nil {
+		t.Fatalf(`service.GetDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	if status.Status != DataExportExpired {
+		t.Errorf(`status.Status = "%s", expected "%s"`, status.Status, DataExportExpired)
+	}
+
+	if deleted, err := queries.DeleteExpiredDataExportArchives(context.Background()); err != nil || deleted != 1 {
+		t.Errorf(`queries.DeleteExpiredDataExportArchives(ctx) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+
+	again, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	if again.ExportId == export.ExportId || again.Status != DataExportPending {
+		t.Errorf(`again = "%+v", expected a new pending export`, again)
+	}
+}
+
+func TestService_GetDataExport_OtherUser(t *testing.T) {
+	service, _, _, userId := newDeletionTestService(t)
+
+	export, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err = service.GetDataExport(
+		context.Background(),
+		&dto.GetDataExportRequest{UserId: userId + 1, ExportId: export.ExportId},
+	)
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestDataExporter_ProcessNext_Failure(t *testing.T) {
+	service, _, mailer, userId := newDeletionTestService(t)
+	exporter := newTestDataExporter(service)
+	exporter.Sources = []DataExportSource{
+		{
+			Name: "games.json",
+			Load: func(ctx context.Context, queries db.Querier, userId int32) (any, error) {
+				return nil, errors.New("game service unavailable")
+			},
+		},
+	}
+
+	export, err := service.RequestDataExport(context.Background(), &dto.RequestDataExportRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.RequestDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if processed, err := exporter.ProcessNext(context.Background()); !processed || err == nil {
+		t.Fatalf(`exporter.ProcessNext(ctx) = "%v", "%v", expected "true", non-nil`, processed, err)
+	}
+	if processed, err := exporter.ProcessNext(context.Background()); processed || err != nil {
+		t.Errorf(`exporter.ProcessNext(ctx) = "%v", "%v", expected "false", "<nil>"`, processed, err)
+	}
+	if len(mailer.Messages()) != 0 {
+		t.Errorf(`len(mailer.Messages()) = "%d", expected "0"`, len(mailer.Messages()))
+	}
+
+	status, err := service.GetDataExport(
+		context.Background(),
+		&dto.GetDataExportRequest{UserId: userId, ExportId: export.ExportId},
+	)
+	if err != nil {
+		t.Fatalf(`service.GetDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	if status.Status != DataExportFailed {
+		t.Errorf(`status.Status = "%s", expected "%s"`, status.Status, DataExportFailed)
+	}
+}
+
+func TestDeletionPurger_DeletesDataExports(t *testing.T) {
+	service, queries, mailer, userId := newDeletionTestService(t)
+	_, token := buildTestDataExport(t, service, mailer, newTestDataExporter(service), userId)
+	scheduleTestDeletion(t, service, mailer, userId)
+
+	queries.Now = func() time.Time {
+		return time.Now().Add(service.DeletionGracePeriod + time.Minute)
+	}
+	if deleted, err := newTestDeletionPurger(service).PurgeBatch(context.Background()); err != nil || deleted != 1 {
+		t.Fatalf(`purger.PurgeBatch(ctx) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+
+	_, err := service.DownloadDataExport(context.Background(), &dto.DownloadDataExportRequest{Token: token})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestNewGameDataExportSource(t *testing.T) {
+	secret := []byte("secret")
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				err := common.VerifyWebhook(secret, r.Header, body, time.Now(), time.Minute)
+				if err != nil || r.URL.Path != "/internal/user-data" || string(body) != `{"userId":7}` {
+					http.Error(w, "unexpected request", http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write([]byte(`{"userId":7,"games":[]}`))
+			},
+		),
+	)
+	defer server.Close()
+
+	data, err := NewGameDataExportSource(server.URL+"/", string(secret)).Load(context.Background(), nil, 7)
+	if err != nil {
+		t.Fatalf(`source.Load(ctx, nil, 7) error = "%v", expected "<nil>"`, err)
+	}
+	if raw, _ := json.Marshal(data); string(raw) != `{"userId":7,"games":[]}` {
+		t.Errorf(`data = "%s", expected the game service's response`, raw)
+	}
+
+	if _, err := NewGameDataExportSource(server.URL, "other").Load(context.Background(), nil, 7); err == nil {
+		t.Error(`source.Load(ctx, nil, 7) error = "<nil>" with the wrong secret, expected non-nil`)
+	}
+}
@@ -0,0 +1,892 @@
+// Package memory contains a thread-safe in-memory implementation of db.Querier for tests and local runs
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"user/db/generated"
+)
+
+// ErrUniqueViolation Returned when a write would violate a unique constraint, mirroring Postgres
+var ErrUniqueViolation = errors.New("duplicate key value violates unique constraint")
+
+// Querier In-memory db.Querier mirroring the users, users_archive, email_changes, user_outbox, data_exports and
+// data_export_archives tables and their triggers
+type Querier struct {
+	*tables
+	// inTx Whether this is the handle RunInTx passes to its function, whose calls run inside the transaction
+	inTx bool
+}
+
+// tables State shared by a querier and the handles of its transactions
+type tables struct {
+	// txMutex Held by a running transaction, and shared by every call made outside of it, so that no other caller
+	// sees or writes the store until the transaction commits or rolls back
+	txMutex   sync.RWMutex
+	mutex     sync.RWMutex
+	users     map[int32]db.User
+	archive   []db.UsersArchive
+	nextId    int32
+	archiveId int32
+	outbox    []db.UserOutbox
+	outboxId  int64
+	changes   []db.EmailChange
+	changeId  int64
+	exports   []db.DataExport
+	exportId  int64
+	archives  map[int64][]byte
+
+	// Now Clock used for created_at, updated_at, archived_at and the outbox and data export timestamps
+	Now func() time.Time
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New Create an empty in-memory querier
+func New() *Querier {
+	return &Querier{
+		tables: &tables{
+			users:    map[int32]db.User{},
+			nextId:   1,
+			archives: map[int64][]byte{},
+			Now:      time.Now,
+		},
+	}
+}
+
+// CountUsers CountUsers() implementation from db.Querier interface
+func (q *Querier) CountUsers(ctx context.Context) (int64, error) {
+	defer q.rlock()()
+
+	return int64(len(q.users)), nil
+}
+
+// CreateUser CreateUser() implementation from db.Querier interface
+func (q *Querier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	defer q.lock()()
+
+	if err := q.checkUnique(0, arg.Username, arg.Email); err != nil {
+		return db.User{}, err
+	}
+
+	now := q.Now()
+	user := db.User{
+		ID:           q.nextId,
+		Username:     arg.Username,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+		IsVerified:   false,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	q.users[user.ID] = user
+	q.nextId++
+
+	return user, nil
+}
+
+// DeleteUser DeleteUser() implementation from db.Querier interface. The deleted row is copied to the archive
+func (q *Querier) DeleteUser(ctx context.Context, id int32) error {
+	defer q.lock()()
+
+	if user, ok := q.users[id]; ok {
+		q.archiveUser(user)
+	}
+	return nil
+}
+
+// GetUser GetUser() implementation from db.Querier interface
+func (q *Querier) GetUser(ctx context.Context, arg db.GetUserParams) (db.User, error) {
+	defer q.rlock()()
+
+	for _, user := range q.sortedUsers() {
+		if arg.ID.Valid && user.ID != arg.ID.Int32 {
+			continue
+		}
+		if arg.Username.Valid && user.Username != arg.Username.String {
+			continue
+		}
+		if arg.Email.Valid && user.Email != arg.Email.String {
+			continue
+		}
+		return user, nil
+	}
+
+	return db.User{}, sql.ErrNoRows
+}
+
+// GetUsers GetUsers() implementation from db.Querier interface
+func (q *Querier) GetUsers(ctx context.Context, arg db.GetUsersParams) ([]db.User, error) {
+	defer q.rlock()()
+
+	if arg.Limit < 0 || arg.Offset < 0 {
+		return nil, errors.New("LIMIT and OFFSET must not be negative")
+	}
+
+	users := q.sortedUsers()
+	start := min(int(arg.Offset), len(users))
+	end := min(start+int(arg.Limit), len(users))
+
+	return users[start:end], nil
+}
+
+// UpdateUser UpdateUser() implementation from db.Querier interface
+func (q *Querier) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.users[arg.ID]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	if arg.Username.Valid {
+		user.Username = arg.Username.String
+	}
+	if arg.Email.Valid {
+		user.Email = arg.Email.String
+	}
+	if arg.PasswordHash.Valid {
+		user.PasswordHash = arg.PasswordHash.String
+	}
+
+	if err := q.checkUnique(user.ID, user.Username, user.Email); err != nil {
+		return db.User{}, err
+	}
+
+	user.UpdatedAt = q.Now()
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// SetUserVerified SetUserVerified() implementation from db.Querier interface
+func (q *Querier) SetUserVerified(ctx context.Context, arg db.SetUserVerifiedParams) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.users[arg.ID]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	user.IsVerified = arg.IsVerified
+	user.UpdatedAt = q.Now()
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// RestoreUser RestoreUser() implementation from db.Querier interface
+func (q *Querier) RestoreUser(ctx context.Context, usersID int32) (db.User, error) {
+	defer q.lock()()
+
+	var latest *db.UsersArchive
+	for i := range q.archive {
+		if q.archive[i].AnonymizedAt.Valid {
+			continue
+		}
+		if q.archive[i].UsersID == usersID && (latest == nil || !q.archive[i].ArchivedAt.Before(latest.ArchivedAt)) {
+			latest = &q.archive[i]
+		}
+	}
+	if latest == nil {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	if _, ok := q.users[usersID]; ok {
+		return db.User{}, fmt.Errorf("%w \"users_pkey\"", ErrUniqueViolation)
+	}
+	if err := q.checkUnique(usersID, latest.Username, latest.Email); err != nil {
+		return db.User{}, err
+	}
+
+	user := db.User{
+		ID:           latest.UsersID,
+		Username:     latest.Username,
+		Email:        latest.Email,
+		PasswordHash: latest.PasswordHash,
+		IsVerified:   latest.IsVerified,
+		CreatedAt:    latest.CreatedAt,
+		UpdatedAt:    q.Now(),
+	}
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// SetUserPendingEmail SetUserPendingEmail() implementation from db.Querier interface
+func (q *Querier) SetUserPendingEmail(ctx context.Context, arg db.SetUserPendingEmailParams) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.users[arg.ID]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	user.PendingEmail = arg.PendingEmail
+	user.UpdatedAt = q.Now()
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// SetUserEmail SetUserEmail() implementation from db.Querier interface
+func (q *Querier) SetUserEmail(ctx context.Context, arg db.SetUserEmailParams) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.users[arg.ID]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	if err := q.checkUnique(user.ID, user.Username, arg.Email); err != nil {
+		return db.User{}, err
+	}
+
+	user.Email = arg.Email
+	user.IsVerified = arg.IsVerified
+	user.PendingEmail = sql.NullString{}
+	user.UpdatedAt = q.Now()
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// CreateEmailChange CreateEmailChange() implementation from db.Querier interface
+func (q *Querier) CreateEmailChange(ctx context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error) {
+	defer q.lock()()
+
+	for _, change := range q.changes {
+		if change.ConfirmTokenHash == arg.ConfirmTokenHash || change.UndoTokenHash == arg.UndoTokenHash {
+			return db.EmailChange{}, fmt.Errorf("%w \"email_changes_token_hash_key\"", ErrUniqueViolation)
+		}
+	}
+
+	q.changeId++
+	change := db.EmailChange{
+		ID:               q.changeId,
+		UserID:           arg.UserID,
+		OldEmail:         arg.OldEmail,
+		NewEmail:         arg.NewEmail,
+		OldIsVerified:    arg.OldIsVerified,
+		ConfirmTokenHash: arg.ConfirmTokenHash,
+		UndoTokenHash:    arg.UndoTokenHash,
+		ConfirmExpiresAt: arg.ConfirmExpiresAt,
+		UndoExpiresAt:    arg.UndoExpiresAt,
+		CreatedAt:        q.Now(),
+	}
+	q.changes = append(q.changes, change)
+
+	return change, nil
+}
+
+// GetEmailChangeByConfirmToken GetEmailChangeByConfirmToken() implementation from db.Querier interface
+func (q *Querier) GetEmailChangeByConfirmToken(ctx context.Context, confirmTokenHash string) (db.EmailChange, error) {
+	defer q.rlock()()
+
+	for _, change := range q.changes {
+		if change.ConfirmTokenHash == confirmTokenHash {
+			return change, nil
+		}
+	}
+	return db.EmailChange{}, sql.ErrNoRows
+}
+
+// GetEmailChangeByUndoToken GetEmailChangeByUndoToken() implementation from db.Querier interface
+func (q *Querier) GetEmailChangeByUndoToken(ctx context.Context, undoTokenHash string) (db.EmailChange, error) {
+	defer q.rlock()()
+
+	for _, change := range q.changes {
+		if change.UndoTokenHash == undoTokenHash {
+			return change, nil
+		}
+	}
+	return db.EmailChange{}, sql.ErrNoRows
+}
+
+// CancelPendingEmailChanges CancelPendingEmailChanges() implementation from db.Querier interface
+func (q *Querier) CancelPendingEmailChanges(ctx context.Context, userID int32) error {
+	defer q.lock()()
+
+	now := sql.NullTime{Time: q.Now(), Valid: true}
+	for i := range q.changes {
+		change := &q.changes[i]
+		if change.UserID == userID && !change.ConfirmedAt.Valid && !change.UndoneAt.Valid && !change.CancelledAt.Valid {
+			change.CancelledAt = now
+		}
+	}
+	return nil
+}
+
+// MarkEmailChangeConfirmed MarkEmailChangeConfirmed() implementation from db.Querier interface
+func (q *Querier) MarkEmailChangeConfirmed(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	if change := q.findEmailChange(id); change != nil {
+		change.ConfirmedAt = sql.NullTime{Time: q.Now(), Valid: true}
+	}
+	return nil
+}
+
+// MarkEmailChangeUndone MarkEmailChangeUndone() implementation from db.Querier interface
+func (q *Querier) MarkEmailChangeUndone(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	if change := q.findEmailChange(id); change != nil {
+		change.UndoneAt = sql.NullTime{Time: q.Now(), Valid: true}
+	}
+	return nil
+}
+
+// GetEmailChangesByUser GetEmailChangesByUser() implementation from db.Querier interface
+func (q *Querier) GetEmailChangesByUser(ctx context.Context, userID int32) ([]db.EmailChange, error) {
+	defer q.rlock()()
+
+	var changes []db.EmailChange
+	for _, change := range q.changes {
+		if change.UserID == userID {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// ScheduleUserDeletion ScheduleUserDeletion() implementation from db.Querier interface
+func (q *Querier) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.users[arg.ID]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	for _, other := range q.users {
+		if other.ID != user.ID && other.DeletionTokenHash.String == arg.DeletionTokenHash {
+			return db.User{}, fmt.Errorf("%w \"users_deletion_token_hash_key\"", ErrUniqueViolation)
+		}
+	}
+
+	now := q.Now()
+	user.DeletionScheduledAt = sql.NullTime{Time: now.Add(seconds(arg.GraceSeconds)), Valid: true}
+	user.DeletionTokenHash = sql.NullString{String: arg.DeletionTokenHash, Valid: true}
+	user.UpdatedAt = now
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// CancelUserDeletion CancelUserDeletion() implementation from db.Querier interface
+func (q *Querier) CancelUserDeletion(ctx context.Context, id int32) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.users[id]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	user.DeletionScheduledAt = sql.NullTime{}
+	user.DeletionTokenHash = sql.NullString{}
+	user.UpdatedAt = q.Now()
+	q.users[user.ID] = user
+
+	return user, nil
+}
+
+// GetUserByDeletionToken GetUserByDeletionToken() implementation from db.Querier interface
+func (q *Querier) GetUserByDeletionToken(ctx context.Context, deletionTokenHash sql.NullString) (db.User, error) {
+	defer q.rlock()()
+
+	if deletionTokenHash.Valid {
+		for _, user := range q.users {
+			if user.DeletionTokenHash == deletionTokenHash {
+				return user, nil
+			}
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+// GetUsersDueForDeletion GetUsersDueForDeletion() implementation from db.Querier interface
+func (q *Querier) GetUsersDueForDeletion(ctx context.Context, batchSize int32) ([]db.User, error) {
+	defer q.rlock()()
+
+	now := q.Now()
+	var due []db.User
+	for _, user := range q.users {
+		if user.DeletionScheduledAt.Valid && !user.DeletionScheduledAt.Time.After(now) {
+			due = append(due, user)
+		}
+	}
+	sort.Slice(
+		due, func(i, j int) bool {
+			return due[i].DeletionScheduledAt.Time.Before(due[j].DeletionScheduledAt.Time)
+		},
+	)
+
+	return due[:min(len(due), int(batchSize))], nil
+}
+
+// DeleteUserIfDue DeleteUserIfDue() implementation from db.Querier interface. The deleted row is copied to the
+// archive
+func (q *Querier) DeleteUserIfDue(ctx context.Context, id int32) (int64, error) {
+	defer q.lock()()
+
+	user, ok := q.users[id]
+	if !ok || !user.DeletionScheduledAt.Valid || user.DeletionScheduledAt.Time.After(q.Now()) {
+		return 0, nil
+	}
+
+	q.archiveUser(user)
+	return 1, nil
+}
+
+// AnonymizeArchivedUsers AnonymizeArchivedUsers() implementation from db.Querier interface
+func (q *Querier) AnonymizeArchivedUsers(ctx context.Context, retentionSeconds float64) (int64, error) {
+	defer q.lock()()
+
+	now := q.Now()
+	cutoff := now.Add(-seconds(retentionSeconds))
+	var anonymized int64
+	for i := range q.archive {
+		row := &q.archive[i]
+		if row.AnonymizedAt.Valid || !row.ArchivedAt.Before(cutoff) {
+			continue
+		}
+		row.Username = "deleted"
+		row.Email = fmt.Sprintf("deleted-%d@anonymized.invalid", row.ID)
+		row.PasswordHash = ""
+		row.AnonymizedAt = sql.NullTime{Time: now, Valid: true}
+		anonymized++
+	}
+
+	return anonymized, nil
+}
+
+// GetUserArchive GetUserArchive() implementation from db.Querier interface
+func (q *Querier) GetUserArchive(ctx context.Context, usersID int32) ([]db.UsersArchive, error) {
+	defer q.rlock()()
+
+	var rows []db.UsersArchive
+	for _, row := range q.archive {
+		if row.UsersID == usersID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// InsertOutboxEvent InsertOutboxEvent() implementation from db.Querier interface
+func (q *Querier) InsertOutboxEvent(ctx context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error) {
+	defer q.lock()()
+
+	now := q.Now()
+	q.outboxId++
+	event := db.UserOutbox{
+		ID:            q.outboxId,
+		EventType:     arg.EventType,
+		UserID:        arg.UserID,
+		Payload:       append([]byte(nil), arg.Payload...),
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	q.outbox = append(q.outbox, event)
+
+	return event, nil
+}
+
+// ClaimOutboxEvents ClaimOutboxEvents() implementation from db.Querier interface
+func (q *Querier) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.UserOutbox, error) {
+	defer q.lock()()
+
+	now := q.Now()
+	var claimed []db.UserOutbox
+	for i := range q.outbox {
+		if len(claimed) >= int(arg.BatchSize) {
+			break
+		}
+		event := &q.outbox[i]
+		if event.DeliveredAt.Valid || event.NextAttemptAt.After(now) {
+			continue
+		}
+		event.NextAttemptAt = now.Add(seconds(arg.LeaseSeconds))
+		claimed = append(claimed, *event)
+	}
+
+	return claimed, nil
+}
+
+// MarkOutboxEventDelivered MarkOutboxEventDelivered() implementation from db.Querier interface
+func (q *Querier) MarkOutboxEventDelivered(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	if event := q.findOutboxEvent(id); event != nil {
+		event.DeliveredAt = sql.NullTime{Time: q.Now(), Valid: true}
+		event.Attempts++
+		event.LastError = sql.NullString{}
+	}
+	return nil
+}
+
+// MarkOutboxEventFailed MarkOutboxEventFailed() implementation from db.Querier interface
+func (q *Querier) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	defer q.lock()()
+
+	if event := q.findOutboxEvent(arg.ID); event != nil {
+		event.Attempts++
+		event.LastError = sql.NullString{String: arg.LastError, Valid: true}
+		event.NextAttemptAt = q.Now().Add(seconds(arg.RetrySeconds))
+	}
+	return nil
+}
+
+// DeleteDeliveredOutboxEvents DeleteDeliveredOutboxEvents() implementation from db.Querier interface
+func (q *Querier) DeleteDeliveredOutboxEvents(ctx context.Context, retentionSeconds float64) (int64, error) {
+	defer q.lock()()
+
+	cutoff := q.Now().Add(-seconds(retentionSeconds))
+	kept := q.outbox[:0]
+	var deleted int64
+	for _, event := range q.outbox {
+		if event.DeliveredAt.Valid && event.DeliveredAt.Time.Before(cutoff) {
+			deleted++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	q.outbox = kept
+
+	return deleted, nil
+}
+
+// GetOutboxEventsByUser GetOutboxEventsByUser() implementation from db.Querier interface
+func (q *Querier) GetOutboxEventsByUser(ctx context.Context, userID int32) ([]db.UserOutbox, error) {
+	defer q.rlock()()
+
+	var events []db.UserOutbox
+	for _, event := range q.outbox {
+		if event.UserID == userID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// CreateDataExport CreateDataExport() implementation from db.Querier interface
+func (q *Querier) CreateDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+	defer q.lock()()
+
+	q.exportId++
+	export := db.DataExport{
+		ID:        q.exportId,
+		UserID:    userID,
+		Status:    "pending",
+		CreatedAt: q.Now(),
+	}
+	q.exports = append(q.exports, export)
+
+	return export, nil
+}
+
+// GetDataExport GetDataExport() implementation from db.Querier interface
+func (q *Querier) GetDataExport(ctx context.Context, arg db.GetDataExportParams) (db.DataExport, error) {
+	defer q.rlock()()
+
+	if export := q.findDataExport(arg.ID); export != nil && export.UserID == arg.UserID {
+		return *export, nil
+	}
+	return db.DataExport{}, sql.ErrNoRows
+}
+
+// GetLatestDataExport GetLatestDataExport() implementation from db.Querier interface
+func (q *Querier) GetLatestDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+	defer q.rlock()()
+
+	for i := len(q.exports) - 1; i >= 0; i-- {
+		if q.exports[i].UserID == userID {
+			return q.exports[i], nil
+		}
+	}
+	return db.DataExport{}, sql.ErrNoRows
+}
+
+// GetDataExportByDownloadToken GetDataExportByDownloadToken() implementation from db.Querier interface
+func (q *Querier) GetDataExportByDownloadToken(
+	ctx context.Context,
+	downloadTokenHash sql.NullString,
+) (db.DataExport, error) {
+	defer q.rlock()()
+
+	if downloadTokenHash.Valid {
+		for _, export := range q.exports {
+			if export.DownloadTokenHash == downloadTokenHash {
+				return export, nil
+			}
+		}
+	}
+	return db.DataExport{}, sql.ErrNoRows
+}
+
+// ClaimDataExport ClaimDataExport() implementation from db.Querier interface
+func (q *Querier) ClaimDataExport(ctx context.Context, leaseSeconds float64) (db.DataExport, error) {
+	defer q.lock()()
+
+	now := q.Now()
+	cutoff := now.Add(-seconds(leaseSeconds))
+	for i := range q.exports {
+		export := &q.exports[i]
+		if export.Status == "pending" || (export.Status == "running" && export.StartedAt.Time.Before(cutoff)) {
+			export.Status = "running"
+			export.StartedAt = sql.NullTime{Time: now, Valid: true}
+			return *export, nil
+		}
+	}
+	return db.DataExport{}, sql.ErrNoRows
+}
+
+// InsertDataExportArchive InsertDataExportArchive() implementation from db.Querier interface
+func (q *Querier) InsertDataExportArchive(ctx context.Context, arg db.InsertDataExportArchiveParams) error {
+	defer q.lock()()
+
+	q.archives[arg.DataExportID] = append([]byte(nil), arg.Archive...)
+	return nil
+}
+
+// GetDataExportArchive GetDataExportArchive() implementation from db.Querier interface
+func (q *Querier) GetDataExportArchive(ctx context.Context, dataExportID int64) ([]byte, error) {
+	defer q.rlock()()
+
+	archive, ok := q.archives[dataExportID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return append([]byte(nil), archive...), nil
+}
+
+// MarkDataExportReady MarkDataExportReady() implementation from db.Querier interface
+func (q *Querier) MarkDataExportReady(ctx context.Context, arg db.MarkDataExportReadyParams) error {
+	defer q.lock()()
+
+	for _, other := range q.exports {
+		if other.ID != arg.ID && other.DownloadTokenHash.String == arg.DownloadTokenHash {
+			return fmt.Errorf("%w \"data_exports_download_token_hash_key\"", ErrUniqueViolation)
+		}
+	}
+
+	if export := q.findDataExport(arg.ID); export != nil {
+		now := q.Now()
+		export.Status = "ready"
+		export.DownloadTokenHash = sql.NullString{String: arg.DownloadTokenHash, Valid: true}
+		export.LastError = sql.NullString{}
+		export.CompletedAt = sql.NullTime{Time: now, Valid: true}
+		export.ExpiresAt = sql.NullTime{Time: now.Add(seconds(arg.TtlSeconds)), Valid: true}
+	}
+	return nil
+}
+
+// MarkDataExportFailed MarkDataExportFailed() implementation from db.Querier interface
+func (q *Querier) MarkDataExportFailed(ctx context.Context, arg db.MarkDataExportFailedParams) error {
+	defer q.lock()()
+
+	if export := q.findDataExport(arg.ID); export != nil {
+		export.Status = "failed"
+		export.LastError = sql.NullString{String: arg.LastError, Valid: true}
+		export.CompletedAt = sql.NullTime{Time: q.Now(), Valid: true}
+	}
+	return nil
+}
+
+// DeleteExpiredDataExportArchives DeleteExpiredDataExportArchives() implementation from db.Querier interface
+func (q *Querier) DeleteExpiredDataExportArchives(ctx context.Context) (int64, error) {
+	defer q.lock()()
+
+	now := q.Now()
+	var deleted int64
+	for _, export := range q.exports {
+		if _, ok := q.archives[export.ID]; ok && export.ExpiresAt.Valid && !export.ExpiresAt.Time.After(now) {
+			delete(q.archives, export.ID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteUserDataExportArchives DeleteUserDataExportArchives() implementation from db.Querier interface
+func (q *Querier) DeleteUserDataExportArchives(ctx context.Context, userID int32) error {
+	defer q.lock()()
+
+	for _, export := range q.exports {
+		if export.UserID == userID {
+			delete(q.archives, export.ID)
+		}
+	}
+	return nil
+}
+
+// RunInTx Run fn as a transaction: every call made outside of it waits until it ends, and every write made by fn is
+// rolled back if it returns an error. Transactions started inside fn join it
+func (q *Querier) RunInTx(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error {
+	if q.inTx {
+		return fn(q)
+	}
+
+	q.txMutex.Lock()
+	defer q.txMutex.Unlock()
+
+	// No other caller can reach the store until the transaction ends, so restoring this snapshot on rollback undoes
+	// only the transaction's own writes
+	users := make(map[int32]db.User, len(q.users))
+	for id, user := range q.users {
+		users[id] = user
+	}
+	archive := append([]db.UsersArchive(nil), q.archive...)
+	outbox := append([]db.UserOutbox(nil), q.outbox...)
+	changes := append([]db.EmailChange(nil), q.changes...)
+	exports := append([]db.DataExport(nil), q.exports...)
+	archives := make(map[int64][]byte, len(q.archives))
+	for id, archive := range q.archives {
+		archives[id] = archive
+	}
+	nextId, archiveId, outboxId, changeId, exportId := q.nextId, q.archiveId, q.outboxId, q.changeId, q.exportId
+
+	if err := fn(&Querier{tables: q.tables, inTx: true}); err != nil {
+		q.mutex.Lock()
+		q.users, q.archive, q.outbox, q.changes = users, archive, outbox, changes
+		q.exports, q.archives = exports, archives
+		q.nextId, q.archiveId, q.outboxId, q.changeId, q.exportId = nextId, archiveId, outboxId, changeId, exportId
+		q.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Archive Get a copy of the users_archive rows in insertion order
+func (q *Querier) Archive() []db.UsersArchive {
+	defer q.rlock()()
+
+	return append([]db.UsersArchive(nil), q.archive...)
+}
+
+// Outbox Get a copy of the user_outbox rows in insertion order
+func (q *Querier) Outbox() []db.UserOutbox {
+	defer q.rlock()()
+
+	return append([]db.UserOutbox(nil), q.outbox...)
+}
+
+// lock Take the write lock, first waiting for any transaction this call is not part of to end, and get the function
+// releasing it
+func (q *Querier) lock() (unlock func()) {
+	if !q.inTx {
+		q.txMutex.RLock()
+	}
+	q.mutex.Lock()
+	return func() {
+		q.mutex.Unlock()
+		if !q.inTx {
+			q.txMutex.RUnlock()
+		}
+	}
+}
+
+// rlock Take the read lock, first waiting for any transaction this call is not part of to end, and get the function
+// releasing it
+func (q *Querier) rlock() (unlock func()) {
+	if !q.inTx {
+		q.txMutex.RLock()
+	}
+	q.mutex.RLock()
+	return func() {
+		q.mutex.RUnlock()
+		if !q.inTx {
+			q.txMutex.RUnlock()
+		}
+	}
+}
+
+// archiveUser Move a user to the archive, like the archive_user trigger
+func (q *Querier) archiveUser(user db.User) {
+	q.archiveId++
+	q.archive = append(
+		q.archive, db.UsersArchive{
+			ID:           q.archiveId,
+			UsersID:      user.ID,
+			Username:     user.Username,
+			Email:        user.Email,
+			PasswordHash: user.PasswordHash,
+			IsVerified:   user.IsVerified,
+			CreatedAt:    user.CreatedAt,
+			UpdatedAt:    user.UpdatedAt,
+			ArchivedAt:   q.Now(),
+		},
+	)
+	delete(q.users, user.ID)
+}
+
+// findEmailChange Get a pointer to the email change with the specified id, or nil if there is none
+func (q *Querier) findEmailChange(id int64) *db.EmailChange {
+	for i := range q.changes {
+		if q.changes[i].ID == id {
+			return &q.changes[i]
+		}
+	}
+	return nil
+}
+
+// findDataExport Get a pointer to the data export with the specified id, or nil if there is none
+func (q *Querier) findDataExport(id int64) *db.DataExport {
+	for i := range q.exports {
+		if q.exports[i].ID == id {
+			return &q.exports[i]
+		}
+	}
+	return nil
+}
+
+// findOutboxEvent Get a pointer to the outbox row with the specified id, or nil if there is none
+func (q *Querier) findOutboxEvent(id int64) *db.UserOutbox {
+	for i := range q.outbox {
+		if q.outbox[i].ID == id {
+			return &q.outbox[i]
+		}
+	}
+	return nil
+}
+
+// checkUnique Return ErrUniqueViolation if another user already has the username or email
+func (q *Querier) checkUnique(id int32, username string, email string) error {
+	for _, user := range q.users {
+		if user.ID == id {
+			continue
+		}
+		if user.Username == username {
+			return fmt.Errorf("%w \"users_username_key\"", ErrUniqueViolation)
+		}
+		if user.Email == email {
+			return fmt.Errorf("%w \"users_email_key\"", ErrUniqueViolation)
+		}
+	}
+	return nil
+}
+
+// sortedUsers Get all users ordered by id
+func (q *Querier) sortedUsers() []db.User {
+	users := make([]db.User, 0, len(q.users))
+	for _, user := range q.users {
+		users = append(users, user)
+	}
+	sort.Slice(
+		users, func(i, j int) bool {
+			return users[i].ID < users[j].ID
+		},
+	)
+	return users
+}
+
+// seconds Convert a number of seconds as passed to the interval queries to a duration
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
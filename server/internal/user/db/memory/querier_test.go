@@ -0,0 +1,372 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"user/db/generated"
+)
+
+func newTestQuerier(now time.Time) *Querier {
+	querier := New()
+	querier.Now = func() time.Time {
+		return now
+	}
+	return querier
+}
+
+func createTestUser(t *testing.T, querier *Querier, username string, email string) db.User {
+	user, err := querier.CreateUser(
+		context.Background(), db.CreateUserParams{
+			Username:     username,
+			Email:        email,
+			PasswordHash: "hash",
+		},
+	)
+	if err != nil {
+		t.Fatalf(`querier.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	return user
+}
+
+func TestQuerier_CreateUser_Success(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	querier := newTestQuerier(now)
+
+	first := createTestUser(t, querier, "first", "first@email.com")
+	second := createTestUser(t, querier, "second", "second@email.com")
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf(`ids = "%d, %d", expected "1, 2"`, first.ID, second.ID)
+	}
+	if first.IsVerified {
+		t.Error(`first.IsVerified = "true", expected "false"`)
+	}
+	if !first.CreatedAt.Equal(now) || !first.UpdatedAt.Equal(now) {
+		t.Errorf(`first.CreatedAt, first.UpdatedAt = "%v, %v", expected "%v"`, first.CreatedAt, first.UpdatedAt, now)
+	}
+}
+
+func TestQuerier_CreateUser_UniqueViolation(t *testing.T) {
+	querier := New()
+	createTestUser(t, querier, "first", "first@email.com")
+
+	tests := []db.CreateUserParams{
+		{Username: "first", Email: "other@email.com"},
+		{Username: "other", Email: "first@email.com"},
+	}
+	for _, params := range tests {
+		if _, err := querier.CreateUser(context.Background(), params); !errors.Is(err, ErrUniqueViolation) {
+			t.Errorf(`querier.CreateUser(%+v) error = "%v", expected "%v"`, params, err, ErrUniqueViolation)
+		}
+	}
+}
+
+func TestQuerier_GetUser_Filters(t *testing.T) {
+	querier := New()
+	createTestUser(t, querier, "first", "first@email.com")
+	second := createTestUser(t, querier, "second", "second@email.com")
+
+	user, err := querier.GetUser(
+		context.Background(), db.GetUserParams{
+			Username: sql.NullString{String: "second", Valid: true},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`querier.GetUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if user.ID != second.ID {
+		t.Errorf(`user.ID = "%d", expected "%d"`, user.ID, second.ID)
+	}
+
+	_, err = querier.GetUser(
+		context.Background(), db.GetUserParams{
+			ID:    sql.NullInt32{Int32: second.ID, Valid: true},
+			Email: sql.NullString{String: "first@email.com", Valid: true},
+		},
+	)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.GetUser(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestQuerier_GetUsers_Pagination(t *testing.T) {
+	querier := New()
+	for _, username := range []string{"a", "b", "c", "d"} {
+		createTestUser(t, querier, username, username+"@email.com")
+	}
+
+	users, err := querier.GetUsers(context.Background(), db.GetUsersParams{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf(`querier.GetUsers(...) error = "%v", expected "<nil>"`, err)
+	}
+	if len(users) != 2 || users[0].Username != "b" || users[1].Username != "c" {
+		t.Errorf(`users = "%+v", expected users "b" and "c"`, users)
+	}
+
+	users, _ = querier.GetUsers(context.Background(), db.GetUsersParams{Limit: 10, Offset: 10})
+	if len(users) != 0 {
+		t.Errorf(`len(users) = "%d", expected "0"`, len(users))
+	}
+
+	count, _ := querier.CountUsers(context.Background())
+	if count != 4 {
+		t.Errorf(`count = "%d", expected "4"`, count)
+	}
+}
+
+func TestQuerier_UpdateUser_Success(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	querier := newTestQuerier(created)
+	user := createTestUser(t, querier, "first", "first@email.com")
+	querier.Now = func() time.Time {
+		return updated
+	}
+
+	user, err := querier.UpdateUser(
+		context.Background(), db.UpdateUserParams{
+			ID:       user.ID,
+			Username: sql.NullString{String: "renamed", Valid: true},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`querier.UpdateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if user.Username != "renamed" || user.Email != "first@email.com" {
+		t.Errorf(`user = "%+v", expected username "renamed" and unchanged email`, user)
+	}
+	if !user.UpdatedAt.Equal(updated) || !user.CreatedAt.Equal(created) {
+		t.Errorf(`user.CreatedAt, user.UpdatedAt = "%v, %v", expected "%v, %v"`, user.CreatedAt, user.UpdatedAt, created, updated)
+	}
+}
+
+func TestQuerier_UpdateUser_Failures(t *testing.T) {
+	querier := New()
+	createTestUser(t, querier, "first", "first@email.com")
+	second := createTestUser(t, querier, "second", "second@email.com")
+
+	_, err := querier.UpdateUser(
+		context.Background(), db.UpdateUserParams{
+			ID:    second.ID,
+			Email: sql.NullString{String: "first@email.com", Valid: true},
+		},
+	)
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf(`querier.UpdateUser(...) error = "%v", expected "%v"`, err, ErrUniqueViolation)
+	}
+
+	_, err = querier.UpdateUser(context.Background(), db.UpdateUserParams{ID: 100})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.UpdateUser(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestQuerier_DeleteAndRestoreUser(t *testing.T) {
+	querier := New()
+	user := createTestUser(t, querier, "first", "first@email.com")
+
+	if err := querier.DeleteUser(context.Background(), user.ID); err != nil {
+		t.Fatalf(`querier.DeleteUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	archive := querier.Archive()
+	if len(archive) != 1 || archive[0].UsersID != user.ID || archive[0].Username != user.Username {
+		t.Errorf(`archive = "%+v", expected one row for user "%d"`, archive, user.ID)
+	}
+
+	if count, _ := querier.CountUsers(context.Background()); count != 0 {
+		t.Errorf(`count = "%d", expected "0"`, count)
+	}
+
+	restored, err := querier.RestoreUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf(`querier.RestoreUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if restored.ID != user.ID || restored.Username != user.Username {
+		t.Errorf(`restored = "%+v", expected "%+v"`, restored, user)
+	}
+
+	if _, err := querier.RestoreUser(context.Background(), user.ID); !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf(`querier.RestoreUser(...) error = "%v", expected "%v"`, err, ErrUniqueViolation)
+	}
+
+	if _, err := querier.RestoreUser(context.Background(), 100); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.RestoreUser(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestQuerier_SetUserVerified(t *testing.T) {
+	querier := New()
+	user := createTestUser(t, querier, "first", "first@email.com")
+
+	user, err := querier.SetUserVerified(context.Background(), db.SetUserVerifiedParams{ID: user.ID, IsVerified: true})
+	if err != nil {
+		t.Fatalf(`querier.SetUserVerified(...) error = "%v", expected "<nil>"`, err)
+	}
+	if !user.IsVerified {
+		t.Error(`user.IsVerified = "false", expected "true"`)
+	}
+}
+
+func TestQuerier_ConcurrentCreates(t *testing.T) {
+	querier := New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = querier.CreateUser(
+				context.Background(), db.CreateUserParams{
+					Username: "same",
+					Email:    "same@email.com",
+				},
+			)
+		}()
+	}
+	wg.Wait()
+
+	if count, _ := querier.CountUsers(context.Background()); count != 1 {
+		t.Errorf(`count = "%d", expected "1"`, count)
+	}
+}
+
+func TestQuerier_RunInTx_Commit(t *testing.T) {
+	querier := New()
+
+	err := querier.RunInTx(
+		context.Background(), nil, func(queries db.Querier) error {
+			_, err := queries.CreateUser(context.Background(), db.CreateUserParams{Username: "first", Email: "first@email.com"})
+			return err
+		},
+	)
+	if err != nil {
+		t.Fatalf(`querier.RunInTx(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if count, _ := querier.CountUsers(context.Background()); count != 1 {
+		t.Errorf(`count = "%d", expected "1"`, count)
+	}
+}
+
+func TestQuerier_RunInTx_Rollback(t *testing.T) {
+	querier := New()
+	user := createTestUser(t, querier, "first", "first@email.com")
+
+	expected := errors.New("rollback")
+	err := querier.RunInTx(
+		context.Background(), nil, func(queries db.Querier) error {
+			if err := queries.DeleteUser(context.Background(), user.ID); err != nil {
+				return err
+			}
+			if _, err := queries.CreateUser(
+				context.Background(),
+				db.CreateUserParams{Username: "second", Email: "second@email.com"},
+			); err != nil {
+				return err
+			}
+			return expected
+		},
+	)
+	if !errors.Is(err, expected) {
+		t.Fatalf(`querier.RunInTx(...) error = "%v", expected "%v"`, err, expected)
+	}
+
+	users, _ := querier.GetUsers(context.Background(), db.GetUsersParams{Limit: 10})
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf(`users = "%+v", expected only user "%d"`, users, user.ID)
+	}
+	if archive := querier.Archive(); len(archive) != 0 {
+		t.Errorf(`archive = "%+v", expected no rows`, archive)
+	}
+
+	second := createTestUser(t, querier, "second", "second@email.com")
+	if second.ID != user.ID+1 {
+		t.Errorf(`second.ID = "%d", expected "%d"`, second.ID, user.ID+1)
+	}
+}
+
+func TestQuerier_RunInTx_RollbackKeepsOtherWrites(t *testing.T) {
+	querier := New()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	expected := errors.New("rollback")
+	go func() {
+		done <- querier.RunInTx(
+			context.Background(), nil, func(queries db.Querier) error {
+				_, err := queries.CreateUser(
+					context.Background(),
+					db.CreateUserParams{Username: "first", Email: "first@email.com"},
+				)
+				if err != nil {
+					return err
+				}
+				close(started)
+				<-release
+				return expected
+			},
+		)
+	}()
+	<-started
+
+	created := make(chan error)
+	go func() {
+		_, err := querier.CreateUser(
+			context.Background(),
+			db.CreateUserParams{Username: "second", Email: "second@email.com"},
+		)
+		created <- err
+	}()
+
+	// Writes from outside the transaction wait for it to end, so rolling it back cannot undo them
+	select {
+	case err := <-created:
+		t.Fatalf(`querier.CreateUser(...) = "%v" while the transaction was running, expected it to wait`, err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-done; !errors.Is(err, expected) {
+		t.Fatalf(`querier.RunInTx(...) error = "%v", expected "%v"`, err, expected)
+	}
+	if err := <-created; err != nil {
+		t.Fatalf(`querier.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	users, _ := querier.GetUsers(context.Background(), db.GetUsersParams{Limit: 10})
+	if len(users) != 1 || users[0].Username != "second" {
+		t.Errorf(`users = "%+v", expected only "second"`, users)
+	}
+}
+
+func TestQuerier_ClaimDataExport_Lease(t *testing.T) {
+	now := time.Now()
+	querier := newTestQuerier(now)
+
+	export, err := querier.CreateDataExport(context.Background(), 1)
+	if err != nil {
+		t.Fatalf(`querier.CreateDataExport(ctx, 1) error = "%v", expected "<nil>"`, err)
+	}
+
+	claimed, err := querier.ClaimDataExport(context.Background(), 60)
+	if err != nil || claimed.ID != export.ID || claimed.Status != "running" {
+		t.Fatalf(`querier.ClaimDataExport(ctx, 60) = "%+v", "%v", expected export "%d" running`, claimed, err, export.ID)
+	}
+	if _, err := querier.ClaimDataExport(context.Background(), 60); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`querier.ClaimDataExport(ctx, 60) error = "%v", expected "%v" while leased`, err, sql.ErrNoRows)
+	}
+
+	querier.Now = func() time.Time {
+		return now.Add(2 * time.Minute)
+	}
+	if claimed, err := querier.ClaimDataExport(context.Background(), 60); err != nil || claimed.ID != export.ID {
+		t.Errorf(`querier.ClaimDataExport(ctx, 60) = "%+v", "%v", expected export "%d" again`, claimed, err, export.ID)
+	}
+}
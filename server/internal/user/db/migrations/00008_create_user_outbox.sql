@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_outbox (
+   id BIGSERIAL PRIMARY KEY,
+   event_type VARCHAR(64) NOT NULL,
+   user_id INTEGER NOT NULL,
+   payload JSONB NOT NULL,
+   attempts INTEGER DEFAULT 0 NOT NULL,
+   last_error TEXT,
+   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   delivered_at TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX user_outbox_pending_idx ON user_outbox (next_attempt_at, id) WHERE delivered_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_outbox;
+-- +goose StatementEnd
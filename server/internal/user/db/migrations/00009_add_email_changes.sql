@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN pending_email VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE email_changes (
+   id BIGSERIAL PRIMARY KEY,
+   user_id INTEGER NOT NULL,
+   old_email VARCHAR(255) NOT NULL,
+   new_email VARCHAR(255) NOT NULL,
+   old_is_verified BOOLEAN NOT NULL,
+   confirm_token_hash TEXT NOT NULL UNIQUE,
+   undo_token_hash TEXT NOT NULL UNIQUE,
+   confirm_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+   undo_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   confirmed_at TIMESTAMP WITH TIME ZONE,
+   undone_at TIMESTAMP WITH TIME ZONE,
+   cancelled_at TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX email_changes_user_id_idx ON email_changes (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE email_changes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN pending_email;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN deletion_token_hash TEXT UNIQUE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX users_deletion_scheduled_at_idx ON users (deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users_archive ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION disable_users_archive_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Anonymizing a row once is the only update allowed
+    IF OLD.anonymized_at IS NULL AND NEW.anonymized_at IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'Updates to users_archive are not allowed';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION disable_users_archive_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'Updates to users_archive are not allowed';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users_archive DROP COLUMN anonymized_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN deletion_token_hash;
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
+-- +goose StatementEnd
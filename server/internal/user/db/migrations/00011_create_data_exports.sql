@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE data_exports (
+   id BIGSERIAL PRIMARY KEY,
+   user_id INTEGER NOT NULL,
+   status VARCHAR(16) DEFAULT 'pending' NOT NULL,
+   download_token_hash TEXT UNIQUE,
+   last_error TEXT,
+   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   started_at TIMESTAMP WITH TIME ZONE,
+   completed_at TIMESTAMP WITH TIME ZONE,
+   expires_at TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX data_exports_queued_idx ON data_exports (id) WHERE status IN ('pending', 'running');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Archives are kept apart from their export so that status checks never load them
+CREATE TABLE data_export_archives (
+   data_export_id BIGINT PRIMARY KEY REFERENCES data_exports (id) ON DELETE CASCADE,
+   archive BYTEA NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE data_export_archives;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE data_exports;
+-- +goose StatementEnd
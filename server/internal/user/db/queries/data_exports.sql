@@ -0,0 +1,79 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (user_id)
+VALUES ($1)
+    RETURNING *;
+
+-- name: GetDataExport :one
+SELECT *
+FROM data_exports
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetLatestDataExport :one
+SELECT *
+FROM data_exports
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetDataExportByDownloadToken :one
+SELECT *
+FROM data_exports
+WHERE download_token_hash = $1;
+
+-- name: ClaimDataExport :one
+-- Claims the oldest pending export, or one whose worker let its lease run out, skipping exports other workers hold
+UPDATE data_exports
+SET status = 'running',
+    started_at = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT id
+    FROM data_exports
+    WHERE status = 'pending'
+        OR (status = 'running' AND started_at < CURRENT_TIMESTAMP - make_interval(secs => sqlc.arg(lease_seconds)::float8))
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING *;
+
+-- name: InsertDataExportArchive :exec
+INSERT INTO data_export_archives (data_export_id, archive)
+VALUES ($1, $2)
+ON CONFLICT (data_export_id) DO UPDATE SET archive = EXCLUDED.archive;
+
+-- name: GetDataExportArchive :one
+SELECT archive
+FROM data_export_archives
+WHERE data_export_id = $1;
+
+-- name: MarkDataExportReady :exec
+UPDATE data_exports
+SET status = 'ready',
+    download_token_hash = sqlc.arg(download_token_hash)::text,
+    last_error = NULL,
+    completed_at = CURRENT_TIMESTAMP,
+    expires_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(ttl_seconds)::float8)
+WHERE id = sqlc.arg(id);
+
+-- name: MarkDataExportFailed :exec
+UPDATE data_exports
+SET status = 'failed',
+    last_error = sqlc.arg(last_error)::text,
+    completed_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteExpiredDataExportArchives :execrows
+DELETE FROM data_export_archives
+WHERE data_export_id IN (
+    SELECT id
+    FROM data_exports
+    WHERE expires_at <= CURRENT_TIMESTAMP
+);
+
+-- name: DeleteUserDataExportArchives :exec
+DELETE FROM data_export_archives
+WHERE data_export_id IN (
+    SELECT id
+    FROM data_exports
+    WHERE user_id = $1
+);
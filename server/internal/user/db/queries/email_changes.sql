@@ -0,0 +1,38 @@
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+    user_id, old_email, new_email, old_is_verified,
+    confirm_token_hash, undo_token_hash, confirm_expires_at, undo_expires_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING *;
+
+-- name: GetEmailChangeByConfirmToken :one
+SELECT *
+FROM email_changes
+WHERE confirm_token_hash = $1;
+
+-- name: GetEmailChangeByUndoToken :one
+SELECT *
+FROM email_changes
+WHERE undo_token_hash = $1;
+
+-- name: CancelPendingEmailChanges :exec
+UPDATE email_changes
+SET cancelled_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND confirmed_at IS NULL AND undone_at IS NULL AND cancelled_at IS NULL;
+
+-- name: MarkEmailChangeConfirmed :exec
+UPDATE email_changes
+SET confirmed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: MarkEmailChangeUndone :exec
+UPDATE email_changes
+SET undone_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: GetEmailChangesByUser :many
+SELECT *
+FROM email_changes
+WHERE user_id = $1
+ORDER BY id;
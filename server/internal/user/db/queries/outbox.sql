@@ -0,0 +1,42 @@
+-- name: InsertOutboxEvent :one
+INSERT INTO user_outbox (event_type, user_id, payload)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+-- Leases the oldest due events so that concurrent relays never deliver the same event at the same time
+UPDATE user_outbox
+SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+WHERE id IN (
+    SELECT id
+    FROM user_outbox
+    WHERE delivered_at IS NULL AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING *;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE user_outbox
+SET delivered_at = CURRENT_TIMESTAMP,
+    attempts = attempts + 1,
+    last_error = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE user_outbox
+SET attempts = attempts + 1,
+    last_error = sqlc.arg(last_error)::text,
+    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(retry_seconds)::float8)
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM user_outbox
+WHERE delivered_at < CURRENT_TIMESTAMP - make_interval(secs => sqlc.arg(retention_seconds)::float8);
+
+-- name: GetOutboxEventsByUser :many
+SELECT *
+FROM user_outbox
+WHERE user_id = $1
+ORDER BY id;
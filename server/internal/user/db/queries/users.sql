@@ -34,4 +34,78 @@ WHERE id = $1
 
 -- name: DeleteUser :exec
 DELETE FROM users
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: SetUserVerified :one
+UPDATE users
+SET is_verified = $2
+WHERE id = $1
+    RETURNING *;
+
+-- name: RestoreUser :one
+INSERT INTO users (id, username, email, password_hash, is_verified, created_at)
+SELECT users_id, username, email, password_hash, is_verified, created_at
+FROM users_archive
+WHERE users_id = $1 AND anonymized_at IS NULL
+ORDER BY archived_at DESC
+LIMIT 1
+    RETURNING *;
+
+-- name: SetUserPendingEmail :one
+UPDATE users
+SET pending_email = sqlc.narg(pending_email)
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: SetUserEmail :one
+UPDATE users
+SET email = sqlc.arg(email),
+    is_verified = sqlc.arg(is_verified),
+    pending_email = NULL
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(grace_seconds)::float8),
+    deletion_token_hash = sqlc.arg(deletion_token_hash)::text
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: CancelUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = NULL,
+    deletion_token_hash = NULL
+WHERE id = $1
+    RETURNING *;
+
+-- name: GetUserByDeletionToken :one
+SELECT *
+FROM users
+WHERE deletion_token_hash = $1;
+
+-- name: GetUsersDueForDeletion :many
+SELECT *
+FROM users
+WHERE deletion_scheduled_at <= CURRENT_TIMESTAMP
+ORDER BY deletion_scheduled_at
+LIMIT sqlc.arg(batch_size);
+
+-- name: DeleteUserIfDue :execrows
+DELETE FROM users
+WHERE id = $1 AND deletion_scheduled_at <= CURRENT_TIMESTAMP;
+
+-- name: AnonymizeArchivedUsers :execrows
+UPDATE users_archive
+SET username = 'deleted',
+    email = 'deleted-' || id || '@anonymized.invalid',
+    password_hash = '',
+    anonymized_at = CURRENT_TIMESTAMP
+WHERE anonymized_at IS NULL
+    AND archived_at < CURRENT_TIMESTAMP - make_interval(secs => sqlc.arg(retention_seconds)::float8);
+
+-- name: GetUserArchive :many
+SELECT *
+FROM users_archive
+WHERE users_id = $1
+ORDER BY archived_at;
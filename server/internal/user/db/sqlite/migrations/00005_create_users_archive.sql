@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users_archive (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   users_id INTEGER NOT NULL,
+   username VARCHAR(15) NOT NULL,
+   email VARCHAR(255) NOT NULL,
+   password_hash TEXT NOT NULL,
+   is_verified BOOLEAN NOT NULL,
+   created_at DATETIME NOT NULL,
+   updated_at DATETIME NOT NULL,
+   archived_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')) NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE users_archive;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TRIGGER archive_user
+BEFORE DELETE ON users
+FOR EACH ROW
+BEGIN
+    INSERT INTO users_archive (
+        users_id, username, email, password_hash, is_verified, created_at, updated_at
+    ) VALUES (
+        OLD.id, OLD.username, OLD.email, OLD.password_hash, OLD.is_verified, OLD.created_at, OLD.updated_at
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS archive_user;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TRIGGER disable_users_archive_update
+BEFORE UPDATE ON users_archive
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'Updates to users_archive are not allowed');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS disable_users_archive_update;
+-- +goose StatementEnd
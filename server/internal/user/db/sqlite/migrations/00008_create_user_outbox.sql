@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_outbox (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   event_type VARCHAR(64) NOT NULL,
+   user_id INTEGER NOT NULL,
+   payload TEXT NOT NULL,
+   attempts INTEGER DEFAULT 0 NOT NULL,
+   last_error TEXT,
+   created_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')) NOT NULL,
+   next_attempt_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')) NOT NULL,
+   delivered_at DATETIME
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX user_outbox_pending_idx ON user_outbox (next_attempt_at, id) WHERE delivered_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_outbox;
+-- +goose StatementEnd
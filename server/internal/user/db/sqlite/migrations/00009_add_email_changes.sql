@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN pending_email VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE email_changes (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   user_id INTEGER NOT NULL,
+   old_email VARCHAR(255) NOT NULL,
+   new_email VARCHAR(255) NOT NULL,
+   old_is_verified BOOLEAN NOT NULL,
+   confirm_token_hash TEXT NOT NULL UNIQUE,
+   undo_token_hash TEXT NOT NULL UNIQUE,
+   confirm_expires_at DATETIME NOT NULL,
+   undo_expires_at DATETIME NOT NULL,
+   created_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')) NOT NULL,
+   confirmed_at DATETIME,
+   undone_at DATETIME,
+   cancelled_at DATETIME
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX email_changes_user_id_idx ON email_changes (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE email_changes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN pending_email;
+-- +goose StatementEnd
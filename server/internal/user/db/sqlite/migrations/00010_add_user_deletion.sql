@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deletion_scheduled_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deletion_token_hash TEXT;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX users_deletion_token_hash_key ON users (deletion_token_hash);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX users_deletion_scheduled_at_idx ON users (deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users_archive ADD COLUMN anonymized_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS disable_users_archive_update;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Anonymizing a row once is the only update allowed
+CREATE TRIGGER disable_users_archive_update
+BEFORE UPDATE ON users_archive
+FOR EACH ROW
+WHEN NOT (OLD.anonymized_at IS NULL AND NEW.anonymized_at IS NOT NULL)
+BEGIN
+    SELECT RAISE(ABORT, 'Updates to users_archive are not allowed');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS disable_users_archive_update;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER disable_users_archive_update
+BEFORE UPDATE ON users_archive
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'Updates to users_archive are not allowed');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users_archive DROP COLUMN anonymized_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_deletion_scheduled_at_idx;
+DROP INDEX IF EXISTS users_deletion_token_hash_key;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN deletion_token_hash;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
+-- +goose StatementEnd
@@ -0,0 +1,482 @@
+// Package sqlite adapts the sqlc-generated SQLite queries to the db.Querier interface used by the user service
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"user/db/generated"
+	"user/db/sqlite/generated"
+)
+
+// Querier db.Querier implementation backed by the SQLite queries
+type Querier struct {
+	Queries sqlitedb.Querier
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New Create a db.Querier using the specified SQLite connection
+func New(database sqlitedb.DBTX) *Querier {
+	return &Querier{
+		Queries: sqlitedb.New(database),
+	}
+}
+
+// CountUsers CountUsers() implementation from db.Querier interface
+func (q *Querier) CountUsers(ctx context.Context) (int64, error) {
+	return q.Queries.CountUsers(ctx)
+}
+
+// CreateUser CreateUser() implementation from db.Querier interface
+func (q *Querier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	user, err := q.Queries.CreateUser(
+		ctx, sqlitedb.CreateUserParams{
+			Username:     arg.Username,
+			Email:        arg.Email,
+			PasswordHash: arg.PasswordHash,
+		},
+	)
+	return toUser(user), err
+}
+
+// DeleteUser DeleteUser() implementation from db.Querier interface
+func (q *Querier) DeleteUser(ctx context.Context, id int32) error {
+	return q.Queries.DeleteUser(ctx, int64(id))
+}
+
+// GetUser GetUser() implementation from db.Querier interface
+func (q *Querier) GetUser(ctx context.Context, arg db.GetUserParams) (db.User, error) {
+	user, err := q.Queries.GetUser(
+		ctx, sqlitedb.GetUserParams{
+			ID:       sql.NullInt64{Int64: int64(arg.ID.Int32), Valid: arg.ID.Valid},
+			Username: arg.Username,
+			Email:    arg.Email,
+		},
+	)
+	return toUser(user), err
+}
+
+// GetUsers GetUsers() implementation from db.Querier interface
+func (q *Querier) GetUsers(ctx context.Context, arg db.GetUsersParams) ([]db.User, error) {
+	users, err := q.Queries.GetUsers(
+		ctx, sqlitedb.GetUsersParams{
+			Limit:  int64(arg.Limit),
+			Offset: int64(arg.Offset),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.User, len(users))
+	for i, user := range users {
+		result[i] = toUser(user)
+	}
+	return result, nil
+}
+
+// UpdateUser UpdateUser() implementation from db.Querier interface
+func (q *Querier) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	user, err := q.Queries.UpdateUser(
+		ctx, sqlitedb.UpdateUserParams{
+			ID:           int64(arg.ID),
+			Username:     arg.Username,
+			Email:        arg.Email,
+			PasswordHash: arg.PasswordHash,
+		},
+	)
+	return toUser(user), err
+}
+
+// SetUserVerified SetUserVerified() implementation from db.Querier interface
+func (q *Querier) SetUserVerified(ctx context.Context, arg db.SetUserVerifiedParams) (db.User, error) {
+	user, err := q.Queries.SetUserVerified(
+		ctx, sqlitedb.SetUserVerifiedParams{
+			ID:         int64(arg.ID),
+			IsVerified: arg.IsVerified,
+		},
+	)
+	return toUser(user), err
+}
+
+// RestoreUser RestoreUser() implementation from db.Querier interface
+func (q *Querier) RestoreUser(ctx context.Context, usersID int32) (db.User, error) {
+	user, err := q.Queries.RestoreUser(ctx, int64(usersID))
+	return toUser(user), err
+}
+
+// SetUserPendingEmail SetUserPendingEmail() implementation from db.Querier interface
+func (q *Querier) SetUserPendingEmail(ctx context.Context, arg db.SetUserPendingEmailParams) (db.User, error) {
+	user, err := q.Queries.SetUserPendingEmail(
+		ctx, sqlitedb.SetUserPendingEmailParams{
+			PendingEmail: arg.PendingEmail,
+			ID:           int64(arg.ID),
+		},
+	)
+	return toUser(user), err
+}
+
+// SetUserEmail SetUserEmail() implementation from db.Querier interface
+func (q *Querier) SetUserEmail(ctx context.Context, arg db.SetUserEmailParams) (db.User, error) {
+	user, err := q.Queries.SetUserEmail(
+		ctx, sqlitedb.SetUserEmailParams{
+			Email:      arg.Email,
+			IsVerified: arg.IsVerified,
+			ID:         int64(arg.ID),
+		},
+	)
+	return toUser(user), err
+}
+
+// CreateEmailChange CreateEmailChange() implementation from db.Querier interface
+func (q *Querier) CreateEmailChange(ctx context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error) {
+	change, err := q.Queries.CreateEmailChange(
+		ctx, sqlitedb.CreateEmailChangeParams{
+			UserID:           int64(arg.UserID),
+			OldEmail:         arg.OldEmail,
+			NewEmail:         arg.NewEmail,
+			OldIsVerified:    arg.OldIsVerified,
+			ConfirmTokenHash: arg.ConfirmTokenHash,
+			UndoTokenHash:    arg.UndoTokenHash,
+			ConfirmExpiresAt: arg.ConfirmExpiresAt,
+			UndoExpiresAt:    arg.UndoExpiresAt,
+		},
+	)
+	return toEmailChange(change), err
+}
+
+// GetEmailChangeByConfirmToken GetEmailChangeByConfirmToken() implementation from db.Querier interface
+func (q *Querier) GetEmailChangeByConfirmToken(ctx context.Context, confirmTokenHash string) (db.EmailChange, error) {
+	change, err := q.Queries.GetEmailChangeByConfirmToken(ctx, confirmTokenHash)
+	return toEmailChange(change), err
+}
+
+// GetEmailChangeByUndoToken GetEmailChangeByUndoToken() implementation from db.Querier interface
+func (q *Querier) GetEmailChangeByUndoToken(ctx context.Context, undoTokenHash string) (db.EmailChange, error) {
+	change, err := q.Queries.GetEmailChangeByUndoToken(ctx, undoTokenHash)
+	return toEmailChange(change), err
+}
+
+// CancelPendingEmailChanges CancelPendingEmailChanges() implementation from db.Querier interface
+func (q *Querier) CancelPendingEmailChanges(ctx context.Context, userID int32) error {
+	return q.Queries.CancelPendingEmailChanges(ctx, int64(userID))
+}
+
+// MarkEmailChangeConfirmed MarkEmailChangeConfirmed() implementation from db.Querier interface
+func (q *Querier) MarkEmailChangeConfirmed(ctx context.Context, id int64) error {
+	return q.Queries.MarkEmailChangeConfirmed(ctx, id)
+}
+
+// MarkEmailChangeUndone MarkEmailChangeUndone() implementation from db.Querier interface
+func (q *Querier) MarkEmailChangeUndone(ctx context.Context, id int64) error {
+	return q.Queries.MarkEmailChangeUndone(ctx, id)
+}
+
+// GetEmailChangesByUser GetEmailChangesByUser() implementation from db.Querier interface
+func (q *Querier) GetEmailChangesByUser(ctx context.Context, userID int32) ([]db.EmailChange, error) {
+	changes, err := q.Queries.GetEmailChangesByUser(ctx, int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.EmailChange, len(changes))
+	for i, change := range changes {
+		result[i] = toEmailChange(change)
+	}
+	return result, nil
+}
+
+// ScheduleUserDeletion ScheduleUserDeletion() implementation from db.Querier interface
+func (q *Querier) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (db.User, error) {
+	user, err := q.Queries.ScheduleUserDeletion(
+		ctx, sqlitedb.ScheduleUserDeletionParams{
+			GraceSeconds:      arg.GraceSeconds,
+			DeletionTokenHash: arg.DeletionTokenHash,
+			ID:                int64(arg.ID),
+		},
+	)
+	return toUser(user), err
+}
+
+// CancelUserDeletion CancelUserDeletion() implementation from db.Querier interface
+func (q *Querier) CancelUserDeletion(ctx context.Context, id int32) (db.User, error) {
+	user, err := q.Queries.CancelUserDeletion(ctx, int64(id))
+	return toUser(user), err
+}
+
+// GetUserByDeletionToken GetUserByDeletionToken() implementation from db.Querier interface
+func (q *Querier) GetUserByDeletionToken(ctx context.Context, deletionTokenHash sql.NullString) (db.User, error) {
+	user, err := q.Queries.GetUserByDeletionToken(ctx, deletionTokenHash)
+	return toUser(user), err
+}
+
+// GetUsersDueForDeletion GetUsersDueForDeletion() implementation from db.Querier interface
+func (q *Querier) GetUsersDueForDeletion(ctx context.Context, batchSize int32) ([]db.User, error) {
+	users, err := q.Queries.GetUsersDueForDeletion(ctx, int64(batchSize))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.User, len(users))
+	for i, user := range users {
+		result[i] = toUser(user)
+	}
+	return result, nil
+}
+
+// DeleteUserIfDue DeleteUserIfDue() implementation from db.Querier interface
+func (q *Querier) DeleteUserIfDue(ctx context.Context, id int32) (int64, error) {
+	return q.Queries.DeleteUserIfDue(ctx, int64(id))
+}
+
+// AnonymizeArchivedUsers AnonymizeArchivedUsers() implementation from db.Querier interface
+func (q *Querier) AnonymizeArchivedUsers(ctx context.Context, retentionSeconds float64) (int64, error) {
+	return q.Queries.AnonymizeArchivedUsers(ctx, retentionSeconds)
+}
+
+// GetUserArchive GetUserArchive() implementation from db.Querier interface
+func (q *Querier) GetUserArchive(ctx context.Context, usersID int32) ([]db.UsersArchive, error) {
+	rows, err := q.Queries.GetUserArchive(ctx, int64(usersID))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.UsersArchive, len(rows))
+	for i, row := range rows {
+		result[i] = toUsersArchive(row)
+	}
+	return result, nil
+}
+
+// InsertOutboxEvent InsertOutboxEvent() implementation from db.Querier interface
+func (q *Querier) InsertOutboxEvent(ctx context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error) {
+	event, err := q.Queries.InsertOutboxEvent(
+		ctx, sqlitedb.InsertOutboxEventParams{
+			EventType: arg.EventType,
+			UserID:    int64(arg.UserID),
+			Payload:   string(arg.Payload),
+		},
+	)
+	return toUserOutbox(event), err
+}
+
+// ClaimOutboxEvents ClaimOutboxEvents() implementation from db.Querier interface
+func (q *Querier) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.UserOutbox, error) {
+	events, err := q.Queries.ClaimOutboxEvents(
+		ctx, sqlitedb.ClaimOutboxEventsParams{
+			LeaseSeconds: arg.LeaseSeconds,
+			BatchSize:    int64(arg.BatchSize),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.UserOutbox, len(events))
+	for i, event := range events {
+		result[i] = toUserOutbox(event)
+	}
+	return result, nil
+}
+
+// MarkOutboxEventDelivered MarkOutboxEventDelivered() implementation from db.Querier interface
+func (q *Querier) MarkOutboxEventDelivered(ctx context.Context, id int64) error {
+	return q.Queries.MarkOutboxEventDelivered(ctx, id)
+}
+
+// MarkOutboxEventFailed MarkOutboxEventFailed() implementation from db.Querier interface
+func (q *Querier) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	return q.Queries.MarkOutboxEventFailed(
+		ctx, sqlitedb.MarkOutboxEventFailedParams{
+			LastError:    arg.LastError,
+			RetrySeconds: arg.RetrySeconds,
+			ID:           arg.ID,
+		},
+	)
+}
+
+// DeleteDeliveredOutboxEvents DeleteDeliveredOutboxEvents() implementation from db.Querier interface
+func (q *Querier) DeleteDeliveredOutboxEvents(ctx context.Context, retentionSeconds float64) (int64, error) {
+	return q.Queries.DeleteDeliveredOutboxEvents(ctx, retentionSeconds)
+}
+
+// GetOutboxEventsByUser GetOutboxEventsByUser() implementation from db.Querier interface
+func (q *Querier) GetOutboxEventsByUser(ctx context.Context, userID int32) ([]db.UserOutbox, error) {
+	events, err := q.Queries.GetOutboxEventsByUser(ctx, int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]db.UserOutbox, len(events))
+	for i, event := range events {
+		result[i] = toUserOutbox(event)
+	}
+	return result, nil
+}
+
+// CreateDataExport CreateDataExport() implementation from db.Querier interface
+func (q *Querier) CreateDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+	export, err := q.Queries.CreateDataExport(ctx, int64(userID))
+	return toDataExport(export), err
+}
+
+// GetDataExport GetDataExport() implementation from db.Querier interface
+func (q *Querier) GetDataExport(ctx context.Context, arg db.GetDataExportParams) (db.DataExport, error) {
+	export, err := q.Queries.GetDataExport(
+		ctx, sqlitedb.GetDataExportParams{
+			ID:     arg.ID,
+			UserID: int64(arg.UserID),
+		},
+	)
+	return toDataExport(export), err
+}
+
+// GetLatestDataExport GetLatestDataExport() implementation from db.Querier interface
+func (q *Querier) GetLatestDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+	export, err := q.Queries.GetLatestDataExport(ctx, int64(userID))
+	return toDataExport(export), err
+}
+
+// GetDataExportByDownloadToken GetDataExportByDownloadToken() implementation from db.Querier interface
+func (q *Querier) GetDataExportByDownloadToken(
+	ctx context.Context,
+	downloadTokenHash sql.NullString,
+) (db.DataExport, error) {
+	export, err := q.Queries.GetDataExportByDownloadToken(ctx, downloadTokenHash)
+	return toDataExport(export), err
+}
+
+// ClaimDataExport ClaimDataExport() implementation from db.Querier interface
+func (q *Querier) ClaimDataExport(ctx context.Context, leaseSeconds float64) (db.DataExport, error) {
+	export, err := q.Queries.ClaimDataExport(ctx, leaseSeconds)
+	return toDataExport(export), err
+}
+
+// InsertDataExportArchive InsertDataExportArchive() implementation from db.Querier interface
+func (q *Querier) InsertDataExportArchive(ctx context.Context, arg db.InsertDataExportArchiveParams) error {
+	return q.Queries.InsertDataExportArchive(
+		ctx, sqlitedb.InsertDataExportArchiveParams{
+			DataExportID: arg.DataExportID,
+			Archive:      arg.Archive,
+		},
+	)
+}
+
+// GetDataExportArchive GetDataExportArchive() implementation from db.Querier interface
+func (q *Querier) GetDataExportArchive(ctx context.Context, dataExportID int64) ([]byte, error) {
+	return q.Queries.GetDataExportArchive(ctx, dataExportID)
+}
+
+// MarkDataExportReady MarkDataExportReady() implementation from db.Querier interface
+func (q *Querier) MarkDataExportReady(ctx context.Context, arg db.MarkDataExportReadyParams) error {
+	return q.Queries.MarkDataExportReady(
+		ctx, sqlitedb.MarkDataExportReadyParams{
+			DownloadTokenHash: arg.DownloadTokenHash,
+			TtlSeconds:        arg.TtlSeconds,
+			ID:                arg.ID,
+		},
+	)
+}
+
+// MarkDataExportFailed MarkDataExportFailed() implementation from db.Querier interface
+func (q *Querier) MarkDataExportFailed(ctx context.Context, arg db.MarkDataExportFailedParams) error {
+	return q.Queries.MarkDataExportFailed(
+		ctx, sqlitedb.MarkDataExportFailedParams{
+			LastError: arg.LastError,
+			ID:        arg.ID,
+		},
+	)
+}
+
+// DeleteExpiredDataExportArchives DeleteExpiredDataExportArchives() implementation from db.Querier interface
+func (q *Querier) DeleteExpiredDataExportArchives(ctx context.Context) (int64, error) {
+	return q.Queries.DeleteExpiredDataExportArchives(ctx)
+}
+
+// DeleteUserDataExportArchives DeleteUserDataExportArchives() implementation from db.Querier interface
+func (q *Querier) DeleteUserDataExportArchives(ctx context.Context, userID int32) error {
+	return q.Queries.DeleteUserDataExportArchives(ctx, int64(userID))
+}
+
+// toUser Convert a SQLite user row to the shared db.User model
+func toUser(user sqlitedb.User) db.User {
+	return db.User{
+		ID:           int32(user.ID),
+		Username:     user.Username,
+		Email:        user.Email,
+		PasswordHash: user.PasswordHash,
+		IsVerified:   user.IsVerified,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		PendingEmail: user.PendingEmail,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		DeletionTokenHash:   user.DeletionTokenHash,
+	}
+}
+
+// toUsersArchive Convert a SQLite users_archive row to the shared db.UsersArchive model
+func toUsersArchive(row sqlitedb.UsersArchive) db.UsersArchive {
+	return db.UsersArchive{
+		ID:           int32(row.ID),
+		UsersID:      int32(row.UsersID),
+		Username:     row.Username,
+		Email:        row.Email,
+		PasswordHash: row.PasswordHash,
+		IsVerified:   row.IsVerified,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+		ArchivedAt:   row.ArchivedAt,
+		AnonymizedAt: row.AnonymizedAt,
+	}
+}
+
+// toEmailChange Convert a SQLite email_changes row to the shared db.EmailChange model
+func toEmailChange(change sqlitedb.EmailChange) db.EmailChange {
+	return db.EmailChange{
+		ID:               change.ID,
+		UserID:           int32(change.UserID),
+		OldEmail:         change.OldEmail,
+		NewEmail:         change.NewEmail,
+		OldIsVerified:    change.OldIsVerified,
+		ConfirmTokenHash: change.ConfirmTokenHash,
+		UndoTokenHash:    change.UndoTokenHash,
+		ConfirmExpiresAt: change.ConfirmExpiresAt,
+		UndoExpiresAt:    change.UndoExpiresAt,
+		CreatedAt:        change.CreatedAt,
+		ConfirmedAt:      change.ConfirmedAt,
+		UndoneAt:         change.UndoneAt,
+		CancelledAt:      change.CancelledAt,
+	}
+}
+
+// toUserOutbox Convert a SQLite user_outbox row to the shared db.UserOutbox model
+func toUserOutbox(event sqlitedb.UserOutbox) db.UserOutbox {
+	return db.UserOutbox{
+		ID:            event.ID,
+		EventType:     event.EventType,
+		UserID:        int32(event.UserID),
+		Payload:       json.RawMessage(event.Payload),
+		Attempts:      int32(event.Attempts),
+		LastError:     event.LastError,
+		CreatedAt:     event.CreatedAt,
+		NextAttemptAt: event.NextAttemptAt,
+		DeliveredAt:   event.DeliveredAt,
+	}
+}
+
+// toDataExport Convert a SQLite data_exports row to the shared db.DataExport model
+func toDataExport(export sqlitedb.DataExport) db.DataExport {
+	return db.DataExport{
+		ID:                export.ID,
+		UserID:            int32(export.UserID),
+		Status:            export.Status,
+		DownloadTokenHash: export.DownloadTokenHash,
+		LastError:         export.LastError,
+		CreatedAt:         export.CreatedAt,
+		StartedAt:         export.StartedAt,
+		CompletedAt:       export.CompletedAt,
+		ExpiresAt:         export.ExpiresAt,
+	}
+}
@@ -0,0 +1,82 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (user_id)
+VALUES (sqlc.arg(user_id))
+    RETURNING *;
+
+-- name: GetDataExport :one
+SELECT *
+FROM data_exports
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: GetLatestDataExport :one
+SELECT *
+FROM data_exports
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetDataExportByDownloadToken :one
+SELECT *
+FROM data_exports
+WHERE download_token_hash = sqlc.arg(download_token_hash);
+
+-- name: ClaimDataExport :one
+-- Claims the oldest pending export, or one whose worker let its lease run out; SQLite serializes writers, so two
+-- workers never claim the same export
+UPDATE data_exports
+SET status = 'running',
+    started_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = (
+    SELECT id
+    FROM data_exports
+    WHERE status = 'pending'
+        OR (
+            status = 'running'
+            AND started_at < strftime('%Y-%m-%d %H:%M:%f', 'now', (-CAST(sqlc.arg(lease_seconds) AS REAL)) || ' seconds')
+        )
+    ORDER BY id
+    LIMIT 1
+)
+    RETURNING *;
+
+-- name: InsertDataExportArchive :exec
+INSERT INTO data_export_archives (data_export_id, archive)
+VALUES (sqlc.arg(data_export_id), sqlc.arg(archive))
+ON CONFLICT (data_export_id) DO UPDATE SET archive = excluded.archive;
+
+-- name: GetDataExportArchive :one
+SELECT archive
+FROM data_export_archives
+WHERE data_export_id = sqlc.arg(data_export_id);
+
+-- name: MarkDataExportReady :exec
+UPDATE data_exports
+SET status = 'ready',
+    download_token_hash = CAST(sqlc.arg(download_token_hash) AS TEXT),
+    last_error = NULL,
+    completed_at = strftime('%Y-%m-%d %H:%M:%f', 'now'),
+    expires_at = strftime('%Y-%m-%d %H:%M:%f', 'now', CAST(sqlc.arg(ttl_seconds) AS REAL) || ' seconds')
+WHERE id = sqlc.arg(id);
+
+-- name: MarkDataExportFailed :exec
+UPDATE data_exports
+SET status = 'failed',
+    last_error = CAST(sqlc.arg(last_error) AS TEXT),
+    completed_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteExpiredDataExportArchives :execrows
+DELETE FROM data_export_archives
+WHERE data_export_id IN (
+    SELECT id
+    FROM data_exports
+    WHERE expires_at <= strftime('%Y-%m-%d %H:%M:%f', 'now')
+);
+
+-- name: DeleteUserDataExportArchives :exec
+DELETE FROM data_export_archives
+WHERE data_export_id IN (
+    SELECT id
+    FROM data_exports
+    WHERE user_id = sqlc.arg(user_id)
+);
@@ -0,0 +1,41 @@
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+    user_id, old_email, new_email, old_is_verified,
+    confirm_token_hash, undo_token_hash, confirm_expires_at, undo_expires_at
+)
+VALUES (
+    sqlc.arg(user_id), sqlc.arg(old_email), sqlc.arg(new_email), sqlc.arg(old_is_verified),
+    sqlc.arg(confirm_token_hash), sqlc.arg(undo_token_hash), sqlc.arg(confirm_expires_at), sqlc.arg(undo_expires_at)
+)
+    RETURNING *;
+
+-- name: GetEmailChangeByConfirmToken :one
+SELECT *
+FROM email_changes
+WHERE confirm_token_hash = sqlc.arg(confirm_token_hash);
+
+-- name: GetEmailChangeByUndoToken :one
+SELECT *
+FROM email_changes
+WHERE undo_token_hash = sqlc.arg(undo_token_hash);
+
+-- name: CancelPendingEmailChanges :exec
+UPDATE email_changes
+SET cancelled_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE user_id = sqlc.arg(user_id) AND confirmed_at IS NULL AND undone_at IS NULL AND cancelled_at IS NULL;
+
+-- name: MarkEmailChangeConfirmed :exec
+UPDATE email_changes
+SET confirmed_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id);
+
+-- name: MarkEmailChangeUndone :exec
+UPDATE email_changes
+SET undone_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id);
+
+-- name: GetEmailChangesByUser :many
+SELECT *
+FROM email_changes
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY id;
@@ -0,0 +1,41 @@
+-- name: InsertOutboxEvent :one
+INSERT INTO user_outbox (event_type, user_id, payload)
+VALUES (sqlc.arg(event_type), sqlc.arg(user_id), sqlc.arg(payload))
+    RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+-- Leases the oldest due events; SQLite serializes writers, so the lease alone keeps relays from overlapping
+UPDATE user_outbox
+SET next_attempt_at = strftime('%Y-%m-%d %H:%M:%f', 'now', CAST(sqlc.arg(lease_seconds) AS REAL) || ' seconds')
+WHERE id IN (
+    SELECT id
+    FROM user_outbox
+    WHERE delivered_at IS NULL AND next_attempt_at <= strftime('%Y-%m-%d %H:%M:%f', 'now')
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+)
+    RETURNING *;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE user_outbox
+SET delivered_at = strftime('%Y-%m-%d %H:%M:%f', 'now'),
+    attempts = attempts + 1,
+    last_error = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE user_outbox
+SET attempts = attempts + 1,
+    last_error = CAST(sqlc.arg(last_error) AS TEXT),
+    next_attempt_at = strftime('%Y-%m-%d %H:%M:%f', 'now', CAST(sqlc.arg(retry_seconds) AS REAL) || ' seconds')
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM user_outbox
+WHERE delivered_at < strftime('%Y-%m-%d %H:%M:%f', 'now', (-CAST(sqlc.arg(retention_seconds) AS REAL)) || ' seconds');
+
+-- name: GetOutboxEventsByUser :many
+SELECT *
+FROM user_outbox
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY id;
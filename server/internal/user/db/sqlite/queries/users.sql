@@ -0,0 +1,118 @@
+-- name: CreateUser :one
+INSERT INTO users (username, email, password_hash)
+VALUES (sqlc.arg(username), sqlc.arg(email), sqlc.arg(password_hash))
+    RETURNING *;
+
+-- name: GetUser :one
+SELECT *
+FROM users
+WHERE
+    (id = sqlc.narg(id) OR sqlc.narg(id) IS NULL) AND
+    (username = sqlc.narg(username) OR sqlc.narg(username) IS NULL) AND
+    (email = sqlc.narg(email) OR sqlc.narg(email) IS NULL)
+    LIMIT 1;
+
+-- name: GetUsers :many
+SELECT *
+FROM users
+LIMIT ?
+OFFSET ?;
+
+-- name: CountUsers :one
+SELECT
+    COUNT(*)
+FROM
+    users;
+
+-- name: UpdateUser :one
+-- updated_at is set explicitly because RETURNING does not reflect changes made by AFTER UPDATE triggers
+UPDATE users
+SET username = COALESCE(sqlc.narg(username), username),
+    email = COALESCE(sqlc.narg(email), email),
+    password_hash = COALESCE(sqlc.narg(password_hash), password_hash),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = sqlc.arg(id);
+
+-- name: SetUserVerified :one
+UPDATE users
+SET is_verified = sqlc.arg(is_verified),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: RestoreUser :one
+INSERT INTO users (id, username, email, password_hash, is_verified, created_at)
+SELECT users_id, username, email, password_hash, is_verified, created_at
+FROM users_archive
+WHERE users_id = sqlc.arg(users_id) AND anonymized_at IS NULL
+ORDER BY archived_at DESC
+LIMIT 1
+    RETURNING *;
+
+-- name: SetUserPendingEmail :one
+UPDATE users
+SET pending_email = sqlc.narg(pending_email),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: SetUserEmail :one
+UPDATE users
+SET email = sqlc.arg(email),
+    is_verified = sqlc.arg(is_verified),
+    pending_email = NULL,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = strftime('%Y-%m-%d %H:%M:%f', 'now', CAST(sqlc.arg(grace_seconds) AS REAL) || ' seconds'),
+    deletion_token_hash = CAST(sqlc.arg(deletion_token_hash) AS TEXT),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: CancelUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = NULL,
+    deletion_token_hash = NULL,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = sqlc.arg(id)
+    RETURNING *;
+
+-- name: GetUserByDeletionToken :one
+SELECT *
+FROM users
+WHERE deletion_token_hash = sqlc.arg(deletion_token_hash);
+
+-- name: GetUsersDueForDeletion :many
+SELECT *
+FROM users
+WHERE deletion_scheduled_at <= strftime('%Y-%m-%d %H:%M:%f', 'now')
+ORDER BY deletion_scheduled_at
+LIMIT sqlc.arg(batch_size);
+
+-- name: DeleteUserIfDue :execrows
+DELETE FROM users
+WHERE id = sqlc.arg(id) AND deletion_scheduled_at <= strftime('%Y-%m-%d %H:%M:%f', 'now');
+
+-- name: AnonymizeArchivedUsers :execrows
+UPDATE users_archive
+SET username = 'deleted',
+    email = 'deleted-' || id || '@anonymized.invalid',
+    password_hash = '',
+    anonymized_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE anonymized_at IS NULL
+    AND archived_at < strftime('%Y-%m-%d %H:%M:%f', 'now', (-CAST(sqlc.arg(retention_seconds) AS REAL)) || ' seconds');
+
+-- name: GetUserArchive :many
+SELECT *
+FROM users_archive
+WHERE users_id = sqlc.arg(users_id)
+ORDER BY archived_at;
@@ -0,0 +1,256 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"user/db/generated"
+	"user/dto"
+)
+
+const (
+	// EventUserDeletionScheduled Event written when a user asks for their account to be deleted
+	EventUserDeletionScheduled = "user.deletion_scheduled"
+	// EventUserDeletionCancelled Event written when a scheduled deletion is cancelled during the grace period
+	EventUserDeletionCancelled = "user.deletion_cancelled"
+)
+
+// ScheduleUserDeletion Schedule the user's account for deletion once the grace period ends and email them a link
+// cancelling it. Until then the account still exists but cannot be signed in to. Scheduling again restarts the grace
+// period and replaces the link
+func (service *ServiceImpl) ScheduleUserDeletion(
+	context context.Context,
+	request *dto.ScheduleUserDeletionRequest,
+) (*dto.ScheduleUserDeletionResponse, error) {
+	token, tokenHash, err := newLinkToken()
+	if err != nil {
+		return nil, err
+	}
+
+	gracePeriod := service.deletionGracePeriod()
+	var user db.User
+	err = service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			user, err = queries.ScheduleUserDeletion(
+				context, db.ScheduleUserDeletionParams{
+					GraceSeconds:      gracePeriod.Seconds(),
+					DeletionTokenHash: tokenHash,
+					ID:                int32(request.UserId),
+				},
+			)
+			if err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserDeletionScheduled, user)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule user deletion: %w", err)
+	}
+
+	service.sendMails(
+		context, []common.MailMessage{
+			{
+				To:      user.Email,
+				Subject: "Your account will be deleted",
+				Body: fmt.Sprintf(
+					"Hi %s,\n\nYour account and its data will be deleted in %s, and you cannot sign in until then. "+
+						"If you change your mind, open the link below before then to keep your account:\n\n%s\n",
+					user.Username,
+					formatLifetime(gracePeriod),
+					service.emailLink("/account/restore", token),
+				),
+			},
+		},
+	)
+
+	return &dto.ScheduleUserDeletionResponse{
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
+
+// CancelUserDeletion Keep an account scheduled for deletion, using the token emailed to the user. The token only
+// works during the grace period, after which the account may already have been purged
+func (service *ServiceImpl) CancelUserDeletion(
+	context context.Context,
+	request *dto.CancelUserDeletionRequest,
+) (*dto.CancelUserDeletionResponse, error) {
+	var user db.User
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			user, err = queries.GetUserByDeletionToken(
+				context,
+				sql.NullString{String: hashLinkToken(request.Token), Valid: true},
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidLinkTokenError()
+			} else if err != nil {
+				return err
+			}
+
+			if !user.DeletionScheduledAt.Valid || !time.Now().Before(user.DeletionScheduledAt.Time) {
+				return invalidLinkTokenError()
+			}
+
+			user, err = queries.CancelUserDeletion(context, user.ID)
+			if err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserDeletionCancelled, user)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel user deletion: %w", err)
+	}
+
+	return &dto.CancelUserDeletionResponse{
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
+
+// deletionGracePeriod Get the configured grace period, or the default if none is set
+func (service *ServiceImpl) deletionGracePeriod() time.Duration {
+	if service.DeletionGracePeriod <= 0 {
+		return common.DefaultDeletionGracePeriod
+	}
+	return service.DeletionGracePeriod
+}
+
+// deletionScheduledAt Get when the user will be deleted, or nil if no deletion is scheduled
+func deletionScheduledAt(user db.User) *time.Time {
+	if !user.DeletionScheduledAt.Valid {
+		return nil
+	}
+	return &user.DeletionScheduledAt.Time
+}
+
+// DeletionPurger Deletes accounts whose grace period has ended and anonymizes archived accounts once their retention
+// period has passed. Deleted rows go to users_archive through the archive_user trigger, as with DeleteUser
+type DeletionPurger struct {
+	Service *ServiceImpl
+	Logger  *slog.Logger
+
+	Interval  time.Duration
+	BatchSize int
+	// ArchiveRetention How long archived accounts keep their details. Zero keeps them forever
+	ArchiveRetention time.Duration
+	// OnPurged Called with the id of each purged account once its deletion is committed, so caches can drop it
+	OnPurged func(ctx context.Context, userId int)
+}
+
+// NewDeletionPurger Create a purger from the account deletion configuration
+func NewDeletionPurger(service *ServiceImpl, config *common.DeletionConfig, logger *slog.Logger) *DeletionPurger {
+	return &DeletionPurger{
+		Service:          service,
+		Logger:           logger,
+		Interval:         config.PurgeInterval,
+		BatchSize:        config.BatchSize,
+		ArchiveRetention: config.ArchiveRetention,
+	}
+}
+
+// Run Purge accounts every interval until the context is cancelled, starting immediately
+func (purger *DeletionPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(purger.Interval)
+	defer ticker.Stop()
+
+	for {
+		purger.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge Delete every account that is due in batches, then anonymize expired archive rows. Errors are logged so a
+// failure is retried on the next run
+func (purger *DeletionPurger) Purge(ctx context.Context) {
+	for {
+		deleted, err := purger.PurgeBatch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				purger.Logger.ErrorContext(ctx, "failed to purge deleted accounts", slog.Any("error", err))
+			}
+			break
+		}
+		if deleted > 0 {
+			purger.Logger.InfoContext(ctx, "purged deleted accounts", slog.Int("count", deleted))
+		}
+		if deleted < purger.BatchSize {
+			break
+		}
+	}
+
+	if purger.ArchiveRetention > 0 {
+		anonymized, err := purger.Service.Queries.AnonymizeArchivedUsers(ctx, purger.ArchiveRetention.Seconds())
+		if err != nil && ctx.Err() == nil {
+			purger.Logger.ErrorContext(ctx, "failed to anonymize archived accounts", slog.Any("error", err))
+		} else if anonymized > 0 {
+			purger.Logger.InfoContext(ctx, "anonymized archived accounts", slog.Int64("count", anonymized))
+		}
+	}
+}
+
+// PurgeBatch Delete up to BatchSize accounts whose grace period has ended, returning how many were deleted. Each
+// account is deleted in its own transaction that checks the deletion is still due, so a cancellation made after the
+// batch was read is never lost. Data export archives not yet downloaded are deleted along with the account
+func (purger *DeletionPurger) PurgeBatch(ctx context.Context) (int, error) {
+	users, err := purger.Service.Queries.GetUsersDueForDeletion(ctx, int32(purger.BatchSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get accounts due for deletion: %w", err)
+	}
+
+	deleted := 0
+	for _, user := range users {
+		var purged bool
+		err := purger.Service.runInTx(
+			ctx, nil, func(queries db.Querier) error {
+				rows, err := queries.DeleteUserIfDue(ctx, user.ID)
+				if err != nil || rows == 0 {
+					return err
+				}
+				if err := queries.DeleteUserDataExportArchives(ctx, user.ID); err != nil {
+					return err
+				}
+
+				purged = true
+				return writeUserEvent(ctx, queries, EventUserDeleted, user)
+			},
+		)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete user %d: %w", user.ID, err)
+		}
+		if purged {
+			deleted++
+			if purger.OnPurged != nil {
+				purger.OnPurged(ctx, int(user.ID))
+			}
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,189 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+	"user/db/memory"
+	"user/dto"
+)
+
+// newDeletionTestService Create a service backed by the memory querier with a user, returning the service, its
+// querier and mailer, and the user's id. The querier's clock is set to now and can be moved by the test
+func newDeletionTestService(t *testing.T) (*ServiceImpl, *memory.Querier, *common.MemoryMailer, int) {
+	queries := memory.New()
+	mailer := &common.MemoryMailer{}
+	service := &ServiceImpl{
+		Queries:             queries,
+		Transactor:          queries,
+		Mailer:              mailer,
+		AppUrl:              "https://quizchief.gg",
+		DeletionGracePeriod: 24 * time.Hour,
+	}
+
+	created, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	return service, queries, mailer, created.UserId
+}
+
+func newTestDeletionPurger(service *ServiceImpl) *DeletionPurger {
+	return &DeletionPurger{
+		Service:          service,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Interval:         time.Millisecond,
+		BatchSize:        10,
+		ArchiveRetention: 30 * 24 * time.Hour,
+	}
+}
+
+// scheduleTestDeletion Schedule the user's deletion, returning the cancellation token from the email
+func scheduleTestDeletion(t *testing.T, service *ServiceImpl, mailer *common.MemoryMailer, userId int) string {
+	response, err := service.ScheduleUserDeletion(context.Background(), &dto.ScheduleUserDeletionRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.ScheduleUserDeletion(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.DeletionScheduledAt == nil {
+		t.Fatal(`response.DeletionScheduledAt = "<nil>", expected non-nil`)
+	}
+
+	messages := mailer.Messages()
+	if len(messages) == 0 || messages[len(messages)-1].To != response.Email {
+		t.Fatalf(`messages = "%+v", expected a mail to "%s"`, messages, response.Email)
+	}
+	return linkToken(t, messages[len(messages)-1].Body, "/account/restore")
+}
+
+func TestService_ScheduleUserDeletion(t *testing.T) {
+	service, queries, mailer, userId := newDeletionTestService(t)
+	scheduleTestDeletion(t, service, mailer, userId)
+
+	user, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &userId})
+	if err != nil {
+		t.Fatalf(`service.GetUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	expected := time.Now().Add(service.DeletionGracePeriod)
+	if user.DeletionScheduledAt == nil || user.DeletionScheduledAt.Sub(expected).Abs() > time.Minute {
+		t.Errorf(`user.DeletionScheduledAt = "%v", expected about "%v"`, user.DeletionScheduledAt, expected)
+	}
+
+	outbox := queries.Outbox()
+	if event := outbox[len(outbox)-1]; event.EventType != EventUserDeletionScheduled {
+		t.Errorf(`event.EventType = "%s", expected "%s"`, event.EventType, EventUserDeletionScheduled)
+	}
+}
+
+func TestService_CancelUserDeletion(t *testing.T) {
+	service, _, mailer, userId := newDeletionTestService(t)
+	firstToken := scheduleTestDeletion(t, service, mailer, userId)
+	token := scheduleTestDeletion(t, service, mailer, userId)
+
+	_, err := service.CancelUserDeletion(context.Background(), &dto.CancelUserDeletionRequest{Token: firstToken})
+	assertHTTPError(t, err, http.StatusBadRequest)
+
+	response, err := service.CancelUserDeletion(context.Background(), &dto.CancelUserDeletionRequest{Token: token})
+	if err != nil {
+		t.Fatalf(`service.CancelUserDeletion(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.DeletionScheduledAt != nil {
+		t.Errorf(`response.DeletionScheduledAt = "%v", expected "<nil>"`, response.DeletionScheduledAt)
+	}
+
+	_, err = service.CancelUserDeletion(context.Background(), &dto.CancelUserDeletionRequest{Token: token})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestService_CancelUserDeletion_AfterGracePeriod(t *testing.T) {
+	service, queries, mailer, userId := newDeletionTestService(t)
+	queries.Now = func() time.Time {
+		return time.Now().Add(-2 * service.DeletionGracePeriod)
+	}
+	token := scheduleTestDeletion(t, service, mailer, userId)
+
+	_, err := service.CancelUserDeletion(context.Background(), &dto.CancelUserDeletionRequest{Token: token})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestDeletionPurger_Purge(t *testing.T) {
+	service, queries, mailer, userId := newDeletionTestService(t)
+	otherId := createGRPCTestUser(t, service, "other-user", "other@email.com")
+	scheduleTestDeletion(t, service, mailer, userId)
+	cancelled := scheduleTestDeletion(t, service, mailer, otherId)
+	if _, err := service.CancelUserDeletion(context.Background(), &dto.CancelUserDeletionRequest{Token: cancelled}); err != nil {
+		t.Fatalf(`service.CancelUserDeletion(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	purger := newTestDeletionPurger(service)
+	if deleted, err := purger.PurgeBatch(context.Background()); err != nil || deleted != 0 {
+		t.Fatalf(`purger.PurgeBatch(ctx) = "%d", "%v" during the grace period, expected "0", "<nil>"`, deleted, err)
+	}
+
+	now := time.Now().Add(service.DeletionGracePeriod + time.Minute)
+	queries.Now = func() time.Time {
+		return now
+	}
+	purger.Purge(context.Background())
+
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &userId}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`service.GetUser(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+	if _, err := service.GetUser(context.Background(), &dto.GetUserRequest{UserId: &otherId}); err != nil {
+		t.Errorf(`service.GetUser(...) error = "%v" for the cancelled user, expected "<nil>"`, err)
+	}
+
+	outbox := queries.Outbox()
+	if event := outbox[len(outbox)-1]; event.EventType != EventUserDeleted || event.UserID != int32(userId) {
+		t.Errorf(`event = "%s" for user "%d", expected "%s" for user "%d"`, event.EventType, event.UserID, EventUserDeleted, userId)
+	}
+
+	archive := queries.Archive()
+	if len(archive) != 1 || archive[0].Email != ValidEmail || archive[0].AnonymizedAt.Valid {
+		t.Fatalf(`archive = "%+v", expected the deleted user`, archive)
+	}
+
+	now = now.Add(purger.ArchiveRetention + time.Minute)
+	purger.Purge(context.Background())
+
+	archive = queries.Archive()
+	if archive[0].Email == ValidEmail || archive[0].PasswordHash != "" || !archive[0].AnonymizedAt.Valid {
+		t.Errorf(`archive[0] = "%+v", expected it to be anonymized`, archive[0])
+	}
+	if _, err := service.RestoreUser(context.Background(), &dto.RestoreUserRequest{UserId: userId}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`service.RestoreUser(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestDeletionPurger_InvalidatesCache(t *testing.T) {
+	service, queries, mailer, userId := newDeletionTestService(t)
+	cachingService := NewCachingService(service, common.NewLRUCache(100), time.Hour)
+	scheduleTestDeletion(t, service, mailer, userId)
+
+	request := &dto.GetUserRequest{UserId: &userId}
+	if _, err := cachingService.GetUser(context.Background(), request); err != nil {
+		t.Fatalf(`cachingService.GetUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	queries.Now = func() time.Time {
+		return time.Now().Add(service.DeletionGracePeriod + time.Minute)
+	}
+	purger := newTestDeletionPurger(service)
+	purger.OnPurged = cachingService.Invalidate
+	purger.Purge(context.Background())
+
+	if _, err := cachingService.GetUser(context.Background(), request); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`cachingService.GetUser(...) error = "%v" after the purge, expected "%v"`, err, sql.ErrNoRows)
+	}
+}
@@ -29,3 +29,50 @@ type UpdateUserRequest struct {
 type DeleteUserRequest struct {
 	UserId int `json:"userId"`
 }
+
+type VerifyUserRequest struct {
+	UserId     int  `json:"userId"`
+	IsVerified bool `json:"isVerified"`
+}
+
+type RestoreUserRequest struct {
+	UserId int `json:"userId"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+type UndoEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+type ScheduleUserDeletionRequest struct {
+	UserId int `json:"userId"`
+}
+
+type CancelUserDeletionRequest struct {
+	Token string `json:"token"`
+}
+
+type RequestDataExportRequest struct {
+	UserId int `json:"userId"`
+}
+
+type GetDataExportRequest struct {
+	UserId   int   `json:"userId"`
+	ExportId int64 `json:"exportId"`
+}
+
+type DownloadDataExportRequest struct {
+	Token string `json:"token"`
+}
+
+type ExportUsersRequest struct {
+	Format string `json:"format"`
+}
+
+type ImportUsersRequest struct {
+	Format string `json:"format"`
+	DryRun bool   `json:"dryRun"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -9,13 +10,15 @@ type CreateUserResponse struct {
 }
 
 type User struct {
-	UserId       int       `json:"userId"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"passwordHash"`
-	IsVerified   bool      `json:"isVerified"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	UserId              int        `json:"userId"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	PasswordHash        string     `json:"passwordHash"`
+	IsVerified          bool       `json:"isVerified"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+	PendingEmail        *string    `json:"pendingEmail,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
 }
 
 type GetUserResponse = User
@@ -30,3 +33,90 @@ type UpdateUserResponse = User
 
 type DeleteUserResponse struct {
 }
+
+type VerifyUserResponse = User
+
+type RestoreUserResponse = User
+
+type ConfirmEmailChangeResponse = User
+
+type UndoEmailChangeResponse = User
+
+type ScheduleUserDeletionResponse = User
+
+type CancelUserDeletionResponse = User
+
+type DataExport struct {
+	ExportId    int64      `json:"exportId"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+type RequestDataExportResponse = DataExport
+
+type GetDataExportResponse = DataExport
+
+type DownloadDataExportResponse struct {
+	FileName string
+	Archive  []byte
+}
+
+type ExportedUser struct {
+	UserId     int       `json:"userId"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	IsVerified bool      `json:"isVerified"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type ImportUserError struct {
+	Line     int    `json:"line"`
+	Username string `json:"username"`
+	Message  string `json:"message"`
+}
+
+type ImportUsersResponse struct {
+	DryRun   bool              `json:"dryRun"`
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Errors   []ImportUserError `json:"errors"`
+}
+
+type DataExportManifest struct {
+	ExportId    int64     `json:"exportId"`
+	UserId      int       `json:"userId"`
+	RequestedAt time.Time `json:"requestedAt"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Files       []string  `json:"files"`
+}
+
+type DataExportAccount struct {
+	ExportedUser
+	PendingEmail        *string    `json:"pendingEmail,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+}
+
+type DataExportArchivedAccount struct {
+	ExportedUser
+	ArchivedAt   time.Time  `json:"archivedAt"`
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
+}
+
+type DataExportEmailChange struct {
+	OldEmail    string     `json:"oldEmail"`
+	NewEmail    string     `json:"newEmail"`
+	RequestedAt time.Time  `json:"requestedAt"`
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty"`
+	UndoneAt    *time.Time `json:"undoneAt,omitempty"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+}
+
+type DataExportEvent struct {
+	EventType string          `json:"eventType"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Payload   json.RawMessage `json:"payload"`
+}
@@ -0,0 +1,357 @@
+package user
+
+import (
+	"common"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/jwtauth/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user/db/memory"
+	"user/dto"
+)
+
+func newEndToEndServer(t *testing.T) (*httptest.Server, *memory.Querier) {
+	t.Setenv(BASE_URL_KEY, MockUrl)
+	common.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	queries := memory.New()
+	service := &ServiceImpl{
+		Queries:    queries,
+		Transactor: queries,
+	}
+	server := httptest.NewServer(NewRouter(service, slog.Default()))
+	t.Cleanup(server.Close)
+
+	return server, queries
+}
+
+// doRequest Send a request to the server, signed in with the token unless it is empty
+func doRequest(t *testing.T, method string, url string, token string, body string) *http.Response {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf(`http.NewRequest(...) error = "%v", expected "<nil>"`, err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf(`http.DefaultClient.Do(request) error = "%v", expected "<nil>"`, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = response.Body.Close()
+		},
+	)
+
+	return response
+}
+
+func createUserPayload(username string, email string) string {
+	return fmt.Sprintf(`{"username": "%s", "email": "%s", "password": "%s"}`, username, email, ValidPassword)
+}
+
+// createEndToEndUser Create a user through the API and sign a token for them, as signing in would
+func createEndToEndUser(t *testing.T, server *httptest.Server, username string, email string) (int, string) {
+	response := doRequest(t, http.MethodPost, server.URL+"/user", "", createUserPayload(username, email))
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf(`POST /user status = "%d", expected "%d"`, response.StatusCode, http.StatusCreated)
+	}
+
+	var created dto.CreateUserResponse
+	if err := json.NewDecoder(response.Body).Decode(&created); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&created) = "%v", expected "<nil>"`, err)
+	}
+
+	claims := map[string]any{"user_id": created.UserId, "username": username, "email": email}
+	_, token, err := common.TokenAuth.Encode(claims)
+	if err != nil {
+		t.Fatalf(`common.TokenAuth.Encode(...) error = "%v", expected "<nil>"`, err)
+	}
+	return created.UserId, token
+}
+
+func TestEndToEnd_UserLifecycle(t *testing.T) {
+	server, queries := newEndToEndServer(t)
+	_, token := createEndToEndUser(t, server, "operator", "operator@email.com")
+
+	response := doRequest(t, http.MethodPost, server.URL+"/user", "", createUserPayload(ValidUsername, ValidEmail))
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf(`POST /user status = "%d", expected "%d"`, response.StatusCode, http.StatusCreated)
+	}
+
+	var created dto.CreateUserResponse
+	if err := json.NewDecoder(response.Body).Decode(&created); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&created) = "%v", expected "<nil>"`, err)
+	}
+
+	getUserUrl := fmt.Sprintf("%s/user?username=%s", server.URL, ValidUsername)
+	if response := doRequest(t, http.MethodGet, getUserUrl, "", ""); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf(`GET /user status = "%d" without a token, expected "%d"`, response.StatusCode, http.StatusUnauthorized)
+	}
+
+	response = doRequest(t, http.MethodGet, getUserUrl, token, "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`GET /user status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	var fetched dto.GetUserResponse
+	if err := json.NewDecoder(response.Body).Decode(&fetched); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&fetched) = "%v", expected "<nil>"`, err)
+	}
+	if fetched.UserId != created.UserId || fetched.Email != ValidEmail {
+		t.Errorf(`fetched = "%+v", expected user "%d" with email "%s"`, fetched, created.UserId, ValidEmail)
+	}
+	if fetched.PasswordHash == ValidPassword {
+		t.Error(`fetched.PasswordHash equals the plain text password, expected a hash`)
+	}
+
+	response = doRequest(
+		t,
+		http.MethodPatch,
+		fmt.Sprintf("%s/user/%d", server.URL, created.UserId),
+		token,
+		`{"username": "renamed"}`,
+	)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`PATCH /user/{id} status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	var updated dto.UpdateUserResponse
+	if err := json.NewDecoder(response.Body).Decode(&updated); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&updated) = "%v", expected "<nil>"`, err)
+	}
+	if updated.Username != "renamed" || updated.UpdatedAt.Before(fetched.UpdatedAt) {
+		t.Errorf(`updated = "%+v", expected username "renamed" and a newer updatedAt`, updated)
+	}
+
+	response = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/user/%d", server.URL, created.UserId), token, "")
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf(`DELETE /user/{id} status = "%d", expected "%d"`, response.StatusCode, http.StatusNoContent)
+	}
+
+	archive := queries.Archive()
+	if len(archive) != 1 || archive[0].Username != "renamed" {
+		t.Errorf(`archive = "%+v", expected one row for "renamed"`, archive)
+	}
+
+	response = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/user/%d", server.URL, created.UserId), token, "")
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf(`DELETE /user/{id} status = "%d", expected "%d"`, response.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestEndToEnd_CreateUser_Duplicate(t *testing.T) {
+	server, _ := newEndToEndServer(t)
+
+	response := doRequest(t, http.MethodPost, server.URL+"/user", "", createUserPayload(ValidUsername, ValidEmail))
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf(`POST /user status = "%d", expected "%d"`, response.StatusCode, http.StatusCreated)
+	}
+
+	payload := createUserPayload(ValidUsername, "other@email.com")
+	response = doRequest(t, http.MethodPost, server.URL+"/user", "", payload)
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf(`POST /user status = "%d", expected "%d"`, response.StatusCode, http.StatusBadRequest)
+	}
+
+	response = doRequest(t, http.MethodPost, server.URL+"/user", "", createUserPayload("other", ValidEmail))
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf(`POST /user status = "%d", expected "%d"`, response.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestEndToEnd_GetUsers_Pagination(t *testing.T) {
+	server, _ := newEndToEndServer(t)
+
+	var token string
+	for i := 0; i < 5; i++ {
+		_, userToken := createEndToEndUser(t, server, fmt.Sprintf("user-%d", i), fmt.Sprintf("user-%d@email.com", i))
+		if i == 0 {
+			token = userToken
+		}
+	}
+
+	response := doRequest(t, http.MethodGet, server.URL+"/user/all?limit=2&offset=2", token, "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`GET /user/all status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	var page dto.GetUsersResponse
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&page) = "%v", expected "<nil>"`, err)
+	}
+
+	if len(page.Users) != 2 || page.Users[0].Username != "user-2" {
+		t.Errorf(`page.Users = "%+v", expected users "user-2" and "user-3"`, page.Users)
+	}
+	if page.PrevLink == nil || *page.PrevLink != MockUrl+"/user/all?limit=2&offset=0" {
+		t.Errorf(`page.PrevLink = "%v", expected "%s/user/all?limit=2&offset=0"`, page.PrevLink, MockUrl)
+	}
+	if page.NextLink == nil || *page.NextLink != MockUrl+"/user/all?limit=2&offset=4" {
+		t.Errorf(`page.NextLink = "%v", expected "%s/user/all?limit=2&offset=4"`, page.NextLink, MockUrl)
+	}
+}
+
+func TestEndToEnd_ScheduleDeletion(t *testing.T) {
+	server, _ := newEndToEndServer(t)
+	_, token := createEndToEndUser(t, server, ValidUsername, ValidEmail)
+
+	response := doRequest(t, http.MethodPost, server.URL+"/user/me/deletion", "", "")
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf(`POST /user/me/deletion status = "%d" without a token, expected "%d"`, response.StatusCode,
+			http.StatusUnauthorized)
+	}
+
+	response = doRequest(t, http.MethodPost, server.URL+"/user/me/deletion", token, "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`POST /user/me/deletion status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	var scheduled dto.ScheduleUserDeletionResponse
+	if err := json.NewDecoder(response.Body).Decode(&scheduled); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&scheduled) = "%v", expected "<nil>"`, err)
+	}
+	if scheduled.Username != ValidUsername || scheduled.DeletionScheduledAt == nil {
+		t.Errorf(`scheduled = "%+v", expected "%s" scheduled for deletion`, scheduled, ValidUsername)
+	}
+
+	response = doRequest(t, http.MethodGet, server.URL+"/user/me", token, "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`GET /user/me status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	var current dto.GetUserResponse
+	if err := json.NewDecoder(response.Body).Decode(&current); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&current) = "%v", expected "<nil>"`, err)
+	}
+	if current.DeletionScheduledAt == nil {
+		t.Errorf(`current = "%+v", expected the deletion to be scheduled`, current)
+	}
+}
+
+func TestEndToEnd_DataExport(t *testing.T) {
+	server, queries := newEndToEndServer(t)
+	_, token := createEndToEndUser(t, server, ValidUsername, ValidEmail)
+	exporter := newTestDataExporter(&ServiceImpl{Queries: queries, Transactor: queries})
+
+	response := doRequest(t, http.MethodGet, server.URL+"/user/me/export", "", "")
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf(`GET /user/me/export status = "%d" without a token, expected "%d"`, response.StatusCode,
+			http.StatusUnauthorized)
+	}
+
+	response = doRequest(t, http.MethodGet, server.URL+"/user/me/export", token, "")
+	if response.StatusCode != http.StatusAccepted {
+		t.Fatalf(`GET /user/me/export status = "%d", expected "%d"`, response.StatusCode, http.StatusAccepted)
+	}
+
+	var requested dto.RequestDataExportResponse
+	if err := json.NewDecoder(response.Body).Decode(&requested); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&requested) = "%v", expected "<nil>"`, err)
+	}
+	location := fmt.Sprintf("/user/me/export/%d", requested.ExportId)
+	if response.Header.Get("Location") != location {
+		t.Errorf(`Location = "%s", expected "%s"`, response.Header.Get("Location"), location)
+	}
+
+	// pollExport Get the export's status from its Location
+	pollExport := func() dto.GetDataExportResponse {
+		response := doRequest(t, http.MethodGet, server.URL+location, token, "")
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf(`GET %s status = "%d", expected "%d"`, location, response.StatusCode, http.StatusOK)
+		}
+
+		var export dto.GetDataExportResponse
+		if err := json.NewDecoder(response.Body).Decode(&export); err != nil {
+			t.Fatalf(`json.NewDecoder(response.Body).Decode(&export) = "%v", expected "<nil>"`, err)
+		}
+		return export
+	}
+
+	if export := pollExport(); export.Status != DataExportPending {
+		t.Errorf(`export.Status = "%s" before the worker ran, expected "%s"`, export.Status, DataExportPending)
+	}
+
+	if processed, err := exporter.ProcessNext(context.Background()); !processed || err != nil {
+		t.Fatalf(`exporter.ProcessNext(...) = "%v", "%v", expected "true", "<nil>"`, processed, err)
+	}
+
+	export := pollExport()
+	if export.Status != DataExportReady || export.CompletedAt == nil || export.ExpiresAt == nil {
+		t.Errorf(`export = "%+v", expected "%s" with completion and expiry times`, export, DataExportReady)
+	}
+
+	response = doRequest(t, http.MethodGet, server.URL+"/user/me/export", token, "")
+	if response.StatusCode != http.StatusOK {
+		t.Errorf(`GET /user/me/export status = "%d" once ready, expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+}
+
+func TestEndToEnd_ImportAndExport(t *testing.T) {
+	server, _ := newEndToEndServer(t)
+	adminId, token := createEndToEndUser(t, server, "operator", "operator@email.com")
+	claims := map[string]any{"user_id": adminId, "username": "operator", "email": "operator@email.com", "admin": true}
+	_, adminToken, err := common.TokenAuth.Encode(claims)
+	if err != nil {
+		t.Fatalf(`common.TokenAuth.Encode(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	input := "username,email,password\n" +
+		"first,first@email.com," + ValidPassword + "\n" +
+		"second,not-an-email," + ValidPassword + "\n"
+
+	// Signed-in users without the admin role cannot import or export users
+	response := doRequest(t, http.MethodPost, server.URL+"/user/import?format=csv", token, input)
+	if response.StatusCode != http.StatusForbidden {
+		t.Errorf(`POST /user/import status = "%d", expected "%d"`, response.StatusCode, http.StatusForbidden)
+	}
+	response = doRequest(t, http.MethodGet, server.URL+"/user/export?format=csv", "", "")
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf(`GET /user/export status = "%d", expected "%d"`, response.StatusCode, http.StatusUnauthorized)
+	}
+
+	response = doRequest(t, http.MethodPost, server.URL+"/user/import?format=csv&dryRun=true", adminToken, input)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`POST /user/import status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	var report dto.ImportUsersResponse
+	if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+		t.Fatalf(`json.NewDecoder(response.Body).Decode(&report) = "%v", expected "<nil>"`, err)
+	}
+	if !report.DryRun || report.Imported != 1 || report.Failed != 1 || report.Errors[0].Message != "invalid email format" {
+		t.Errorf(`report = "%+v", expected 1 valid row and an invalid email error`, report)
+	}
+
+	response = doRequest(t, http.MethodPost, server.URL+"/user/import?format=csv", adminToken, input)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`POST /user/import status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+
+	response = doRequest(t, http.MethodGet, server.URL+"/user/export?format=csv", adminToken, "")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf(`GET /user/export status = "%d", expected "%d"`, response.StatusCode, http.StatusOK)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/csv" {
+		t.Errorf(`Content-Type = "%s", expected "text/csv"`, contentType)
+	}
+
+	body, _ := io.ReadAll(response.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], fmt.Sprintf("%d,first,first@email.com,false,", adminId+1)) {
+		t.Errorf(`body = "%s", expected a header, the admin and the imported user`, body)
+	}
+
+	response = doRequest(t, http.MethodGet, server.URL+"/user/export?format=xml", adminToken, "")
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf(`GET /user/export status = "%d", expected "%d"`, response.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,315 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"user/db/generated"
+	"user/dto"
+)
+
+// EventUserEmailChanged Event written when a user's email address changes through a confirmation or an undo
+const EventUserEmailChanged = "user.email_changed"
+
+const (
+	// EmailChangeConfirmLifetime How long the link sent to the new address can confirm the change
+	EmailChangeConfirmLifetime = 24 * time.Hour
+	// EmailChangeUndoLifetime How long the link sent to the old address can undo the change, confirmed or not
+	EmailChangeUndoLifetime = 7 * 24 * time.Hour
+)
+
+// requestEmailChange Put the new address into the user's pending email and create the tokens confirming and undoing
+// the change, replacing any change still pending. The user keeps their current address and verification status
+// until the change is confirmed. Returns the updated user and the emails to send once the transaction commits
+func (service *ServiceImpl) requestEmailChange(
+	context context.Context,
+	queries db.Querier,
+	user db.User,
+	newEmail string,
+) (db.User, []common.MailMessage, error) {
+	confirmToken, confirmTokenHash, err := newLinkToken()
+	if err != nil {
+		return db.User{}, nil, err
+	}
+	undoToken, undoTokenHash, err := newLinkToken()
+	if err != nil {
+		return db.User{}, nil, err
+	}
+
+	if err := queries.CancelPendingEmailChanges(context, user.ID); err != nil {
+		return db.User{}, nil, fmt.Errorf("failed to cancel pending email changes: %w", err)
+	}
+
+	now := time.Now()
+	_, err = queries.CreateEmailChange(
+		context, db.CreateEmailChangeParams{
+			UserID:           user.ID,
+			OldEmail:         user.Email,
+			NewEmail:         newEmail,
+			OldIsVerified:    user.IsVerified,
+			ConfirmTokenHash: confirmTokenHash,
+			UndoTokenHash:    undoTokenHash,
+			ConfirmExpiresAt: now.Add(EmailChangeConfirmLifetime),
+			UndoExpiresAt:    now.Add(EmailChangeUndoLifetime),
+		},
+	)
+	if err != nil {
+		return db.User{}, nil, fmt.Errorf("failed to create email change: %w", err)
+	}
+
+	user, err = queries.SetUserPendingEmail(
+		context, db.SetUserPendingEmailParams{
+			PendingEmail: sql.NullString{String: newEmail, Valid: true},
+			ID:           user.ID,
+		},
+	)
+	if err != nil {
+		return db.User{}, nil, fmt.Errorf("failed to set pending email: %w", err)
+	}
+
+	mails := []common.MailMessage{
+		{
+			To:      newEmail,
+			Subject: "Confirm your new email address",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nConfirm that you want to use this address for your account by opening the link below "+
+					"within %s:\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+				user.Username,
+				formatLifetime(EmailChangeConfirmLifetime),
+				service.emailLink("/email/confirm", confirmToken),
+			),
+		},
+		{
+			To:      user.Email,
+			Subject: "Your email address is being changed",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nSomeone asked to change the email address of your account to %s. If this was not you, "+
+					"open the link below within %s to keep this address, even if the change has already been "+
+					"confirmed:\n\n%s\n",
+				user.Username,
+				newEmail,
+				formatLifetime(EmailChangeUndoLifetime),
+				service.emailLink("/email/undo", undoToken),
+			),
+		},
+	}
+	return user, mails, nil
+}
+
+// ConfirmEmailChange Switch the user to the pending email address using the token sent to it. Confirming proves
+// ownership of the new address, so the user is verified afterwards
+func (service *ServiceImpl) ConfirmEmailChange(
+	context context.Context,
+	request *dto.ConfirmEmailChangeRequest,
+) (*dto.ConfirmEmailChangeResponse, error) {
+	var user db.User
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			change, err := queries.GetEmailChangeByConfirmToken(context, hashLinkToken(request.Token))
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidLinkTokenError()
+			} else if err != nil {
+				return err
+			}
+
+			if change.ConfirmedAt.Valid || change.UndoneAt.Valid || change.CancelledAt.Valid ||
+				time.Now().After(change.ConfirmExpiresAt) {
+				return invalidLinkTokenError()
+			}
+
+			user, err = getUserById(context, queries, change.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidLinkTokenError()
+			} else if err != nil {
+				return err
+			}
+			if user.PendingEmail.String != change.NewEmail || user.Email != change.OldEmail {
+				return invalidLinkTokenError()
+			}
+
+			if err := checkEmailAvailable(context, queries, change.NewEmail, user.ID); err != nil {
+				return err
+			}
+
+			user, err = queries.SetUserEmail(
+				context, db.SetUserEmailParams{
+					Email:      change.NewEmail,
+					IsVerified: true,
+					ID:         user.ID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := queries.MarkEmailChangeConfirmed(context, change.ID); err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserEmailChanged, user)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+
+	return &dto.ConfirmEmailChangeResponse{
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
+
+// UndoEmailChange Put the user back on the address the change was requested from, using the token sent to it.
+// A pending change is cancelled; a confirmed one is reverted along with the verification status it replaced
+func (service *ServiceImpl) UndoEmailChange(
+	context context.Context,
+	request *dto.UndoEmailChangeRequest,
+) (*dto.UndoEmailChangeResponse, error) {
+	var user db.User
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			change, err := queries.GetEmailChangeByUndoToken(context, hashLinkToken(request.Token))
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidLinkTokenError()
+			} else if err != nil {
+				return err
+			}
+
+			if change.UndoneAt.Valid || time.Now().After(change.UndoExpiresAt) {
+				return invalidLinkTokenError()
+			}
+
+			user, err = getUserById(context, queries, change.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidLinkTokenError()
+			} else if err != nil {
+				return err
+			}
+
+			if err := queries.CancelPendingEmailChanges(context, user.ID); err != nil {
+				return err
+			}
+
+			eventType := EventUserUpdated
+			if user.Email != change.OldEmail {
+				if err := checkEmailAvailable(context, queries, change.OldEmail, user.ID); err != nil {
+					return err
+				}
+
+				user, err = queries.SetUserEmail(
+					context, db.SetUserEmailParams{
+						Email:      change.OldEmail,
+						IsVerified: change.OldIsVerified,
+						ID:         user.ID,
+					},
+				)
+				eventType = EventUserEmailChanged
+			} else {
+				user, err = queries.SetUserPendingEmail(context, db.SetUserPendingEmailParams{ID: user.ID})
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := queries.MarkEmailChangeUndone(context, change.ID); err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, eventType, user)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to undo email change: %w", err)
+	}
+
+	return &dto.UndoEmailChangeResponse{
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
+
+// sendMails Send emails produced by a committed operation. Failures are logged rather than returned because the
+// change has already been made; the user can request it again to get new emails
+func (service *ServiceImpl) sendMails(context context.Context, mails []common.MailMessage) {
+	if service.Mailer == nil {
+		return
+	}
+
+	for _, mail := range mails {
+		if err := service.Mailer.Send(context, &mail); err != nil {
+			slog.ErrorContext(context, "failed to send mail", slog.String("subject", mail.Subject), slog.Any("error", err))
+		}
+	}
+}
+
+// emailLink Build a link to the web app carrying the token
+func (service *ServiceImpl) emailLink(path string, token string) string {
+	return strings.TrimSuffix(service.AppUrl, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// getUserById Get a user by id with the specified queries
+func getUserById(context context.Context, queries db.Querier, id int32) (db.User, error) {
+	return queries.GetUser(
+		context, db.GetUserParams{
+			ID: sql.NullInt32{Int32: id, Valid: true},
+		},
+	)
+}
+
+// checkEmailAvailable Return an HTTPError if a user other than the specified one has the email address
+func checkEmailAvailable(context context.Context, queries db.Querier, email string, userId int32) error {
+	owner, err := queries.GetUser(
+		context, db.GetUserParams{
+			Email: sql.NullString{String: email, Valid: true},
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if owner.ID != userId {
+		return &common.HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    "email already exists",
+		}
+	}
+	return nil
+}
+
+// pendingEmail Get the user's pending email address, or nil if no change is pending
+func pendingEmail(user db.User) *string {
+	if !user.PendingEmail.Valid {
+		return nil
+	}
+	return &user.PendingEmail.String
+}
+
+// formatLifetime Describe a token lifetime in hours or days for an email
+func formatLifetime(lifetime time.Duration) string {
+	if hours := int(lifetime.Hours()); hours%24 == 0 && hours > 24 {
+		return fmt.Sprintf("%d days", hours/24)
+	} else {
+		return fmt.Sprintf("%d hours", hours)
+	}
+}
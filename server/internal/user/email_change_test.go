@@ -0,0 +1,211 @@
+package user
+
+import (
+	"common"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"user/db/generated"
+	"user/db/memory"
+	"user/dto"
+)
+
+const newEmail = "new@email.com"
+
+// newEmailChangeTestService Create a service backed by the memory querier with a verified user, returning the
+// service, its mailer and the user's id
+func newEmailChangeTestService(t *testing.T) (*ServiceImpl, *memory.Querier, *common.MemoryMailer, int) {
+	queries := memory.New()
+	mailer := &common.MemoryMailer{}
+	service := &ServiceImpl{
+		Queries:    queries,
+		Transactor: queries,
+		Mailer:     mailer,
+		AppUrl:     "https://quizchief.gg/",
+	}
+
+	created, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	_, err = service.VerifyUser(context.Background(), &dto.VerifyUserRequest{UserId: created.UserId, IsVerified: true})
+	if err != nil {
+		t.Fatalf(`service.VerifyUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	return service, queries, mailer, created.UserId
+}
+
+// requestTestEmailChange Ask for the user's email to change, returning the confirm and undo tokens from the emails
+func requestTestEmailChange(
+	t *testing.T,
+	service *ServiceImpl,
+	mailer *common.MemoryMailer,
+	userId int,
+	email string,
+) (string, string) {
+	sent := len(mailer.Messages())
+	response, err := service.UpdateUser(context.Background(), &dto.UpdateUserRequest{UserId: userId, Email: &email})
+	if err != nil {
+		t.Fatalf(`service.UpdateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.Email != ValidEmail || response.PendingEmail == nil || *response.PendingEmail != email {
+		t.Errorf(`response = "%+v", expected email "%s" pending "%s"`, response, ValidEmail, email)
+	}
+
+	messages := mailer.Messages()[sent:]
+	if len(messages) != 2 {
+		t.Fatalf(`len(messages) = "%d", expected "2"`, len(messages))
+	}
+	if messages[0].To != email || messages[1].To != ValidEmail {
+		t.Errorf(`messages = "%+v", expected mails to "%s" and "%s"`, messages, email, ValidEmail)
+	}
+	return linkToken(t, messages[0].Body, "/email/confirm"), linkToken(t, messages[1].Body, "/email/undo")
+}
+
+// linkToken Extract the token from the link to the specified path in an email body
+func linkToken(t *testing.T, body string, path string) string {
+	prefix := "https://quizchief.gg" + path + "?token="
+	start := strings.Index(body, prefix)
+	if start < 0 {
+		t.Fatalf(`body = "%s", expected a link starting with "%s"`, body, prefix)
+	}
+	link := strings.Fields(body[start:])[0]
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf(`url.Parse("%s") error = "%v", expected "<nil>"`, link, err)
+	}
+	return parsed.Query().Get("token")
+}
+
+func TestService_ConfirmEmailChange(t *testing.T) {
+	service, queries, mailer, userId := newEmailChangeTestService(t)
+	confirmToken, _ := requestTestEmailChange(t, service, mailer, userId, newEmail)
+
+	response, err := service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: confirmToken})
+	if err != nil {
+		t.Fatalf(`service.ConfirmEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.Email != newEmail || !response.IsVerified || response.PendingEmail != nil {
+		t.Errorf(`response = "%+v", expected verified email "%s" with nothing pending`, response, newEmail)
+	}
+
+	outbox := queries.Outbox()
+	if event := outbox[len(outbox)-1]; event.EventType != EventUserEmailChanged {
+		t.Errorf(`event.EventType = "%s", expected "%s"`, event.EventType, EventUserEmailChanged)
+	}
+
+	_, err = service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: confirmToken})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestService_UndoEmailChange_AfterConfirm(t *testing.T) {
+	service, _, mailer, userId := newEmailChangeTestService(t)
+	confirmToken, undoToken := requestTestEmailChange(t, service, mailer, userId, newEmail)
+
+	_, err := service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: confirmToken})
+	if err != nil {
+		t.Fatalf(`service.ConfirmEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	_, err = service.VerifyUser(context.Background(), &dto.VerifyUserRequest{UserId: userId, IsVerified: false})
+	if err != nil {
+		t.Fatalf(`service.VerifyUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	response, err := service.UndoEmailChange(context.Background(), &dto.UndoEmailChangeRequest{Token: undoToken})
+	if err != nil {
+		t.Fatalf(`service.UndoEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.Email != ValidEmail || !response.IsVerified || response.PendingEmail != nil {
+		t.Errorf(`response = "%+v", expected verified email "%s" with nothing pending`, response, ValidEmail)
+	}
+
+	_, err = service.UndoEmailChange(context.Background(), &dto.UndoEmailChangeRequest{Token: undoToken})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestService_UndoEmailChange_Pending(t *testing.T) {
+	service, _, mailer, userId := newEmailChangeTestService(t)
+	confirmToken, undoToken := requestTestEmailChange(t, service, mailer, userId, newEmail)
+
+	response, err := service.UndoEmailChange(context.Background(), &dto.UndoEmailChangeRequest{Token: undoToken})
+	if err != nil {
+		t.Fatalf(`service.UndoEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.Email != ValidEmail || response.PendingEmail != nil {
+		t.Errorf(`response = "%+v", expected email "%s" with nothing pending`, response, ValidEmail)
+	}
+
+	_, err = service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: confirmToken})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestService_ConfirmEmailChange_Superseded(t *testing.T) {
+	service, _, mailer, userId := newEmailChangeTestService(t)
+	firstToken, _ := requestTestEmailChange(t, service, mailer, userId, newEmail)
+	secondToken, _ := requestTestEmailChange(t, service, mailer, userId, "newer@email.com")
+
+	_, err := service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: firstToken})
+	assertHTTPError(t, err, http.StatusBadRequest)
+
+	response, err := service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: secondToken})
+	if err != nil {
+		t.Fatalf(`service.ConfirmEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.Email != "newer@email.com" {
+		t.Errorf(`response.Email = "%s", expected "newer@email.com"`, response.Email)
+	}
+}
+
+func TestService_ConfirmEmailChange_Expired(t *testing.T) {
+	service, queries, _, userId := newEmailChangeTestService(t)
+
+	expired := time.Now().Add(-time.Minute)
+	_, err := queries.CreateEmailChange(
+		context.Background(), db.CreateEmailChangeParams{
+			UserID:           int32(userId),
+			OldEmail:         ValidEmail,
+			NewEmail:         newEmail,
+			ConfirmTokenHash: hashLinkToken("confirm"),
+			UndoTokenHash:    hashLinkToken("undo"),
+			ConfirmExpiresAt: expired,
+			UndoExpiresAt:    expired,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`queries.CreateEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err = service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: "confirm"})
+	assertHTTPError(t, err, http.StatusBadRequest)
+	_, err = service.UndoEmailChange(context.Background(), &dto.UndoEmailChangeRequest{Token: "undo"})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
+
+func TestService_ConfirmEmailChange_Conflict(t *testing.T) {
+	service, _, mailer, userId := newEmailChangeTestService(t)
+	confirmToken, _ := requestTestEmailChange(t, service, mailer, userId, newEmail)
+
+	_, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: "other-user",
+			Email:    newEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	_, err = service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: confirmToken})
+	assertHTTPError(t, err, http.StatusConflict)
+}
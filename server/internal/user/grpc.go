@@ -0,0 +1,166 @@
+package user
+
+import (
+	"common"
+	"common/userpb"
+	"context"
+	"database/sql"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
+	"user/dto"
+)
+
+// MaxBatchGetUsers Maximum number of ids accepted by a single BatchGetUsers call
+const MaxBatchGetUsers = 100
+
+// GRPCServer userpb.UserServiceServer implementation backed by the same Service as the REST API
+type GRPCServer struct {
+	userpb.UnimplementedUserServiceServer
+	Service Service
+}
+
+// NewGRPCServer Create a gRPC server with the user service registered
+func NewGRPCServer(service Service, logger *slog.Logger) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(common.UnaryServerLogger(logger)))
+	userpb.RegisterUserServiceServer(server, &GRPCServer{Service: service})
+	return server
+}
+
+// localUserGetter common.UserGetter implementation calling the gRPC server in process, so the REST API checks tokens
+// against users the same way the other services do over gRPC
+type localUserGetter struct {
+	server *GRPCServer
+}
+
+// GetUser GetUser() implementation from common.UserGetter interface
+func (getter localUserGetter) GetUser(
+	ctx context.Context,
+	in *userpb.GetUserRequest,
+	opts ...grpc.CallOption,
+) (*userpb.GetUserResponse, error) {
+	return getter.server.GetUser(ctx, in)
+}
+
+// GetUser GetUser() implementation from userpb.UserServiceServer interface
+func (server *GRPCServer) GetUser(ctx context.Context, request *userpb.GetUserRequest) (*userpb.GetUserResponse, error) {
+	var getUserRequest dto.GetUserRequest
+	switch lookup := request.Lookup.(type) {
+	case *userpb.GetUserRequest_Id:
+		userId := int(lookup.Id)
+		getUserRequest.UserId = &userId
+	case *userpb.GetUserRequest_Username:
+		getUserRequest.Username = &lookup.Username
+	case *userpb.GetUserRequest_Email:
+		getUserRequest.Email = &lookup.Email
+	default:
+		return nil, status.Error(codes.InvalidArgument, "one of id, username, or email is required")
+	}
+
+	user, err := server.Service.GetUser(ctx, &getUserRequest)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return &userpb.GetUserResponse{User: toProtoUser(user)}, nil
+}
+
+// BatchGetUsers BatchGetUsers() implementation from userpb.UserServiceServer interface
+func (server *GRPCServer) BatchGetUsers(
+	ctx context.Context,
+	request *userpb.BatchGetUsersRequest,
+) (*userpb.BatchGetUsersResponse, error) {
+	if len(request.Ids) > MaxBatchGetUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids may be requested", MaxBatchGetUsers)
+	}
+
+	response := &userpb.BatchGetUsersResponse{}
+	seen := make(map[int64]bool, len(request.Ids))
+	for _, id := range request.Ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		userId := int(id)
+		user, err := server.Service.GetUser(ctx, &dto.GetUserRequest{UserId: &userId})
+		if errors.Is(err, sql.ErrNoRows) {
+			response.MissingIds = append(response.MissingIds, id)
+			continue
+		} else if err != nil {
+			return nil, toStatusError(ctx, err)
+		}
+
+		response.Users = append(response.Users, toProtoUser(user))
+	}
+
+	return response, nil
+}
+
+// VerifyCredentials VerifyCredentials() implementation from userpb.UserServiceServer interface. Unknown users, wrong
+// passwords, and accounts scheduled for deletion all report valid = false so callers cannot tell them apart
+func (server *GRPCServer) VerifyCredentials(
+	ctx context.Context,
+	request *userpb.VerifyCredentialsRequest,
+) (*userpb.VerifyCredentialsResponse, error) {
+	var getUserRequest dto.GetUserRequest
+	switch login := request.Login.(type) {
+	case *userpb.VerifyCredentialsRequest_Username:
+		getUserRequest.Username = &login.Username
+	case *userpb.VerifyCredentialsRequest_Email:
+		getUserRequest.Email = &login.Email
+	default:
+		return nil, status.Error(codes.InvalidArgument, "one of username or email is required")
+	}
+
+	user, err := server.Service.GetUser(ctx, &getUserRequest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &userpb.VerifyCredentialsResponse{Valid: false}, nil
+	} else if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)); err != nil {
+		return &userpb.VerifyCredentialsResponse{Valid: false}, nil
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return &userpb.VerifyCredentialsResponse{Valid: false}, nil
+	}
+
+	return &userpb.VerifyCredentialsResponse{
+		Valid: true,
+		User:  toProtoUser(user),
+	}, nil
+}
+
+// toProtoUser Convert a user response to its protobuf representation, leaving out the password hash
+func toProtoUser(user *dto.GetUserResponse) *userpb.User {
+	return &userpb.User{
+		Id:         int64(user.UserId),
+		Username:   user.Username,
+		Email:      user.Email,
+		IsVerified: user.IsVerified,
+		CreatedAt:  timestamppb.New(user.CreatedAt),
+		UpdatedAt:  timestamppb.New(user.UpdatedAt),
+	}
+}
+
+// toStatusError Map a service error to a gRPC status, mirroring handleError for the REST API
+func toStatusError(ctx context.Context, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	var httpError *common.HTTPError
+	if errors.As(err, &httpError) {
+		return status.Error(codes.InvalidArgument, httpError.Message)
+	}
+
+	slog.ErrorContext(ctx, "unhandled service error", slog.Any("error", err))
+	return status.Error(codes.Internal, "internal server error")
+}
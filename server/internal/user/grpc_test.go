@@ -0,0 +1,207 @@
+package user
+
+import (
+	"common"
+	"common/userpb"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"log/slog"
+	"net"
+	"testing"
+	"user/db/memory"
+	"user/dto"
+)
+
+func newGRPCTestClient(t *testing.T) (userpb.UserServiceClient, *ServiceImpl) {
+	queries := memory.New()
+	service := &ServiceImpl{
+		Queries:    queries,
+		Transactor: queries,
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewGRPCServer(service, slog.Default())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	client, connection, err := common.DialUserService(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			},
+		),
+	)
+	if err != nil {
+		t.Fatalf(`common.DialUserService(...) error = "%v", expected "<nil>"`, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = connection.Close()
+		},
+	)
+
+	return client, service
+}
+
+func createGRPCTestUser(t *testing.T, service *ServiceImpl, username string, email string) int {
+	response, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: username,
+			Email:    email,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	return response.UserId
+}
+
+func TestGRPCServer_GetUser(t *testing.T) {
+	client, service := newGRPCTestClient(t)
+	userId := createGRPCTestUser(t, service, ValidUsername, ValidEmail)
+
+	response, err := client.GetUser(
+		context.Background(),
+		&userpb.GetUserRequest{Lookup: &userpb.GetUserRequest_Username{Username: ValidUsername}},
+	)
+	if err != nil {
+		t.Fatalf(`client.GetUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.User.Id != int64(userId) || response.User.Email != ValidEmail {
+		t.Errorf(`response.User = "%v", expected user "%d" with email "%s"`, response.User, userId, ValidEmail)
+	}
+	if response.User.CreatedAt.AsTime().IsZero() {
+		t.Error(`response.User.CreatedAt is zero, expected the creation time`)
+	}
+}
+
+func TestGRPCServer_GetUser_Errors(t *testing.T) {
+	client, _ := newGRPCTestClient(t)
+
+	tests := []struct {
+		request  *userpb.GetUserRequest
+		expected codes.Code
+	}{
+		{&userpb.GetUserRequest{Lookup: &userpb.GetUserRequest_Id{Id: 100}}, codes.NotFound},
+		{&userpb.GetUserRequest{}, codes.InvalidArgument},
+	}
+
+	for _, test := range tests {
+		_, err := client.GetUser(context.Background(), test.request)
+		if code := status.Code(err); code != test.expected {
+			t.Errorf(`client.GetUser(%v) code = "%v", expected "%v"`, test.request, code, test.expected)
+		}
+	}
+}
+
+func TestGRPCServer_BatchGetUsers(t *testing.T) {
+	client, service := newGRPCTestClient(t)
+	first := createGRPCTestUser(t, service, "first", "first@email.com")
+	second := createGRPCTestUser(t, service, "second", "second@email.com")
+
+	response, err := client.BatchGetUsers(
+		context.Background(),
+		&userpb.BatchGetUsersRequest{Ids: []int64{int64(first), 100, int64(second), int64(first)}},
+	)
+	if err != nil {
+		t.Fatalf(`client.BatchGetUsers(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if len(response.Users) != 2 || response.Users[0].Id != int64(first) || response.Users[1].Id != int64(second) {
+		t.Errorf(`response.Users = "%v", expected users "%d" and "%d"`, response.Users, first, second)
+	}
+	if len(response.MissingIds) != 1 || response.MissingIds[0] != 100 {
+		t.Errorf(`response.MissingIds = "%v", expected "[100]"`, response.MissingIds)
+	}
+
+	_, err = client.BatchGetUsers(
+		context.Background(),
+		&userpb.BatchGetUsersRequest{Ids: make([]int64, MaxBatchGetUsers+1)},
+	)
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf(`client.BatchGetUsers(too many ids) code = "%v", expected "%v"`, code, codes.InvalidArgument)
+	}
+}
+
+func TestGRPCServer_VerifyCredentials(t *testing.T) {
+	client, service := newGRPCTestClient(t)
+	userId := createGRPCTestUser(t, service, ValidUsername, ValidEmail)
+
+	tests := []struct {
+		request  *userpb.VerifyCredentialsRequest
+		expected bool
+	}{
+		{
+			&userpb.VerifyCredentialsRequest{
+				Login:    &userpb.VerifyCredentialsRequest_Email{Email: ValidEmail},
+				Password: ValidPassword,
+			},
+			true,
+		},
+		{
+			&userpb.VerifyCredentialsRequest{
+				Login:    &userpb.VerifyCredentialsRequest_Username{Username: ValidUsername},
+				Password: "wrong",
+			},
+			false,
+		},
+		{
+			&userpb.VerifyCredentialsRequest{
+				Login:    &userpb.VerifyCredentialsRequest_Username{Username: "unknown"},
+				Password: ValidPassword,
+			},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		response, err := client.VerifyCredentials(context.Background(), test.request)
+		if err != nil {
+			t.Fatalf(`client.VerifyCredentials(...) error = "%v", expected "<nil>"`, err)
+		}
+		if response.Valid != test.expected {
+			t.Errorf(`client.VerifyCredentials(%v) valid = "%v", expected "%v"`, test.request, response.Valid, test.expected)
+		}
+		if response.Valid && response.User.Id != int64(userId) {
+			t.Errorf(`response.User.Id = "%d", expected "%d"`, response.User.Id, userId)
+		}
+		if !response.Valid && response.User != nil {
+			t.Errorf(`response.User = "%v", expected "<nil>"`, response.User)
+		}
+	}
+
+	_, err := client.VerifyCredentials(context.Background(), &userpb.VerifyCredentialsRequest{Password: ValidPassword})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf(`client.VerifyCredentials(no login) code = "%v", expected "%v"`, code, codes.InvalidArgument)
+	}
+}
+
+func TestGRPCServer_VerifyCredentials_PendingDeletion(t *testing.T) {
+	client, service := newGRPCTestClient(t)
+	userId := createGRPCTestUser(t, service, ValidUsername, ValidEmail)
+
+	_, err := service.ScheduleUserDeletion(context.Background(), &dto.ScheduleUserDeletionRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf(`service.ScheduleUserDeletion(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	response, err := client.VerifyCredentials(
+		context.Background(), &userpb.VerifyCredentialsRequest{
+			Login:    &userpb.VerifyCredentialsRequest_Username{Username: ValidUsername},
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`client.VerifyCredentials(...) error = "%v", expected "<nil>"`, err)
+	}
+	if response.Valid {
+		t.Error(`response.Valid = "true", expected "false"`)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"common"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"user/dto"
@@ -15,18 +17,18 @@ func CreateUserHandler(service Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var request dto.CreateUserRequest
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		if err := ValidateCreateUserRequest(&request, service, r.Context()); err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		response, err := service.CreateUser(r.Context(), &request)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
@@ -41,7 +43,7 @@ func CreateUserHandler(service Service) http.HandlerFunc {
 // GetCurrentUserHandler Handler function for get current user endpoint
 func GetCurrentUserHandler(service Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		userClaims, ok := r.Context().Value(common.UsersClaimKey).(*common.UserClaims)
+		userClaims, ok := common.GetUserClaims(r.Context())
 		if !ok {
 			http.Error(w, "user claims not found", http.StatusUnauthorized)
 			return
@@ -53,6 +55,7 @@ func GetCurrentUserHandler(service Service) http.HandlerFunc {
 
 		response, err := service.GetUser(r.Context(), &request)
 		if err != nil {
+			slog.ErrorContext(r.Context(), "unable to retrieve user", slog.Any("error", err))
 			http.Error(w, "unable to retrieve user", http.StatusInternalServerError)
 			return
 		} else if response == nil {
@@ -73,18 +76,18 @@ func GetUserHandler(service Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		request, err := generateGetUserRequest(r)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		if err := ValidateGetUserRequest(request); err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		response, err := service.GetUser(r.Context(), request)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
@@ -101,18 +104,18 @@ func GetUsersHandler(service Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		request, err := generateGetUsersRequest(r)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		if err := ValidateGetUsersRequest(request); err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		response, err := service.GetUsers(r.Context(), request)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
@@ -129,18 +132,18 @@ func UpdateUserHandler(service Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		request, err := generateUpdateUserRequest(r)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		if err := ValidateUpdateUserRequest(request, service, r.Context()); err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		response, err := service.UpdateUser(r.Context(), request)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
@@ -157,18 +160,18 @@ func DeleteUserHandler(service Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		request, err := generateDeleteUserRequest(r)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		if err := ValidateDeleteUserRequest(request, service, r.Context()); err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
 		response, err := service.DeleteUser(r.Context(), request)
 		if err != nil {
-			handleError(err, w)
+			handleError(err, w, r)
 			return
 		}
 
@@ -180,6 +183,272 @@ func DeleteUserHandler(service Service) http.HandlerFunc {
 	}
 }
 
+// ConfirmEmailChangeHandler Handler function for confirm email change endpoint
+func ConfirmEmailChangeHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request dto.ConfirmEmailChangeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateConfirmEmailChangeRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.ConfirmEmailChange(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// UndoEmailChangeHandler Handler function for undo email change endpoint
+func UndoEmailChangeHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request dto.UndoEmailChangeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateUndoEmailChangeRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.UndoEmailChange(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// ScheduleCurrentUserDeletionHandler Handler function for schedule current user deletion endpoint
+func ScheduleCurrentUserDeletionHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.ScheduleUserDeletionRequest{
+			UserId: userClaims.ID,
+		}
+
+		if err := ValidateScheduleUserDeletionRequest(&request, service, r.Context()); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.ScheduleUserDeletion(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// CancelUserDeletionHandler Handler function for cancel user deletion endpoint
+func CancelUserDeletionHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request dto.CancelUserDeletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateCancelUserDeletionRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.CancelUserDeletion(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// RequestCurrentUserDataExportHandler Handler function for request current user data export endpoint. Responds with
+// 202 Accepted until the export is ready to download
+func RequestCurrentUserDataExportHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request := dto.RequestDataExportRequest{
+			UserId: userClaims.ID,
+		}
+
+		if err := ValidateRequestDataExportRequest(&request, service, r.Context()); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.RequestDataExport(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		statusCode := http.StatusAccepted
+		if response.Status == DataExportReady {
+			statusCode = http.StatusOK
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", fmt.Sprintf("/user/me/export/%d", response.ExportId))
+		w.WriteHeader(statusCode)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetCurrentUserDataExportHandler Handler function for get current user data export endpoint
+func GetCurrentUserDataExportHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := common.GetUserClaims(r.Context())
+		if !ok {
+			http.Error(w, "user claims not found", http.StatusUnauthorized)
+			return
+		}
+
+		request, err := generateGetDataExportRequest(r, userClaims.ID)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateGetDataExportRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.GetDataExport(r.Context(), request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// DownloadDataExportHandler Handler function for download data export endpoint
+func DownloadDataExportHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := dto.DownloadDataExportRequest{Token: r.URL.Query().Get("token")}
+
+		if err := ValidateDownloadDataExportRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := service.DownloadDataExport(r.Context(), &request)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, response.FileName))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(response.Archive); err != nil {
+			slog.ErrorContext(r.Context(), "failed to write data export", slog.Any("error", err))
+		}
+	}
+}
+
+// ExportUsersHandler Handler function for export users endpoint
+func ExportUsersHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := dto.ExportUsersRequest{Format: r.URL.Query().Get("format")}
+		if request.Format == "" {
+			request.Format = FormatJSONL
+		}
+
+		if err := ValidateExportUsersRequest(&request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", exportContentType(request.Format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, request.Format))
+		w.WriteHeader(http.StatusOK)
+		if err := ExportUsers(r.Context(), service, &request, w); err != nil {
+			// The status has already been sent, so the truncated body is the only signal left for the client
+			slog.ErrorContext(r.Context(), "failed to export users", slog.Any("error", err))
+		}
+	}
+}
+
+// ImportUsersHandler Handler function for import users endpoint
+func ImportUsersHandler(service Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request, err := generateImportUsersRequest(r)
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		if err := ValidateImportUsersRequest(request); err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		response, err := ImportUsers(r.Context(), service, request, http.MaxBytesReader(w, r.Body, MaxImportSize))
+		if err != nil {
+			handleError(err, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
 // generateGetUserRequest Populate and return GetUserRequest
 func generateGetUserRequest(r *http.Request) (*dto.GetUserRequest, error) {
 	query := r.URL.Query()
@@ -245,6 +514,25 @@ func generateGetUsersRequest(r *http.Request) (*dto.GetUsersRequest, error) {
 	return &request, nil
 }
 
+// generateImportUsersRequest Populate and return ImportUsersRequest from the query parameters
+func generateImportUsersRequest(r *http.Request) (*dto.ImportUsersRequest, error) {
+	query := r.URL.Query()
+	request := dto.ImportUsersRequest{Format: query.Get("format")}
+
+	if dryRunStr := query.Get("dryRun"); dryRunStr != "" {
+		dryRun, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return nil, &common.HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid dryRun",
+			}
+		}
+		request.DryRun = dryRun
+	}
+
+	return &request, nil
+}
+
 // generateUpdateUserRequest Populate and return UpdateUserRequest
 func generateUpdateUserRequest(r *http.Request) (*dto.UpdateUserRequest, error) {
 	var request dto.UpdateUserRequest
@@ -284,12 +572,29 @@ func generateDeleteUserRequest(r *http.Request) (*dto.DeleteUserRequest, error)
 	return &request, nil
 }
 
-// handleError Write the appropriate response given an error
-func handleError(err error, w http.ResponseWriter) {
+// generateGetDataExportRequest Populate and return GetDataExportRequest for the specified user
+func generateGetDataExportRequest(r *http.Request, userId int) (*dto.GetDataExportRequest, error) {
+	exportId, err := strconv.ParseInt(chi.URLParam(r, "exportId"), 10, 64)
+	if err != nil {
+		return nil, &common.HTTPError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "invalid export id",
+		}
+	}
+
+	return &dto.GetDataExportRequest{
+		UserId:   userId,
+		ExportId: exportId,
+	}, nil
+}
+
+// handleError Write the appropriate response given an error, logging errors that result in a 500
+func handleError(err error, w http.ResponseWriter, r *http.Request) {
 	var httpErr *common.HTTPError
 	if errors.As(err, &httpErr) {
 		http.Error(w, httpErr.Error(), httpErr.StatusCode)
 	} else {
+		slog.ErrorContext(r.Context(), "unhandled service error", slog.Any("error", err))
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
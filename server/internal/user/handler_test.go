@@ -1,12 +1,14 @@
 package user
 
 import (
+	"bytes"
 	"common"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -875,6 +877,259 @@ func TestDeleteUserHandler_ServiceFailure(t *testing.T) {
 	}
 }
 
+func TestConfirmEmailChangeHandler_Success(t *testing.T) {
+	service := &mockService{
+		confirmEmailChangeFunc: func(
+			context context.Context,
+			request *dto.ConfirmEmailChangeRequest,
+		) (*dto.ConfirmEmailChangeResponse, error) {
+			if request.Token != "token" {
+				t.Errorf(`request.Token = "%s", expected "token"`, request.Token)
+			}
+			return &dto.ConfirmEmailChangeResponse{UserId: 1, Email: ValidEmail, IsVerified: true}, nil
+		},
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/user/email/confirm", strings.NewReader(`{"token": "token"}`))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/user/email/confirm", ConfirmEmailChangeHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	var response dto.ConfirmEmailChangeResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if response.Email != ValidEmail {
+		t.Errorf(`response.Email = "%s", expected "%s"`, response.Email, ValidEmail)
+	}
+}
+
+func TestConfirmEmailChangeHandler_MissingToken(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodPost, "/user/email/confirm", strings.NewReader(`{}`))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/user/email/confirm", ConfirmEmailChangeHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUndoEmailChangeHandler_InvalidToken(t *testing.T) {
+	service := &mockService{
+		undoEmailChangeFunc: func(
+			context context.Context,
+			request *dto.UndoEmailChangeRequest,
+		) (*dto.UndoEmailChangeResponse, error) {
+			return nil, fmt.Errorf("failed to undo email change: %w", invalidLinkTokenError())
+		},
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/user/email/undo", strings.NewReader(`{"token": "token"}`))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/user/email/undo", UndoEmailChangeHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestScheduleCurrentUserDeletionHandler_Success(t *testing.T) {
+	scheduledAt := time.Now().Add(time.Hour)
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			return &dto.GetUserResponse{UserId: *request.UserId}, nil
+		},
+		scheduleUserDeletionFunc: func(
+			context context.Context,
+			request *dto.ScheduleUserDeletionRequest,
+		) (*dto.ScheduleUserDeletionResponse, error) {
+			return &dto.ScheduleUserDeletionResponse{UserId: request.UserId, DeletionScheduledAt: &scheduledAt}, nil
+		},
+	}
+
+	userClaims := &common.UserClaims{
+		ID:       1,
+		Username: ValidUsername,
+		Email:    ValidEmail,
+	}
+	ctx := context.WithValue(context.Background(), common.UsersClaimKey, userClaims)
+	request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/user/me/deletion", strings.NewReader(""))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/user/me/deletion", ScheduleCurrentUserDeletionHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+
+	var response dto.ScheduleUserDeletionResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Errorf(`json.NewDecoder(recorder.Body).Decode(&response) = "%v", expected "<nil>"`, err)
+	}
+	if response.UserId != 1 || response.DeletionScheduledAt == nil {
+		t.Errorf(`response = "%+v", expected user 1 scheduled for deletion`, response)
+	}
+}
+
+func TestScheduleCurrentUserDeletionHandler_MissingUserClaims(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodPost, "/user/me/deletion", strings.NewReader(""))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/user/me/deletion", ScheduleCurrentUserDeletionHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCancelUserDeletionHandler_MissingToken(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodPost, "/user/deletion/cancel", strings.NewReader(`{}`))
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/user/deletion/cancel", CancelUserDeletionHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRequestCurrentUserDataExportHandler_Accepted(t *testing.T) {
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			return &dto.GetUserResponse{UserId: *request.UserId}, nil
+		},
+		requestDataExportFunc: func(
+			context context.Context,
+			request *dto.RequestDataExportRequest,
+		) (*dto.RequestDataExportResponse, error) {
+			return &dto.RequestDataExportResponse{ExportId: 7, Status: DataExportPending}, nil
+		},
+	}
+
+	userClaims := &common.UserClaims{
+		ID:       1,
+		Username: ValidUsername,
+		Email:    ValidEmail,
+	}
+	ctx := context.WithValue(context.Background(), common.UsersClaimKey, userClaims)
+	request := httptest.NewRequestWithContext(ctx, http.MethodGet, "/user/me/export", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/user/me/export", RequestCurrentUserDataExportHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusAccepted {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusAccepted)
+	}
+	if location := recorder.Header().Get("Location"); location != "/user/me/export/7" {
+		t.Errorf(`recorder.Header().Get("Location") = "%s", expected "/user/me/export/7"`, location)
+	}
+}
+
+func TestRequestCurrentUserDataExportHandler_MissingUserClaims(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodGet, "/user/me/export", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/user/me/export", RequestCurrentUserDataExportHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestGetCurrentUserDataExportHandler_InvalidExportId(t *testing.T) {
+	service := &mockService{}
+
+	userClaims := &common.UserClaims{
+		ID:       1,
+		Username: ValidUsername,
+		Email:    ValidEmail,
+	}
+	ctx := context.WithValue(context.Background(), common.UsersClaimKey, userClaims)
+	request := httptest.NewRequestWithContext(ctx, http.MethodGet, "/user/me/export/invalidId", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/user/me/export/{exportId}", GetCurrentUserDataExportHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDownloadDataExportHandler_Success(t *testing.T) {
+	service := &mockService{
+		downloadDataExportFunc: func(
+			context context.Context,
+			request *dto.DownloadDataExportRequest,
+		) (*dto.DownloadDataExportResponse, error) {
+			return &dto.DownloadDataExportResponse{FileName: "data-export-7.zip", Archive: []byte("archive")}, nil
+		},
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/user/export/download?token=token", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/user/export/download", DownloadDataExportHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusOK)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/zip" {
+		t.Errorf(`recorder.Header().Get("Content-Type") = "%s", expected "application/zip"`, contentType)
+	}
+	if body := recorder.Body.String(); body != "archive" {
+		t.Errorf(`recorder.Body = "%s", expected "archive"`, body)
+	}
+}
+
+func TestDownloadDataExportHandler_MissingToken(t *testing.T) {
+	service := &mockService{}
+
+	request := httptest.NewRequest(http.MethodGet, "/user/export/download", nil)
+	recorder := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/user/export/download", DownloadDataExportHandler(service))
+	r.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+}
+
 func TestGenerateGetUsersRequest_Success(t *testing.T) {
 	userId := 1
 	username := ValidUsername
@@ -906,6 +1161,44 @@ func TestGenerateGetUsersRequest_Success(t *testing.T) {
 	}
 }
 
+func TestHandleError_LogsInternalServerError(t *testing.T) {
+	var buffer bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buffer, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	request := httptest.NewRequest(http.MethodGet, "/user", nil)
+	recorder := httptest.NewRecorder()
+	handleError(errors.New("database unavailable"), recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusInternalServerError)
+	}
+
+	if !strings.Contains(buffer.String(), "database unavailable") {
+		t.Errorf(`buffer.String() = "%s", expected to contain "database unavailable"`, buffer.String())
+	}
+}
+
+func TestHandleError_DoesNotLogHTTPError(t *testing.T) {
+	var buffer bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buffer, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	request := httptest.NewRequest(http.MethodGet, "/user", nil)
+	recorder := httptest.NewRecorder()
+	handleError(&common.HTTPError{StatusCode: http.StatusBadRequest, Message: "bad"}, recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`recorder.Code = "%v", expected "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+
+	if buffer.Len() != 0 {
+		t.Errorf(`buffer.String() = "%s", expected ""`, buffer.String())
+	}
+}
+
 func assertUserEqual(t *testing.T, actual *dto.User, expected *dto.User) {
 	if actual.UserId != expected.UserId {
 		t.Errorf(`actual.UserId = "%d", expected "%d"`, actual.UserId, expected.UserId)
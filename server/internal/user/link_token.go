@@ -0,0 +1,39 @@
+package user
+
+import (
+	"common"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+// linkTokenBytes Number of random bytes in a token sent in an email link
+const linkTokenBytes = 32
+
+// newLinkToken Generate a random token for an email link, along with the hash that is stored in its place
+func newLinkToken() (string, string, error) {
+	buffer := make([]byte, linkTokenBytes)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buffer)
+	return token, hashLinkToken(token), nil
+}
+
+// hashLinkToken Hash a token for storage, so a leaked database does not expose working links
+func hashLinkToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// invalidLinkTokenError Create the error returned for unknown, used, superseded, or expired tokens
+func invalidLinkTokenError() error {
+	return &common.HTTPError{
+		StatusCode: http.StatusBadRequest,
+		Message:    "invalid or expired token",
+	}
+}
@@ -0,0 +1,52 @@
+package user
+
+import (
+	"common"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+//go:embed db/migrations/*.sql db/sqlite/migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migrations Get the user service SQL migrations embedded in the binary for the specified database driver
+func Migrations(driver string) fs.FS {
+	directory := "db/migrations"
+	if driver == common.DriverSQLite {
+		directory = "db/sqlite/migrations"
+	}
+
+	migrations, err := fs.Sub(embeddedMigrations, directory)
+	if err != nil {
+		panic(err)
+	}
+	return migrations
+}
+
+// Migrate Run a migrate command (up, down, status, version) against the user database
+func Migrate(ctx context.Context, command string, output io.Writer) error {
+	if err := common.ValidateMigrateCommand(command); err != nil {
+		return err
+	}
+
+	databaseConfig, err := common.LoadDatabaseConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load database configuration: %w", err)
+	}
+
+	if databaseConfig.Driver == common.DriverMemory {
+		_, _ = fmt.Fprintln(output, "in-memory database does not use migrations")
+		return nil
+	}
+
+	database, err := common.OpenDatabase(ctx, databaseConfig)
+	if err != nil {
+		return fmt.Errorf("failed to establish database connection: %w", err)
+	}
+	defer database.Close()
+
+	return common.RunMigrations(ctx, database, databaseConfig.Driver, Migrations(databaseConfig.Driver), command, output)
+}
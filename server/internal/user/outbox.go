@@ -0,0 +1,211 @@
+package user
+
+import (
+	"common"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+	"user/db/generated"
+	"user/dto"
+)
+
+const (
+	EventUserCreated  = "user.created"
+	EventUserUpdated  = "user.updated"
+	EventUserVerified = "user.verified"
+	EventUserDeleted  = "user.deleted"
+	EventUserRestored = "user.restored"
+)
+
+// EventSource Source reported on every event relayed from the user outbox
+const EventSource = "user"
+
+const (
+	DefaultOutboxLease          = 30 * time.Second
+	DefaultOutboxInitialBackoff = time.Second
+	DefaultOutboxMaxBackoff     = 10 * time.Minute
+)
+
+// writeUserEvent Record a lifecycle event for the user in the outbox. It must be called with the queries of the
+// transaction that made the change, so the event is committed if and only if the change is
+func writeUserEvent(context context.Context, queries db.Querier, eventType string, user db.User) error {
+	payload, err := json.Marshal(
+		dto.ExportedUser{
+			UserId:     int(user.ID),
+			Username:   user.Username,
+			Email:      user.Email,
+			IsVerified: user.IsVerified,
+			CreatedAt:  user.CreatedAt,
+			UpdatedAt:  user.UpdatedAt,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	_, err = queries.InsertOutboxEvent(
+		context, db.InsertOutboxEventParams{
+			EventType: eventType,
+			UserID:    user.ID,
+			Payload:   payload,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// OutboxRelay Delivers events from the user_outbox table to a sink. Events are leased before delivery and only
+// marked delivered once the sink accepts them, so each event is delivered at least once: a crash or failed
+// delivery leads to a retry with exponential backoff, for as long as it takes
+type OutboxRelay struct {
+	Queries db.Querier
+	Sink    common.EventSink
+	Logger  *slog.Logger
+
+	BatchSize    int
+	PollInterval time.Duration
+	// Lease How long a claimed event is hidden from other relays. It must exceed the time taken to deliver a batch
+	Lease          time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Retention How long delivered events are kept before being purged. Zero keeps them forever
+	Retention time.Duration
+}
+
+// NewOutboxRelay Create a relay from the outbox configuration
+func NewOutboxRelay(
+	queries db.Querier,
+	sink common.EventSink,
+	config *common.OutboxConfig,
+	logger *slog.Logger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		Queries:        queries,
+		Sink:           sink,
+		Logger:         logger,
+		BatchSize:      config.BatchSize,
+		PollInterval:   config.PollInterval,
+		Lease:          DefaultOutboxLease,
+		InitialBackoff: DefaultOutboxInitialBackoff,
+		MaxBackoff:     DefaultOutboxMaxBackoff,
+		Retention:      config.Retention,
+	}
+}
+
+// Run Relay events until the context is cancelled. A full batch is followed immediately by the next one, otherwise
+// the relay waits for the poll interval
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relay.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		relayed, err := relay.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			relay.Logger.ErrorContext(ctx, "failed to relay outbox events", slog.Any("error", err))
+		}
+
+		if relay.Retention > 0 {
+			if _, err := relay.Queries.DeleteDeliveredOutboxEvents(ctx, relay.Retention.Seconds()); err != nil && ctx.Err() == nil {
+				relay.Logger.ErrorContext(ctx, "failed to purge delivered outbox events", slog.Any("error", err))
+			}
+		}
+
+		if err == nil && relayed == relay.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch Claim up to BatchSize due events and publish them to the sink in order, returning how many were
+// claimed. Failed deliveries are rescheduled rather than returned as errors
+func (relay *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	events, err := relay.Queries.ClaimOutboxEvents(
+		ctx, db.ClaimOutboxEventsParams{
+			LeaseSeconds: relay.Lease.Seconds(),
+			BatchSize:    int32(relay.BatchSize),
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	// UPDATE ... RETURNING does not guarantee an order
+	sort.Slice(
+		events, func(i, j int) bool {
+			return events[i].ID < events[j].ID
+		},
+	)
+
+	for _, event := range events {
+		if err := relay.publish(ctx, &event); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// publish Deliver one event and record the outcome, returning an error only if the outcome could not be recorded
+func (relay *OutboxRelay) publish(ctx context.Context, event *db.UserOutbox) error {
+	publishErr := relay.Sink.Publish(
+		ctx, &common.Event{
+			Id:         event.ID,
+			Type:       event.EventType,
+			Source:     EventSource,
+			OccurredAt: event.CreatedAt,
+			Data:       event.Payload,
+		},
+	)
+
+	if publishErr == nil {
+		if err := relay.Queries.MarkOutboxEventDelivered(ctx, event.ID); err != nil {
+			return fmt.Errorf("failed to mark outbox event %d delivered: %w", event.ID, err)
+		}
+		return nil
+	}
+
+	backoff := relay.backoff(int(event.Attempts))
+	relay.Logger.WarnContext(
+		ctx,
+		"failed to deliver outbox event",
+		slog.Int64("event_id", event.ID),
+		slog.String("event_type", event.EventType),
+		slog.Int("attempts", int(event.Attempts)+1),
+		slog.Duration("retry_in", backoff),
+		slog.Any("error", publishErr),
+	)
+
+	err := relay.Queries.MarkOutboxEventFailed(
+		ctx, db.MarkOutboxEventFailedParams{
+			LastError:    publishErr.Error(),
+			RetrySeconds: backoff.Seconds(),
+			ID:           event.ID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox event %d: %w", event.ID, err)
+	}
+	return nil
+}
+
+// backoff Get the delay before retrying an event that has already failed the specified number of times
+func (relay *OutboxRelay) backoff(attempts int) time.Duration {
+	backoff := relay.InitialBackoff
+	for i := 0; i < attempts && backoff < relay.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, relay.MaxBackoff)
+}
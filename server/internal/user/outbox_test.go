@@ -0,0 +1,256 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"user/db/generated"
+	"user/db/memory"
+	"user/dto"
+)
+
+func newTestOutboxRelay(queries db.Querier, sink common.EventSink) *OutboxRelay {
+	return &OutboxRelay{
+		Queries:        queries,
+		Sink:           sink,
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		BatchSize:      10,
+		PollInterval:   time.Millisecond,
+		Lease:          time.Minute,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// flakySink EventSink failing the first failures calls, then recording the events it receives
+type flakySink struct {
+	failures int
+	events   []common.Event
+}
+
+func (sink *flakySink) Publish(ctx context.Context, event *common.Event) error {
+	if sink.failures > 0 {
+		sink.failures--
+		return errors.New("unavailable")
+	}
+	sink.events = append(sink.events, *event)
+	return nil
+}
+
+func TestService_WritesOutboxEvents(t *testing.T) {
+	queries := memory.New()
+	service := &ServiceImpl{
+		Queries:    queries,
+		Transactor: queries,
+	}
+	ctx := context.Background()
+
+	created, err := service.CreateUser(
+		ctx, &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	username := "renamed"
+	steps := []func() error{
+		func() error {
+			_, err := service.UpdateUser(ctx, &dto.UpdateUserRequest{UserId: created.UserId, Username: &username})
+			return err
+		},
+		func() error {
+			_, err := service.VerifyUser(ctx, &dto.VerifyUserRequest{UserId: created.UserId, IsVerified: true})
+			return err
+		},
+		func() error {
+			_, err := service.DeleteUser(ctx, &dto.DeleteUserRequest{UserId: created.UserId})
+			return err
+		},
+		func() error {
+			_, err := service.DeleteUser(ctx, &dto.DeleteUserRequest{UserId: created.UserId})
+			return err
+		},
+		func() error {
+			_, err := service.RestoreUser(ctx, &dto.RestoreUserRequest{UserId: created.UserId})
+			return err
+		},
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf(`step %d error = "%v", expected "<nil>"`, i, err)
+		}
+	}
+
+	expected := []string{EventUserCreated, EventUserUpdated, EventUserVerified, EventUserDeleted, EventUserRestored}
+	outbox := queries.Outbox()
+	if len(outbox) != len(expected) {
+		t.Fatalf(`len(outbox) = "%d", expected "%d"`, len(outbox), len(expected))
+	}
+	for i, event := range outbox {
+		if event.EventType != expected[i] || event.UserID != int32(created.UserId) {
+			t.Errorf(`outbox[%d] = "%s" for user "%d", expected "%s"`, i, event.EventType, event.UserID, expected[i])
+		}
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(outbox[1].Payload, &payload); err != nil {
+		t.Fatalf(`json.Unmarshal(payload) error = "%v", expected "<nil>"`, err)
+	}
+	if payload["username"] != username {
+		t.Errorf(`payload["username"] = "%v", expected "%s"`, payload["username"], username)
+	}
+	if _, ok := payload["passwordHash"]; ok {
+		t.Error(`payload contains "passwordHash", expected it to be left out`)
+	}
+}
+
+func TestService_OutboxFailureRollsBackChange(t *testing.T) {
+	queries := memory.New()
+	failing := &mockQuerier{
+		insertOutboxEventFunc: func(ctx context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error) {
+			return db.UserOutbox{}, errors.New("outbox unavailable")
+		},
+	}
+	service := &ServiceImpl{
+		Queries: failing,
+		Transactor: &mockTransactor{
+			runInTxFunc: func(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error {
+				return queries.RunInTx(
+					ctx, options, func(tx db.Querier) error {
+						failing.createUserFunc = tx.CreateUser
+						return fn(failing)
+					},
+				)
+			},
+		},
+	}
+
+	_, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err == nil {
+		t.Fatal(`service.CreateUser(...) error = "<nil>", expected non-nil`)
+	}
+	if count, _ := queries.CountUsers(context.Background()); count != 0 {
+		t.Errorf(`count = "%d", expected "0"`, count)
+	}
+}
+
+func TestOutboxRelay_RetriesUntilDelivered(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := memory.New()
+	queries.Now = func() time.Time {
+		return now
+	}
+	_, _ = queries.InsertOutboxEvent(
+		context.Background(), db.InsertOutboxEventParams{
+			EventType: EventUserCreated,
+			UserID:    1,
+			Payload:   json.RawMessage(`{"userId":1}`),
+		},
+	)
+
+	sink := &flakySink{failures: 2}
+	relay := newTestOutboxRelay(queries, sink)
+
+	for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+		if _, err := relay.RelayBatch(context.Background()); err != nil {
+			t.Fatalf(`relay.RelayBatch(ctx) error = "%v", expected "<nil>"`, err)
+		}
+
+		event := queries.Outbox()[0]
+		if int(event.Attempts) != attempt+1 || !event.LastError.Valid {
+			t.Errorf(`event = "%+v", expected failed attempt %d`, event, attempt+1)
+		}
+		if expected := now.Add(backoff); !event.NextAttemptAt.Equal(expected) {
+			t.Errorf(`event.NextAttemptAt = "%v", expected "%v"`, event.NextAttemptAt, expected)
+		}
+
+		if relayed, _ := relay.RelayBatch(context.Background()); relayed != 0 {
+			t.Errorf(`relay.RelayBatch(ctx) relayed = "%d" before the backoff elapsed, expected "0"`, relayed)
+		}
+		now = event.NextAttemptAt
+	}
+
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatalf(`relay.RelayBatch(ctx) error = "%v", expected "<nil>"`, err)
+	}
+	if len(sink.events) != 1 || sink.events[0].Type != EventUserCreated || sink.events[0].Source != EventSource {
+		t.Fatalf(`sink.events = "%+v", expected one "%s" event`, sink.events, EventUserCreated)
+	}
+	if event := queries.Outbox()[0]; !event.DeliveredAt.Valid || event.LastError.Valid {
+		t.Errorf(`event = "%+v", expected it to be delivered`, event)
+	}
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := newTestOutboxRelay(nil, nil)
+
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, time.Second},
+		{3, 8 * time.Second},
+		{100, time.Minute},
+	}
+
+	for _, test := range tests {
+		if backoff := relay.backoff(test.attempts); backoff != test.expected {
+			t.Errorf(`relay.backoff(%d) = "%v", expected "%v"`, test.attempts, backoff, test.expected)
+		}
+	}
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	queries := memory.New()
+	for i := 0; i < 25; i++ {
+		_, _ = queries.InsertOutboxEvent(
+			context.Background(), db.InsertOutboxEventParams{
+				EventType: EventUserUpdated,
+				UserID:    int32(i),
+				Payload:   json.RawMessage(`{}`),
+			},
+		)
+	}
+
+	publisher := common.NewMemoryPublisher()
+	relay := newTestOutboxRelay(queries, &common.PublisherSink{Publisher: publisher})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(publisher.Messages()) < 25 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	messages := publisher.Messages()
+	if len(messages) != 25 {
+		t.Fatalf(`len(messages) = "%d", expected "25"`, len(messages))
+	}
+	var first common.Event
+	_ = json.Unmarshal(messages[0].Data, &first)
+	if first.Id != 1 {
+		t.Errorf(`first.Id = "%d", expected "1"`, first.Id)
+	}
+}
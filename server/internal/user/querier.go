@@ -0,0 +1,348 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"time"
+	"user/db/generated"
+	"user/db/memory"
+	"user/db/sqlite"
+)
+
+// NewService Create the service for the configured database driver, along with a function releasing its resources
+func NewService(ctx context.Context, config *common.DatabaseConfig) (*ServiceImpl, func() error, error) {
+	if config.Driver == common.DriverMemory {
+		queries := memory.New()
+		service := &ServiceImpl{
+			Queries:    queries,
+			Transactor: queries,
+		}
+		return service, func() error { return nil }, nil
+	}
+
+	database, err := common.OpenDatabase(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newQuerier := func(tx db.DBTX) db.Querier {
+		return NewTimeoutQuerier(db.New(tx), config.QueryTimeout)
+	}
+	if config.Driver == common.DriverSQLite {
+		newQuerier = func(tx db.DBTX) db.Querier {
+			return NewTimeoutQuerier(sqlite.New(tx), config.QueryTimeout)
+		}
+	}
+
+	service := &ServiceImpl{
+		Queries: newQuerier(database),
+		Transactor: &SQLTransactor{
+			Database:   database,
+			NewQuerier: newQuerier,
+			MaxRetries: common.DefaultTxMaxRetries,
+		},
+	}
+	return service, database.Close, nil
+}
+
+// TimeoutQuerier db.Querier decorator bounding every query by a timeout derived from the request context
+type TimeoutQuerier struct {
+	Queries db.Querier
+	Timeout time.Duration
+}
+
+// NewTimeoutQuerier Wrap the specified querier so each query is cancelled after the timeout
+func NewTimeoutQuerier(queries db.Querier, timeout time.Duration) *TimeoutQuerier {
+	return &TimeoutQuerier{
+		Queries: queries,
+		Timeout: timeout,
+	}
+}
+
+// CountUsers CountUsers() implementation from db.Querier interface
+func (q *TimeoutQuerier) CountUsers(ctx context.Context) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CountUsers(ctx)
+}
+
+// CreateUser CreateUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateUser(ctx, arg)
+}
+
+// DeleteUser DeleteUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteUser(ctx context.Context, id int32) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteUser(ctx, id)
+}
+
+// GetUser GetUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUser(ctx context.Context, arg db.GetUserParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUser(ctx, arg)
+}
+
+// GetUsers GetUsers() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUsers(ctx context.Context, arg db.GetUsersParams) ([]db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUsers(ctx, arg)
+}
+
+// UpdateUser UpdateUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.UpdateUser(ctx, arg)
+}
+
+// SetUserVerified SetUserVerified() implementation from db.Querier interface
+func (q *TimeoutQuerier) SetUserVerified(ctx context.Context, arg db.SetUserVerifiedParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.SetUserVerified(ctx, arg)
+}
+
+// RestoreUser RestoreUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) RestoreUser(ctx context.Context, usersID int32) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.RestoreUser(ctx, usersID)
+}
+
+// SetUserPendingEmail SetUserPendingEmail() implementation from db.Querier interface
+func (q *TimeoutQuerier) SetUserPendingEmail(ctx context.Context, arg db.SetUserPendingEmailParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.SetUserPendingEmail(ctx, arg)
+}
+
+// SetUserEmail SetUserEmail() implementation from db.Querier interface
+func (q *TimeoutQuerier) SetUserEmail(ctx context.Context, arg db.SetUserEmailParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.SetUserEmail(ctx, arg)
+}
+
+// CreateEmailChange CreateEmailChange() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateEmailChange(ctx context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateEmailChange(ctx, arg)
+}
+
+// GetEmailChangeByConfirmToken GetEmailChangeByConfirmToken() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetEmailChangeByConfirmToken(ctx context.Context, confirmTokenHash string) (db.EmailChange, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetEmailChangeByConfirmToken(ctx, confirmTokenHash)
+}
+
+// GetEmailChangeByUndoToken GetEmailChangeByUndoToken() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetEmailChangeByUndoToken(ctx context.Context, undoTokenHash string) (db.EmailChange, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetEmailChangeByUndoToken(ctx, undoTokenHash)
+}
+
+// CancelPendingEmailChanges CancelPendingEmailChanges() implementation from db.Querier interface
+func (q *TimeoutQuerier) CancelPendingEmailChanges(ctx context.Context, userID int32) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CancelPendingEmailChanges(ctx, userID)
+}
+
+// MarkEmailChangeConfirmed MarkEmailChangeConfirmed() implementation from db.Querier interface
+func (q *TimeoutQuerier) MarkEmailChangeConfirmed(ctx context.Context, id int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.MarkEmailChangeConfirmed(ctx, id)
+}
+
+// MarkEmailChangeUndone MarkEmailChangeUndone() implementation from db.Querier interface
+func (q *TimeoutQuerier) MarkEmailChangeUndone(ctx context.Context, id int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.MarkEmailChangeUndone(ctx, id)
+}
+
+// GetEmailChangesByUser GetEmailChangesByUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetEmailChangesByUser(ctx context.Context, userID int32) ([]db.EmailChange, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetEmailChangesByUser(ctx, userID)
+}
+
+// ScheduleUserDeletion ScheduleUserDeletion() implementation from db.Querier interface
+func (q *TimeoutQuerier) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.ScheduleUserDeletion(ctx, arg)
+}
+
+// CancelUserDeletion CancelUserDeletion() implementation from db.Querier interface
+func (q *TimeoutQuerier) CancelUserDeletion(ctx context.Context, id int32) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CancelUserDeletion(ctx, id)
+}
+
+// GetUserByDeletionToken GetUserByDeletionToken() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUserByDeletionToken(ctx context.Context, deletionTokenHash sql.NullString) (db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUserByDeletionToken(ctx, deletionTokenHash)
+}
+
+// GetUsersDueForDeletion GetUsersDueForDeletion() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUsersDueForDeletion(ctx context.Context, batchSize int32) ([]db.User, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUsersDueForDeletion(ctx, batchSize)
+}
+
+// DeleteUserIfDue DeleteUserIfDue() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteUserIfDue(ctx context.Context, id int32) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteUserIfDue(ctx, id)
+}
+
+// AnonymizeArchivedUsers AnonymizeArchivedUsers() implementation from db.Querier interface
+func (q *TimeoutQuerier) AnonymizeArchivedUsers(ctx context.Context, retentionSeconds float64) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.AnonymizeArchivedUsers(ctx, retentionSeconds)
+}
+
+// GetUserArchive GetUserArchive() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetUserArchive(ctx context.Context, usersID int32) ([]db.UsersArchive, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetUserArchive(ctx, usersID)
+}
+
+// InsertOutboxEvent InsertOutboxEvent() implementation from db.Querier interface
+func (q *TimeoutQuerier) InsertOutboxEvent(ctx context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.InsertOutboxEvent(ctx, arg)
+}
+
+// ClaimOutboxEvents ClaimOutboxEvents() implementation from db.Querier interface
+func (q *TimeoutQuerier) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.UserOutbox, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.ClaimOutboxEvents(ctx, arg)
+}
+
+// MarkOutboxEventDelivered MarkOutboxEventDelivered() implementation from db.Querier interface
+func (q *TimeoutQuerier) MarkOutboxEventDelivered(ctx context.Context, id int64) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.MarkOutboxEventDelivered(ctx, id)
+}
+
+// MarkOutboxEventFailed MarkOutboxEventFailed() implementation from db.Querier interface
+func (q *TimeoutQuerier) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.MarkOutboxEventFailed(ctx, arg)
+}
+
+// DeleteDeliveredOutboxEvents DeleteDeliveredOutboxEvents() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteDeliveredOutboxEvents(ctx context.Context, retentionSeconds float64) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteDeliveredOutboxEvents(ctx, retentionSeconds)
+}
+
+// GetOutboxEventsByUser GetOutboxEventsByUser() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetOutboxEventsByUser(ctx context.Context, userID int32) ([]db.UserOutbox, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetOutboxEventsByUser(ctx, userID)
+}
+
+// CreateDataExport CreateDataExport() implementation from db.Querier interface
+func (q *TimeoutQuerier) CreateDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.CreateDataExport(ctx, userID)
+}
+
+// GetDataExport GetDataExport() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetDataExport(ctx context.Context, arg db.GetDataExportParams) (db.DataExport, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetDataExport(ctx, arg)
+}
+
+// GetLatestDataExport GetLatestDataExport() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetLatestDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetLatestDataExport(ctx, userID)
+}
+
+// GetDataExportByDownloadToken GetDataExportByDownloadToken() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetDataExportByDownloadToken(ctx context.Context, downloadTokenHash sql.NullString) (db.DataExport, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetDataExportByDownloadToken(ctx, downloadTokenHash)
+}
+
+// ClaimDataExport ClaimDataExport() implementation from db.Querier interface
+func (q *TimeoutQuerier) ClaimDataExport(ctx context.Context, leaseSeconds float64) (db.DataExport, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.ClaimDataExport(ctx, leaseSeconds)
+}
+
+// InsertDataExportArchive InsertDataExportArchive() implementation from db.Querier interface
+func (q *TimeoutQuerier) InsertDataExportArchive(ctx context.Context, arg db.InsertDataExportArchiveParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.InsertDataExportArchive(ctx, arg)
+}
+
+// GetDataExportArchive GetDataExportArchive() implementation from db.Querier interface
+func (q *TimeoutQuerier) GetDataExportArchive(ctx context.Context, dataExportID int64) ([]byte, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.GetDataExportArchive(ctx, dataExportID)
+}
+
+// MarkDataExportReady MarkDataExportReady() implementation from db.Querier interface
+func (q *TimeoutQuerier) MarkDataExportReady(ctx context.Context, arg db.MarkDataExportReadyParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.MarkDataExportReady(ctx, arg)
+}
+
+// MarkDataExportFailed MarkDataExportFailed() implementation from db.Querier interface
+func (q *TimeoutQuerier) MarkDataExportFailed(ctx context.Context, arg db.MarkDataExportFailedParams) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.MarkDataExportFailed(ctx, arg)
+}
+
+// DeleteExpiredDataExportArchives DeleteExpiredDataExportArchives() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteExpiredDataExportArchives(ctx context.Context) (int64, error) {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteExpiredDataExportArchives(ctx)
+}
+
+// DeleteUserDataExportArchives DeleteUserDataExportArchives() implementation from db.Querier interface
+func (q *TimeoutQuerier) DeleteUserDataExportArchives(ctx context.Context, userID int32) error {
+	ctx, cancel := common.WithQueryTimeout(ctx, q.Timeout)
+	defer cancel()
+	return q.Queries.DeleteUserDataExportArchives(ctx, userID)
+}
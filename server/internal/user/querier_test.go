@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+	"user/db/generated"
+)
+
+func TestTimeoutQuerier_AppliesTimeout(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	querier := NewTimeoutQuerier(
+		&mockQuerier{
+			countUsersFunc: func(ctx context.Context) (int64, error) {
+				deadline, hasDeadline = ctx.Deadline()
+				return 1, nil
+			},
+		},
+		time.Second,
+	)
+
+	if _, err := querier.CountUsers(context.Background()); err != nil {
+		t.Errorf(`querier.CountUsers(context.Background()) error = "%v", expected "<nil>"`, err)
+	}
+
+	if !hasDeadline {
+		t.Fatal(`ctx.Deadline() ok = "false", expected "true"`)
+	}
+	if time.Until(deadline) > time.Second {
+		t.Errorf(`time.Until(deadline) = "%v", expected at most "1s"`, time.Until(deadline))
+	}
+}
+
+func TestTimeoutQuerier_CancelsAfterQuery(t *testing.T) {
+	var queryContext context.Context
+	querier := NewTimeoutQuerier(
+		&mockQuerier{
+			getUserFunc: func(ctx context.Context, arg db.GetUserParams) (db.User, error) {
+				queryContext = ctx
+				return db.User{}, nil
+			},
+		},
+		time.Minute,
+	)
+
+	if _, err := querier.GetUser(context.Background(), db.GetUserParams{}); err != nil {
+		t.Errorf(`querier.GetUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if queryContext.Err() == nil {
+		t.Error(`queryContext.Err() = "<nil>", expected non-nil`)
+	}
+}
@@ -2,67 +2,183 @@ package user
 
 import (
 	"common"
-	"fmt"
+	"context"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
 	_ "github.com/lib/pq" // registers "postgres" driver
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"time"
-	"user/db/generated"
 )
 
 // RunServer Start the user service and listen for requests
 func RunServer() {
+	logger := common.NewLogger()
+	slog.SetDefault(logger)
+
 	if err := common.InitJWT(); err != nil {
-		log.Fatal("Error initializing JWT")
-		return
+		logger.Error("Error initializing JWT", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	databaseConfig, err := common.LoadDatabaseConfig()
+	if err != nil {
+		logger.Error("Error loading database configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	service, closeDatabase, err := NewService(context.Background(), databaseConfig)
+	if err != nil {
+		logger.Error("Error establishing database connection", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer closeDatabase()
+
+	mailConfig, err := common.LoadMailConfig()
+	if err != nil {
+		logger.Error("Error loading mail configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+	service.Mailer = common.NewMailer(mailConfig, logger)
+	service.AppUrl = mailConfig.AppUrl
+
+	deletionConfig, err := common.LoadDeletionConfig()
+	if err != nil {
+		logger.Error("Error loading account deletion configuration", slog.Any("error", err))
+		os.Exit(1)
 	}
+	service.DeletionGracePeriod = deletionConfig.GracePeriod
+	purger := NewDeletionPurger(service, deletionConfig, logger)
 
-	database, err := common.GetDatabaseConnection()
+	dataExportConfig, err := common.LoadDataExportConfig()
 	if err != nil {
-		log.Fatalf("Error establishing database connection: %v", err)
-		return
+		logger.Error("Error loading data export configuration", slog.Any("error", err))
+		os.Exit(1)
 	}
+	go NewDataExporter(service, dataExportConfig, logger).Run(context.Background())
 
-	queries := db.New(database)
-	service := &ServiceImpl{
-		Queries: queries,
+	cacheConfig, err := common.LoadCacheConfig()
+	if err != nil {
+		logger.Error("Error loading cache configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	var handlerService Service = service
+	if cacheConfig.Size > 0 {
+		cachingService := NewCachingService(service, common.NewLRUCache(cacheConfig.Size), cacheConfig.TTL)
+		cachingService.Metrics.Publish("user_cache")
+		handlerService = cachingService
+		purger.OnPurged = cachingService.Invalidate
 	}
+	go purger.Run(context.Background())
+
+	outboxConfig, err := common.LoadOutboxConfig()
+	if err != nil {
+		logger.Error("Error loading outbox configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if outboxConfig.WebhookUrl != "" {
+		sink := common.NewWebhookSink(outboxConfig.WebhookUrl, outboxConfig.WebhookSecret)
+		relay := NewOutboxRelay(service.Queries, sink, outboxConfig, logger)
+		go relay.Run(context.Background())
 
+		logger.Info("Relaying user events to " + outboxConfig.WebhookUrl)
+	}
+
+	router := NewRouter(handlerService, logger)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		logger.Error("PORT environment variable not set")
+		os.Exit(1)
+	}
+
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			logger.Error("Error listening for gRPC", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		grpcServer := NewGRPCServer(handlerService, logger)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Error("gRPC server error", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+
+		logger.Info("Serving gRPC on port " + grpcPort)
+	}
+
+	if metricsPort := os.Getenv("METRICS_PORT"); metricsPort != "" {
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, common.NewMetricsHandler()); err != nil {
+				logger.Error("Metrics server error", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+
+		logger.Info("Serving metrics on port " + metricsPort)
+	}
+
+	logger.Info("Listening on port " + port)
+
+	err = http.ListenAndServe(":"+port, router)
+	if err != nil {
+		logger.Error("Server error", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+// NewRouter Create the router for the user service with all routes registered
+func NewRouter(service Service, logger *slog.Logger) *chi.Mux {
 	router := chi.NewRouter()
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
-	router.Use(middleware.Logger)
+	router.Use(common.RequestLogger(logger))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(time.Minute))
 
+	router.Get("/openapi.json", OpenAPIHandler())
+	router.Get("/docs", DocsHandler())
+
 	router.Post("/user", CreateUserHandler(service))
+	// The token sent by email authorizes these, so they are reachable without signing in. Cancelling deletion must be,
+	// since accounts scheduled for deletion cannot sign in
+	router.Post("/user/email/confirm", ConfirmEmailChangeHandler(service))
+	router.Post("/user/email/undo", UndoEmailChangeHandler(service))
+	router.Post("/user/deletion/cancel", CancelUserDeletionHandler(service))
+	router.Get("/user/export/download", DownloadDataExportHandler(service))
 	router.Group(
 		func(router chi.Router) {
-			// TODO r.Use(jwtauth.Verifier(tokenAuth))
-			// TODO r.Use(jwtauth.Authenticator(tokenAuth))
-			// TODO r.Use(user.authMiddleware(*queries))
+			router.Use(jwtauth.Verifier(common.TokenAuth))
+			router.Use(jwtauth.Authenticator(common.TokenAuth))
+			router.Use(common.AuthMiddleware(localUserGetter{server: &GRPCServer{Service: service}}))
 
 			router.Get("/user/me", GetCurrentUserHandler(service))
+			router.Post("/user/me/deletion", ScheduleCurrentUserDeletionHandler(service))
+			router.Get("/user/me/export", RequestCurrentUserDataExportHandler(service))
+			router.Get("/user/me/export/{exportId}", GetCurrentUserDataExportHandler(service))
 			router.Get("/user", GetUserHandler(service))
 			router.Get("/user/all", GetUsersHandler(service))
 			router.Patch("/user/{id}", UpdateUserHandler(service))
 			router.Delete("/user/{id}", DeleteUserHandler(service))
-		},
-	)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		log.Fatal("PORT environment variable not set")
-		return
-	}
+			router.Group(
+				func(router chi.Router) {
+					router.Use(common.AdminOnly)
 
-	fmt.Println("Listening on port " + port)
+					router.Get("/user/export", ExportUsersHandler(service))
+					router.Post("/user/import", ImportUsersHandler(service))
+				},
+			)
+		},
+	)
 
-	err = http.ListenAndServe(":"+port, router)
-	if err != nil {
-		log.Fatalf("Server error: %v", err)
-	}
+	return router
 }
@@ -5,8 +5,10 @@ import (
 	"common"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"time"
 	"user/db/generated"
 	"user/dto"
 )
@@ -20,11 +22,55 @@ type Service interface {
 	GetUsers(context context.Context, request *dto.GetUsersRequest) (*dto.GetUsersResponse, error)
 	UpdateUser(context context.Context, request *dto.UpdateUserRequest) (*dto.UpdateUserResponse, error)
 	DeleteUser(context context.Context, request *dto.DeleteUserRequest) (*dto.DeleteUserResponse, error)
+	VerifyUser(context context.Context, request *dto.VerifyUserRequest) (*dto.VerifyUserResponse, error)
+	RestoreUser(context context.Context, request *dto.RestoreUserRequest) (*dto.RestoreUserResponse, error)
+	ConfirmEmailChange(
+		context context.Context,
+		request *dto.ConfirmEmailChangeRequest,
+	) (*dto.ConfirmEmailChangeResponse, error)
+	UndoEmailChange(context context.Context, request *dto.UndoEmailChangeRequest) (*dto.UndoEmailChangeResponse, error)
+	ScheduleUserDeletion(
+		context context.Context,
+		request *dto.ScheduleUserDeletionRequest,
+	) (*dto.ScheduleUserDeletionResponse, error)
+	CancelUserDeletion(
+		context context.Context,
+		request *dto.CancelUserDeletionRequest,
+	) (*dto.CancelUserDeletionResponse, error)
+	RequestDataExport(
+		context context.Context,
+		request *dto.RequestDataExportRequest,
+	) (*dto.RequestDataExportResponse, error)
+	GetDataExport(context context.Context, request *dto.GetDataExportRequest) (*dto.GetDataExportResponse, error)
+	DownloadDataExport(
+		context context.Context,
+		request *dto.DownloadDataExportRequest,
+	) (*dto.DownloadDataExportResponse, error)
 }
 
 // ServiceImpl Implementation for the Service
 type ServiceImpl struct {
 	Queries db.Querier
+	// Transactor Runs multi-step operations atomically. When nil, queries run directly against Queries
+	Transactor Transactor
+	// Mailer Sends email change, account deletion and data export links. When nil, no emails are sent
+	Mailer common.Mailer
+	// AppUrl Base url of the web app, used for links in emails
+	AppUrl string
+	// DeletionGracePeriod How long a scheduled deletion can be cancelled. Zero uses common.DefaultDeletionGracePeriod
+	DeletionGracePeriod time.Duration
+}
+
+// runInTx Run fn inside a transaction if a Transactor is configured, otherwise directly against Queries
+func (service *ServiceImpl) runInTx(
+	context context.Context,
+	options *sql.TxOptions,
+	fn func(queries db.Querier) error,
+) error {
+	if service.Transactor == nil {
+		return fn(service.Queries)
+	}
+	return service.Transactor.RunInTx(context, options, fn)
 }
 
 // CreateUser Create a new user
@@ -43,7 +89,17 @@ func (service *ServiceImpl) CreateUser(
 	}
 	params.PasswordHash = string(hashedPassword)
 
-	user, err := service.Queries.CreateUser(context, params)
+	var user db.User
+	err = service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			user, err = queries.CreateUser(context, params)
+			if err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserCreated, user)
+		},
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -85,13 +141,15 @@ func (service *ServiceImpl) GetUser(context context.Context, request *dto.GetUse
 	}
 
 	return &dto.GetUserResponse{
-		UserId:       int(user.ID),
-		Username:     user.Username,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-		IsVerified:   user.IsVerified,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
 	}, nil
 }
 
@@ -115,26 +173,40 @@ func (service *ServiceImpl) GetUsers(
 		params.Offset = int32(*request.Offset)
 	}
 
-	userCount, err := service.Queries.CountUsers(context)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count users: %w", err)
-	}
-
-	users, err := service.Queries.GetUsers(context, params)
+	// Count and page in one snapshot so the pagination links match the returned page
+	var userCount int64
+	var users []db.User
+	err := service.runInTx(
+		context, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries db.Querier) error {
+			var err error
+			userCount, err = queries.CountUsers(context)
+			if err != nil {
+				return fmt.Errorf("failed to count users: %w", err)
+			}
+
+			users, err = queries.GetUsers(context, params)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve users: %w", err)
+			}
+			return nil
+		},
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve users: %w", err)
+		return nil, err
 	}
 
 	response := dto.GetUsersResponse{Users: make([]dto.GetUserResponse, len(users))}
 	for i, user := range users {
 		response.Users[i] = dto.GetUserResponse{
-			UserId:       int(user.ID),
-			Username:     user.Username,
-			Email:        user.Email,
-			PasswordHash: user.PasswordHash,
-			IsVerified:   user.IsVerified,
-			CreatedAt:    user.CreatedAt,
-			UpdatedAt:    user.UpdatedAt,
+			UserId:              int(user.ID),
+			Username:            user.Username,
+			Email:               user.Email,
+			PasswordHash:        user.PasswordHash,
+			IsVerified:          user.IsVerified,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
+			PendingEmail:        pendingEmail(user),
+			DeletionScheduledAt: deletionScheduledAt(user),
 		}
 	}
 
@@ -189,11 +261,8 @@ func (service *ServiceImpl) UpdateUser(
 		params.Username = sql.NullString{String: *request.Username, Valid: true}
 	}
 
-	if request.Email == nil {
-		params.Email = sql.NullString{String: "", Valid: false}
-	} else {
-		params.Email = sql.NullString{String: *request.Email, Valid: true}
-	}
+	// Email changes only take effect once the new address is confirmed, see requestEmailChange
+	params.Email = sql.NullString{String: "", Valid: false}
 
 	if request.Password == nil {
 		params.PasswordHash = sql.NullString{String: "", Valid: false}
@@ -208,19 +277,40 @@ func (service *ServiceImpl) UpdateUser(
 		params.PasswordHash = sql.NullString{String: string(hashedPassword), Valid: true}
 	}
 
-	user, err := service.Queries.UpdateUser(context, params)
+	var user db.User
+	var mails []common.MailMessage
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			user, err = queries.UpdateUser(context, params)
+			if err != nil {
+				return err
+			}
+
+			if request.Email != nil && *request.Email != user.Email {
+				user, mails, err = service.requestEmailChange(context, queries, user, *request.Email)
+				if err != nil {
+					return err
+				}
+			}
+			return writeUserEvent(context, queries, EventUserUpdated, user)
+		},
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	service.sendMails(context, mails)
 
 	return &dto.UpdateUserResponse{
-		UserId:       int(user.ID),
-		Username:     user.Username,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-		IsVerified:   user.IsVerified,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
 	}, nil
 }
 
@@ -229,9 +319,99 @@ func (service *ServiceImpl) DeleteUser(
 	context context.Context,
 	request *dto.DeleteUserRequest,
 ) (*dto.DeleteUserResponse, error) {
-	err := service.Queries.DeleteUser(context, int32(request.UserId))
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			// The user is read first so the event carries the deleted row; deleting a missing user stays a no-op
+			user, err := queries.GetUser(
+				context, db.GetUserParams{
+					ID: sql.NullInt32{Int32: int32(request.UserId), Valid: true},
+				},
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if err := queries.DeleteUser(context, user.ID); err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserDeleted, user)
+		},
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete user: %w", err)
 	}
 	return &dto.DeleteUserResponse{}, nil
 }
+
+// VerifyUser Mark a user as verified or unverified
+func (service *ServiceImpl) VerifyUser(
+	context context.Context,
+	request *dto.VerifyUserRequest,
+) (*dto.VerifyUserResponse, error) {
+	params := db.SetUserVerifiedParams{
+		ID:         int32(request.UserId),
+		IsVerified: request.IsVerified,
+	}
+
+	var user db.User
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			user, err = queries.SetUserVerified(context, params)
+			if err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserVerified, user)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set user verification: %w", err)
+	}
+
+	return &dto.VerifyUserResponse{
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
+
+// RestoreUser Restore a deleted user from the most recent users_archive entry
+func (service *ServiceImpl) RestoreUser(
+	context context.Context,
+	request *dto.RestoreUserRequest,
+) (*dto.RestoreUserResponse, error) {
+	var user db.User
+	err := service.runInTx(
+		context, nil, func(queries db.Querier) error {
+			var err error
+			user, err = queries.RestoreUser(context, int32(request.UserId))
+			if err != nil {
+				return err
+			}
+			return writeUserEvent(context, queries, EventUserRestored, user)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	return &dto.RestoreUserResponse{
+		UserId:              int(user.ID),
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		IsVerified:          user.IsVerified,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PendingEmail:        pendingEmail(user),
+		DeletionScheduledAt: deletionScheduledAt(user),
+	}, nil
+}
@@ -3,6 +3,7 @@ package user
 import (
     "common"
     "context"
+    "database/sql"
     "errors"
     "fmt"
     "github.com/go-chi/chi/v5"
@@ -10,6 +11,7 @@ import (
     "testing"
     "time"
     "user/db/generated"
+    "user/db/memory"
     "user/dto"
 )
 
@@ -487,19 +489,37 @@ func TestService_UpdateUser_Success(t *testing.T) {
         CreatedAt:    time.Now(),
         UpdatedAt:    time.Now(),
     }
+    newEmail := "new@email.com"
+    var change db.CreateEmailChangeParams
     mockQuerier := &mockQuerier{
         updateUserFunc: func(context context.Context, arg db.UpdateUserParams) (db.User, error) {
+            if arg.Email.Valid {
+                t.Errorf(`arg.Email = "%v", expected the email to be left unchanged`, arg.Email)
+            }
+            return mockUser, nil
+        },
+        cancelPendingEmailChangesFunc: func(context context.Context, userID int32) error {
+            return nil
+        },
+        createEmailChangeFunc: func(context context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error) {
+            change = arg
+            return db.EmailChange{}, nil
+        },
+        setUserPendingEmailFunc: func(context context.Context, arg db.SetUserPendingEmailParams) (db.User, error) {
+            mockUser.PendingEmail = arg.PendingEmail
             return mockUser, nil
         },
     }
+    mailer := &common.MemoryMailer{}
     service := ServiceImpl{
         Queries: mockQuerier,
+        Mailer:  mailer,
     }
 
     request := dto.UpdateUserRequest{
         UserId:   userId,
         Username: &username,
-        Email:    &email,
+        Email:    &newEmail,
         Password: &password,
     }
     response, err := service.UpdateUser(nil, &request)
@@ -511,6 +531,15 @@ func TestService_UpdateUser_Success(t *testing.T) {
         return
     }
     assertUserEqualToDB(t, response, &mockUser)
+    if response.PendingEmail == nil || *response.PendingEmail != newEmail {
+        t.Errorf(`response.PendingEmail = "%v", expected "%s"`, response.PendingEmail, newEmail)
+    }
+    if change.OldEmail != email || change.NewEmail != newEmail || !change.OldIsVerified {
+        t.Errorf(`change = "%+v", expected a change from "%s" to "%s"`, change, email, newEmail)
+    }
+    if messages := mailer.Messages(); len(messages) != 2 || messages[0].To != newEmail || messages[1].To != email {
+        t.Errorf(`messages = "%+v", expected mails to "%s" and "%s"`, messages, newEmail, email)
+    }
 }
 
 func TestService_UpdateUser_NoChange(t *testing.T) {
@@ -571,10 +600,18 @@ func TestService_UpdateUser_QueryFailure(t *testing.T) {
 }
 
 func TestService_DeleteUser_Success(t *testing.T) {
+    var events []db.InsertOutboxEventParams
     mockQuerier := &mockQuerier{
+        getUserFunc: func(context context.Context, arg db.GetUserParams) (db.User, error) {
+            return db.User{ID: arg.ID.Int32, Username: "user"}, nil
+        },
         deleteUserFunc: func(context context.Context, arg int32) error {
             return nil
         },
+        insertOutboxEventFunc: func(context context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error) {
+            events = append(events, arg)
+            return db.UserOutbox{}, nil
+        },
     }
     service := ServiceImpl{
         Queries: mockQuerier,
@@ -591,10 +628,32 @@ func TestService_DeleteUser_Success(t *testing.T) {
     if response == nil {
         t.Error(`service.GetUser(nil, request) response = "<nil>", expected non-nil`)
     }
+    if len(events) != 1 || events[0].EventType != EventUserDeleted || events[0].UserID != int32(userId) {
+        t.Errorf(`events = "%+v", expected one "%s" event for user "%d"`, events, EventUserDeleted, userId)
+    }
+}
+
+func TestService_DeleteUser_NotFound(t *testing.T) {
+    mockQuerier := &mockQuerier{
+        getUserFunc: func(context context.Context, arg db.GetUserParams) (db.User, error) {
+            return db.User{}, sql.ErrNoRows
+        },
+    }
+    service := ServiceImpl{
+        Queries: mockQuerier,
+    }
+
+    _, err := service.DeleteUser(nil, &dto.DeleteUserRequest{UserId: 1})
+    if err != nil {
+        t.Errorf(`service.DeleteUser(nil, request) error = "%v", expected "<nil>"`, err)
+    }
 }
 
 func TestService_DeleteUser_QueryFailure(t *testing.T) {
     mockQuerier := &mockQuerier{
+        getUserFunc: func(context context.Context, arg db.GetUserParams) (db.User, error) {
+            return db.User{ID: arg.ID.Int32}, nil
+        },
         deleteUserFunc: func(context context.Context, arg int32) error {
             return errors.New("")
         },
@@ -613,13 +672,252 @@ func TestService_DeleteUser_QueryFailure(t *testing.T) {
     }
 }
 
+func TestService_VerifyUser_Success(t *testing.T) {
+    expected := db.User{
+        ID:           1,
+        Username:     ValidUsername,
+        Email:        ValidEmail,
+        PasswordHash: ValidPassword,
+        IsVerified:   true,
+        CreatedAt:    time.Now(),
+        UpdatedAt:    time.Now(),
+    }
+    mockQuerier := &mockQuerier{
+        setUserVerifiedFunc: func(context context.Context, arg db.SetUserVerifiedParams) (db.User, error) {
+            if arg.ID != expected.ID || !arg.IsVerified {
+                t.Errorf(`arg = "%+v", expected ID "%d" and IsVerified "true"`, arg, expected.ID)
+            }
+            return expected, nil
+        },
+    }
+    service := ServiceImpl{
+        Queries: mockQuerier,
+    }
+
+    request := dto.VerifyUserRequest{
+        UserId:     int(expected.ID),
+        IsVerified: true,
+    }
+    response, err := service.VerifyUser(nil, &request)
+    if err != nil {
+        t.Errorf(`service.VerifyUser(nil, request) error = "%v", expected "<nil>"`, err)
+    }
+    if response == nil {
+        t.Error(`service.VerifyUser(nil, request) response = "<nil>", expected non-nil`)
+        return
+    }
+    assertUserEqualToDB(t, response, &expected)
+}
+
+func TestService_VerifyUser_QueryFailure(t *testing.T) {
+    mockQuerier := &mockQuerier{
+        setUserVerifiedFunc: func(context context.Context, arg db.SetUserVerifiedParams) (db.User, error) {
+            return db.User{}, errors.New("")
+        },
+    }
+    service := ServiceImpl{
+        Queries: mockQuerier,
+    }
+
+    request := dto.VerifyUserRequest{
+        UserId:     1,
+        IsVerified: true,
+    }
+    if _, err := service.VerifyUser(nil, &request); err == nil {
+        t.Error(`service.VerifyUser(nil, request) error = "<nil>", expected non-nil`)
+    }
+}
+
+func TestService_RestoreUser_Success(t *testing.T) {
+    expected := db.User{
+        ID:           1,
+        Username:     ValidUsername,
+        Email:        ValidEmail,
+        PasswordHash: ValidPassword,
+        IsVerified:   true,
+        CreatedAt:    time.Now(),
+        UpdatedAt:    time.Now(),
+    }
+    mockQuerier := &mockQuerier{
+        restoreUserFunc: func(context context.Context, usersID int32) (db.User, error) {
+            return expected, nil
+        },
+    }
+    service := ServiceImpl{
+        Queries: mockQuerier,
+    }
+
+    request := dto.RestoreUserRequest{
+        UserId: int(expected.ID),
+    }
+    response, err := service.RestoreUser(nil, &request)
+    if err != nil {
+        t.Errorf(`service.RestoreUser(nil, request) error = "%v", expected "<nil>"`, err)
+    }
+    if response == nil {
+        t.Error(`service.RestoreUser(nil, request) response = "<nil>", expected non-nil`)
+        return
+    }
+    assertUserEqualToDB(t, response, &expected)
+}
+
+func TestService_RestoreUser_QueryFailure(t *testing.T) {
+    mockQuerier := &mockQuerier{
+        restoreUserFunc: func(context context.Context, usersID int32) (db.User, error) {
+            return db.User{}, errors.New("")
+        },
+    }
+    service := ServiceImpl{
+        Queries: mockQuerier,
+    }
+
+    request := dto.RestoreUserRequest{
+        UserId: 1,
+    }
+    if _, err := service.RestoreUser(nil, &request); err == nil {
+        t.Error(`service.RestoreUser(nil, request) error = "<nil>", expected non-nil`)
+    }
+}
+
+func TestService_GetUsers_Transaction(t *testing.T) {
+    t.Setenv(BASE_URL_KEY, MockUrl)
+    queries := memory.New()
+    for i := 0; i < 3; i++ {
+        _, err := queries.CreateUser(
+            context.Background(), db.CreateUserParams{
+                Username: fmt.Sprintf("user-%d", i),
+                Email:    fmt.Sprintf("user-%d@email.com", i),
+            },
+        )
+        if err != nil {
+            t.Fatalf(`queries.CreateUser(...) error = "%v", expected "<nil>"`, err)
+        }
+    }
+
+    var txOptions *sql.TxOptions
+    transactor := &mockTransactor{
+        runInTxFunc: func(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error {
+            txOptions = options
+            return queries.RunInTx(ctx, options, fn)
+        },
+    }
+    service := ServiceImpl{
+        Queries:    &mockQuerier{},
+        Transactor: transactor,
+    }
+
+    limit := 2
+    request := dto.GetUsersRequest{
+        Limit: &limit,
+    }
+    ctx := context.WithValue(context.Background(), chi.RouteCtxKey, chi.NewRouteContext())
+    response, err := service.GetUsers(ctx, &request)
+    if err != nil {
+        t.Fatalf(`service.GetUsers(ctx, request) error = "%v", expected "<nil>"`, err)
+    }
+
+    if txOptions == nil || !txOptions.ReadOnly || txOptions.Isolation != sql.LevelRepeatableRead {
+        t.Errorf(`txOptions = "%+v", expected a read-only repeatable read transaction`, txOptions)
+    }
+    if len(response.Users) != 2 || response.NextLink == nil {
+        t.Errorf(`response = "%+v", expected 2 users and a next link`, response)
+    }
+}
+
+func TestService_UpdateUser_TransactionFailure(t *testing.T) {
+    queries := memory.New()
+    user, err := queries.CreateUser(
+        context.Background(), db.CreateUserParams{
+            Username: ValidUsername,
+            Email:    ValidEmail,
+        },
+    )
+    if err != nil {
+        t.Fatalf(`queries.CreateUser(...) error = "%v", expected "<nil>"`, err)
+    }
+
+    commitErr := errors.New("commit failed")
+    transactor := &mockTransactor{
+        runInTxFunc: func(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error {
+            return queries.RunInTx(
+                ctx, options, func(queries db.Querier) error {
+                    if err := fn(queries); err != nil {
+                        return err
+                    }
+                    return commitErr
+                },
+            )
+        },
+    }
+    service := ServiceImpl{
+        Queries:    queries,
+        Transactor: transactor,
+    }
+
+    username := "renamed"
+    request := dto.UpdateUserRequest{
+        UserId:   int(user.ID),
+        Username: &username,
+    }
+    if _, err := service.UpdateUser(context.Background(), &request); !errors.Is(err, commitErr) {
+        t.Errorf(`service.UpdateUser(ctx, request) error = "%v", expected "%v"`, err, commitErr)
+    }
+
+    fetched, err := queries.GetUser(
+        context.Background(), db.GetUserParams{
+            ID: sql.NullInt32{Int32: user.ID, Valid: true},
+        },
+    )
+    if err != nil {
+        t.Fatalf(`queries.GetUser(...) error = "%v", expected "<nil>"`, err)
+    }
+    if fetched.Username != ValidUsername {
+        t.Errorf(`fetched.Username = "%s", expected "%s"`, fetched.Username, ValidUsername)
+    }
+    if outbox := queries.Outbox(); len(outbox) != 0 {
+        t.Errorf(`outbox = "%+v", expected the event to be rolled back`, outbox)
+    }
+}
+
+type mockTransactor struct {
+    runInTxFunc func(ctx context.Context, options *sql.TxOptions, fn func(queries db.Querier) error) error
+}
+
+func (transactor *mockTransactor) RunInTx(
+    ctx context.Context,
+    options *sql.TxOptions,
+    fn func(queries db.Querier) error,
+) error {
+    return transactor.runInTxFunc(ctx, options, fn)
+}
+
 type mockQuerier struct {
-    countUsersFunc func(ctx context.Context) (int64, error)
-    createUserFunc func(ctx context.Context, arg db.CreateUserParams) (db.User, error)
-    deleteUserFunc func(ctx context.Context, id int32) error
-    getUserFunc    func(ctx context.Context, arg db.GetUserParams) (db.User, error)
-    getUsersFunc   func(ctx context.Context, arg db.GetUsersParams) ([]db.User, error)
-    updateUserFunc func(ctx context.Context, arg db.UpdateUserParams) (db.User, error)
+    countUsersFunc      func(ctx context.Context) (int64, error)
+    createUserFunc      func(ctx context.Context, arg db.CreateUserParams) (db.User, error)
+    deleteUserFunc      func(ctx context.Context, id int32) error
+    getUserFunc         func(ctx context.Context, arg db.GetUserParams) (db.User, error)
+    getUsersFunc        func(ctx context.Context, arg db.GetUsersParams) ([]db.User, error)
+    updateUserFunc      func(ctx context.Context, arg db.UpdateUserParams) (db.User, error)
+    setUserVerifiedFunc func(ctx context.Context, arg db.SetUserVerifiedParams) (db.User, error)
+    restoreUserFunc     func(ctx context.Context, usersID int32) (db.User, error)
+    // insertOutboxEventFunc Optional; events are discarded when it is nil
+    insertOutboxEventFunc func(ctx context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error)
+
+    setUserPendingEmailFunc          func(ctx context.Context, arg db.SetUserPendingEmailParams) (db.User, error)
+    setUserEmailFunc                 func(ctx context.Context, arg db.SetUserEmailParams) (db.User, error)
+    createEmailChangeFunc            func(ctx context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error)
+    getEmailChangeByConfirmTokenFunc func(ctx context.Context, confirmTokenHash string) (db.EmailChange, error)
+    getEmailChangeByUndoTokenFunc    func(ctx context.Context, undoTokenHash string) (db.EmailChange, error)
+    cancelPendingEmailChangesFunc    func(ctx context.Context, userID int32) error
+    markEmailChangeConfirmedFunc     func(ctx context.Context, id int64) error
+    markEmailChangeUndoneFunc        func(ctx context.Context, id int64) error
+
+    scheduleUserDeletionFunc   func(ctx context.Context, arg db.ScheduleUserDeletionParams) (db.User, error)
+    cancelUserDeletionFunc     func(ctx context.Context, id int32) (db.User, error)
+    getUserByDeletionTokenFunc func(ctx context.Context, deletionTokenHash sql.NullString) (db.User, error)
+    getUsersDueForDeletionFunc func(ctx context.Context, batchSize int32) ([]db.User, error)
+    deleteUserIfDueFunc        func(ctx context.Context, id int32) (int64, error)
+    anonymizeArchivedUsersFunc func(ctx context.Context, retentionSeconds float64) (int64, error)
 }
 
 func (q *mockQuerier) CountUsers(ctx context.Context) (int64, error) {
@@ -646,6 +944,152 @@ func (q *mockQuerier) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (
     return q.updateUserFunc(ctx, arg)
 }
 
+func (q *mockQuerier) SetUserVerified(ctx context.Context, arg db.SetUserVerifiedParams) (db.User, error) {
+    return q.setUserVerifiedFunc(ctx, arg)
+}
+
+func (q *mockQuerier) RestoreUser(ctx context.Context, usersID int32) (db.User, error) {
+    return q.restoreUserFunc(ctx, usersID)
+}
+
+func (q *mockQuerier) SetUserPendingEmail(ctx context.Context, arg db.SetUserPendingEmailParams) (db.User, error) {
+    return q.setUserPendingEmailFunc(ctx, arg)
+}
+
+func (q *mockQuerier) SetUserEmail(ctx context.Context, arg db.SetUserEmailParams) (db.User, error) {
+    return q.setUserEmailFunc(ctx, arg)
+}
+
+func (q *mockQuerier) CreateEmailChange(ctx context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error) {
+    return q.createEmailChangeFunc(ctx, arg)
+}
+
+func (q *mockQuerier) GetEmailChangeByConfirmToken(ctx context.Context, confirmTokenHash string) (db.EmailChange, error) {
+    return q.getEmailChangeByConfirmTokenFunc(ctx, confirmTokenHash)
+}
+
+func (q *mockQuerier) GetEmailChangeByUndoToken(ctx context.Context, undoTokenHash string) (db.EmailChange, error) {
+    return q.getEmailChangeByUndoTokenFunc(ctx, undoTokenHash)
+}
+
+func (q *mockQuerier) CancelPendingEmailChanges(ctx context.Context, userID int32) error {
+    return q.cancelPendingEmailChangesFunc(ctx, userID)
+}
+
+func (q *mockQuerier) MarkEmailChangeConfirmed(ctx context.Context, id int64) error {
+    return q.markEmailChangeConfirmedFunc(ctx, id)
+}
+
+func (q *mockQuerier) MarkEmailChangeUndone(ctx context.Context, id int64) error {
+    return q.markEmailChangeUndoneFunc(ctx, id)
+}
+
+func (q *mockQuerier) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (db.User, error) {
+    return q.scheduleUserDeletionFunc(ctx, arg)
+}
+
+func (q *mockQuerier) CancelUserDeletion(ctx context.Context, id int32) (db.User, error) {
+    return q.cancelUserDeletionFunc(ctx, id)
+}
+
+func (q *mockQuerier) GetUserByDeletionToken(ctx context.Context, deletionTokenHash sql.NullString) (db.User, error) {
+    return q.getUserByDeletionTokenFunc(ctx, deletionTokenHash)
+}
+
+func (q *mockQuerier) GetUsersDueForDeletion(ctx context.Context, batchSize int32) ([]db.User, error) {
+    return q.getUsersDueForDeletionFunc(ctx, batchSize)
+}
+
+func (q *mockQuerier) DeleteUserIfDue(ctx context.Context, id int32) (int64, error) {
+    return q.deleteUserIfDueFunc(ctx, id)
+}
+
+func (q *mockQuerier) AnonymizeArchivedUsers(ctx context.Context, retentionSeconds float64) (int64, error) {
+    return q.anonymizeArchivedUsersFunc(ctx, retentionSeconds)
+}
+
+func (q *mockQuerier) InsertOutboxEvent(ctx context.Context, arg db.InsertOutboxEventParams) (db.UserOutbox, error) {
+    if q.insertOutboxEventFunc == nil {
+        return db.UserOutbox{}, nil
+    }
+    return q.insertOutboxEventFunc(ctx, arg)
+}
+
+func (q *mockQuerier) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.UserOutbox, error) {
+    return nil, nil
+}
+
+func (q *mockQuerier) MarkOutboxEventDelivered(ctx context.Context, id int64) error {
+    return nil
+}
+
+func (q *mockQuerier) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+    return nil
+}
+
+func (q *mockQuerier) DeleteDeliveredOutboxEvents(ctx context.Context, retentionSeconds float64) (int64, error) {
+    return 0, nil
+}
+
+func (q *mockQuerier) GetOutboxEventsByUser(ctx context.Context, userID int32) ([]db.UserOutbox, error) {
+    return nil, nil
+}
+
+func (q *mockQuerier) GetUserArchive(ctx context.Context, usersID int32) ([]db.UsersArchive, error) {
+    return nil, nil
+}
+
+func (q *mockQuerier) GetEmailChangesByUser(ctx context.Context, userID int32) ([]db.EmailChange, error) {
+    return nil, nil
+}
+
+func (q *mockQuerier) CreateDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+    return db.DataExport{}, nil
+}
+
+func (q *mockQuerier) GetDataExport(ctx context.Context, arg db.GetDataExportParams) (db.DataExport, error) {
+    return db.DataExport{}, sql.ErrNoRows
+}
+
+func (q *mockQuerier) GetLatestDataExport(ctx context.Context, userID int32) (db.DataExport, error) {
+    return db.DataExport{}, sql.ErrNoRows
+}
+
+func (q *mockQuerier) GetDataExportByDownloadToken(
+    ctx context.Context,
+    downloadTokenHash sql.NullString,
+) (db.DataExport, error) {
+    return db.DataExport{}, sql.ErrNoRows
+}
+
+func (q *mockQuerier) ClaimDataExport(ctx context.Context, leaseSeconds float64) (db.DataExport, error) {
+    return db.DataExport{}, sql.ErrNoRows
+}
+
+func (q *mockQuerier) InsertDataExportArchive(ctx context.Context, arg db.InsertDataExportArchiveParams) error {
+    return nil
+}
+
+func (q *mockQuerier) GetDataExportArchive(ctx context.Context, dataExportID int64) ([]byte, error) {
+    return nil, sql.ErrNoRows
+}
+
+func (q *mockQuerier) MarkDataExportReady(ctx context.Context, arg db.MarkDataExportReadyParams) error {
+    return nil
+}
+
+func (q *mockQuerier) MarkDataExportFailed(ctx context.Context, arg db.MarkDataExportFailedParams) error {
+    return nil
+}
+
+func (q *mockQuerier) DeleteExpiredDataExportArchives(ctx context.Context) (int64, error) {
+    return 0, nil
+}
+
+func (q *mockQuerier) DeleteUserDataExportArchives(ctx context.Context, userID int32) error {
+    return nil
+}
+
 func assertUserEqualToDB(t *testing.T, actual *dto.User, expected *db.User) {
     if actual.UserId != int(expected.ID) {
         t.Errorf(`actual.UserId = "%d", expected "%d"`, actual.UserId, expected.ID)
@@ -0,0 +1,340 @@
+package user
+
+import (
+	"common"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+	"user/db/generated"
+	"user/db/sqlite"
+	"user/dto"
+)
+
+func newSQLiteService(t *testing.T) (*ServiceImpl, *sql.DB) {
+	config := &common.DatabaseConfig{
+		Driver:         common.DriverSQLite,
+		Url:            ":memory:",
+		ConnectTimeout: time.Second,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+
+	database, err := common.OpenDatabase(context.Background(), config)
+	if err != nil {
+		t.Fatalf(`common.OpenDatabase(...) error = "%v", expected "<nil>"`, err)
+	}
+	t.Cleanup(
+		func() {
+			_ = database.Close()
+		},
+	)
+
+	err = common.RunMigrations(
+		context.Background(),
+		database,
+		common.DriverSQLite,
+		Migrations(common.DriverSQLite),
+		common.MigrateUp,
+		io.Discard,
+	)
+	if err != nil {
+		t.Fatalf(`common.RunMigrations(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	service := &ServiceImpl{
+		Queries: sqlite.New(database),
+		Transactor: &SQLTransactor{
+			Database: database,
+			NewQuerier: func(tx db.DBTX) db.Querier {
+				return sqlite.New(tx)
+			},
+			MaxRetries: common.DefaultTxMaxRetries,
+		},
+	}
+	return service, database
+}
+
+func TestSQLite_UserLifecycle(t *testing.T) {
+	service, database := newSQLiteService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateUser(
+		ctx, &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	fetched, err := service.GetUser(ctx, &dto.GetUserRequest{Email: &[]string{ValidEmail}[0]})
+	if err != nil {
+		t.Fatalf(`service.GetUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if fetched.UserId != created.UserId || fetched.IsVerified || fetched.CreatedAt.IsZero() {
+		t.Errorf(`fetched = "%+v", expected unverified user "%d" with createdAt set`, fetched, created.UserId)
+	}
+
+	renamed := "renamed"
+	updated, err := service.UpdateUser(ctx, &dto.UpdateUserRequest{UserId: created.UserId, Username: &renamed})
+	if err != nil {
+		t.Fatalf(`service.UpdateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if updated.Username != renamed || updated.Email != ValidEmail || updated.UpdatedAt.Before(fetched.UpdatedAt) {
+		t.Errorf(`updated = "%+v", expected username "%s" and an updatedAt no older than createdAt`, updated, renamed)
+	}
+
+	verified, err := service.VerifyUser(ctx, &dto.VerifyUserRequest{UserId: created.UserId, IsVerified: true})
+	if err != nil || !verified.IsVerified {
+		t.Errorf(`service.VerifyUser(...) = "%+v, %v", expected verified user`, verified, err)
+	}
+
+	if _, err := service.DeleteUser(ctx, &dto.DeleteUserRequest{UserId: created.UserId}); err != nil {
+		t.Fatalf(`service.DeleteUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	var archivedUsername string
+	row := database.QueryRow("SELECT username FROM users_archive WHERE users_id = ?", created.UserId)
+	if err := row.Scan(&archivedUsername); err != nil || archivedUsername != renamed {
+		t.Errorf(`archived username = "%s, %v", expected "%s"`, archivedUsername, err, renamed)
+	}
+
+	if _, err := database.Exec("UPDATE users_archive SET username = 'changed'"); err == nil {
+		t.Error(`UPDATE users_archive error = "<nil>", expected non-nil`)
+	}
+
+	restored, err := service.RestoreUser(ctx, &dto.RestoreUserRequest{UserId: created.UserId})
+	if err != nil {
+		t.Fatalf(`service.RestoreUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if restored.UserId != created.UserId || restored.Username != renamed || !restored.IsVerified {
+		t.Errorf(`restored = "%+v", expected verified user "%d" named "%s"`, restored, created.UserId, renamed)
+	}
+}
+
+func TestSQLite_UniqueConstraints(t *testing.T) {
+	service, _ := newSQLiteService(t)
+	ctx := context.Background()
+
+	request := dto.CreateUserRequest{Username: ValidUsername, Email: ValidEmail, Password: ValidPassword}
+	if _, err := service.CreateUser(ctx, &request); err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	request.Email = "other@email.com"
+	if _, err := service.CreateUser(ctx, &request); err == nil {
+		t.Error(`service.CreateUser(duplicate username) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestSQLite_GetUsers_Pagination(t *testing.T) {
+	t.Setenv(BASE_URL_KEY, MockUrl)
+	service, _ := newSQLiteService(t)
+	queries := service.Queries
+
+	for _, username := range []string{"a-user", "b-user", "c-user"} {
+		_, err := queries.CreateUser(
+			context.Background(), db.CreateUserParams{
+				Username:     username,
+				Email:        username + "@email.com",
+				PasswordHash: "hash",
+			},
+		)
+		if err != nil {
+			t.Fatalf(`queries.CreateUser(...) error = "%v", expected "<nil>"`, err)
+		}
+	}
+
+	limit, offset := 2, 1
+	response, err := service.GetUsers(context.Background(), &dto.GetUsersRequest{Limit: &limit, Offset: &offset})
+	if err != nil {
+		t.Fatalf(`service.GetUsers(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	if len(response.Users) != 2 || response.Users[0].Username != "b-user" {
+		t.Errorf(`response.Users = "%+v", expected "b-user" and "c-user"`, response.Users)
+	}
+	if response.NextLink != nil {
+		t.Errorf(`response.NextLink = "%s", expected "<nil>"`, *response.NextLink)
+	}
+}
+
+func TestSQLite_OutboxRelay(t *testing.T) {
+	service, _ := newSQLiteService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateUser(
+		ctx, &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+	if _, err := service.DeleteUser(ctx, &dto.DeleteUserRequest{UserId: created.UserId}); err != nil {
+		t.Fatalf(`service.DeleteUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	publisher := common.NewMemoryPublisher()
+	relay := newTestOutboxRelay(service.Queries, &common.PublisherSink{Publisher: publisher})
+
+	relayed, err := relay.RelayBatch(ctx)
+	if err != nil || relayed != 2 {
+		t.Fatalf(`relay.RelayBatch(ctx) = "%d, %v", expected "2, <nil>"`, relayed, err)
+	}
+	messages := publisher.Messages()
+	if len(messages) != 2 || messages[0].Subject != EventUserCreated || messages[1].Subject != EventUserDeleted {
+		t.Errorf(`messages = "%+v", expected "%s" then "%s"`, messages, EventUserCreated, EventUserDeleted)
+	}
+
+	if relayed, err := relay.RelayBatch(ctx); err != nil || relayed != 0 {
+		t.Errorf(`relay.RelayBatch(ctx) = "%d, %v", expected "0, <nil>"`, relayed, err)
+	}
+	if deleted, err := service.Queries.DeleteDeliveredOutboxEvents(ctx, -1); err != nil || deleted != 2 {
+		t.Errorf(`DeleteDeliveredOutboxEvents(ctx, -1) = "%d, %v", expected "2, <nil>"`, deleted, err)
+	}
+}
+
+func TestSQLite_EmailChange(t *testing.T) {
+	service, _ := newSQLiteService(t)
+	mailer := &common.MemoryMailer{}
+	service.Mailer = mailer
+	service.AppUrl = "https://quizchief.gg"
+
+	created, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	confirmToken, undoToken := requestTestEmailChange(t, service, mailer, created.UserId, newEmail)
+
+	confirmed, err := service.ConfirmEmailChange(context.Background(), &dto.ConfirmEmailChangeRequest{Token: confirmToken})
+	if err != nil {
+		t.Fatalf(`service.ConfirmEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	if confirmed.Email != newEmail || !confirmed.IsVerified || confirmed.PendingEmail != nil {
+		t.Errorf(`confirmed = "%+v", expected verified email "%s" with nothing pending`, confirmed, newEmail)
+	}
+
+	undone, err := service.UndoEmailChange(context.Background(), &dto.UndoEmailChangeRequest{Token: undoToken})
+	if err != nil {
+		t.Fatalf(`service.UndoEmailChange(...) error = "%v", expected "<nil>"`, err)
+	}
+	if undone.Email != ValidEmail || undone.IsVerified {
+		t.Errorf(`undone = "%+v", expected unverified email "%s"`, undone, ValidEmail)
+	}
+}
+
+func TestSQLite_DeletionPurge(t *testing.T) {
+	service, _ := newSQLiteService(t)
+	service.DeletionGracePeriod = time.Millisecond
+
+	created, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	scheduled, err := service.ScheduleUserDeletion(
+		context.Background(),
+		&dto.ScheduleUserDeletionRequest{UserId: created.UserId},
+	)
+	if err != nil {
+		t.Fatalf(`service.ScheduleUserDeletion(...) error = "%v", expected "<nil>"`, err)
+	}
+	if scheduled.DeletionScheduledAt == nil {
+		t.Fatal(`scheduled.DeletionScheduledAt = "<nil>", expected non-nil`)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	purger := newTestDeletionPurger(service)
+	if deleted, err := purger.PurgeBatch(context.Background()); err != nil || deleted != 1 {
+		t.Fatalf(`purger.PurgeBatch(ctx) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	anonymized, err := service.Queries.AnonymizeArchivedUsers(context.Background(), 0)
+	if err != nil || anonymized != 1 {
+		t.Fatalf(`AnonymizeArchivedUsers(ctx, 0) = "%d", "%v", expected "1", "<nil>"`, anonymized, err)
+	}
+	if anonymized, _ := service.Queries.AnonymizeArchivedUsers(context.Background(), 0); anonymized != 0 {
+		t.Errorf(`AnonymizeArchivedUsers(ctx, 0) = "%d" on the second run, expected "0"`, anonymized)
+	}
+
+	_, err = service.RestoreUser(context.Background(), &dto.RestoreUserRequest{UserId: created.UserId})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf(`service.RestoreUser(...) error = "%v", expected "%v"`, err, sql.ErrNoRows)
+	}
+}
+
+func TestSQLite_DataExport(t *testing.T) {
+	service, _ := newSQLiteService(t)
+	mailer := &common.MemoryMailer{}
+	service.Mailer = mailer
+	service.AppUrl = "https://quizchief.gg"
+
+	created, err := service.CreateUser(
+		context.Background(), &dto.CreateUserRequest{
+			Username: ValidUsername,
+			Email:    ValidEmail,
+			Password: ValidPassword,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`service.CreateUser(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	exporter := newTestDataExporter(service)
+	export, token := buildTestDataExport(t, service, mailer, exporter, created.UserId)
+
+	download, err := service.DownloadDataExport(context.Background(), &dto.DownloadDataExportRequest{Token: token})
+	if err != nil {
+		t.Fatalf(`service.DownloadDataExport(...) error = "%v", expected "<nil>"`, err)
+	}
+	var account dto.DataExportAccount
+	readArchiveFile(t, download.Archive, "account.json", &account)
+	if account.UserId != created.UserId || account.Email != ValidEmail {
+		t.Errorf(`account = "%+v", expected user "%d" with email "%s"`, account, created.UserId, ValidEmail)
+	}
+
+	if processed, err := exporter.ProcessNext(context.Background()); processed || err != nil {
+		t.Errorf(`exporter.ProcessNext(ctx) = "%v", "%v", expected "false", "<nil>"`, processed, err)
+	}
+
+	err = service.Queries.MarkDataExportReady(
+		context.Background(), db.MarkDataExportReadyParams{
+			DownloadTokenHash: hashLinkToken(token),
+			TtlSeconds:        0,
+			ID:                export.ExportId,
+		},
+	)
+	if err != nil {
+		t.Fatalf(`MarkDataExportReady(...) error = "%v", expected "<nil>"`, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if deleted, err := service.Queries.DeleteExpiredDataExportArchives(context.Background()); err != nil || deleted != 1 {
+		t.Errorf(`DeleteExpiredDataExportArchives(ctx) = "%d", "%v", expected "1", "<nil>"`, deleted, err)
+	}
+	_, err = service.DownloadDataExport(context.Background(), &dto.DownloadDataExportRequest{Token: token})
+	assertHTTPError(t, err, http.StatusBadRequest)
+}
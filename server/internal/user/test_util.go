@@ -15,11 +15,36 @@ const (
 )
 
 type mockService struct {
-	createUserFunc func(context context.Context, request *dto.CreateUserRequest) (*dto.CreateUserResponse, error)
-	getUserFunc    func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error)
-	getUsersFunc   func(context context.Context, request *dto.GetUsersRequest) (*dto.GetUsersResponse, error)
-	updateUserFunc func(context context.Context, request *dto.UpdateUserRequest) (*dto.UpdateUserResponse, error)
-	deleteUserFunc func(context context.Context, request *dto.DeleteUserRequest) (*dto.DeleteUserResponse, error)
+	createUserFunc  func(context context.Context, request *dto.CreateUserRequest) (*dto.CreateUserResponse, error)
+	getUserFunc     func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error)
+	getUsersFunc    func(context context.Context, request *dto.GetUsersRequest) (*dto.GetUsersResponse, error)
+	updateUserFunc  func(context context.Context, request *dto.UpdateUserRequest) (*dto.UpdateUserResponse, error)
+	deleteUserFunc  func(context context.Context, request *dto.DeleteUserRequest) (*dto.DeleteUserResponse, error)
+	verifyUserFunc  func(context context.Context, request *dto.VerifyUserRequest) (*dto.VerifyUserResponse, error)
+	restoreUserFunc func(context context.Context, request *dto.RestoreUserRequest) (*dto.RestoreUserResponse, error)
+
+	confirmEmailChangeFunc func(
+		context context.Context,
+		request *dto.ConfirmEmailChangeRequest,
+	) (*dto.ConfirmEmailChangeResponse, error)
+	undoEmailChangeFunc      func(context context.Context, request *dto.UndoEmailChangeRequest) (*dto.UndoEmailChangeResponse, error)
+	scheduleUserDeletionFunc func(
+		context context.Context,
+		request *dto.ScheduleUserDeletionRequest,
+	) (*dto.ScheduleUserDeletionResponse, error)
+	cancelUserDeletionFunc func(
+		context context.Context,
+		request *dto.CancelUserDeletionRequest,
+	) (*dto.CancelUserDeletionResponse, error)
+	requestDataExportFunc func(
+		context context.Context,
+		request *dto.RequestDataExportRequest,
+	) (*dto.RequestDataExportResponse, error)
+	getDataExportFunc      func(context context.Context, request *dto.GetDataExportRequest) (*dto.GetDataExportResponse, error)
+	downloadDataExportFunc func(
+		context context.Context,
+		request *dto.DownloadDataExportRequest,
+	) (*dto.DownloadDataExportResponse, error)
 }
 
 func (m *mockService) CreateUser(context context.Context, request *dto.CreateUserRequest) (
@@ -51,6 +76,69 @@ func (m *mockService) DeleteUser(context context.Context, request *dto.DeleteUse
 	return m.deleteUserFunc(context, request)
 }
 
+func (m *mockService) VerifyUser(context context.Context, request *dto.VerifyUserRequest) (
+	*dto.VerifyUserResponse,
+	error,
+) {
+	return m.verifyUserFunc(context, request)
+}
+
+func (m *mockService) RestoreUser(context context.Context, request *dto.RestoreUserRequest) (
+	*dto.RestoreUserResponse,
+	error,
+) {
+	return m.restoreUserFunc(context, request)
+}
+
+func (m *mockService) ConfirmEmailChange(context context.Context, request *dto.ConfirmEmailChangeRequest) (
+	*dto.ConfirmEmailChangeResponse,
+	error,
+) {
+	return m.confirmEmailChangeFunc(context, request)
+}
+
+func (m *mockService) UndoEmailChange(context context.Context, request *dto.UndoEmailChangeRequest) (
+	*dto.UndoEmailChangeResponse,
+	error,
+) {
+	return m.undoEmailChangeFunc(context, request)
+}
+
+func (m *mockService) ScheduleUserDeletion(context context.Context, request *dto.ScheduleUserDeletionRequest) (
+	*dto.ScheduleUserDeletionResponse,
+	error,
+) {
+	return m.scheduleUserDeletionFunc(context, request)
+}
+
+func (m *mockService) CancelUserDeletion(context context.Context, request *dto.CancelUserDeletionRequest) (
+	*dto.CancelUserDeletionResponse,
+	error,
+) {
+	return m.cancelUserDeletionFunc(context, request)
+}
+
+func (m *mockService) RequestDataExport(context context.Context, request *dto.RequestDataExportRequest) (
+	*dto.RequestDataExportResponse,
+	error,
+) {
+	return m.requestDataExportFunc(context, request)
+}
+
+func (m *mockService) GetDataExport(context context.Context, request *dto.GetDataExportRequest) (
+	*dto.GetDataExportResponse,
+	error,
+) {
+	return m.getDataExportFunc(context, request)
+}
+
+func (m *mockService) DownloadDataExport(context context.Context, request *dto.DownloadDataExportRequest) (
+	*dto.DownloadDataExportResponse,
+	error,
+) {
+	return m.downloadDataExportFunc(context, request)
+}
+
 func assertHTTPError(t *testing.T, err error, statusCode int) {
 	var httpErr *common.HTTPError
 	if ok := errors.As(err, &httpErr); !ok {
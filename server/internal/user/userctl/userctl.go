@@ -0,0 +1,478 @@
+// Package userctl contains the implementation of the userctl admin CLI for managing users
+package userctl
+
+import (
+	"bufio"
+	"common"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"user"
+	"user/dto"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+const usage = `Usage: userctl [-o table|json] <command> [flags]
+
+Commands:
+  create          Create a user, reading the password from stdin
+  get             Look up a user by id, username, or email
+  list            List users (paginated)
+  reset-password  Set a new password for a user, read from stdin
+  verify          Mark a user as verified (or unverified with -unverify)
+  delete          Delete a user (the row is kept in users_archive)
+  restore         Restore a deleted user from users_archive
+  export          Write all users (without password hashes) to stdout as CSV or JSONL
+  import          Create users from a CSV or JSONL file (use -dry-run to only validate)
+`
+
+// command A userctl subcommand. Passwords are read from stdin rather than flags, so they stay out of argv and shell
+// history
+type command func(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error)
+
+// streamResult Command result written directly to stdout instead of being formatted with -o
+type streamResult func(writer io.Writer) error
+
+var commands = map[string]command{
+	"create":         createCommand,
+	"get":            getCommand,
+	"list":           listCommand,
+	"reset-password": resetPasswordCommand,
+	"verify":         verifyCommand,
+	"delete":         deleteCommand,
+	"restore":        restoreCommand,
+	"export":         exportCommand,
+	"import":         importCommand,
+}
+
+// Run Parse the arguments, run the matching command against the service and write the result to stdout
+func Run(
+	ctx context.Context,
+	args []string,
+	service user.Service,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	flags := flag.NewFlagSet("userctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+	}
+	output := flags.String("o", OutputTable, "output format (table or json)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *output != OutputTable && *output != OutputJSON {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no command specified")
+	}
+
+	run, ok := commands[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+
+	result, err := run(ctx, service, flags.Args()[1:], stdin)
+	if err != nil {
+		return err
+	}
+
+	if stream, ok := result.(streamResult); ok {
+		return stream(stdout)
+	}
+
+	if *output == OutputJSON {
+		return writeJSON(stdout, result)
+	}
+	return writeTable(stdout, result)
+}
+
+// createCommand Create a user after running it through the same validation as the API
+func createCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the new user")
+	email := flags.String("email", "", "email of the new user")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	password, err := readPassword(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	request := dto.CreateUserRequest{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+	}
+	if err := user.ValidateCreateUserRequest(&request, service, ctx); err != nil {
+		return nil, err
+	}
+
+	response, err := service.CreateUser(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	return getUser(ctx, service, response.UserId)
+}
+
+// getCommand Look up a user by id, username, or email
+func getCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	userId := flags.Int("id", -1, "id of the user")
+	username := flags.String("username", "", "username of the user")
+	email := flags.String("email", "", "email of the user")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var request dto.GetUserRequest
+	if *userId >= 0 {
+		request.UserId = userId
+	}
+	if *username != "" {
+		request.Username = username
+	}
+	if *email != "" {
+		request.Email = email
+	}
+
+	if err := user.ValidateGetUserRequest(&request); err != nil {
+		return nil, err
+	}
+
+	return service.GetUser(ctx, &request)
+}
+
+// listCommand List users with the same pagination and sort options as GET /user/all
+func listCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	limit := flags.Int("limit", user.DefaultUsersPageLimit, "maximum number of users to return")
+	offset := flags.Int("offset", 0, "number of users to skip")
+	sortField := flags.String("sort-field", "", "field to sort by")
+	sortOrder := flags.String("sort-order", "", "sort direction (asc or desc)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	request := dto.GetUsersRequest{
+		Limit:  limit,
+		Offset: offset,
+	}
+	if *sortField != "" {
+		request.SortField = sortField
+	}
+	if *sortOrder != "" {
+		request.SortDirection = sortOrder
+	}
+
+	if err := user.ValidateGetUsersRequest(&request); err != nil {
+		return nil, err
+	}
+
+	return service.GetUsers(ctx, &request)
+}
+
+// resetPasswordCommand Set a new password for a user
+func resetPasswordCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	userId := flags.Int("id", -1, "id of the user")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	password, err := readPassword(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	request := dto.UpdateUserRequest{
+		UserId:   *userId,
+		Password: &password,
+	}
+	if err := user.ValidateUpdateUserRequest(&request, service, ctx); err != nil {
+		return nil, err
+	}
+
+	return service.UpdateUser(ctx, &request)
+}
+
+// verifyCommand Mark a user as verified or unverified
+func verifyCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	userId := flags.Int("id", -1, "id of the user")
+	unverify := flags.Bool("unverify", false, "mark the user as unverified instead")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	request := dto.VerifyUserRequest{
+		UserId:     *userId,
+		IsVerified: !*unverify,
+	}
+	if err := user.ValidateVerifyUserRequest(&request, service, ctx); err != nil {
+		return nil, err
+	}
+
+	return service.VerifyUser(ctx, &request)
+}
+
+// deleteCommand Delete a user
+func deleteCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("delete", flag.ContinueOnError)
+	userId := flags.Int("id", -1, "id of the user")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	request := dto.DeleteUserRequest{UserId: *userId}
+	if err := user.ValidateDeleteUserRequest(&request, service, ctx); err != nil {
+		return nil, err
+	}
+
+	deleted, err := getUser(ctx, service, *userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := service.DeleteUser(ctx, &request); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// restoreCommand Restore a deleted user from users_archive
+func restoreCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	userId := flags.Int("id", -1, "id of the deleted user")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	request := dto.RestoreUserRequest{UserId: *userId}
+	if err := user.ValidateRestoreUserRequest(&request, service, ctx); err != nil {
+		return nil, err
+	}
+
+	return service.RestoreUser(ctx, &request)
+}
+
+// exportCommand Write all users to stdout in the requested format
+func exportCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", user.FormatJSONL, "file format (csv or jsonl)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	request := dto.ExportUsersRequest{Format: *format}
+	if err := user.ValidateExportUsersRequest(&request); err != nil {
+		return nil, err
+	}
+
+	return streamResult(
+		func(writer io.Writer) error {
+			return user.ExportUsers(ctx, service, &request, writer)
+		},
+	), nil
+}
+
+// importCommand Create users from a CSV or JSONL file and report the rows that failed
+func importCommand(ctx context.Context, service user.Service, args []string, stdin io.Reader) (any, error) {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "path of the file to import")
+	format := flags.String("format", "", "file format (csv or jsonl); defaults to the file extension")
+	dryRun := flags.Bool("dry-run", false, "validate the rows without creating any users")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *file == "" {
+		return nil, errors.New("-file is required")
+	}
+
+	request := dto.ImportUsersRequest{
+		Format: *format,
+		DryRun: *dryRun,
+	}
+	if request.Format == "" {
+		request.Format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+	if err := user.ValidateImportUsersRequest(&request); err != nil {
+		return nil, err
+	}
+
+	reader, err := os.Open(*file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return user.ImportUsers(ctx, service, &request, reader)
+}
+
+// getUser Retrieve a user by id
+func getUser(ctx context.Context, service user.Service, userId int) (*dto.User, error) {
+	response, err := service.GetUser(ctx, &dto.GetUserRequest{UserId: &userId})
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, &common.HTTPError{StatusCode: http.StatusNotFound, Message: "user not found"}
+	}
+	return response, nil
+}
+
+// readPassword Read a password from the first line of stdin
+func readPassword(stdin io.Reader) (string, error) {
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("a password is required on stdin")
+	}
+	return password, nil
+}
+
+// printedUser User as userctl prints it, without the password hash
+type printedUser struct {
+	dto.ExportedUser
+	PendingEmail        *string    `json:"pendingEmail,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+}
+
+// printedUsers Page of users as userctl prints it, without password hashes
+type printedUsers struct {
+	Users    []printedUser `json:"users"`
+	PrevLink *string       `json:"prevLink,omitempty"`
+	NextLink *string       `json:"nextLink,omitempty"`
+}
+
+// toPrintedUser Convert a user to the form userctl prints it in
+func toPrintedUser(u *dto.User) printedUser {
+	return printedUser{
+		ExportedUser: dto.ExportedUser{
+			UserId:     u.UserId,
+			Username:   u.Username,
+			Email:      u.Email,
+			IsVerified: u.IsVerified,
+			CreatedAt:  u.CreatedAt,
+			UpdatedAt:  u.UpdatedAt,
+		},
+		PendingEmail:        u.PendingEmail,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
+
+// writeJSON Write the result as indented JSON, omitting password hashes
+func writeJSON(writer io.Writer, result any) error {
+	switch value := result.(type) {
+	case *dto.User:
+		result = toPrintedUser(value)
+	case *dto.GetUsersResponse:
+		users := make([]printedUser, 0, len(value.Users))
+		for i := range value.Users {
+			users = append(users, toPrintedUser(&value.Users[i]))
+		}
+		result = printedUsers{Users: users, PrevLink: value.PrevLink, NextLink: value.NextLink}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// writeTable Write the result as an aligned table, omitting password hashes
+func writeTable(writer io.Writer, result any) error {
+	var users []dto.User
+	var response *dto.GetUsersResponse
+
+	switch value := result.(type) {
+	case *dto.ImportUsersResponse:
+		return writeImportReport(writer, value)
+	case *dto.User:
+		users = []dto.User{*value}
+	case *dto.GetUsersResponse:
+		response = value
+		users = value.Users
+	default:
+		return fmt.Errorf("unsupported result type %T", result)
+	}
+
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "ID\tUSERNAME\tEMAIL\tVERIFIED\tCREATED\tUPDATED")
+	for _, u := range users {
+		_, _ = fmt.Fprintf(
+			table,
+			"%d\t%s\t%s\t%s\t%s\t%s\n",
+			u.UserId,
+			u.Username,
+			u.Email,
+			strconv.FormatBool(u.IsVerified),
+			u.CreatedAt.Format(time.RFC3339),
+			u.UpdatedAt.Format(time.RFC3339),
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if response != nil {
+		if response.PrevLink != nil {
+			_, _ = fmt.Fprintf(writer, "\nprevious: %s\n", *response.PrevLink)
+		}
+		if response.NextLink != nil {
+			_, _ = fmt.Fprintf(writer, "next: %s\n", *response.NextLink)
+		}
+	}
+
+	return nil
+}
+
+// writeImportReport Write an import summary followed by a table of the rows that failed
+func writeImportReport(writer io.Writer, response *dto.ImportUsersResponse) error {
+	summary := "imported"
+	if response.DryRun {
+		summary = "valid (dry run)"
+	}
+	_, _ = fmt.Fprintf(writer, "%d rows, %d %s, %d failed\n", response.Total, response.Imported, summary, response.Failed)
+
+	if len(response.Errors) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(writer)
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "LINE\tUSERNAME\tERROR")
+	for _, rowError := range response.Errors {
+		_, _ = fmt.Fprintf(table, "%d\t%s\t%s\n", rowError.Line, rowError.Username, rowError.Message)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,418 @@
+package userctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"user/dto"
+)
+
+const (
+	ValidUsername = "test-username"
+	ValidEmail    = "test@email.com"
+	ValidPassword = "testPassword1234#?!@$%^&*-"
+)
+
+// stubService Service storing users in a map, with deleted users kept for restore
+type stubService struct {
+	users   map[int]dto.User
+	deleted map[int]dto.User
+	nextId  int
+}
+
+func newStubService(users ...dto.User) *stubService {
+	service := &stubService{users: map[int]dto.User{}, deleted: map[int]dto.User{}, nextId: 1}
+	for _, u := range users {
+		service.users[u.UserId] = u
+		if u.UserId >= service.nextId {
+			service.nextId = u.UserId + 1
+		}
+	}
+	return service
+}
+
+func (s *stubService) CreateUser(ctx context.Context, request *dto.CreateUserRequest) (*dto.CreateUserResponse, error) {
+	userId := s.nextId
+	s.nextId++
+	s.users[userId] = dto.User{UserId: userId, Username: request.Username, Email: request.Email, CreatedAt: time.Now()}
+	return &dto.CreateUserResponse{UserId: userId}, nil
+}
+
+func (s *stubService) GetUser(ctx context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+	for _, u := range s.users {
+		if (request.UserId == nil || *request.UserId == u.UserId) &&
+			(request.Username == nil || *request.Username == u.Username) &&
+			(request.Email == nil || *request.Email == u.Email) {
+			return &u, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *stubService) GetUsers(ctx context.Context, request *dto.GetUsersRequest) (*dto.GetUsersResponse, error) {
+	response := dto.GetUsersResponse{}
+	for i := 1; i < s.nextId; i++ {
+		if u, ok := s.users[i]; ok {
+			response.Users = append(response.Users, u)
+		}
+	}
+	return &response, nil
+}
+
+func (s *stubService) UpdateUser(ctx context.Context, request *dto.UpdateUserRequest) (*dto.UpdateUserResponse, error) {
+	u := s.users[request.UserId]
+	if request.Password != nil {
+		u.PasswordHash = "hashed:" + *request.Password
+	}
+	s.users[request.UserId] = u
+	return &u, nil
+}
+
+func (s *stubService) DeleteUser(ctx context.Context, request *dto.DeleteUserRequest) (*dto.DeleteUserResponse, error) {
+	s.deleted[request.UserId] = s.users[request.UserId]
+	delete(s.users, request.UserId)
+	return &dto.DeleteUserResponse{}, nil
+}
+
+func (s *stubService) VerifyUser(ctx context.Context, request *dto.VerifyUserRequest) (*dto.VerifyUserResponse, error) {
+	u := s.users[request.UserId]
+	u.IsVerified = request.IsVerified
+	s.users[request.UserId] = u
+	return &u, nil
+}
+
+func (s *stubService) RestoreUser(ctx context.Context, request *dto.RestoreUserRequest) (*dto.RestoreUserResponse, error) {
+	u, ok := s.deleted[request.UserId]
+	if !ok {
+		return nil, errors.New("not archived")
+	}
+	delete(s.deleted, request.UserId)
+	s.users[request.UserId] = u
+	return &u, nil
+}
+
+func (s *stubService) ConfirmEmailChange(
+	ctx context.Context,
+	request *dto.ConfirmEmailChangeRequest,
+) (*dto.ConfirmEmailChangeResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) UndoEmailChange(
+	ctx context.Context,
+	request *dto.UndoEmailChangeRequest,
+) (*dto.UndoEmailChangeResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) ScheduleUserDeletion(
+	ctx context.Context,
+	request *dto.ScheduleUserDeletionRequest,
+) (*dto.ScheduleUserDeletionResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) CancelUserDeletion(
+	ctx context.Context,
+	request *dto.CancelUserDeletionRequest,
+) (*dto.CancelUserDeletionResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) RequestDataExport(
+	ctx context.Context,
+	request *dto.RequestDataExportRequest,
+) (*dto.RequestDataExportResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) GetDataExport(
+	ctx context.Context,
+	request *dto.GetDataExportRequest,
+) (*dto.GetDataExportResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *stubService) DownloadDataExport(
+	ctx context.Context,
+	request *dto.DownloadDataExportRequest,
+) (*dto.DownloadDataExportResponse, error) {
+	return nil, errors.New("not supported")
+}
+
+func run(t *testing.T, service *stubService, args ...string) (string, error) {
+	return runWithInput(t, service, "", args...)
+}
+
+// runWithInput Run userctl with the input on stdin, such as a password
+func runWithInput(t *testing.T, service *stubService, input string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), args, service, strings.NewReader(input), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestRun_Create(t *testing.T) {
+	service := newStubService()
+
+	output, err := runWithInput(
+		t,
+		service,
+		ValidPassword+"\n",
+		"create",
+		"-username", ValidUsername,
+		"-email", ValidEmail,
+	)
+	if err != nil {
+		t.Fatalf(`Run(create) error = "%v", expected "<nil>"`, err)
+	}
+
+	if !strings.Contains(output, ValidUsername) {
+		t.Errorf(`output = "%s", expected to contain "%s"`, output, ValidUsername)
+	}
+	if len(service.users) != 1 {
+		t.Errorf(`len(service.users) = "%d", expected "1"`, len(service.users))
+	}
+}
+
+func TestRun_CreateInvalidPassword(t *testing.T) {
+	service := newStubService()
+
+	_, err := runWithInput(t, service, "short", "create", "-username", ValidUsername, "-email", ValidEmail)
+	if err == nil {
+		t.Error(`Run(create) error = "<nil>", expected non-nil`)
+	}
+	if len(service.users) != 0 {
+		t.Errorf(`len(service.users) = "%d", expected "0"`, len(service.users))
+	}
+}
+
+func TestRun_CreatePasswordFlag(t *testing.T) {
+	service := newStubService()
+
+	_, err := runWithInput(
+		t,
+		service,
+		ValidPassword,
+		"create",
+		"-username", ValidUsername,
+		"-email", ValidEmail,
+		"-password", ValidPassword,
+	)
+	if err == nil {
+		t.Error(`Run(create -password) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_CreateMissingPassword(t *testing.T) {
+	service := newStubService()
+
+	if _, err := run(t, service, "create", "-username", ValidUsername, "-email", ValidEmail); err == nil {
+		t.Error(`Run(create) error = "<nil>", expected non-nil without a password on stdin`)
+	}
+	if len(service.users) != 0 {
+		t.Errorf(`len(service.users) = "%d", expected "0"`, len(service.users))
+	}
+}
+
+func TestRun_GetJSON(t *testing.T) {
+	service := newStubService(
+		dto.User{UserId: 4, Username: ValidUsername, Email: ValidEmail, PasswordHash: "secret-hash"},
+	)
+
+	output, err := run(t, service, "-o", "json", "get", "-email", ValidEmail)
+	if err != nil {
+		t.Fatalf(`Run(get) error = "%v", expected "<nil>"`, err)
+	}
+
+	var response dto.User
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf(`json.Unmarshal(output, &response) = "%v", expected "<nil>"`, err)
+	}
+	if response.UserId != 4 {
+		t.Errorf(`response.UserId = "%d", expected "4"`, response.UserId)
+	}
+	if strings.Contains(output, "passwordHash") || strings.Contains(output, "secret-hash") {
+		t.Errorf(`output = "%s", expected no password hash`, output)
+	}
+}
+
+func TestRun_ListJSON(t *testing.T) {
+	service := newStubService(
+		dto.User{UserId: 1, Username: "first", Email: "first@email.com", PasswordHash: "secret-hash"},
+		dto.User{UserId: 2, Username: "second", Email: "second@email.com", PasswordHash: "secret-hash"},
+	)
+
+	output, err := run(t, service, "-o", "json", "list")
+	if err != nil {
+		t.Fatalf(`Run(list) error = "%v", expected "<nil>"`, err)
+	}
+
+	var response dto.GetUsersResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf(`json.Unmarshal(output, &response) = "%v", expected "<nil>"`, err)
+	}
+	if len(response.Users) != 2 || response.Users[1].Username != "second" {
+		t.Errorf(`response.Users = "%+v", expected both users`, response.Users)
+	}
+	if strings.Contains(output, "passwordHash") || strings.Contains(output, "secret-hash") {
+		t.Errorf(`output = "%s", expected no password hashes`, output)
+	}
+}
+
+func TestRun_GetMissingLookup(t *testing.T) {
+	if _, err := run(t, newStubService(), "get"); err == nil {
+		t.Error(`Run(get) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_ListTable(t *testing.T) {
+	service := newStubService(
+		dto.User{UserId: 1, Username: "first", Email: "first@email.com"},
+		dto.User{UserId: 2, Username: "second", Email: "second@email.com"},
+	)
+
+	output, err := run(t, service, "list", "-limit", "10", "-sort-field", "username", "-sort-order", "asc")
+	if err != nil {
+		t.Fatalf(`Run(list) error = "%v", expected "<nil>"`, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Errorf(`len(lines) = "%d", expected "3"`, len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "ID") {
+		t.Errorf(`lines[0] = "%s", expected header`, lines[0])
+	}
+}
+
+func TestRun_ListInvalidSortField(t *testing.T) {
+	if _, err := run(t, newStubService(), "list", "-sort-field", "shoeSize"); err == nil {
+		t.Error(`Run(list) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_ResetPassword(t *testing.T) {
+	service := newStubService(dto.User{UserId: 1, Username: ValidUsername, Email: ValidEmail})
+
+	if _, err := runWithInput(t, service, ValidPassword+"\r\n", "reset-password", "-id", "1"); err != nil {
+		t.Fatalf(`Run(reset-password) error = "%v", expected "<nil>"`, err)
+	}
+
+	if service.users[1].PasswordHash != "hashed:"+ValidPassword {
+		t.Errorf(`service.users[1].PasswordHash = "%s", expected "hashed:%s"`, service.users[1].PasswordHash, ValidPassword)
+	}
+}
+
+func TestRun_Verify(t *testing.T) {
+	service := newStubService(dto.User{UserId: 1, Username: ValidUsername, Email: ValidEmail})
+
+	if _, err := run(t, service, "verify", "-id", "1"); err != nil {
+		t.Fatalf(`Run(verify) error = "%v", expected "<nil>"`, err)
+	}
+
+	if !service.users[1].IsVerified {
+		t.Error(`service.users[1].IsVerified = "false", expected "true"`)
+	}
+}
+
+func TestRun_DeleteAndRestore(t *testing.T) {
+	service := newStubService(dto.User{UserId: 1, Username: ValidUsername, Email: ValidEmail})
+
+	if _, err := run(t, service, "delete", "-id", "1"); err != nil {
+		t.Fatalf(`Run(delete) error = "%v", expected "<nil>"`, err)
+	}
+	if _, ok := service.users[1]; ok {
+		t.Error(`service.users[1] exists, expected deleted`)
+	}
+
+	if _, err := run(t, service, "restore", "-id", "1"); err != nil {
+		t.Fatalf(`Run(restore) error = "%v", expected "<nil>"`, err)
+	}
+	if _, ok := service.users[1]; !ok {
+		t.Error(`service.users[1] does not exist, expected restored`)
+	}
+}
+
+func TestRun_RestoreExistingUser(t *testing.T) {
+	service := newStubService(dto.User{UserId: 1, Username: ValidUsername, Email: ValidEmail})
+
+	if _, err := run(t, service, "restore", "-id", "1"); err == nil {
+		t.Error(`Run(restore) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_ExportCSV(t *testing.T) {
+	service := newStubService(
+		dto.User{UserId: 1, Username: "first", Email: "first@email.com", PasswordHash: "secret-hash"},
+		dto.User{UserId: 2, Username: "second", Email: "second@email.com", PasswordHash: "secret-hash"},
+	)
+
+	output, err := run(t, service, "-o", "json", "export", "-format", "csv")
+	if err != nil {
+		t.Fatalf(`Run(export) error = "%v", expected "<nil>"`, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "userId,username,email") {
+		t.Errorf(`output = "%s", expected a header and 2 rows`, output)
+	}
+	if strings.Contains(output, "secret-hash") {
+		t.Errorf(`output = "%s", expected no password hashes`, output)
+	}
+}
+
+func TestRun_ImportDryRun(t *testing.T) {
+	service := newStubService(dto.User{UserId: 1, Username: "taken", Email: "taken@email.com"})
+
+	file := filepath.Join(t.TempDir(), "users.csv")
+	contents := "username,email,password\n" +
+		"new-user,new@email.com," + ValidPassword + "\n" +
+		"taken,other@email.com," + ValidPassword + "\n" +
+		"weak,weak@email.com,short\n"
+	if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+		t.Fatalf(`os.WriteFile(...) error = "%v", expected "<nil>"`, err)
+	}
+
+	output, err := run(t, service, "-o", "json", "import", "-file", file, "-dry-run")
+	if err != nil {
+		t.Fatalf(`Run(import) error = "%v", expected "<nil>"`, err)
+	}
+
+	var report dto.ImportUsersResponse
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf(`json.Unmarshal(output, &report) error = "%v", expected "<nil>"`, err)
+	}
+	if !report.DryRun || report.Total != 3 || report.Imported != 1 || report.Failed != 2 {
+		t.Errorf(`report = "%+v", expected 3 rows with 1 valid and 2 failed`, report)
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 4 {
+		t.Errorf(`report.Errors = "%+v", expected errors on lines 3 and 4`, report.Errors)
+	}
+	if len(service.users) != 1 {
+		t.Errorf(`len(service.users) = "%d", expected "1"`, len(service.users))
+	}
+}
+
+func TestRun_ImportMissingFile(t *testing.T) {
+	if _, err := run(t, newStubService(), "import", "-format", "csv"); err == nil {
+		t.Error(`Run(import) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	if _, err := run(t, newStubService(), "promote"); err == nil {
+		t.Error(`Run(promote) error = "<nil>", expected non-nil`)
+	}
+}
+
+func TestRun_UnknownOutputFormat(t *testing.T) {
+	if _, err := run(t, newStubService(), "-o", "yaml", "list"); err == nil {
+		t.Error(`Run(-o yaml list) error = "<nil>", expected non-nil`)
+	}
+}
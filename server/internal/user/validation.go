@@ -170,6 +170,46 @@ func ValidateDeleteUserRequest(request *dto.DeleteUserRequest, service Service,
     return nil
 }
 
+// ValidateVerifyUserRequest Validate request for marking a user as verified
+func ValidateVerifyUserRequest(request *dto.VerifyUserRequest, service Service, context context.Context) error {
+    if request.UserId < 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid user id",
+        }
+    }
+
+    getUserRequest := dto.GetUserRequest{UserId: &request.UserId}
+    if response, _ := service.GetUser(context, &getUserRequest); response == nil {
+        return &common.HTTPError{
+            StatusCode: http.StatusNotFound,
+            Message:    "user not found",
+        }
+    }
+
+    return nil
+}
+
+// ValidateRestoreUserRequest Validate request for restoring a deleted user
+func ValidateRestoreUserRequest(request *dto.RestoreUserRequest, service Service, context context.Context) error {
+    if request.UserId < 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid user id",
+        }
+    }
+
+    getUserRequest := dto.GetUserRequest{UserId: &request.UserId}
+    if response, _ := service.GetUser(context, &getUserRequest); response != nil {
+        return &common.HTTPError{
+            StatusCode: http.StatusConflict,
+            Message:    "user has not been deleted",
+        }
+    }
+
+    return nil
+}
+
 // validateUsername Validate a username
 func validateUsername(username string, service Service, context context.Context) error {
     if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
@@ -251,3 +291,117 @@ func validatePassword(password string) error {
 
     return nil
 }
+
+// ValidateConfirmEmailChangeRequest Validate request for confirming an email change
+func ValidateConfirmEmailChangeRequest(request *dto.ConfirmEmailChangeRequest) error {
+    return validateLinkToken(request.Token)
+}
+
+// ValidateUndoEmailChangeRequest Validate request for undoing an email change
+func ValidateUndoEmailChangeRequest(request *dto.UndoEmailChangeRequest) error {
+    return validateLinkToken(request.Token)
+}
+
+// ValidateScheduleUserDeletionRequest Validate request for scheduling a user's deletion
+func ValidateScheduleUserDeletionRequest(
+    request *dto.ScheduleUserDeletionRequest,
+    service Service,
+    context context.Context,
+) error {
+    if request.UserId < 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid user id",
+        }
+    }
+
+    getUserRequest := dto.GetUserRequest{UserId: &request.UserId}
+    if response, _ := service.GetUser(context, &getUserRequest); response == nil {
+        return &common.HTTPError{
+            StatusCode: http.StatusNotFound,
+            Message:    "user not found",
+        }
+    }
+
+    return nil
+}
+
+// ValidateCancelUserDeletionRequest Validate request for cancelling a user's deletion
+func ValidateCancelUserDeletionRequest(request *dto.CancelUserDeletionRequest) error {
+    return validateLinkToken(request.Token)
+}
+
+// ValidateRequestDataExportRequest Validate request for exporting a user's personal data
+func ValidateRequestDataExportRequest(
+    request *dto.RequestDataExportRequest,
+    service Service,
+    context context.Context,
+) error {
+    if request.UserId < 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid user id",
+        }
+    }
+
+    getUserRequest := dto.GetUserRequest{UserId: &request.UserId}
+    if response, _ := service.GetUser(context, &getUserRequest); response == nil {
+        return &common.HTTPError{
+            StatusCode: http.StatusNotFound,
+            Message:    "user not found",
+        }
+    }
+
+    return nil
+}
+
+// ValidateGetDataExportRequest Validate request for getting the status of a data export
+func ValidateGetDataExportRequest(request *dto.GetDataExportRequest) error {
+    if request.ExportId <= 0 {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "invalid export id",
+        }
+    }
+
+    return nil
+}
+
+// ValidateDownloadDataExportRequest Validate request for downloading a data export
+func ValidateDownloadDataExportRequest(request *dto.DownloadDataExportRequest) error {
+    return validateLinkToken(request.Token)
+}
+
+// validateLinkToken Validate that a token from an email link was provided
+func validateLinkToken(token string) error {
+    if token == "" {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    "token is required",
+        }
+    }
+
+    return nil
+}
+
+// ValidateExportUsersRequest Validate request for exporting users
+func ValidateExportUsersRequest(request *dto.ExportUsersRequest) error {
+    return validateBulkFormat(request.Format)
+}
+
+// ValidateImportUsersRequest Validate request for importing users
+func ValidateImportUsersRequest(request *dto.ImportUsersRequest) error {
+    return validateBulkFormat(request.Format)
+}
+
+// validateBulkFormat Validate the file format of an import or export
+func validateBulkFormat(format string) error {
+    if format != FormatCSV && format != FormatJSONL {
+        return &common.HTTPError{
+            StatusCode: http.StatusBadRequest,
+            Message:    fmt.Sprintf("unsupported format %q, expected %q or %q", format, FormatCSV, FormatJSONL),
+        }
+    }
+
+    return nil
+}
@@ -267,6 +267,75 @@ func TestValidateDeleteUserRequest_UserNotFound(t *testing.T) {
 	assertHTTPError(t, err, http.StatusNotFound)
 }
 
+func TestValidateVerifyUserRequest_Success(t *testing.T) {
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			return &dto.GetUserResponse{}, nil
+		},
+	}
+
+	request := dto.VerifyUserRequest{
+		UserId:     1,
+		IsVerified: true,
+	}
+
+	if err := ValidateVerifyUserRequest(&request, service, nil); err != nil {
+		t.Errorf(`ValidateVerifyUserRequest(&request, service, nil) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestValidateVerifyUserRequest_UserNotFound(t *testing.T) {
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			return nil, nil
+		},
+	}
+
+	request := dto.VerifyUserRequest{
+		UserId: 1,
+	}
+
+	err := ValidateVerifyUserRequest(&request, service, nil)
+	if err == nil {
+		t.Errorf(`ValidateVerifyUserRequest(&request, service, nil) = "%v", expected "user not found"`, err)
+	}
+	assertHTTPError(t, err, http.StatusNotFound)
+}
+
+func TestValidateRestoreUserRequest_Success(t *testing.T) {
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			return nil, nil
+		},
+	}
+
+	request := dto.RestoreUserRequest{
+		UserId: 1,
+	}
+
+	if err := ValidateRestoreUserRequest(&request, service, nil); err != nil {
+		t.Errorf(`ValidateRestoreUserRequest(&request, service, nil) = "%v", expected "<nil>"`, err)
+	}
+}
+
+func TestValidateRestoreUserRequest_UserExists(t *testing.T) {
+	service := &mockService{
+		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
+			return &dto.GetUserResponse{}, nil
+		},
+	}
+
+	request := dto.RestoreUserRequest{
+		UserId: 1,
+	}
+
+	err := ValidateRestoreUserRequest(&request, service, nil)
+	if err == nil {
+		t.Errorf(`ValidateRestoreUserRequest(&request, service, nil) = "%v", expected "user has not been deleted"`, err)
+	}
+	assertHTTPError(t, err, http.StatusConflict)
+}
+
 func TestValidateUsername_Success(t *testing.T) {
 	service := &mockService{
 		getUserFunc: func(context context.Context, request *dto.GetUserRequest) (*dto.GetUserResponse, error) {
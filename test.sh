@@ -2,16 +2,17 @@
 set -euo pipefail
 
 PROJECT_ROOT="$(pwd)"
-SERVICES=("user")
+SERVICES=("user" "quiz" "game")
+MODULES=("common" "${SERVICES[@]}")
 
 echo "Running unit tests..."
-for SERVICE in "${SERVICES[@]}"; do
-    SERVICE_PATH="./server/internal/$SERVICE"
+for MODULE in "${MODULES[@]}"; do
+    MODULE_PATH="./server/internal/$MODULE"
 
-    cd $SERVICE_PATH
+    cd $MODULE_PATH
 
-    echo "---------- ${SERVICES} ----------"
-    go test -v
+    echo "---------- ${MODULE} ----------"
+    go test ./...
 
     cd $PROJECT_ROOT
 done